@@ -17,6 +17,7 @@ import (
 	"github.com/skycoin/skycoin/src/readable"
 	"github.com/skycoin/skycoin/src/skycoin"
 	"github.com/skycoin/skycoin/src/util/logging"
+	"github.com/skycoin/skycoin/src/visor"
 
 	// register the supported wallets
 	_ "github.com/skycoin/skycoin/src/wallet/bip44wallet"
@@ -84,10 +85,16 @@ var (
 		UnconfirmedBurnFactor:          10,
 		UnconfirmedMaxTransactionSize:  32768,
 		UnconfirmedMaxDropletPrecision: 3,
+		UnconfirmedMinFeePerByte:       0,
 		CreateBlockBurnFactor:          10,
 		CreateBlockMaxTransactionSize:  32768,
 		CreateBlockMaxDropletPrecision: 3,
+		CreateBlockMinFeePerByte:       0,
 		MaxBlockTransactionsSize:       32768,
+		MinBlockInterval:               0,
+		SignalingWindow:                1000,
+		SignalingThreshold:             95,
+		SignalingRoundLength:           1000,
 
 		DisplayName:           "Skycoin",
 		Ticker:                "SKY",
@@ -124,7 +131,13 @@ func main() {
 
 	// parse config values
 	if err := coin.ParseConfig(); err != nil {
-		logger.Error(err)
+		if errs, ok := err.(visor.ConfigErrors); ok {
+			for _, e := range errs {
+				logger.Error(e)
+			}
+		} else {
+			logger.Error(err)
+		}
 		os.Exit(1)
 	}
 
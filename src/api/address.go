@@ -2,9 +2,16 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/base58"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/util/mathutil"
+	"github.com/skycoin/skycoin/src/visor"
 )
 
 // VerifyAddressRequest is the request data for POST /api/v2/address/verify
@@ -54,3 +61,388 @@ func addressVerifyHandler(w http.ResponseWriter, r *http.Request) {
 		},
 	})
 }
+
+// Address types of a ValidateAddressResponse
+const (
+	// AddressTypeAddress is an ordinary address
+	AddressTypeAddress = "address"
+	// AddressTypeStealth is a stealth address, which can't receive coins directly
+	AddressTypeStealth = "stealth"
+)
+
+// ValidateAddressRequest is the request data for POST /api/v2/crypto/validate_address
+type ValidateAddressRequest struct {
+	Address string `json:"address"`
+}
+
+// ValidateAddressResponse is returned by POST /api/v2/crypto/validate_address
+type ValidateAddressResponse struct {
+	Valid bool `json:"valid"`
+	// Reason explains why the address is invalid, empty if it is valid
+	Reason string `json:"reason,omitempty"`
+	// Type is set once the address has been decoded to a known address type
+	Type string `json:"type,omitempty"`
+	// Version is set once the checksum of the address has been verified
+	Version *byte `json:"version,omitempty"`
+}
+
+// validateAddressHandler validates an address and explains why it is invalid
+// Method: POST
+// URI: /api/v2/crypto/validate_address
+func validateAddressHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError405Response(w)
+		return
+	}
+
+	var req ValidateAddressRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError400Response(w, err.Error())
+		return
+	}
+
+	if req.Address == "" {
+		writeError400Response(w, "address is required")
+		return
+	}
+
+	writeHTTPResponse(w, HTTPResponse{
+		Data: validateAddress(req.Address),
+	})
+}
+
+// validateAddress decodes an address as an ordinary address or a stealth address,
+// and describes the first problem found if it is invalid
+func validateAddress(addr string) ValidateAddressResponse {
+	invalid := func(reason string) ValidateAddressResponse {
+		return ValidateAddressResponse{
+			Reason: reason,
+		}
+	}
+
+	b, err := base58.Decode(addr)
+	if err != nil {
+		switch err {
+		case base58.ErrInvalidChar:
+			return invalid("invalid base58 character")
+		default:
+			return invalid(fmt.Sprintf("invalid base58 string: %v", err))
+		}
+	}
+
+	rsp := ValidateAddressResponse{}
+	switch len(b) {
+	case 20 + 1 + 4:
+		rsp.Type = AddressTypeAddress
+		version := b[20]
+
+		switch _, err := cipher.AddressFromBytes(b); err {
+		case nil:
+			rsp.Valid = true
+			rsp.Version = &version
+		case cipher.ErrAddressInvalidChecksum:
+			rsp.Reason = "invalid base58 checksum"
+		case cipher.ErrAddressInvalidVersion:
+			rsp.Reason = fmt.Sprintf("wrong version byte %d, expected 0", version)
+			rsp.Version = &version
+		default:
+			rsp.Reason = err.Error()
+		}
+
+	default:
+		_, err := cipher.StealthAddressFromBytes(b)
+		if err == cipher.ErrStealthAddressInvalidLength {
+			return invalid(fmt.Sprintf("unknown address type, the address is %d bytes long", len(b)))
+		}
+
+		rsp.Type = AddressTypeStealth
+		version := b[0]
+
+		switch err {
+		case nil:
+			rsp.Valid = true
+			rsp.Version = &version
+		case cipher.ErrStealthAddressInvalidChecksum:
+			rsp.Reason = "invalid base58 checksum"
+		case cipher.ErrStealthAddressInvalidVersion:
+			rsp.Reason = fmt.Sprintf("wrong version byte %d, expected 0", version)
+			rsp.Version = &version
+		default:
+			rsp.Reason = fmt.Sprintf("invalid public key: %v", err)
+			rsp.Version = &version
+		}
+	}
+
+	return rsp
+}
+
+// ProjectedCoinHoursOutput is the coin hours projection of an unspent output
+type ProjectedCoinHoursOutput struct {
+	Hash            string `json:"hash"`
+	Coins           string `json:"coins"`
+	CalculatedHours uint64 `json:"calculated_hours"`
+	ProjectedHours  uint64 `json:"projected_hours"`
+}
+
+// ProjectedCoinHoursResponse is returned by GET /api/v2/address/{addr}/projected_coin_hours
+type ProjectedCoinHoursResponse struct {
+	Address              string                     `json:"address"`
+	HeadBlockSeq         uint64                     `json:"head_block_seq"`
+	AtBlock              uint64                     `json:"at_block"`
+	AtTime               uint64                     `json:"at_time"`
+	Outputs              []ProjectedCoinHoursOutput `json:"outputs"`
+	TotalCalculatedHours uint64                     `json:"total_calculated_hours"`
+	TotalProjectedHours  uint64                     `json:"total_projected_hours"`
+}
+
+// AddressBalanceAtResponse is returned by GET /api/v2/address/{addr}/balance_at
+type AddressBalanceAtResponse struct {
+	Address string `json:"address"`
+	Height  uint64 `json:"height"`
+	Coins   string `json:"coins"`
+	Hours   uint64 `json:"hours"`
+}
+
+// AddressTransactionCountResponse is returned by GET /api/v2/address/{addr}/transaction_count
+type AddressTransactionCountResponse struct {
+	Address string `json:"address"`
+	Count   uint64 `json:"count"`
+}
+
+// addressHandler routes the endpoints of a single address
+// URI: /api/v2/address/{addr}/...
+func addressHandler(gateway Gatewayer) http.HandlerFunc {
+	projectedCoinHours := projectedCoinHoursHandler(gateway)
+	balanceAt := addressBalanceAtHandler(gateway)
+	transactionCount := addressTransactionCountHandler(gateway)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v2/address/"), "/")
+		if len(parts) != 2 || parts[0] == "" {
+			writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusNotFound, ""))
+			return
+		}
+
+		switch parts[1] {
+		case "projected_coin_hours":
+			projectedCoinHours(w, r, parts[0])
+		case "balance_at":
+			balanceAt(w, r, parts[0])
+		case "transaction_count":
+			transactionCount(w, r, parts[0])
+		default:
+			writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusNotFound, ""))
+		}
+	}
+}
+
+// projectedCoinHoursHandler returns the coin hours that the confirmed unspent outputs of an address
+// will have at a future block. The time of the future block is estimated from the head block time
+// and the block creation interval.
+// Method: GET
+// URI: /api/v2/address/{addr}/projected_coin_hours
+// Args:
+//	at_block: block seq to project the coin hours to [required]. Must not be lower than the head block seq
+func projectedCoinHoursHandler(gateway Gatewayer) func(w http.ResponseWriter, r *http.Request, addrStr string) {
+	return func(w http.ResponseWriter, r *http.Request, addrStr string) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		addr, err := cipher.DecodeBase58Address(addrStr)
+		if err != nil {
+			writeError400Response(w, fmt.Sprintf("invalid address: %v", err))
+			return
+		}
+
+		atBlockStr := r.FormValue("at_block")
+		if atBlockStr == "" {
+			writeError400Response(w, "at_block is required")
+			return
+		}
+
+		atBlock, err := strconv.ParseUint(atBlockStr, 10, 64)
+		if err != nil {
+			writeError400Response(w, fmt.Sprintf("invalid 'at_block' value: %v", err))
+			return
+		}
+
+		summary, err := gateway.GetUnspentOutputsSummary([]visor.OutputsFilter{visor.FbyAddresses([]cipher.Address{addr})})
+		if err != nil {
+			writeError500Response(w, fmt.Sprintf("gateway.GetUnspentOutputsSummary failed: %v", err))
+			return
+		}
+
+		headSeq := summary.HeadBlock.Head.BkSeq
+		if atBlock < headSeq {
+			writeError400Response(w, fmt.Sprintf("at_block must not be lower than the head block seq %d", headSeq))
+			return
+		}
+
+		// Estimate the time of the future block
+		blockInterval := gateway.DaemonConfig().BlockCreationInterval
+		elapsed, err := mathutil.MultUint64(atBlock-headSeq, blockInterval)
+		if err != nil {
+			writeError400Response(w, "at_block is too far in the future")
+			return
+		}
+		atTime, err := mathutil.AddUint64(summary.HeadBlock.Head.Time, elapsed)
+		if err != nil {
+			writeError400Response(w, "at_block is too far in the future")
+			return
+		}
+
+		resp := ProjectedCoinHoursResponse{
+			Address:      addr.String(),
+			HeadBlockSeq: headSeq,
+			AtBlock:      atBlock,
+			AtTime:       atTime,
+			Outputs:      make([]ProjectedCoinHoursOutput, 0, len(summary.Confirmed)),
+		}
+
+		for _, o := range summary.Confirmed {
+			projected, err := visor.NewUnspentOutput(o.UxOut, atTime)
+			if err != nil {
+				writeError500Response(w, err.Error())
+				return
+			}
+
+			coins, err := droplet.ToString(o.Body.Coins)
+			if err != nil {
+				writeError500Response(w, err.Error())
+				return
+			}
+
+			resp.Outputs = append(resp.Outputs, ProjectedCoinHoursOutput{
+				Hash:            o.Hash().Hex(),
+				Coins:           coins,
+				CalculatedHours: o.CalculatedHours,
+				ProjectedHours:  projected.CalculatedHours,
+			})
+
+			resp.TotalCalculatedHours, err = mathutil.AddUint64(resp.TotalCalculatedHours, o.CalculatedHours)
+			if err != nil {
+				writeError500Response(w, "total calculated hours overflow")
+				return
+			}
+
+			resp.TotalProjectedHours, err = mathutil.AddUint64(resp.TotalProjectedHours, projected.CalculatedHours)
+			if err != nil {
+				writeError500Response(w, "total projected hours overflow")
+				return
+			}
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: resp,
+		})
+	}
+}
+
+// addressBalanceAtHandler returns the confirmed balance of an address after the block at a height was executed.
+// The coin hours are calculated at the time of that block. The balance is computed from the history
+// of the address' outputs, and cached for subsequent requests if the address has outputs and the cache is not full.
+// Method: GET
+// URI: /api/v2/address/{addr}/balance_at
+// Args:
+//	height: block seq of the balance [required]
+func addressBalanceAtHandler(gateway Gatewayer) func(w http.ResponseWriter, r *http.Request, addrStr string) {
+	return func(w http.ResponseWriter, r *http.Request, addrStr string) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		addr, err := cipher.DecodeBase58Address(addrStr)
+		if err != nil {
+			writeError400Response(w, fmt.Sprintf("invalid address: %v", err))
+			return
+		}
+
+		heightStr := r.FormValue("height")
+		if heightStr == "" {
+			writeError400Response(w, "height is required")
+			return
+		}
+
+		height, err := strconv.ParseUint(heightStr, 10, 64)
+		if err != nil {
+			writeError400Response(w, fmt.Sprintf("invalid 'height' value: %v", err))
+			return
+		}
+
+		b, err := gateway.GetAddressBalanceAt(addr, height)
+		if err != nil {
+			switch err.(type) {
+			case visor.ErrBlockPruned:
+				writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusGone, err.Error()))
+			default:
+				writeError500Response(w, fmt.Sprintf("gateway.GetAddressBalanceAt failed: %v", err))
+			}
+			return
+		}
+
+		if b == nil {
+			writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusNotFound, fmt.Sprintf("block at height %d not found", height)))
+			return
+		}
+
+		coins, err := droplet.ToString(b.Coins)
+		if err != nil {
+			writeError500Response(w, err.Error())
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: AddressBalanceAtResponse{
+				Address: addr.String(),
+				Height:  height,
+				Coins:   coins,
+				Hours:   b.Hours,
+			},
+		})
+	}
+}
+
+// addressTransactionCountHandler returns the number of confirmed transactions of an address,
+// read from the address transaction index without loading the transactions, so that
+// pagination UIs can show the number of pages before fetching them.
+// Method: GET
+// URI: /api/v2/address/{addr}/transaction_count
+// Response:
+//      200 - ok, returns the address and its transaction count
+//      400 - invalid address
+//      503 - the address transaction index is not available
+func addressTransactionCountHandler(gateway Gatewayer) func(w http.ResponseWriter, r *http.Request, addrStr string) {
+	return func(w http.ResponseWriter, r *http.Request, addrStr string) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		addr, err := cipher.DecodeBase58Address(addrStr)
+		if err != nil {
+			writeError400Response(w, fmt.Sprintf("invalid address: %v", err))
+			return
+		}
+
+		n, err := gateway.GetAddressTransactionCount(addr)
+		if err != nil {
+			switch err {
+			case visor.ErrIndexNotAvailable:
+				writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusServiceUnavailable, err.Error()))
+			default:
+				writeError500Response(w, fmt.Sprintf("gateway.GetAddressTransactionCount failed: %v", err))
+			}
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: AddressTransactionCountResponse{
+				Address: addr.String(),
+				Count:   n,
+			},
+		})
+	}
+}
@@ -2,12 +2,22 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/base58"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/historydb"
 )
 
 func toJSON(t *testing.T, r interface{}) string {
@@ -146,3 +156,611 @@ func TestVerifyAddress(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateAddress(t *testing.T) {
+	version := func(v byte) *byte {
+		return &v
+	}
+
+	addr := testutil.MakeAddress()
+	wrongVersionAddr := addr
+	wrongVersionAddr.Version = 1
+
+	scan, _ := cipher.GenerateKeyPair()
+	spend, _ := cipher.GenerateKeyPair()
+	stealthAddr, err := cipher.NewStealthAddress(scan, spend)
+	require.NoError(t, err)
+	badStealthAddr := stealthAddr
+	badStealthAddr.SpendKey = cipher.PubKey{}
+	wrongVersionStealthAddr := stealthAddr
+	wrongVersionStealthAddr.Version = 2
+
+	cases := []struct {
+		name         string
+		method       string
+		status       int
+		httpBody     string
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "400 - EOF",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "EOF"),
+		},
+		{
+			name:         "400 - Missing address",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			httpBody:     "{}",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "address is required"),
+		},
+		{
+			name:     "200 - valid address",
+			method:   http.MethodPost,
+			status:   http.StatusOK,
+			httpBody: toJSON(t, ValidateAddressRequest{Address: addr.String()}),
+			httpResponse: HTTPResponse{
+				Data: ValidateAddressResponse{
+					Valid:   true,
+					Type:    AddressTypeAddress,
+					Version: version(0),
+				},
+			},
+		},
+		{
+			name:     "200 - valid stealth address",
+			method:   http.MethodPost,
+			status:   http.StatusOK,
+			httpBody: toJSON(t, ValidateAddressRequest{Address: stealthAddr.String()}),
+			httpResponse: HTTPResponse{
+				Data: ValidateAddressResponse{
+					Valid:   true,
+					Type:    AddressTypeStealth,
+					Version: version(0),
+				},
+			},
+		},
+		{
+			name:     "200 - invalid base58 character",
+			method:   http.MethodPost,
+			status:   http.StatusOK,
+			httpBody: toJSON(t, ValidateAddressRequest{Address: "0cpQ7t3PZZXvjTst8G7Uvs7XH4LeM8fBPD"}),
+			httpResponse: HTTPResponse{
+				Data: ValidateAddressResponse{
+					Reason: "invalid base58 character",
+				},
+			},
+		},
+		{
+			name:     "200 - invalid checksum",
+			method:   http.MethodPost,
+			status:   http.StatusOK,
+			httpBody: toJSON(t, ValidateAddressRequest{Address: "7apQ7t3PZZXvjTst8G7Uvs7XH4LeM8fBPD"}),
+			httpResponse: HTTPResponse{
+				Data: ValidateAddressResponse{
+					Reason: "invalid base58 checksum",
+					Type:   AddressTypeAddress,
+				},
+			},
+		},
+		{
+			name:     "200 - wrong version byte",
+			method:   http.MethodPost,
+			status:   http.StatusOK,
+			httpBody: toJSON(t, ValidateAddressRequest{Address: string(base58.Encode(wrongVersionAddr.Bytes()))}),
+			httpResponse: HTTPResponse{
+				Data: ValidateAddressResponse{
+					Reason:  "wrong version byte 1, expected 0",
+					Type:    AddressTypeAddress,
+					Version: version(1),
+				},
+			},
+		},
+		{
+			name:     "200 - unknown address type",
+			method:   http.MethodPost,
+			status:   http.StatusOK,
+			httpBody: toJSON(t, ValidateAddressRequest{Address: string(base58.Encode(addr.Bytes()[:24]))}),
+			httpResponse: HTTPResponse{
+				Data: ValidateAddressResponse{
+					Reason: "unknown address type, the address is 24 bytes long",
+				},
+			},
+		},
+		{
+			name:     "200 - stealth address wrong version byte",
+			method:   http.MethodPost,
+			status:   http.StatusOK,
+			httpBody: toJSON(t, ValidateAddressRequest{Address: string(base58.Encode(wrongVersionStealthAddr.Bytes()))}),
+			httpResponse: HTTPResponse{
+				Data: ValidateAddressResponse{
+					Reason:  "wrong version byte 2, expected 0",
+					Type:    AddressTypeStealth,
+					Version: version(2),
+				},
+			},
+		},
+		{
+			name:     "200 - stealth address invalid public key",
+			method:   http.MethodPost,
+			status:   http.StatusOK,
+			httpBody: toJSON(t, ValidateAddressRequest{Address: string(base58.Encode(badStealthAddr.Bytes()))}),
+			httpResponse: HTTPResponse{
+				Data: ValidateAddressResponse{
+					Reason:  "invalid public key: Invalid public key",
+					Type:    AddressTypeStealth,
+					Version: version(0),
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoint := "/api/v2/crypto/validate_address"
+			gateway := &MockGatewayer{}
+
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var addrRsp ValidateAddressResponse
+				err := json.Unmarshal(rsp.Data, &addrRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(ValidateAddressResponse), addrRsp)
+			}
+		})
+	}
+}
+
+func TestProjectedCoinHours(t *testing.T) {
+	addr := testutil.MakeAddress()
+
+	uxouts := []coin.UxOut{
+		{
+			Head: coin.UxHead{Time: 1000000, BkSeq: 90},
+			Body: coin.UxBody{Address: addr, Coins: 36e6, Hours: 10},
+		},
+		{
+			Head: coin.UxHead{Time: 996400, BkSeq: 80},
+			Body: coin.UxBody{Address: addr, Coins: 72e6, Hours: 5},
+		},
+	}
+
+	headBlock := &coin.SignedBlock{
+		Block: coin.Block{
+			Head: coin.BlockHeader{
+				BkSeq: 100,
+				Time:  1000000,
+			},
+		},
+	}
+
+	unspents, err := visor.NewUnspentOutputs(uxouts, headBlock.Head.Time)
+	require.NoError(t, err)
+
+	summary := &visor.UnspentOutputsSummary{
+		HeadBlock: headBlock,
+		Confirmed: unspents,
+	}
+
+	cases := []struct {
+		name                      string
+		method                    string
+		path                      string
+		atBlock                   string
+		status                    int
+		getUnspentOutputsResponse *visor.UnspentOutputsSummary
+		getUnspentOutputsError    error
+		httpResponse              HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			path:         "/api/v2/address/" + addr.String() + "/projected_coin_hours",
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "404 - unknown endpoint",
+			method:       http.MethodGet,
+			path:         "/api/v2/address/" + addr.String() + "/foo",
+			status:       http.StatusNotFound,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:         "404 - missing address",
+			method:       http.MethodGet,
+			path:         "/api/v2/address/projected_coin_hours",
+			status:       http.StatusNotFound,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:         "400 - invalid address",
+			method:       http.MethodGet,
+			path:         "/api/v2/address/foo/projected_coin_hours",
+			atBlock:      "110",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid address: Invalid address length"),
+		},
+		{
+			name:         "400 - missing at_block",
+			method:       http.MethodGet,
+			path:         "/api/v2/address/" + addr.String() + "/projected_coin_hours",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "at_block is required"),
+		},
+		{
+			name:         "400 - invalid at_block",
+			method:       http.MethodGet,
+			path:         "/api/v2/address/" + addr.String() + "/projected_coin_hours",
+			atBlock:      "-1",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid 'at_block' value: strconv.ParseUint: parsing \"-1\": invalid syntax"),
+		},
+		{
+			name:                      "400 - at_block lower than head",
+			method:                    http.MethodGet,
+			path:                      "/api/v2/address/" + addr.String() + "/projected_coin_hours",
+			atBlock:                   "99",
+			status:                    http.StatusBadRequest,
+			getUnspentOutputsResponse: summary,
+			httpResponse:              NewHTTPErrorResponse(http.StatusBadRequest, "at_block must not be lower than the head block seq 100"),
+		},
+		{
+			name:                      "400 - at_block too far in the future",
+			method:                    http.MethodGet,
+			path:                      "/api/v2/address/" + addr.String() + "/projected_coin_hours",
+			atBlock:                   "18446744073709551615",
+			status:                    http.StatusBadRequest,
+			getUnspentOutputsResponse: summary,
+			httpResponse:              NewHTTPErrorResponse(http.StatusBadRequest, "at_block is too far in the future"),
+		},
+		{
+			name:                   "500 - GetUnspentOutputsSummary failed",
+			method:                 http.MethodGet,
+			path:                   "/api/v2/address/" + addr.String() + "/projected_coin_hours",
+			atBlock:                "110",
+			status:                 http.StatusInternalServerError,
+			getUnspentOutputsError: errors.New("getUnspentOutputsError"),
+			httpResponse:           NewHTTPErrorResponse(http.StatusInternalServerError, "gateway.GetUnspentOutputsSummary failed: getUnspentOutputsError"),
+		},
+		{
+			name:                      "200",
+			method:                    http.MethodGet,
+			path:                      "/api/v2/address/" + addr.String() + "/projected_coin_hours",
+			atBlock:                   "110",
+			status:                    http.StatusOK,
+			getUnspentOutputsResponse: summary,
+			httpResponse: HTTPResponse{
+				Data: ProjectedCoinHoursResponse{
+					Address:      addr.String(),
+					HeadBlockSeq: 100,
+					AtBlock:      110,
+					AtTime:       1000100,
+					Outputs: []ProjectedCoinHoursOutput{
+						{
+							Hash:            uxouts[0].Hash().Hex(),
+							Coins:           "36.000000",
+							CalculatedHours: 10,
+							ProjectedHours:  11,
+						},
+						{
+							Hash:            uxouts[1].Hash().Hex(),
+							Coins:           "72.000000",
+							CalculatedHours: 77,
+							ProjectedHours:  79,
+						},
+					},
+					TotalCalculatedHours: 87,
+					TotalProjectedHours:  90,
+				},
+			},
+		},
+		{
+			name:                      "200 - at head block",
+			method:                    http.MethodGet,
+			path:                      "/api/v2/address/" + addr.String() + "/projected_coin_hours",
+			atBlock:                   "100",
+			status:                    http.StatusOK,
+			getUnspentOutputsResponse: &visor.UnspentOutputsSummary{HeadBlock: headBlock},
+			httpResponse: HTTPResponse{
+				Data: ProjectedCoinHoursResponse{
+					Address:      addr.String(),
+					HeadBlockSeq: 100,
+					AtBlock:      100,
+					AtTime:       1000000,
+					Outputs:      []ProjectedCoinHoursOutput{},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetUnspentOutputsSummary", mock.Anything).Return(tc.getUnspentOutputsResponse, tc.getUnspentOutputsError)
+			gateway.On("DaemonConfig").Return(daemon.DaemonConfig{
+				BlockCreationInterval: 10,
+			})
+
+			endpoint := tc.path
+			if tc.atBlock != "" {
+				endpoint += "?at_block=" + tc.atBlock
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var projectedRsp ProjectedCoinHoursResponse
+				err := json.Unmarshal(rsp.Data, &projectedRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(ProjectedCoinHoursResponse), projectedRsp)
+			}
+		})
+	}
+}
+
+func TestAddressBalanceAt(t *testing.T) {
+	addr := testutil.MakeAddress()
+
+	cases := []struct {
+		name                   string
+		method                 string
+		path                   string
+		height                 string
+		status                 int
+		getAddressBalanceAt    *historydb.AddressBalance
+		getAddressBalanceAtErr error
+		httpResponse           HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			path:         "/api/v2/address/" + addr.String() + "/balance_at",
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "400 - invalid address",
+			method:       http.MethodGet,
+			path:         "/api/v2/address/foo/balance_at",
+			height:       "10",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid address: Invalid address length"),
+		},
+		{
+			name:         "400 - missing height",
+			method:       http.MethodGet,
+			path:         "/api/v2/address/" + addr.String() + "/balance_at",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "height is required"),
+		},
+		{
+			name:         "400 - invalid height",
+			method:       http.MethodGet,
+			path:         "/api/v2/address/" + addr.String() + "/balance_at",
+			height:       "-1",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid 'height' value: strconv.ParseUint: parsing \"-1\": invalid syntax"),
+		},
+		{
+			name:                   "500 - GetAddressBalanceAt failed",
+			method:                 http.MethodGet,
+			path:                   "/api/v2/address/" + addr.String() + "/balance_at",
+			height:                 "10",
+			status:                 http.StatusInternalServerError,
+			getAddressBalanceAtErr: errors.New("getAddressBalanceAtErr"),
+			httpResponse:           NewHTTPErrorResponse(http.StatusInternalServerError, "gateway.GetAddressBalanceAt failed: getAddressBalanceAtErr"),
+		},
+		{
+			name:         "404 - block not found",
+			method:       http.MethodGet,
+			path:         "/api/v2/address/" + addr.String() + "/balance_at",
+			height:       "10",
+			status:       http.StatusNotFound,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, "block at height 10 not found"),
+		},
+		{
+			name:   "200",
+			method: http.MethodGet,
+			path:   "/api/v2/address/" + addr.String() + "/balance_at",
+			height: "10",
+			status: http.StatusOK,
+			getAddressBalanceAt: &historydb.AddressBalance{
+				Coins: 12345000,
+				Hours: 678,
+			},
+			httpResponse: HTTPResponse{
+				Data: AddressBalanceAtResponse{
+					Address: addr.String(),
+					Height:  10,
+					Coins:   "12.345000",
+					Hours:   678,
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetAddressBalanceAt", addr, uint64(10)).Return(tc.getAddressBalanceAt, tc.getAddressBalanceAtErr)
+
+			endpoint := tc.path
+			if tc.height != "" {
+				endpoint += "?height=" + tc.height
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var balanceRsp AddressBalanceAtResponse
+				err := json.Unmarshal(rsp.Data, &balanceRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(AddressBalanceAtResponse), balanceRsp)
+			}
+		})
+	}
+}
+
+func TestAddressTransactionCount(t *testing.T) {
+	addr := testutil.MakeAddress()
+
+	cases := []struct {
+		name                          string
+		method                        string
+		path                          string
+		status                        int
+		getAddressTransactionCount    uint64
+		getAddressTransactionCountErr error
+		httpResponse                  HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			path:         "/api/v2/address/" + addr.String() + "/transaction_count",
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "400 - invalid address",
+			method:       http.MethodGet,
+			path:         "/api/v2/address/foo/transaction_count",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid address: Invalid address length"),
+		},
+		{
+			name:                          "503 - index not available",
+			method:                        http.MethodGet,
+			path:                          "/api/v2/address/" + addr.String() + "/transaction_count",
+			status:                        http.StatusServiceUnavailable,
+			getAddressTransactionCountErr: visor.ErrIndexNotAvailable,
+			httpResponse:                  NewHTTPErrorResponse(http.StatusServiceUnavailable, visor.ErrIndexNotAvailable.Error()),
+		},
+		{
+			name:                          "500 - GetAddressTransactionCount failed",
+			method:                        http.MethodGet,
+			path:                          "/api/v2/address/" + addr.String() + "/transaction_count",
+			status:                        http.StatusInternalServerError,
+			getAddressTransactionCountErr: errors.New("getAddressTransactionCountErr"),
+			httpResponse:                  NewHTTPErrorResponse(http.StatusInternalServerError, "gateway.GetAddressTransactionCount failed: getAddressTransactionCountErr"),
+		},
+		{
+			name:                       "200",
+			method:                     http.MethodGet,
+			path:                       "/api/v2/address/" + addr.String() + "/transaction_count",
+			status:                     http.StatusOK,
+			getAddressTransactionCount: 42,
+			httpResponse: HTTPResponse{
+				Data: AddressTransactionCountResponse{
+					Address: addr.String(),
+					Count:   42,
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetAddressTransactionCount", addr).Return(tc.getAddressTransactionCount, tc.getAddressTransactionCountErr)
+
+			req, err := http.NewRequest(tc.method, tc.path, nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var countRsp AddressTransactionCountResponse
+				err := json.Unmarshal(rsp.Data, &countRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(AddressTransactionCountResponse), countRsp)
+			}
+		})
+	}
+}
@@ -148,7 +148,12 @@ func blockHandler(gateway Gatewayer) http.HandlerFunc {
 			}
 
 			if err != nil {
-				wh.Error500(w, err.Error())
+				switch err.(type) {
+				case visor.ErrBlockPruned:
+					wh.Error410(w, err.Error())
+				default:
+					wh.Error500(w, err.Error())
+				}
 				return
 			}
 
@@ -176,7 +181,12 @@ func blockHandler(gateway Gatewayer) http.HandlerFunc {
 		}
 
 		if err != nil {
-			wh.Error500(w, err.Error())
+			switch err.(type) {
+			case visor.ErrBlockPruned:
+				wh.Error410(w, err.Error())
+			default:
+				wh.Error500(w, err.Error())
+			}
 			return
 		}
 
@@ -291,6 +301,8 @@ func blocksHandler(gateway Gatewayer) http.HandlerFunc {
 				switch err.(type) {
 				case visor.ErrBlockNotExist:
 					wh.Error404(w, err.Error())
+				case visor.ErrBlockPruned:
+					wh.Error410(w, err.Error())
 				default:
 					wh.Error500(w, err.Error())
 				}
@@ -318,6 +330,8 @@ func blocksHandler(gateway Gatewayer) http.HandlerFunc {
 				switch err.(type) {
 				case visor.ErrBlockNotExist:
 					wh.Error404(w, err.Error())
+				case visor.ErrBlockPruned:
+					wh.Error410(w, err.Error())
 				default:
 					wh.Error500(w, err.Error())
 				}
@@ -364,7 +378,12 @@ func lastBlocksHandler(gateway Gatewayer) http.HandlerFunc {
 		if verbose {
 			blocks, inputs, err := gateway.GetLastBlocksVerbose(n)
 			if err != nil {
-				wh.Error500(w, err.Error())
+				switch err.(type) {
+				case visor.ErrBlockPruned:
+					wh.Error410(w, err.Error())
+				default:
+					wh.Error500(w, err.Error())
+				}
 				return
 			}
 
@@ -380,7 +399,12 @@ func lastBlocksHandler(gateway Gatewayer) http.HandlerFunc {
 
 		blocks, err := gateway.GetLastBlocks(n)
 		if err != nil {
-			wh.Error500(w, err.Error())
+			switch err.(type) {
+			case visor.ErrBlockPruned:
+				wh.Error410(w, err.Error())
+			default:
+				wh.Error500(w, err.Error())
+			}
 			return
 		}
 
@@ -393,3 +417,234 @@ func lastBlocksHandler(gateway Gatewayer) http.HandlerFunc {
 		wh.SendJSONOr500(logger, w, rb)
 	}
 }
+
+// maxBlockStatsRange is the maximum number of blocks in a GET /api/v2/blockchain/stats request
+const maxBlockStatsRange = 1000
+
+// BlockStats are the statistics of a block, returned by GET /api/v2/blockchain/stats
+type BlockStats struct {
+	Height               uint64 `json:"height"`
+	TxCount              int    `json:"tx_count"`
+	TotalCoinHoursBurned uint64 `json:"total_coin_hours_burned"`
+	SizeBytes            uint32 `json:"size_bytes"`
+	TimestampUnix        uint64 `json:"timestamp_unix"`
+}
+
+// blockchainStatsHandler returns the statistics of the blocks between start and end, including both start and end.
+// Blocks after the head block are not included.
+// Method: GET
+// URI: /api/v2/blockchain/stats
+// Args:
+//	start [int, required]
+//	end [int, required]. At most 1000 blocks can be requested at once
+func blockchainStatsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		sStart := r.FormValue("start")
+		sEnd := r.FormValue("end")
+		if sStart == "" || sEnd == "" {
+			writeError400Response(w, "start and end are required")
+			return
+		}
+
+		start, err := strconv.ParseUint(sStart, 10, 64)
+		if err != nil {
+			writeError400Response(w, fmt.Sprintf("invalid 'start' value: %v", err))
+			return
+		}
+
+		end, err := strconv.ParseUint(sEnd, 10, 64)
+		if err != nil {
+			writeError400Response(w, fmt.Sprintf("invalid 'end' value: %v", err))
+			return
+		}
+
+		if start > end {
+			writeError400Response(w, "start must not be greater than end")
+			return
+		}
+
+		if end-start >= maxBlockStatsRange {
+			writeError400Response(w, fmt.Sprintf("at most %d blocks can be requested", maxBlockStatsRange))
+			return
+		}
+
+		stats, err := gateway.StatsByHeight(start, end)
+		if err != nil {
+			switch err.(type) {
+			case visor.ErrBlockPruned:
+				writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusGone, err.Error()))
+			default:
+				writeError500Response(w, fmt.Sprintf("gateway.StatsByHeight failed: %v", err))
+			}
+			return
+		}
+
+		resp := make([]BlockStats, len(stats))
+		for i, s := range stats {
+			resp[i] = BlockStats{
+				Height:               s.Height,
+				TxCount:              s.TxCount,
+				TotalCoinHoursBurned: s.TotalCoinHoursBurned,
+				SizeBytes:            s.SizeBytes,
+				TimestampUnix:        s.TimestampUnix,
+			}
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: resp,
+		})
+	}
+}
+
+const (
+	// defaultFeeHistoryBlocks is the default number of blocks in a GET /api/v2/fees/history request
+	defaultFeeHistoryBlocks = 50
+	// maxFeeHistoryBlocks is the maximum number of blocks in a GET /api/v2/fees/history request
+	maxFeeHistoryBlocks = 1000
+)
+
+// BlockFeeHistory is the distribution of the fee rates of a block's transactions, returned by GET /api/v2/fees/history.
+// Fee rates are in coin hours per byte of the transaction.
+type BlockFeeHistory struct {
+	Height           uint64  `json:"height"`
+	TxCount          int     `json:"tx_count"`
+	TimestampUnix    uint64  `json:"timestamp_unix"`
+	MinFeePerByte    float64 `json:"min_fee_per_byte"`
+	MedianFeePerByte float64 `json:"median_fee_per_byte"`
+	MaxFeePerByte    float64 `json:"max_fee_per_byte"`
+}
+
+// feeHistoryHandler returns the minimum, median and maximum fee rates of the transactions of the last blocks,
+// ordered by height. The genesis block is not included.
+// Method: GET
+// URI: /api/v2/fees/history
+// Args:
+//	blocks [int, optional]. Number of blocks, defaults to 50. At most 1000 blocks can be requested
+func feeHistoryHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		n := uint64(defaultFeeHistoryBlocks)
+		if s := r.FormValue("blocks"); s != "" {
+			var err error
+			n, err = strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				writeError400Response(w, fmt.Sprintf("invalid 'blocks' value: %v", err))
+				return
+			}
+		}
+
+		if n == 0 || n > maxFeeHistoryBlocks {
+			writeError400Response(w, fmt.Sprintf("blocks must be between 1 and %d", maxFeeHistoryBlocks))
+			return
+		}
+
+		history, err := gateway.GetFeeHistory(n)
+		if err != nil {
+			switch err.(type) {
+			case visor.ErrBlockPruned:
+				writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusGone, err.Error()))
+			default:
+				writeError500Response(w, fmt.Sprintf("gateway.GetFeeHistory failed: %v", err))
+			}
+			return
+		}
+
+		resp := make([]BlockFeeHistory, len(history))
+		for i, h := range history {
+			resp[i] = BlockFeeHistory{
+				Height:           h.Height,
+				TxCount:          h.TxCount,
+				TimestampUnix:    h.TimestampUnix,
+				MinFeePerByte:    h.MinFeeRate,
+				MedianFeePerByte: h.MedianFeeRate,
+				MaxFeePerByte:    h.MaxFeeRate,
+			}
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: resp,
+		})
+	}
+}
+
+// BlockSizeBucket is a bucket of a block size histogram
+type BlockSizeBucket struct {
+	// MaxSize is the upper bound of the bucket, in bytes
+	MaxSize uint32 `json:"max_size"`
+	// Blocks is the number of blocks larger than the previous bucket's MaxSize and at most MaxSize
+	Blocks uint64 `json:"blocks"`
+}
+
+// BlockSizeHistogram counts the blocks by the size of their transactions
+type BlockSizeHistogram struct {
+	Blocks     uint64            `json:"blocks"`
+	TotalSize  uint64            `json:"total_size"`
+	FromHeight uint64            `json:"from_height"`
+	ToHeight   uint64            `json:"to_height"`
+	Buckets    []BlockSizeBucket `json:"buckets"`
+	// BlocksAboveLastBucket is the number of blocks larger than the MaxSize of the last bucket
+	BlocksAboveLastBucket uint64 `json:"blocks_above_last_bucket"`
+}
+
+// BlockchainCapacity is returned by GET /api/v2/blockchain/capacity
+type BlockchainCapacity struct {
+	// MaxBlockSize is the maximum size of the transactions of the next block, in bytes
+	MaxBlockSize uint32             `json:"max_block_size"`
+	BlockSizes   BlockSizeHistogram `json:"block_sizes"`
+}
+
+func newBlockchainCapacity(c visor.BlockchainCapacity) BlockchainCapacity {
+	h := c.Histogram
+
+	buckets := make([]BlockSizeBucket, len(h.Buckets))
+	for i, b := range h.Buckets {
+		buckets[i] = BlockSizeBucket{
+			MaxSize: b,
+			Blocks:  h.Counts[i],
+		}
+	}
+
+	return BlockchainCapacity{
+		MaxBlockSize: c.MaxBlockSize,
+		BlockSizes: BlockSizeHistogram{
+			Blocks:                h.Count,
+			TotalSize:             h.Sum,
+			FromHeight:            h.FromSeq,
+			ToHeight:              h.ToSeq,
+			Buckets:               buckets,
+			BlocksAboveLastBucket: h.Counts[len(h.Counts)-1],
+		},
+	}
+}
+
+// blockchainCapacityHandler returns the maximum size of the next block and the histogram of the sizes of the blocks
+// in the blockchain, to monitor how close the blocks are to the maximum block size
+// Method: GET
+// URI: /api/v2/blockchain/capacity
+func blockchainCapacityHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		capacity, err := gateway.GetBlockchainCapacity()
+		if err != nil {
+			writeError500Response(w, fmt.Sprintf("gateway.GetBlockchainCapacity failed: %v", err))
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: newBlockchainCapacity(*capacity),
+		})
+	}
+}
@@ -371,6 +371,15 @@ func TestGetBlock(t *testing.T) {
 			seq:                     1,
 			gatewayGetBlockBySeqErr: errors.New("GetSignedBlockBySeq failed"),
 		},
+		{
+			name:                    "410 - block by seq is pruned",
+			method:                  http.MethodGet,
+			status:                  http.StatusGone,
+			err:                     "410 Gone - transactions of block seq=1 have been pruned",
+			seqStr:                  "1",
+			seq:                     1,
+			gatewayGetBlockBySeqErr: visor.ErrBlockPruned{Seq: 1},
+		},
 		{
 			name:                       "200 - get block by seq",
 			method:                     http.MethodGet,
@@ -1220,3 +1229,403 @@ func TestGetLastBlocks(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockchainStats(t *testing.T) {
+	stats := []visor.BlockStats{
+		{
+			Height:               10,
+			TxCount:              2,
+			TotalCoinHoursBurned: 120,
+			SizeBytes:            366,
+			TimestampUnix:        1540000000,
+		},
+		{
+			Height:        11,
+			TimestampUnix: 1540000010,
+		},
+	}
+
+	cases := []struct {
+		name             string
+		method           string
+		query            string
+		status           int
+		statsByHeight    []visor.BlockStats
+		statsByHeightErr error
+		httpResponse     HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			query:        "?start=10&end=11",
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "400 - missing end",
+			method:       http.MethodGet,
+			query:        "?start=10",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "start and end are required"),
+		},
+		{
+			name:         "400 - invalid start",
+			method:       http.MethodGet,
+			query:        "?start=foo&end=11",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid 'start' value: strconv.ParseUint: parsing \"foo\": invalid syntax"),
+		},
+		{
+			name:         "400 - invalid end",
+			method:       http.MethodGet,
+			query:        "?start=10&end=-1",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid 'end' value: strconv.ParseUint: parsing \"-1\": invalid syntax"),
+		},
+		{
+			name:         "400 - start greater than end",
+			method:       http.MethodGet,
+			query:        "?start=11&end=10",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "start must not be greater than end"),
+		},
+		{
+			name:         "400 - range too large",
+			method:       http.MethodGet,
+			query:        "?start=10&end=1010",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "at most 1000 blocks can be requested"),
+		},
+		{
+			name:             "410 - pruned",
+			method:           http.MethodGet,
+			query:            "?start=10&end=11",
+			status:           http.StatusGone,
+			statsByHeightErr: visor.ErrBlockPruned{Seq: 10},
+			httpResponse:     NewHTTPErrorResponse(http.StatusGone, "transactions of block seq=10 have been pruned"),
+		},
+		{
+			name:             "500 - StatsByHeight failed",
+			method:           http.MethodGet,
+			query:            "?start=10&end=11",
+			status:           http.StatusInternalServerError,
+			statsByHeightErr: errors.New("statsByHeightErr"),
+			httpResponse:     NewHTTPErrorResponse(http.StatusInternalServerError, "gateway.StatsByHeight failed: statsByHeightErr"),
+		},
+		{
+			name:          "200",
+			method:        http.MethodGet,
+			query:         "?start=10&end=11",
+			status:        http.StatusOK,
+			statsByHeight: stats,
+			httpResponse: HTTPResponse{
+				Data: []BlockStats{
+					{
+						Height:               10,
+						TxCount:              2,
+						TotalCoinHoursBurned: 120,
+						SizeBytes:            366,
+						TimestampUnix:        1540000000,
+					},
+					{
+						Height:        11,
+						TimestampUnix: 1540000010,
+					},
+				},
+			},
+		},
+		{
+			name:         "200 - no blocks",
+			method:       http.MethodGet,
+			query:        "?start=10&end=11",
+			status:       http.StatusOK,
+			httpResponse: HTTPResponse{Data: []BlockStats{}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("StatsByHeight", uint64(10), uint64(11)).Return(tc.statsByHeight, tc.statsByHeightErr)
+
+			req, err := http.NewRequest(tc.method, "/api/v2/blockchain/stats"+tc.query, nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var statsRsp []BlockStats
+				err := json.Unmarshal(rsp.Data, &statsRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.([]BlockStats), statsRsp)
+			}
+		})
+	}
+}
+
+func TestFeeHistory(t *testing.T) {
+	history := []visor.BlockFeeHistory{
+		{
+			Height:        10,
+			TxCount:       3,
+			TimestampUnix: 1540000000,
+			MinFeeRate:    0.5,
+			MedianFeeRate: 1.25,
+			MaxFeeRate:    4,
+		},
+		{
+			Height:        11,
+			TxCount:       1,
+			TimestampUnix: 1540000010,
+			MinFeeRate:    2,
+			MedianFeeRate: 2,
+			MaxFeeRate:    2,
+		},
+	}
+
+	cases := []struct {
+		name          string
+		method        string
+		query         string
+		status        int
+		n             uint64
+		feeHistory    []visor.BlockFeeHistory
+		feeHistoryErr error
+		httpResponse  HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "400 - invalid blocks",
+			method:       http.MethodGet,
+			query:        "?blocks=foo",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid 'blocks' value: strconv.ParseUint: parsing \"foo\": invalid syntax"),
+		},
+		{
+			name:         "400 - zero blocks",
+			method:       http.MethodGet,
+			query:        "?blocks=0",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "blocks must be between 1 and 1000"),
+		},
+		{
+			name:         "400 - too many blocks",
+			method:       http.MethodGet,
+			query:        "?blocks=1001",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "blocks must be between 1 and 1000"),
+		},
+		{
+			name:          "410 - pruned",
+			method:        http.MethodGet,
+			query:         "?blocks=2",
+			status:        http.StatusGone,
+			n:             2,
+			feeHistoryErr: visor.ErrBlockPruned{Seq: 10},
+			httpResponse:  NewHTTPErrorResponse(http.StatusGone, "transactions of block seq=10 have been pruned"),
+		},
+		{
+			name:          "500 - GetFeeHistory failed",
+			method:        http.MethodGet,
+			query:         "?blocks=2",
+			status:        http.StatusInternalServerError,
+			n:             2,
+			feeHistoryErr: errors.New("feeHistoryErr"),
+			httpResponse:  NewHTTPErrorResponse(http.StatusInternalServerError, "gateway.GetFeeHistory failed: feeHistoryErr"),
+		},
+		{
+			name:       "200",
+			method:     http.MethodGet,
+			query:      "?blocks=2",
+			status:     http.StatusOK,
+			n:          2,
+			feeHistory: history,
+			httpResponse: HTTPResponse{
+				Data: []BlockFeeHistory{
+					{
+						Height:           10,
+						TxCount:          3,
+						TimestampUnix:    1540000000,
+						MinFeePerByte:    0.5,
+						MedianFeePerByte: 1.25,
+						MaxFeePerByte:    4,
+					},
+					{
+						Height:           11,
+						TxCount:          1,
+						TimestampUnix:    1540000010,
+						MinFeePerByte:    2,
+						MedianFeePerByte: 2,
+						MaxFeePerByte:    2,
+					},
+				},
+			},
+		},
+		{
+			name:         "200 - default blocks",
+			method:       http.MethodGet,
+			status:       http.StatusOK,
+			n:            50,
+			httpResponse: HTTPResponse{Data: []BlockFeeHistory{}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetFeeHistory", tc.n).Return(tc.feeHistory, tc.feeHistoryErr)
+
+			req, err := http.NewRequest(tc.method, "/api/v2/fees/history"+tc.query, nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var historyRsp []BlockFeeHistory
+				err := json.Unmarshal(rsp.Data, &historyRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.([]BlockFeeHistory), historyRsp)
+			}
+		})
+	}
+}
+
+func TestBlockchainCapacity(t *testing.T) {
+	capacity := &visor.BlockchainCapacity{
+		MaxBlockSize: 32768,
+		Histogram: visor.BlockSizeHistogram{
+			Buckets: []uint32{1024, 4096},
+			Counts:  []uint64{3, 2, 1},
+			Count:   6,
+			Sum:     12000,
+			FromSeq: 10,
+			ToSeq:   15,
+		},
+	}
+
+	cases := []struct {
+		name         string
+		method       string
+		status       int
+		capacity     *visor.BlockchainCapacity
+		capacityErr  error
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "500 - GetBlockchainCapacity failed",
+			method:       http.MethodGet,
+			status:       http.StatusInternalServerError,
+			capacityErr:  errors.New("capacityErr"),
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "gateway.GetBlockchainCapacity failed: capacityErr"),
+		},
+		{
+			name:     "200",
+			method:   http.MethodGet,
+			status:   http.StatusOK,
+			capacity: capacity,
+			httpResponse: HTTPResponse{
+				Data: BlockchainCapacity{
+					MaxBlockSize: 32768,
+					BlockSizes: BlockSizeHistogram{
+						Blocks:     6,
+						TotalSize:  12000,
+						FromHeight: 10,
+						ToHeight:   15,
+						Buckets: []BlockSizeBucket{
+							{
+								MaxSize: 1024,
+								Blocks:  3,
+							},
+							{
+								MaxSize: 4096,
+								Blocks:  2,
+							},
+						},
+						BlocksAboveLastBucket: 1,
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetBlockchainCapacity").Return(tc.capacity, tc.capacityErr)
+
+			req, err := http.NewRequest(tc.method, "/api/v2/blockchain/capacity", nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var capacityRsp BlockchainCapacity
+				err := json.Unmarshal(rsp.Data, &capacityRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(BlockchainCapacity), capacityRsp)
+			}
+		})
+	}
+}
@@ -575,6 +575,81 @@ func (c *Client) BlockchainProgress() (*readable.BlockchainProgress, error) {
 	return &b, nil
 }
 
+// BlockchainStats makes a request to GET /api/v2/blockchain/stats
+func (c *Client) BlockchainStats(start, end uint64) ([]BlockStats, error) {
+	v := url.Values{}
+	v.Add("start", fmt.Sprint(start))
+	v.Add("end", fmt.Sprint(end))
+	endpoint := "/api/v2/blockchain/stats?" + v.Encode()
+
+	var rsp []BlockStats
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return rsp, err
+	}
+
+	return nil, err
+}
+
+// BlockchainCapacity makes a request to GET /api/v2/blockchain/capacity
+func (c *Client) BlockchainCapacity() (*BlockchainCapacity, error) {
+	var rsp BlockchainCapacity
+	ok, err := c.GetV2("/api/v2/blockchain/capacity", &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// FeeHistory makes a request to GET /api/v2/fees/history
+func (c *Client) FeeHistory(blocks uint64) ([]BlockFeeHistory, error) {
+	v := url.Values{}
+	v.Add("blocks", fmt.Sprint(blocks))
+	endpoint := "/api/v2/fees/history?" + v.Encode()
+
+	var rsp []BlockFeeHistory
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return rsp, err
+	}
+
+	return nil, err
+}
+
+// Events makes a request to GET /api/v2/events.
+// typ is the event type to filter by, or empty for all events. If limit is 0, the server's default is used.
+func (c *Client) Events(since uint64, typ string, limit uint64) (*EventsResponse, error) {
+	v := url.Values{}
+	v.Add("since", fmt.Sprint(since))
+	if typ != "" {
+		v.Add("type", typ)
+	}
+	if limit != 0 {
+		v.Add("limit", fmt.Sprint(limit))
+	}
+	endpoint := "/api/v2/events?" + v.Encode()
+
+	var rsp EventsResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// BlockchainParams makes a request to GET /api/v2/blockchain/params
+func (c *Client) BlockchainParams() (*BlockchainParams, error) {
+	var rsp BlockchainParams
+	ok, err := c.GetV2("/api/v2/blockchain/params", &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
 // Balance makes a request to POST /api/v1/balance?addrs=xxx
 func (c *Client) Balance(addrs []string) (*BalanceResponse, error) {
 	v := url.Values{}
@@ -747,6 +822,7 @@ type CreateTransactionRequest struct {
 	To                []Receiver     `json:"to"`
 	UxOuts            []string       `json:"unspents,omitempty"`
 	Addresses         []string       `json:"addresses,omitempty"`
+	SortTx            bool           `json:"sort_tx"`
 }
 
 // HoursSelection defines options for hours distribution
@@ -768,6 +844,8 @@ type WalletCreateTransactionRequest struct {
 	Unsigned bool   `json:"unsigned"`
 	WalletID string `json:"wallet_id"`
 	Password string `json:"password"`
+	// PIN of the wallet session, sent instead of the password
+	PIN string `json:"pin,omitempty"`
 	CreateTransactionRequest
 }
 
@@ -962,6 +1040,50 @@ func (c *Client) NetworkExchangedPeers() ([]string, error) {
 	return dc, nil
 }
 
+// NetworkGraph makes a request to GET /api/v2/network/graph
+func (c *Client) NetworkGraph() (*NetworkGraph, error) {
+	var rsp NetworkGraph
+	ok, err := c.GetV2("/api/v2/network/graph", &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// DBStats makes a request to GET /api/v2/node/dbstats
+func (c *Client) DBStats() (*DBStats, error) {
+	var rsp DBStats
+	ok, err := c.GetV2("/api/v2/node/dbstats", &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// DBCorruption makes a request to GET /api/v2/node/dbcorruption
+func (c *Client) DBCorruption() ([]CorruptionDescription, error) {
+	var rsp []CorruptionDescription
+	ok, err := c.GetV2("/api/v2/node/dbcorruption", &rsp)
+	if ok {
+		return rsp, err
+	}
+
+	return nil, err
+}
+
+// MempoolStats makes a request to GET /api/v2/node/mempoolstats
+func (c *Client) MempoolStats() (*MempoolStats, error) {
+	var rsp MempoolStats
+	ok, err := c.GetV2("/api/v2/node/mempoolstats", &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
 // PendingTransactions makes a request to GET /api/v1/pendingTxs
 func (c *Client) PendingTransactions() ([]readable.UnconfirmedTransactions, error) {
 	var v []readable.UnconfirmedTransactions
@@ -1185,6 +1307,17 @@ func (c *Client) VerifyTransaction(req VerifyTransactionRequest) (*VerifyTransac
 	return nil, err
 }
 
+// ValidateTransaction makes a request to POST /api/v2/transaction/validate.
+func (c *Client) ValidateTransaction(req VerifyTransactionRequest) (*ValidateTransactionResponse, error) {
+	var rsp ValidateTransactionResponse
+	ok, err := c.PostJSONV2("/api/v2/transaction/validate", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
 // VerifyAddress makes a request to POST /api/v2/address/verify
 // The API may respond with an error but include data useful for processing,
 // so both return values may be non-nil.
@@ -1202,6 +1335,64 @@ func (c *Client) VerifyAddress(addr string) (*VerifyAddressResponse, error) {
 	return nil, err
 }
 
+// ValidateAddress makes a request to POST /api/v2/crypto/validate_address
+func (c *Client) ValidateAddress(addr string) (*ValidateAddressResponse, error) {
+	req := ValidateAddressRequest{
+		Address: addr,
+	}
+
+	var rsp ValidateAddressResponse
+	ok, err := c.PostJSONV2("/api/v2/crypto/validate_address", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// ProjectedCoinHours makes a request to GET /api/v2/address/{addr}/projected_coin_hours
+func (c *Client) ProjectedCoinHours(addr string, atBlock uint64) (*ProjectedCoinHoursResponse, error) {
+	v := url.Values{}
+	v.Add("at_block", fmt.Sprint(atBlock))
+	endpoint := fmt.Sprintf("/api/v2/address/%s/projected_coin_hours?%s", url.PathEscape(addr), v.Encode())
+
+	var rsp ProjectedCoinHoursResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// AddressBalanceAt makes a request to GET /api/v2/address/{addr}/balance_at
+func (c *Client) AddressBalanceAt(addr string, height uint64) (*AddressBalanceAtResponse, error) {
+	v := url.Values{}
+	v.Add("height", fmt.Sprint(height))
+	endpoint := fmt.Sprintf("/api/v2/address/%s/balance_at?%s", url.PathEscape(addr), v.Encode())
+
+	var rsp AddressBalanceAtResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// AddressTransactionCount makes a request to GET /api/v2/address/{addr}/transaction_count
+func (c *Client) AddressTransactionCount(addr string) (*AddressTransactionCountResponse, error) {
+	endpoint := fmt.Sprintf("/api/v2/address/%s/transaction_count", url.PathEscape(addr))
+
+	var rsp AddressTransactionCountResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
 // RichlistParams are arguments to the /richlist endpoint
 type RichlistParams struct {
 	N                   int
@@ -1226,6 +1417,26 @@ func (c *Client) Richlist(params *RichlistParams) (*Richlist, error) {
 	return &r, nil
 }
 
+// BlockchainRichlist makes a request to GET /api/v2/blockchain/richlist
+func (c *Client) BlockchainRichlist(params *RichlistParams) (*Richlist, error) {
+	endpoint := "/api/v2/blockchain/richlist"
+
+	if params != nil {
+		v := url.Values{}
+		v.Add("n", fmt.Sprint(params.N))
+		v.Add("include_distribution", fmt.Sprint(params.IncludeDistribution))
+		endpoint = "/api/v2/blockchain/richlist?" + v.Encode()
+	}
+
+	var rsp Richlist
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
 // AddressCount makes a request to GET /api/v1/addresscount
 func (c *Client) AddressCount() (uint64, error) {
 	var r struct {
@@ -1281,6 +1492,20 @@ func (c *Client) DecryptWallet(id, password string) (*WalletResponse, error) {
 	return &wlt, nil
 }
 
+// ChangeWalletPassword makes a request to POST /api/v1/wallet/password to change the password of an encrypted wallet
+func (c *Client) ChangeWalletPassword(id, password, newPassword string) (*WalletResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	v.Add("password", password)
+	v.Add("new_password", newPassword)
+	var wlt WalletResponse
+	if err := c.PostForm("/api/v1/wallet/password", strings.NewReader(v.Encode()), &wlt); err != nil {
+		return nil, err
+	}
+
+	return &wlt, nil
+}
+
 // RecoverWallet makes a request to POST /api/v2/wallet/recover to recover an encrypted wallet by seed.
 // The password argument is optional, if provided, the recovered wallet will be encrypted with this password,
 // otherwise the recovered wallet will be unencrypted.
@@ -1294,6 +1519,94 @@ func (c *Client) RecoverWallet(req WalletRecoverRequest) (*WalletResponse, error
 	return nil, err
 }
 
+// WalletMeta makes a request to GET /api/v2/wallet/{id}/meta
+func (c *Client) WalletMeta(id string) (*WalletMetaResponse, error) {
+	endpoint := fmt.Sprintf("/api/v2/wallet/%s/meta", url.PathEscape(id))
+
+	var rsp WalletMetaResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// UpdateWalletMeta makes a request to POST /api/v2/wallet/{id}/meta
+func (c *Client) UpdateWalletMeta(id string, req WalletMetaRequest) (*WalletMetaResponse, error) {
+	endpoint := fmt.Sprintf("/api/v2/wallet/%s/meta", url.PathEscape(id))
+
+	var rsp WalletMetaResponse
+	ok, err := c.PostJSONV2(endpoint, req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// WalletAddressStats makes a request to GET /api/v2/wallet/{id}/address_stats
+func (c *Client) WalletAddressStats(id string) (*WalletAddressStatsResponse, error) {
+	endpoint := fmt.Sprintf("/api/v2/wallet/%s/address_stats", url.PathEscape(id))
+
+	var rsp WalletAddressStatsResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// WalletConsolidate makes a request to POST /api/v2/wallet/{id}/consolidate.
+// If maxInputs is 0, as many outputs as fit in a transaction are consolidated.
+func (c *Client) WalletConsolidate(id, addr string, maxInputs int, req WalletConsolidateRequest) (*CreateTransactionResponse, error) {
+	v := url.Values{}
+	v.Add("address", addr)
+	if maxInputs != 0 {
+		v.Add("max_inputs", fmt.Sprint(maxInputs))
+	}
+	endpoint := fmt.Sprintf("/api/v2/wallet/%s/consolidate?%s", url.PathEscape(id), v.Encode())
+
+	var rsp CreateTransactionResponse
+	ok, err := c.PostJSONV2(endpoint, req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// WalletCoinJoin makes a request to POST /api/v2/wallet/{id}/coinjoin
+func (c *Client) WalletCoinJoin(id string, req WalletCoinJoinRequest) error {
+	endpoint := fmt.Sprintf("/api/v2/wallet/%s/coinjoin", url.PathEscape(id))
+	_, err := c.PostJSONV2(endpoint, req, nil)
+	return err
+}
+
+// StartWalletSession makes a request to POST /api/v2/wallet/{id}/session
+func (c *Client) StartWalletSession(id, password, pin string) (*WalletSessionResponse, error) {
+	endpoint := fmt.Sprintf("/api/v2/wallet/%s/session", url.PathEscape(id))
+
+	var rsp WalletSessionResponse
+	ok, err := c.PostJSONV2(endpoint, WalletSessionRequest{
+		Password: password,
+		PIN:      pin,
+	}, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// EndWalletSession makes a request to DELETE /api/v2/wallet/{id}/session
+func (c *Client) EndWalletSession(id string) error {
+	endpoint := fmt.Sprintf("/api/v2/wallet/%s/session", url.PathEscape(id))
+	_, err := c.DeleteV2(endpoint, nil)
+	return err
+}
+
 // Disconnect disconnect a connections by ID
 func (c *Client) Disconnect(id uint64) error {
 	v := url.Values{}
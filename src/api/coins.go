@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/util/droplet"
+)
+
+// ErrInvalidDropletFactor is returned if a droplet factor is not a power of 10
+var ErrInvalidDropletFactor = errors.New("droplet factor must be a power of 10")
+
+// dropletFactorDecimals returns the number of decimal places of a coin amount with a droplet factor
+func dropletFactorDecimals(factor uint64) (int32, error) {
+	if factor == 0 {
+		return 0, ErrInvalidDropletFactor
+	}
+
+	var n int32
+	for ; factor > 1; factor /= 10 {
+		if factor%10 != 0 {
+			return 0, ErrInvalidDropletFactor
+		}
+		n++
+	}
+
+	return n, nil
+}
+
+// DropletsToCoins converts droplets to a fixed-point decimal coin amount string,
+// with as many decimal places as the droplet factor has zeros.
+// For example, with a factor of 1e6, 123000456 becomes "123.000456" and 123000000 becomes "123.000000".
+// Panics if the factor is not a power of 10.
+func DropletsToCoins(droplets, factor uint64) string {
+	n, err := dropletFactorDecimals(factor)
+	if err != nil {
+		panic(err)
+	}
+
+	if n == 0 {
+		return strconv.FormatUint(droplets, 10)
+	}
+
+	return fmt.Sprintf("%d.%0*d", droplets/factor, n, droplets%factor)
+}
+
+// CoinsToDroplets converts a decimal coin amount string to droplets, using the droplet factor.
+// For example, with a factor of 1e6, "123.000456" becomes 123000456.
+func CoinsToDroplets(coins string, factor uint64) (uint64, error) {
+	n, err := dropletFactorDecimals(factor)
+	if err != nil {
+		return 0, err
+	}
+
+	d, err := decimal.NewFromString(coins)
+	if err != nil {
+		return 0, err
+	}
+
+	if d.Sign() == -1 {
+		return 0, droplet.ErrNegativeValue
+	}
+
+	if d.Exponent() < -n {
+		return 0, droplet.ErrTooManyDecimals
+	}
+
+	// The droplet amount is coefficient * 10^exponent, where the exponent is not negative
+	d = d.Shift(n)
+	b := d.Coefficient()
+	if e := d.Exponent(); e > 0 {
+		b.Mul(b, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(e)), nil))
+	}
+
+	if !b.IsUint64() {
+		return 0, droplet.ErrTooLarge
+	}
+
+	return b.Uint64(), nil
+}
+
+// BlockchainParams are the coin amount parameters of the blockchain, returned by GET /api/v2/blockchain/params
+type BlockchainParams struct {
+	// DropletFactor is the number of droplets in one coin
+	DropletFactor uint64 `json:"droplet_factor"`
+	// MaxDecimals is the maximum number of decimal places of coin amounts in user created transactions
+	MaxDecimals uint8 `json:"max_decimals"`
+	// DustThreshold is the minimum coins of an output of a transaction created by the node, 0 if there is no threshold
+	DustThreshold string `json:"dust_threshold"`
+	// DustPolicy is how a change output below the dust threshold is handled when creating transactions
+	DustPolicy string `json:"dust_policy"`
+	// MinFeePerByte is the minimum coin hour fee per byte of the size of a transaction created by the node, 0 if there is no minimum
+	MinFeePerByte uint64 `json:"min_fee_per_byte"`
+}
+
+// blockchainParamsHandler returns the parameters needed to display and parse coin amounts.
+// Coin amounts in API responses are droplets divided by droplet_factor.
+// Method: GET
+// URI: /api/v2/blockchain/params
+func blockchainParamsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		c := gateway.VisorConfig()
+		dustThreshold, err := droplet.ToString(c.DustThreshold)
+		if err != nil {
+			writeError500Response(w, err.Error())
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: BlockchainParams{
+				DropletFactor: droplet.Multiplier,
+				MaxDecimals:   params.UserVerifyTxn.MaxDropletPrecision,
+				DustThreshold: dustThreshold,
+				DustPolicy:    string(c.DustPolicy),
+				MinFeePerByte: params.UserVerifyTxn.MinFeePerByte,
+			},
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func TestDropletsToCoins(t *testing.T) {
+	cases := []struct {
+		droplets uint64
+		factor   uint64
+		coins    string
+	}{
+		{0, 1e6, "0.000000"},
+		{1, 1e6, "0.000001"},
+		{123000456, 1e6, "123.000456"},
+		{123000000, 1e6, "123.000000"},
+		{123000456, 1e8, "1.23000456"},
+		{123, 1, "123"},
+		{math.MaxUint64, 1e6, "18446744073709.551615"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.coins, func(t *testing.T) {
+			require.Equal(t, tc.coins, DropletsToCoins(tc.droplets, tc.factor))
+
+			// The droplet package uses the same format
+			if tc.factor == droplet.Multiplier && tc.droplets <= math.MaxInt64 {
+				s, err := droplet.ToString(tc.droplets)
+				require.NoError(t, err)
+				require.Equal(t, s, tc.coins)
+			}
+
+			droplets, err := CoinsToDroplets(tc.coins, tc.factor)
+			require.NoError(t, err)
+			require.Equal(t, tc.droplets, droplets)
+		})
+	}
+
+	require.Panics(t, func() {
+		DropletsToCoins(1, 0)
+	})
+	require.Panics(t, func() {
+		DropletsToCoins(1, 15)
+	})
+}
+
+func TestCoinsToDroplets(t *testing.T) {
+	cases := []struct {
+		coins    string
+		factor   uint64
+		droplets uint64
+		err      error
+	}{
+		{coins: "1", factor: 1e6, droplets: 1e6},
+		{coins: "1.5", factor: 1e6, droplets: 15e5},
+		{coins: "0.000001", factor: 1e6, droplets: 1},
+		{coins: "0.0000001", factor: 1e6, err: droplet.ErrTooManyDecimals},
+		{coins: "0.0000001", factor: 1e8, droplets: 10},
+		{coins: "-1", factor: 1e6, err: droplet.ErrNegativeValue},
+		{coins: "18446744073709.551616", factor: 1e6, err: droplet.ErrTooLarge},
+		{coins: "1e100", factor: 1e6, err: droplet.ErrTooLarge},
+		{coins: "1e2", factor: 1e6, droplets: 1e8},
+		{coins: "1", factor: 20, err: ErrInvalidDropletFactor},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.coins, func(t *testing.T) {
+			droplets, err := CoinsToDroplets(tc.coins, tc.factor)
+			if tc.err != nil {
+				require.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.droplets, droplets)
+		})
+	}
+
+	_, err := CoinsToDroplets("foo", 1e6)
+	require.Error(t, err)
+}
+
+func TestBlockchainParams(t *testing.T) {
+	cases := []struct {
+		name   string
+		config visor.Config
+		params BlockchainParams
+	}{
+		{
+			name:   "no dust threshold",
+			config: visor.NewConfig(),
+			params: BlockchainParams{
+				DropletFactor: 1e6,
+				MaxDecimals:   params.UserVerifyTxn.MaxDropletPrecision,
+				DustThreshold: "0.000000",
+				DustPolicy:    "reject",
+				MinFeePerByte: params.UserVerifyTxn.MinFeePerByte,
+			},
+		},
+		{
+			name: "dust threshold",
+			config: visor.Config{
+				DustThreshold: 1e3,
+				DustPolicy:    transaction.DustPolicyMerge,
+			},
+			params: BlockchainParams{
+				DropletFactor: 1e6,
+				MaxDecimals:   params.UserVerifyTxn.MaxDropletPrecision,
+				DustThreshold: "0.001000",
+				DustPolicy:    "merge",
+				MinFeePerByte: params.UserVerifyTxn.MinFeePerByte,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("VisorConfig").Return(tc.config)
+
+			req, err := http.NewRequest(http.MethodGet, "/api/v2/blockchain/params", nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+			require.Nil(t, rsp.Error)
+
+			var p BlockchainParams
+			err = json.Unmarshal(rsp.Data, &p)
+			require.NoError(t, err)
+			require.Equal(t, tc.params, p)
+		})
+	}
+}
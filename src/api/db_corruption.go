@@ -0,0 +1,96 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+// CorruptionDescription is a corrupted entry of the node's database
+type CorruptionDescription struct {
+	// Bucket is the name of the bucket of the entry, empty if unknown
+	Bucket string `json:"bucket"`
+	// Key is the hex hash, or the address for the address index buckets, of the entry. Empty if unknown
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+func newCorruptionDescriptions(descs []visor.CorruptionDescription) []CorruptionDescription {
+	ds := make([]CorruptionDescription, len(descs))
+	for i, d := range descs {
+		ds[i] = CorruptionDescription{
+			Bucket: d.Bucket,
+			Key:    d.Key,
+			Error:  d.Err.Error(),
+		}
+	}
+	return ds
+}
+
+// DBCorruptionCheck is the state of a database corruption check
+type DBCorruptionCheck struct {
+	Running   bool  `json:"running"`
+	StartedAt int64 `json:"started_at"`
+	// FinishedAt is 0 while the check is running
+	FinishedAt int64 `json:"finished_at"`
+	// Corruptions are the corrupted entries found by the check, empty while the check is running
+	// or if the database is not corrupted
+	Corruptions []CorruptionDescription `json:"corruptions"`
+	// Error is the error that stopped the check before it completed
+	Error string `json:"error,omitempty"`
+}
+
+func newDBCorruptionCheck(c *visor.CorruptionCheck) DBCorruptionCheck {
+	check := DBCorruptionCheck{
+		Running:     c.Running,
+		StartedAt:   c.StartedAt.Unix(),
+		Corruptions: newCorruptionDescriptions(c.Descriptions),
+	}
+	if !c.FinishedAt.IsZero() {
+		check.FinishedAt = c.FinishedAt.Unix()
+	}
+	if c.Err != nil {
+		check.Error = c.Err.Error()
+	}
+	return check
+}
+
+// dbCorruptionHandler starts a check of the database, or returns the state of the last check.
+// The check looks for the corrupted entries that would cause the database to be reset with -reset-corrupt-db,
+// without resetting it. It verifies all block signatures and can take several minutes, so it runs in the
+// background on a snapshot of the database: POST starts the check and GET polls it.
+// Only one check runs at a time, a 503 is returned by POST if a check is already running.
+// GET returns a 404 if no check was started
+// Method: GET, POST
+// URI: /api/v2/node/dbcorruption
+func dbCorruptionHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			if err := gateway.StartCorruptionCheck(); err != nil {
+				switch err {
+				case visor.ErrCorruptionCheckRunning:
+					writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusServiceUnavailable, err.Error()))
+				default:
+					writeError500Response(w, fmt.Sprintf("gateway.StartCorruptionCheck failed: %v", err))
+				}
+				return
+			}
+		default:
+			writeError405Response(w)
+			return
+		}
+
+		c := gateway.GetCorruptionCheck()
+		if c == nil {
+			writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusNotFound, "no database corruption check was started"))
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: newDBCorruptionCheck(c),
+		})
+	}
+}
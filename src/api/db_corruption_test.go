@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func TestDBCorruption(t *testing.T) {
+	startedAt := time.Unix(1570000000, 0).UTC()
+	finishedAt := time.Unix(1570000300, 0).UTC()
+
+	cases := []struct {
+		name         string
+		method       string
+		status       int
+		startErr     error
+		check        *visor.CorruptionCheck
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPut,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "500 - gateway.StartCorruptionCheck error",
+			method:       http.MethodPost,
+			status:       http.StatusInternalServerError,
+			startErr:     errors.New("StartCorruptionCheck failed"),
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "gateway.StartCorruptionCheck failed: StartCorruptionCheck failed"),
+		},
+		{
+			name:         "503 - check already running",
+			method:       http.MethodPost,
+			status:       http.StatusServiceUnavailable,
+			startErr:     visor.ErrCorruptionCheckRunning,
+			httpResponse: NewHTTPErrorResponse(http.StatusServiceUnavailable, visor.ErrCorruptionCheckRunning.Error()),
+		},
+		{
+			name:         "404 - no check started",
+			method:       http.MethodGet,
+			status:       http.StatusNotFound,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, "no database corruption check was started"),
+		},
+		{
+			name:   "200 - check started",
+			method: http.MethodPost,
+			status: http.StatusOK,
+			check: &visor.CorruptionCheck{
+				Running:   true,
+				StartedAt: startedAt,
+			},
+			httpResponse: HTTPResponse{
+				Data: DBCorruptionCheck{
+					Running:     true,
+					StartedAt:   startedAt.Unix(),
+					Corruptions: []CorruptionDescription{},
+				},
+			},
+		},
+		{
+			name:   "200 - not corrupted",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			check: &visor.CorruptionCheck{
+				StartedAt:  startedAt,
+				FinishedAt: finishedAt,
+			},
+			httpResponse: HTTPResponse{
+				Data: DBCorruptionCheck{
+					StartedAt:   startedAt.Unix(),
+					FinishedAt:  finishedAt.Unix(),
+					Corruptions: []CorruptionDescription{},
+				},
+			},
+		},
+		{
+			name:   "200 - corrupted",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			check: &visor.CorruptionCheck{
+				StartedAt:  startedAt,
+				FinishedAt: finishedAt,
+				Descriptions: []visor.CorruptionDescription{
+					{
+						Bucket: "block_sigs",
+						Key:    "98db7eb30e13853d3dd93d5d8b4061596d5d288b6f8b92c4d43c46c6599f67fb",
+						Err:    errors.New("Signature not found for block seq=3"),
+					},
+				},
+			},
+			httpResponse: HTTPResponse{
+				Data: DBCorruptionCheck{
+					StartedAt:  startedAt.Unix(),
+					FinishedAt: finishedAt.Unix(),
+					Corruptions: []CorruptionDescription{
+						{
+							Bucket: "block_sigs",
+							Key:    "98db7eb30e13853d3dd93d5d8b4061596d5d288b6f8b92c4d43c46c6599f67fb",
+							Error:  "Signature not found for block seq=3",
+						},
+					},
+				},
+			},
+		},
+		{
+			name:   "200 - check failed",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			check: &visor.CorruptionCheck{
+				StartedAt:  startedAt,
+				FinishedAt: finishedAt,
+				Err:        visor.ErrVerifyStopped,
+			},
+			httpResponse: HTTPResponse{
+				Data: DBCorruptionCheck{
+					StartedAt:   startedAt.Unix(),
+					FinishedAt:  finishedAt.Unix(),
+					Corruptions: []CorruptionDescription{},
+					Error:       visor.ErrVerifyStopped.Error(),
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("StartCorruptionCheck").Return(tc.startErr)
+			gateway.On("GetCorruptionCheck").Return(tc.check)
+
+			req, err := http.NewRequest(tc.method, "/api/v2/node/dbcorruption", nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var checkRsp DBCorruptionCheck
+				err := json.Unmarshal(rsp.Data, &checkRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(DBCorruptionCheck), checkRsp)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// DBStats are the internal statistics of the node's database
+type DBStats struct {
+	// Size of a page of the database file, in bytes
+	PageSize int `json:"page_size"`
+	// Size of the database, in bytes
+	Size      int64 `json:"size"`
+	PageCount int64 `json:"page_count"`
+	// Number of free pages, and of pages that will be free once the open read transactions are closed
+	FreePageCount    int `json:"free_page_count"`
+	PendingPageCount int `json:"pending_page_count"`
+	// Percentage of the pages that are free or pending
+	FreedPagesPct float64 `json:"freed_pages_pct"`
+	// Bytes allocated in the free pages
+	FreeAlloc int `json:"free_alloc"`
+	// Size of the freelist, in bytes
+	FreelistInuse int `json:"freelist_inuse"`
+	// Number of transactions since the database was opened
+	TxCount         uint64 `json:"tx_count"`
+	ReadTxCount     int    `json:"read_tx_count"`
+	OpenReadTxCount int    `json:"open_read_tx_count"`
+	// Size of the pages allocated by the write transactions since the database was opened, in bytes
+	BytesWritten uint64 `json:"bytes_written"`
+	// Number of writes to the database file
+	WriteCount     int    `json:"write_count"`
+	WriteTime      string `json:"write_time"`
+	RebalanceCount int    `json:"rebalance_count"`
+	RebalanceTime  string `json:"rebalance_time"`
+	SplitCount     int    `json:"split_count"`
+	SpillCount     int    `json:"spill_count"`
+	SpillTime      string `json:"spill_time"`
+}
+
+func newDBStats(s dbutil.DBStats) DBStats {
+	return DBStats{
+		PageSize:         s.PageSize,
+		Size:             s.Size,
+		PageCount:        s.PageCount,
+		FreePageCount:    s.FreePageN,
+		PendingPageCount: s.PendingPageN,
+		FreedPagesPct:    s.FreedPagesPct,
+		FreeAlloc:        s.FreeAlloc,
+		FreelistInuse:    s.FreelistInuse,
+		TxCount:          s.TxCount,
+		ReadTxCount:      s.TxN,
+		OpenReadTxCount:  s.OpenTxN,
+		BytesWritten:     s.BytesWritten,
+		WriteCount:       s.TxStats.Write,
+		WriteTime:        s.TxStats.WriteTime.String(),
+		RebalanceCount:   s.TxStats.Rebalance,
+		RebalanceTime:    s.TxStats.RebalanceTime.String(),
+		SplitCount:       s.TxStats.Split,
+		SpillCount:       s.TxStats.Spill,
+		SpillTime:        s.TxStats.SpillTime.String(),
+	}
+}
+
+// dbStatsHandler returns the internal statistics of the database, to monitor its size and fragmentation
+// Method: GET
+// URI: /api/v2/node/dbstats
+func dbStatsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		stats, err := gateway.GetDBStats()
+		if err != nil {
+			writeError500Response(w, fmt.Sprintf("gateway.GetDBStats failed: %v", err))
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: newDBStats(*stats),
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestDBStats(t *testing.T) {
+	stats := &dbutil.DBStats{
+		Stats: bolt.Stats{
+			FreePageN:     10,
+			PendingPageN:  2,
+			FreeAlloc:     40960,
+			FreelistInuse: 64,
+			TxN:           1000,
+			OpenTxN:       1,
+			TxStats: bolt.TxStats{
+				PageAlloc:     1024000,
+				Rebalance:     3,
+				RebalanceTime: time.Millisecond,
+				Split:         4,
+				Spill:         5,
+				SpillTime:     time.Second,
+				Write:         300,
+				WriteTime:     time.Second * 2,
+			},
+		},
+		PageSize:      4096,
+		Size:          409600,
+		PageCount:     100,
+		FreedPagesPct: 12,
+		TxCount:       1100,
+		BytesWritten:  1024000,
+	}
+
+	cases := []struct {
+		name         string
+		method       string
+		status       int
+		stats        *dbutil.DBStats
+		err          error
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "500 - gateway.GetDBStats error",
+			method:       http.MethodGet,
+			status:       http.StatusInternalServerError,
+			err:          errors.New("GetDBStats failed"),
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "gateway.GetDBStats failed: GetDBStats failed"),
+		},
+		{
+			name:   "200",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			stats:  stats,
+			httpResponse: HTTPResponse{
+				Data: DBStats{
+					PageSize:         4096,
+					Size:             409600,
+					PageCount:        100,
+					FreePageCount:    10,
+					PendingPageCount: 2,
+					FreedPagesPct:    12,
+					FreeAlloc:        40960,
+					FreelistInuse:    64,
+					TxCount:          1100,
+					ReadTxCount:      1000,
+					OpenReadTxCount:  1,
+					BytesWritten:     1024000,
+					WriteCount:       300,
+					WriteTime:        "2s",
+					RebalanceCount:   3,
+					RebalanceTime:    "1ms",
+					SplitCount:       4,
+					SpillCount:       5,
+					SpillTime:        "1s",
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetDBStats").Return(tc.stats, tc.err)
+
+			req, err := http.NewRequest(tc.method, "/api/v2/node/dbstats", nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var statsRsp DBStats
+				err := json.Unmarshal(rsp.Data, &statsRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(DBStats), statsRsp)
+			}
+		})
+	}
+}
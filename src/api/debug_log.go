@@ -0,0 +1,159 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// debugLogMaxBodySize is the maximum number of bytes of a request or response body written to the debug log
+const debugLogMaxBodySize = 64 * 1024
+
+// debugLogEntry is a request and its response, written to the debug log as a line of JSON
+type debugLogEntry struct {
+	ID       uint64           `json:"id"`
+	Time     time.Time        `json:"time"`
+	Duration string           `json:"duration"`
+	Request  debugLogRequest  `json:"request"`
+	Response debugLogResponse `json:"response"`
+}
+
+type debugLogRequest struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	RemoteAddr string      `json:"remote_addr"`
+	Headers    http.Header `json:"headers"`
+	Body       string      `json:"body"`
+	// Truncated is true if only the first debugLogMaxBodySize bytes of the body are logged
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+type debugLogResponse struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers"`
+	Body    string      `json:"body"`
+	// Size is the length of the whole body, which is larger than the logged body if it was truncated
+	Size      int  `json:"size"`
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// debugLogger writes the full requests and responses of the API to a writer, to reproduce bugs.
+// The requests are numbered in the order they are received, the number is the entry's ID.
+type debugLogger struct {
+	sync.Mutex
+	w      io.Writer
+	lastID uint64
+}
+
+func newDebugLogger(w io.Writer) *debugLogger {
+	return &debugLogger{
+		w: w,
+	}
+}
+
+// handler logs the request and response of every request served by handler.
+// The Authorization header is not logged.
+func (l *debugLogger) handler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := atomic.AddUint64(&l.lastID, 1)
+		start := time.Now()
+
+		headers := r.Header.Clone()
+		headers.Del("Authorization")
+
+		entry := debugLogEntry{
+			ID:   id,
+			Time: start.UTC(),
+			Request: debugLogRequest{
+				Method:     r.Method,
+				URL:        r.URL.String(),
+				RemoteAddr: r.RemoteAddr,
+				Headers:    headers,
+			},
+		}
+
+		// Read the start of the body and put it back in front of the rest for the handler
+		if r.Body != nil {
+			body, err := ioutil.ReadAll(io.LimitReader(r.Body, debugLogMaxBodySize+1))
+			if err != nil {
+				logger.WithError(err).Error("API debug log: reading the request body failed")
+			}
+
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{
+				Reader: io.MultiReader(bytes.NewReader(body), r.Body),
+				Closer: r.Body,
+			}
+
+			if len(body) > debugLogMaxBodySize {
+				body = body[:debugLogMaxBodySize]
+				entry.Request.Truncated = true
+			}
+			entry.Request.Body = string(body)
+		}
+
+		dw := &debugLogResponseWriter{
+			ResponseWriter: w,
+			status:         http.StatusOK,
+		}
+		handler.ServeHTTP(dw, r)
+
+		entry.Duration = time.Since(start).String()
+		entry.Response = debugLogResponse{
+			Status:    dw.status,
+			Headers:   w.Header().Clone(),
+			Body:      dw.body.String(),
+			Size:      dw.size,
+			Truncated: dw.size > dw.body.Len(),
+		}
+
+		l.write(entry)
+	})
+}
+
+func (l *debugLogger) write(entry debugLogEntry) {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		logger.WithError(err).Error("API debug log: json.Marshal failed")
+		return
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	if _, err := l.w.Write(append(b, '\n')); err != nil {
+		logger.WithError(err).Error("API debug log: write failed")
+	}
+}
+
+// debugLogResponseWriter records the status and the start of the body of a response
+type debugLogResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+	size   int
+}
+
+func (w *debugLogResponseWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *debugLogResponseWriter) Write(b []byte) (int, error) {
+	if n := debugLogMaxBodySize - w.body.Len(); n > 0 {
+		if n > len(b) {
+			n = len(b)
+		}
+		w.body.Write(b[:n])
+	}
+	w.size += len(b)
+
+	return w.ResponseWriter.Write(b)
+}
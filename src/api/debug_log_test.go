@@ -0,0 +1,108 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readDebugLog(t *testing.T, b *bytes.Buffer) []debugLogEntry {
+	var entries []debugLogEntry
+	s := bufio.NewScanner(b)
+	s.Buffer(nil, 1024*1024)
+	for s.Scan() {
+		var e debugLogEntry
+		require.NoError(t, json.Unmarshal(s.Bytes(), &e))
+		entries = append(entries, e)
+	}
+	require.NoError(t, s.Err())
+	return entries
+}
+
+func TestDebugLog(t *testing.T) {
+	var log bytes.Buffer
+	c := defaultMuxConfig()
+	c.username = "foo"
+	c.password = "bar"
+	c.debugLog = &log
+	handler := newServerMux(c, &MockGatewayer{})
+
+	body := `{"address":"2GgFvqoyk9RjwVzj8tqfcXVXB4orBwoc9qv"}`
+	req, err := http.NewRequest(http.MethodPost, "/api/v2/address/verify?x=1", strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", ContentTypeJSON)
+	req.SetBasicAuth("foo", "bar")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// Requests rejected by the middlewares are logged too
+	req, err = http.NewRequest(http.MethodGet, "/api/v1/health", nil)
+	require.NoError(t, err)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	entries := readDebugLog(t, &log)
+	require.Len(t, entries, 2)
+
+	e := entries[0]
+	require.Equal(t, uint64(1), e.ID)
+	require.Equal(t, http.MethodPost, e.Request.Method)
+	require.Equal(t, "/api/v2/address/verify?x=1", e.Request.URL)
+	require.Equal(t, ContentTypeJSON, e.Request.Headers.Get("Content-Type"))
+	require.Empty(t, e.Request.Headers.Get("Authorization"))
+	require.Equal(t, body, e.Request.Body)
+	require.False(t, e.Request.Truncated)
+	require.Equal(t, http.StatusOK, e.Response.Status)
+	require.Equal(t, ContentTypeJSON, e.Response.Headers.Get("Content-Type"))
+	require.Contains(t, e.Response.Body, `"version": 0`)
+	require.Equal(t, len(e.Response.Body), e.Response.Size)
+	require.False(t, e.Response.Truncated)
+
+	e = entries[1]
+	require.Equal(t, uint64(2), e.ID)
+	require.Equal(t, http.StatusUnauthorized, e.Response.Status)
+	require.Empty(t, e.Request.Body)
+}
+
+func TestDebugLogTruncated(t *testing.T) {
+	var log bytes.Buffer
+	l := newDebugLogger(&log)
+
+	// The handler receives the whole request body, only the logged body is truncated
+	handler := l.handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		_, err = w.Write(b)
+		require.NoError(t, err)
+		_, err = w.Write(b)
+		require.NoError(t, err)
+	}))
+
+	body := bytes.Repeat([]byte("a"), debugLogMaxBodySize+10)
+	req, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, append(body, body...), rr.Body.Bytes())
+
+	entries := readDebugLog(t, &log)
+	require.Len(t, entries, 1)
+
+	e := entries[0]
+	require.True(t, e.Request.Truncated)
+	require.Equal(t, string(body[:debugLogMaxBodySize]), e.Request.Body)
+	require.True(t, e.Response.Truncated)
+	require.Equal(t, string(body[:debugLogMaxBodySize]), e.Response.Body)
+	require.Equal(t, len(body)*2, e.Response.Size)
+}
@@ -0,0 +1,171 @@
+package api
+
+// APIs for the event log
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+const (
+	// defaultEventsLimit is the default minimum number of events in a GET /api/v2/events response
+	defaultEventsLimit = 1000
+	// maxEventsLimit is the maximum value of the limit of a GET /api/v2/events request
+	maxEventsLimit = 10000
+)
+
+// EventOutputCreated is the payload of an output_created event
+type EventOutputCreated struct {
+	TxID      string `json:"txid"`
+	OutputIdx uint32 `json:"output_idx"`
+	Address   string `json:"address"`
+	Coins     string `json:"coins"`
+	Hours     uint64 `json:"hours"`
+}
+
+// EventOutputSpent is the payload of an output_spent event.
+// The spent output is the output at source_output_idx of the transaction source_txid
+type EventOutputSpent struct {
+	TxID            string `json:"txid"`
+	InputIdx        uint32 `json:"input_idx"`
+	SourceTxID      string `json:"source_txid"`
+	SourceOutputIdx uint32 `json:"source_output_idx"`
+}
+
+// Event is an event of the event log. Only the payload of the event's type is set
+type Event struct {
+	Seq           uint64              `json:"seq"`
+	Index         uint32              `json:"index"`
+	Type          string              `json:"type"`
+	OutputCreated *EventOutputCreated `json:"output_created,omitempty"`
+	OutputSpent   *EventOutputSpent   `json:"output_spent,omitempty"`
+}
+
+// EventsResponse is returned by GET /api/v2/events
+type EventsResponse struct {
+	Events []Event `json:"events"`
+	// NextSince is the since value to request the next events with
+	NextSince uint64 `json:"next_since"`
+}
+
+func newEventsResponse(events []visor.Event, next uint64) (*EventsResponse, error) {
+	resp := &EventsResponse{
+		Events:    make([]Event, len(events)),
+		NextSince: next,
+	}
+
+	for i, e := range events {
+		re := Event{
+			Seq:   e.Seq,
+			Index: e.Index,
+			Type:  e.Type.String(),
+		}
+
+		switch e.Type {
+		case visor.EventOutputCreated:
+			coins, err := droplet.ToString(e.OutputCreated.Coins)
+			if err != nil {
+				return nil, err
+			}
+
+			re.OutputCreated = &EventOutputCreated{
+				TxID:      e.OutputCreated.TxID.Hex(),
+				OutputIdx: e.OutputCreated.OutputIdx,
+				Address:   e.OutputCreated.Address.String(),
+				Coins:     coins,
+				Hours:     e.OutputCreated.CoinHours,
+			}
+		case visor.EventOutputSpent:
+			re.OutputSpent = &EventOutputSpent{
+				TxID:            e.OutputSpent.TxID.Hex(),
+				InputIdx:        e.OutputSpent.InputIdx,
+				SourceTxID:      e.OutputSpent.SourceTxID.Hex(),
+				SourceOutputIdx: e.OutputSpent.SourceOutputIdx,
+			}
+		default:
+			return nil, fmt.Errorf("invalid event type %v", e.Type)
+		}
+
+		resp.Events[i] = re
+	}
+
+	return resp, nil
+}
+
+// eventsHandler returns the outputs created and spent by the blocks from seq since, in the order they happened.
+// The events of whole blocks are returned until at least limit events are returned.
+// Requires the event log to be enabled with -enable-event-log.
+// Method: GET
+// URI: /api/v2/events
+// Args:
+//	since [int, optional]. Seq of the first block, defaults to 0
+//	type [string, optional]. Only return events of this type, "output_created" or "output_spent"
+//	limit [int, optional]. Minimum number of events to return, defaults to 1000. At most 10000
+func eventsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		var since uint64
+		if s := r.FormValue("since"); s != "" {
+			var err error
+			since, err = strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				writeError400Response(w, fmt.Sprintf("invalid 'since' value: %v", err))
+				return
+			}
+		}
+
+		var typ visor.EventType
+		if s := r.FormValue("type"); s != "" {
+			var err error
+			typ, err = visor.ParseEventType(s)
+			if err != nil {
+				writeError400Response(w, err.Error())
+				return
+			}
+		}
+
+		limit := uint64(defaultEventsLimit)
+		if s := r.FormValue("limit"); s != "" {
+			var err error
+			limit, err = strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				writeError400Response(w, fmt.Sprintf("invalid 'limit' value: %v", err))
+				return
+			}
+		}
+
+		if limit == 0 || limit > maxEventsLimit {
+			writeError400Response(w, fmt.Sprintf("limit must be between 1 and %d", maxEventsLimit))
+			return
+		}
+
+		events, next, err := gateway.GetEvents(since, typ, int(limit))
+		if err != nil {
+			switch err {
+			case visor.ErrEventLogDisabled:
+				writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusForbidden, err.Error()))
+			default:
+				writeError500Response(w, fmt.Sprintf("gateway.GetEvents failed: %v", err))
+			}
+			return
+		}
+
+		resp, err := newEventsResponse(events, next)
+		if err != nil {
+			writeError500Response(w, err.Error())
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: resp,
+		})
+	}
+}
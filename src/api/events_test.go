@@ -0,0 +1,209 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func TestEvents(t *testing.T) {
+	txID := testutil.RandSHA256(t)
+	srcTxID := testutil.RandSHA256(t)
+	addr := testutil.MakeAddress()
+
+	events := []visor.Event{
+		{
+			Seq:   5,
+			Index: 0,
+			Type:  visor.EventOutputSpent,
+			OutputSpent: &visor.OutputSpent{
+				TxID:            txID,
+				InputIdx:        0,
+				SourceTxID:      srcTxID,
+				SourceOutputIdx: 2,
+			},
+		},
+		{
+			Seq:   5,
+			Index: 1,
+			Type:  visor.EventOutputCreated,
+			OutputCreated: &visor.OutputCreated{
+				TxID:      txID,
+				OutputIdx: 0,
+				Address:   addr,
+				Coins:     1500000,
+				CoinHours: 10,
+			},
+		},
+	}
+
+	cases := []struct {
+		name         string
+		method       string
+		query        string
+		status       int
+		since        uint64
+		typ          visor.EventType
+		limit        int
+		events       []visor.Event
+		next         uint64
+		eventsErr    error
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "400 - invalid since",
+			method:       http.MethodGet,
+			query:        "?since=foo",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid 'since' value: strconv.ParseUint: parsing \"foo\": invalid syntax"),
+		},
+		{
+			name:         "400 - invalid type",
+			method:       http.MethodGet,
+			query:        "?type=foo",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid event type \"foo\", must be \"output_created\" or \"output_spent\""),
+		},
+		{
+			name:         "400 - invalid limit",
+			method:       http.MethodGet,
+			query:        "?limit=foo",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid 'limit' value: strconv.ParseUint: parsing \"foo\": invalid syntax"),
+		},
+		{
+			name:         "400 - zero limit",
+			method:       http.MethodGet,
+			query:        "?limit=0",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "limit must be between 1 and 10000"),
+		},
+		{
+			name:         "400 - limit too large",
+			method:       http.MethodGet,
+			query:        "?limit=10001",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "limit must be between 1 and 10000"),
+		},
+		{
+			name:         "403 - event log disabled",
+			method:       http.MethodGet,
+			status:       http.StatusForbidden,
+			limit:        1000,
+			eventsErr:    visor.ErrEventLogDisabled,
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, "The event log is disabled"),
+		},
+		{
+			name:         "500 - GetEvents failed",
+			method:       http.MethodGet,
+			status:       http.StatusInternalServerError,
+			limit:        1000,
+			eventsErr:    errors.New("eventsErr"),
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "gateway.GetEvents failed: eventsErr"),
+		},
+		{
+			name:   "200",
+			method: http.MethodGet,
+			query:  "?since=5&limit=2",
+			status: http.StatusOK,
+			since:  5,
+			limit:  2,
+			events: events,
+			next:   6,
+			httpResponse: HTTPResponse{
+				Data: EventsResponse{
+					Events: []Event{
+						{
+							Seq:   5,
+							Index: 0,
+							Type:  "output_spent",
+							OutputSpent: &EventOutputSpent{
+								TxID:            txID.Hex(),
+								InputIdx:        0,
+								SourceTxID:      srcTxID.Hex(),
+								SourceOutputIdx: 2,
+							},
+						},
+						{
+							Seq:   5,
+							Index: 1,
+							Type:  "output_created",
+							OutputCreated: &EventOutputCreated{
+								TxID:      txID.Hex(),
+								OutputIdx: 0,
+								Address:   addr.String(),
+								Coins:     "1.500000",
+								Hours:     10,
+							},
+						},
+					},
+					NextSince: 6,
+				},
+			},
+		},
+		{
+			name:   "200 - type filter",
+			method: http.MethodGet,
+			query:  "?type=output_spent",
+			status: http.StatusOK,
+			typ:    visor.EventOutputSpent,
+			limit:  1000,
+			next:   3,
+			httpResponse: HTTPResponse{
+				Data: EventsResponse{
+					Events:    []Event{},
+					NextSince: 3,
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetEvents", tc.since, tc.typ, tc.limit).Return(tc.events, tc.next, tc.eventsErr)
+
+			req, err := http.NewRequest(tc.method, "/api/v2/events"+tc.query, nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var eventsRsp EventsResponse
+				err := json.Unmarshal(rsp.Data, &eventsRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(EventsResponse), eventsRsp)
+			}
+		})
+	}
+}
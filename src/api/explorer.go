@@ -221,6 +221,67 @@ func richlistHandler(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
+// defaultRichlistN is the default number of results of GET /api/v2/blockchain/richlist
+const defaultRichlistN = 100
+
+// blockchainRichlistHandler returns the top skycoin holders of the head block.
+// The richlist is computed once per block.
+// Method: GET
+// URI: /api/v2/blockchain/richlist
+// Args:
+//	n [int, number of results to include, must be greater than 0. Defaults to 100]
+//	include_distribution [bool, include the distribution addresses in the richlist]
+func blockchainRichlistHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		topn := defaultRichlistN
+		if topnStr := r.FormValue("n"); topnStr != "" {
+			var err error
+			topn, err = strconv.Atoi(topnStr)
+			if err != nil || topn <= 0 {
+				writeError400Response(w, "invalid n")
+				return
+			}
+		}
+
+		var includeDistribution bool
+		if includeDistributionStr := r.FormValue("include_distribution"); includeDistributionStr != "" {
+			var err error
+			includeDistribution, err = strconv.ParseBool(includeDistributionStr)
+			if err != nil {
+				writeError400Response(w, "invalid include_distribution")
+				return
+			}
+		}
+
+		richlist, err := gateway.GetRichlist(includeDistribution)
+		if err != nil {
+			writeError500Response(w, err.Error())
+			return
+		}
+
+		if topn < len(richlist) {
+			richlist = richlist[:topn]
+		}
+
+		readableRichlist, err := readable.NewRichlistBalances(richlist)
+		if err != nil {
+			writeError500Response(w, err.Error())
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: Richlist{
+				Richlist: readableRichlist,
+			},
+		})
+	}
+}
+
 // addressCountHandler returns the total number of unique address that have coins
 // Method: GET
 // URI: /addresscount
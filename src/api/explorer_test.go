@@ -472,6 +472,145 @@ func TestGetRichlist(t *testing.T) {
 	}
 }
 
+
+func TestBlockchainRichlist(t *testing.T) {
+	richlist := visor.Richlist{
+		{
+			Address: cipher.MustDecodeBase58Address("2fGC7kwAM9yZyEF1QqBqp8uo9RUsF6ENGJF"),
+			Coins:   1000000e6,
+		},
+		{
+			Address: cipher.MustDecodeBase58Address("27jg25DZX21MXMypVbKJMmgCJ5SPuEunMF1"),
+			Coins:   500000e6,
+			Locked:  true,
+		},
+		{
+			Address: cipher.MustDecodeBase58Address("2TmvdBWJgxMwGs84R4drS9p5fYkva4dGdfs"),
+			Coins:   244458e6,
+		},
+	}
+
+	readableRichlist := []readable.RichlistBalance{
+		{
+			Address: "2fGC7kwAM9yZyEF1QqBqp8uo9RUsF6ENGJF",
+			Coins:   "1000000.000000",
+		},
+		{
+			Address: "27jg25DZX21MXMypVbKJMmgCJ5SPuEunMF1",
+			Coins:   "500000.000000",
+			Locked:  true,
+		},
+		{
+			Address: "2TmvdBWJgxMwGs84R4drS9p5fYkva4dGdfs",
+			Coins:   "244458.000000",
+		},
+	}
+
+	cases := []struct {
+		name                     string
+		method                   string
+		query                    string
+		status                   int
+		includeDistribution      bool
+		gatewayGetRichlistResult visor.Richlist
+		gatewayGetRichlistErr    error
+		httpResponse             HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "400 - invalid n",
+			method:       http.MethodGet,
+			query:        "?n=foo",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid n"),
+		},
+		{
+			name:         "400 - n is 0",
+			method:       http.MethodGet,
+			query:        "?n=0",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid n"),
+		},
+		{
+			name:         "400 - invalid include_distribution",
+			method:       http.MethodGet,
+			query:        "?include_distribution=foo",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid include_distribution"),
+		},
+		{
+			name:                  "500 - GetRichlist failed",
+			method:                http.MethodGet,
+			status:                http.StatusInternalServerError,
+			gatewayGetRichlistErr: errors.New("gatewayGetRichlistErr"),
+			httpResponse:          NewHTTPErrorResponse(http.StatusInternalServerError, "gatewayGetRichlistErr"),
+		},
+		{
+			name:                     "200 - default n",
+			method:                   http.MethodGet,
+			status:                   http.StatusOK,
+			gatewayGetRichlistResult: richlist,
+			httpResponse: HTTPResponse{
+				Data: Richlist{
+					Richlist: readableRichlist,
+				},
+			},
+		},
+		{
+			name:                     "200 - n=2 include_distribution",
+			method:                   http.MethodGet,
+			query:                    "?n=2&include_distribution=true",
+			status:                   http.StatusOK,
+			includeDistribution:      true,
+			gatewayGetRichlistResult: richlist,
+			httpResponse: HTTPResponse{
+				Data: Richlist{
+					Richlist: readableRichlist[:2],
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetRichlist", tc.includeDistribution).Return(tc.gatewayGetRichlistResult, tc.gatewayGetRichlistErr)
+
+			req, err := http.NewRequest(tc.method, "/api/v2/blockchain/richlist"+tc.query, nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var richlistRsp Richlist
+				err := json.Unmarshal(rsp.Data, &richlistRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(Richlist), richlistRsp)
+			}
+		})
+	}
+}
 func TestGetAddressCount(t *testing.T) {
 	type Result struct {
 		Count uint64
@@ -6,9 +6,11 @@ import (
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/daemon/gnet"
 	"github.com/skycoin/skycoin/src/kvstorage"
 	"github.com/skycoin/skycoin/src/transaction"
 	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
 	"github.com/skycoin/skycoin/src/visor/historydb"
 	"github.com/skycoin/skycoin/src/wallet"
 )
@@ -46,6 +48,7 @@ type Daemoner interface {
 	DaemonConfig() daemon.DaemonConfig
 	GetConnection(addr string) (*daemon.Connection, error)
 	GetConnections(f func(c daemon.Connection) bool) ([]daemon.Connection, error)
+	GetConnectionEvents() []gnet.ConnectionEvent
 	DisconnectByGnetID(gnetID uint64) error
 	GetDefaultConnections() []string
 	GetTrustConnections() []string
@@ -53,14 +56,20 @@ type Daemoner interface {
 	GetBlockchainProgress(headSeq uint64) *daemon.BlockchainProgress
 	InjectBroadcastTransaction(txn coin.Transaction) error
 	InjectTransaction(txn coin.Transaction) error
+	RequestCoinJoin(coordinatorAddr string, inputs []cipher.SHA256, keys []cipher.SecKey, output coin.TransactionOutput) error
 }
 
 // Visorer interface for visor.Visor methods used by the API
 type Visorer interface {
 	VisorConfig() visor.Config
 	StartedAt() time.Time
+	RestartInfo() visor.RestartInfo
+	UnconfirmedEvictions() uint64
 	HeadBkSeq() (uint64, bool, error)
 	GetBlockchainMetadata() (*visor.BlockchainMetadata, error)
+	GetDBStats() (*dbutil.DBStats, error)
+	StartCorruptionCheck() error
+	GetCorruptionCheck() *visor.CorruptionCheck
 	ResendUnconfirmedTxns() ([]cipher.SHA256, error)
 	GetSignedBlockByHash(hash cipher.SHA256) (*coin.SignedBlock, error)
 	GetSignedBlockByHashVerbose(hash cipher.SHA256) (*coin.SignedBlock, [][]visor.TransactionInput, error)
@@ -72,27 +81,38 @@ type Visorer interface {
 	GetBlocksInRangeVerbose(start, end uint64) ([]coin.SignedBlock, [][][]visor.TransactionInput, error)
 	GetLastBlocks(num uint64) ([]coin.SignedBlock, error)
 	GetLastBlocksVerbose(num uint64) ([]coin.SignedBlock, [][][]visor.TransactionInput, error)
+	StatsByHeight(start, end uint64) ([]visor.BlockStats, error)
+	GetFeeHistory(n uint64) ([]visor.BlockFeeHistory, error)
+	GetBlockchainCapacity() (*visor.BlockchainCapacity, error)
+	GetEvents(since uint64, typ visor.EventType, limit int) ([]visor.Event, uint64, error)
 	GetUnspentOutputsSummary(filters []visor.OutputsFilter) (*visor.UnspentOutputsSummary, error)
 	GetBalanceOfAddresses(addrs []cipher.Address) ([]wallet.BalancePair, error)
 	VerifyTxnVerbose(txn *coin.Transaction, signed visor.TxnSignedFlag) ([]visor.TransactionInput, bool, error)
+	VerifyTransaction(txn coin.Transaction, signed visor.TxnSignedFlag) ([]visor.ValidationError, error)
 	AddressCount() (uint64, error)
 	GetUxOutByID(id cipher.SHA256) (*historydb.UxOut, uint64, error)
 	GetSpentOutputsForAddresses(addr []cipher.Address) ([][]historydb.UxOut, uint64, error)
+	GetAddressBalanceAt(addr cipher.Address, seq uint64) (*historydb.AddressBalance, error)
+	GetAddressTransactionCount(addr cipher.Address) (uint64, error)
 	// GetVerboseTransactionsForAddress(a cipher.Address) ([]visor.Transaction, [][]visor.TransactionInput, error)
 	GetRichlist(includeDistribution bool) (visor.Richlist, error)
 	GetAllUnconfirmedTransactions() ([]visor.UnconfirmedTransaction, error)
 	GetAllUnconfirmedTransactionsVerbose() ([]visor.UnconfirmedTransaction, [][]visor.TransactionInput, error)
 	GetTransaction(txid cipher.SHA256) (*visor.Transaction, error)
 	GetTransactionWithInputs(txid cipher.SHA256) (*visor.Transaction, []visor.TransactionInput, error)
+	GetUnconfirmedStats() (*visor.UnconfirmedStats, error)
 	GetTransactions(flts []visor.TxFilter, order visor.SortOrder, page *visor.PageIndex) ([]visor.Transaction, uint64, error)
 	GetTransactionsWithInputs(flts []visor.TxFilter, order visor.SortOrder, page *visor.PageIndex) ([]visor.Transaction, [][]visor.TransactionInput, uint64, error)
 	GetWalletUnconfirmedTransactions(wltID string) ([]visor.UnconfirmedTransaction, error)
 	GetWalletUnconfirmedTransactionsVerbose(wltID string) ([]visor.UnconfirmedTransaction, [][]visor.TransactionInput, error)
 	GetWalletBalance(wltID string) (wallet.BalancePair, wallet.AddressBalances, error)
+	GetWalletAddressStats(wltID string) ([]visor.AddressStats, bool, error)
 	CreateTransaction(p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error)
 	WalletCreateTransaction(wltID string, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error)
 	WalletCreateTransactionSigned(wltID string, password []byte, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error)
+	CreateConsolidationParams(wltID string, addr cipher.Address, maxInputs int) (transaction.Params, visor.CreateTransactionParams, error)
 	WalletSignTransaction(wltID string, password []byte, txn *coin.Transaction, signIndexes []int) (*coin.Transaction, []visor.TransactionInput, error)
+	WalletCoinJoinKeys(wltID string, password []byte, inputs []cipher.SHA256) ([]cipher.SecKey, error)
 	ScanWalletAddresses(wltID string, password []byte, num uint64) ([]cipher.Address, error)
 	TransactionsFinder() wallet.TransactionsFinder
 }
@@ -102,6 +122,7 @@ type Walleter interface {
 	UnloadWallet(wltID string) error
 	EncryptWallet(wltID string, password []byte) (wallet.Wallet, error)
 	DecryptWallet(wltID string, password []byte) (wallet.Wallet, error)
+	ChangePassword(wltID string, password, newPassword []byte) (wallet.Wallet, error)
 	GetWalletSeed(wltID string, password []byte) (string, string, error)
 	CreateWallet(wltName string, options wallet.Options) (wallet.Wallet, error)
 	RecoverWallet(wltID, seed, seedPassphrase string, password []byte, options ...wallet.Option) (wallet.Wallet, error)
@@ -110,7 +131,11 @@ type Walleter interface {
 	GetWallet(wltID string) (wallet.Wallet, error)
 	GetWallets() (wallet.Wallets, error)
 	UpdateWalletLabel(wltID, label string) error
+	UpdateWalletMeta(wltID, label string, meta map[string]string) (wallet.Wallet, error)
 	WalletDir() (string, error)
+	StartSession(wltID string, password, pin []byte) (time.Time, error)
+	SessionPassword(wltID string, pin []byte) ([]byte, error)
+	EndSession(wltID string) error
 }
 
 // Storer interface for kvstorage.Manager methods used by the API
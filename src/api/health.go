@@ -36,6 +36,8 @@ type HealthResponse struct {
 	UserVerifyTxn        readable.VerifyTxn   `json:"user_verify_transaction"`
 	UnconfirmedVerifyTxn readable.VerifyTxn   `json:"unconfirmed_verify_transaction"`
 	StartedAt            int64                `json:"started_at"`
+	RestartCount         int                  `json:"restart_count"`
+	LastRestartReason    string               `json:"last_restart_reason"`
 	Fiber                readable.FiberConfig `json:"fiber"`
 }
 
@@ -67,6 +69,8 @@ func getHealthData(c muxConfig, gateway Gatewayer) (*HealthResponse, error) {
 
 	_, walletAPIEnabled := c.enabledAPISets[EndpointsWallet]
 
+	restartInfo := gateway.RestartInfo()
+
 	userAgent, err := c.health.DaemonUserAgent.Build()
 	if err != nil {
 		return nil, err
@@ -94,6 +98,8 @@ func getHealthData(c muxConfig, gateway Gatewayer) (*HealthResponse, error) {
 		UnconfirmedVerifyTxn: readable.NewVerifyTxn(gateway.DaemonConfig().UnconfirmedVerifyTxn),
 		Uptime:               wh.FromDuration(time.Since(gateway.StartedAt())),
 		StartedAt:            gateway.StartedAt().Unix(),
+		RestartCount:         restartInfo.RestartCount,
+		LastRestartReason:    restartInfo.LastRestartReason,
 	}, nil
 }
 
@@ -186,11 +186,18 @@ func TestHealthHandler(t *testing.T) {
 
 			gateway.On("StartedAt").Return(startedAt)
 
+			restartInfo := visor.RestartInfo{
+				RestartCount:      3,
+				LastRestartReason: visor.ShutdownReasonUnclean,
+			}
+			gateway.On("RestartInfo").Return(restartInfo)
+
 			dc := daemon.DaemonConfig{
 				UnconfirmedVerifyTxn: params.VerifyTxn{
 					BurnFactor:          params.UserVerifyTxn.BurnFactor * 2,
 					MaxTransactionSize:  params.UserVerifyTxn.MaxTransactionSize * 2,
 					MaxDropletPrecision: params.UserVerifyTxn.MaxDropletPrecision - 1,
+					MinFeePerByte:       2,
 				},
 			}
 
@@ -253,7 +260,10 @@ func TestHealthHandler(t *testing.T) {
 			require.Equal(t, dc.UnconfirmedVerifyTxn.BurnFactor, r.UnconfirmedVerifyTxn.BurnFactor)
 			require.Equal(t, dc.UnconfirmedVerifyTxn.MaxTransactionSize, r.UnconfirmedVerifyTxn.MaxTransactionSize)
 			require.Equal(t, dc.UnconfirmedVerifyTxn.MaxDropletPrecision, r.UnconfirmedVerifyTxn.MaxDropletPrecision)
+			require.Equal(t, dc.UnconfirmedVerifyTxn.MinFeePerByte, r.UnconfirmedVerifyTxn.MinFeePerByte)
 			require.True(t, time.Now().Unix() > r.StartedAt)
+			require.Equal(t, restartInfo.RestartCount, r.RestartCount)
+			require.Equal(t, restartInfo.LastRestartReason, r.LastRestartReason)
 
 		})
 	}
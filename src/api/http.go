@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -58,6 +59,8 @@ const (
 	EndpointsPrometheus = "PROMETHEUS"
 	// EndpointsNetCtrl endpoints for managing network connections
 	EndpointsNetCtrl = "NET_CTRL"
+	// EndpointsDBCtrl endpoints for administering the database
+	EndpointsDBCtrl = "DB_CTRL"
 	// EndpointsStorage endpoints implement interface for key-value storage for arbitrary data
 	EndpointsStorage = "STORAGE"
 )
@@ -84,6 +87,16 @@ type Config struct {
 	EnabledAPISets     map[string]struct{}
 	Username           string
 	Password           string
+	// Serve node metrics for prometheus on /metrics
+	EnableMetrics bool
+	// Duration of the database check at startup, exposed on /metrics
+	DBCheckDuration time.Duration
+	// Reject any request that is not a GET request
+	ReadOnly bool
+	// Limits the number of requests per minute to routes, across all clients. It can be shared by several servers
+	RouteRateLimiter *RouteRateLimiter
+	// If set, the full requests and responses are written to it, as lines of JSON
+	DebugLog io.Writer
 }
 
 // HealthConfig configuration data exposed in /health
@@ -106,6 +119,11 @@ type muxConfig struct {
 	username           string
 	password           string
 	health             HealthConfig
+	enableMetrics      bool
+	dbCheckDuration    time.Duration
+	readOnly           bool
+	routeRateLimiter   *RouteRateLimiter
+	debugLog           io.Writer
 }
 
 // HTTPResponse represents the http response struct
@@ -212,9 +230,20 @@ func create(host string, c Config, gateway Gatewayer) (*Server, error) {
 		hostWhitelist:      c.HostWhitelist,
 		username:           c.Username,
 		password:           c.Password,
+		enableMetrics:      c.EnableMetrics,
+		dbCheckDuration:    c.DBCheckDuration,
+		readOnly:           c.ReadOnly,
+		routeRateLimiter:   c.RouteRateLimiter,
+		debugLog:           c.DebugLog,
 	}
 
 	srvMux := newServerMux(mc, gateway)
+
+	if c.RouteRateLimiter != nil {
+		if err := c.RouteRateLimiter.checkRoutes(); err != nil {
+			return nil, err
+		}
+	}
 	srv := &http.Server{
 		Handler:      srvMux,
 		ReadTimeout:  c.ReadTimeout,
@@ -244,8 +273,8 @@ func Create(host string, c Config, gateway Gatewayer) (*Server, error) {
 
 	s, err := create(host, c, gateway)
 	if err != nil {
-		if closeErr := s.listener.Close(); closeErr != nil {
-			logger.WithError(err).Warning("s.listener.Close() error")
+		if closeErr := listener.Close(); closeErr != nil {
+			logger.WithError(closeErr).Warning("listener.Close() error")
 		}
 		return nil, err
 	}
@@ -278,8 +307,8 @@ func CreateHTTPS(host string, c Config, gateway Gatewayer, certFile, keyFile str
 
 	s, err := create(host, c, gateway)
 	if err != nil {
-		if closeErr := s.listener.Close(); closeErr != nil {
-			logger.WithError(err).Warning("s.listener.Close() error")
+		if closeErr := listener.Close(); closeErr != nil {
+			logger.WithError(closeErr).Warning("listener.Close() error")
 		}
 		return nil, err
 	}
@@ -311,6 +340,16 @@ func (s *Server) Serve() error {
 	return nil
 }
 
+// Close closes the listener of a Server that was never served.
+// Use Shutdown instead once Serve has been called.
+func (s *Server) Close() error {
+	if s == nil {
+		return nil
+	}
+
+	return s.listener.Close()
+}
+
 // Shutdown closes the HTTP service. This can only be called after Serve or ServeHTTPS has been called.
 func (s *Server) Shutdown() {
 	if s == nil {
@@ -392,9 +431,27 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 		})
 	}
 
+	var metrics *nodeMetrics
+	if c.enableMetrics {
+		metrics = newNodeMetrics(c.dbCheckDuration)
+	}
+
+	var debugLog *debugLogger
+	if c.debugLog != nil {
+		debugLog = newDebugLogger(c.debugLog)
+	}
+
 	webHandlerWithOptionals := func(apiVersion, endpoint string, handlerFunc http.Handler, checkCSRF, checkHeaders bool) {
+		if c.routeRateLimiter != nil {
+			handlerFunc = routeRateLimit(apiVersion, endpoint, c.routeRateLimiter, handlerFunc)
+		}
+
 		handler := wh.ElapsedHandler(logger, handlerFunc)
 
+		if metrics != nil {
+			handler = metrics.countRequests(handler)
+		}
+
 		handler = corsHandler.Handler(handler)
 
 		if checkCSRF {
@@ -409,7 +466,17 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 			handler = ContentTypeJSONRequired(handler)
 		}
 
+		if c.readOnly {
+			handler = readOnlyCheck(apiVersion, handler)
+		}
+
 		handler = basicAuth(apiVersion, c.username, c.password, "skycoin daemon", handler)
+
+		// The debug log is inside the gzip handler, so that the bodies are logged uncompressed
+		if debugLog != nil {
+			handler = debugLog.handler(handler)
+		}
+
 		handler = gziphandler.GzipHandler(handler)
 		mux.Handle(endpoint, handler)
 	}
@@ -471,6 +538,13 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 		http.MethodGet: []string{EndpointsRead, EndpointsStatus},
 	})
 
+	webHandlerV2("/node/dbstats", dbStatsHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsRead, EndpointsStatus},
+	})
+	webHandlerV2("/node/mempoolstats", mempoolStatsHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsRead, EndpointsStatus},
+	})
+
 	// Wallet endpoints
 	webHandlerV1("/wallet", walletHandler(gateway), map[string][]string{
 		http.MethodGet: []string{EndpointsWallet},
@@ -524,9 +598,17 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	webHandlerV1("/wallet/decrypt", walletDecryptHandler(gateway), map[string][]string{
 		http.MethodPost: []string{EndpointsWallet},
 	})
+	webHandlerV1("/wallet/password", walletChangePasswordHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
 	webHandlerV2("/wallet/recover", walletRecoverHandler(gateway), map[string][]string{
 		http.MethodPost: []string{EndpointsWallet},
 	})
+	webHandlerV2("/wallet/", walletHandlerV2Subtree(gateway), map[string][]string{
+		http.MethodGet:    []string{EndpointsWallet},
+		http.MethodPost:   []string{EndpointsWallet},
+		http.MethodDelete: []string{EndpointsWallet},
+	})
 
 	// Blockchain interface
 	webHandlerV1("/blockchain/metadata", blockchainMetadataHandler(gateway), map[string][]string{
@@ -535,6 +617,24 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	webHandlerV1("/blockchain/progress", blockchainProgressHandler(gateway), map[string][]string{
 		http.MethodGet: []string{EndpointsRead, EndpointsStatus},
 	})
+	webHandlerV2("/blockchain/stats", blockchainStatsHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsRead},
+	})
+	webHandlerV2("/blockchain/params", blockchainParamsHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsRead},
+	})
+	webHandlerV2("/blockchain/richlist", blockchainRichlistHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsRead},
+	})
+	webHandlerV2("/blockchain/capacity", blockchainCapacityHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsRead},
+	})
+	webHandlerV2("/fees/history", feeHistoryHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsRead},
+	})
+	webHandlerV2("/events", eventsHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsRead},
+	})
 	webHandlerV1("/block", blockHandler(gateway), map[string][]string{
 		http.MethodGet: []string{EndpointsRead},
 	})
@@ -562,12 +662,21 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	webHandlerV1("/network/connections/exchange", exchgConnectionsHandler(gateway), map[string][]string{
 		http.MethodGet: []string{EndpointsRead, EndpointsStatus},
 	})
+	webHandlerV2("/network/graph", networkGraphHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsRead, EndpointsStatus},
+	})
 
 	// Network admin endpoints
 	webHandlerV1("/network/connection/disconnect", disconnectHandler(gateway), map[string][]string{
 		http.MethodPost: []string{EndpointsNetCtrl},
 	})
 
+	// Database admin endpoints
+	webHandlerV2("/node/dbcorruption", dbCorruptionHandler(gateway), map[string][]string{
+		http.MethodGet:  []string{EndpointsDBCtrl},
+		http.MethodPost: []string{EndpointsDBCtrl},
+	})
+
 	// Transaction related endpoints
 	webHandlerV1("/pendingTxs", pendingTxnsHandler(gateway), map[string][]string{
 		http.MethodGet: []string{EndpointsRead},
@@ -582,6 +691,9 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	webHandlerV2("/transaction/verify", verifyTxnHandler(gateway), map[string][]string{
 		http.MethodPost: []string{EndpointsRead},
 	})
+	webHandlerV2("/transaction/validate", validateTxnHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsRead},
+	})
 	webHandlerV1("/transactions", transactionsHandler(gateway), map[string][]string{
 		http.MethodGet:  []string{EndpointsRead},
 		http.MethodPost: []string{EndpointsRead},
@@ -615,6 +727,11 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 		http.MethodGet: []string{EndpointsRead},
 	})
 
+	// Node metrics for Prometheus, served outside of the API sets
+	if metrics != nil {
+		webHandlerWithOptionals(apiVersion1, "/metrics", nodeMetricsHandler(c, gateway, metrics), false, !c.disableHeaderCheck)
+	}
+
 	// golang process internal metrics for Prometheus
 	webHandlerV2("/metrics", metricsHandler(c, gateway), map[string][]string{
 		http.MethodGet: []string{EndpointsPrometheus},
@@ -624,6 +741,12 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	webHandlerV2("/address/verify", http.HandlerFunc(addressVerifyHandler), map[string][]string{
 		http.MethodPost: []string{EndpointsRead},
 	})
+	webHandlerV2("/address/", addressHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsRead},
+	})
+	webHandlerV2("/crypto/validate_address", http.HandlerFunc(validateAddressHandler), map[string][]string{
+		http.MethodPost: []string{EndpointsRead},
+	})
 
 	// Explorer endpoints
 	webHandlerV1("/coinSupply", coinSupplyHandler(gateway), map[string][]string{
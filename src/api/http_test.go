@@ -3,6 +3,7 @@ package api
 import (
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -22,6 +23,7 @@ var allAPISetsEnabled = map[string]struct{}{
 	EndpointsInsecureWalletSeed: struct{}{},
 	EndpointsPrometheus:         struct{}{},
 	EndpointsNetCtrl:            struct{}{},
+	EndpointsDBCtrl:             struct{}{},
 	EndpointsStorage:            struct{}{},
 }
 
@@ -133,6 +135,9 @@ var endpointsMethods = map[string][]string{
 	"/api/v1/wallet/newSeed": []string{
 		http.MethodGet,
 	},
+	"/api/v1/wallet/password": []string{
+		http.MethodPost,
+	},
 	"/api/v1/wallet/seed": []string{
 		http.MethodPost,
 	},
@@ -158,9 +163,69 @@ var endpointsMethods = map[string][]string{
 	"/api/v2/transaction/verify": []string{
 		http.MethodPost,
 	},
+	"/api/v2/transaction/validate": []string{
+		http.MethodPost,
+	},
+	"/api/v2/blockchain/stats": []string{
+		http.MethodGet,
+	},
+	"/api/v2/blockchain/params": []string{
+		http.MethodGet,
+	},
+	"/api/v2/blockchain/richlist": []string{
+		http.MethodGet,
+	},
+	"/api/v2/events": []string{
+		http.MethodGet,
+	},
+	"/api/v2/blockchain/capacity": []string{
+		http.MethodGet,
+	},
+	"/api/v2/fees/history": []string{
+		http.MethodGet,
+	},
+	"/api/v2/network/graph": []string{
+		http.MethodGet,
+	},
+	"/api/v2/node/dbstats": []string{
+		http.MethodGet,
+	},
+	"/api/v2/node/dbcorruption": []string{
+		http.MethodGet,
+		http.MethodPost,
+	},
+	"/api/v2/node/mempoolstats": []string{
+		http.MethodGet,
+	},
 	"/api/v2/address/verify": []string{
 		http.MethodPost,
 	},
+	"/api/v2/address/2GgFvqoyk9RjwVzj8tqfcXVXB4orBwoc9qv/projected_coin_hours": []string{
+		http.MethodGet,
+	},
+	"/api/v2/address/2GgFvqoyk9RjwVzj8tqfcXVXB4orBwoc9qv/balance_at": []string{
+		http.MethodGet,
+	},
+	"/api/v2/address/2GgFvqoyk9RjwVzj8tqfcXVXB4orBwoc9qv/transaction_count": []string{
+		http.MethodGet,
+	},
+	"/api/v2/crypto/validate_address": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/foo.wlt/meta": []string{
+		http.MethodGet,
+		http.MethodPost,
+	},
+	"/api/v2/wallet/foo.wlt/address_stats": []string{
+		http.MethodGet,
+	},
+	"/api/v2/wallet/foo.wlt/consolidate": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/foo.wlt/session": []string{
+		http.MethodPost,
+		http.MethodDelete,
+	},
 	"/api/v2/wallet/recover": []string{
 		http.MethodPost,
 	},
@@ -291,6 +356,18 @@ func TestEnableGUI(t *testing.T) {
 	}
 }
 
+func TestServerClose(t *testing.T) {
+	s, err := Create("127.0.0.1:0", Config{}, &MockGatewayer{})
+	require.NoError(t, err)
+	addr := s.Addr()
+
+	// The address can be listened on again once the unserved server is closed
+	require.NoError(t, s.Close())
+	l, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+}
+
 func TestAPISetDisabled(t *testing.T) {
 	tf := func(t *testing.T, endpoint, method string, disableCSRF bool) {
 		req, err := http.NewRequest(method, endpoint, nil)
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+// MempoolAgeBucket is a bucket of the unconfirmed transaction age histogram
+type MempoolAgeBucket struct {
+	// MaxAge is the upper bound of the bucket
+	MaxAge string `json:"max_age"`
+	// Transactions is the number of transactions older than the previous bucket's MaxAge and at most MaxAge old
+	Transactions uint64 `json:"transactions"`
+}
+
+// MempoolStats is returned by GET /api/v2/node/mempoolstats
+type MempoolStats struct {
+	// Count is the number of unconfirmed transactions
+	Count uint64 `json:"count"`
+	// Size is the sum of the sizes of the unconfirmed transactions, in bytes
+	Size uint64 `json:"size"`
+	// AgeBuckets counts the unconfirmed transactions by how long they have been waiting since they were last received
+	AgeBuckets []MempoolAgeBucket `json:"age_buckets"`
+	// TransactionsAboveLastBucket is the number of transactions older than the MaxAge of the last bucket
+	TransactionsAboveLastBucket uint64 `json:"transactions_above_last_bucket"`
+}
+
+func newMempoolStats(s visor.UnconfirmedStats) MempoolStats {
+	h := s.Ages
+
+	buckets := make([]MempoolAgeBucket, len(h.Buckets))
+	for i, b := range h.Buckets {
+		buckets[i] = MempoolAgeBucket{
+			MaxAge:       b.String(),
+			Transactions: h.Counts[i],
+		}
+	}
+
+	return MempoolStats{
+		Count:                       s.Count,
+		Size:                        s.Size,
+		AgeBuckets:                  buckets,
+		TransactionsAboveLastBucket: h.Counts[len(h.Counts)-1],
+	}
+}
+
+// mempoolStatsHandler returns the number and size of the unconfirmed transactions and the histogram
+// of how long they have been waiting, to tune the eviction of the unconfirmed pool and to see whether
+// congestion is temporary
+// Method: GET
+// URI: /api/v2/node/mempoolstats
+func mempoolStatsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		stats, err := gateway.GetUnconfirmedStats()
+		if err != nil {
+			writeError500Response(w, fmt.Sprintf("gateway.GetUnconfirmedStats failed: %v", err))
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: newMempoolStats(*stats),
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func TestMempoolStats(t *testing.T) {
+	stats := &visor.UnconfirmedStats{
+		Count: 10,
+		Size:  4000,
+		Ages: visor.UnconfirmedAgeHistogram{
+			Buckets: []time.Duration{30 * time.Second, 5 * time.Minute},
+			Counts:  []uint64{6, 3, 1},
+		},
+	}
+
+	cases := []struct {
+		name         string
+		method       string
+		status       int
+		stats        *visor.UnconfirmedStats
+		err          error
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "500 - gateway.GetUnconfirmedStats error",
+			method:       http.MethodGet,
+			status:       http.StatusInternalServerError,
+			err:          errors.New("GetUnconfirmedStats failed"),
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "gateway.GetUnconfirmedStats failed: GetUnconfirmedStats failed"),
+		},
+		{
+			name:   "200",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			stats:  stats,
+			httpResponse: HTTPResponse{
+				Data: MempoolStats{
+					Count: 10,
+					Size:  4000,
+					AgeBuckets: []MempoolAgeBucket{
+						{
+							MaxAge:       "30s",
+							Transactions: 6,
+						},
+						{
+							MaxAge:       "5m0s",
+							Transactions: 3,
+						},
+					},
+					TransactionsAboveLastBucket: 1,
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetUnconfirmedStats").Return(tc.stats, tc.err)
+
+			req, err := http.NewRequest(tc.method, "/api/v2/node/mempoolstats", nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var statsRsp MempoolStats
+				err := json.Unmarshal(rsp.Data, &statsRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(MempoolStats), statsRsp)
+			}
+		})
+	}
+}
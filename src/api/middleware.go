@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
 	wh "github.com/skycoin/skycoin/src/util/http"
@@ -158,6 +161,18 @@ func originRefererCheck(apiVersion, host string, hostWhitelist []string, handler
 	})
 }
 
+// readOnlyCheck rejects any request that is not a GET request with 405 Method Not Allowed
+func readOnlyCheck(apiVersion string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, apiVersion, http.StatusMethodNotAllowed, "")
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
 func basicAuth(apiVersion, username, password, realm string, f http.Handler) http.HandlerFunc {
 	needsAuth := username != "" || password != ""
 	usernamePasswordHash := cipher.SumSHA256(append([]byte(username), []byte(password)...))
@@ -196,6 +211,179 @@ func basicAuth(apiVersion, username, password, realm string, f http.Handler) htt
 	}
 }
 
+// routeRateLimiter limits the number of requests to a route in a sliding window of one minute,
+// across all clients
+type routeRateLimiter struct {
+	sync.Mutex
+	// times of the requests accepted in the window, oldest first
+	times  []time.Time
+	window time.Duration
+	limit  int
+	now    func() time.Time
+}
+
+func newRouteRateLimiter(requestsPerMinute int) *routeRateLimiter {
+	return &routeRateLimiter{
+		times:  make([]time.Time, 0, requestsPerMinute),
+		window: time.Minute,
+		limit:  requestsPerMinute,
+		now:    time.Now,
+	}
+}
+
+// allow records a request and returns true if it is within the limit.
+// Otherwise, returns the time to wait until a request is accepted again
+func (l *routeRateLimiter) allow() (bool, time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+
+	now := l.now()
+
+	// Forget the requests that are out of the window
+	n := 0
+	for n < len(l.times) && !l.times[n].After(now.Add(-l.window)) {
+		n++
+	}
+	l.times = append(l.times[:0], l.times[n:]...)
+
+	if len(l.times) >= l.limit {
+		return false, l.times[0].Add(l.window).Sub(now)
+	}
+
+	l.times = append(l.times, now)
+	return true, 0
+}
+
+// setLimit changes the maximum number of requests in the window
+func (l *routeRateLimiter) setLimit(requestsPerMinute int) {
+	l.Lock()
+	defer l.Unlock()
+	l.limit = requestsPerMinute
+}
+
+// RouteRateLimiter limits the number of requests per minute to routes, across all clients.
+// It can be shared by several servers, so that the limits apply across all of their listeners
+type RouteRateLimiter struct {
+	sync.RWMutex
+	limiters map[string]*routeRateLimiter
+	// routes registered by the servers using the limiter
+	routes map[string]struct{}
+}
+
+// NewRouteRateLimiter creates a RouteRateLimiter with the maximum number of requests per minute
+// to a route by route pattern, e.g. /api/v2/blockchain/richlist
+func NewRouteRateLimiter(limits map[string]int) *RouteRateLimiter {
+	l := &RouteRateLimiter{
+		limiters: make(map[string]*routeRateLimiter, len(limits)),
+		routes:   make(map[string]struct{}),
+	}
+
+	for route, limit := range limits {
+		l.limiters[route] = newRouteRateLimiter(limit)
+	}
+
+	return l
+}
+
+// SetLimits replaces the limits of the routes. The requests in the window of a route that
+// remains limited are kept. The routes must be registered by the servers using the limiter
+func (l *RouteRateLimiter) SetLimits(limits map[string]int) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if err := l.checkLimits(limits); err != nil {
+		return err
+	}
+
+	limiters := make(map[string]*routeRateLimiter, len(limits))
+	for route, limit := range limits {
+		rl, ok := l.limiters[route]
+		if ok {
+			rl.setLimit(limit)
+		} else {
+			rl = newRouteRateLimiter(limit)
+		}
+		limiters[route] = rl
+	}
+	l.limiters = limiters
+
+	return nil
+}
+
+// CheckLimits checks limits like SetLimits, without replacing the limits of the routes
+func (l *RouteRateLimiter) CheckLimits(limits map[string]int) error {
+	l.RLock()
+	defer l.RUnlock()
+	return l.checkLimits(limits)
+}
+
+// checkRoutes checks the limits against the registered routes
+func (l *RouteRateLimiter) checkRoutes() error {
+	l.RLock()
+	defer l.RUnlock()
+
+	limits := make(map[string]int, len(l.limiters))
+	for route, rl := range l.limiters {
+		limits[route] = rl.limit
+	}
+
+	return l.checkLimits(limits)
+}
+
+// checkLimits checks that each rate limited route is a registered route pattern and has a positive limit.
+// Must be called with the lock held
+func (l *RouteRateLimiter) checkLimits(limits map[string]int) error {
+	for route, limit := range limits {
+		if limit <= 0 {
+			return fmt.Errorf("rate limit of route %q must be positive", route)
+		}
+
+		if _, ok := l.routes[route]; !ok {
+			return fmt.Errorf("rate limited route %q is not an API route", route)
+		}
+	}
+
+	return nil
+}
+
+func (l *RouteRateLimiter) registerRoute(route string) {
+	l.Lock()
+	defer l.Unlock()
+	l.routes[route] = struct{}{}
+}
+
+// allow records a request to the route and returns true if the route is not limited or the request is within the limit.
+// Otherwise, returns the time to wait until a request is accepted again
+func (l *RouteRateLimiter) allow(route string) (bool, time.Duration) {
+	l.RLock()
+	rl, ok := l.limiters[route]
+	l.RUnlock()
+
+	if !ok {
+		return true, 0
+	}
+
+	return rl.allow()
+}
+
+// routeRateLimit returns 429 Too Many Requests when the route has been requested more times in the last minute
+// than the limit of the route. The Retry-After header is set to the number of seconds until the route
+// can be requested again
+func routeRateLimit(apiVersion, route string, limiter *RouteRateLimiter, handler http.Handler) http.Handler {
+	limiter.registerRoute(route)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ok, wait := limiter.allow(route); !ok {
+			retryAfter := int64((wait + time.Second - 1) / time.Second)
+			w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+			writeError(w, apiVersion, http.StatusTooManyRequests, "")
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
 func writeError(w http.ResponseWriter, apiVersion string, code int, msg string) {
 	switch apiVersion {
 	case apiVersion1:
@@ -6,8 +6,11 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/visor"
 )
 
 func TestOriginRefererCheck(t *testing.T) {
@@ -275,6 +278,53 @@ func TestContentSecurityPolicy(t *testing.T) {
 	}
 }
 
+func TestReadOnly(t *testing.T) {
+	for endpoint, methods := range endpointsMethods {
+		for _, m := range methods {
+			if m == http.MethodGet {
+				continue
+			}
+
+			name := fmt.Sprintf("%s %s", m, endpoint)
+			t.Run(name, func(t *testing.T) {
+				req, err := http.NewRequest(m, endpoint, nil)
+				require.NoError(t, err)
+
+				isAPIV2 := strings.HasPrefix(endpoint, "/api/v2")
+				if isAPIV2 {
+					req.Header.Set("Content-Type", ContentTypeJSON)
+				}
+
+				mc := defaultMuxConfig()
+				mc.readOnly = true
+
+				rr := httptest.NewRecorder()
+				handler := newServerMux(mc, &MockGatewayer{})
+				handler.ServeHTTP(rr, req)
+
+				require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+				if isAPIV2 {
+					require.Equal(t, "{\n    \"error\": {\n        \"message\": \"Method Not Allowed\",\n        \"code\": 405\n    }\n}", rr.Body.String())
+				} else {
+					require.Equal(t, "405 Method Not Allowed\n", rr.Body.String())
+				}
+			})
+		}
+	}
+
+	// GET requests are served
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/version", nil)
+	require.NoError(t, err)
+
+	mc := defaultMuxConfig()
+	mc.readOnly = true
+
+	rr := httptest.NewRecorder()
+	handler := newServerMux(mc, &MockGatewayer{})
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
 func TestIsContentTypeJSON(t *testing.T) {
 	require.True(t, isContentTypeJSON(ContentTypeJSON))
 	require.True(t, isContentTypeJSON("application/json"))
@@ -282,3 +332,158 @@ func TestIsContentTypeJSON(t *testing.T) {
 	require.False(t, isContentTypeJSON("application/x-www-form-urlencoded"))
 	require.False(t, isContentTypeJSON(ContentTypeForm))
 }
+
+func TestRouteRateLimiter(t *testing.T) {
+	now := time.Unix(1540000000, 0)
+	l := newRouteRateLimiter(2)
+	l.now = func() time.Time {
+		return now
+	}
+
+	ok, _ := l.allow()
+	require.True(t, ok)
+
+	now = now.Add(10 * time.Second)
+	ok, _ = l.allow()
+	require.True(t, ok)
+
+	// The limit is reached until the first request is out of the window
+	now = now.Add(10 * time.Second)
+	ok, wait := l.allow()
+	require.False(t, ok)
+	require.Equal(t, 40*time.Second, wait)
+
+	now = now.Add(40 * time.Second)
+	ok, _ = l.allow()
+	require.True(t, ok)
+
+	ok, wait = l.allow()
+	require.False(t, ok)
+	require.Equal(t, 10*time.Second, wait)
+}
+
+func TestRouteRateLimit(t *testing.T) {
+	endpoint := "/api/v2/blockchain/params"
+
+	mc := defaultMuxConfig()
+	mc.routeRateLimiter = NewRouteRateLimiter(map[string]int{
+		endpoint: 2,
+	})
+	gateway := &MockGatewayer{}
+	gateway.On("VisorConfig").Return(visor.NewConfig())
+
+	// The limit is shared by the servers using the limiter
+	handler := newServerMux(mc, gateway)
+	otherHandler := newServerMux(mc, gateway)
+
+	get := func(h http.Handler, route string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, route, nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	require.Equal(t, http.StatusOK, get(handler, endpoint).Code)
+	require.Equal(t, http.StatusOK, get(otherHandler, endpoint).Code)
+
+	rr := get(handler, endpoint)
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.Equal(t, "60", rr.Header().Get("Retry-After"))
+	require.Equal(t, "{\n    \"error\": {\n        \"message\": \"Too Many Requests\",\n        \"code\": 429\n    }\n}", rr.Body.String())
+	require.Equal(t, http.StatusTooManyRequests, get(otherHandler, endpoint).Code)
+
+	// Other routes are not limited
+	require.Equal(t, http.StatusOK, get(handler, "/api/v1/version").Code)
+
+	// A raised limit keeps the requests in the window
+	err := mc.routeRateLimiter.SetLimits(map[string]int{
+		endpoint:          3,
+		"/api/v1/version": 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, get(handler, endpoint).Code)
+	require.Equal(t, http.StatusTooManyRequests, get(otherHandler, endpoint).Code)
+
+	// A newly limited route starts with an empty window
+	require.Equal(t, http.StatusOK, get(handler, "/api/v1/version").Code)
+	require.Equal(t, http.StatusTooManyRequests, get(handler, "/api/v1/version").Code)
+
+	// A route that is no longer limited is not limited
+	err = mc.routeRateLimiter.SetLimits(nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, get(handler, endpoint).Code)
+}
+
+func TestRouteRateLimiterSetLimits(t *testing.T) {
+	mc := defaultMuxConfig()
+	mc.routeRateLimiter = NewRouteRateLimiter(nil)
+	newServerMux(mc, &MockGatewayer{})
+
+	cases := []struct {
+		name   string
+		limits map[string]int
+		err    string
+	}{
+		{
+			name: "valid",
+			limits: map[string]int{
+				"/api/v2/blockchain/richlist": 6,
+				"/api/v2/wallet/":             60,
+			},
+		},
+		{
+			name: "unknown route",
+			limits: map[string]int{
+				"/api/v2/foo": 6,
+			},
+			err: `rate limited route "/api/v2/foo" is not an API route`,
+		},
+		{
+			name: "subtree route without trailing slash",
+			limits: map[string]int{
+				"/api/v2/wallet": 6,
+			},
+			err: `rate limited route "/api/v2/wallet" is not an API route`,
+		},
+		{
+			name: "zero limit",
+			limits: map[string]int{
+				"/api/v2/blockchain/richlist": 0,
+			},
+			err: `rate limit of route "/api/v2/blockchain/richlist" must be positive`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := mc.routeRateLimiter.CheckLimits(tc.limits)
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.err)
+			}
+
+			err = mc.routeRateLimiter.SetLimits(tc.limits)
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestCreateInvalidRouteRateLimits(t *testing.T) {
+	c := Config{
+		DisableCSRF: true,
+		RouteRateLimiter: NewRouteRateLimiter(map[string]int{
+			"/api/v2/foo": 6,
+		}),
+	}
+
+	s, err := Create("127.0.0.1:0", c, &MockGatewayer{})
+	require.EqualError(t, err, `rate limited route "/api/v2/foo" is not an API route`)
+	require.Nil(t, s)
+}
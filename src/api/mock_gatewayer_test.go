@@ -8,6 +8,10 @@ import (
 
 	daemon "github.com/skycoin/skycoin/src/daemon"
 
+	dbutil "github.com/skycoin/skycoin/src/visor/dbutil"
+
+	gnet "github.com/skycoin/skycoin/src/daemon/gnet"
+
 	historydb "github.com/skycoin/skycoin/src/visor/historydb"
 
 	kvstorage "github.com/skycoin/skycoin/src/kvstorage"
@@ -63,6 +67,57 @@ func (_m *MockGatewayer) AddressCount() (uint64, error) {
 	return r0, r1
 }
 
+// ChangePassword provides a mock function with given fields: wltID, password, newPassword
+func (_m *MockGatewayer) ChangePassword(wltID string, password []byte, newPassword []byte) (wallet.Wallet, error) {
+	ret := _m.Called(wltID, password, newPassword)
+
+	var r0 wallet.Wallet
+	if rf, ok := ret.Get(0).(func(string, []byte, []byte) wallet.Wallet); ok {
+		r0 = rf(wltID, password, newPassword)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(wallet.Wallet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []byte, []byte) error); ok {
+		r1 = rf(wltID, password, newPassword)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateConsolidationParams provides a mock function with given fields: wltID, addr, maxInputs
+func (_m *MockGatewayer) CreateConsolidationParams(wltID string, addr cipher.Address, maxInputs int) (transaction.Params, visor.CreateTransactionParams, error) {
+	ret := _m.Called(wltID, addr, maxInputs)
+
+	var r0 transaction.Params
+	if rf, ok := ret.Get(0).(func(string, cipher.Address, int) transaction.Params); ok {
+		r0 = rf(wltID, addr, maxInputs)
+	} else {
+		r0 = ret.Get(0).(transaction.Params)
+	}
+
+	var r1 visor.CreateTransactionParams
+	if rf, ok := ret.Get(1).(func(string, cipher.Address, int) visor.CreateTransactionParams); ok {
+		r1 = rf(wltID, addr, maxInputs)
+	} else {
+		r1 = ret.Get(1).(visor.CreateTransactionParams)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, cipher.Address, int) error); ok {
+		r2 = rf(wltID, addr, maxInputs)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // CreateTransaction provides a mock function with given fields: p, wp
 func (_m *MockGatewayer) CreateTransaction(p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error) {
 	ret := _m.Called(p, wp)
@@ -169,6 +224,20 @@ func (_m *MockGatewayer) DisconnectByGnetID(gnetID uint64) error {
 	return r0
 }
 
+// EndSession provides a mock function with given fields: wltID
+func (_m *MockGatewayer) EndSession(wltID string) error {
+	ret := _m.Called(wltID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(wltID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // EncryptWallet provides a mock function with given fields: wltID, password
 func (_m *MockGatewayer) EncryptWallet(wltID string, password []byte) (wallet.Wallet, error) {
 	ret := _m.Called(wltID, password)
@@ -192,6 +261,50 @@ func (_m *MockGatewayer) EncryptWallet(wltID string, password []byte) (wallet.Wa
 	return r0, r1
 }
 
+// GetAddressBalanceAt provides a mock function with given fields: addr, seq
+func (_m *MockGatewayer) GetAddressBalanceAt(addr cipher.Address, seq uint64) (*historydb.AddressBalance, error) {
+	ret := _m.Called(addr, seq)
+
+	var r0 *historydb.AddressBalance
+	if rf, ok := ret.Get(0).(func(cipher.Address, uint64) *historydb.AddressBalance); ok {
+		r0 = rf(addr, seq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*historydb.AddressBalance)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(cipher.Address, uint64) error); ok {
+		r1 = rf(addr, seq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAddressTransactionCount provides a mock function with given fields: addr
+func (_m *MockGatewayer) GetAddressTransactionCount(addr cipher.Address) (uint64, error) {
+	ret := _m.Called(addr)
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func(cipher.Address) uint64); ok {
+		r0 = rf(addr)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(cipher.Address) error); ok {
+		r1 = rf(addr)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAllStorageValues provides a mock function with given fields: storageType
 func (_m *MockGatewayer) GetAllStorageValues(storageType kvstorage.Type) (map[string]string, error) {
 	ret := _m.Called(storageType)
@@ -293,6 +406,29 @@ func (_m *MockGatewayer) GetBalanceOfAddresses(addrs []cipher.Address) ([]wallet
 	return r0, r1
 }
 
+// GetBlockchainCapacity provides a mock function with given fields:
+func (_m *MockGatewayer) GetBlockchainCapacity() (*visor.BlockchainCapacity, error) {
+	ret := _m.Called()
+
+	var r0 *visor.BlockchainCapacity
+	if rf, ok := ret.Get(0).(func() *visor.BlockchainCapacity); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*visor.BlockchainCapacity)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBlockchainMetadata provides a mock function with given fields:
 func (_m *MockGatewayer) GetBlockchainMetadata() (*visor.BlockchainMetadata, error) {
 	ret := _m.Called()
@@ -465,6 +601,22 @@ func (_m *MockGatewayer) GetConnection(addr string) (*daemon.Connection, error)
 	return r0, r1
 }
 
+// GetConnectionEvents provides a mock function with given fields:
+func (_m *MockGatewayer) GetConnectionEvents() []gnet.ConnectionEvent {
+	ret := _m.Called()
+
+	var r0 []gnet.ConnectionEvent
+	if rf, ok := ret.Get(0).(func() []gnet.ConnectionEvent); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]gnet.ConnectionEvent)
+		}
+	}
+
+	return r0
+}
+
 // GetConnections provides a mock function with given fields: f
 func (_m *MockGatewayer) GetConnections(f func(daemon.Connection) bool) ([]daemon.Connection, error) {
 	ret := _m.Called(f)
@@ -488,6 +640,45 @@ func (_m *MockGatewayer) GetConnections(f func(daemon.Connection) bool) ([]daemo
 	return r0, r1
 }
 
+// GetCorruptionCheck provides a mock function with given fields:
+func (_m *MockGatewayer) GetCorruptionCheck() *visor.CorruptionCheck {
+	ret := _m.Called()
+
+	var r0 *visor.CorruptionCheck
+	if rf, ok := ret.Get(0).(func() *visor.CorruptionCheck); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*visor.CorruptionCheck)
+		}
+	}
+
+	return r0
+}
+
+// GetDBStats provides a mock function with given fields:
+func (_m *MockGatewayer) GetDBStats() (*dbutil.DBStats, error) {
+	ret := _m.Called()
+
+	var r0 *dbutil.DBStats
+	if rf, ok := ret.Get(0).(func() *dbutil.DBStats); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dbutil.DBStats)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDefaultConnections provides a mock function with given fields:
 func (_m *MockGatewayer) GetDefaultConnections() []string {
 	ret := _m.Called()
@@ -504,6 +695,36 @@ func (_m *MockGatewayer) GetDefaultConnections() []string {
 	return r0
 }
 
+// GetEvents provides a mock function with given fields: since, typ, limit
+func (_m *MockGatewayer) GetEvents(since uint64, typ visor.EventType, limit int) ([]visor.Event, uint64, error) {
+	ret := _m.Called(since, typ, limit)
+
+	var r0 []visor.Event
+	if rf, ok := ret.Get(0).(func(uint64, visor.EventType, int) []visor.Event); ok {
+		r0 = rf(since, typ, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]visor.Event)
+		}
+	}
+
+	var r1 uint64
+	if rf, ok := ret.Get(1).(func(uint64, visor.EventType, int) uint64); ok {
+		r1 = rf(since, typ, limit)
+	} else {
+		r1 = ret.Get(1).(uint64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(uint64, visor.EventType, int) error); ok {
+		r2 = rf(since, typ, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetExchgConnection provides a mock function with given fields:
 func (_m *MockGatewayer) GetExchgConnection() []string {
 	ret := _m.Called()
@@ -520,6 +741,29 @@ func (_m *MockGatewayer) GetExchgConnection() []string {
 	return r0
 }
 
+// GetFeeHistory provides a mock function with given fields: n
+func (_m *MockGatewayer) GetFeeHistory(n uint64) ([]visor.BlockFeeHistory, error) {
+	ret := _m.Called(n)
+
+	var r0 []visor.BlockFeeHistory
+	if rf, ok := ret.Get(0).(func(uint64) []visor.BlockFeeHistory); ok {
+		r0 = rf(n)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]visor.BlockFeeHistory)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uint64) error); ok {
+		r1 = rf(n)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetLastBlocks provides a mock function with given fields: num
 func (_m *MockGatewayer) GetLastBlocks(num uint64) ([]coin.SignedBlock, error) {
 	ret := _m.Called(num)
@@ -899,6 +1143,29 @@ func (_m *MockGatewayer) GetTrustConnections() []string {
 	return r0
 }
 
+// GetUnconfirmedStats provides a mock function with given fields:
+func (_m *MockGatewayer) GetUnconfirmedStats() (*visor.UnconfirmedStats, error) {
+	ret := _m.Called()
+
+	var r0 *visor.UnconfirmedStats
+	if rf, ok := ret.Get(0).(func() *visor.UnconfirmedStats); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*visor.UnconfirmedStats)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUnspentOutputsSummary provides a mock function with given fields: filters
 func (_m *MockGatewayer) GetUnspentOutputsSummary(filters []visor.OutputsFilter) (*visor.UnspentOutputsSummary, error) {
 	ret := _m.Called(filters)
@@ -975,6 +1242,36 @@ func (_m *MockGatewayer) GetWallet(wltID string) (wallet.Wallet, error) {
 	return r0, r1
 }
 
+// GetWalletAddressStats provides a mock function with given fields: wltID
+func (_m *MockGatewayer) GetWalletAddressStats(wltID string) ([]visor.AddressStats, bool, error) {
+	ret := _m.Called(wltID)
+
+	var r0 []visor.AddressStats
+	if rf, ok := ret.Get(0).(func(string) []visor.AddressStats); ok {
+		r0 = rf(wltID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]visor.AddressStats)
+		}
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(wltID)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(wltID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetWalletBalance provides a mock function with given fields: wltID
 func (_m *MockGatewayer) GetWalletBalance(wltID string) (wallet.BalancePair, wallet.AddressBalances, error) {
 	ret := _m.Called(wltID)
@@ -1241,6 +1538,20 @@ func (_m *MockGatewayer) RemoveStorageValue(storageType kvstorage.Type, key stri
 	return r0
 }
 
+// RequestCoinJoin provides a mock function with given fields: coordinatorAddr, inputs, keys, output
+func (_m *MockGatewayer) RequestCoinJoin(coordinatorAddr string, inputs []cipher.SHA256, keys []cipher.SecKey, output coin.TransactionOutput) error {
+	ret := _m.Called(coordinatorAddr, inputs, keys, output)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []cipher.SHA256, []cipher.SecKey, coin.TransactionOutput) error); ok {
+		r0 = rf(coordinatorAddr, inputs, keys, output)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ResendUnconfirmedTxns provides a mock function with given fields:
 func (_m *MockGatewayer) ResendUnconfirmedTxns() ([]cipher.SHA256, error) {
 	ret := _m.Called()
@@ -1264,6 +1575,20 @@ func (_m *MockGatewayer) ResendUnconfirmedTxns() ([]cipher.SHA256, error) {
 	return r0, r1
 }
 
+// RestartInfo provides a mock function with given fields:
+func (_m *MockGatewayer) RestartInfo() visor.RestartInfo {
+	ret := _m.Called()
+
+	var r0 visor.RestartInfo
+	if rf, ok := ret.Get(0).(func() visor.RestartInfo); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(visor.RestartInfo)
+	}
+
+	return r0
+}
+
 // ScanAddresses provides a mock function with given fields: wltID, password, n, tf
 func (_m *MockGatewayer) ScanAddresses(wltID string, password []byte, n uint64, tf wallet.TransactionsFinder) ([]cipher.Address, error) {
 	ret := _m.Called(wltID, password, n, tf)
@@ -1310,6 +1635,64 @@ func (_m *MockGatewayer) ScanWalletAddresses(wltID string, password []byte, num
 	return r0, r1
 }
 
+// SessionPassword provides a mock function with given fields: wltID, pin
+func (_m *MockGatewayer) SessionPassword(wltID string, pin []byte) ([]byte, error) {
+	ret := _m.Called(wltID, pin)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(string, []byte) []byte); ok {
+		r0 = rf(wltID, pin)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []byte) error); ok {
+		r1 = rf(wltID, pin)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StartCorruptionCheck provides a mock function with given fields:
+func (_m *MockGatewayer) StartCorruptionCheck() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StartSession provides a mock function with given fields: wltID, password, pin
+func (_m *MockGatewayer) StartSession(wltID string, password []byte, pin []byte) (time.Time, error) {
+	ret := _m.Called(wltID, password, pin)
+
+	var r0 time.Time
+	if rf, ok := ret.Get(0).(func(string, []byte, []byte) time.Time); ok {
+		r0 = rf(wltID, password, pin)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []byte, []byte) error); ok {
+		r1 = rf(wltID, password, pin)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // StartedAt provides a mock function with given fields:
 func (_m *MockGatewayer) StartedAt() time.Time {
 	ret := _m.Called()
@@ -1324,6 +1707,29 @@ func (_m *MockGatewayer) StartedAt() time.Time {
 	return r0
 }
 
+// StatsByHeight provides a mock function with given fields: start, end
+func (_m *MockGatewayer) StatsByHeight(start uint64, end uint64) ([]visor.BlockStats, error) {
+	ret := _m.Called(start, end)
+
+	var r0 []visor.BlockStats
+	if rf, ok := ret.Get(0).(func(uint64, uint64) []visor.BlockStats); ok {
+		r0 = rf(start, end)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]visor.BlockStats)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uint64, uint64) error); ok {
+		r1 = rf(start, end)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // TransactionsFinder provides a mock function with given fields:
 func (_m *MockGatewayer) TransactionsFinder() wallet.TransactionsFinder {
 	ret := _m.Called()
@@ -1340,6 +1746,20 @@ func (_m *MockGatewayer) TransactionsFinder() wallet.TransactionsFinder {
 	return r0
 }
 
+// UnconfirmedEvictions provides a mock function with given fields:
+func (_m *MockGatewayer) UnconfirmedEvictions() uint64 {
+	ret := _m.Called()
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func() uint64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	return r0
+}
+
 // UnloadWallet provides a mock function with given fields: wltID
 func (_m *MockGatewayer) UnloadWallet(wltID string) error {
 	ret := _m.Called(wltID)
@@ -1368,6 +1788,52 @@ func (_m *MockGatewayer) UpdateWalletLabel(wltID string, label string) error {
 	return r0
 }
 
+// UpdateWalletMeta provides a mock function with given fields: wltID, label, meta
+func (_m *MockGatewayer) UpdateWalletMeta(wltID string, label string, meta map[string]string) (wallet.Wallet, error) {
+	ret := _m.Called(wltID, label, meta)
+
+	var r0 wallet.Wallet
+	if rf, ok := ret.Get(0).(func(string, string, map[string]string) wallet.Wallet); ok {
+		r0 = rf(wltID, label, meta)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(wallet.Wallet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, map[string]string) error); ok {
+		r1 = rf(wltID, label, meta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifyTransaction provides a mock function with given fields: txn, signed
+func (_m *MockGatewayer) VerifyTransaction(txn coin.Transaction, signed visor.TxnSignedFlag) ([]visor.ValidationError, error) {
+	ret := _m.Called(txn, signed)
+
+	var r0 []visor.ValidationError
+	if rf, ok := ret.Get(0).(func(coin.Transaction, visor.TxnSignedFlag) []visor.ValidationError); ok {
+		r0 = rf(txn, signed)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]visor.ValidationError)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(coin.Transaction, visor.TxnSignedFlag) error); ok {
+		r1 = rf(txn, signed)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // VerifyTxnVerbose provides a mock function with given fields: txn, signed
 func (_m *MockGatewayer) VerifyTxnVerbose(txn *coin.Transaction, signed visor.TxnSignedFlag) ([]visor.TransactionInput, bool, error) {
 	ret := _m.Called(txn, signed)
@@ -1412,6 +1878,29 @@ func (_m *MockGatewayer) VisorConfig() visor.Config {
 	return r0
 }
 
+// WalletCoinJoinKeys provides a mock function with given fields: wltID, password, inputs
+func (_m *MockGatewayer) WalletCoinJoinKeys(wltID string, password []byte, inputs []cipher.SHA256) ([]cipher.SecKey, error) {
+	ret := _m.Called(wltID, password, inputs)
+
+	var r0 []cipher.SecKey
+	if rf, ok := ret.Get(0).(func(string, []byte, []cipher.SHA256) []cipher.SecKey); ok {
+		r0 = rf(wltID, password, inputs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]cipher.SecKey)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []byte, []cipher.SHA256) error); ok {
+		r1 = rf(wltID, password, inputs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // WalletCreateTransaction provides a mock function with given fields: wltID, p, wp
 func (_m *MockGatewayer) WalletCreateTransaction(wltID string, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error) {
 	ret := _m.Called(wltID, p, wp)
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/daemon/gnet"
 	"github.com/skycoin/skycoin/src/readable"
 	wh "github.com/skycoin/skycoin/src/util/http"
 )
@@ -226,3 +227,81 @@ func disconnectHandler(gateway Gatewayer) http.HandlerFunc {
 		wh.SendJSONOr500(logger, w, struct{}{})
 	}
 }
+
+// NetworkGraphNode is a peer in the network graph
+type NetworkGraphNode struct {
+	Address string `json:"address"`
+	// Connected is true if the last recorded event of the peer is a connect
+	Connected bool `json:"connected"`
+}
+
+// NetworkGraphEdge is a connect or disconnect of a peer in the network graph
+type NetworkGraphEdge struct {
+	Peer string `json:"peer"`
+	// Type is "connect" or "disconnect"
+	Type     string `json:"type"`
+	ID       uint64 `json:"id"`
+	Outgoing bool   `json:"outgoing"`
+	Time     int64  `json:"time"`
+	// Bytes transferred over the connection until the event, 0 for a connect
+	BytesSent     uint64 `json:"bytes_sent"`
+	BytesReceived uint64 `json:"bytes_received"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+// NetworkGraph is returned by GET /api/v2/network/graph
+type NetworkGraph struct {
+	Nodes []NetworkGraphNode `json:"nodes"`
+	Edges []NetworkGraphEdge `json:"edges"`
+}
+
+// newNetworkGraph creates a NetworkGraph from connection events, the nodes are in the order of their first event
+func newNetworkGraph(events []gnet.ConnectionEvent) NetworkGraph {
+	g := NetworkGraph{
+		Nodes: []NetworkGraphNode{},
+		Edges: make([]NetworkGraphEdge, len(events)),
+	}
+
+	nodes := make(map[string]int)
+	for i, e := range events {
+		n, ok := nodes[e.Addr]
+		if !ok {
+			n = len(g.Nodes)
+			nodes[e.Addr] = n
+			g.Nodes = append(g.Nodes, NetworkGraphNode{
+				Address: e.Addr,
+			})
+		}
+		g.Nodes[n].Connected = e.Type == gnet.ConnectionEventConnect
+
+		g.Edges[i] = NetworkGraphEdge{
+			Peer:          e.Addr,
+			Type:          string(e.Type),
+			ID:            e.ID,
+			Outgoing:      e.Outgoing,
+			Time:          e.Time.Unix(),
+			BytesSent:     e.BytesSent,
+			BytesReceived: e.BytesReceived,
+			Reason:        e.Reason,
+		}
+	}
+
+	return g
+}
+
+// networkGraphHandler returns the last peer connection events as a graph, with the peers as nodes
+// and the connection events as edges, oldest first
+// URI: /api/v2/network/graph
+// Method: GET
+func networkGraphHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: newNetworkGraph(gateway.GetConnectionEvents()),
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/daemon/gnet"
 	"github.com/skycoin/skycoin/src/daemon/pex"
 	"github.com/skycoin/skycoin/src/readable"
 	"github.com/skycoin/skycoin/src/util/useragent"
@@ -628,3 +629,139 @@ func TestDisconnect(t *testing.T) {
 		})
 	}
 }
+
+func TestNetworkGraph(t *testing.T) {
+	t0 := time.Unix(1540000000, 0)
+	events := []gnet.ConnectionEvent{
+		{
+			Type:     gnet.ConnectionEventConnect,
+			Addr:     "1.2.3.4:6000",
+			ID:       1,
+			Outgoing: true,
+			Time:     t0,
+		},
+		{
+			Type: gnet.ConnectionEventConnect,
+			Addr: "5.6.7.8:6000",
+			ID:   2,
+			Time: t0.Add(time.Second),
+		},
+		{
+			Type:          gnet.ConnectionEventDisconnect,
+			Addr:          "1.2.3.4:6000",
+			ID:            1,
+			Outgoing:      true,
+			Time:          t0.Add(time.Second * 2),
+			BytesSent:     100,
+			BytesReceived: 200,
+			Reason:        "Idle",
+		},
+	}
+
+	cases := []struct {
+		name         string
+		method       string
+		status       int
+		events       []gnet.ConnectionEvent
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:   "200 - no events",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			httpResponse: HTTPResponse{
+				Data: NetworkGraph{
+					Nodes: []NetworkGraphNode{},
+					Edges: []NetworkGraphEdge{},
+				},
+			},
+		},
+		{
+			name:   "200",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			events: events,
+			httpResponse: HTTPResponse{
+				Data: NetworkGraph{
+					Nodes: []NetworkGraphNode{
+						{
+							Address:   "1.2.3.4:6000",
+							Connected: false,
+						},
+						{
+							Address:   "5.6.7.8:6000",
+							Connected: true,
+						},
+					},
+					Edges: []NetworkGraphEdge{
+						{
+							Peer:     "1.2.3.4:6000",
+							Type:     "connect",
+							ID:       1,
+							Outgoing: true,
+							Time:     1540000000,
+						},
+						{
+							Peer: "5.6.7.8:6000",
+							Type: "connect",
+							ID:   2,
+							Time: 1540000001,
+						},
+						{
+							Peer:          "1.2.3.4:6000",
+							Type:          "disconnect",
+							ID:            1,
+							Outgoing:      true,
+							Time:          1540000002,
+							BytesSent:     100,
+							BytesReceived: 200,
+							Reason:        "Idle",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetConnectionEvents").Return(tc.events)
+
+			req, err := http.NewRequest(tc.method, "/api/v2/network/graph", nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var graphRsp NetworkGraph
+				err := json.Unmarshal(rsp.Data, &graphRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(NetworkGraph), graphRsp)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	wh "github.com/skycoin/skycoin/src/util/http"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+const nodeMetricsNamespace = "skycoin"
+
+// nodeMetrics are the node metrics served on /metrics when Config.EnableMetrics is set.
+// They are registered in their own registry so that they don't mix with the
+// process metrics served by the /api/v2/metrics endpoint in the default registry.
+type nodeMetrics struct {
+	registry *prometheus.Registry
+
+	blockHeight     prometheus.Gauge
+	peerCount       prometheus.Gauge
+	unconfirmedTxns prometheus.Gauge
+	blocksPerMinute prometheus.Gauge
+	apiRequests     prometheus.Counter
+	dbCheckDuration prometheus.Gauge
+	poolEvictions   prometheus.Counter
+	maxBlockSize    prometheus.Gauge
+	blockSizes      *blockSizeCollector
+
+	// Head block seq and time of the previous scrape, used to calculate blocksPerMinute
+	sync.Mutex
+	lastSeq  uint64
+	lastTime time.Time
+	// Unconfirmed pool evictions at the previous scrape, used to increment poolEvictions
+	lastEvictions uint64
+}
+
+func newNodeMetrics(dbCheckDuration time.Duration) *nodeMetrics {
+	m := &nodeMetrics{
+		registry: prometheus.NewRegistry(),
+		blockHeight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: nodeMetricsNamespace,
+			Name:      "block_height",
+			Help:      "Sequence number of the head block",
+		}),
+		peerCount: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: nodeMetricsNamespace,
+			Name:      "peer_count",
+			Help:      "Number of connected peers",
+		}),
+		unconfirmedTxns: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: nodeMetricsNamespace,
+			Name:      "unconfirmed_txns",
+			Help:      "Number of unconfirmed transactions",
+		}),
+		blocksPerMinute: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: nodeMetricsNamespace,
+			Name:      "blocks_processed_per_minute",
+			Help:      "Number of blocks added to the blockchain per minute, since the previous scrape",
+		}),
+		apiRequests: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: nodeMetricsNamespace,
+			Name:      "api_requests_total",
+			Help:      "Number of HTTP API requests handled",
+		}),
+		dbCheckDuration: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: nodeMetricsNamespace,
+			Name:      "db_check_duration_seconds",
+			Help:      "Duration of the database check at startup",
+		}),
+		poolEvictions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: nodeMetricsNamespace,
+			Name:      "pool_evictions_total",
+			Help:      "Number of transactions evicted from the full unconfirmed transaction pool",
+		}),
+		maxBlockSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: nodeMetricsNamespace,
+			Name:      "max_block_size_bytes",
+			Help:      "Maximum size of the transactions of the next block",
+		}),
+		blockSizes: newBlockSizeCollector(),
+	}
+
+	m.registry.MustRegister(
+		m.blockHeight,
+		m.peerCount,
+		m.unconfirmedTxns,
+		m.blocksPerMinute,
+		m.apiRequests,
+		m.dbCheckDuration,
+		m.poolEvictions,
+		m.maxBlockSize,
+		m.blockSizes,
+	)
+
+	m.dbCheckDuration.Set(dbCheckDuration.Seconds())
+
+	return m
+}
+
+// blockSizeCollector exposes the block size histogram stored by the visor as a prometheus histogram
+type blockSizeCollector struct {
+	desc *prometheus.Desc
+
+	sync.Mutex
+	histogram visor.BlockSizeHistogram
+}
+
+func newBlockSizeCollector() *blockSizeCollector {
+	return &blockSizeCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(nodeMetricsNamespace, "", "block_size_bytes"),
+			"Size of the transactions of the blocks in the blockchain",
+			nil, nil,
+		),
+	}
+}
+
+func (c *blockSizeCollector) set(h visor.BlockSizeHistogram) {
+	c.Lock()
+	defer c.Unlock()
+	c.histogram = h
+}
+
+// Describe implements prometheus.Collector
+func (c *blockSizeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector
+func (c *blockSizeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.Lock()
+	defer c.Unlock()
+
+	// Prometheus buckets are cumulative
+	buckets := make(map[float64]uint64, len(c.histogram.Buckets))
+	var n uint64
+	for i, b := range c.histogram.Buckets {
+		n += c.histogram.Counts[i]
+		buckets[float64(b)] = n
+	}
+
+	ch <- prometheus.MustNewConstHistogram(c.desc, c.histogram.Count, float64(c.histogram.Sum), buckets)
+}
+
+// countRequests wraps a handler to count the requests it handles
+func (m *nodeMetrics) countRequests(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.apiRequests.Inc()
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// updateBlocks sets the block height and calculates the block rate since the previous update
+func (m *nodeMetrics) updateBlocks(seq uint64, now time.Time) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.blockHeight.Set(float64(seq))
+
+	if !m.lastTime.IsZero() && now.After(m.lastTime) && seq >= m.lastSeq {
+		m.blocksPerMinute.Set(float64(seq-m.lastSeq) / now.Sub(m.lastTime).Minutes())
+	}
+
+	m.lastSeq = seq
+	m.lastTime = now
+}
+
+// updateEvictions increments the evictions counter by the evictions since the previous update
+func (m *nodeMetrics) updateEvictions(evictions uint64) {
+	m.Lock()
+	defer m.Unlock()
+
+	if evictions > m.lastEvictions {
+		m.poolEvictions.Add(float64(evictions - m.lastEvictions))
+	}
+
+	m.lastEvictions = evictions
+}
+
+func nodeMetricsHandler(c muxConfig, gateway Gatewayer, m *nodeMetrics) http.HandlerFunc {
+	promHandler := promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		health, err := getHealthData(c, gateway)
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		m.updateBlocks(health.BlockchainMetadata.Head.BkSeq, time.Now())
+		m.peerCount.Set(float64(health.OpenConnections))
+		m.unconfirmedTxns.Set(float64(health.BlockchainMetadata.Unconfirmed))
+		m.updateEvictions(gateway.UnconfirmedEvictions())
+
+		capacity, err := gateway.GetBlockchainCapacity()
+		if err != nil {
+			wh.Error500(w, fmt.Sprintf("gateway.GetBlockchainCapacity failed: %v", err))
+			return
+		}
+
+		m.maxBlockSize.Set(float64(capacity.MaxBlockSize))
+		m.blockSizes.set(capacity.Histogram)
+
+		promHandler.ServeHTTP(w, r)
+	}
+}
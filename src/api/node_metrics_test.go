@@ -0,0 +1,196 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func TestNodeMetricsHandler(t *testing.T) {
+	cases := []struct {
+		name                     string
+		method                   string
+		enableMetrics            bool
+		code                     int
+		err                      string
+		getBlockchainMetadataErr error
+	}{
+		{
+			name:   "metrics disabled",
+			method: http.MethodGet,
+			code:   http.StatusNotFound,
+			err:    "404 Not Found",
+		},
+		{
+			name:          "405 method not allowed",
+			method:        http.MethodPost,
+			enableMetrics: true,
+			code:          http.StatusMethodNotAllowed,
+			err:           "405 Method Not Allowed",
+		},
+		{
+			name:                     "gateway.GetBlockchainMetadata error",
+			method:                   http.MethodGet,
+			enableMetrics:            true,
+			code:                     http.StatusInternalServerError,
+			err:                      "500 Internal Server Error - gateway.GetBlockchainMetadata failed: GetBlockchainMetadata failed",
+			getBlockchainMetadataErr: errors.New("GetBlockchainMetadata failed"),
+		},
+		{
+			name:          "valid response",
+			method:        http.MethodGet,
+			enableMetrics: true,
+			code:          http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			metadata := visor.BlockchainMetadata{
+				HeadBlock: coin.SignedBlock{
+					Block: coin.Block{
+						Head: coin.BlockHeader{
+							BkSeq: 21175,
+							Time:  1523168686,
+						},
+					},
+				},
+				Unspents:    10,
+				Unconfirmed: 20,
+			}
+
+			conns := []daemon.Connection{
+				{
+					ConnectionDetails: daemon.ConnectionDetails{
+						Outgoing: true,
+						State:    daemon.ConnectionStateIntroduced,
+					},
+				},
+				{
+					ConnectionDetails: daemon.ConnectionDetails{
+						Outgoing: false,
+						State:    daemon.ConnectionStateConnected,
+					},
+				},
+			}
+
+			gateway := &MockGatewayer{}
+			if tc.getBlockchainMetadataErr != nil {
+				gateway.On("GetBlockchainMetadata").Return(nil, tc.getBlockchainMetadataErr)
+			} else {
+				gateway.On("GetBlockchainMetadata").Return(&metadata, nil)
+			}
+			gateway.On("GetConnections", mock.Anything).Return(conns, nil)
+			gateway.On("StartedAt").Return(time.Now())
+			gateway.On("RestartInfo").Return(visor.RestartInfo{})
+			gateway.On("UnconfirmedEvictions").Return(uint64(3))
+			gateway.On("DaemonConfig").Return(daemon.DaemonConfig{})
+			gateway.On("GetBlockchainCapacity").Return(&visor.BlockchainCapacity{
+				MaxBlockSize: 32768,
+				Histogram: visor.BlockSizeHistogram{
+					Buckets: []uint32{1024, 4096},
+					Counts:  []uint64{3, 2, 1},
+					Count:   6,
+					Sum:     12000,
+					FromSeq: 0,
+					ToSeq:   5,
+				},
+			}, nil)
+
+			cfg := defaultMuxConfig()
+			cfg.enableMetrics = tc.enableMetrics
+			cfg.dbCheckDuration = time.Millisecond * 1500
+			cfg.health.DaemonUserAgent.Coin = "skycoin"
+			cfg.health.DaemonUserAgent.Version = "0.25.0"
+
+			req, err := http.NewRequest(tc.method, "/metrics", nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(cfg, gateway)
+			handler.ServeHTTP(rr, req)
+
+			if tc.code != http.StatusOK {
+				require.Equal(t, tc.code, rr.Code)
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			body := rr.Body.String()
+			require.Contains(t, body, "skycoin_block_height 21175\n")
+			require.Contains(t, body, "skycoin_peer_count 2\n")
+			require.Contains(t, body, "skycoin_unconfirmed_txns 20\n")
+			require.Contains(t, body, "skycoin_db_check_duration_seconds 1.5\n")
+			require.Contains(t, body, "skycoin_blocks_processed_per_minute 0\n")
+			require.Contains(t, body, "skycoin_pool_evictions_total 3\n")
+			require.Contains(t, body, "skycoin_max_block_size_bytes 32768\n")
+			require.Contains(t, body, "skycoin_block_size_bytes_bucket{le=\"1024\"} 3\n")
+			require.Contains(t, body, "skycoin_block_size_bytes_bucket{le=\"4096\"} 5\n")
+			require.Contains(t, body, "skycoin_block_size_bytes_bucket{le=\"+Inf\"} 6\n")
+			require.Contains(t, body, "skycoin_block_size_bytes_sum 12000\n")
+			require.Contains(t, body, "skycoin_block_size_bytes_count 6\n")
+			// The /metrics request itself is counted
+			require.Contains(t, body, "skycoin_api_requests_total 1\n")
+			// Process metrics of the default registry are not included
+			require.NotContains(t, body, "go_goroutines")
+		})
+	}
+}
+
+func gaugeValue(t *testing.T, g prometheus.Gauge) float64 {
+	var m dto.Metric
+	require.NoError(t, g.Write(&m))
+	return m.GetGauge().GetValue()
+}
+
+func TestNodeMetricsUpdateBlocks(t *testing.T) {
+	m := newNodeMetrics(0)
+	now := time.Now()
+
+	m.updateBlocks(10, now)
+	require.Equal(t, float64(10), gaugeValue(t, m.blockHeight))
+	require.Equal(t, float64(0), gaugeValue(t, m.blocksPerMinute))
+
+	m.updateBlocks(16, now.Add(time.Second*30))
+	require.Equal(t, float64(16), gaugeValue(t, m.blockHeight))
+	require.Equal(t, float64(12), gaugeValue(t, m.blocksPerMinute))
+
+	m.updateBlocks(16, now.Add(time.Minute*2))
+	require.Equal(t, float64(0), gaugeValue(t, m.blocksPerMinute))
+}
+
+func TestNodeMetricsUpdateEvictions(t *testing.T) {
+	m := newNodeMetrics(0)
+
+	counterValue := func() float64 {
+		var d dto.Metric
+		require.NoError(t, m.poolEvictions.Write(&d))
+		return d.GetCounter().GetValue()
+	}
+
+	m.updateEvictions(0)
+	require.Equal(t, float64(0), counterValue())
+
+	m.updateEvictions(5)
+	require.Equal(t, float64(5), counterValue())
+
+	m.updateEvictions(7)
+	require.Equal(t, float64(7), counterValue())
+
+	m.updateEvictions(7)
+	require.Equal(t, float64(7), counterValue())
+}
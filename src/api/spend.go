@@ -11,6 +11,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon"
 	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/transaction"
 	"github.com/skycoin/skycoin/src/util/droplet"
@@ -268,6 +269,7 @@ type createTransactionRequest struct {
 	To                []receiver     `json:"to"`
 	UxOuts            []wh.SHA256    `json:"unspents,omitempty"`
 	Addresses         []wh.Address   `json:"addresses,omitempty"`
+	SortTx            bool           `json:"sort_tx"`
 }
 
 // hoursSelection defines options for hours distribution
@@ -440,8 +442,9 @@ func (r createTransactionRequest) TransactionParams() transaction.Params {
 			Mode:        r.HoursSelection.Mode,
 			ShareFactor: r.HoursSelection.ShareFactor,
 		},
-		ChangeAddress: changeAddress,
-		To:            to,
+		ChangeAddress:     changeAddress,
+		To:                to,
+		SortInputsOutputs: r.SortTx,
 	}
 }
 
@@ -545,6 +548,8 @@ type walletCreateTransactionRequest struct {
 	Unsigned bool   `json:"unsigned"`
 	WalletID string `json:"wallet_id"`
 	Password string `json:"password"`
+	// PIN of the wallet session, sent instead of the password
+	PIN string `json:"pin"`
 	createTransactionRequest
 }
 
@@ -558,6 +563,14 @@ func (r walletCreateTransactionRequest) Validate() error {
 		return errors.New("password must not be used for unsigned transactions")
 	}
 
+	if r.Unsigned && len(r.PIN) != 0 {
+		return errors.New("pin must not be used for unsigned transactions")
+	}
+
+	if len(r.Password) != 0 && len(r.PIN) != 0 {
+		return errors.New("password and pin must not be used together")
+	}
+
 	return r.createTransactionRequest.Validate()
 }
 
@@ -596,7 +609,11 @@ func walletCreateTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 		if req.Unsigned {
 			txn, inputs, err = gateway.WalletCreateTransaction(req.WalletID, req.TransactionParams(), req.VisorParams())
 		} else {
-			txn, inputs, err = gateway.WalletCreateTransactionSigned(req.WalletID, []byte(req.Password), req.TransactionParams(), req.VisorParams())
+			var password []byte
+			password, err = walletPassword(gateway, req.WalletID, req.Password, req.PIN)
+			if err == nil {
+				txn, inputs, err = gateway.WalletCreateTransactionSigned(req.WalletID, password, req.TransactionParams(), req.VisorParams())
+			}
 		}
 		if err != nil {
 			switch err.(type) {
@@ -638,8 +655,10 @@ func walletCreateTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 
 // WalletSignTransactionRequest is the request body object for /api/v2/wallet/transaction/sign
 type WalletSignTransactionRequest struct {
-	WalletID           string `json:"wallet_id"`
-	Password           string `json:"password"`
+	WalletID string `json:"wallet_id"`
+	Password string `json:"password"`
+	// PIN of the wallet session, sent instead of the password
+	PIN                string `json:"pin"`
 	EncodedTransaction string `json:"encoded_transaction"`
 	SignIndexes        []int  `json:"sign_indexes"`
 }
@@ -675,6 +694,12 @@ func walletSignTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
+		if req.Password != "" && req.PIN != "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "password and pin must not be used together")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
 		txn, err := decodeTxn(req.EncodedTransaction)
 		if err != nil {
 			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("Decode transaction failed: %v", err))
@@ -709,7 +734,12 @@ func walletSignTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 			signIndexesMap[i] = struct{}{}
 		}
 
-		signedTxn, inputs, err := gateway.WalletSignTransaction(req.WalletID, []byte(req.Password), txn, req.SignIndexes)
+		var signedTxn *coin.Transaction
+		var inputs []visor.TransactionInput
+		password, err := walletPassword(gateway, req.WalletID, req.Password, req.PIN)
+		if err == nil {
+			signedTxn, inputs, err = gateway.WalletSignTransaction(req.WalletID, password, txn, req.SignIndexes)
+		}
 		if err != nil {
 			var resp HTTPResponse
 			switch err.(type) {
@@ -746,3 +776,255 @@ func walletSignTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 		})
 	}
 }
+
+// WalletConsolidateRequest is the request body object for /api/v2/wallet/{id}/consolidate
+type WalletConsolidateRequest struct {
+	Unsigned bool   `json:"unsigned"`
+	Password string `json:"password"`
+	// PIN of the wallet session, sent instead of the password
+	PIN string `json:"pin"`
+}
+
+// walletConsolidateHandler creates a transaction that consolidates the unspent outputs of a wallet address:
+// up to max_inputs of the outputs with the fewest coins are spent, and their coins and hours minus the fee
+// are sent back to the address.
+// The transaction is not injected.
+// Method: POST
+// URI: /api/v2/wallet/{id}/consolidate
+// Args:
+//	address [string]. Address of the wallet whose outputs are consolidated
+//	max_inputs [int, optional]. Maximum number of outputs to spend, at least 2.
+//	    Defaults to as many outputs as fit in a transaction
+//	JSON body: unsigned [bool, optional], password [string, optional], pin [string, optional]
+func walletConsolidateHandler(gateway Gatewayer) func(w http.ResponseWriter, r *http.Request, wltID string) {
+	return func(w http.ResponseWriter, r *http.Request, wltID string) {
+		if r.Method != http.MethodPost {
+			writeError405Response(w)
+			return
+		}
+
+		addrStr := r.FormValue("address")
+		if addrStr == "" {
+			writeError400Response(w, "address is required")
+			return
+		}
+
+		addr, err := cipher.DecodeBase58Address(addrStr)
+		if err != nil {
+			writeError400Response(w, fmt.Sprintf("invalid address: %v", err))
+			return
+		}
+
+		var maxInputs int
+		if s := r.FormValue("max_inputs"); s != "" {
+			n, err := strconv.ParseUint(s, 10, 16)
+			if err != nil {
+				writeError400Response(w, fmt.Sprintf("invalid 'max_inputs' value: %v", err))
+				return
+			}
+			if n < 2 {
+				writeError400Response(w, "max_inputs must be at least 2")
+				return
+			}
+			maxInputs = int(n)
+		}
+
+		var req WalletConsolidateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError400Response(w, err.Error())
+			return
+		}
+
+		if req.Unsigned && len(req.Password) != 0 {
+			writeError400Response(w, "password must not be used for unsigned transactions")
+			return
+		}
+
+		if req.Unsigned && len(req.PIN) != 0 {
+			writeError400Response(w, "pin must not be used for unsigned transactions")
+			return
+		}
+
+		if len(req.Password) != 0 && len(req.PIN) != 0 {
+			writeError400Response(w, "password and pin must not be used together")
+			return
+		}
+
+		var txn *coin.Transaction
+		var inputs []visor.TransactionInput
+		p, wp, err := gateway.CreateConsolidationParams(wltID, addr, maxInputs)
+		if err == nil {
+			if req.Unsigned {
+				txn, inputs, err = gateway.WalletCreateTransaction(wltID, p, wp)
+			} else {
+				var password []byte
+				password, err = walletPassword(gateway, wltID, req.Password, req.PIN)
+				if err == nil {
+					txn, inputs, err = gateway.WalletCreateTransactionSigned(wltID, password, p, wp)
+				}
+			}
+		}
+		if err != nil {
+			var resp HTTPResponse
+			switch err.(type) {
+			case wallet.Error:
+				switch err {
+				case wallet.ErrWalletNotExist:
+					resp = NewHTTPErrorResponse(http.StatusNotFound, "")
+				case wallet.ErrWalletAPIDisabled:
+					resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+				default:
+					resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+				}
+			case blockdb.ErrUnspentNotExist, transaction.Error, visor.UserError:
+				resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			default:
+				switch err {
+				case fee.ErrTxnNoFee, fee.ErrTxnInsufficientCoinHours:
+					resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+				default:
+					resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+				}
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		txnResp, err := NewCreateTransactionResponse(txn, inputs)
+		if err != nil {
+			writeError500Response(w, fmt.Sprintf("NewCreateTransactionResponse failed: %v", err))
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: txnResp,
+		})
+	}
+}
+
+// WalletCoinJoinRequest is the request body object for /api/v2/wallet/{id}/coinjoin
+type WalletCoinJoinRequest struct {
+	// Address of the coinjoin coordinator, which must be a connected peer
+	Coordinator string `json:"coordinator"`
+	// Unspent outputs of the wallet spent by the coinjoin transaction
+	UxOuts []string `json:"unspents"`
+	// Output that receives the coins of the unspent outputs
+	Address string `json:"address"`
+	Coins   string `json:"coins"`
+	Hours   string `json:"hours"`
+	// Password of the wallet
+	Password string `json:"password"`
+	// PIN of the wallet session, sent instead of the password
+	PIN string `json:"pin"`
+}
+
+// walletCoinJoinHandler sends a join request to a coinjoin coordinator, to spend unspent outputs of the wallet
+// in the next coinjoin round. The coins of the outputs must equal the coins of the output, the coin hours
+// that are not sent to the output pay the fee. The node signs the coinjoin transaction when the coordinator
+// sends it, if it spends the unspent outputs to the output. A new request replaces the previous request
+// sent to the same coordinator.
+// Method: POST
+// URI: /api/v2/wallet/{id}/coinjoin
+// Args: JSON body
+func walletCoinJoinHandler(gateway Gatewayer) func(w http.ResponseWriter, r *http.Request, wltID string) {
+	return func(w http.ResponseWriter, r *http.Request, wltID string) {
+		if r.Method != http.MethodPost {
+			writeError405Response(w)
+			return
+		}
+
+		var req WalletCoinJoinRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError400Response(w, err.Error())
+			return
+		}
+
+		if req.Coordinator == "" {
+			writeError400Response(w, "coordinator is required")
+			return
+		}
+
+		if len(req.UxOuts) == 0 {
+			writeError400Response(w, "unspents is required")
+			return
+		}
+
+		inputs := make([]cipher.SHA256, len(req.UxOuts))
+		for i, s := range req.UxOuts {
+			h, err := cipher.SHA256FromHex(s)
+			if err != nil {
+				writeError400Response(w, fmt.Sprintf("invalid unspent %q: %v", s, err))
+				return
+			}
+			inputs[i] = h
+		}
+
+		addr, err := cipher.DecodeBase58Address(req.Address)
+		if err != nil {
+			writeError400Response(w, fmt.Sprintf("invalid address: %v", err))
+			return
+		}
+
+		coins, err := droplet.FromString(req.Coins)
+		if err != nil {
+			writeError400Response(w, fmt.Sprintf("invalid coins value: %v", err))
+			return
+		}
+
+		hours, err := strconv.ParseUint(req.Hours, 10, 64)
+		if err != nil {
+			writeError400Response(w, fmt.Sprintf("invalid hours value: %v", err))
+			return
+		}
+
+		if req.Password != "" && req.PIN != "" {
+			writeError400Response(w, "password and pin must not be used together")
+			return
+		}
+
+		output := coin.TransactionOutput{
+			Address: addr,
+			Coins:   coins,
+			Hours:   hours,
+		}
+
+		var keys []cipher.SecKey
+		password, err := walletPassword(gateway, wltID, req.Password, req.PIN)
+		if err == nil {
+			keys, err = gateway.WalletCoinJoinKeys(wltID, password, inputs)
+		}
+		if err == nil {
+			err = gateway.RequestCoinJoin(req.Coordinator, inputs, keys, output)
+		}
+		if err != nil {
+			var resp HTTPResponse
+			switch err.(type) {
+			case wallet.Error:
+				switch err {
+				case wallet.ErrWalletNotExist:
+					resp = NewHTTPErrorResponse(http.StatusNotFound, "")
+				case wallet.ErrWalletAPIDisabled:
+					resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+				default:
+					resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+				}
+			case blockdb.ErrUnspentNotExist, visor.UserError:
+				resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			default:
+				switch err {
+				case daemon.ErrConnectionNotExist,
+					daemon.ErrCoinJoinCoordinatorNotIntroduced,
+					daemon.ErrCoinJoinInvalidOutput,
+					daemon.ErrCoinJoinCoinsMismatch:
+					resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+				default:
+					resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+				}
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{})
+	}
+}
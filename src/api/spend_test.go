@@ -15,6 +15,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon"
 	"github.com/skycoin/skycoin/src/testutil"
 	"github.com/skycoin/skycoin/src/transaction"
 	"github.com/skycoin/skycoin/src/util/fee"
@@ -42,6 +43,7 @@ type rawCreateTxnRequest struct {
 	ChangeAddress  string            `json:"change_address,omitempty"`
 	To             []rawReceiver     `json:"to"`
 	Password       string            `json:"password"`
+	SortTx         bool              `json:"sort_tx,omitempty"`
 }
 
 func TestCreateTransaction(t *testing.T) {
@@ -723,6 +725,32 @@ func TestCreateTransaction(t *testing.T) {
 			},
 		},
 
+		{
+			name:   "200 - manual type nonzero hours - sort tx",
+			method: http.MethodPost,
+			body: &rawCreateTxnRequest{
+				HoursSelection: rawHoursSelection{
+					Type: transaction.HoursSelectionTypeManual,
+				},
+				To: []rawReceiver{
+					{
+						Address: destinationAddress.String(),
+						Coins:   "100",
+						Hours:   "10",
+					},
+				},
+				ChangeAddress: changeAddress.String(),
+				Addresses:     []string{changeAddress.String()},
+				SortTx:        true,
+			},
+			status:                         http.StatusOK,
+			gatewayCreateTransactionResult: txn,
+			gatewayCreateTransactionInputs: inputs,
+			httpResponse: HTTPResponse{
+				Data: createTxnResponse,
+			},
+		},
+
 		{
 			name:                           "200 - manual type nonzero hours - csrf disabled",
 			method:                         http.MethodPost,
@@ -874,6 +902,7 @@ func TestWalletCreateTransaction(t *testing.T) {
 		rawCreateTxnRequest
 		WalletID string `json:"wallet_id"`
 		Password string `json:"password"`
+		PIN      string `json:"pin"`
 		Unsigned bool   `json:"unsigned"`
 	}
 
@@ -953,6 +982,8 @@ func TestWalletCreateTransaction(t *testing.T) {
 		createTransactionResponse      *CreateTransactionResponse
 		csrfDisabled                   bool
 		contentType                    string
+		sessionPassword                []byte
+		sessionPasswordErr             error
 	}
 
 	baseCases := []testCase{
@@ -1756,6 +1787,52 @@ func TestWalletCreateTransaction(t *testing.T) {
 		},
 		status: http.StatusBadRequest,
 		err:    "400 Bad Request - password must not be used for unsigned transactions",
+	}, testCase{
+		name:   "400 - pin provided for unsigned request",
+		method: http.MethodPost,
+		body: rawWalletCreateTxnRequest{
+			rawCreateTxnRequest: validBody.rawCreateTxnRequest,
+			WalletID:            "foo.wlt",
+			PIN:                 "1234",
+			Unsigned:            true,
+		},
+		status: http.StatusBadRequest,
+		err:    "400 Bad Request - pin must not be used for unsigned transactions",
+	}, testCase{
+		name:   "400 - password and pin provided",
+		method: http.MethodPost,
+		body: rawWalletCreateTxnRequest{
+			rawCreateTxnRequest: validBody.rawCreateTxnRequest,
+			WalletID:            "foo.wlt",
+			Password:            "foo",
+			PIN:                 "1234",
+		},
+		status: http.StatusBadRequest,
+		err:    "400 Bad Request - password and pin must not be used together",
+	}, testCase{
+		name:   "400 - wrong pin",
+		method: http.MethodPost,
+		body: rawWalletCreateTxnRequest{
+			rawCreateTxnRequest: validBody.rawCreateTxnRequest,
+			WalletID:            "foo.wlt",
+			PIN:                 "1234",
+		},
+		status:             http.StatusBadRequest,
+		sessionPasswordErr: wallet.ErrWrongPIN,
+		err:                "400 Bad Request - wrong pin",
+	}, testCase{
+		name:   "200 - pin",
+		method: http.MethodPost,
+		body: rawWalletCreateTxnRequest{
+			rawCreateTxnRequest: validBody.rawCreateTxnRequest,
+			WalletID:            "foo.wlt",
+			PIN:                 "1234",
+		},
+		status:                         http.StatusOK,
+		sessionPassword:                []byte("pwd"),
+		gatewayCreateTransactionResult: txn,
+		gatewayCreateTransactionInputs: inputs,
+		createTransactionResponse:      createTxnResponse,
 	})
 
 	for _, tc := range cases {
@@ -1773,7 +1850,13 @@ func TestWalletCreateTransaction(t *testing.T) {
 					x := gateway.On("WalletCreateTransaction", body.WalletID, body.TransactionParams(), body.VisorParams())
 					x.Return(tc.gatewayCreateTransactionResult, tc.gatewayCreateTransactionInputs, tc.gatewayCreateTransactionErr)
 				} else {
-					x := gateway.On("WalletCreateTransactionSigned", body.WalletID, []byte(body.Password), body.TransactionParams(), body.VisorParams())
+					password := []byte(body.Password)
+					if body.PIN != "" {
+						gateway.On("SessionPassword", body.WalletID, []byte(body.PIN)).Return(tc.sessionPassword, tc.sessionPasswordErr)
+						password = tc.sessionPassword
+					}
+
+					x := gateway.On("WalletCreateTransactionSigned", body.WalletID, password, body.TransactionParams(), body.VisorParams())
 					x.Return(tc.gatewayCreateTransactionResult, tc.gatewayCreateTransactionInputs, tc.gatewayCreateTransactionErr)
 
 				}
@@ -1902,6 +1985,8 @@ func TestWalletSignTransaction(t *testing.T) {
 		gatewaySignTransactionResult *coin.Transaction
 		gatewaySignTransactionInputs []visor.TransactionInput
 		gatewaySignTransactionErr    error
+		sessionPassword              []byte
+		sessionPasswordErr           error
 		csrfDisabled                 bool
 		contentType                  string
 		httpResponse                 HTTPResponse
@@ -2115,6 +2200,49 @@ func TestWalletSignTransaction(t *testing.T) {
 				Data: *signedTxnResp,
 			},
 		},
+
+		{
+			name:   "400 - password and pin",
+			method: http.MethodPost,
+			body: &WalletSignTransactionRequest{
+				WalletID:           "foo.wlt",
+				Password:           "pwd",
+				PIN:                "1234",
+				EncodedTransaction: validBody.EncodedTransaction,
+			},
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "password and pin must not be used together"),
+		},
+
+		{
+			name:   "400 - no session",
+			method: http.MethodPost,
+			body: &WalletSignTransactionRequest{
+				WalletID:           "foo.wlt",
+				PIN:                "1234",
+				EncodedTransaction: validBody.EncodedTransaction,
+			},
+			status:             http.StatusBadRequest,
+			sessionPasswordErr: wallet.ErrSessionNotExist,
+			httpResponse:       NewHTTPErrorResponse(http.StatusBadRequest, "wallet session doesn't exist or has expired, the password is required"),
+		},
+
+		{
+			name:   "200 - pin",
+			method: http.MethodPost,
+			body: &WalletSignTransactionRequest{
+				WalletID:           "foo.wlt",
+				PIN:                "1234",
+				EncodedTransaction: validBody.EncodedTransaction,
+			},
+			status:                       http.StatusOK,
+			sessionPassword:              []byte("pwd"),
+			gatewaySignTransactionResult: &signedTxn,
+			gatewaySignTransactionInputs: inputs,
+			httpResponse: HTTPResponse{
+				Data: *signedTxnResp,
+			},
+		},
 	}
 
 	for _, tc := range tt {
@@ -2132,7 +2260,13 @@ func TestWalletSignTransaction(t *testing.T) {
 			}
 
 			if tc.body != nil {
-				gateway.On("WalletSignTransaction", tc.body.WalletID, []byte(tc.body.Password), txn, tc.body.SignIndexes).Return(tc.gatewaySignTransactionResult, tc.gatewaySignTransactionInputs, tc.gatewaySignTransactionErr)
+				password := []byte(tc.body.Password)
+				if tc.body.PIN != "" {
+					gateway.On("SessionPassword", tc.body.WalletID, []byte(tc.body.PIN)).Return(tc.sessionPassword, tc.sessionPasswordErr)
+					password = tc.sessionPassword
+				}
+
+				gateway.On("WalletSignTransaction", tc.body.WalletID, password, txn, tc.body.SignIndexes).Return(tc.gatewaySignTransactionResult, tc.gatewaySignTransactionInputs, tc.gatewaySignTransactionErr)
 			}
 
 			endpoint := "/api/v2/wallet/transaction/sign"
@@ -2191,3 +2325,481 @@ func TestWalletSignTransaction(t *testing.T) {
 		})
 	}
 }
+
+func TestWalletConsolidate(t *testing.T) {
+	addr := testutil.MakeAddress()
+
+	txn := coin.Transaction{
+		Length:    100,
+		Type:      0,
+		InnerHash: testutil.RandSHA256(t),
+		Sigs:      []cipher.Sig{testutil.RandSig(t), testutil.RandSig(t)},
+		In:        []cipher.SHA256{testutil.RandSHA256(t), testutil.RandSHA256(t)},
+		Out: []coin.TransactionOutput{
+			{
+				Address: addr,
+				Coins:   2e6,
+				Hours:   150,
+			},
+		},
+	}
+
+	inputs := make([]visor.TransactionInput, len(txn.In))
+	for i := range inputs {
+		inputs[i] = visor.TransactionInput{
+			UxOut: coin.UxOut{
+				Head: coin.UxHead{
+					Time:  uint64(time.Now().UTC().Unix()),
+					BkSeq: 9999,
+				},
+				Body: coin.UxBody{
+					SrcTransaction: testutil.RandSHA256(t),
+					Address:        addr,
+					Coins:          1e6,
+					Hours:          100,
+				},
+			},
+			CalculatedHours: 200,
+		}
+	}
+
+	txnResp, err := NewCreateTransactionResponse(&txn, inputs)
+	require.NoError(t, err)
+
+	p := transaction.Params{
+		To: []coin.TransactionOutput{
+			{
+				Address: addr,
+				Coins:   2e6,
+			},
+		},
+		ChangeAddress: &addr,
+	}
+	wp := visor.CreateTransactionParams{
+		UxOuts: txn.In,
+	}
+
+	tt := []struct {
+		name         string
+		method       string
+		query        string
+		body         string
+		status       int
+		maxInputs    int
+		paramsErr    error
+		unsigned     bool
+		password     string
+		createErr    error
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "400 - missing address",
+			method:       http.MethodPost,
+			body:         "{}",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "address is required"),
+		},
+		{
+			name:         "400 - invalid address",
+			method:       http.MethodPost,
+			query:        "?address=foo",
+			body:         "{}",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid address: Invalid address length"),
+		},
+		{
+			name:         "400 - invalid max_inputs",
+			method:       http.MethodPost,
+			query:        "?address=" + addr.String() + "&max_inputs=foo",
+			body:         "{}",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid 'max_inputs' value: strconv.ParseUint: parsing \"foo\": invalid syntax"),
+		},
+		{
+			name:         "400 - max_inputs too small",
+			method:       http.MethodPost,
+			query:        "?address=" + addr.String() + "&max_inputs=1",
+			body:         "{}",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "max_inputs must be at least 2"),
+		},
+		{
+			name:         "400 - invalid json",
+			method:       http.MethodPost,
+			query:        "?address=" + addr.String(),
+			body:         "{ca",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid character 'c' looking for beginning of object key string"),
+		},
+		{
+			name:         "400 - password for unsigned",
+			method:       http.MethodPost,
+			query:        "?address=" + addr.String(),
+			body:         `{"unsigned":true,"password":"foo"}`,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "password must not be used for unsigned transactions"),
+		},
+		{
+			name:         "404 - wallet not found",
+			method:       http.MethodPost,
+			query:        "?address=" + addr.String(),
+			body:         "{}",
+			status:       http.StatusNotFound,
+			paramsErr:    wallet.ErrWalletNotExist,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:         "403 - wallet API disabled",
+			method:       http.MethodPost,
+			query:        "?address=" + addr.String(),
+			body:         "{}",
+			status:       http.StatusForbidden,
+			paramsErr:    wallet.ErrWalletAPIDisabled,
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:         "400 - unknown address",
+			method:       http.MethodPost,
+			query:        "?address=" + addr.String(),
+			body:         "{}",
+			status:       http.StatusBadRequest,
+			paramsErr:    wallet.ErrUnknownAddress,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "address not found in wallet"),
+		},
+		{
+			name:         "400 - nothing to consolidate",
+			method:       http.MethodPost,
+			query:        "?address=" + addr.String() + "&max_inputs=5",
+			body:         "{}",
+			status:       http.StatusBadRequest,
+			maxInputs:    5,
+			paramsErr:    visor.ErrNothingToConsolidate,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "address has fewer than 2 unspent outputs to consolidate"),
+		},
+		{
+			name:         "500 - CreateConsolidationParams failed",
+			method:       http.MethodPost,
+			query:        "?address=" + addr.String(),
+			body:         "{}",
+			status:       http.StatusInternalServerError,
+			paramsErr:    errors.New("paramsErr"),
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "paramsErr"),
+		},
+		{
+			name:         "400 - insufficient coin hours",
+			method:       http.MethodPost,
+			query:        "?address=" + addr.String(),
+			body:         "{}",
+			status:       http.StatusBadRequest,
+			createErr:    fee.ErrTxnInsufficientCoinHours,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, fee.ErrTxnInsufficientCoinHours.Error()),
+		},
+		{
+			name:         "400 - invalid password",
+			method:       http.MethodPost,
+			query:        "?address=" + addr.String(),
+			body:         `{"password":"bar"}`,
+			status:       http.StatusBadRequest,
+			password:     "bar",
+			createErr:    wallet.ErrInvalidPassword,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid password"),
+		},
+		{
+			name:      "200 - signed",
+			method:    http.MethodPost,
+			query:     "?address=" + addr.String() + "&max_inputs=2",
+			body:      `{"password":"foo"}`,
+			status:    http.StatusOK,
+			password:  "foo",
+			maxInputs: 2,
+			httpResponse: HTTPResponse{
+				Data: *txnResp,
+			},
+		},
+		{
+			name:     "200 - unsigned",
+			method:   http.MethodPost,
+			query:    "?address=" + addr.String(),
+			body:     `{"unsigned":true}`,
+			status:   http.StatusOK,
+			unsigned: true,
+			httpResponse: HTTPResponse{
+				Data: *txnResp,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("CreateConsolidationParams", "foo.wlt", addr, tc.maxInputs).Return(p, wp, tc.paramsErr)
+			if tc.unsigned {
+				gateway.On("WalletCreateTransaction", "foo.wlt", p, wp).Return(&txn, inputs, tc.createErr)
+			} else {
+				gateway.On("WalletCreateTransactionSigned", "foo.wlt", []byte(tc.password), p, wp).Return(&txn, inputs, tc.createErr)
+			}
+
+			req, err := http.NewRequest(tc.method, "/api/v2/wallet/foo.wlt/consolidate"+tc.query, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var cRsp CreateTransactionResponse
+				err := json.Unmarshal(rsp.Data, &cRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(CreateTransactionResponse), cRsp)
+			}
+		})
+	}
+}
+
+func TestWalletCoinJoin(t *testing.T) {
+	addr := testutil.MakeAddress()
+	h1 := testutil.RandSHA256(t)
+	h2 := testutil.RandSHA256(t)
+	inputs := []cipher.SHA256{h1, h2}
+	_, s1 := cipher.GenerateKeyPair()
+	_, s2 := cipher.GenerateKeyPair()
+	keys := []cipher.SecKey{s1, s2}
+	output := coin.TransactionOutput{
+		Address: addr,
+		Coins:   2e6,
+		Hours:   50,
+	}
+	coordinator := "1.2.3.4:6000"
+
+	body := func(password, pin string) string {
+		b, err := json.Marshal(WalletCoinJoinRequest{
+			Coordinator: coordinator,
+			UxOuts:      []string{h1.Hex(), h2.Hex()},
+			Address:     addr.String(),
+			Coins:       "2",
+			Hours:       "50",
+			Password:    password,
+			PIN:         pin,
+		})
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	tt := []struct {
+		name         string
+		method       string
+		body         string
+		status       int
+		password     string
+		pin          string
+		keysErr      error
+		requestErr   error
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "400 - invalid json",
+			method:       http.MethodPost,
+			body:         "{ca",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid character 'c' looking for beginning of object key string"),
+		},
+		{
+			name:         "400 - missing coordinator",
+			method:       http.MethodPost,
+			body:         "{}",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "coordinator is required"),
+		},
+		{
+			name:         "400 - missing unspents",
+			method:       http.MethodPost,
+			body:         `{"coordinator":"1.2.3.4:6000"}`,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "unspents is required"),
+		},
+		{
+			name:         "400 - invalid unspent",
+			method:       http.MethodPost,
+			body:         `{"coordinator":"1.2.3.4:6000","unspents":["foo"]}`,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, `invalid unspent "foo": encoding/hex: invalid byte: U+006F 'o'`),
+		},
+		{
+			name:         "400 - invalid address",
+			method:       http.MethodPost,
+			body:         fmt.Sprintf(`{"coordinator":"1.2.3.4:6000","unspents":["%s"],"address":"foo"}`, h1.Hex()),
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid address: Invalid address length"),
+		},
+		{
+			name:         "400 - invalid coins",
+			method:       http.MethodPost,
+			body:         fmt.Sprintf(`{"coordinator":"1.2.3.4:6000","unspents":["%s"],"address":"%s","coins":"foo"}`, h1.Hex(), addr),
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid coins value: can't convert foo to decimal"),
+		},
+		{
+			name:         "400 - invalid hours",
+			method:       http.MethodPost,
+			body:         fmt.Sprintf(`{"coordinator":"1.2.3.4:6000","unspents":["%s"],"address":"%s","coins":"1","hours":"foo"}`, h1.Hex(), addr),
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid hours value: strconv.ParseUint: parsing \"foo\": invalid syntax"),
+		},
+		{
+			name:         "400 - password and pin",
+			method:       http.MethodPost,
+			body:         body("foo", "1234"),
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "password and pin must not be used together"),
+		},
+		{
+			name:         "404 - wallet not found",
+			method:       http.MethodPost,
+			body:         body("foo", ""),
+			status:       http.StatusNotFound,
+			password:     "foo",
+			keysErr:      wallet.ErrWalletNotExist,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:         "403 - wallet API disabled",
+			method:       http.MethodPost,
+			body:         body("foo", ""),
+			status:       http.StatusForbidden,
+			password:     "foo",
+			keysErr:      wallet.ErrWalletAPIDisabled,
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:         "400 - invalid password",
+			method:       http.MethodPost,
+			body:         body("bar", ""),
+			status:       http.StatusBadRequest,
+			password:     "bar",
+			keysErr:      wallet.ErrInvalidPassword,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid password"),
+		},
+		{
+			name:         "400 - unspent not in wallet",
+			method:       http.MethodPost,
+			body:         body("foo", ""),
+			status:       http.StatusBadRequest,
+			password:     "foo",
+			keysErr:      visor.ErrUxOutNotInWallet,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "UxOut is not owned by the wallet"),
+		},
+		{
+			name:         "400 - unspent does not exist",
+			method:       http.MethodPost,
+			body:         body("foo", ""),
+			status:       http.StatusBadRequest,
+			password:     "foo",
+			keysErr:      blockdb.NewErrUnspentNotExist(h1.Hex()),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, blockdb.NewErrUnspentNotExist(h1.Hex()).Error()),
+		},
+		{
+			name:         "400 - coordinator not connected",
+			method:       http.MethodPost,
+			body:         body("foo", ""),
+			status:       http.StatusBadRequest,
+			password:     "foo",
+			requestErr:   daemon.ErrConnectionNotExist,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, daemon.ErrConnectionNotExist.Error()),
+		},
+		{
+			name:         "400 - coins mismatch",
+			method:       http.MethodPost,
+			body:         body("foo", ""),
+			status:       http.StatusBadRequest,
+			password:     "foo",
+			requestErr:   daemon.ErrCoinJoinCoinsMismatch,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, daemon.ErrCoinJoinCoinsMismatch.Error()),
+		},
+		{
+			name:         "500 - RequestCoinJoin failed",
+			method:       http.MethodPost,
+			body:         body("foo", ""),
+			status:       http.StatusInternalServerError,
+			password:     "foo",
+			requestErr:   errors.New("RequestCoinJoin failed"),
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "RequestCoinJoin failed"),
+		},
+		{
+			name:         "200",
+			method:       http.MethodPost,
+			body:         body("foo", ""),
+			status:       http.StatusOK,
+			password:     "foo",
+			httpResponse: HTTPResponse{},
+		},
+		{
+			name:         "200 - session pin",
+			method:       http.MethodPost,
+			body:         body("", "1234"),
+			status:       http.StatusOK,
+			password:     "foo",
+			pin:          "1234",
+			httpResponse: HTTPResponse{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("SessionPassword", "foo.wlt", []byte(tc.pin)).Return([]byte(tc.password), nil)
+			gateway.On("WalletCoinJoinKeys", "foo.wlt", []byte(tc.password), inputs).Return(keys, tc.keysErr)
+			gateway.On("RequestCoinJoin", coordinator, inputs, keys, output).Return(tc.requestErr)
+
+			req, err := http.NewRequest(tc.method, "/api/v2/wallet/foo.wlt/coinjoin", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+			require.Nil(t, rsp.Data)
+
+			if tc.status == http.StatusOK {
+				gateway.AssertCalled(t, "RequestCoinJoin", coordinator, inputs, keys, output)
+			}
+		})
+	}
+}
@@ -122,7 +122,12 @@ func transactionHandler(gateway Gatewayer) http.HandlerFunc {
 		if verbose {
 			txn, inputs, err := gateway.GetTransactionWithInputs(h)
 			if err != nil {
-				wh.Error500(w, err.Error())
+				switch err.(type) {
+				case visor.ErrBlockPruned:
+					wh.Error410(w, err.Error())
+				default:
+					wh.Error500(w, err.Error())
+				}
 				return
 			}
 			if txn == nil {
@@ -142,7 +147,12 @@ func transactionHandler(gateway Gatewayer) http.HandlerFunc {
 
 		txn, err := gateway.GetTransaction(h)
 		if err != nil {
-			wh.Error500(w, err.Error())
+			switch err.(type) {
+			case visor.ErrBlockPruned:
+				wh.Error410(w, err.Error())
+			default:
+				wh.Error500(w, err.Error())
+			}
 			return
 		}
 		if txn == nil {
@@ -294,7 +304,12 @@ func transactionsHandler(gateway Gatewayer) http.HandlerFunc {
 		if verbose {
 			txns, inputs, _, err := gateway.GetTransactionsWithInputs(flts, visor.AscOrder, nil)
 			if err != nil {
-				wh.Error500(w, err.Error())
+				switch err.(type) {
+				case visor.ErrBlockPruned:
+					wh.Error410(w, err.Error())
+				default:
+					wh.Error500(w, err.Error())
+				}
 				return
 			}
 
@@ -310,7 +325,12 @@ func transactionsHandler(gateway Gatewayer) http.HandlerFunc {
 		} else {
 			txns, _, err := gateway.GetTransactions(flts, visor.AscOrder, nil)
 			if err != nil {
-				wh.Error500(w, err.Error())
+				switch err.(type) {
+				case visor.ErrBlockPruned:
+					wh.Error410(w, err.Error())
+				default:
+					wh.Error500(w, err.Error())
+				}
 				return
 			}
 
@@ -414,7 +434,12 @@ func transactionsHandlerV2(gateway Gatewayer) http.HandlerFunc {
 		if verbose {
 			txns, inputs, pages, err := gateway.GetTransactionsWithInputs(flts, order, pageIndex)
 			if err != nil {
-				writeError500Response(w, err.Error())
+				switch err.(type) {
+				case visor.ErrBlockPruned:
+					writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusGone, err.Error()))
+				default:
+					writeError500Response(w, err.Error())
+				}
 				return
 			}
 
@@ -439,7 +464,12 @@ func transactionsHandlerV2(gateway Gatewayer) http.HandlerFunc {
 		} else {
 			txns, pages, err := gateway.GetTransactions(flts, order, pageIndex)
 			if err != nil {
-				writeError500Response(w, err.Error())
+				switch err.(type) {
+				case visor.ErrBlockPruned:
+					writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusGone, err.Error()))
+				default:
+					writeError500Response(w, err.Error())
+				}
 				return
 			}
 
@@ -512,7 +542,11 @@ func injectTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 					visor.ErrTxnViolatesSoftConstraint:
 					wh.Error400(w, err.Error())
 				default:
-					wh.Error500(w, err.Error())
+					if err == visor.ErrUnconfirmedPoolFull {
+						wh.Error503(w, err.Error())
+					} else {
+						wh.Error500(w, err.Error())
+					}
 				}
 				return
 			}
@@ -524,7 +558,7 @@ func injectTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 					visor.ErrTxnViolatesSoftConstraint:
 					wh.Error400(w, err.Error())
 				default:
-					if daemon.IsBroadcastFailure(err) {
+					if daemon.IsBroadcastFailure(err) || err == visor.ErrUnconfirmedPoolFull {
 						wh.Error503(w, err.Error())
 					} else {
 						wh.Error500(w, err.Error())
@@ -610,7 +644,12 @@ func rawTxnHandler(gateway Gatewayer) http.HandlerFunc {
 
 		txn, err := gateway.GetTransaction(h)
 		if err != nil {
-			wh.Error400(w, err.Error())
+			switch err.(type) {
+			case visor.ErrBlockPruned:
+				wh.Error410(w, err.Error())
+			default:
+				wh.Error400(w, err.Error())
+			}
 			return
 		}
 
@@ -630,6 +669,7 @@ func rawTxnHandler(gateway Gatewayer) http.HandlerFunc {
 }
 
 // VerifyTransactionRequest represents the data struct of the request for /api/v2/transaction/verify
+// and /api/v2/transaction/validate
 type VerifyTransactionRequest struct {
 	Unsigned           bool   `json:"unsigned"`
 	EncodedTransaction string `json:"encoded_transaction"`
@@ -726,6 +766,87 @@ func verifyTxnHandler(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
+// ValidationError is a problem of a transaction, returned by /api/v2/transaction/validate
+type ValidationError struct {
+	// Constraint is the violated constraint, "hard", "soft" or "user"
+	Constraint string `json:"constraint"`
+	// Input is the index of the input the error is about, if any
+	Input *int `json:"input,omitempty"`
+	// Output is the index of the output the error is about, if any
+	Output  *int   `json:"output,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidateTransactionResponse the response data struct for /api/v2/transaction/validate
+type ValidateTransactionResponse struct {
+	Valid  bool              `json:"valid"`
+	Errors []ValidationError `json:"errors"`
+}
+
+// Decode a transaction and return all of the problems found by validating it,
+// instead of only the first one like /api/v2/transaction/verify
+// Method: POST
+// URI: /api/v2/transaction/validate
+func validateTxnHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError405Response(w)
+			return
+		}
+
+		var req VerifyTransactionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError400Response(w, err.Error())
+			return
+		}
+
+		if req.EncodedTransaction == "" {
+			writeError400Response(w, "encoded_transaction is required")
+			return
+		}
+
+		txn, err := decodeTxn(req.EncodedTransaction)
+		if err != nil {
+			writeError400Response(w, fmt.Sprintf("decode transaction failed: %v", err))
+			return
+		}
+
+		signed := visor.TxnSigned
+		if req.Unsigned {
+			signed = visor.TxnUnsigned
+		}
+
+		verrs, err := gateway.VerifyTransaction(*txn, signed)
+		if err != nil {
+			writeError500Response(w, err.Error())
+			return
+		}
+
+		errs := make([]ValidationError, len(verrs))
+		for i, e := range verrs {
+			errs[i] = ValidationError{
+				Constraint: string(e.Constraint),
+				Message:    e.Err.Error(),
+			}
+			if e.Input >= 0 {
+				input := e.Input
+				errs[i].Input = &input
+			}
+			if e.Output >= 0 {
+				output := e.Output
+				errs[i].Output = &output
+			}
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: ValidateTransactionResponse{
+				Valid:  len(errs) == 0,
+				Errors: errs,
+			},
+		})
+	}
+}
+
 func decodeTxn(encodedTxn string) (*coin.Transaction, error) {
 	var txn coin.Transaction
 	b, err := hex.DecodeString(encodedTxn)
@@ -25,6 +25,7 @@ import (
 	"github.com/skycoin/skycoin/src/daemon/gnet"
 	"github.com/skycoin/skycoin/src/readable"
 	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/util/fee"
 	"github.com/skycoin/skycoin/src/visor"
 )
 
@@ -363,6 +364,18 @@ func TestGetTransactionByID(t *testing.T) {
 			getTransactionError: errors.New("getTransactionError"),
 		},
 
+		{
+			name:   "410 - transaction pruned",
+			method: http.MethodGet,
+			status: http.StatusGone,
+			err:    "410 Gone - transactions of block seq=10 have been pruned",
+			httpBody: &httpBody{
+				txid: validHash,
+			},
+			txid:                testutil.SHA256FromHex(t, validHash),
+			getTransactionError: visor.ErrBlockPruned{Seq: 10},
+		},
+
 		{
 			name:   "500 - getTransactionResultVerboseError",
 			method: http.MethodGet,
@@ -727,6 +740,24 @@ func TestInjectTransaction(t *testing.T) {
 			injectTransactionArg:   validTransaction,
 			injectTransactionError: gnet.ErrPoolEmpty,
 		},
+		{
+			name:                   "503 - visor.ErrUnconfirmedPoolFull",
+			method:                 http.MethodPost,
+			status:                 http.StatusServiceUnavailable,
+			err:                    "503 Service Unavailable - The unconfirmed transaction pool is full",
+			httpBody:               string(validTxnBodyJSON),
+			injectTransactionArg:   validTransaction,
+			injectTransactionError: visor.ErrUnconfirmedPoolFull,
+		},
+		{
+			name:                   "503 - no broadcast visor.ErrUnconfirmedPoolFull",
+			method:                 http.MethodPost,
+			status:                 http.StatusServiceUnavailable,
+			err:                    "503 Service Unavailable - The unconfirmed transaction pool is full",
+			httpBody:               string(validTxnBodyNoBroadcastJSON),
+			injectTransactionArg:   validTransaction,
+			injectTransactionError: visor.ErrUnconfirmedPoolFull,
+		},
 		{
 			name:                   "500 - other injectBroadcastTransactionError",
 			method:                 http.MethodPost,
@@ -1912,3 +1943,157 @@ func TestVerifyTransaction(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTransaction(t *testing.T) {
+	txnAndInputs := prepareTxnAndInputs(t)
+	encodedTxn := txnAndInputs.txn.MustSerializeHex()
+
+	intPtr := func(i int) *int {
+		return &i
+	}
+
+	cases := []struct {
+		name         string
+		method       string
+		body         string
+		signed       visor.TxnSignedFlag
+		verifyErrs   []visor.ValidationError
+		verifyErr    error
+		status       int
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "400 - EOF",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "EOF"),
+		},
+		{
+			name:         "400 - encoded_transaction is required",
+			method:       http.MethodPost,
+			body:         `{}`,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "encoded_transaction is required"),
+		},
+		{
+			name:         "400 - invalid transaction",
+			method:       http.MethodPost,
+			body:         `{"encoded_transaction":"abcd"}`,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "decode transaction failed: Invalid transaction: Not enough buffer data to deserialize"),
+		},
+		{
+			name:         "500 - VerifyTransaction failed",
+			method:       http.MethodPost,
+			body:         `{"encoded_transaction":"` + encodedTxn + `"}`,
+			signed:       visor.TxnSigned,
+			verifyErr:    errors.New("verifyErr"),
+			status:       http.StatusInternalServerError,
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "verifyErr"),
+		},
+		{
+			name:       "200 - valid",
+			method:     http.MethodPost,
+			body:       `{"encoded_transaction":"` + encodedTxn + `"}`,
+			signed:     visor.TxnSigned,
+			verifyErrs: []visor.ValidationError{},
+			status:     http.StatusOK,
+			httpResponse: HTTPResponse{
+				Data: ValidateTransactionResponse{
+					Valid:  true,
+					Errors: []ValidationError{},
+				},
+			},
+		},
+		{
+			name:   "200 - invalid unsigned",
+			method: http.MethodPost,
+			body:   `{"unsigned":true,"encoded_transaction":"` + encodedTxn + `"}`,
+			signed: visor.TxnUnsigned,
+			verifyErrs: []visor.ValidationError{
+				{
+					Constraint: visor.ConstraintHard,
+					Input:      0,
+					Output:     -1,
+					Err:        visor.ErrTxnInvalidInputSignature,
+				},
+				{
+					Constraint: visor.ConstraintSoft,
+					Input:      -1,
+					Output:     -1,
+					Err:        fee.ErrTxnInsufficientFee,
+				},
+				{
+					Constraint: visor.ConstraintUser,
+					Input:      -1,
+					Output:     1,
+					Err:        visor.ErrTxnDustOutput,
+				},
+			},
+			status: http.StatusOK,
+			httpResponse: HTTPResponse{
+				Data: ValidateTransactionResponse{
+					Valid: false,
+					Errors: []ValidationError{
+						{
+							Constraint: "hard",
+							Input:      intPtr(0),
+							Message:    "Signature not valid for output being spent",
+						},
+						{
+							Constraint: "soft",
+							Message:    fee.ErrTxnInsufficientFee.Error(),
+						},
+						{
+							Constraint: "user",
+							Output:     intPtr(1),
+							Message:    "Transaction output coins are below the dust threshold",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("VerifyTransaction", txnAndInputs.txn, tc.signed).Return(tc.verifyErrs, tc.verifyErr)
+
+			req, err := http.NewRequest(tc.method, "/api/v2/transaction/validate", strings.NewReader(tc.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var validateRsp ValidateTransactionResponse
+				err := json.Unmarshal(rsp.Data, &validateRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(ValidateTransactionResponse), validateRsp)
+			}
+		})
+	}
+}
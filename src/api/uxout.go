@@ -6,6 +6,7 @@ import (
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/readable"
 	wh "github.com/skycoin/skycoin/src/util/http"
+	"github.com/skycoin/skycoin/src/visor"
 )
 
 // URI: /api/v1/uxout
@@ -34,7 +35,12 @@ func uxOutHandler(gateway Gatewayer) http.HandlerFunc {
 
 		uxout, headTime, err := gateway.GetUxOutByID(id)
 		if err != nil {
-			wh.Error400(w, err.Error())
+			switch err.(type) {
+			case visor.ErrBlockPruned:
+				wh.Error410(w, err.Error())
+			default:
+				wh.Error400(w, err.Error())
+			}
 			return
 		}
 
@@ -79,7 +85,12 @@ func addrUxOutsHandler(gateway Gatewayer) http.HandlerFunc {
 
 		uxs, headTime, err := gateway.GetSpentOutputsForAddresses([]cipher.Address{cipherAddr})
 		if err != nil {
-			wh.Error400(w, err.Error())
+			switch err.(type) {
+			case visor.ErrBlockPruned:
+				wh.Error410(w, err.Error())
+			default:
+				wh.Error400(w, err.Error())
+			}
 			return
 		}
 
@@ -17,6 +17,7 @@ import (
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/readable"
 	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
 	"github.com/skycoin/skycoin/src/visor/historydb"
 )
 
@@ -103,6 +104,18 @@ func TestGetUxOutByID(t *testing.T) {
 			getGetUxOutByIDArg:   testutil.SHA256FromHex(t, validHash),
 			getGetUxOutByIDError: errors.New("getGetUxOutByIDError"),
 		},
+		{
+			name:   "410 - uxout pruned",
+			method: http.MethodGet,
+			status: http.StatusGone,
+			err:    "410 Gone - transactions of block seq=10 have been pruned",
+			httpBody: &httpBody{
+				uxid: validHash,
+			},
+			uxid:                 validHash,
+			getGetUxOutByIDArg:   testutil.SHA256FromHex(t, validHash),
+			getGetUxOutByIDError: visor.ErrBlockPruned{Seq: 10},
+		},
 		{
 			name:   "404 - uxout == nil",
 			method: http.MethodGet,
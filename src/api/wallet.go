@@ -9,11 +9,15 @@ import (
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/skycoin/skycoin/src/cipher/bip39"
 	"github.com/skycoin/skycoin/src/cipher/bip44"
 	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/util/droplet"
 	wh "github.com/skycoin/skycoin/src/util/http"
+	"github.com/skycoin/skycoin/src/visor"
 	"github.com/skycoin/skycoin/src/wallet"
 )
 
@@ -965,6 +969,59 @@ func walletDecryptHandler(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
+// Changes the password of an encrypted wallet
+// URI: /api/v1/wallet/password
+// Method: POST
+// Args:
+//     id: wallet id
+//     password: current wallet password
+//     new_password: new wallet password
+func walletChangePasswordHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		id := r.FormValue("id")
+		if id == "" {
+			wh.Error400(w, "missing wallet id")
+			return
+		}
+
+		password := r.FormValue("password")
+		newPassword := r.FormValue("new_password")
+		defer func() {
+			password = ""
+			newPassword = ""
+		}()
+
+		wlt, err := gateway.ChangePassword(id, []byte(password), []byte(newPassword))
+		if err != nil {
+			switch err {
+			case wallet.ErrMissingPassword,
+				wallet.ErrWalletNotEncrypted,
+				wallet.ErrInvalidPassword:
+				wh.Error400(w, err.Error())
+			case wallet.ErrWalletAPIDisabled:
+				wh.Error403(w, "")
+			case wallet.ErrWalletNotExist:
+				wh.Error404(w, "")
+			default:
+				wh.Error500(w, err.Error())
+			}
+			return
+		}
+
+		rlt, err := NewWalletResponse(wlt)
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+		wh.SendJSONOr500(logger, w, rlt)
+	}
+}
+
 // WalletRecoverRequest is the request data for POST /api/v2/wallet/recover
 type WalletRecoverRequest struct {
 	ID             string `json:"id"`
@@ -1055,3 +1112,289 @@ func walletRecoverHandler(gateway Gatewayer) http.HandlerFunc {
 		})
 	}
 }
+
+// WalletMetaRequest is the request data for POST /api/v2/wallet/{id}/meta
+type WalletMetaRequest struct {
+	// Label replaces the wallet label, if not empty
+	Label string `json:"label"`
+	// Meta keys with an empty value are removed
+	Meta map[string]string `json:"meta"`
+}
+
+// WalletMetaResponse is returned by GET and POST /api/v2/wallet/{id}/meta
+type WalletMetaResponse struct {
+	Label     string            `json:"label"`
+	Timestamp int64             `json:"timestamp"`
+	Meta      map[string]string `json:"meta"`
+}
+
+func newWalletMetaResponse(w wallet.Wallet) WalletMetaResponse {
+	return WalletMetaResponse{
+		Label:     w.Label(),
+		Timestamp: w.Timestamp(),
+		Meta:      w.UserMeta(),
+	}
+}
+
+// walletHandlerV2Subtree dispatches the /api/v2/wallet/{id}/... endpoints
+func walletHandlerV2Subtree(gateway Gatewayer) http.HandlerFunc {
+	meta := walletMetaHandler(gateway)
+	addressStats := walletAddressStatsHandler(gateway)
+	consolidate := walletConsolidateHandler(gateway)
+	coinJoin := walletCoinJoinHandler(gateway)
+	session := walletSessionHandler(gateway)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v2/wallet/"), "/")
+		if len(parts) != 2 || parts[0] == "" {
+			writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusNotFound, ""))
+			return
+		}
+
+		switch parts[1] {
+		case "meta":
+			meta(w, r, parts[0])
+		case "address_stats":
+			addressStats(w, r, parts[0])
+		case "consolidate":
+			consolidate(w, r, parts[0])
+		case "coinjoin":
+			coinJoin(w, r, parts[0])
+		case "session":
+			session(w, r, parts[0])
+		default:
+			writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusNotFound, ""))
+		}
+	}
+}
+
+// walletMetaHandler returns or updates the label and the user defined metadata of a wallet.
+// Metadata keys must match [a-z_]{1,32} and values must be at most 255 bytes.
+// On update, a key with an empty value is removed and the other keys are not modified.
+// URI: /api/v2/wallet/{id}/meta
+// Method: GET, POST
+// Args (POST, JSON body):
+//     label: the new label, the label is not modified if empty [optional]
+//     meta: the metadata keys to set [optional]
+func walletMetaHandler(gateway Gatewayer) func(w http.ResponseWriter, r *http.Request, wltID string) {
+	return func(w http.ResponseWriter, r *http.Request, wltID string) {
+		var wlt wallet.Wallet
+		var err error
+
+		switch r.Method {
+		case http.MethodGet:
+			wlt, err = gateway.GetWallet(wltID)
+		case http.MethodPost:
+			var req WalletMetaRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError400Response(w, err.Error())
+				return
+			}
+
+			if err := wallet.ValidateUserMeta(req.Meta); err != nil {
+				writeError400Response(w, err.Error())
+				return
+			}
+
+			wlt, err = gateway.UpdateWalletMeta(wltID, req.Label, req.Meta)
+		default:
+			writeError405Response(w)
+			return
+		}
+
+		if err != nil {
+			var resp HTTPResponse
+			switch err.(type) {
+			case wallet.Error:
+				switch err {
+				case wallet.ErrWalletNotExist:
+					resp = NewHTTPErrorResponse(http.StatusNotFound, "")
+				case wallet.ErrWalletAPIDisabled:
+					resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+				default:
+					resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+				}
+			default:
+				resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: newWalletMetaResponse(wlt),
+		})
+	}
+}
+
+// WalletAddressStats are the usage statistics of an address of a wallet
+type WalletAddressStats struct {
+	Address       string `json:"address"`
+	TotalReceived string `json:"total_received"`
+	TotalSent     string `json:"total_sent"`
+	UnspentCount  uint64 `json:"unspent_count"`
+	// LastTxnHeight is the height of the most recent block that created or spent an output of the address,
+	// null if the address has never been used
+	LastTxnHeight *uint64 `json:"last_txn_height"`
+}
+
+// WalletAddressStatsResponse is returned by GET /api/v2/wallet/{id}/address_stats
+type WalletAddressStatsResponse struct {
+	// Approximate is true if the address index has not been built up to the head block yet
+	Approximate bool                 `json:"approximate"`
+	Addresses   []WalletAddressStats `json:"addresses"`
+}
+
+func newWalletAddressStatsResponse(stats []visor.AddressStats, approximate bool) (*WalletAddressStatsResponse, error) {
+	addrs := make([]WalletAddressStats, len(stats))
+	for i, s := range stats {
+		received, err := droplet.ToString(s.Received)
+		if err != nil {
+			return nil, err
+		}
+
+		sent, err := droplet.ToString(s.Sent)
+		if err != nil {
+			return nil, err
+		}
+
+		addrs[i] = WalletAddressStats{
+			Address:       s.Address.String(),
+			TotalReceived: received,
+			TotalSent:     sent,
+			UnspentCount:  s.UnspentCount,
+		}
+
+		if s.Used {
+			lastSeq := s.LastSeq
+			addrs[i].LastTxnHeight = &lastSeq
+		}
+	}
+
+	return &WalletAddressStatsResponse{
+		Approximate: approximate,
+		Addresses:   addrs,
+	}, nil
+}
+
+// walletAddressStatsHandler returns the usage statistics of each address of a wallet, in the wallet's address order:
+// the coins received and sent, the number of unspent outputs and the height of the last transaction.
+// Only confirmed transactions are counted. Coins sent from an address to itself are counted as both sent and received.
+// URI: /api/v2/wallet/{id}/address_stats
+// Method: GET
+func walletAddressStatsHandler(gateway Gatewayer) func(w http.ResponseWriter, r *http.Request, wltID string) {
+	return func(w http.ResponseWriter, r *http.Request, wltID string) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		stats, approximate, err := gateway.GetWalletAddressStats(wltID)
+		if err != nil {
+			var resp HTTPResponse
+			switch err {
+			case wallet.ErrWalletNotExist:
+				resp = NewHTTPErrorResponse(http.StatusNotFound, "")
+			case wallet.ErrWalletAPIDisabled:
+				resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+			default:
+				resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		resp, err := newWalletAddressStatsResponse(stats, approximate)
+		if err != nil {
+			writeError500Response(w, err.Error())
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: resp,
+		})
+	}
+}
+
+// WalletSessionRequest is the request body of POST /api/v2/wallet/{id}/session
+type WalletSessionRequest struct {
+	Password string `json:"password"`
+	PIN      string `json:"pin"`
+}
+
+// WalletSessionResponse is returned by POST /api/v2/wallet/{id}/session
+type WalletSessionResponse struct {
+	// Expires is the unix time at which the session expires
+	Expires int64 `json:"expires"`
+}
+
+// walletSessionHandler starts or ends the PIN session of an encrypted wallet.
+// During a session, a 4 to 8 digit PIN can be sent instead of the password to create and sign transactions.
+// The password is cached in memory only, and is required again when the session expires.
+// The session is ended after 3 consecutive wrong PINs.
+// URI: /api/v2/wallet/{id}/session
+// Method: POST, DELETE
+// Args (POST, JSON body):
+//     password: wallet password
+//     pin: session PIN
+func walletSessionHandler(gateway Gatewayer) func(w http.ResponseWriter, r *http.Request, wltID string) {
+	return func(w http.ResponseWriter, r *http.Request, wltID string) {
+		var expires time.Time
+		var err error
+
+		switch r.Method {
+		case http.MethodPost:
+			var req WalletSessionRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError400Response(w, err.Error())
+				return
+			}
+
+			expires, err = gateway.StartSession(wltID, []byte(req.Password), []byte(req.PIN))
+		case http.MethodDelete:
+			err = gateway.EndSession(wltID)
+		default:
+			writeError405Response(w)
+			return
+		}
+
+		if err != nil {
+			var resp HTTPResponse
+			switch err.(type) {
+			case wallet.Error:
+				switch err {
+				case wallet.ErrWalletNotExist:
+					resp = NewHTTPErrorResponse(http.StatusNotFound, "")
+				case wallet.ErrWalletAPIDisabled:
+					resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+				default:
+					resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+				}
+			default:
+				resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			writeHTTPResponse(w, HTTPResponse{})
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: WalletSessionResponse{
+				Expires: expires.Unix(),
+			},
+		})
+	}
+}
+
+// walletPassword returns the password of a request, or the password of the wallet's session if a PIN is sent instead
+func walletPassword(gateway Gatewayer, wltID, password, pin string) ([]byte, error) {
+	if pin == "" {
+		return []byte(password), nil
+	}
+
+	return gateway.SessionPassword(wltID, []byte(pin))
+}
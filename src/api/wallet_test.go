@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"encoding/json"
 
@@ -2944,6 +2945,183 @@ func TestDecryptWallet(t *testing.T) {
 	}
 }
 
+func TestChangeWalletPassword(t *testing.T) {
+	_, responseEntries := makeEntries([]byte("seed"), 5)
+	type gatewayReturnPair struct {
+		w   wallet.Wallet
+		err error
+	}
+
+	tt := []struct {
+		name          string
+		method        string
+		wltID         string
+		password      string
+		newPassword   string
+		gatewayReturn gatewayReturnPair
+		status        int
+		expectWallet  WalletResponse
+		expectErr     string
+	}{
+		{
+			name:        "200 - OK",
+			method:      http.MethodPost,
+			wltID:       "wallet.wlt",
+			password:    "pwd",
+			newPassword: "newpwd",
+			gatewayReturn: gatewayReturnPair{
+				w: func() wallet.Wallet {
+					wlt, err := deterministic.NewWallet(
+						"wallet.wlt",
+						"",
+						"seed",
+						wallet.OptionPassword([]byte("newpwd")),
+						wallet.OptionGenerateN(5),
+						wallet.OptionEncrypt(true))
+					require.NoError(t, err)
+					wlt.SetTimestamp(0)
+					return wlt
+				}(),
+			},
+			status: http.StatusOK,
+			expectWallet: WalletResponse{
+				Meta: readable.WalletMeta{
+					Coin:       "skycoin",
+					Filename:   "wallet.wlt",
+					Type:       "deterministic",
+					Version:    "0.4",
+					CryptoType: "scrypt-chacha20poly1305",
+					Encrypted:  true,
+				},
+				Entries: responseEntries,
+			},
+		},
+		{
+			name:        "403 Forbidden",
+			method:      http.MethodPost,
+			wltID:       "wallet.wlt",
+			password:    "pwd",
+			newPassword: "newpwd",
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletAPIDisabled,
+			},
+			status:    http.StatusForbidden,
+			expectErr: "403 Forbidden",
+		},
+		{
+			name:      "405 Method Not Allowed",
+			method:    http.MethodGet,
+			status:    http.StatusMethodNotAllowed,
+			expectErr: "405 Method Not Allowed",
+		},
+		{
+			name:      "400 - Missing Wallet ID",
+			method:    http.MethodPost,
+			status:    http.StatusBadRequest,
+			expectErr: "400 Bad Request - missing wallet id",
+		},
+		{
+			name:     "400 - Missing Password",
+			method:   http.MethodPost,
+			wltID:    "wallet.wlt",
+			password: "pwd",
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrMissingPassword,
+			},
+			status:    http.StatusBadRequest,
+			expectErr: "400 Bad Request - missing password",
+		},
+		{
+			name:        "400 - Wallet Is Not Encrypted",
+			method:      http.MethodPost,
+			wltID:       "wallet.wlt",
+			password:    "pwd",
+			newPassword: "newpwd",
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletNotEncrypted,
+			},
+			status:    http.StatusBadRequest,
+			expectErr: "400 Bad Request - wallet is not encrypted",
+		},
+		{
+			name:        "400 - Invalid Password",
+			method:      http.MethodPost,
+			wltID:       "wallet.wlt",
+			password:    "wrong",
+			newPassword: "newpwd",
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrInvalidPassword,
+			},
+			status:    http.StatusBadRequest,
+			expectErr: "400 Bad Request - invalid password",
+		},
+		{
+			name:        "404 - Wallet Does Not Exist",
+			method:      http.MethodPost,
+			wltID:       "wallet.wlt",
+			password:    "pwd",
+			newPassword: "newpwd",
+			gatewayReturn: gatewayReturnPair{
+				err: wallet.ErrWalletNotExist,
+			},
+			status:    http.StatusNotFound,
+			expectErr: "404 Not Found",
+		},
+		{
+			name:        "500 - Save Failed",
+			method:      http.MethodPost,
+			wltID:       "wallet.wlt",
+			password:    "pwd",
+			newPassword: "newpwd",
+			gatewayReturn: gatewayReturnPair{
+				err: errors.New("rename failed"),
+			},
+			status:    http.StatusInternalServerError,
+			expectErr: "500 Internal Server Error - rename failed",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("ChangePassword", tc.wltID, []byte(tc.password), []byte(tc.newPassword)).Return(tc.gatewayReturn.w, tc.gatewayReturn.err)
+
+			endpoint := "/api/v1/wallet/password"
+			v := url.Values{}
+			v.Add("id", tc.wltID)
+			v.Add("password", tc.password)
+			v.Add("new_password", tc.newPassword)
+
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(v.Encode()))
+			require.NoError(t, err)
+			req.Header.Add("Content-Type", ContentTypeForm)
+
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+
+			cfg := defaultMuxConfig()
+			cfg.disableCSRF = false
+
+			handler := newServerMux(cfg, gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "wrong status code: got `%v` want `%v`", status, tc.status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.expectErr, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			var rsp WalletResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectWallet, rsp)
+		})
+	}
+}
+
 // makeEntries derives N wallet address entries from given seed
 // Returns set of entry.Entry and wallet.ReadableEntry, the readable
 // entries' secrets are removed.
@@ -3236,3 +3414,466 @@ func TestWalletRecover(t *testing.T) {
 		})
 	}
 }
+
+func TestWalletMeta(t *testing.T) {
+	w, err := wallet.NewWallet(
+		"foo.wlt",
+		"foolabel",
+		"fooseed",
+		wallet.Options{
+			Type:      wallet.WalletTypeDeterministic,
+			Coin:      wallet.CoinTypeSkycoin,
+			GenerateN: 1,
+		})
+	require.NoError(t, err)
+	require.NoError(t, w.SetUserMeta(map[string]string{"purpose": "savings"}))
+
+	okResponse := WalletMetaResponse{
+		Label:     "foolabel",
+		Timestamp: w.Timestamp(),
+		Meta:      map[string]string{"purpose": "savings"},
+	}
+
+	type gatewayReturnPair struct {
+		w   wallet.Wallet
+		err error
+	}
+
+	cases := []struct {
+		name         string
+		method       string
+		endpoint     string
+		status       int
+		httpBody     string
+		req          *WalletMetaRequest
+		getWallet    *gatewayReturnPair
+		updateMeta   *gatewayReturnPair
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPut,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "404 - unknown endpoint",
+			method:       http.MethodGet,
+			endpoint:     "/api/v2/wallet/foo.wlt/bar",
+			status:       http.StatusNotFound,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:         "404 - missing wallet id",
+			method:       http.MethodGet,
+			endpoint:     "/api/v2/wallet/meta",
+			status:       http.StatusNotFound,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:         "GET 404 - wallet does not exist",
+			method:       http.MethodGet,
+			status:       http.StatusNotFound,
+			getWallet:    &gatewayReturnPair{err: wallet.ErrWalletNotExist},
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:         "GET 403 - wallet api disabled",
+			method:       http.MethodGet,
+			status:       http.StatusForbidden,
+			getWallet:    &gatewayReturnPair{err: wallet.ErrWalletAPIDisabled},
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:         "GET 200",
+			method:       http.MethodGet,
+			status:       http.StatusOK,
+			getWallet:    &gatewayReturnPair{w: w},
+			httpResponse: HTTPResponse{Data: okResponse},
+		},
+		{
+			name:         "POST 400 - invalid json",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			httpBody:     "{",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "unexpected EOF"),
+		},
+		{
+			name:   "POST 400 - invalid meta key",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletMetaRequest{
+				Meta: map[string]string{"Purpose": "savings"},
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, `invalid meta key "Purpose", keys must match [a-z_]{1,32}`),
+		},
+		{
+			name:   "POST 400 - meta value too long",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletMetaRequest{
+				Meta: map[string]string{"purpose": strings.Repeat("x", 256)},
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, `meta value of "purpose" is longer than 255 bytes`),
+		},
+		{
+			name:   "POST 404 - wallet does not exist",
+			method: http.MethodPost,
+			status: http.StatusNotFound,
+			req: &WalletMetaRequest{
+				Meta: map[string]string{"purpose": "savings"},
+			},
+			updateMeta:   &gatewayReturnPair{err: wallet.ErrWalletNotExist},
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:   "POST 500 - other error",
+			method: http.MethodPost,
+			status: http.StatusInternalServerError,
+			req: &WalletMetaRequest{
+				Meta: map[string]string{"purpose": "savings"},
+			},
+			updateMeta:   &gatewayReturnPair{err: errors.New("save failed")},
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "save failed"),
+		},
+		{
+			name:   "POST 200",
+			method: http.MethodPost,
+			status: http.StatusOK,
+			req: &WalletMetaRequest{
+				Label: "foolabel",
+				Meta:  map[string]string{"purpose": "savings"},
+			},
+			updateMeta:   &gatewayReturnPair{w: w},
+			httpResponse: HTTPResponse{Data: okResponse},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.getWallet != nil {
+				gateway.On("GetWallet", "foo.wlt").Return(tc.getWallet.w, tc.getWallet.err)
+			}
+			if tc.updateMeta != nil {
+				gateway.On("UpdateWalletMeta", "foo.wlt", tc.req.Label, tc.req.Meta).Return(tc.updateMeta.w, tc.updateMeta.err)
+			}
+
+			if tc.httpBody == "" && tc.req != nil {
+				tc.httpBody = toJSON(t, tc.req)
+			}
+
+			endpoint := tc.endpoint
+			if endpoint == "" {
+				endpoint = "/api/v2/wallet/foo.wlt/meta"
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var metaRsp WalletMetaResponse
+				err := json.Unmarshal(rsp.Data, &metaRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(WalletMetaResponse), metaRsp)
+			}
+
+			gateway.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWalletAddressStats(t *testing.T) {
+	addrs := []cipher.Address{testutil.MakeAddress(), testutil.MakeAddress()}
+	stats := []visor.AddressStats{
+		{
+			Address:      addrs[0],
+			Received:     10e6,
+			Sent:         7e6,
+			UnspentCount: 2,
+			LastSeq:      42,
+			Used:         true,
+		},
+		{
+			Address: addrs[1],
+		},
+	}
+
+	lastTxnHeight := uint64(42)
+	okResponse := WalletAddressStatsResponse{
+		Approximate: true,
+		Addresses: []WalletAddressStats{
+			{
+				Address:       addrs[0].String(),
+				TotalReceived: "10.000000",
+				TotalSent:     "7.000000",
+				UnspentCount:  2,
+				LastTxnHeight: &lastTxnHeight,
+			},
+			{
+				Address:       addrs[1].String(),
+				TotalReceived: "0.000000",
+				TotalSent:     "0.000000",
+			},
+		},
+	}
+
+	cases := []struct {
+		name         string
+		method       string
+		status       int
+		stats        []visor.AddressStats
+		approximate  bool
+		err          error
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "404 - wallet does not exist",
+			method:       http.MethodGet,
+			status:       http.StatusNotFound,
+			err:          wallet.ErrWalletNotExist,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:         "403 - wallet api disabled",
+			method:       http.MethodGet,
+			status:       http.StatusForbidden,
+			err:          wallet.ErrWalletAPIDisabled,
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:         "500 - other error",
+			method:       http.MethodGet,
+			status:       http.StatusInternalServerError,
+			err:          errors.New("db error"),
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "db error"),
+		},
+		{
+			name:         "200",
+			method:       http.MethodGet,
+			status:       http.StatusOK,
+			stats:        stats,
+			approximate:  true,
+			httpResponse: HTTPResponse{Data: okResponse},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.method == http.MethodGet {
+				gateway.On("GetWalletAddressStats", "foo.wlt").Return(tc.stats, tc.approximate, tc.err)
+			}
+
+			req, err := http.NewRequest(tc.method, "/api/v2/wallet/foo.wlt/address_stats", nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var statsRsp WalletAddressStatsResponse
+				err := json.Unmarshal(rsp.Data, &statsRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(WalletAddressStatsResponse), statsRsp)
+			}
+
+			gateway.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWalletSession(t *testing.T) {
+	expires := time.Unix(1540000300, 0)
+
+	type startSessionResult struct {
+		expires time.Time
+		err     error
+	}
+
+	cases := []struct {
+		name         string
+		method       string
+		status       int
+		httpBody     string
+		req          *WalletSessionRequest
+		startSession *startSessionResult
+		endSession   *error
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "400 - invalid json",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			httpBody:     "{",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "unexpected EOF"),
+		},
+		{
+			name:   "400 - invalid pin",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletSessionRequest{
+				Password: "pwd",
+				PIN:      "12",
+			},
+			startSession: &startSessionResult{err: wallet.ErrInvalidPIN},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "pin must be 4 to 8 digits"),
+		},
+		{
+			name:   "400 - invalid password",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletSessionRequest{
+				Password: "wrong",
+				PIN:      "1234",
+			},
+			startSession: &startSessionResult{err: wallet.ErrInvalidPassword},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid password"),
+		},
+		{
+			name:   "403 - wallet api disabled",
+			method: http.MethodPost,
+			status: http.StatusForbidden,
+			req: &WalletSessionRequest{
+				Password: "pwd",
+				PIN:      "1234",
+			},
+			startSession: &startSessionResult{err: wallet.ErrWalletAPIDisabled},
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:   "404 - wallet not exist",
+			method: http.MethodPost,
+			status: http.StatusNotFound,
+			req: &WalletSessionRequest{
+				Password: "pwd",
+				PIN:      "1234",
+			},
+			startSession: &startSessionResult{err: wallet.ErrWalletNotExist},
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:   "200 - start session",
+			method: http.MethodPost,
+			status: http.StatusOK,
+			req: &WalletSessionRequest{
+				Password: "pwd",
+				PIN:      "1234",
+			},
+			startSession: &startSessionResult{expires: expires},
+			httpResponse: HTTPResponse{
+				Data: WalletSessionResponse{
+					Expires: 1540000300,
+				},
+			},
+		},
+		{
+			name:         "403 - end session wallet api disabled",
+			method:       http.MethodDelete,
+			status:       http.StatusForbidden,
+			endSession:   &wallet.ErrWalletAPIDisabled,
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:         "200 - end session",
+			method:       http.MethodDelete,
+			status:       http.StatusOK,
+			endSession:   new(error),
+			httpResponse: HTTPResponse{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.startSession != nil {
+				gateway.On("StartSession", "foo.wlt", []byte(tc.req.Password), []byte(tc.req.PIN)).Return(tc.startSession.expires, tc.startSession.err)
+			}
+			if tc.endSession != nil {
+				gateway.On("EndSession", "foo.wlt").Return(*tc.endSession)
+			}
+
+			if tc.httpBody == "" && tc.req != nil {
+				tc.httpBody = toJSON(t, tc.req)
+			}
+
+			req, err := http.NewRequest(tc.method, "/api/v2/wallet/foo.wlt/session", strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var sessionRsp WalletSessionResponse
+				err := json.Unmarshal(rsp.Data, &sessionRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(WalletSessionResponse), sessionRsp)
+			}
+
+			gateway.AssertExpectations(t)
+		})
+	}
+}
@@ -106,7 +106,10 @@ func MustDecodeBase58Address(addr string) Address {
 	return a
 }
 
-// AddressFromBytes converts []byte to an Address
+// AddressFromBytes converts []byte to an Address.
+// The checksum covers the version byte and is checked first, so ErrAddressInvalidChecksum means
+// that the address was mistyped or corrupted, and ErrAddressInvalidVersion that it is a valid address
+// of another version.
 func AddressFromBytes(b []byte) (Address, error) {
 	if len(b) != 20+1+4 {
 		return Address{}, ErrAddressInvalidLength
@@ -124,6 +124,66 @@ func TestAddressFromBytes(t *testing.T) {
 	require.EqualError(t, err, "Address version invalid")
 }
 
+func TestAddressFromBytesChecksumVersionErrors(t *testing.T) {
+	require.NotEqual(t, ErrAddressInvalidChecksum, ErrAddressInvalidVersion)
+	require.NotEqual(t, ErrAddressInvalidChecksum.Error(), ErrAddressInvalidVersion.Error())
+
+	p, _ := GenerateKeyPair()
+	a := AddressFromPubKey(p)
+
+	// An address of another version has a valid checksum, it is from another network
+	for v := 1; v <= 0xFF; v++ {
+		other := a
+		other.Version = byte(v)
+
+		_, err := AddressFromBytes(other.Bytes())
+		require.Equal(t, ErrAddressInvalidVersion, err, "version %d", v)
+
+		_, err = DecodeBase58Address(other.String())
+		require.Equal(t, ErrAddressInvalidVersion, err, "version %d", v)
+	}
+
+	// Changing any byte, including the version byte, invalidates the checksum.
+	// A corrupted address of another version is reported as a checksum error too.
+	for _, version := range []byte{0, 1} {
+		for i := 0; i < 20+1+4; i++ {
+			for _, x := range []byte{0x01, 0x80, 0xFF} {
+				b := Address{
+					Version: version,
+					Key:     a.Key,
+				}.Bytes()
+				b[i] ^= x
+
+				_, err := AddressFromBytes(b)
+				require.Equal(t, ErrAddressInvalidChecksum, err, "version %d byte %d xor %x", version, i, x)
+			}
+		}
+	}
+}
+
+func TestDecodeBase58AddressTypo(t *testing.T) {
+	const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+	p, _ := GenerateKeyPair()
+	s := AddressFromPubKey(p).String()
+
+	// A mistyped character is never accepted, nor reported as an address of another version.
+	// Most typos are checksum errors, a typo can also change the length of the decoded bytes.
+	for i := range s {
+		for _, c := range alphabet {
+			if byte(c) == s[i] {
+				continue
+			}
+
+			typo := s[:i] + string(c) + s[i+1:]
+			_, err := DecodeBase58Address(typo)
+			require.Error(t, err)
+			require.NotEqual(t, ErrAddressInvalidVersion, err, typo)
+			require.True(t, err == ErrAddressInvalidChecksum || err == ErrAddressInvalidLength, "%s: %v", typo, err)
+		}
+	}
+}
+
 func TestMustAddressFromBytes(t *testing.T) {
 	p, _ := GenerateKeyPair()
 	a := AddressFromPubKey(p)
@@ -170,6 +170,29 @@ func TestBitcoinAddressFromBytes(t *testing.T) {
 	require.EqualError(t, err, "Address version invalid")
 }
 
+func TestBitcoinAddressFromBytesChecksumVersionErrors(t *testing.T) {
+	p, _ := GenerateKeyPair()
+	a := BitcoinAddressFromPubKey(p)
+
+	// An address of another version has a valid checksum, only mainnet addresses are supported
+	for v := 1; v <= 0xFF; v++ {
+		other := a
+		other.Version = byte(v)
+
+		_, err := BitcoinAddressFromBytes(other.Bytes())
+		require.Equal(t, ErrAddressInvalidVersion, err, "version %d", v)
+	}
+
+	// Changing any byte, including the version byte, invalidates the checksum
+	for i := 0; i < 20+1+4; i++ {
+		b := a.Bytes()
+		b[i] ^= 0x01
+
+		_, err := BitcoinAddressFromBytes(b)
+		require.Equal(t, ErrAddressInvalidChecksum, err, "byte %d", i)
+	}
+}
+
 func TestMustBitcoinAddressFromBytes(t *testing.T) {
 	p, _ := GenerateKeyPair()
 	a := BitcoinAddressFromPubKey(p)
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"hash"
+	"io"
 	"log"
 	"time"
 
@@ -175,6 +176,27 @@ func MustPubKeyFromSig(sig Sig, hash SHA256) PubKey {
 	return pk
 }
 
+// PubKeyFromUncompressed converts a 65 byte uncompressed public key in the format
+// "<0x04> <X> <Y>" to a PubKey
+func PubKeyFromUncompressed(b [65]byte) (PubKey, error) {
+	compressed := secp256k1.CompressPubkey(b[:])
+	if compressed == nil {
+		return PubKey{}, ErrInvalidPubKey
+	}
+	return NewPubKey(compressed)
+}
+
+// ToUncompressed returns the 65 byte uncompressed form of the public key, in the format "<0x04> <X> <Y>"
+func (pk PubKey) ToUncompressed() ([65]byte, error) {
+	if err := pk.Verify(); err != nil {
+		return [65]byte{}, err
+	}
+
+	var b [65]byte
+	copy(b[:], secp256k1.UncompressPubkey(pk[:]))
+	return b, nil
+}
+
 // Verify attempts to determine if pubkey is valid. Returns nil on success
 func (pk PubKey) Verify() error {
 	if secp256k1.VerifyPubkey(pk[:]) != 1 {
@@ -359,6 +381,12 @@ func (s Sig) Hex() string {
 	return hex.EncodeToString(s[:])
 }
 
+// RecoverPublicKey recovers the public key that signed the SHA256 hash of msg.
+// It is the same as PubKeyFromSig(s, SumSHA256(msg))
+func (s Sig) RecoverPublicKey(msg []byte) (PubKey, error) {
+	return PubKeyFromSig(s, SumSHA256(msg))
+}
+
 // SignHash sign hash
 func SignHash(hash SHA256, sec SecKey) (Sig, error) {
 	if secp256k1.VerifySeckey(sec[:]) != 1 {
@@ -502,6 +530,35 @@ func GenerateKeyPair() (PubKey, SecKey) {
 	return pubKey, secKey
 }
 
+// GenerateKeyPairFromReader creates a key pair from random bytes read from r.
+// Bytes that do not form a valid secret key are skipped, and more bytes are read.
+// It is intended for reproducible key pairs in tests, e.g. with a bytes.Reader over a fixed seed;
+// use GenerateKeyPair, which reads the OS random source, for keys that hold funds.
+func GenerateKeyPairFromReader(r io.Reader) (PubKey, SecKey, error) {
+	for {
+		var b [32]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return PubKey{}, SecKey{}, err
+		}
+
+		if secp256k1.VerifySeckey(b[:]) != 1 {
+			continue
+		}
+
+		secKey, err := NewSecKey(b[:])
+		if err != nil {
+			return PubKey{}, SecKey{}, err
+		}
+
+		pubKey, err := PubKeyFromSecKey(secKey)
+		if err != nil {
+			return PubKey{}, SecKey{}, err
+		}
+
+		return pubKey, secKey, nil
+	}
+}
+
 // GenerateDeterministicKeyPair generates deterministic key pair
 func GenerateDeterministicKeyPair(seed []byte) (PubKey, SecKey, error) {
 	if len(seed) == 0 {
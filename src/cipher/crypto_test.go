@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -150,6 +151,73 @@ func TestPubKeyVerifyDefault2(t *testing.T) {
 	}
 }
 
+func TestPubKeyToUncompressed(t *testing.T) {
+	// There are no NIST test vectors for secp256k1, which is not a NIST curve.
+	// The multiples of the generator point are from SEC 2 (https://www.secg.org/sec2-v2.pdf),
+	// the last vector is from http://www.righto.com/2014/02/bitcoins-hard-way-using-raw-bitcoin.html
+	cases := []struct {
+		seckey       string
+		uncompressed string
+	}{
+		{
+			seckey:       "0000000000000000000000000000000000000000000000000000000000000001",
+			uncompressed: "0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8",
+		},
+		{
+			seckey:       "0000000000000000000000000000000000000000000000000000000000000002",
+			uncompressed: "04c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee51ae168fea63dc339a3c58419466ceaeef7f632653266d0e1236431a950cfe52a",
+		},
+		{
+			seckey:       "0000000000000000000000000000000000000000000000000000000000000003",
+			uncompressed: "04f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9388f7b0f632de8140fe337e62a37f3566500a99934c2231b6cb9fd7584b8e672",
+		},
+		{
+			seckey:       "f19c523315891e6e15ae0608a35eec2e00ebd6d1984cf167f46336dabd9b2de4",
+			uncompressed: "04fe43d0c2c3daab30f9472beb5b767be020b81c7cc940ed7a7e910f0c1d9feef10fe85eb3ce193405c2dd8453b7aeb6c1752361efdbf4f52ea8bf8f304aab37ab",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.seckey, func(t *testing.T) {
+			pk := MustPubKeyFromSecKey(MustSecKeyFromHex(tc.seckey))
+
+			b, err := pk.ToUncompressed()
+			require.NoError(t, err)
+			require.Equal(t, tc.uncompressed, hex.EncodeToString(b[:]))
+
+			pk2, err := PubKeyFromUncompressed(b)
+			require.NoError(t, err)
+			require.Equal(t, pk, pk2)
+		})
+	}
+
+	// A random pubkey survives the round trip
+	pk, _ := GenerateKeyPair()
+	b, err := pk.ToUncompressed()
+	require.NoError(t, err)
+	pk2, err := PubKeyFromUncompressed(b)
+	require.NoError(t, err)
+	require.Equal(t, pk, pk2)
+
+	// Invalid pubkeys can't be uncompressed
+	_, err = PubKey{}.ToUncompressed()
+	require.Equal(t, ErrInvalidPubKey, err)
+
+	// Invalid uncompressed pubkeys are rejected
+	bad := b
+	bad[0] = 0x03
+	_, err = PubKeyFromUncompressed(bad)
+	require.Equal(t, ErrInvalidPubKey, err)
+
+	bad = b
+	bad[64] ^= 0x01
+	_, err = PubKeyFromUncompressed(bad)
+	require.Equal(t, ErrInvalidPubKey, err)
+
+	_, err = PubKeyFromUncompressed([65]byte{})
+	require.Equal(t, ErrInvalidPubKey, err)
+}
+
 func TestPubKeyRipemd160(t *testing.T) {
 	p, _ := GenerateKeyPair()
 	h := PubKeyRipemd160(p)
@@ -513,6 +581,25 @@ func TestPubKeyFromSig(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestSigRecoverPublicKey(t *testing.T) {
+	p, s := GenerateKeyPair()
+	msg := randBytes(t, 256)
+	sig := MustSignHash(SumSHA256(msg), s)
+
+	p2, err := sig.RecoverPublicKey(msg)
+	require.NoError(t, err)
+	require.Equal(t, p, p2)
+
+	// A different message recovers a different public key
+	p3, err := sig.RecoverPublicKey(randBytes(t, 256))
+	if err == nil {
+		require.NotEqual(t, p, p3)
+	}
+
+	_, err = Sig{}.RecoverPublicKey(msg)
+	require.Equal(t, ErrInvalidSigPubKeyRecovery, err)
+}
+
 func TestMustPubKeyFromSig(t *testing.T) {
 	p, s := GenerateKeyPair()
 	h := SumSHA256(randBytes(t, 256))
@@ -548,6 +635,43 @@ func TestGenerateKeyPair(t *testing.T) {
 	}
 }
 
+func TestGenerateKeyPairFromReader(t *testing.T) {
+	seed := randBytes(t, 64)
+
+	p, s, err := GenerateKeyPairFromReader(bytes.NewReader(seed))
+	require.NoError(t, err)
+	require.NoError(t, p.Verify())
+	require.NoError(t, s.Verify())
+	require.Equal(t, MustPubKeyFromSecKey(s), p)
+	require.Equal(t, seed[:32], s[:])
+
+	// The same source gives the same key pair
+	p2, s2, err := GenerateKeyPairFromReader(bytes.NewReader(seed))
+	require.NoError(t, err)
+	require.Equal(t, p, p2)
+	require.Equal(t, s, s2)
+
+	// Successive key pairs read successive bytes
+	r := bytes.NewReader(seed)
+	_, _, err = GenerateKeyPairFromReader(r)
+	require.NoError(t, err)
+	_, s2, err = GenerateKeyPairFromReader(r)
+	require.NoError(t, err)
+	require.Equal(t, seed[32:], s2[:])
+
+	// Bytes that are not a valid secret key are skipped
+	invalid := bytes.Repeat([]byte{0xFF}, 32)
+	_, s2, err = GenerateKeyPairFromReader(bytes.NewReader(append(invalid, seed[:32]...)))
+	require.NoError(t, err)
+	require.Equal(t, s, s2)
+
+	// The reader runs out of bytes
+	_, _, err = GenerateKeyPairFromReader(bytes.NewReader(seed[:31]))
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+	_, _, err = GenerateKeyPairFromReader(bytes.NewReader(invalid))
+	require.Equal(t, io.EOF, err)
+}
+
 func TestGenerateDeterministicKeyPair(t *testing.T) {
 	// TODO -- deterministic key pairs are useless as is because we can't
 	// generate pair n+1, only pair 0
@@ -0,0 +1,52 @@
+package cipher
+
+import (
+	"encoding/hex"
+	"log"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// ToEthereumAddress returns the Ethereum address of the public key, so that the same key pair can be used
+// on Ethereum. The address is the last 20 bytes of the Keccak-256 hash of the uncompressed public key
+// without its 0x04 prefix, hex encoded with a 0x prefix and an EIP-55 checksum.
+// Panics if the public key is invalid.
+func (pk PubKey) ToEthereumAddress() string {
+	b, err := pk.ToUncompressed()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	h := keccak256(b[1:])
+	return ethereumChecksumAddress(h[12:])
+}
+
+// ethereumChecksumAddress hex encodes a 20 byte Ethereum address with the EIP-55 mixed case checksum:
+// a letter is uppercased if the matching nibble of the Keccak-256 hash of the lowercase hex address is 8 or more
+func ethereumChecksumAddress(addr []byte) string {
+	s := []byte(hex.EncodeToString(addr))
+	h := keccak256(s)
+
+	for i, c := range s {
+		if c < 'a' {
+			continue
+		}
+
+		nibble := h[i/2] >> 4
+		if i%2 == 1 {
+			nibble = h[i/2] & 0x0f
+		}
+		if nibble >= 8 {
+			s[i] = c - 'a' + 'A'
+		}
+	}
+
+	return "0x" + string(s)
+}
+
+// keccak256 returns the legacy Keccak-256 hash used by Ethereum, which differs from SHA3-256 by its padding
+func keccak256(b []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(b) //nolint:errcheck
+	return h.Sum(nil)
+}
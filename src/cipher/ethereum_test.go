@@ -0,0 +1,128 @@
+package cipher
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestPubKeyToEthereumAddress(t *testing.T) {
+	cases := []struct {
+		seckey string
+		addr   string
+	}{
+		{
+			seckey: "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318",
+			addr:   "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.addr, func(t *testing.T) {
+			pk := MustPubKeyFromSecKey(MustSecKeyFromHex(tc.seckey))
+			require.Equal(t, tc.addr, pk.ToEthereumAddress())
+		})
+	}
+
+	require.Panics(t, func() {
+		_ = PubKey{}.ToEthereumAddress()
+	})
+}
+
+func TestEthereumChecksumAddress(t *testing.T) {
+	// Test vectors from EIP-55
+	cases := []string{
+		"0x52908400098527886E0F7030069857D2E4169EE7",
+		"0x8617E340B3D01FA5F11F306F4090FD50E238070D",
+		"0xde709f2102306220921060314715629080e2fb77",
+		"0x27b1fdb04752bbc536007a920d24acb045561c26",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	}
+
+	for _, addr := range cases {
+		t.Run(addr, func(t *testing.T) {
+			b, err := hex.DecodeString(strings.ToLower(addr[2:]))
+			require.NoError(t, err)
+			require.Equal(t, addr, ethereumChecksumAddress(b))
+		})
+	}
+}
+
+func TestKeccak256(t *testing.T) {
+	cases := []struct {
+		in  string
+		out string
+	}{
+		{
+			in:  "",
+			out: "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+		},
+		{
+			in:  "abc",
+			out: "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45",
+		},
+		{
+			in:  "The quick brown fox jumps over the lazy dog",
+			out: "4d741b6f1eb29cb2a9b9911c82f56fa8d73b04959d3d9d222895df6c0b28aa15",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			h := keccak256([]byte(tc.in))
+			require.Equal(t, tc.out, hex.EncodeToString(h))
+		})
+	}
+}
+
+// The Keccak-256 sponge of x/crypto is shared with SHA3-256, which only differs by the padding,
+// so it is checked against the SHA3-256 test vectors for inputs that span multiple blocks
+func TestKeccak256SHA3Sponge(t *testing.T) {
+	// rate is the number of bytes absorbed per block by Keccak-256 and SHA3-256
+	const rate = 136
+
+	cases := []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{
+			name: "empty",
+			in:   "",
+			out:  "a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a",
+		},
+		{
+			name: "abc",
+			in:   "abc",
+			out:  "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532",
+		},
+		{
+			name: "rate minus one",
+			in:   strings.Repeat("a", rate-1),
+			out:  "8094bb53c44cfb1e67b7c30447f9a1c33696d2463ecc1d9c92538913392843c9",
+		},
+		{
+			name: "rate",
+			in:   strings.Repeat("a", rate),
+			out:  "3fc5559f14db8e453a0a3091edbd2bc25e11528d81c66fa570a4efdcc2695ee1",
+		},
+		{
+			name: "multiple blocks",
+			in:   strings.Repeat("abcdefghij", 50),
+			out:  "ecb77d452a2f202722e70db84ed72a17f892b56c2331ee9e43c5dfbac697fee5",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := sha3.Sum256([]byte(tc.in))
+			require.Equal(t, tc.out, hex.EncodeToString(h[:]))
+		})
+	}
+}
@@ -194,15 +194,5 @@ func nextPowerOfTwo(n uint64) uint64 {
 // Merkle computes the merkle root of a hash array
 // Array of hashes is padded with 0 hashes until next power of 2
 func Merkle(h0 []SHA256) SHA256 {
-	lh := uint64(len(h0))
-	np := nextPowerOfTwo(lh)
-	h1 := append(h0, make([]SHA256, np-lh)...)
-	for len(h1) != 1 {
-		h2 := make([]SHA256, len(h1)/2)
-		for i := 0; i < len(h2); i++ {
-			h2[i] = AddSHA256(h1[2*i], h1[2*i+1])
-		}
-		h1 = h2
-	}
-	return h1[0]
+	return NewMerkleTreeFromHashes(h0).Root()
 }
@@ -0,0 +1,111 @@
+package cipher
+
+import (
+	"log"
+)
+
+// MerkleTree is a merkle tree of hashes, as used for the body hash of a block.
+// Each node is the SHA256 of the concatenation of its children.
+// The leaves are padded with null hashes up to the next power of 2,
+// so a tree of a single leaf has the leaf hash as its root.
+type MerkleTree struct {
+	// levels[0] are the padded leaf hashes, each following level has half as many hashes
+	// and the last level is the root
+	levels [][]SHA256
+	// number of leaves, excluding the padding
+	n int
+}
+
+// MerkleProof proves that a leaf is in a MerkleTree
+type MerkleProof struct {
+	// Index of the leaf in the tree
+	Index int
+	// Hashes of the siblings of the nodes on the path from the leaf up to the root, starting with the leaf's sibling
+	Hashes []SHA256
+}
+
+// NewMerkleTree creates a MerkleTree of arbitrary data. The leaf hashes are the SumSHA256 of the leaves
+func NewMerkleTree(leaves [][]byte) *MerkleTree {
+	hashes := make([]SHA256, len(leaves))
+	for i, l := range leaves {
+		hashes[i] = SumSHA256(l)
+	}
+
+	return NewMerkleTreeFromHashes(hashes)
+}
+
+// NewMerkleTreeFromHashes creates a MerkleTree of leaf hashes
+func NewMerkleTreeFromHashes(hashes []SHA256) *MerkleTree {
+	n := len(hashes)
+	level := make([]SHA256, nextPowerOfTwo(uint64(n)))
+	copy(level, hashes)
+
+	levels := [][]SHA256{level}
+	for len(level) != 1 {
+		next := make([]SHA256, len(level)/2)
+		for i := range next {
+			next[i] = AddSHA256(level[2*i], level[2*i+1])
+		}
+		levels = append(levels, next)
+		level = next
+	}
+
+	return &MerkleTree{
+		levels: levels,
+		n:      n,
+	}
+}
+
+// Root returns the merkle root
+func (t *MerkleTree) Root() SHA256 {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Len returns the number of leaves, excluding the padding
+func (t *MerkleTree) Len() int {
+	return t.n
+}
+
+// Proof returns the proof that the leaf at index is in the tree. Panics if index is out of range
+func (t *MerkleTree) Proof(index int) MerkleProof {
+	if index < 0 || index >= t.n {
+		log.Panicf("MerkleTree.Proof: index %d out of range of %d leaves", index, t.n)
+	}
+
+	p := MerkleProof{
+		Index:  index,
+		Hashes: make([]SHA256, 0, len(t.levels)-1),
+	}
+
+	for _, level := range t.levels[:len(t.levels)-1] {
+		p.Hashes = append(p.Hashes, level[index^1])
+		index /= 2
+	}
+
+	return p
+}
+
+// VerifyProof returns true if the proof shows that the leaf data is in the tree with the root
+func VerifyProof(leaf []byte, proof MerkleProof, root SHA256) bool {
+	return VerifyProofHash(SumSHA256(leaf), proof, root)
+}
+
+// VerifyProofHash returns true if the proof shows that the leaf hash is in the tree with the root.
+// For the body hash of a block, the leaf hashes are the transaction hashes.
+func VerifyProofHash(hash SHA256, proof MerkleProof, root SHA256) bool {
+	if proof.Index < 0 || len(proof.Hashes) >= 63 || proof.Index>>uint(len(proof.Hashes)) != 0 {
+		return false
+	}
+
+	index := proof.Index
+	for _, h := range proof.Hashes {
+		if index%2 == 0 {
+			hash = AddSHA256(hash, h)
+		} else {
+			hash = AddSHA256(h, hash)
+		}
+		index /= 2
+	}
+
+	return hash == root
+}
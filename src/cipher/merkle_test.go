@@ -0,0 +1,126 @@
+package cipher
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerkleTreeRoot(t *testing.T) {
+	// Roots of the trees of the leaves "a", "b", "c"... in the block body hash scheme:
+	// single SHA256 of the concatenated children, with the leaves padded with null hashes to a power of 2
+	cases := []struct {
+		leaves []string
+		root   string
+	}{
+		{
+			leaves: nil,
+			root:   "0000000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			leaves: []string{"a"},
+			root:   "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb",
+		},
+		{
+			leaves: []string{"a", "b"},
+			root:   "e5a01fee14e0ed5c48714f22180f25ad8365b53f9779f79dc4a3d7e93963f94a",
+		},
+		{
+			leaves: []string{"a", "b", "c"},
+			root:   "d0a664079d491a97357efa1ce1eab5aeb566adef78a2b910e8d13e901e192832",
+		},
+		{
+			leaves: []string{"a", "b", "c", "d"},
+			root:   "14ede5e8e97ad9372327728f5099b95604a39593cac3bd38a343ad76205213e7",
+		},
+		{
+			leaves: []string{"a", "b", "c", "d", "e"},
+			root:   "c6cde104e4847b9111f224882d4fb270b5f240f1bd24dda998828dc06303708c",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(fmt.Sprint(tc.leaves), func(t *testing.T) {
+			leaves := make([][]byte, len(tc.leaves))
+			hashes := make([]SHA256, len(tc.leaves))
+			for i, l := range tc.leaves {
+				leaves[i] = []byte(l)
+				hashes[i] = SumSHA256(leaves[i])
+			}
+
+			tree := NewMerkleTree(leaves)
+			require.Equal(t, tc.root, tree.Root().Hex())
+			require.Equal(t, len(tc.leaves), tree.Len())
+
+			require.Equal(t, tree.Root(), NewMerkleTreeFromHashes(hashes).Root())
+			require.Equal(t, tree.Root(), Merkle(hashes))
+		})
+	}
+}
+
+func TestMerkleDoesNotModifyHashes(t *testing.T) {
+	// Merkle must not write the padding into the spare capacity of the caller's slice
+	hashes := make([]SHA256, 3, 4)
+	all := hashes[:4]
+	all[3] = SumSHA256([]byte("x"))
+
+	Merkle(hashes)
+	require.Equal(t, SumSHA256([]byte("x")), all[3])
+}
+
+func TestMerkleTreeProof(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		leaves := make([][]byte, n)
+		for i := range leaves {
+			leaves[i] = randBytes(t, 32)
+		}
+
+		tree := NewMerkleTree(leaves)
+		root := tree.Root()
+
+		for i, l := range leaves {
+			p := tree.Proof(i)
+			require.Equal(t, i, p.Index)
+			require.Len(t, p.Hashes, len(tree.levels)-1)
+			require.True(t, VerifyProof(l, p, root), "n=%d i=%d", n, i)
+			require.True(t, VerifyProofHash(SumSHA256(l), p, root))
+
+			// The proof is not valid for another leaf or root
+			require.False(t, VerifyProof(randBytes(t, 32), p, root))
+			require.False(t, VerifyProof(l, p, SumSHA256(root[:])))
+
+			if len(p.Hashes) == 0 {
+				continue
+			}
+
+			// The proof is not valid for another index
+			other := p
+			other.Index = i ^ 1
+			require.False(t, VerifyProof(l, other, root))
+			other.Index = i + 1<<uint(len(p.Hashes))
+			require.False(t, VerifyProof(l, other, root))
+			other.Index = -1
+			require.False(t, VerifyProof(l, other, root))
+
+			// The proof is not valid with a modified hash
+			other = MerkleProof{
+				Index:  i,
+				Hashes: append([]SHA256{}, p.Hashes...),
+			}
+			other.Hashes[len(other.Hashes)-1] = SumSHA256(other.Hashes[len(other.Hashes)-1][:])
+			require.False(t, VerifyProof(l, other, root))
+		}
+
+		require.Panics(t, func() {
+			tree.Proof(n)
+		})
+		require.Panics(t, func() {
+			tree.Proof(-1)
+		})
+	}
+
+	require.Panics(t, func() {
+		NewMerkleTree(nil).Proof(0)
+	})
+}
@@ -94,6 +94,44 @@ func TestUncompressedPubkeyFromSeckey(t *testing.T) {
 	}
 }
 
+func TestCompressPubkey(t *testing.T) {
+	// http://www.righto.com/2014/02/bitcoins-hard-way-using-raw-bitcoin.html
+	privkey, err := hex.DecodeString(`f19c523315891e6e15ae0608a35eec2e00ebd6d1984cf167f46336dabd9b2de4`)
+	if err != nil {
+		t.Fatal()
+	}
+
+	uncompressed, err := hex.DecodeString(`04fe43d0c2c3daab30f9472beb5b767be020b81c7cc940ed7a7e910f0c1d9feef10fe85eb3ce193405c2dd8453b7aeb6c1752361efdbf4f52ea8bf8f304aab37ab`)
+	if err != nil {
+		t.Fatal()
+	}
+
+	if pubkey := CompressPubkey(uncompressed); pubkey == nil {
+		t.Fatal()
+	} else if !bytes.Equal(pubkey, PubkeyFromSeckey(privkey)) {
+		t.Fatal()
+	}
+
+	// Wrong prefix
+	bad := append([]byte{}, uncompressed...)
+	bad[0] = 0x02
+	if CompressPubkey(bad) != nil {
+		t.Fatal()
+	}
+
+	// Not on the curve
+	bad = append([]byte{}, uncompressed...)
+	bad[64] ^= 0x01
+	if CompressPubkey(bad) != nil {
+		t.Fatal()
+	}
+
+	// Wrong length
+	if CompressPubkey(uncompressed[:64]) != nil {
+		t.Fatal()
+	}
+}
+
 // returns random pubkey, seckey, hash and signature
 func randX() ([]byte, []byte, []byte, []byte) {
 	pubkey, seckey := GenerateKeyPair()
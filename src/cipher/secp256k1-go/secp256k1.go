@@ -119,6 +119,34 @@ func UncompressPubkey(pubkey []byte) []byte {
 	return pubkey2
 }
 
+// CompressPubkey compresses an uncompressed pubkey in the format "<04> <X> <Y>".
+// Returns nil if the pubkey is not a point on the curve
+func CompressPubkey(pubkey []byte) []byte {
+	if len(pubkey) != 65 || pubkey[0] != 0x04 {
+		return nil
+	}
+
+	var pubXY secp.XY
+	pubXY.X.SetB32(pubkey[1:33])
+	pubXY.Y.SetB32(pubkey[33:65])
+	if !pubXY.IsValid() {
+		return nil
+	}
+
+	// Reject coordinates that are not reduced modulo the field prime,
+	// their compressed form would not decompress to the same bytes
+	if !bytes.Equal(pubXY.BytesUncompressed(), pubkey) {
+		return nil
+	}
+
+	compressed := pubXY.Bytes()
+	if VerifyPubkey(compressed) != 1 {
+		return nil
+	}
+
+	return compressed
+}
+
 // UncompressedPubkeyFromSeckey returns nil on error
 // should only need pubkey, not private key
 func UncompressedPubkeyFromSeckey(seckey []byte) []byte {
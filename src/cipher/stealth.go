@@ -0,0 +1,234 @@
+package cipher
+
+import (
+	"errors"
+	"log"
+	"math/big"
+
+	"github.com/skycoin/skycoin/src/cipher/base58"
+	secp256k1 "github.com/skycoin/skycoin/src/cipher/secp256k1-go"
+	secp256k1go "github.com/skycoin/skycoin/src/cipher/secp256k1-go/secp256k1-go2"
+)
+
+var (
+	// ErrStealthAddressInvalidLength Unexpected size of stealth address bytes buffer
+	ErrStealthAddressInvalidLength = errors.New("Invalid stealth address length")
+	// ErrStealthAddressInvalidChecksum Computed checksum did not match expected value
+	ErrStealthAddressInvalidChecksum = errors.New("Invalid stealth address checksum")
+	// ErrStealthAddressInvalidVersion Unsupported stealth address version value
+	ErrStealthAddressInvalidVersion = errors.New("Stealth address version invalid")
+	// ErrStealthInvalidTweak The shared secret of a stealth payment is not a valid secret key
+	ErrStealthInvalidTweak = errors.New("Stealth payment shared secret is not a valid secret key")
+)
+
+/*
+Stealth addresses let a recipient publish a single address that payments can be sent to,
+without the payments being linkable on the blockchain.
+
+A stealth address encodes two public keys of the recipient:
+- the scan key, whose secret key is used to find the payments to the recipient
+- the spend key, whose secret key is used to spend them
+
+To pay a stealth address, the sender creates an ephemeral key pair (r, R=rG) and derives
+- the shared secret c = SHA256(ECDH(scan, r))
+- the one-time public key P = spend + cG
+The payment is sent to the ordinary address of P, and R is given to the recipient alongside it.
+
+The recipient computes c = SHA256(ECDH(R, scanSecret)) to recognize P, and spends it with the
+one-time secret key p = spendSecret + c (mod n).
+
+One-time addresses are ordinary addresses, they can't be distinguished from other addresses.
+
+In base 58 format the stealth address is 1+33+33+4 bytes
+- the version byte
+- the scan public key
+- the spend public key
+- the first 4 bytes of the SHA256 of the 67 bytes that come before
+*/
+
+const stealthAddressLen = 1 + 33 + 33 + 4
+
+// StealthAddress is the public address of a recipient of stealth payments
+type StealthAddress struct {
+	Version  byte
+	ScanKey  PubKey
+	SpendKey PubKey
+}
+
+// NewStealthAddress creates a StealthAddress from a scan and a spend public key
+func NewStealthAddress(scan, spend PubKey) (StealthAddress, error) {
+	if err := scan.Verify(); err != nil {
+		return StealthAddress{}, err
+	}
+	if err := spend.Verify(); err != nil {
+		return StealthAddress{}, err
+	}
+
+	return StealthAddress{
+		Version:  0,
+		ScanKey:  scan,
+		SpendKey: spend,
+	}, nil
+}
+
+// DecodeBase58StealthAddress creates a StealthAddress from its base58 encoding
+func DecodeBase58StealthAddress(addr string) (StealthAddress, error) {
+	b, err := base58.Decode(addr)
+	if err != nil {
+		return StealthAddress{}, err
+	}
+	return StealthAddressFromBytes(b)
+}
+
+// IsStealthAddress returns true if addr is a base58 encoded stealth address
+func IsStealthAddress(addr string) bool {
+	_, err := DecodeBase58StealthAddress(addr)
+	return err == nil
+}
+
+// StealthAddressFromBytes converts []byte to a StealthAddress
+func StealthAddressFromBytes(b []byte) (StealthAddress, error) {
+	if len(b) != stealthAddressLen {
+		return StealthAddress{}, ErrStealthAddressInvalidLength
+	}
+
+	a := StealthAddress{
+		Version: b[0],
+	}
+	copy(a.ScanKey[:], b[1:34])
+	copy(a.SpendKey[:], b[34:67])
+
+	chksum := a.Checksum()
+	var checksum [4]byte
+	copy(checksum[:], b[67:])
+
+	if checksum != chksum {
+		return StealthAddress{}, ErrStealthAddressInvalidChecksum
+	}
+
+	if a.Version != 0 {
+		return StealthAddress{}, ErrStealthAddressInvalidVersion
+	}
+
+	return NewStealthAddress(a.ScanKey, a.SpendKey)
+}
+
+// Bytes return the stealth address as a byte slice
+func (sa StealthAddress) Bytes() []byte {
+	b := make([]byte, 0, stealthAddressLen)
+	b = append(b, sa.Version)
+	b = append(b, sa.ScanKey[:]...)
+	b = append(b, sa.SpendKey[:]...)
+	chksum := sa.Checksum()
+	return append(b, chksum[:]...)
+}
+
+// String returns the stealth address as a Base58 encoded string
+func (sa StealthAddress) String() string {
+	return string(base58.Encode(sa.Bytes()))
+}
+
+// Checksum returns the StealthAddress Checksum which is the first 4 bytes of sha256(version+scan+spend)
+func (sa StealthAddress) Checksum() Checksum {
+	b := make([]byte, 0, stealthAddressLen-4)
+	b = append(b, sa.Version)
+	b = append(b, sa.ScanKey[:]...)
+	b = append(b, sa.SpendKey[:]...)
+	h := SumSHA256(b)
+	c := Checksum{}
+	copy(c[:], h[:len(c)])
+	return c
+}
+
+// NewPayment derives the one-time address of a payment to the stealth address, from an ephemeral secret key
+// created by the sender for this payment. The ephemeral public key must be given to the recipient with the payment.
+// The ephemeral secret key must not be reused, or the payments can be linked.
+func (sa StealthAddress) NewPayment(ephemeral SecKey) (Address, PubKey, error) {
+	ephemeralPubKey, err := PubKeyFromSecKey(ephemeral)
+	if err != nil {
+		return Address{}, PubKey{}, err
+	}
+
+	shared, err := ECDH(sa.ScanKey, ephemeral)
+	if err != nil {
+		return Address{}, PubKey{}, err
+	}
+
+	pubKey, err := stealthPaymentPubKey(shared, sa.SpendKey)
+	if err != nil {
+		return Address{}, PubKey{}, err
+	}
+
+	return AddressFromPubKey(pubKey), ephemeralPubKey, nil
+}
+
+// StealthPaymentAddress returns the one-time address of the payment with the given ephemeral public key.
+// The recipient compares it with the address of an output to find the payments to their stealth address.
+func StealthPaymentAddress(scan SecKey, spend, ephemeral PubKey) (Address, error) {
+	shared, err := ECDH(ephemeral, scan)
+	if err != nil {
+		return Address{}, err
+	}
+
+	pubKey, err := stealthPaymentPubKey(shared, spend)
+	if err != nil {
+		return Address{}, err
+	}
+
+	return AddressFromPubKey(pubKey), nil
+}
+
+// StealthPaymentSecKey returns the one-time secret key that spends the payment with the given ephemeral public key
+func StealthPaymentSecKey(scan, spend SecKey, ephemeral PubKey) (SecKey, error) {
+	shared, err := ECDH(ephemeral, scan)
+	if err != nil {
+		return SecKey{}, err
+	}
+
+	if err := spend.verify(false); err != nil {
+		return SecKey{}, err
+	}
+
+	tweak := SumSHA256(shared)
+	if secp256k1.VerifySeckey(tweak[:]) != 1 {
+		return SecKey{}, ErrStealthInvalidTweak
+	}
+
+	// p = spend + tweak (mod n)
+	var k, t big.Int
+	k.SetBytes(spend[:])
+	t.SetBytes(tweak[:])
+	k.Add(&k, &t)
+	k.Mod(&k, &secp256k1go.TheCurve.Order.Int)
+
+	return NewSecKey(secp256k1go.LeftPadBytes(k.Bytes(), 32))
+}
+
+// stealthPaymentPubKey returns the one-time public key spend + SHA256(shared)*G
+func stealthPaymentPubKey(shared []byte, spend PubKey) (PubKey, error) {
+	if err := spend.Verify(); err != nil {
+		return PubKey{}, err
+	}
+
+	tweak := SumSHA256(shared)
+	if secp256k1.VerifySeckey(tweak[:]) != 1 {
+		return PubKey{}, ErrStealthInvalidTweak
+	}
+
+	tweakPubKey := secp256k1.PubkeyFromSeckey(tweak[:])
+	if tweakPubKey == nil {
+		return PubKey{}, ErrStealthInvalidTweak
+	}
+
+	var p1, p2 secp256k1go.XY
+	if err := p1.ParsePubkey(tweakPubKey); err != nil {
+		log.Panicf("stealthPaymentPubKey: invalid tweak pubkey: %v", err)
+	}
+	if err := p2.ParsePubkey(spend[:]); err != nil {
+		log.Panicf("stealthPaymentPubKey: invalid spend pubkey: %v", err)
+	}
+
+	p1.AddXY(&p2)
+
+	return NewPubKey(p1.Bytes())
+}
@@ -0,0 +1,126 @@
+package cipher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher/base58"
+)
+
+func TestNewStealthAddress(t *testing.T) {
+	scan, _ := GenerateKeyPair()
+	spend, _ := GenerateKeyPair()
+
+	sa, err := NewStealthAddress(scan, spend)
+	require.NoError(t, err)
+	require.Equal(t, byte(0), sa.Version)
+	require.Equal(t, scan, sa.ScanKey)
+	require.Equal(t, spend, sa.SpendKey)
+
+	_, err = NewStealthAddress(PubKey{}, spend)
+	require.Error(t, err)
+	_, err = NewStealthAddress(scan, PubKey{})
+	require.Error(t, err)
+}
+
+func TestStealthAddressEncoding(t *testing.T) {
+	scan, _ := GenerateKeyPair()
+	spend, _ := GenerateKeyPair()
+	sa, err := NewStealthAddress(scan, spend)
+	require.NoError(t, err)
+
+	b := sa.Bytes()
+	require.Len(t, b, stealthAddressLen)
+
+	sa2, err := StealthAddressFromBytes(b)
+	require.NoError(t, err)
+	require.Equal(t, sa, sa2)
+
+	s := sa.String()
+	sa2, err = DecodeBase58StealthAddress(s)
+	require.NoError(t, err)
+	require.Equal(t, sa, sa2)
+
+	require.True(t, IsStealthAddress(s))
+	require.False(t, IsStealthAddress(AddressFromPubKey(spend).String()))
+	require.False(t, IsStealthAddress(""))
+
+	// A stealth address is not an ordinary address
+	_, err = DecodeBase58Address(s)
+	require.Equal(t, ErrAddressInvalidLength, err)
+
+	_, err = StealthAddressFromBytes(b[:len(b)-1])
+	require.Equal(t, ErrStealthAddressInvalidLength, err)
+
+	bad := append([]byte{}, b...)
+	bad[len(bad)-1]++
+	_, err = StealthAddressFromBytes(bad)
+	require.Equal(t, ErrStealthAddressInvalidChecksum, err)
+
+	sa2 = sa
+	sa2.Version = 1
+	_, err = StealthAddressFromBytes(sa2.Bytes())
+	require.Equal(t, ErrStealthAddressInvalidVersion, err)
+
+	// The keys must be valid public keys
+	sa2 = sa
+	sa2.ScanKey = PubKey{}
+	_, err = DecodeBase58StealthAddress(string(base58.Encode(sa2.Bytes())))
+	require.Error(t, err)
+}
+
+func TestStealthPayment(t *testing.T) {
+	scan, scanSec := GenerateKeyPair()
+	spend, spendSec := GenerateKeyPair()
+	sa, err := NewStealthAddress(scan, spend)
+	require.NoError(t, err)
+
+	// The sender derives a one-time address for each payment
+	_, ephemeralSec := GenerateKeyPair()
+	addr, ephemeral, err := sa.NewPayment(ephemeralSec)
+	require.NoError(t, err)
+	require.Equal(t, MustPubKeyFromSecKey(ephemeralSec), ephemeral)
+	require.NotEqual(t, AddressFromPubKey(spend), addr)
+	require.NotEqual(t, AddressFromPubKey(scan), addr)
+
+	_, ephemeralSec2 := GenerateKeyPair()
+	addr2, ephemeral2, err := sa.NewPayment(ephemeralSec2)
+	require.NoError(t, err)
+	require.NotEqual(t, addr, addr2)
+
+	// The recipient finds the payments with the scan secret key
+	found, err := StealthPaymentAddress(scanSec, spend, ephemeral)
+	require.NoError(t, err)
+	require.Equal(t, addr, found)
+
+	found, err = StealthPaymentAddress(scanSec, spend, ephemeral2)
+	require.NoError(t, err)
+	require.Equal(t, addr2, found)
+
+	// Another scan key doesn't find the payment
+	_, otherScanSec := GenerateKeyPair()
+	found, err = StealthPaymentAddress(otherScanSec, spend, ephemeral)
+	require.NoError(t, err)
+	require.NotEqual(t, addr, found)
+
+	// The recipient spends the payments with the one-time secret key
+	sec, err := StealthPaymentSecKey(scanSec, spendSec, ephemeral)
+	require.NoError(t, err)
+	require.Equal(t, addr, MustAddressFromSecKey(sec))
+
+	hash := SumSHA256([]byte("stealth"))
+	sig := MustSignHash(hash, sec)
+	require.NoError(t, VerifyAddressSignedHash(addr, sig, hash))
+
+	sec, err = StealthPaymentSecKey(scanSec, spendSec, ephemeral2)
+	require.NoError(t, err)
+	require.Equal(t, addr2, MustAddressFromSecKey(sec))
+
+	_, _, err = sa.NewPayment(SecKey{})
+	require.Error(t, err)
+	_, err = StealthPaymentAddress(scanSec, spend, PubKey{})
+	require.Error(t, err)
+	_, err = StealthPaymentSecKey(scanSec, SecKey{}, ephemeral)
+	require.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package cipher
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+const (
+	// voucherPayloadLength is the length of the signed portion of a voucher: pubkey + hours + expiry
+	voucherPayloadLength = len(PubKey{}) + 8 + 8
+	// VoucherLength is the length of a serialized voucher: pubkey + hours + expiry + signature
+	VoucherLength = voucherPayloadLength + len(Sig{})
+)
+
+var (
+	// ErrInvalidLengthVoucher Invalid voucher length
+	ErrInvalidLengthVoucher = errors.New("Invalid voucher length")
+	// ErrVoucherPubKeyMismatch Voucher was not issued by the expected pubkey
+	ErrVoucherPubKeyMismatch = errors.New("Voucher pubkey does not match pubkey")
+	// ErrVoucherExpired Voucher expiry time has passed
+	ErrVoucherExpired = errors.New("Voucher has expired")
+	// ErrVoucherZeroHours Voucher does not grant any coin hours
+	ErrVoucherZeroHours = errors.New("Voucher hours must be greater than 0")
+)
+
+// SignVoucher creates a signed voucher redeemable for a number of coin hours until the expiry time.
+// The voucher is serialized as pubkey (33 bytes) + hours (8 bytes) + expiry unix time (8 bytes) + signature (65 bytes).
+// The signature is made over the SHA256 hash of the pubkey, hours and expiry bytes.
+func SignVoucher(secKey SecKey, hours uint64, expiry time.Time) ([]byte, error) {
+	if hours == 0 {
+		return nil, ErrVoucherZeroHours
+	}
+
+	pubKey, err := PubKeyFromSecKey(secKey)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, voucherPayloadLength, VoucherLength)
+	copy(b[:len(pubKey)], pubKey[:])
+	binary.LittleEndian.PutUint64(b[len(pubKey):], hours)
+	binary.LittleEndian.PutUint64(b[len(pubKey)+8:], uint64(expiry.Unix()))
+
+	sig, err := SignHash(SumSHA256(b), secKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, sig[:]...), nil
+}
+
+// VerifyVoucher verifies that a voucher was signed by pubKey and has not expired,
+// and returns the number of coin hours and expiry time encoded in the voucher
+func VerifyVoucher(pubKey PubKey, voucherBytes []byte) (uint64, time.Time, error) {
+	if len(voucherBytes) != VoucherLength {
+		return 0, time.Time{}, ErrInvalidLengthVoucher
+	}
+
+	var voucherPubKey PubKey
+	copy(voucherPubKey[:], voucherBytes[:len(voucherPubKey)])
+	if voucherPubKey != pubKey {
+		return 0, time.Time{}, ErrVoucherPubKeyMismatch
+	}
+
+	sig, err := NewSig(voucherBytes[voucherPayloadLength:])
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	if err := VerifyPubKeySignedHash(pubKey, sig, SumSHA256(voucherBytes[:voucherPayloadLength])); err != nil {
+		return 0, time.Time{}, err
+	}
+
+	hours := binary.LittleEndian.Uint64(voucherBytes[len(pubKey):])
+	expiry := time.Unix(int64(binary.LittleEndian.Uint64(voucherBytes[len(pubKey)+8:])), 0).UTC()
+
+	if hours == 0 {
+		return 0, time.Time{}, ErrVoucherZeroHours
+	}
+
+	if !time.Now().Before(expiry) {
+		return 0, time.Time{}, ErrVoucherExpired
+	}
+
+	return hours, expiry, nil
+}
@@ -0,0 +1,53 @@
+package cipher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerifyVoucher(t *testing.T) {
+	p, s := GenerateKeyPair()
+	p2, _ := GenerateKeyPair()
+
+	expiry := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	_, err := SignVoucher(s, 0, expiry)
+	require.Equal(t, ErrVoucherZeroHours, err)
+
+	_, err = SignVoucher(SecKey{}, 100, expiry)
+	require.Equal(t, ErrPubKeyFromNullSecKey, err)
+
+	v, err := SignVoucher(s, 100, expiry)
+	require.NoError(t, err)
+	require.Len(t, v, VoucherLength)
+
+	hours, exp, err := VerifyVoucher(p, v)
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), hours)
+	require.True(t, expiry.Equal(exp))
+
+	// Wrong pubkey
+	_, _, err = VerifyVoucher(p2, v)
+	require.Equal(t, ErrVoucherPubKeyMismatch, err)
+
+	// Invalid length
+	_, _, err = VerifyVoucher(p, v[:len(v)-1])
+	require.Equal(t, ErrInvalidLengthVoucher, err)
+	_, _, err = VerifyVoucher(p, append(v, 0))
+	require.Equal(t, ErrInvalidLengthVoucher, err)
+
+	// Tampered hours
+	v2 := make([]byte, len(v))
+	copy(v2, v)
+	v2[len(p)]++
+	_, _, err = VerifyVoucher(p, v2)
+	require.Error(t, err)
+
+	// Expired
+	v, err = SignVoucher(s, 100, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	_, _, err = VerifyVoucher(p, v)
+	require.Equal(t, ErrVoucherExpired, err)
+}
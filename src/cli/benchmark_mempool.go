@@ -0,0 +1,242 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/transaction"
+)
+
+func benchmarkMempoolCmd() *cobra.Command {
+	benchmarkMempoolCmd := &cobra.Command{
+		Short: "Measure the transaction rate the node's unconfirmed pool can sustain",
+		Use:   "benchmarkMempool [wallet]",
+		Long: `Submit signed transactions to the node at a fixed rate and report how many
+    were accepted and rejected, and the 50th, 95th and 99th percentile latencies
+    of the injectTransaction API.
+
+    The transactions are created and signed by the node before the benchmark starts,
+    with the wallet's unspent outputs. Each transaction spends one output and sends
+    its coins back to an address of the same wallet, so no coins leave the wallet.
+    Only the coin hours burned as fees are spent. Since an output can only be spent
+    once until the next block, rate * duration transactions require as many unspent outputs.
+
+    The transactions are broadcast to the network like any other transaction.
+    Run the benchmark against a node of a test network.
+
+    Use caution when using the "-p" command. If you have command history enabled
+    your wallet encryption password can be recovered from the history log. If you
+    do not include the "-p" option you will be prompted to enter your password
+    after you enter your command.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			rate, err := c.Flags().GetInt("rate")
+			if err != nil {
+				return err
+			}
+			if rate <= 0 {
+				return errors.New("--rate must be greater than 0")
+			}
+
+			duration, err := c.Flags().GetDuration("duration")
+			if err != nil {
+				return err
+			}
+			if duration <= 0 {
+				return errors.New("--duration must be greater than 0")
+			}
+
+			w, err := apiClient.Wallet(args[0])
+			if err != nil {
+				return err
+			}
+
+			var password string
+			if w.Meta.Encrypted {
+				p, err := getPassword(c)
+				if err != nil {
+					return err
+				}
+				password = string(p)
+			}
+
+			n := int(int64(rate) * int64(duration) / int64(time.Second))
+			if n == 0 {
+				n = 1
+			}
+
+			txns, err := createMempoolBenchmarkTxns(apiClient, w, password, n)
+			if err != nil {
+				return err
+			}
+			if len(txns) < n {
+				fmt.Printf("only %d of %d transactions could be created, the wallet does not have enough spendable outputs\n", len(txns), n)
+			}
+
+			result := RunMempoolBenchmark(txns, rate, apiClient.InjectEncodedTransaction)
+
+			return printJSON(result)
+		},
+	}
+
+	benchmarkMempoolCmd.Flags().Int("rate", 10, "Number of transactions submitted per second")
+	benchmarkMempoolCmd.Flags().Duration("duration", 30*time.Second, "Duration of the benchmark")
+	benchmarkMempoolCmd.Flags().StringP("password", "p", "", "Wallet password")
+
+	return benchmarkMempoolCmd
+}
+
+// createMempoolBenchmarkTxns creates and signs up to n transactions with the wallet, each spending one spendable output.
+// Outputs that can't be spent on their own, for example because they have no coin hours to pay the fee, are skipped.
+func createMempoolBenchmarkTxns(c *api.Client, w *api.WalletResponse, password string, n int) ([]string, error) {
+	if len(w.Entries) == 0 {
+		return nil, errors.New("the wallet has no addresses")
+	}
+
+	addrs := make([]string, len(w.Entries))
+	for i, e := range w.Entries {
+		addrs[i] = e.Address
+	}
+
+	outputs, err := c.OutputsForAddresses(addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	var txns []string
+	for i, o := range outputs.SpendableOutputs() {
+		if len(txns) == n {
+			break
+		}
+
+		to := addrs[i%len(addrs)]
+		rsp, err := c.WalletCreateTransaction(api.WalletCreateTransactionRequest{
+			WalletID: w.Meta.Filename,
+			Password: password,
+			CreateTransactionRequest: api.CreateTransactionRequest{
+				HoursSelection: api.HoursSelection{
+					Type:        transaction.HoursSelectionTypeAuto,
+					Mode:        transaction.HoursSelectionModeShare,
+					ShareFactor: "0.5",
+				},
+				ChangeAddress: &to,
+				To: []api.Receiver{
+					{
+						Address: to,
+						Coins:   o.Coins,
+					},
+				},
+				UxOuts: []string{o.Hash},
+			},
+		})
+		if err != nil {
+			continue
+		}
+
+		txns = append(txns, rsp.EncodedTransaction)
+	}
+
+	if len(txns) == 0 {
+		return nil, errors.New("no transactions could be created, the wallet has no spendable outputs with coin hours")
+	}
+
+	return txns, nil
+}
+
+// MempoolBenchmarkResult is the result of RunMempoolBenchmark
+type MempoolBenchmarkResult struct {
+	Submitted int `json:"submitted"`
+	Accepted  int `json:"accepted"`
+	Rejected  int `json:"rejected"`
+	// Rejection error messages and their number of occurrences
+	Errors   map[string]int `json:"errors,omitempty"`
+	Duration string         `json:"duration"`
+	// Achieved rate, in transactions per second
+	Rate       float64 `json:"rate"`
+	LatencyP50 string  `json:"latency_p50"`
+	LatencyP95 string  `json:"latency_p95"`
+	LatencyP99 string  `json:"latency_p99"`
+}
+
+// RunMempoolBenchmark submits the encoded transactions at rate transactions per second and measures
+// the latency of each submission. Submissions don't wait for the previous ones to complete,
+// so a slow node doesn't lower the submission rate.
+func RunMempoolBenchmark(txns []string, rate int, submit func(rawTxn string) (string, error)) MempoolBenchmarkResult {
+	var mx sync.Mutex
+	var wg sync.WaitGroup
+	latencies := make([]time.Duration, 0, len(txns))
+	result := MempoolBenchmarkResult{
+		Submitted: len(txns),
+	}
+
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+
+	start := time.Now()
+	for i, txn := range txns {
+		if i > 0 {
+			<-ticker.C
+		}
+
+		wg.Add(1)
+		go func(txn string) {
+			defer wg.Done()
+
+			t := time.Now()
+			_, err := submit(txn)
+			latency := time.Since(t)
+
+			mx.Lock()
+			defer mx.Unlock()
+
+			latencies = append(latencies, latency)
+			if err != nil {
+				result.Rejected++
+				if result.Errors == nil {
+					result.Errors = make(map[string]int)
+				}
+				result.Errors[err.Error()]++
+				return
+			}
+			result.Accepted++
+		}(txn)
+	}
+
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	result.Duration = elapsed.String()
+	if elapsed > 0 {
+		result.Rate = float64(len(txns)) / elapsed.Seconds()
+	}
+
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+	result.LatencyP50 = latencyPercentile(latencies, 50).String()
+	result.LatencyP95 = latencyPercentile(latencies, 95).String()
+	result.LatencyP99 = latencyPercentile(latencies, 99).String()
+
+	return result
+}
+
+// latencyPercentile returns the p-th percentile of sorted latencies, using the nearest rank method
+func latencyPercentile(latencies []time.Duration, p int) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+
+	// The nearest rank is ceil(p / 100 * n)
+	rank := (p*len(latencies) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return latencies[rank-1]
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyPercentile(t *testing.T) {
+	require.Equal(t, time.Duration(0), latencyPercentile(nil, 50))
+
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	require.Equal(t, 50*time.Millisecond, latencyPercentile(latencies, 50))
+	require.Equal(t, 95*time.Millisecond, latencyPercentile(latencies, 95))
+	require.Equal(t, 99*time.Millisecond, latencyPercentile(latencies, 99))
+
+	latencies = []time.Duration{time.Millisecond, 2 * time.Millisecond, 3 * time.Millisecond}
+	require.Equal(t, 2*time.Millisecond, latencyPercentile(latencies, 50))
+	require.Equal(t, 3*time.Millisecond, latencyPercentile(latencies, 95))
+	require.Equal(t, time.Millisecond, latencyPercentile(latencies, 0))
+}
+
+func TestRunMempoolBenchmark(t *testing.T) {
+	txns := []string{"a", "b", "c", "d", "e"}
+
+	var mx sync.Mutex
+	var submitted []string
+	submit := func(rawTxn string) (string, error) {
+		mx.Lock()
+		submitted = append(submitted, rawTxn)
+		mx.Unlock()
+
+		if rawTxn == "b" || rawTxn == "d" {
+			return "", errors.New("rejected")
+		}
+		return rawTxn, nil
+	}
+
+	start := time.Now()
+	result := RunMempoolBenchmark(txns, 100, submit)
+
+	// 5 transactions at 100/s are submitted over at least 40ms
+	require.True(t, time.Since(start) >= 40*time.Millisecond)
+	require.ElementsMatch(t, txns, submitted)
+	require.Equal(t, 5, result.Submitted)
+	require.Equal(t, 3, result.Accepted)
+	require.Equal(t, 2, result.Rejected)
+	require.Equal(t, map[string]int{"rejected": 2}, result.Errors)
+	require.NotEmpty(t, result.LatencyP50)
+	require.NotEmpty(t, result.LatencyP99)
+	require.True(t, result.Rate > 0)
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func blockByTimeCmd() *cobra.Command {
+	blockByTimeCmd := &cobra.Command{
+		Short:   "Find the block closest to a time, without connecting to a node",
+		Use:     "blockByTime",
+		Aliases: []string{"block-by-time"},
+		Long: `Prints the header of the block whose timestamp is closest to the time given with --time,
+    in RFC3339 format, e.g. 2018-01-02T15:04:05Z. If two blocks are equally close, the earlier one is printed.
+    The blocks are read from the database of a stopped node.
+    If --db is not specified, the default data.db in $HOME/.$COIN/ will be read.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, _ []string) error {
+			timeStr, err := c.Flags().GetString("time")
+			if err != nil {
+				return err
+			}
+			if timeStr == "" {
+				return errors.New("--time is required")
+			}
+
+			t, err := time.Parse(time.RFC3339, timeStr)
+			if err != nil {
+				return fmt.Errorf("invalid --time: %v", err)
+			}
+			if t.Unix() < 0 {
+				return errors.New("invalid --time: must not be before 1970-01-01T00:00:00Z")
+			}
+
+			db, err := c.Flags().GetString("db")
+			if err != nil {
+				return err
+			}
+
+			dbPath, err := resolveDBPath(cliConfig, db)
+			if err != nil {
+				return err
+			}
+
+			header, err := findBlockByTime(dbPath, uint64(t.Unix()))
+			if err != nil {
+				return err
+			}
+
+			return printJSON(header)
+		},
+	}
+
+	blockByTimeCmd.Flags().String("time", "", "time to find the closest block to, in RFC3339 format")
+	blockByTimeCmd.Flags().String("db", "", "database of a stopped node to read the blocks from")
+
+	return blockByTimeCmd
+}
+
+// findBlockByTime returns the header of the block of the database closest to the unix time t
+func findBlockByTime(dbPath string, t uint64) (*readable.BlockHeader, error) {
+	db, err := openDBReadOnly(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	b, err := visor.FindBlockByTime(wrapDB(db), t)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, errors.New("the database has no blocks")
+	}
+
+	header := readable.NewBlockHeader(b.Head)
+	return &header, nil
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindBlockByTime(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blockbytime")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	dbPath := filepath.Join(dir, "data.db")
+
+	_, err = findBlockByTime(dbPath, 1000)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not exist")
+
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{
+		Timeout: time.Second,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	_, err = findBlockByTime(dbPath, 1000)
+	require.EqualError(t, err, "the database has no blocks")
+}
@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/fiber"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func chainExportCmd() *cobra.Command {
+	chainExportCmd := &cobra.Command{
+		Short: "Export all blocks of the database to a file",
+		Use:   "chainExport",
+		Long: `Writes all blocks of the database to a file, from the genesis block to the head block.
+    Each block is written in binary format, prefixed with its length.
+    The file can be imported on another machine with "chainImport".
+    The node must be stopped while exporting its database. A pruned database cannot be exported.
+    If --db is not specified, the default data.db in $HOME/.$COIN/ will be exported.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, _ []string) error {
+			db, err := c.Flags().GetString("db")
+			if err != nil {
+				return err
+			}
+
+			output, err := c.Flags().GetString("output")
+			if err != nil {
+				return err
+			}
+			if output == "" {
+				return errors.New("--output is required")
+			}
+
+			dbPath, err := resolveDBPath(cliConfig, db)
+			if err != nil {
+				return err
+			}
+
+			return exportChain(dbPath, output)
+		},
+	}
+
+	chainExportCmd.Flags().String("db", "", "path of the database to export")
+	chainExportCmd.Flags().StringP("output", "o", "", "path of the export file")
+
+	return chainExportCmd
+}
+
+func chainImportCmd() *cobra.Command {
+	chainImportCmd := &cobra.Command{
+		Short: "Import blocks exported by chainExport into a new database",
+		Use:   "chainImport",
+		Long: `Creates a new database from a file written by "chainExport".
+    Each block is validated and its signature is verified before it is added, like a block received from a peer.
+    The database must not exist yet. If the import fails, the new database is removed.
+    The blocks are verified with the blockchain public key and coin distribution of the fiber config file
+    given by --fiber-config, or of the skycoin mainnet if --fiber-config is not specified.
+    If --db is not specified, the default data.db in $HOME/.$COIN/ will be created.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, _ []string) error {
+			db, err := c.Flags().GetString("db")
+			if err != nil {
+				return err
+			}
+
+			input, err := c.Flags().GetString("input")
+			if err != nil {
+				return err
+			}
+			if input == "" {
+				return errors.New("--input is required")
+			}
+
+			fiberConfig, err := c.Flags().GetString("fiber-config")
+			if err != nil {
+				return err
+			}
+
+			dbPath, err := resolveDBPath(cliConfig, db)
+			if err != nil {
+				return err
+			}
+
+			pubkey, dist, err := loadChainParams(fiberConfig)
+			if err != nil {
+				return err
+			}
+
+			return importChain(dbPath, input, pubkey, dist)
+		},
+	}
+
+	chainImportCmd.Flags().String("db", "", "path of the database to create")
+	chainImportCmd.Flags().StringP("input", "i", "", "path of the file written by chainExport")
+	chainImportCmd.Flags().String("fiber-config", "", "path of the fiber config file of the coin whose blocks are imported")
+
+	return chainImportCmd
+}
+
+func exportChain(dbPath, output string) error {
+	if _, err := os.Stat(output); err == nil {
+		return fmt.Errorf("%s already exists", output)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	db, err := openDBReadOnly(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+
+	n, err := visor.ExportBlocks(wrapDB(db), f, func(n uint64) {
+		fmt.Printf("exported %d blocks\n", n)
+	})
+	if err != nil {
+		f.Close()
+		os.Remove(output)
+		return fmt.Errorf("export failed: %v", err)
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(output)
+		return err
+	}
+
+	fmt.Printf("export success, %d blocks\n", n)
+	return nil
+}
+
+// loadChainParams returns the blockchain pubkey and distribution of the fiber config file at path,
+// or of the skycoin mainnet if path is empty
+func loadChainParams(path string) (cipher.PubKey, params.Distribution, error) {
+	if path == "" {
+		pubkey, err := cipher.PubKeyFromHex(blockchainPubkey)
+		if err != nil {
+			return cipher.PubKey{}, params.Distribution{}, fmt.Errorf("decode blockchain pubkey failed: %v", err)
+		}
+		return pubkey, params.MainNetDistribution, nil
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return cipher.PubKey{}, params.Distribution{}, err
+	}
+
+	cfg, err := fiber.NewConfig(filepath.Base(path), filepath.Dir(path))
+	if err != nil {
+		return cipher.PubKey{}, params.Distribution{}, fmt.Errorf("load fiber config failed: %v", err)
+	}
+
+	pubkey, err := cipher.PubKeyFromHex(cfg.Node.BlockchainPubkeyStr)
+	if err != nil {
+		return cipher.PubKey{}, params.Distribution{}, fmt.Errorf("decode blockchain pubkey failed: %v", err)
+	}
+
+	dist := params.Distribution{
+		MaxCoinSupply:        cfg.Params.MaxCoinSupply,
+		InitialUnlockedCount: cfg.Params.InitialUnlockedCount,
+		UnlockAddressRate:    cfg.Params.UnlockAddressRate,
+		UnlockTimeInterval:   cfg.Params.UnlockTimeInterval,
+		Addresses:            cfg.Params.DistributionAddresses,
+	}
+	if len(dist.Addresses) == 0 {
+		return cipher.PubKey{}, params.Distribution{}, errors.New("the fiber config has no distribution addresses")
+	}
+	if err := dist.Validate(); err != nil {
+		return cipher.PubKey{}, params.Distribution{}, fmt.Errorf("invalid distribution: %v", err)
+	}
+
+	return pubkey, dist, nil
+}
+
+func importChain(dbPath, input string, pubkey cipher.PubKey, dist params.Distribution) error {
+	if _, err := os.Stat(dbPath); err == nil {
+		return fmt.Errorf("db file: %v already exists, blocks can only be imported into a new database", dbPath)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	f, err := os.Open(input)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{
+		Timeout: 5 * time.Second,
+	})
+	if err != nil {
+		return fmt.Errorf("create db failed: %v", err)
+	}
+
+	n, err := importBlocks(db, f, pubkey, dist)
+	if err == nil && n == 0 {
+		err = errors.New("the input has no blocks")
+	}
+	if err != nil {
+		db.Close()
+		os.Remove(dbPath)
+		return fmt.Errorf("import failed: %v", err)
+	}
+
+	if err := db.Close(); err != nil {
+		os.Remove(dbPath)
+		return fmt.Errorf("close db failed: %v", err)
+	}
+
+	fmt.Printf("import success, %d blocks\n", n)
+	return nil
+}
+
+func importBlocks(db *bolt.DB, r io.Reader, pubkey cipher.PubKey, dist params.Distribution) (uint64, error) {
+	cfg := visor.NewConfig()
+	cfg.BlockchainPubkey = pubkey
+	cfg.Distribution = dist
+
+	wdb := wrapDB(db)
+	v, err := visor.New(cfg, wdb, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := v.ImportBlocks(r, func(n uint64) {
+		fmt.Printf("imported %d blocks\n", n)
+	})
+	if err != nil {
+		return n, err
+	}
+
+	// visor.New recorded a start, so without a shutdown reason the node reports an unclean shutdown on its first start
+	if err := visor.SetShutdownReason(wdb, "chainImport"); err != nil {
+		return n, fmt.Errorf("visor.SetShutdownReason failed: %v", err)
+	}
+
+	return n, nil
+}
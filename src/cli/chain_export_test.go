@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/params"
+)
+
+func TestExportImportChain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chainexport")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	pubkey, dist, err := loadChainParams("")
+	require.NoError(t, err)
+	require.Equal(t, params.MainNetDistribution, dist)
+
+	dbPath := filepath.Join(dir, "data.db")
+	output := filepath.Join(dir, "chain.bin")
+
+	err = exportChain(dbPath, output)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not exist")
+
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{
+		Timeout: time.Second,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	// An empty database exports an empty file
+	err = exportChain(dbPath, output)
+	require.NoError(t, err)
+	data, err := ioutil.ReadFile(output)
+	require.NoError(t, err)
+	require.Empty(t, data)
+
+	// The output file is not overwritten
+	err = exportChain(dbPath, output)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already exists")
+
+	// Blocks can't be imported into an existing database
+	err = importChain(dbPath, output, pubkey, dist)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already exists")
+
+	// The new database is removed if the import fails
+	newDBPath := filepath.Join(dir, "new.db")
+	err = importChain(newDBPath, output, pubkey, dist)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "the input has no blocks")
+	_, err = os.Stat(newDBPath)
+	require.True(t, os.IsNotExist(err))
+
+	err = ioutil.WriteFile(output, []byte{1, 2, 3}, 0600)
+	require.NoError(t, err)
+	err = importChain(newDBPath, output, pubkey, dist)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "truncated block frame length")
+	_, err = os.Stat(newDBPath)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestLoadChainParams(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chainparams")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, _, err = loadChainParams(filepath.Join(dir, "missing.fiber.toml"))
+	require.Error(t, err)
+	require.True(t, os.IsNotExist(err))
+
+	// The test fiber config has no distribution addresses
+	_, _, err = loadChainParams("../fiber/testdata/test.fiber.toml")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "the fiber config has no distribution addresses")
+
+	path := filepath.Join(dir, "coin.fiber.toml")
+	err = ioutil.WriteFile(path, []byte(`[node]
+blockchain_pubkey_str = "0328c576d3f420e7682058a981173a4b374c7cc5ff55bf394d3cf57059bbe6456a"
+
+[params]
+max_coin_supply = 2000000
+initial_unlocked_count = 1
+unlock_address_rate = 1
+unlock_time_interval = 3600
+distribution_addresses = [
+	"R6aHqKWSQfvpdo2fGSrq4F1RYXkBWR9HHJ",
+	"2EYM4WFHe4Dgz6kjAdUkM6Etep7ruz2ia6h",
+]
+`), 0600)
+	require.NoError(t, err)
+
+	pubkey, dist, err := loadChainParams(path)
+	require.NoError(t, err)
+	require.Equal(t, "0328c576d3f420e7682058a981173a4b374c7cc5ff55bf394d3cf57059bbe6456a", pubkey.Hex())
+	require.Equal(t, uint64(2000000), dist.MaxCoinSupply)
+	require.Equal(t, uint64(1), dist.InitialUnlockedCount)
+	require.Equal(t, uint64(1), dist.UnlockAddressRate)
+	require.Equal(t, uint64(3600), dist.UnlockTimeInterval)
+	require.Equal(t, []string{"R6aHqKWSQfvpdo2fGSrq4F1RYXkBWR9HHJ", "2EYM4WFHe4Dgz6kjAdUkM6Etep7ruz2ia6h"}, dist.Addresses)
+}
@@ -29,6 +29,27 @@ func wrapDB(db *bolt.DB) *dbutil.DB {
 	return wdb
 }
 
+// openDBReadOnly opens the database file at dbPath read-only.
+// The node holds an exclusive lock on the database while running, so opening it times out
+func openDBReadOnly(dbPath string) (*bolt.DB, error) {
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("db file: %v does not exist", dbPath)
+	}
+
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{
+		Timeout:  5 * time.Second,
+		ReadOnly: true,
+	})
+	if err != nil {
+		if err == bolt.ErrTimeout {
+			return nil, fmt.Errorf("open db failed: %v, make sure the node is stopped", err)
+		}
+		return nil, fmt.Errorf("open db failed: %v", err)
+	}
+
+	return db, nil
+}
+
 func checkDBCmd() *cobra.Command {
 	return &cobra.Command{
 		Short: "Verify the database",
@@ -54,17 +75,9 @@ func checkDB(_ *cobra.Command, args []string) error {
 	}
 
 	// check if this file exists
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-		return fmt.Errorf("db file: %v does not exist", dbPath)
-	}
-
-	db, err := bolt.Open(dbPath, 0600, &bolt.Options{
-		Timeout:  5 * time.Second,
-		ReadOnly: true,
-	})
-
+	db, err := openDBReadOnly(dbPath)
 	if err != nil {
-		return fmt.Errorf("open db failed: %v", err)
+		return err
 	}
 	pubkey, err := cipher.PubKeyFromHex(blockchainPubkey)
 	if err != nil {
@@ -75,7 +88,7 @@ func checkDB(_ *cobra.Command, args []string) error {
 		apputil.CatchInterrupt(quitChan)
 	}()
 
-	if err := visor.CheckDatabase(wrapDB(db), pubkey, quitChan); err != nil {
+	if err := visor.CheckDatabase(wrapDB(db), pubkey, nil, quitChan); err != nil {
 		if err == visor.ErrVerifyStopped {
 			return nil
 		}
@@ -111,17 +124,9 @@ func checkDBDecoding(_ *cobra.Command, args []string) error {
 	}
 
 	// check if this file exists
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-		return fmt.Errorf("db file: %v does not exist", dbPath)
-	}
-
-	db, err := bolt.Open(dbPath, 0600, &bolt.Options{
-		Timeout:  5 * time.Second,
-		ReadOnly: true,
-	})
-
+	db, err := openDBReadOnly(dbPath)
 	if err != nil {
-		return fmt.Errorf("open db failed: %v", err)
+		return err
 	}
 
 	go func() {
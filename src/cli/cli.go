@@ -181,13 +181,23 @@ func NewCLI(cfg Config) (*cobra.Command, error) {
 		addressGenCmd(),
 		fiberAddressGenCmd(),
 		addressOutputsCmd(),
+		benchmarkMempoolCmd(),
 		blocksCmd(),
+		blockByTimeCmd(),
 		broadcastTxCmd(),
 		checkDBCmd(),
 		checkDBEncodingCmd(),
+		dbCompactCmd(),
+		dbInfoCmd(),
+		chainExportCmd(),
+		chainImportCmd(),
 		createRawTxnCmd(),
 		createRawTxnV2Cmd(),
+		createCheckpointsCmd(),
 		signTxnCmd(),
+		offlineSignTxnCmd(),
+		offlineAddressBalanceCmd(),
+		peerListCmd(),
 		decodeRawTxnCmd(),
 		encodeJSONTxnCmd(),
 		decryptWalletCmd(),
@@ -207,6 +217,8 @@ func NewCLI(cfg Config) (*cobra.Command, error) {
 		walletAddAddressesCmd(),
 		walletScanAddressesCmd(),
 		walletKeyExportCmd(),
+		walletExportCmd(),
+		walletAuditCmd(),
 		walletBalanceCmd(),
 		walletHisCmd(),
 		walletOutputsCmd(),
@@ -215,6 +227,7 @@ func NewCLI(cfg Config) (*cobra.Command, error) {
 		pendingTransactionsCmd(),
 		addresscountCmd(),
 		distributeGenesisCmd(),
+		genesisGenCmd(),
 	}
 
 	skyCLI.Version = Version
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/util/apputil"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func createCheckpointsCmd() *cobra.Command {
+	createCheckpointsCmd := &cobra.Command{
+		Short:   "Create a signed checkpoint manifest of the database",
+		Use:     "createCheckpoints",
+		Aliases: []string{"create-checkpoints"},
+		Long: `Verifies the signatures of all blocks of the database against the public key of the blockchain secret key,
+    then prints a checkpoint manifest with the hash of every --interval blocks and of the head block, signed with the secret key.
+    A node started with the manifest as its -checkpoints-file only verifies the signatures of the blocks after
+    the last checkpoint when checking its database.
+    The secret key is read from --seckey, or from the environment variable named by --seckey-env.
+    The database is opened read-only, the node must be stopped.
+    If --db is not specified, the default data.db in $HOME/.$COIN/ will be read.
+
+    Use caution when using --seckey, the secret key can be recovered from the command history.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, _ []string) error {
+			db, err := c.Flags().GetString("db")
+			if err != nil {
+				return err
+			}
+
+			interval, err := c.Flags().GetUint64("interval")
+			if err != nil {
+				return err
+			}
+
+			seckey, err := genesisGenSecKey(c)
+			if err != nil {
+				return err
+			}
+
+			dbPath, err := resolveDBPath(cliConfig, db)
+			if err != nil {
+				return err
+			}
+
+			go func() {
+				apputil.CatchInterrupt(quitChan)
+			}()
+
+			m, err := createCheckpointManifest(dbPath, seckey, interval, quitChan)
+			if err != nil {
+				if err == visor.ErrVerifyStopped {
+					return nil
+				}
+				return err
+			}
+
+			return printJSON(m)
+		},
+	}
+
+	createCheckpointsCmd.Flags().String("db", "", "path of the database to read")
+	createCheckpointsCmd.Flags().Uint64("interval", 1000, "number of blocks between checkpoints")
+	createCheckpointsCmd.Flags().String("seckey", "", "blockchain secret key, hex encoded")
+	createCheckpointsCmd.Flags().String("seckey-env", "", "name of the environment variable that holds the hex encoded blockchain secret key")
+
+	return createCheckpointsCmd
+}
+
+// createCheckpointManifest verifies the database against the public key of seckey and returns a checkpoint manifest
+// of its blocks, signed with seckey
+func createCheckpointManifest(dbPath string, seckey cipher.SecKey, interval uint64, quit chan struct{}) (*visor.CheckpointManifest, error) {
+	pubkey, err := cipher.PubKeyFromSecKey(seckey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seckey: %v", err)
+	}
+
+	db, err := openDBReadOnly(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	wdb := wrapDB(db)
+
+	// Only blocks signed by the blockchain secret key are checkpointed
+	if err := visor.CheckDatabase(wdb, pubkey, nil, quit); err != nil {
+		if err == visor.ErrVerifyStopped {
+			return nil, err
+		}
+		return nil, fmt.Errorf("checkdb failed: %v", err)
+	}
+
+	cps, err := visor.CreateCheckpoints(wdb, interval)
+	if err != nil {
+		return nil, err
+	}
+	if len(cps) == 0 {
+		return nil, errors.New("database has no blocks")
+	}
+
+	return visor.NewCheckpointManifest(cps, seckey)
+}
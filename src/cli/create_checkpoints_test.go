@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+)
+
+func TestCreateCheckpointManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoints")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	dbPath := filepath.Join(dir, "data.db")
+	pubkey, seckey := cipher.GenerateKeyPair()
+	_, otherSeckey := cipher.GenerateKeyPair()
+
+	_, err = createCheckpointManifest(dbPath, seckey, 10, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not exist")
+
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{
+		Timeout: time.Second,
+	})
+	require.NoError(t, err)
+
+	wdb := wrapDB(db)
+	require.NoError(t, visor.CreateBuckets(wdb))
+
+	bc, err := visor.NewBlockchain(wdb, visor.BlockchainConfig{Pubkey: pubkey})
+	require.NoError(t, err)
+
+	gb, err := coin.NewGenesisBlock(testutil.MakeAddress(), 100e12, 1e9)
+	require.NoError(t, err)
+
+	err = wdb.Update("", func(tx *dbutil.Tx) error {
+		if err := bc.ExecuteBlock(tx, &coin.SignedBlock{
+			Block: *gb,
+			Sig:   cipher.MustSignHash(gb.HashHeader(), seckey),
+		}); err != nil {
+			return err
+		}
+		return historydb.New().ParseBlock(tx, *gb)
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	// The blocks are not signed by the secret key
+	_, err = createCheckpointManifest(dbPath, otherSeckey, 10, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "checkdb failed")
+
+	_, err = createCheckpointManifest(dbPath, seckey, 0, nil)
+	require.Error(t, err)
+
+	m, err := createCheckpointManifest(dbPath, seckey, 10, nil)
+	require.NoError(t, err)
+	require.Equal(t, []visor.Checkpoint{
+		{
+			Seq:  0,
+			Hash: gb.HashHeader().Hex(),
+		},
+	}, m.Checkpoints)
+
+	checkpoints, err := m.Verify(pubkey)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), checkpoints.LastSeq())
+}
@@ -156,6 +156,7 @@ func createRawTxnV2Cmd() *cobra.Command {
 	createRawTxnCmd.Flags().StringP("hours-selection-type", "", transaction.HoursSelectionTypeAuto, "Hours selection type")
 	createRawTxnCmd.Flags().StringP("hours-selection-mode", "", transaction.HoursSelectionModeShare, "Hours selection mode")
 	createRawTxnCmd.Flags().StringP("hours-selection-share-factor", "", "0.5", "Hour selection share factor")
+	createRawTxnCmd.Flags().Bool("sort-tx", false, "Sort the inputs and outputs into a canonical order, hiding which output is the change output")
 
 	return createRawTxnCmd
 }
@@ -247,12 +248,18 @@ func makeCreateTransactionRequest(c *cobra.Command, args []string, fromAddrs []s
 		return nil, err
 	}
 
+	sortTx, err := c.Flags().GetBool("sort-tx")
+	if err != nil {
+		return nil, err
+	}
+
 	return &api.CreateTransactionRequest{
 		IgnoreUnconfirmed: iu,
 		HoursSelection:    *hoursSelection,
 		ChangeAddress:     changeAddr,
 		Addresses:         fromAddrs,
 		To:                to,
+		SortTx:            sortTx,
 	}, nil
 }
 
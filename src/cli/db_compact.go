@@ -0,0 +1,257 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/spf13/cobra"
+)
+
+// compactTxMaxSize is the approximate number of bytes copied in a single write transaction when compacting
+const compactTxMaxSize = 64 * 1024 * 1024
+
+func dbCompactCmd() *cobra.Command {
+	dbCompactCmd := &cobra.Command{
+		Short: "Compact the database",
+		Use:   "dbCompact",
+		Long: `Copies all data of the database to a new file, verifies the copy and replaces the
+    original database with it. This reclaims the space of deleted records, e.g. after pruning blocks.
+    The node must be stopped while compacting its database.
+    If --db is not specified, the default data.db in $HOME/.$COIN/ will be compacted.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, _ []string) error {
+			db, err := c.Flags().GetString("db")
+			if err != nil {
+				return err
+			}
+
+			dbPath, err := resolveDBPath(cliConfig, db)
+			if err != nil {
+				return err
+			}
+
+			return compactDB(dbPath)
+		},
+	}
+
+	dbCompactCmd.Flags().String("db", "", "path of the database to compact")
+
+	return dbCompactCmd
+}
+
+func compactDB(dbPath string) error {
+	srcInfo, err := os.Stat(dbPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("db file: %v does not exist", dbPath)
+		}
+		return err
+	}
+
+	src, err := openDBReadOnly(dbPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dstPath := dbPath + ".compact"
+	if _, err := os.Stat(dstPath); err == nil {
+		return fmt.Errorf("%s already exists, remove it and try again", dstPath)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	dst, err := bolt.Open(dstPath, srcInfo.Mode(), &bolt.Options{
+		Timeout: 5 * time.Second,
+	})
+	if err != nil {
+		return fmt.Errorf("create compacted db failed: %v", err)
+	}
+
+	if err := copyDB(dst, src, compactTxMaxSize); err != nil {
+		dst.Close()
+		os.Remove(dstPath)
+		return fmt.Errorf("copy db failed: %v", err)
+	}
+
+	if err := verifyDBCopy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(dstPath)
+		return fmt.Errorf("verify compacted db failed: %v", err)
+	}
+
+	if err := dst.Close(); err != nil {
+		os.Remove(dstPath)
+		return fmt.Errorf("close compacted db failed: %v", err)
+	}
+
+	if err := src.Close(); err != nil {
+		os.Remove(dstPath)
+		return fmt.Errorf("close db failed: %v", err)
+	}
+
+	dstInfo, err := os.Stat(dstPath)
+	if err != nil {
+		os.Remove(dstPath)
+		return err
+	}
+
+	if err := os.Rename(dstPath, dbPath); err != nil {
+		os.Remove(dstPath)
+		return fmt.Errorf("replace db failed: %v", err)
+	}
+
+	fmt.Printf("compact db success, %d bytes -> %d bytes\n", srcInfo.Size(), dstInfo.Size())
+	return nil
+}
+
+// copyDB copies all buckets of src to dst. The copy is written in multiple transactions
+// of approximately txMaxSize bytes, so that large databases don't have to fit in memory.
+func copyDB(dst, src *bolt.DB, txMaxSize int) error {
+	tx, err := dst.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// tx is replaced when committed, roll back the last transaction if it was not committed
+		tx.Rollback() //nolint:errcheck
+	}()
+
+	var size int
+
+	// path is the list of bucket names leading to the bucket of the key, the last element is the key
+	var walk func(b *bolt.Bucket, path [][]byte) error
+	walk = func(b *bolt.Bucket, path [][]byte) error {
+		return b.ForEach(func(k, v []byte) error {
+			keyPath := append(path[:len(path):len(path)], k)
+
+			if size+len(k)+len(v) > txMaxSize {
+				if err := tx.Commit(); err != nil {
+					return err
+				}
+
+				var err error
+				tx, err = dst.Begin(true)
+				if err != nil {
+					return err
+				}
+
+				size = 0
+			}
+			size += len(k) + len(v)
+
+			dstBkt, err := dstBucket(tx, keyPath[:len(keyPath)-1])
+			if err != nil {
+				return err
+			}
+
+			// A nil value is a nested bucket
+			if v == nil {
+				child := b.Bucket(k)
+				nb, err := dstBkt.CreateBucketIfNotExists(k)
+				if err != nil {
+					return err
+				}
+				if err := nb.SetSequence(child.Sequence()); err != nil {
+					return err
+				}
+
+				return walk(child, keyPath)
+			}
+
+			return dstBkt.Put(k, v)
+		})
+	}
+
+	if err := wrapDB(src).ForEachBucket(func(name string, b *bolt.Bucket) error {
+		nb, err := tx.CreateBucketIfNotExists([]byte(name))
+		if err != nil {
+			return err
+		}
+
+		if err := nb.SetSequence(b.Sequence()); err != nil {
+			return err
+		}
+
+		return walk(b, [][]byte{[]byte(name)})
+	}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// dstBucket returns the bucket at path, which must exist
+func dstBucket(tx *bolt.Tx, path [][]byte) (*bolt.Bucket, error) {
+	b := tx.Bucket(path[0])
+	for _, name := range path[1:] {
+		if b == nil {
+			break
+		}
+		b = b.Bucket(name)
+	}
+
+	if b == nil {
+		return nil, fmt.Errorf("bucket %q not found", bytes.Join(path, []byte("/")))
+	}
+
+	return b, nil
+}
+
+// verifyDBCopy checks that dst has the same buckets as src with the same number of records in each bucket
+func verifyDBCopy(dst, src *bolt.DB) error {
+	srcCounts, err := bucketRecordCounts(src)
+	if err != nil {
+		return err
+	}
+
+	dstCounts, err := bucketRecordCounts(dst)
+	if err != nil {
+		return err
+	}
+
+	if len(srcCounts) != len(dstCounts) {
+		return fmt.Errorf("bucket count mismatch, %d != %d", len(srcCounts), len(dstCounts))
+	}
+
+	for name, n := range srcCounts {
+		m, ok := dstCounts[name]
+		if !ok {
+			return fmt.Errorf("bucket %q is missing", name)
+		}
+
+		if n != m {
+			return fmt.Errorf("bucket %q record count mismatch, %d != %d", name, n, m)
+		}
+	}
+
+	return nil
+}
+
+// bucketRecordCounts returns the number of records in each bucket, including nested buckets.
+// Nested buckets are named by their path, joined with "/".
+func bucketRecordCounts(db *bolt.DB) (map[string]int, error) {
+	counts := make(map[string]int)
+
+	var count func(name string, b *bolt.Bucket) error
+	count = func(name string, b *bolt.Bucket) error {
+		counts[name] = 0
+		return b.ForEach(func(k, v []byte) error {
+			counts[name]++
+			if v == nil {
+				return count(name+"/"+string(k), b.Bucket(k))
+			}
+			return nil
+		})
+	}
+
+	if err := wrapDB(db).ForEachBucket(count); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
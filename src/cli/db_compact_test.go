@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactDB(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbcompact")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	dbPath := filepath.Join(dir, "data.db")
+
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{
+		Timeout: time.Second,
+	})
+	require.NoError(t, err)
+
+	value := make([]byte, 1024)
+	err = db.Update(func(tx *bolt.Tx) error {
+		a, err := tx.CreateBucket([]byte("a"))
+		require.NoError(t, err)
+		for i := 0; i < 1000; i++ {
+			require.NoError(t, a.Put([]byte(fmt.Sprintf("key%04d", i)), value))
+		}
+		require.NoError(t, a.SetSequence(1000))
+
+		nested, err := a.CreateBucket([]byte("nested"))
+		require.NoError(t, err)
+		require.NoError(t, nested.Put([]byte("foo"), []byte("bar")))
+
+		_, err = tx.CreateBucket([]byte("empty"))
+		require.NoError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Delete most records so that the file has free pages to reclaim
+	err = db.Update(func(tx *bolt.Tx) error {
+		a := tx.Bucket([]byte("a"))
+		for i := 10; i < 1000; i++ {
+			require.NoError(t, a.Delete([]byte(fmt.Sprintf("key%04d", i))))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The database can't be compacted while it is open
+	compactErr := make(chan error, 1)
+	go func() {
+		compactErr <- compactDB(dbPath)
+	}()
+	select {
+	case err := <-compactErr:
+		require.Error(t, err)
+	case <-time.After(time.Second * 10):
+		t.Fatal("compactDB should time out")
+	}
+
+	require.NoError(t, db.Close())
+
+	before, err := os.Stat(dbPath)
+	require.NoError(t, err)
+
+	require.NoError(t, compactDB(dbPath))
+
+	after, err := os.Stat(dbPath)
+	require.NoError(t, err)
+	require.True(t, after.Size() < before.Size())
+
+	_, err = os.Stat(dbPath + ".compact")
+	require.True(t, os.IsNotExist(err))
+
+	db, err = bolt.Open(dbPath, 0600, &bolt.Options{
+		Timeout:  time.Second,
+		ReadOnly: true,
+	})
+	require.NoError(t, err)
+	defer db.Close()
+
+	counts, err := bucketRecordCounts(db)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{
+		"a":        11,
+		"a/nested": 1,
+		"empty":    0,
+	}, counts)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		a := tx.Bucket([]byte("a"))
+		require.Equal(t, uint64(1000), a.Sequence())
+		require.Equal(t, value, a.Get([]byte("key0009")))
+		require.Equal(t, []byte("bar"), a.Bucket([]byte("nested")).Get([]byte("foo")))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestCopyDBMultipleTransactions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbcompact")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	src, err := bolt.Open(filepath.Join(dir, "src.db"), 0600, nil)
+	require.NoError(t, err)
+	defer src.Close()
+
+	dst, err := bolt.Open(filepath.Join(dir, "dst.db"), 0600, nil)
+	require.NoError(t, err)
+	defer dst.Close()
+
+	err = src.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucket([]byte("a"))
+		require.NoError(t, err)
+		nested, err := b.CreateBucket([]byte("nested"))
+		require.NoError(t, err)
+		for i := 0; i < 100; i++ {
+			require.NoError(t, b.Put([]byte(fmt.Sprintf("key%04d", i)), []byte("value")))
+			require.NoError(t, nested.Put([]byte(fmt.Sprintf("key%04d", i)), []byte("value")))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Commit after every few records
+	require.NoError(t, copyDB(dst, src, 32))
+	require.NoError(t, verifyDBCopy(dst, src))
+
+	err = src.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte("a")).Delete([]byte("key0000"))
+	})
+	require.NoError(t, err)
+	require.Equal(t, `bucket "a" record count mismatch, 100 != 101`, verifyDBCopy(dst, src).Error())
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/boltdb/bolt"
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// DBInfo is the metadata of a database
+type DBInfo struct {
+	// Version is the stored database version, empty if the database has no version
+	Version  string         `json:"version"`
+	Blocks   uint64         `json:"blocks"`
+	Unspents uint64         `json:"unspents"`
+	Buckets  []DBInfoBucket `json:"buckets"`
+}
+
+// DBInfoBucket is a bucket of a database
+type DBInfoBucket struct {
+	Name string `json:"name"`
+	// Keys is the number of keys of the bucket, including the keys of nested buckets
+	Keys int `json:"keys"`
+	// Size is the number of bytes used by the bucket's pages
+	Size int `json:"size"`
+}
+
+func dbInfoCmd() *cobra.Command {
+	dbInfoCmd := &cobra.Command{
+		Short:   "Show the metadata of the database",
+		Use:     "dbInfo",
+		Aliases: []string{"db-info"},
+		Long: `Prints the stored database version, the number of blocks and unspent outputs,
+    and the number of keys and the size in bytes of each bucket.
+    The database is opened read-only, the node must be stopped.
+    If --db is not specified, the default data.db in $HOME/.$COIN/ will be read.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, _ []string) error {
+			db, err := c.Flags().GetString("db")
+			if err != nil {
+				return err
+			}
+
+			dbPath, err := resolveDBPath(cliConfig, db)
+			if err != nil {
+				return err
+			}
+
+			info, err := dbInfo(dbPath)
+			if err != nil {
+				return err
+			}
+
+			return printDBInfo(os.Stdout, info)
+		},
+	}
+
+	dbInfoCmd.Flags().String("db", "", "path of the database to read")
+
+	return dbInfoCmd
+}
+
+func dbInfo(dbPath string) (*DBInfo, error) {
+	db, err := openDBReadOnly(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	wdb := wrapDB(db)
+
+	var info DBInfo
+
+	v, err := visor.GetDBVersion(wdb)
+	if err != nil {
+		return nil, err
+	}
+	if v != nil {
+		info.Version = v.String()
+	}
+
+	// The blockchain pubkey is not used to read the blocks
+	bc, err := visor.NewBlockchain(wdb, visor.BlockchainConfig{})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := wdb.View("dbInfo", func(tx *dbutil.Tx) error {
+		if dbutil.Exists(tx, blockdb.BlockchainMetaBkt) {
+			headSeq, ok, err := bc.HeadSeq(tx)
+			if err != nil {
+				return err
+			}
+			if ok {
+				info.Blocks = headSeq + 1
+			}
+		}
+
+		if dbutil.Exists(tx, blockdb.UnspentPoolBkt) {
+			n, err := bc.Unspent().Len(tx)
+			if err != nil {
+				return err
+			}
+			info.Unspents = n
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := wdb.ForEachBucket(func(name string, b *bolt.Bucket) error {
+		stats := b.Stats()
+		info.Buckets = append(info.Buckets, DBInfoBucket{
+			Name: name,
+			Keys: stats.KeyN,
+			Size: stats.BranchInuse + stats.LeafInuse + stats.InlineBucketInuse,
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+func printDBInfo(out io.Writer, info *DBInfo) error {
+	version := info.Version
+	if version == "" {
+		version = "-"
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "DB VERSION\t%s\n", version)
+	fmt.Fprintf(w, "BLOCKS\t%d\n", info.Blocks)
+	fmt.Fprintf(w, "UNSPENTS\t%d\n", info.Unspents)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out)
+
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BUCKET\tKEYS\tSIZE (BYTES)")
+	for _, b := range info.Buckets {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", b.Name, b.Keys, b.Size)
+	}
+	return w.Flush()
+}
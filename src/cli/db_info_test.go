@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestDBInfo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbinfo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	dbPath := filepath.Join(dir, "data.db")
+
+	_, err = dbInfo(dbPath)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not exist")
+
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{
+		Timeout: time.Second,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	// An empty database has no version, blocks or buckets
+	info, err := dbInfo(dbPath)
+	require.NoError(t, err)
+	require.Equal(t, &DBInfo{}, info)
+
+	db, err = bolt.Open(dbPath, 0600, &bolt.Options{
+		Timeout: time.Second,
+	})
+	require.NoError(t, err)
+
+	wdb := wrapDB(db)
+	require.NoError(t, visor.CreateBuckets(wdb))
+	require.NoError(t, visor.SetDBVersion(wdb, semver.MustParse("0.25.0")))
+
+	pubkey, seckey := cipher.GenerateKeyPair()
+	bc, err := visor.NewBlockchain(wdb, visor.BlockchainConfig{Pubkey: pubkey})
+	require.NoError(t, err)
+
+	gb, err := coin.NewGenesisBlock(testutil.MakeAddress(), 100e12, 1e9)
+	require.NoError(t, err)
+
+	err = wdb.Update("", func(tx *dbutil.Tx) error {
+		return bc.ExecuteBlock(tx, &coin.SignedBlock{
+			Block: *gb,
+			Sig:   cipher.MustSignHash(gb.HashHeader(), seckey),
+		})
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	info, err = dbInfo(dbPath)
+	require.NoError(t, err)
+	require.Equal(t, "0.25.0", info.Version)
+	require.Equal(t, uint64(1), info.Blocks)
+	require.Equal(t, uint64(1), info.Unspents)
+
+	buckets := make(map[string]DBInfoBucket, len(info.Buckets))
+	for _, b := range info.Buckets {
+		buckets[b.Name] = b
+	}
+	require.Equal(t, 1, buckets[string(blockdb.BlocksBkt)].Keys)
+	require.NotZero(t, buckets[string(blockdb.BlocksBkt)].Size)
+	require.Equal(t, 1, buckets[string(blockdb.UnspentPoolBkt)].Keys)
+	require.Equal(t, 0, buckets[string(visor.UnconfirmedTxnsBkt)].Keys)
+}
+
+func TestPrintDBInfo(t *testing.T) {
+	var buf bytes.Buffer
+	err := printDBInfo(&buf, &DBInfo{
+		Blocks:   10,
+		Unspents: 3,
+		Buckets: []DBInfoBucket{
+			{Name: "blocks", Keys: 10, Size: 4096},
+			{Name: "unconfirmed_txns", Keys: 0, Size: 0},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `DB VERSION  -
+BLOCKS      10
+UNSPENTS    3
+
+BUCKET            KEYS  SIZE (BYTES)
+blocks            10    4096
+unconfirmed_txns  0     0
+`, buf.String())
+}
@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func genesisGenCmd() *cobra.Command {
+	genesisGenCmd := &cobra.Command{
+		Short: "Generate a signed genesis block for a new blockchain",
+		Use:   "genesisGen",
+		Long: `Creates a genesis block that sends --coins and --coin-hours to the genesis address,
+    signs it with the blockchain secret key and prints the binary encoded signed block as hex.
+    The secret key is read from --seckey, or from the environment variable named by --seckey-env,
+    and must be the secret key of --pubkey, the blockchain public key.
+    If --address is not specified, the genesis address is the address of --pubkey.
+    If --coin-hours is not specified, it is the number of droplets of --coins, like the genesis block
+    created by the node from its genesis address and coin volume. If it is different, the node must be
+    started with a -genesis-outputs-file that has the genesis address, coins and coin hours as its only output.
+
+    Use caution when using --seckey, the secret key can be recovered from the command history.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, _ []string) error {
+			pubkeyStr, err := c.Flags().GetString("pubkey")
+			if err != nil {
+				return err
+			}
+			if pubkeyStr == "" {
+				return errors.New("pubkey is required")
+			}
+			pubkey, err := cipher.PubKeyFromHex(pubkeyStr)
+			if err != nil {
+				return fmt.Errorf("invalid pubkey: %v", err)
+			}
+
+			seckey, err := genesisGenSecKey(c)
+			if err != nil {
+				return err
+			}
+
+			addr := cipher.AddressFromPubKey(pubkey)
+			addrStr, err := c.Flags().GetString("address")
+			if err != nil {
+				return err
+			}
+			if addrStr != "" {
+				addr, err = cipher.DecodeBase58Address(addrStr)
+				if err != nil {
+					return fmt.Errorf("invalid address: %v", err)
+				}
+			}
+
+			coinsStr, err := c.Flags().GetString("coins")
+			if err != nil {
+				return err
+			}
+			if coinsStr == "" {
+				return errors.New("coins is required")
+			}
+			coins, err := droplet.FromString(coinsStr)
+			if err != nil {
+				return fmt.Errorf("invalid coins: %v", err)
+			}
+
+			hours := coins
+			if c.Flags().Changed("coin-hours") {
+				hours, err = c.Flags().GetUint64("coin-hours")
+				if err != nil {
+					return err
+				}
+			}
+
+			timestamp, err := c.Flags().GetUint64("timestamp")
+			if err != nil {
+				return err
+			}
+			if timestamp == 0 {
+				return errors.New("timestamp is required")
+			}
+
+			verify, err := c.Flags().GetBool("verify")
+			if err != nil {
+				return err
+			}
+
+			b, err := createGenesisBlock(pubkey, seckey, addr, coins, hours, timestamp)
+			if err != nil {
+				return err
+			}
+
+			rawBlock := hex.EncodeToString(encoder.Serialize(*b))
+
+			if verify {
+				if _, err := verifyGenesisBlock(rawBlock, pubkey); err != nil {
+					return fmt.Errorf("verify genesis block failed: %v", err)
+				}
+			}
+
+			fmt.Println(rawBlock)
+			return nil
+		},
+	}
+
+	genesisGenCmd.Flags().String("pubkey", "", "blockchain public key, hex encoded")
+	genesisGenCmd.Flags().String("seckey", "", "blockchain secret key, hex encoded")
+	genesisGenCmd.Flags().String("seckey-env", "", "name of the environment variable that holds the hex encoded blockchain secret key")
+	genesisGenCmd.Flags().String("address", "", "genesis address, defaults to the address of the pubkey")
+	genesisGenCmd.Flags().String("coins", "", "number of coins created by the genesis block")
+	genesisGenCmd.Flags().Uint64("coin-hours", 0, "number of coin hours created by the genesis block, defaults to the number of droplets of coins")
+	genesisGenCmd.Flags().Uint64("timestamp", 0, "genesis block timestamp, in unix seconds")
+	genesisGenCmd.Flags().Bool("verify", false, "check that the printed block can be decoded and is a valid genesis block signed by the pubkey")
+
+	return genesisGenCmd
+}
+
+// genesisGenSecKey reads the secret key from --seckey or from the environment variable named by --seckey-env
+func genesisGenSecKey(c *cobra.Command) (cipher.SecKey, error) {
+	seckeyStr, err := c.Flags().GetString("seckey")
+	if err != nil {
+		return cipher.SecKey{}, err
+	}
+
+	seckeyEnv, err := c.Flags().GetString("seckey-env")
+	if err != nil {
+		return cipher.SecKey{}, err
+	}
+
+	switch {
+	case seckeyStr != "" && seckeyEnv != "":
+		return cipher.SecKey{}, errors.New("seckey and seckey-env must not be used together")
+	case seckeyEnv != "":
+		seckeyStr = os.Getenv(seckeyEnv)
+		if seckeyStr == "" {
+			return cipher.SecKey{}, fmt.Errorf("environment variable %s is not set", seckeyEnv)
+		}
+	case seckeyStr == "":
+		return cipher.SecKey{}, errors.New("seckey or seckey-env is required")
+	}
+
+	seckey, err := cipher.SecKeyFromHex(seckeyStr)
+	if err != nil {
+		return cipher.SecKey{}, fmt.Errorf("invalid seckey: %v", err)
+	}
+
+	return seckey, nil
+}
+
+// createGenesisBlock creates a genesis block that sends coins and hours to addr and signs it with seckey,
+// which must be the secret key of pubkey.
+// If hours is the same as coins, the block is the same as the genesis block created by coin.NewGenesisBlock,
+// otherwise it is the genesis block of a genesis outputs file with the single output.
+func createGenesisBlock(pubkey cipher.PubKey, seckey cipher.SecKey, addr cipher.Address, coins, hours, timestamp uint64) (*coin.SignedBlock, error) {
+	p, err := cipher.PubKeyFromSecKey(seckey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seckey: %v", err)
+	}
+	if p != pubkey {
+		return nil, errors.New("seckey is not the secret key of pubkey")
+	}
+
+	if coins == 0 {
+		return nil, errors.New("coins must be > 0")
+	}
+
+	var b *coin.Block
+	if hours == coins {
+		b, err = coin.NewGenesisBlock(addr, coins, timestamp)
+	} else {
+		var outputs *visor.GenesisOutputs
+		outputs, err = visor.NewGenesisOutputs([]visor.GenesisOutput{
+			{
+				Address:   addr.String(),
+				Coins:     coins,
+				CoinHours: hours,
+			},
+		})
+		if err == nil {
+			b, err = outputs.NewGenesisBlock(timestamp)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := cipher.SignHash(b.HashHeader(), seckey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &coin.SignedBlock{
+		Block: *b,
+		Sig:   sig,
+	}, nil
+}
+
+// verifyGenesisBlock decodes a hex encoded signed block created by createGenesisBlock
+// and checks that it is a genesis block signed by pubkey
+func verifyGenesisBlock(rawBlock string, pubkey cipher.PubKey) (*coin.SignedBlock, error) {
+	buf, err := hex.DecodeString(rawBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	var b coin.SignedBlock
+	if err := encoder.DeserializeRawExact(buf, &b); err != nil {
+		return nil, fmt.Errorf("decode block failed: %v", err)
+	}
+
+	if err := b.VerifySignature(pubkey); err != nil {
+		return nil, err
+	}
+
+	if b.Head.BkSeq != 0 || !b.Head.PrevHash.Null() {
+		return nil, errors.New("block is not a genesis block")
+	}
+
+	if len(b.Body.Transactions) != 1 {
+		return nil, errors.New("genesis block should have only 1 transaction")
+	}
+
+	txn := b.Body.Transactions[0]
+	if len(txn.In) != 0 {
+		return nil, errors.New("genesis block transaction should not have inputs")
+	}
+	if len(txn.Out) != 1 {
+		return nil, errors.New("genesis block transaction should have only 1 output")
+	}
+
+	if b.Head.BodyHash != b.Body.Hash() {
+		return nil, errors.New("genesis block body hash does not match its header")
+	}
+
+	return &b, nil
+}
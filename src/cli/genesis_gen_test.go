@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func TestCreateGenesisBlock(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	addr := testutil.MakeAddress()
+	coins := uint64(100e12)
+	timestamp := uint64(1426562704)
+
+	b, err := createGenesisBlock(pubkey, seckey, addr, coins, coins, timestamp)
+	require.NoError(t, err)
+
+	// The block is the genesis block created by the node from its genesis address and coin volume
+	expected, err := coin.NewGenesisBlock(addr, coins, timestamp)
+	require.NoError(t, err)
+	require.Equal(t, *expected, b.Block)
+	require.NoError(t, b.VerifySignature(pubkey))
+
+	rawBlock := hex.EncodeToString(encoder.Serialize(*b))
+	decoded, err := verifyGenesisBlock(rawBlock, pubkey)
+	require.NoError(t, err)
+	require.Equal(t, b, decoded)
+
+	// The block is not signed by another pubkey
+	otherPubkey, otherSeckey := cipher.GenerateKeyPair()
+	_, err = verifyGenesisBlock(rawBlock, otherPubkey)
+	require.Error(t, err)
+
+	_, err = verifyGenesisBlock(rawBlock[:len(rawBlock)-2], pubkey)
+	require.Error(t, err)
+
+	// The seckey must be the secret key of the pubkey
+	_, err = createGenesisBlock(pubkey, otherSeckey, addr, coins, coins, timestamp)
+	require.EqualError(t, err, "seckey is not the secret key of pubkey")
+
+	_, err = createGenesisBlock(pubkey, seckey, addr, 0, 0, timestamp)
+	require.EqualError(t, err, "coins must be > 0")
+
+	// With different coin hours, the block is the genesis block of a genesis outputs file with the single output
+	b, err = createGenesisBlock(pubkey, seckey, addr, coins, 1000, timestamp)
+	require.NoError(t, err)
+
+	outputs, err := visor.NewGenesisOutputs([]visor.GenesisOutput{
+		{
+			Address:   addr.String(),
+			Coins:     coins,
+			CoinHours: 1000,
+		},
+	})
+	require.NoError(t, err)
+	expected, err = outputs.NewGenesisBlock(timestamp)
+	require.NoError(t, err)
+	require.Equal(t, *expected, b.Block)
+	require.Equal(t, uint64(1000), b.Body.Transactions[0].Out[0].Hours)
+
+	_, err = verifyGenesisBlock(hex.EncodeToString(encoder.Serialize(*b)), pubkey)
+	require.NoError(t, err)
+}
@@ -1678,6 +1678,9 @@ func TestStableStatus(t *testing.T) {
 	ret.Status.Uptime = wh.FromDuration(time.Duration(0))
 	// StartedAt is not stable
 	ret.Status.StartedAt = 0
+	// Restart info depends on how often the test node has been restarted
+	ret.Status.RestartCount = 0
+	ret.Status.LastRestartReason = ""
 	goldenFile := "status"
 	if useCSRF(t) {
 		goldenFile += "-csrf-enabled"
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/util/mathutil"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+// OfflineAddressBalance represents the confirmed balance of an address, read from a node's database
+type OfflineAddressBalance struct {
+	Address string `json:"address"`
+	Balance
+	Outputs int `json:"outputs"`
+	// Sequence of the head block, the coin hours are calculated at the time of this block
+	HeadSeq uint64 `json:"head_seq"`
+}
+
+func offlineAddressBalanceCmd() *cobra.Command {
+	offlineAddressBalanceCmd := &cobra.Command{
+		Short: "Check the balance of an address in the database of a stopped node",
+		Use:   "offlineAddressBalance",
+		Long: `Sums the confirmed unspent outputs of an address, reading them directly from the database.
+    The node does not need to be running, the database is opened read-only and must not be in use by a node.
+    Coin hours are calculated at the time of the head block.
+    If --db is not specified, the default data.db in $HOME/.$COIN/ will be read.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, _ []string) error {
+			db, err := c.Flags().GetString("db")
+			if err != nil {
+				return err
+			}
+
+			address, err := c.Flags().GetString("address")
+			if err != nil {
+				return err
+			}
+			if address == "" {
+				return errors.New("--address is required")
+			}
+
+			addr, err := cipher.DecodeBase58Address(address)
+			if err != nil {
+				return fmt.Errorf("invalid address: %v, err: %v", address, err)
+			}
+
+			dbPath, err := resolveDBPath(cliConfig, db)
+			if err != nil {
+				return err
+			}
+
+			bal, err := offlineAddressBalance(dbPath, addr)
+			if err != nil {
+				return err
+			}
+
+			return printJSON(bal)
+		},
+	}
+
+	offlineAddressBalanceCmd.Flags().String("db", "", "path of the database to read")
+	offlineAddressBalanceCmd.Flags().String("address", "", "address to check")
+
+	return offlineAddressBalanceCmd
+}
+
+func offlineAddressBalance(dbPath string, addr cipher.Address) (*OfflineAddressBalance, error) {
+	db, err := openDBReadOnly(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	addrUxs, head, err := visor.ReadUnspentsOfAddrs(wrapDB(db), []cipher.Address{addr})
+	if err != nil {
+		return nil, err
+	}
+	if head == nil {
+		return nil, errors.New("the database has no blocks")
+	}
+
+	uxs := addrUxs[addr]
+
+	var coins, hours uint64
+	for i := range uxs {
+		coins, err = mathutil.AddUint64(coins, uxs[i].Body.Coins)
+		if err != nil {
+			return nil, err
+		}
+
+		uxHours, err := uxs[i].CoinHours(head.Time())
+		if err != nil {
+			return nil, err
+		}
+
+		hours, err = mathutil.AddUint64(hours, uxHours)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	coinsStr, err := droplet.ToString(coins)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OfflineAddressBalance{
+		Address: addr.String(),
+		Balance: Balance{
+			Coins: coinsStr,
+			Hours: strconv.FormatUint(hours, 10),
+		},
+		Outputs: len(uxs),
+		HeadSeq: head.Seq(),
+	}, nil
+}
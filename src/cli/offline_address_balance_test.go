@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+func TestOfflineAddressBalance(t *testing.T) {
+	dir, err := ioutil.TempDir("", "offlinebalance")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	dbPath := filepath.Join(dir, "data.db")
+	addr := testutil.MakeAddress()
+
+	_, err = offlineAddressBalance(dbPath, addr)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not exist")
+
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{
+		Timeout: time.Second,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	_, err = offlineAddressBalance(dbPath, addr)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "the database has no blocks")
+}
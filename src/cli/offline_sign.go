@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func offlineSignTxnCmd() *cobra.Command {
+	offlineSignTxnCmd := &cobra.Command{
+		Short: "Sign an unsigned transaction with a local wallet file, without connecting to a node",
+		Use:   "offlineSignTransaction [wallet] [raw transaction]",
+		Long: `Sign an unsigned transaction with a local wallet file, without connecting to a node.
+    The signed transaction is printed as a hex string, which can be broadcast from
+    another machine with "skycoin-cli broadcastTransaction".
+
+    Use this command on a cold machine that has no network access. Create the
+    unsigned transaction on the hot machine with "skycoin-cli createRawTransactionV2 --unsign"
+    or the POST /api/v2/transaction API.
+
+    A raw transaction only refers to its inputs by hash, so the address that owns each
+    input must be given with --input-addresses, in the same order as the transaction inputs.
+    The addresses are listed in the "inputs" of the transaction created by the API.
+
+    Use caution when using the "-p" command. If you have command
+    history enabled your wallet encryption password can be recovered from the
+    history log. If you do not include the "-p" option you will be prompted to
+    enter your password after you enter your command.`,
+		SilenceUsage:          true,
+		Args:                  cobra.ExactArgs(2),
+		DisableFlagsInUseLine: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			walletFile := args[0]
+
+			txn, err := coin.DeserializeTransactionHex(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid raw transaction: %v", err)
+			}
+
+			addrsStr, err := c.Flags().GetString("input-addresses")
+			if err != nil {
+				return err
+			}
+
+			inputAddrs, err := parseInputAddresses(addrsStr)
+			if err != nil {
+				return err
+			}
+
+			password, err := c.Flags().GetString("password")
+			if err != nil {
+				return err
+			}
+			pr := NewPasswordReader([]byte(password))
+
+			signedTxn, err := OfflineSignTransaction(walletFile, &txn, inputAddrs, pr)
+			switch err.(type) {
+			case nil:
+			case WalletLoadError:
+				printHelp(c)
+				return err
+			default:
+				return err
+			}
+
+			rawTxn, err := signedTxn.SerializeHex()
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(rawTxn)
+
+			return nil
+		},
+	}
+
+	offlineSignTxnCmd.Flags().StringP("input-addresses", "a", "", "Comma separated addresses that own the transaction inputs, in input order")
+	offlineSignTxnCmd.Flags().StringP("password", "p", "", "Wallet password")
+
+	return offlineSignTxnCmd
+}
+
+func parseInputAddresses(s string) ([]cipher.Address, error) {
+	if s == "" {
+		return nil, errors.New("--input-addresses is required")
+	}
+
+	var addrs []cipher.Address
+	for _, a := range strings.Split(s, ",") {
+		addr, err := cipher.DecodeBase58Address(strings.TrimSpace(a))
+		if err != nil {
+			return nil, fmt.Errorf("invalid input address %q: %v", a, err)
+		}
+		addrs = append(addrs, addr)
+	}
+
+	return addrs, nil
+}
+
+// OfflineSignTransaction signs the unsigned inputs of a transaction with the keys of a wallet file.
+// inputAddrs are the addresses that own the transaction inputs, in input order.
+// No node is contacted, so the input addresses are not verified against the inputs.
+// If an address is wrong, the signature is invalid and the node rejects the transaction when it is broadcast.
+func OfflineSignTransaction(walletFile string, txn *coin.Transaction, inputAddrs []cipher.Address, pr PasswordReader) (*coin.Transaction, error) {
+	if len(inputAddrs) != len(txn.In) {
+		return nil, fmt.Errorf("transaction has %d inputs but %d input addresses were given", len(txn.In), len(inputAddrs))
+	}
+
+	wlt, err := wallet.Load(walletFile)
+	if err != nil {
+		return nil, WalletLoadError{err}
+	}
+
+	// SignTransaction only uses the owner address of the input UxOuts
+	uxOuts := make([]coin.UxOut, len(inputAddrs))
+	for i, addr := range inputAddrs {
+		uxOuts[i].Body.Address = addr
+	}
+
+	if !wlt.IsEncrypted() {
+		return wallet.SignTransaction(wlt, txn, nil, uxOuts)
+	}
+
+	if pr == nil {
+		return nil, wallet.ErrMissingPassword
+	}
+
+	password, err := pr.Password()
+	if err != nil {
+		return nil, err
+	}
+
+	var signedTxn *coin.Transaction
+	if err := wallet.GuardView(wlt, password, func(w wallet.Wallet) error {
+		var err error
+		signedTxn, err = wallet.SignTransaction(w, txn, nil, uxOuts)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return signedTxn, nil
+}
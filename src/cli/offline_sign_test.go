@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/wallet"
+	"github.com/skycoin/skycoin/src/wallet/crypto"
+	_ "github.com/skycoin/skycoin/src/wallet/deterministic"
+)
+
+func TestOfflineSignTransaction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "offline-sign")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	password := []byte("pwd")
+
+	newWallet := func(filename string, encrypt bool) wallet.Wallet {
+		opts := wallet.Options{
+			Type:      wallet.WalletTypeDeterministic,
+			Coin:      wallet.CoinTypeSkycoin,
+			Seed:      "offline sign test seed",
+			GenerateN: 2,
+		}
+		if encrypt {
+			opts.Encrypt = true
+			opts.Password = password
+			opts.CryptoType = crypto.CryptoTypeSha256Xor
+		}
+
+		w, err := wallet.NewWallet(filename, "test", opts.Seed, opts)
+		require.NoError(t, err)
+		require.NoError(t, wallet.Save(w, dir))
+		return w
+	}
+
+	w := newWallet("test.wlt", false)
+	newWallet("encrypted.wlt", true)
+
+	entries, err := w.GetEntries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// Spend one output owned by each wallet address
+	var uxIn coin.UxArray
+	for _, e := range entries {
+		uxIn = append(uxIn, coin.UxOut{
+			Body: coin.UxBody{
+				SrcTransaction: testutil.RandSHA256(t),
+				Address:        e.SkycoinAddress(),
+				Coins:          1e6,
+				Hours:          10,
+			},
+		})
+	}
+
+	var txn coin.Transaction
+	for _, ux := range uxIn {
+		require.NoError(t, txn.PushInput(ux.Hash()))
+	}
+	require.NoError(t, txn.PushOutput(testutil.MakeAddress(), 2e6, 10))
+	txn.Sigs = make([]cipher.Sig, len(txn.In))
+	require.NoError(t, txn.UpdateHeader())
+
+	inputAddrs := []cipher.Address{uxIn[0].Body.Address, uxIn[1].Body.Address}
+
+	cases := []struct {
+		name       string
+		walletFile string
+		inputAddrs []cipher.Address
+		pr         PasswordReader
+		err        string
+	}{
+		{
+			name:       "ok",
+			walletFile: "test.wlt",
+			inputAddrs: inputAddrs,
+		},
+		{
+			name:       "ok encrypted",
+			walletFile: "encrypted.wlt",
+			inputAddrs: inputAddrs,
+			pr:         PasswordFromBytes(password),
+		},
+		{
+			name:       "encrypted missing password",
+			walletFile: "encrypted.wlt",
+			inputAddrs: inputAddrs,
+			err:        wallet.ErrMissingPassword.Error(),
+		},
+		{
+			name:       "encrypted wrong password",
+			walletFile: "encrypted.wlt",
+			inputAddrs: inputAddrs,
+			pr:         PasswordFromBytes([]byte("wrong")),
+			err:        wallet.ErrInvalidPassword.Error(),
+		},
+		{
+			name:       "wrong number of input addresses",
+			walletFile: "test.wlt",
+			inputAddrs: inputAddrs[:1],
+			err:        "transaction has 2 inputs but 1 input addresses were given",
+		},
+		{
+			name:       "address not in wallet",
+			walletFile: "test.wlt",
+			inputAddrs: []cipher.Address{inputAddrs[0], testutil.MakeAddress()},
+			err:        "Wallet cannot sign all requested inputs",
+		},
+		{
+			name:       "wallet does not exist",
+			walletFile: "missing.wlt",
+			inputAddrs: inputAddrs,
+			err:        "Load wallet failed",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			signedTxn, err := OfflineSignTransaction(filepath.Join(dir, tc.walletFile), &txn, tc.inputAddrs, tc.pr)
+			if tc.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.True(t, signedTxn.IsFullySigned())
+			require.NoError(t, signedTxn.VerifyInputSignatures(uxIn))
+			require.Equal(t, txn.InnerHash, signedTxn.InnerHash)
+
+			// The unsigned transaction is not modified
+			require.False(t, txn.IsFullySigned())
+		})
+	}
+}
+
+func TestParseInputAddresses(t *testing.T) {
+	addr1 := testutil.MakeAddress()
+	addr2 := testutil.MakeAddress()
+
+	addrs, err := parseInputAddresses(addr1.String() + ", " + addr2.String())
+	require.NoError(t, err)
+	require.Equal(t, []cipher.Address{addr1, addr2}, addrs)
+
+	_, err = parseInputAddresses("")
+	require.Error(t, err)
+
+	_, err = parseInputAddresses(addr1.String() + ",foo")
+	require.Error(t, err)
+}
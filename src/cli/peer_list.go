@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/daemon/pex"
+)
+
+// PeerListPeer is a peer of a node's peers file
+type PeerListPeer struct {
+	Address         string `json:"address"`
+	Trusted         bool   `json:"trusted"`
+	HasIncomingPort bool   `json:"has_incoming_port"`
+	// Unix timestamp when the peer was last seen
+	LastSeen  int64  `json:"last_seen"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+func peerListCmd() *cobra.Command {
+	peerListCmd := &cobra.Command{
+		Short: "List the known peers of a stopped node",
+		Use:   "peerList",
+		Long: fmt.Sprintf(`Lists the peers saved by a node in its peers file, reading the file directly.
+    The node does not need to be running. A running node saves its peers when it starts and when it stops.
+    If --peers-file is not specified, the default %s in $HOME/.$COIN/ will be read.`, pex.PeerCacheFilename),
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, _ []string) error {
+			peersFile, err := c.Flags().GetString("peers-file")
+			if err != nil {
+				return err
+			}
+
+			jsonOutput, err := c.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+
+			path, err := resolveDBPath(cliConfig, peersFile)
+			if err != nil {
+				return err
+			}
+
+			peers, err := peerList(path)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(peers)
+			}
+
+			return printPeerList(os.Stdout, peers)
+		},
+	}
+
+	peerListCmd.Flags().String("peers-file", pex.PeerCacheFilename, "path of the peers file to read")
+	peerListCmd.Flags().Bool("json", false, "print the peers as JSON")
+
+	return peerListCmd
+}
+
+func peerList(path string) ([]PeerListPeer, error) {
+	peers, err := pex.LoadPeersFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("peers file: %v does not exist", path)
+		}
+		return nil, err
+	}
+
+	ps := make([]PeerListPeer, len(peers))
+	for i, p := range peers {
+		var userAgent string
+		if !p.UserAgent.Empty() {
+			userAgent, err = p.UserAgent.Build()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		ps[i] = PeerListPeer{
+			Address:         p.Addr,
+			Trusted:         p.Trusted,
+			HasIncomingPort: p.HasIncomingPort,
+			LastSeen:        p.LastSeen,
+			UserAgent:       userAgent,
+		}
+	}
+
+	return ps, nil
+}
+
+func printPeerList(out io.Writer, peers []PeerListPeer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ADDRESS\tTRUSTED\tINCOMING PORT\tLAST SEEN\tUSER AGENT")
+	for _, p := range peers {
+		lastSeen := time.Unix(p.LastSeen, 0).UTC().Format(time.RFC3339)
+		userAgent := p.UserAgent
+		if userAgent == "" {
+			userAgent = "-"
+		}
+		fmt.Fprintf(w, "%s\t%t\t%t\t%s\t%s\n", p.Address, p.Trusted, p.HasIncomingPort, lastSeen, userAgent)
+	}
+	return w.Flush()
+}
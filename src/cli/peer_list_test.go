@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "peerlist")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "peers.json")
+
+	_, err = peerList(path)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not exist")
+
+	err = ioutil.WriteFile(path, []byte(`{
+    "112.32.32.15:7200": {
+        "Addr": "112.32.32.15:7200",
+        "LastSeen": 1540000000,
+        "Private": false,
+        "Trusted": false,
+        "HasIncomingPort": true,
+        "UserAgent": "skycoin:0.25.0"
+    },
+    "112.32.32.14:7200": {
+        "Addr": "112.32.32.14:7200",
+        "LastSeen": 1540000060,
+        "Private": false,
+        "Trusted": true,
+        "HasIncomingPort": false,
+        "UserAgent": ""
+    }
+}`), 0600)
+	require.NoError(t, err)
+
+	peers, err := peerList(path)
+	require.NoError(t, err)
+	require.Equal(t, []PeerListPeer{
+		{
+			Address:  "112.32.32.14:7200",
+			Trusted:  true,
+			LastSeen: 1540000060,
+		},
+		{
+			Address:         "112.32.32.15:7200",
+			HasIncomingPort: true,
+			LastSeen:        1540000000,
+			UserAgent:       "skycoin:0.25.0",
+		},
+	}, peers)
+
+	var buf bytes.Buffer
+	err = printPeerList(&buf, peers)
+	require.NoError(t, err)
+	require.Equal(t, `ADDRESS            TRUSTED  INCOMING PORT  LAST SEEN             USER AGENT
+112.32.32.14:7200  true     false          2018-10-20T01:47:40Z  -
+112.32.32.15:7200  false    true           2018-10-20T01:46:40Z  skycoin:0.25.0
+`, buf.String())
+}
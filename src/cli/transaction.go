@@ -8,6 +8,8 @@ import (
 	"strconv"
 
 	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/util/mathutil"
+	"github.com/skycoin/skycoin/src/visor"
 	"github.com/skycoin/skycoin/src/wallet"
 
 	"github.com/skycoin/skycoin/src/api"
@@ -54,30 +56,222 @@ func transactionCmd() *cobra.Command {
 	}
 }
 
+// Signature statuses of an input of a DecodedRawTransaction
+const (
+	// SignatureUnsigned the input has a null signature
+	SignatureUnsigned = "unsigned"
+	// SignatureInvalid the signature is malformed, or does not match the owner of the spent output
+	SignatureInvalid = "invalid"
+	// SignatureValid the signature is well formed. The owner of the spent output is not known, so it is not checked
+	SignatureValid = "valid"
+	// SignatureVerified the signature matches the owner of the spent output
+	SignatureVerified = "verified"
+)
+
+// DecodedRawTransaction is a raw transaction decoded by decodeRawTransaction
+type DecodedRawTransaction struct {
+	readable.Transaction
+	// Error is why the transaction is not well formed, empty if it is
+	Error string `json:"error,omitempty"`
+	// Signatures are the signature statuses of the inputs, in input order
+	Signatures []RawTransactionSignature `json:"signature_status"`
+	// SpentOutputs are the outputs spent by the inputs, in input order, with null for outputs
+	// not in the database. Only set when the outputs are read from a database
+	SpentOutputs []*readable.TransactionInput `json:"spent_outputs,omitempty"`
+	// Fee is the coin hours burned by the transaction, nil if any of the spent outputs is unknown
+	Fee *uint64 `json:"fee,omitempty"`
+}
+
+// RawTransactionSignature is the signature status of an input of a DecodedRawTransaction
+type RawTransactionSignature struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
 func decodeRawTxnCmd() *cobra.Command {
-	return &cobra.Command{
-		Short:                 "Decode raw transaction",
-		Use:                   "decodeRawTransaction [raw transaction]",
-		DisableFlagsInUseLine: true,
-		SilenceUsage:          true,
-		Args:                  cobra.ExactArgs(1),
-		RunE: func(_ *cobra.Command, args []string) error {
+	decodeRawTxnCmd := &cobra.Command{
+		Short: "Decode raw transaction",
+		Use:   "decodeRawTransaction [raw transaction]",
+		Long: `Decodes a hex-encoded raw transaction and prints it with the status of its signatures.
+    No node is contacted, so whether the inputs are spendable is not checked.
+
+    Signatures are checked to be well formed. A raw transaction only refers to the outputs
+    it spends by hash, so the owners of the outputs, their coins and the transaction fee are
+    unknown unless --db is given. With --db, the spent outputs are read from the database
+    of a stopped node, and the signatures are verified against their owners.`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
 			txn, err := coin.DeserializeTransactionHex(args[0])
 			if err != nil {
 				return fmt.Errorf("invalid raw transaction: %v", err)
 			}
 
-			// Assume the transaction is not malformed and if it has no inputs
-			// that it is the genesis block's transaction
-			isGenesis := len(txn.In) == 0
-			rTxn, err := readable.NewTransaction(txn, isGenesis)
+			db, err := c.Flags().GetString("db")
+			if err != nil {
+				return err
+			}
+
+			var uxOuts []*coin.UxOut
+			var headTime uint64
+			if db != "" {
+				dbPath, err := resolveDBPath(cliConfig, db)
+				if err != nil {
+					return err
+				}
+
+				uxOuts, headTime, err = readTransactionInputs(dbPath, &txn)
+				if err != nil {
+					return err
+				}
+			}
+
+			decoded, err := decodeRawTransaction(&txn, uxOuts, headTime)
 			if err != nil {
 				return err
 			}
 
-			return printJSON(rTxn)
+			return printJSON(decoded)
 		},
 	}
+
+	decodeRawTxnCmd.Flags().String("db", "", "database of a stopped node to read the spent outputs from")
+
+	return decodeRawTxnCmd
+}
+
+// readTransactionInputs reads the outputs spent by txn and the time of the head block from a database
+func readTransactionInputs(dbPath string, txn *coin.Transaction) ([]*coin.UxOut, uint64, error) {
+	db, err := openDBReadOnly(dbPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer db.Close()
+
+	uxOuts, head, err := visor.ReadUxOuts(wrapDB(db), txn.In)
+	if err != nil {
+		return nil, 0, err
+	}
+	if head == nil {
+		return nil, 0, errors.New("the database has no blocks")
+	}
+
+	return uxOuts, head.Time(), nil
+}
+
+// decodeRawTransaction decodes a transaction and checks its signatures.
+// uxOuts are the outputs spent by the inputs, in input order, with nil for unknown outputs,
+// or nil if they were not read. The coin hours of the spent outputs are calculated at headTime.
+func decodeRawTransaction(txn *coin.Transaction, uxOuts []*coin.UxOut, headTime uint64) (*DecodedRawTransaction, error) {
+	if uxOuts != nil && len(uxOuts) != len(txn.In) {
+		return nil, fmt.Errorf("transaction has %d inputs but %d spent outputs were given", len(txn.In), len(uxOuts))
+	}
+
+	// Assume the transaction is not malformed and if it has no inputs
+	// that it is the genesis block's transaction
+	isGenesis := len(txn.In) == 0
+	rTxn, err := readable.NewTransaction(*txn, isGenesis)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DecodedRawTransaction{
+		Transaction: *rTxn,
+		Signatures:  make([]RawTransactionSignature, len(txn.In)),
+	}
+
+	if err := verifyTransactionStructure(txn); err != nil {
+		d.Error = err.Error()
+	}
+
+	var inputHours uint64
+	allInputsKnown := uxOuts != nil
+	for i := range txn.In {
+		var ux *coin.UxOut
+		if uxOuts != nil {
+			ux = uxOuts[i]
+		}
+
+		// A malformed transaction can have fewer signatures than inputs
+		var sig cipher.Sig
+		if i < len(txn.Sigs) {
+			sig = txn.Sigs[i]
+		}
+		d.Signatures[i].Status, d.Signatures[i].Error = signatureStatus(txn, i, sig, ux)
+
+		if uxOuts == nil {
+			continue
+		}
+		if ux == nil {
+			d.SpentOutputs = append(d.SpentOutputs, nil)
+			allInputsKnown = false
+			continue
+		}
+
+		vIn, err := visor.NewTransactionInput(*ux, headTime)
+		if err != nil {
+			return nil, err
+		}
+		in, err := readable.NewTransactionInput(vIn)
+		if err != nil {
+			return nil, err
+		}
+		d.SpentOutputs = append(d.SpentOutputs, &in)
+
+		inputHours, err = mathutil.AddUint64(inputHours, vIn.CalculatedHours)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if allInputsKnown && len(txn.In) > 0 {
+		var outputHours uint64
+		for _, o := range txn.Out {
+			outputHours, err = mathutil.AddUint64(outputHours, o.Hours)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if inputHours >= outputHours {
+			fee := inputHours - outputHours
+			d.Fee = &fee
+		}
+	}
+
+	return d, nil
+}
+
+// verifyTransactionStructure checks that a transaction is well formed. Unsigned inputs are allowed
+func verifyTransactionStructure(txn *coin.Transaction) error {
+	for _, s := range txn.Sigs {
+		if s.Null() {
+			return txn.VerifyUnsigned()
+		}
+	}
+	return txn.Verify()
+}
+
+// signatureStatus checks the signature of the input at index i. If the spent output ux is known,
+// the signature is verified against its owner, otherwise it is only checked to be well formed
+func signatureStatus(txn *coin.Transaction, i int, sig cipher.Sig, ux *coin.UxOut) (string, string) {
+	if sig.Null() {
+		return SignatureUnsigned, ""
+	}
+
+	hash := cipher.AddSHA256(txn.InnerHash, txn.In[i])
+
+	if ux != nil {
+		if err := cipher.VerifyAddressSignedHash(ux.Body.Address, sig, hash); err != nil {
+			return SignatureInvalid, err.Error()
+		}
+		return SignatureVerified, ""
+	}
+
+	if _, err := cipher.PubKeyFromSig(sig, hash); err != nil {
+		return SignatureInvalid, err.Error()
+	}
+	return SignatureValid, ""
 }
 
 func encodeJSONTxnCmd() *cobra.Command {
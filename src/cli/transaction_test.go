@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+func TestDecodeRawTransaction(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	_, otherSeckey := cipher.GenerateKeyPair()
+
+	ux := &coin.UxOut{
+		Head: coin.UxHead{
+			Time: 1000,
+		},
+		Body: coin.UxBody{
+			SrcTransaction: testutil.RandSHA256(t),
+			Address:        cipher.AddressFromPubKey(pubkey),
+			Coins:          2e6,
+			Hours:          100,
+		},
+	}
+
+	toAddr := testutil.MakeAddress()
+	newTxn := func(keys []cipher.SecKey) coin.Transaction {
+		var txn coin.Transaction
+		require.NoError(t, txn.PushInput(ux.Hash()))
+		require.NoError(t, txn.PushOutput(toAddr, 2e6, 40))
+		if keys == nil {
+			txn.Sigs = make([]cipher.Sig, len(txn.In))
+		} else {
+			txn.SignInputs(keys)
+		}
+		require.NoError(t, txn.UpdateHeader())
+		return txn
+	}
+
+	signed := newTxn([]cipher.SecKey{seckey})
+	wrongKey := newTxn([]cipher.SecKey{otherSeckey})
+	unsigned := newTxn(nil)
+
+	fee := uint64(60)
+	spent := &readable.TransactionInput{
+		Hash:            ux.Hash().Hex(),
+		Address:         ux.Body.Address.String(),
+		Coins:           "2.000000",
+		SrcTxid:         ux.Body.SrcTransaction.Hex(),
+		Hours:           100,
+		CalculatedHours: 100,
+	}
+
+	cases := []struct {
+		name            string
+		txn             coin.Transaction
+		uxOuts          []*coin.UxOut
+		signatureStatus string
+		spentOutputs    []*readable.TransactionInput
+		fee             *uint64
+		err             string
+	}{
+		{
+			name:            "signed, spent outputs not read",
+			txn:             signed,
+			signatureStatus: SignatureValid,
+		},
+		{
+			name:            "signed, spent output known",
+			txn:             signed,
+			uxOuts:          []*coin.UxOut{ux},
+			signatureStatus: SignatureVerified,
+			spentOutputs:    []*readable.TransactionInput{spent},
+			fee:             &fee,
+		},
+		{
+			name:            "signed, spent output not in the database",
+			txn:             signed,
+			uxOuts:          []*coin.UxOut{nil},
+			signatureStatus: SignatureValid,
+			spentOutputs:    []*readable.TransactionInput{nil},
+		},
+		{
+			name:            "signed by another key",
+			txn:             wrongKey,
+			uxOuts:          []*coin.UxOut{ux},
+			signatureStatus: SignatureInvalid,
+			spentOutputs:    []*readable.TransactionInput{spent},
+			fee:             &fee,
+		},
+		{
+			name:            "unsigned",
+			txn:             unsigned,
+			uxOuts:          []*coin.UxOut{ux},
+			signatureStatus: SignatureUnsigned,
+			spentOutputs:    []*readable.TransactionInput{spent},
+			fee:             &fee,
+		},
+		{
+			name:   "spent outputs do not match inputs",
+			txn:    signed,
+			uxOuts: []*coin.UxOut{ux, ux},
+			err:    "transaction has 1 inputs but 2 spent outputs were given",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			txn := tc.txn
+			d, err := decodeRawTransaction(&txn, tc.uxOuts, ux.Head.Time)
+			if tc.err != "" {
+				testutil.RequireError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			rTxn, err := readable.NewTransaction(txn, false)
+			require.NoError(t, err)
+			require.Equal(t, *rTxn, d.Transaction)
+
+			require.Empty(t, d.Error)
+			require.Equal(t, tc.fee, d.Fee)
+			require.Equal(t, tc.spentOutputs, d.SpentOutputs)
+
+			require.Len(t, d.Signatures, 1)
+			require.Equal(t, tc.signatureStatus, d.Signatures[0].Status)
+			if tc.signatureStatus == SignatureInvalid {
+				require.NotEmpty(t, d.Signatures[0].Error)
+			} else {
+				require.Empty(t, d.Signatures[0].Error)
+			}
+		})
+	}
+
+	// A transaction with no outputs is not well formed
+	var txn coin.Transaction
+	require.NoError(t, txn.PushInput(ux.Hash()))
+	txn.Sigs = make([]cipher.Sig, 1)
+	require.NoError(t, txn.UpdateHeader())
+	d, err := decodeRawTransaction(&txn, []*coin.UxOut{nil}, 0)
+	require.NoError(t, err)
+	require.Equal(t, "No outputs", d.Error)
+	require.Nil(t, d.Fee)
+}
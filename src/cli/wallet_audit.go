@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// WalletAuditResult is the result of auditing the wallets of a directory for reused keys
+type WalletAuditResult struct {
+	Directory  string                 `json:"directory"`
+	Wallets    int                    `json:"wallets"`
+	Keys       int                    `json:"keys"`
+	Duplicates []WalletAuditDuplicate `json:"duplicates"`
+}
+
+// WalletAuditDuplicate is a key that was found more than once
+type WalletAuditDuplicate struct {
+	PublicKey string                `json:"public_key,omitempty"`
+	Address   string                `json:"address"`
+	Locations []WalletAuditLocation `json:"locations"`
+}
+
+// WalletAuditLocation is a wallet entry that holds a duplicate key
+type WalletAuditLocation struct {
+	Wallet string `json:"wallet"`
+	Label  string `json:"label"`
+	// Account and Change are the account index and chain of a bip44 wallet entry
+	Account *uint32 `json:"account,omitempty"`
+	Change  *uint32 `json:"change,omitempty"`
+	// Index of the entry in the wallet, or in the chain of a bip44 wallet
+	Index int `json:"index"`
+}
+
+func walletAuditCmd() *cobra.Command {
+	walletAuditCmd := &cobra.Command{
+		Short: "Find keys that are used by more than one wallet, without connecting to a node",
+		Use:   "walletAudit",
+		Long: `Loads all wallet files of a wallet directory and reports the keys that
+    appear more than once: a public key that is in more than one wallet, for example
+    because two wallets were created from the same seed, or an address that is
+    repeated within one wallet.
+
+    No node is contacted and no password is needed, encrypted wallets keep
+    their public keys and addresses unencrypted.
+
+    The result is printed as JSON. If any duplicate is found, the command exits
+    with an error after printing it.
+
+    If --dir is not specified, the wallets in $DATA_DIR/wallets are audited.`,
+		Args:                  cobra.NoArgs,
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(c *cobra.Command, _ []string) error {
+			dir, err := c.Flags().GetString("dir")
+			if err != nil {
+				return err
+			}
+
+			if dir == "" {
+				dir = filepath.Join(cliConfig.DataDir, "wallets")
+			}
+
+			result, err := WalletAudit(dir)
+			if err != nil {
+				return err
+			}
+
+			if err := printJSON(result); err != nil {
+				return err
+			}
+
+			if len(result.Duplicates) != 0 {
+				return fmt.Errorf("found %d duplicate keys", len(result.Duplicates))
+			}
+
+			return nil
+		},
+	}
+
+	walletAuditCmd.Flags().String("dir", "", "wallet directory to audit")
+
+	return walletAuditCmd
+}
+
+// walletAuditKey identifies a key. Entries without a public key are identified by their address
+type walletAuditKey struct {
+	pubkey  cipher.PubKey
+	address string
+}
+
+// WalletAudit loads the wallet files of dir and finds the keys that are used by more than one
+// wallet entry. The duplicates are sorted by address, and their locations by wallet filename.
+func WalletAudit(dir string) (*WalletAuditResult, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WalletAuditResult{
+		Directory:  dir,
+		Duplicates: []WalletAuditDuplicate{},
+	}
+
+	var keys []walletAuditKey
+	addrs := make(map[walletAuditKey]string)
+	locations := make(map[walletAuditKey][]WalletAuditLocation)
+
+	// ReadDir sorts the files by name
+	for _, f := range files {
+		if !f.Mode().IsRegular() || !strings.HasSuffix(f.Name(), wallet.WalletExt) {
+			continue
+		}
+
+		// The wallets are not migrated on disk, so that the audit does not write to the wallet directory
+		w, err := wallet.LoadReadOnly(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("load wallet %s failed: %v", f.Name(), err)
+		}
+
+		result.Wallets++
+
+		if err := forEachWalletEntry(w, func(loc WalletAuditLocation, e wallet.Entry) {
+			k := walletAuditKey{
+				pubkey: e.Public,
+			}
+			if e.Public.Null() {
+				k.address = e.Address.String()
+			}
+
+			if _, ok := locations[k]; !ok {
+				keys = append(keys, k)
+				addrs[k] = e.Address.String()
+			}
+			locations[k] = append(locations[k], loc)
+			result.Keys++
+		}); err != nil {
+			return nil, fmt.Errorf("read entries of wallet %s failed: %v", f.Name(), err)
+		}
+	}
+
+	for _, k := range keys {
+		if len(locations[k]) < 2 {
+			continue
+		}
+
+		d := WalletAuditDuplicate{
+			Address:   addrs[k],
+			Locations: locations[k],
+		}
+		if !k.pubkey.Null() {
+			d.PublicKey = k.pubkey.Hex()
+		}
+		result.Duplicates = append(result.Duplicates, d)
+	}
+
+	sort.Slice(result.Duplicates, func(i, j int) bool {
+		return result.Duplicates[i].Address < result.Duplicates[j].Address
+	})
+
+	return result, nil
+}
+
+// forEachWalletEntry calls f on every entry of a wallet, including the entries of all accounts and chains of a bip44 wallet
+func forEachWalletEntry(w wallet.Wallet, f func(WalletAuditLocation, wallet.Entry)) error {
+	if w.Type() != wallet.WalletTypeBip44 {
+		entries, err := w.GetEntries()
+		if err != nil {
+			return err
+		}
+
+		for i, e := range entries {
+			f(WalletAuditLocation{
+				Wallet: w.Filename(),
+				Label:  w.Label(),
+				Index:  i,
+			}, e)
+		}
+
+		return nil
+	}
+
+	for _, a := range w.Accounts() {
+		for _, change := range []bool{false, true} {
+			entries, err := w.GetEntries(wallet.OptionAccount(a.Index), wallet.OptionChange(change))
+			if err != nil {
+				return err
+			}
+
+			account := a.Index
+			var chain uint32
+			if change {
+				chain = 1
+			}
+
+			for i, e := range entries {
+				f(WalletAuditLocation{
+					Wallet:  w.Filename(),
+					Label:   w.Label(),
+					Account: &account,
+					Change:  &chain,
+					Index:   i,
+				}, e)
+			}
+		}
+	}
+
+	return nil
+}
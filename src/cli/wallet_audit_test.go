@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/wallet"
+	_ "github.com/skycoin/skycoin/src/wallet/bip44wallet"
+	"github.com/skycoin/skycoin/src/wallet/collection"
+	"github.com/skycoin/skycoin/src/wallet/crypto"
+)
+
+func TestWalletAudit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wallet-audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	newWallet := func(filename, seed, walletType string, n uint64, encrypt bool) wallet.Wallet {
+		opts := wallet.Options{
+			Type:      walletType,
+			Coin:      wallet.CoinTypeSkycoin,
+			Seed:      seed,
+			GenerateN: n,
+		}
+		if encrypt {
+			opts.Encrypt = true
+			opts.Password = []byte("pwd")
+			opts.CryptoType = crypto.CryptoTypeSha256Xor
+		}
+
+		w, err := wallet.NewWallet(filename, filename, seed, opts)
+		require.NoError(t, err)
+		require.NoError(t, wallet.Save(w, dir))
+		return w
+	}
+
+	entries := func(w wallet.Wallet) wallet.Entries {
+		e, err := w.GetEntries()
+		require.NoError(t, err)
+		return e
+	}
+
+	// No wallets, no duplicates
+	result, err := WalletAudit(dir)
+	require.NoError(t, err)
+	require.Equal(t, &WalletAuditResult{
+		Directory:  dir,
+		Duplicates: []WalletAuditDuplicate{},
+	}, result)
+
+	// Wallets created from different seeds share no keys
+	newWallet("a.wlt", "seed a", wallet.WalletTypeDeterministic, 3, false)
+	newWallet("b.wlt", "seed b", wallet.WalletTypeDeterministic, 2, false)
+
+	result, err = WalletAudit(dir)
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Wallets)
+	require.Equal(t, 5, result.Keys)
+	require.Empty(t, result.Duplicates)
+
+	// An encrypted wallet created from a reused seed shares the keys of its first addresses
+	c := newWallet("c.wlt", "seed a", wallet.WalletTypeDeterministic, 2, true)
+
+	// Two bip44 wallets of the same seed share the keys of both chains, a bip44 wallet is created with a change address
+	bip44Seed := "voyage say extend find sheriff surge priority merit ignore maple cash argue"
+	d := newWallet("d.wlt", bip44Seed, wallet.WalletTypeBip44, 1, false)
+	newWallet("e.wlt", bip44Seed, wallet.WalletTypeBip44, 1, false)
+
+	// A wallet file without a checksum, written by an older version, that repeats an entry
+	col, err := collection.NewWallet("f.wlt", "f.wlt")
+	require.NoError(t, err)
+	pk, sk := cipher.GenerateKeyPair()
+	colEntry := wallet.Entry{
+		Address: cipher.AddressFromPubKey(pk),
+		Public:  pk,
+		Secret:  sk,
+	}
+	require.NoError(t, col.AddEntry(colEntry))
+	data, err := col.Serialize()
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	fields["entries"] = append(fields["entries"].([]interface{}), fields["entries"].([]interface{})[0])
+	data, err = json.Marshal(fields)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "f.wlt"), data, 0600))
+
+	// Files that are not wallets are skipped
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0600))
+
+	result, err = WalletAudit(dir)
+	require.NoError(t, err)
+	require.Equal(t, 6, result.Wallets)
+	require.Equal(t, 5+2+2+2+2, result.Keys)
+
+	uint32Ptr := func(v uint32) *uint32 {
+		return &v
+	}
+
+	dEntries := entries(d)
+	dChange, err := d.GetEntries(wallet.OptionChange(true))
+	require.NoError(t, err)
+
+	duplicate := func(e wallet.Entry, locs ...WalletAuditLocation) WalletAuditDuplicate {
+		return WalletAuditDuplicate{
+			PublicKey: e.Public.Hex(),
+			Address:   e.Address.String(),
+			Locations: locs,
+		}
+	}
+	loc := func(w string, i int) WalletAuditLocation {
+		return WalletAuditLocation{
+			Wallet: w,
+			Label:  w,
+			Index:  i,
+		}
+	}
+	bip44Loc := func(w string, change uint32) WalletAuditLocation {
+		return WalletAuditLocation{
+			Wallet:  w,
+			Label:   w,
+			Account: uint32Ptr(0),
+			Change:  uint32Ptr(change),
+			Index:   0,
+		}
+	}
+
+	expected := []WalletAuditDuplicate{
+		duplicate(entries(c)[0], loc("a.wlt", 0), loc("c.wlt", 0)),
+		duplicate(entries(c)[1], loc("a.wlt", 1), loc("c.wlt", 1)),
+		duplicate(dEntries[0], bip44Loc("d.wlt", 0), bip44Loc("e.wlt", 0)),
+		duplicate(dChange[0], bip44Loc("d.wlt", 1), bip44Loc("e.wlt", 1)),
+		duplicate(colEntry, loc("f.wlt", 0), loc("f.wlt", 1)),
+	}
+	require.ElementsMatch(t, expected, result.Duplicates)
+
+	for i := 1; i < len(result.Duplicates); i++ {
+		require.True(t, result.Duplicates[i-1].Address < result.Duplicates[i].Address)
+	}
+
+	// A wallet that fails to load aborts the audit
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "g.wlt"), []byte("{"), 0600))
+	_, err = WalletAudit(dir)
+	require.Error(t, err)
+
+	_, err = WalletAudit(filepath.Join(dir, "missing"))
+	require.Error(t, err)
+}
+
+func TestWalletAuditDoesNotMigrate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wallet-audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	w, err := wallet.NewWallet("a.wlt", "a.wlt", "seed a", wallet.Options{
+		Type:      wallet.WalletTypeDeterministic,
+		Coin:      wallet.CoinTypeSkycoin,
+		Seed:      "seed a",
+		GenerateN: 2,
+	})
+	require.NoError(t, err)
+	data, err := w.Serialize()
+	require.NoError(t, err)
+
+	// A version 0.1 wallet file without a coin, which Load migrates and saves back with a backup
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	meta := fields["meta"].(map[string]interface{})
+	meta["version"] = "0.1"
+	delete(meta, "coin")
+	data, err = json.Marshal(fields)
+	require.NoError(t, err)
+	filename := filepath.Join(dir, "a.wlt")
+	require.NoError(t, ioutil.WriteFile(filename, data, 0600))
+
+	result, err := WalletAudit(dir)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Wallets)
+	require.Equal(t, 2, result.Keys)
+
+	// The wallet file is not rewritten and no backup is created
+	b, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, data, b)
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+}
@@ -0,0 +1,423 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/bip39"
+	"github.com/skycoin/skycoin/src/cipher/bip44"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+const (
+	walletExportFormatElectrumJSON = "electrum-json"
+	walletExportFormatDescriptor   = "descriptor"
+)
+
+func walletExportCmd() *cobra.Command {
+	walletExportCmd := &cobra.Command{
+		Args:  cobra.ExactArgs(1),
+		RunE:  walletExportHandler,
+		Use:   "walletExport [wallet]",
+		Short: "Export a wallet's keys for import into other wallets",
+		Long: `Export the secret keys of a deterministic or bip44 wallet in a format
+    that can be imported into other wallet software.
+
+    --format=electrum-json prints an Electrum style wallet JSON document.
+    Deterministic wallets are exported as an "imported" keystore of
+    public key to secret key pairs. Bip44 wallets are exported as a "bip32"
+    keystore with the account xprv and xpub.
+
+    --format=descriptor prints BIP380 style output descriptors, one per line.
+    Deterministic wallets produce one "pkh(<secret key>)" descriptor per address.
+    Bip44 wallets produce a ranged descriptor for the external and change chains.
+    Skycoin addresses are pay-to-pubkey-hash addresses, so "pkh" is used.
+
+    The output is printed to stdout, or written to the file given by --output.
+    The output file is created with 0600 permissions and must not already exist.
+    Secret keys are never written to temporary files.
+
+    Please make sure that the node has wallet seed API enabled (--enable-api-sets="INSECURE_WALLET_SEED").
+
+    Use caution when using the "-p" command. If you have command
+    history enabled your wallet encryption password can be recovered
+    from the history log. If you do not include the "-p" option you will
+    be prompted to enter your password after you enter your command.`,
+		SilenceUsage: true,
+	}
+
+	walletExportCmd.Flags().StringP("format", "f", walletExportFormatElectrumJSON, "export format (\"electrum-json\", \"descriptor\")")
+	walletExportCmd.Flags().StringP("output", "o", "", "write the export to this file instead of stdout")
+	walletExportCmd.Flags().StringP("password", "p", "", "wallet password")
+
+	return walletExportCmd
+}
+
+func walletExportHandler(c *cobra.Command, args []string) error {
+	format, err := c.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	switch format {
+	case walletExportFormatElectrumJSON, walletExportFormatDescriptor:
+	default:
+		return errors.New("format must be \"electrum-json\" or \"descriptor\"")
+	}
+
+	output, err := c.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+
+	id := args[0]
+	wlt, err := apiClient.Wallet(id)
+	if err != nil {
+		return err
+	}
+
+	switch wlt.Meta.Type {
+	case wallet.WalletTypeDeterministic, wallet.WalletTypeBip44:
+	default:
+		return fmt.Errorf("unsupported wallet type %q for wallet export command", wlt.Meta.Type)
+	}
+
+	var password []byte
+	if wlt.Meta.Encrypted {
+		pr := NewPasswordReader([]byte(c.Flag("password").Value.String()))
+		password, err = pr.Password()
+		if err != nil {
+			return err
+		}
+	}
+
+	rsp, err := apiClient.WalletSeed(id, string(password))
+	if err != nil {
+		return err
+	}
+
+	var out string
+	switch format {
+	case walletExportFormatElectrumJSON:
+		e, err := makeElectrumWallet(*wlt, rsp.Seed, rsp.SeedPassphrase)
+		if err != nil {
+			return err
+		}
+		d, err := formatJSON(e)
+		if err != nil {
+			return err
+		}
+		out = string(d)
+	case walletExportFormatDescriptor:
+		descs, err := makeDescriptors(*wlt, rsp.Seed, rsp.SeedPassphrase)
+		if err != nil {
+			return err
+		}
+		out = strings.Join(descs, "\n")
+	}
+
+	if output == "" {
+		fmt.Println(out)
+		return nil
+	}
+
+	return writeExportFile(output, out+"\n")
+}
+
+// writeExportFile writes the export directly to a new file, readable only by the owner
+func writeExportFile(path, data string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.WriteString(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// exportKey is a wallet entry with its secret key
+type exportKey struct {
+	Address string
+	PubKey  cipher.PubKey
+	SecKey  cipher.SecKey
+	Change  bool
+}
+
+// deriveDeterministicKeys regenerates the secret keys of a deterministic wallet's entries from its seed
+func deriveDeterministicKeys(wlt api.WalletResponse, seed string) ([]exportKey, error) {
+	if len(wlt.Entries) == 0 {
+		return nil, nil
+	}
+
+	secKeys, err := cipher.GenerateDeterministicKeyPairs([]byte(seed), len(wlt.Entries))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]exportKey, len(wlt.Entries))
+	for i, e := range wlt.Entries {
+		pk := cipher.MustPubKeyFromSecKey(secKeys[i])
+		addr := cipher.AddressFromPubKey(pk)
+		if addr.String() != e.Address {
+			return nil, fmt.Errorf("derived address %s does not match wallet entry address %s", addr, e.Address)
+		}
+
+		keys[i] = exportKey{
+			Address: e.Address,
+			PubKey:  pk,
+			SecKey:  secKeys[i],
+		}
+	}
+
+	return keys, nil
+}
+
+// bip44ExportAccount returns the account 0 node of a bip44 wallet, which holds the wallet's entries
+func bip44ExportAccount(wlt api.WalletResponse, seed, seedPassphrase string) (*bip44.Account, error) {
+	if wlt.Meta.Bip44Coin == nil {
+		return nil, errors.New("wallet has no bip44 coin type")
+	}
+
+	s, err := bip39.NewSeed(seed, seedPassphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	coin, err := bip44.NewCoin(s, *wlt.Meta.Bip44Coin)
+	if err != nil {
+		return nil, err
+	}
+
+	return coin.Account(0)
+}
+
+// deriveBip44Keys regenerates the secret keys of a bip44 wallet's entries from its seed
+func deriveBip44Keys(wlt api.WalletResponse, seed, seedPassphrase string) ([]exportKey, error) {
+	acct, err := bip44ExportAccount(wlt, seed, seedPassphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	external, err := acct.External()
+	if err != nil {
+		return nil, err
+	}
+
+	change, err := acct.Change()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]exportKey, len(wlt.Entries))
+	for i, e := range wlt.Entries {
+		if e.ChildNumber == nil || e.Change == nil {
+			return nil, fmt.Errorf("wallet entry %s is missing its bip44 path", e.Address)
+		}
+
+		chain := external
+		switch *e.Change {
+		case bip44.ExternalChainIndex:
+		case bip44.ChangeChainIndex:
+			chain = change
+		default:
+			return nil, fmt.Errorf("wallet entry %s has invalid change index %d", e.Address, *e.Change)
+		}
+
+		k, err := chain.NewPrivateChildKey(*e.ChildNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		sk := cipher.MustNewSecKey(k.Key)
+		pk := cipher.MustPubKeyFromSecKey(sk)
+		addr := cipher.AddressFromPubKey(pk)
+		if addr.String() != e.Address {
+			return nil, fmt.Errorf("derived address %s does not match wallet entry address %s", addr, e.Address)
+		}
+
+		keys[i] = exportKey{
+			Address: e.Address,
+			PubKey:  pk,
+			SecKey:  sk,
+			Change:  *e.Change == bip44.ChangeChainIndex,
+		}
+	}
+
+	return keys, nil
+}
+
+func deriveExportKeys(wlt api.WalletResponse, seed, seedPassphrase string) ([]exportKey, error) {
+	switch wlt.Meta.Type {
+	case wallet.WalletTypeDeterministic:
+		return deriveDeterministicKeys(wlt, seed)
+	case wallet.WalletTypeBip44:
+		return deriveBip44Keys(wlt, seed, seedPassphrase)
+	default:
+		return nil, fmt.Errorf("unsupported wallet type %q for wallet export command", wlt.Meta.Type)
+	}
+}
+
+// ElectrumKeystore is the keystore section of an Electrum wallet file
+type ElectrumKeystore struct {
+	Type       string            `json:"type"`
+	Keypairs   map[string]string `json:"keypairs,omitempty"`
+	Xprv       string            `json:"xprv,omitempty"`
+	Xpub       string            `json:"xpub,omitempty"`
+	Derivation string            `json:"derivation,omitempty"`
+}
+
+// ElectrumAddresses is the addresses section of an Electrum wallet file
+type ElectrumAddresses struct {
+	Receiving []string `json:"receiving"`
+	Change    []string `json:"change"`
+}
+
+// ElectrumWallet is an Electrum style wallet file
+type ElectrumWallet struct {
+	WalletType string            `json:"wallet_type"`
+	Keystore   ElectrumKeystore  `json:"keystore"`
+	Addresses  ElectrumAddresses `json:"addresses"`
+}
+
+func makeElectrumWallet(wlt api.WalletResponse, seed, seedPassphrase string) (*ElectrumWallet, error) {
+	keys, err := deriveExportKeys(wlt, seed, seedPassphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &ElectrumWallet{
+		Addresses: ElectrumAddresses{
+			Receiving: []string{},
+			Change:    []string{},
+		},
+	}
+
+	for _, k := range keys {
+		if k.Change {
+			e.Addresses.Change = append(e.Addresses.Change, k.Address)
+		} else {
+			e.Addresses.Receiving = append(e.Addresses.Receiving, k.Address)
+		}
+	}
+
+	switch wlt.Meta.Type {
+	case wallet.WalletTypeDeterministic:
+		e.WalletType = "imported"
+		e.Keystore = ElectrumKeystore{
+			Type:     "imported",
+			Keypairs: make(map[string]string, len(keys)),
+		}
+		for _, k := range keys {
+			e.Keystore.Keypairs[k.PubKey.Hex()] = k.SecKey.Hex()
+		}
+	case wallet.WalletTypeBip44:
+		acct, err := bip44ExportAccount(wlt, seed, seedPassphrase)
+		if err != nil {
+			return nil, err
+		}
+		e.WalletType = "standard"
+		e.Keystore = ElectrumKeystore{
+			Type:       "bip32",
+			Xprv:       acct.String(),
+			Xpub:       acct.PublicKey().String(),
+			Derivation: fmt.Sprintf("m/44'/%d'/0'", *wlt.Meta.Bip44Coin),
+		}
+	}
+
+	return e, nil
+}
+
+func makeDescriptors(wlt api.WalletResponse, seed, seedPassphrase string) ([]string, error) {
+	// Derive the keys for both wallet types to verify that they match the wallet's addresses
+	keys, err := deriveExportKeys(wlt, seed, seedPassphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	var descs []string
+	switch wlt.Meta.Type {
+	case wallet.WalletTypeDeterministic:
+		for _, k := range keys {
+			descs = append(descs, addDescriptorChecksum(fmt.Sprintf("pkh(%s)", k.SecKey.Hex())))
+		}
+	case wallet.WalletTypeBip44:
+		acct, err := bip44ExportAccount(wlt, seed, seedPassphrase)
+		if err != nil {
+			return nil, err
+		}
+		for _, chain := range []uint32{bip44.ExternalChainIndex, bip44.ChangeChainIndex} {
+			descs = append(descs, addDescriptorChecksum(fmt.Sprintf("pkh(%s/%d/*)", acct.String(), chain)))
+		}
+	}
+
+	return descs, nil
+}
+
+const (
+	descriptorInputCharset    = "0123456789()[],'/*abcdefgh@:$%{}IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+func descriptorPolymod(c uint64, val uint64) uint64 {
+	c0 := c >> 35
+	c = ((c & 0x7ffffffff) << 5) ^ val
+	for i, g := range []uint64{0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd} {
+		if (c0>>uint(i))&1 != 0 {
+			c ^= g
+		}
+	}
+	return c
+}
+
+// descriptorChecksum computes the BIP380 checksum of an output descriptor
+func descriptorChecksum(desc string) (string, error) {
+	c := uint64(1)
+	cls := uint64(0)
+	clsCount := 0
+	for _, ch := range desc {
+		pos := strings.IndexRune(descriptorInputCharset, ch)
+		if pos < 0 {
+			return "", fmt.Errorf("invalid descriptor character %q", ch)
+		}
+		c = descriptorPolymod(c, uint64(pos)&31)
+		cls = cls*3 + uint64(pos)>>5
+		clsCount++
+		if clsCount == 3 {
+			c = descriptorPolymod(c, cls)
+			cls = 0
+			clsCount = 0
+		}
+	}
+	if clsCount > 0 {
+		c = descriptorPolymod(c, cls)
+	}
+	for i := 0; i < 8; i++ {
+		c = descriptorPolymod(c, 0)
+	}
+	c ^= 1
+
+	var sum [8]byte
+	for i := range sum {
+		sum[i] = descriptorChecksumCharset[(c>>(5*(7-uint(i))))&31]
+	}
+
+	return string(sum[:]), nil
+}
+
+// addDescriptorChecksum appends the BIP380 checksum to a descriptor made of valid descriptor characters
+func addDescriptorChecksum(desc string) string {
+	sum, err := descriptorChecksum(desc)
+	if err != nil {
+		panic(err)
+	}
+	return desc + "#" + sum
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/bip39"
+	"github.com/skycoin/skycoin/src/cipher/bip44"
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestDescriptorChecksum(t *testing.T) {
+	// Test vectors from BIP380
+	sum, err := descriptorChecksum("raw(deadbeef)")
+	require.NoError(t, err)
+	require.Equal(t, "89f8spxm", sum)
+
+	require.Equal(t, "raw(deadbeef)#89f8spxm", addDescriptorChecksum("raw(deadbeef)"))
+
+	_, err = descriptorChecksum("raw(deadbeef)\n")
+	require.Error(t, err)
+}
+
+func TestMakeDeterministicExport(t *testing.T) {
+	seed := "foo bar baz"
+	secKeys := cipher.MustGenerateDeterministicKeyPairs([]byte(seed), 2)
+
+	wlt := api.WalletResponse{
+		Meta: readable.WalletMeta{
+			Type: wallet.WalletTypeDeterministic,
+		},
+	}
+	for _, sk := range secKeys {
+		pk := cipher.MustPubKeyFromSecKey(sk)
+		wlt.Entries = append(wlt.Entries, readable.WalletEntry{
+			Address: cipher.AddressFromPubKey(pk).String(),
+			Public:  pk.Hex(),
+		})
+	}
+
+	e, err := makeElectrumWallet(wlt, seed, "")
+	require.NoError(t, err)
+	require.Equal(t, "imported", e.WalletType)
+	require.Equal(t, "imported", e.Keystore.Type)
+	require.Len(t, e.Keystore.Keypairs, 2)
+	for i, sk := range secKeys {
+		require.Equal(t, sk.Hex(), e.Keystore.Keypairs[wlt.Entries[i].Public])
+	}
+	require.Equal(t, []string{wlt.Entries[0].Address, wlt.Entries[1].Address}, e.Addresses.Receiving)
+	require.Empty(t, e.Addresses.Change)
+
+	descs, err := makeDescriptors(wlt, seed, "")
+	require.NoError(t, err)
+	require.Len(t, descs, 2)
+	for i, sk := range secKeys {
+		require.Equal(t, addDescriptorChecksum("pkh("+sk.Hex()+")"), descs[i])
+	}
+
+	// A wrong seed does not reproduce the wallet's addresses
+	_, err = makeElectrumWallet(wlt, "wrong seed", "")
+	require.Error(t, err)
+}
+
+func TestMakeBip44Export(t *testing.T) {
+	seed := bip39.MustNewDefaultMnemonic()
+	coinType := bip44.CoinTypeSkycoin
+
+	s, err := bip39.NewSeed(seed, "")
+	require.NoError(t, err)
+	c, err := bip44.NewCoin(s, coinType)
+	require.NoError(t, err)
+	acct, err := c.Account(0)
+	require.NoError(t, err)
+
+	wlt := api.WalletResponse{
+		Meta: readable.WalletMeta{
+			Type:      wallet.WalletTypeBip44,
+			Bip44Coin: &coinType,
+		},
+	}
+	for _, chain := range []uint32{bip44.ExternalChainIndex, bip44.ChangeChainIndex} {
+		chainKey, err := acct.NewPrivateChildKey(chain)
+		require.NoError(t, err)
+		k, err := chainKey.NewPrivateChildKey(0)
+		require.NoError(t, err)
+
+		pk := cipher.MustNewPubKey(k.PublicKey().Key)
+		childNumber := uint32(0)
+		change := chain
+		wlt.Entries = append(wlt.Entries, readable.WalletEntry{
+			Address:     cipher.AddressFromPubKey(pk).String(),
+			Public:      pk.Hex(),
+			ChildNumber: &childNumber,
+			Change:      &change,
+		})
+	}
+
+	e, err := makeElectrumWallet(wlt, seed, "")
+	require.NoError(t, err)
+	require.Equal(t, "standard", e.WalletType)
+	require.Equal(t, "bip32", e.Keystore.Type)
+	require.Equal(t, acct.String(), e.Keystore.Xprv)
+	require.Equal(t, acct.PublicKey().String(), e.Keystore.Xpub)
+	require.Equal(t, "m/44'/8000'/0'", e.Keystore.Derivation)
+	require.Equal(t, []string{wlt.Entries[0].Address}, e.Addresses.Receiving)
+	require.Equal(t, []string{wlt.Entries[1].Address}, e.Addresses.Change)
+
+	descs, err := makeDescriptors(wlt, seed, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		addDescriptorChecksum("pkh(" + acct.String() + "/0/*)"),
+		addDescriptorChecksum("pkh(" + acct.String() + "/1/*)"),
+	}, descs)
+
+	// A seed passphrase changes the derived keys
+	_, err = makeDescriptors(wlt, seed, "passphrase")
+	require.Error(t, err)
+}
@@ -6,6 +6,7 @@ This package should not have any dependencies except for go stdlib and cipher.
 package coin
 
 import (
+	"errors"
 	"fmt"
 	"log"
 
@@ -106,6 +107,37 @@ func NewGenesisBlock(genesisAddr cipher.Address, genesisCoins, timestamp uint64)
 	return b, nil
 }
 
+// NewGenesisBlockFromOutputs creates a genesis block that distributes its coins and coin hours to outputs.
+// The UxHash of a genesis block header is otherwise empty, it is set to outputsHash to record the distribution.
+func NewGenesisBlockFromOutputs(outputs []TransactionOutput, timestamp uint64, outputsHash cipher.SHA256) (*Block, error) {
+	if len(outputs) == 0 {
+		return nil, errors.New("Refusing to create genesis block with no outputs")
+	}
+
+	txn := Transaction{}
+	for _, o := range outputs {
+		if err := txn.PushOutput(o.Address, o.Coins, o.Hours); err != nil {
+			return nil, err
+		}
+	}
+
+	body := BlockBody{Transactions: Transactions{txn}}
+	head := BlockHeader{
+		Time:     timestamp,
+		BodyHash: body.Hash(),
+		PrevHash: cipher.SHA256{},
+		BkSeq:    0,
+		Version:  0,
+		Fee:      0,
+		UxHash:   outputsHash,
+	}
+
+	return &Block{
+		Head: head,
+		Body: body,
+	}, nil
+}
+
 // HashHeader return hash of block head.
 func (b Block) HashHeader() cipher.SHA256 {
 	return b.Head.Hash()
@@ -160,12 +192,17 @@ func (bh *BlockHeader) Bytes() []byte {
 
 // Hash returns the merkle hash of contained transactions
 func (bb BlockBody) Hash() cipher.SHA256 {
+	return bb.MerkleTree().Root()
+}
+
+// MerkleTree returns the merkle tree of the transaction hashes, whose root is the body hash.
+// Its proofs show that a transaction is in the block to a client that only has the block header
+func (bb BlockBody) MerkleTree() *cipher.MerkleTree {
 	hashes := make([]cipher.SHA256, len(bb.Transactions))
 	for i := range bb.Transactions {
 		hashes[i] = bb.Transactions[i].Hash()
 	}
-	// Merkle hash of transactions
-	return cipher.Merkle(hashes)
+	return cipher.NewMerkleTreeFromHashes(hashes)
 }
 
 // Size returns the size of Transactions, in bytes
@@ -108,6 +108,24 @@ func TestBlockBodyHash(t *testing.T) {
 	require.Equal(t, b.Body.Hash(), cipher.Merkle(hashes))
 }
 
+func TestBlockBodyMerkleTree(t *testing.T) {
+	b := makeNewBlock(t, testutil.RandSHA256(t))
+	for i := 0; i < 4; i++ {
+		addTransactionToBlock(t, b)
+	}
+	b.Head.BodyHash = b.Body.Hash()
+
+	tree := b.Body.MerkleTree()
+	require.Equal(t, len(b.Body.Transactions), tree.Len())
+	require.Equal(t, b.Head.BodyHash, tree.Root())
+
+	for i, txn := range b.Body.Transactions {
+		p := tree.Proof(i)
+		require.True(t, cipher.VerifyProofHash(txn.Hash(), p, b.Head.BodyHash))
+		require.False(t, cipher.VerifyProofHash(testutil.RandSHA256(t), p, b.Head.BodyHash))
+	}
+}
+
 func TestNewGenesisBlock(t *testing.T) {
 	gb, err := NewGenesisBlock(genAddress, _genCoins, _genTime)
 	require.NoError(t, err)
@@ -130,6 +148,34 @@ func TestNewGenesisBlock(t *testing.T) {
 	require.Equal(t, _genCoins, txn.Out[0].Hours)
 }
 
+func TestNewGenesisBlockFromOutputs(t *testing.T) {
+	_, err := NewGenesisBlockFromOutputs(nil, _genTime, cipher.SHA256{})
+	require.EqualError(t, err, "Refusing to create genesis block with no outputs")
+
+	outputs := []TransactionOutput{
+		{Address: genAddress, Coins: _genCoins, Hours: _genCoinHours},
+		{Address: testutil.MakeAddress(), Coins: 10e6, Hours: 0},
+	}
+	outputsHash := testutil.RandSHA256(t)
+
+	gb, err := NewGenesisBlockFromOutputs(outputs, _genTime, outputsHash)
+	require.NoError(t, err)
+
+	require.Equal(t, cipher.SHA256{}, gb.Head.PrevHash)
+	require.Equal(t, _genTime, gb.Head.Time)
+	require.Equal(t, uint64(0), gb.Head.BkSeq)
+	require.Equal(t, uint32(0), gb.Head.Version)
+	require.Equal(t, uint64(0), gb.Head.Fee)
+	require.Equal(t, outputsHash, gb.Head.UxHash)
+	require.Equal(t, gb.Body.Hash(), gb.Head.BodyHash)
+
+	require.Equal(t, 1, len(gb.Body.Transactions))
+	txn := gb.Body.Transactions[0]
+	require.Len(t, txn.In, 0)
+	require.Len(t, txn.Sigs, 0)
+	require.Equal(t, outputs, txn.Out)
+}
+
 func TestCreateUnspent(t *testing.T) {
 	txn := Transaction{}
 	err := txn.PushOutput(genAddress, 11e6, 255)
@@ -301,6 +301,34 @@ func (txn *Transaction) PushOutput(dst cipher.Address, coins, hours uint64) erro
 	return nil
 }
 
+// SortInputsOutputs sorts the inputs and outputs into a canonical order, so that their order
+// does not reveal which inputs were selected first or which output is the change output.
+// Inputs are sorted by hash, outputs by address, then coins, then hours, comparing bytes.
+// The signatures would be invalidated, so an error is returned if the transaction has any.
+// UpdateHeader should be called afterwards to update the inner hash.
+func (txn *Transaction) SortInputsOutputs() error {
+	if txn.hasNonNullSignature() {
+		return errors.New("Transaction has been signed")
+	}
+
+	sort.Slice(txn.In, func(i, j int) bool {
+		return bytes.Compare(txn.In[i][:], txn.In[j][:]) < 0
+	})
+
+	sort.Slice(txn.Out, func(i, j int) bool {
+		a, b := txn.Out[i], txn.Out[j]
+		if c := bytes.Compare(a.Address.Bytes(), b.Address.Bytes()); c != 0 {
+			return c < 0
+		}
+		if a.Coins != b.Coins {
+			return a.Coins < b.Coins
+		}
+		return a.Hours < b.Hours
+	})
+
+	return nil
+}
+
 // SignInput signs a specific input in the transaction.
 // InnerHash should already be set to a valid value.
 // Returns an error if the input is already signed
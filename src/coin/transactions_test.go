@@ -334,6 +334,40 @@ func TestTransactionPushOutput(t *testing.T) {
 	testutil.RequireError(t, err, "Max transaction outputs reached")
 }
 
+func TestTransactionSortInputsOutputs(t *testing.T) {
+	txn, _ := makeTransactionMultipleInputs(t, 3)
+	err := txn.SortInputsOutputs()
+	testutil.RequireError(t, err, "Transaction has been signed")
+
+	h1 := cipher.SHA256{1}
+	h2 := cipher.SHA256{2}
+	h3 := cipher.SHA256{3}
+	a1 := cipher.Address{Version: 0, Key: cipher.Ripemd160{1}}
+	a2 := cipher.Address{Version: 0, Key: cipher.Ripemd160{2}}
+
+	txn = Transaction{
+		Sigs: make([]cipher.Sig, 3),
+		In:   []cipher.SHA256{h3, h1, h2},
+		Out: []TransactionOutput{
+			{Address: a2, Coins: 1, Hours: 1},
+			{Address: a1, Coins: 2, Hours: 1},
+			{Address: a1, Coins: 1, Hours: 2},
+			{Address: a1, Coins: 1, Hours: 1},
+		},
+	}
+
+	err = txn.SortInputsOutputs()
+	require.NoError(t, err)
+	require.Equal(t, []cipher.SHA256{h1, h2, h3}, txn.In)
+	require.Equal(t, []TransactionOutput{
+		{Address: a1, Coins: 1, Hours: 1},
+		{Address: a1, Coins: 1, Hours: 2},
+		{Address: a1, Coins: 2, Hours: 1},
+		{Address: a2, Coins: 1, Hours: 1},
+	}, txn.Out)
+	require.Len(t, txn.Sigs, 3)
+}
+
 func TestTransactionSignInput(t *testing.T) {
 	txn, seckeys := makeTransactionMultipleInputs(t, 3)
 	require.True(t, txn.IsFullySigned())
@@ -0,0 +1,164 @@
+package consensus
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+Signaling lets the block publisher change protocol parameters without redeploying the nodes.
+
+Each block header carries a 4 byte signaling field, in which the block publisher sets the bits
+of the parameter changes it supports. The field is the block header's Version, which is not
+otherwise validated and is 0 in all blocks created before signaling, so old nodes accept the
+signaling blocks.
+
+A SignalingDeployment maps a bit to a new value of a parameter. The blocks are grouped in rounds
+of RoundLength blocks. At each round boundary, the last SignalingWindow blocks before the boundary
+are counted, and if at least MinSignalingThreshold percent of them signal a deployment's bit,
+the deployment's parameter value applies to all blocks of the round.
+The signals are counted again at every boundary, so a change is reverted if the signals stop.
+*/
+
+// SignalingParameter is a protocol parameter that can be changed by signaling
+type SignalingParameter string
+
+const (
+	// SignalingMaxBlockSize is the maximum size of the transactions of a created block, in bytes
+	SignalingMaxBlockSize SignalingParameter = "max_block_size"
+	// SignalingMinFeePerByte is the minimum coin hour fee per byte of a transaction included in a created block
+	SignalingMinFeePerByte SignalingParameter = "min_fee_per_byte"
+	// SignalingBurnFactor is the burn factor applied to the transactions included in a created block
+	SignalingBurnFactor SignalingParameter = "burn_factor"
+
+	// SignalingFieldBits is the number of bits of the signaling field
+	SignalingFieldBits = 32
+)
+
+var (
+	// ErrSignalingWindowZero is returned by SignalingConfig.Verify if SignalingWindow is 0
+	ErrSignalingWindowZero = errors.New("SignalingWindow must be > 0")
+	// ErrSignalingRoundLengthZero is returned by SignalingConfig.Verify if RoundLength is 0
+	ErrSignalingRoundLengthZero = errors.New("RoundLength must be > 0")
+	// ErrSignalingThresholdRange is returned by SignalingConfig.Verify if MinSignalingThreshold is not between 1 and 100
+	ErrSignalingThresholdRange = errors.New("MinSignalingThreshold must be between 1 and 100")
+)
+
+// SignalingDeployment is a parameter change activated by signaling a bit of the signaling field
+type SignalingDeployment struct {
+	// Bit of the signaling field, from 0 to 31
+	Bit uint8
+	// Parameter to change
+	Parameter SignalingParameter
+	// Value of the parameter while the deployment is active
+	Value uint64
+}
+
+// SignalingConfig configures the parameter changes activated by signaling
+type SignalingConfig struct {
+	// Number of blocks before a round boundary whose signals are counted
+	SignalingWindow uint64
+	// Percent of the blocks of the window that must signal a deployment to activate it
+	MinSignalingThreshold uint8
+	// Number of blocks in a round. Deployments are only activated or reverted at round boundaries
+	RoundLength uint64
+	// Parameter changes that can be signaled
+	Deployments []SignalingDeployment
+}
+
+// NewSignalingConfig creates a SignalingConfig with the default thresholds and no deployments
+func NewSignalingConfig() SignalingConfig {
+	return SignalingConfig{
+		SignalingWindow:       1000,
+		MinSignalingThreshold: 95,
+		RoundLength:           1000,
+	}
+}
+
+// Verify checks the thresholds and that each bit and parameter is used by a single deployment
+func (c SignalingConfig) Verify() error {
+	if c.SignalingWindow == 0 {
+		return ErrSignalingWindowZero
+	}
+
+	if c.RoundLength == 0 {
+		return ErrSignalingRoundLengthZero
+	}
+
+	if c.MinSignalingThreshold == 0 || c.MinSignalingThreshold > 100 {
+		return ErrSignalingThresholdRange
+	}
+
+	bits := make(map[uint8]struct{}, len(c.Deployments))
+	parameters := make(map[SignalingParameter]struct{}, len(c.Deployments))
+	for _, d := range c.Deployments {
+		if d.Bit >= SignalingFieldBits {
+			return fmt.Errorf("signaling bit %d is out of range, must be < %d", d.Bit, SignalingFieldBits)
+		}
+
+		switch d.Parameter {
+		case SignalingMaxBlockSize, SignalingMinFeePerByte, SignalingBurnFactor:
+		default:
+			return fmt.Errorf("unknown signaling parameter %q", d.Parameter)
+		}
+
+		if _, ok := bits[d.Bit]; ok {
+			return fmt.Errorf("signaling bit %d is used by more than one deployment", d.Bit)
+		}
+		bits[d.Bit] = struct{}{}
+
+		if _, ok := parameters[d.Parameter]; ok {
+			return fmt.Errorf("signaling parameter %q is changed by more than one deployment", d.Parameter)
+		}
+		parameters[d.Parameter] = struct{}{}
+	}
+
+	return nil
+}
+
+// SignalBit returns the signaling field with only the given bit set
+func SignalBit(bit uint8) uint32 {
+	return 1 << bit
+}
+
+// Signals returns true if the signaling field has the bit set
+func Signals(field uint32, bit uint8) bool {
+	return field&SignalBit(bit) != 0
+}
+
+// RoundStart returns the sequence of the first block of the round of the block at seq
+func (c SignalingConfig) RoundStart(seq uint64) uint64 {
+	return seq - seq%c.RoundLength
+}
+
+// WindowRange returns the range [start, end) of the sequences of the blocks whose signals decide
+// the active deployments of the block at seq. The range is shorter than the window up to the first
+// round boundary that has SignalingWindow blocks before it.
+func (c SignalingConfig) WindowRange(seq uint64) (uint64, uint64) {
+	end := c.RoundStart(seq)
+	if end < c.SignalingWindow {
+		return 0, end
+	}
+	return end - c.SignalingWindow, end
+}
+
+// ActiveDeployments returns the deployments signaled by at least MinSignalingThreshold percent of
+// the SignalingWindow blocks of a window, given the signaling fields of the blocks of the window.
+// If fewer fields than SignalingWindow are given, the missing blocks count as not signaling.
+func (c SignalingConfig) ActiveDeployments(fields []uint32) []SignalingDeployment {
+	var active []SignalingDeployment
+	for _, d := range c.Deployments {
+		var n uint64
+		for _, f := range fields {
+			if Signals(f, d.Bit) {
+				n++
+			}
+		}
+
+		if n*100 >= uint64(c.MinSignalingThreshold)*c.SignalingWindow {
+			active = append(active, d)
+		}
+	}
+
+	return active
+}
@@ -0,0 +1,178 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignalingConfigVerify(t *testing.T) {
+	require.NoError(t, NewSignalingConfig().Verify())
+
+	cases := []struct {
+		name string
+		f    func(c *SignalingConfig)
+		err  string
+	}{
+		{
+			name: "valid deployments",
+			f: func(c *SignalingConfig) {
+				c.Deployments = []SignalingDeployment{
+					{Bit: 0, Parameter: SignalingMaxBlockSize, Value: 65536},
+					{Bit: 31, Parameter: SignalingBurnFactor, Value: 20},
+				}
+			},
+		},
+		{
+			name: "window zero",
+			f: func(c *SignalingConfig) {
+				c.SignalingWindow = 0
+			},
+			err: ErrSignalingWindowZero.Error(),
+		},
+		{
+			name: "round length zero",
+			f: func(c *SignalingConfig) {
+				c.RoundLength = 0
+			},
+			err: ErrSignalingRoundLengthZero.Error(),
+		},
+		{
+			name: "threshold zero",
+			f: func(c *SignalingConfig) {
+				c.MinSignalingThreshold = 0
+			},
+			err: ErrSignalingThresholdRange.Error(),
+		},
+		{
+			name: "threshold over 100",
+			f: func(c *SignalingConfig) {
+				c.MinSignalingThreshold = 101
+			},
+			err: ErrSignalingThresholdRange.Error(),
+		},
+		{
+			name: "bit out of range",
+			f: func(c *SignalingConfig) {
+				c.Deployments = []SignalingDeployment{
+					{Bit: 32, Parameter: SignalingMaxBlockSize, Value: 65536},
+				}
+			},
+			err: "signaling bit 32 is out of range, must be < 32",
+		},
+		{
+			name: "unknown parameter",
+			f: func(c *SignalingConfig) {
+				c.Deployments = []SignalingDeployment{
+					{Bit: 1, Parameter: "foo", Value: 1},
+				}
+			},
+			err: `unknown signaling parameter "foo"`,
+		},
+		{
+			name: "duplicate bit",
+			f: func(c *SignalingConfig) {
+				c.Deployments = []SignalingDeployment{
+					{Bit: 1, Parameter: SignalingMaxBlockSize, Value: 65536},
+					{Bit: 1, Parameter: SignalingBurnFactor, Value: 20},
+				}
+			},
+			err: "signaling bit 1 is used by more than one deployment",
+		},
+		{
+			name: "duplicate parameter",
+			f: func(c *SignalingConfig) {
+				c.Deployments = []SignalingDeployment{
+					{Bit: 1, Parameter: SignalingMinFeePerByte, Value: 1},
+					{Bit: 2, Parameter: SignalingMinFeePerByte, Value: 2},
+				}
+			},
+			err: `signaling parameter "min_fee_per_byte" is changed by more than one deployment`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewSignalingConfig()
+			tc.f(&c)
+			err := c.Verify()
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestSignals(t *testing.T) {
+	require.Equal(t, uint32(1), SignalBit(0))
+	require.Equal(t, uint32(0x80000000), SignalBit(31))
+
+	field := SignalBit(3) | SignalBit(5)
+	require.True(t, Signals(field, 3))
+	require.True(t, Signals(field, 5))
+	require.False(t, Signals(field, 4))
+	require.False(t, Signals(0, 0))
+}
+
+func TestSignalingWindowRange(t *testing.T) {
+	c := SignalingConfig{
+		SignalingWindow:       5,
+		MinSignalingThreshold: 80,
+		RoundLength:           10,
+	}
+
+	cases := []struct {
+		seq        uint64
+		roundStart uint64
+		start      uint64
+		end        uint64
+	}{
+		{seq: 0, roundStart: 0, start: 0, end: 0},
+		{seq: 9, roundStart: 0, start: 0, end: 0},
+		{seq: 10, roundStart: 10, start: 5, end: 10},
+		{seq: 19, roundStart: 10, start: 5, end: 10},
+		{seq: 25, roundStart: 20, start: 15, end: 20},
+	}
+
+	for _, tc := range cases {
+		require.Equal(t, tc.roundStart, c.RoundStart(tc.seq), "seq %d", tc.seq)
+		start, end := c.WindowRange(tc.seq)
+		require.Equal(t, tc.start, start, "seq %d", tc.seq)
+		require.Equal(t, tc.end, end, "seq %d", tc.seq)
+	}
+
+	// The window is shortened until a round boundary has enough blocks before it
+	c.SignalingWindow = 15
+	start, end := c.WindowRange(12)
+	require.Equal(t, uint64(0), start)
+	require.Equal(t, uint64(10), end)
+}
+
+func TestActiveDeployments(t *testing.T) {
+	blockSize := SignalingDeployment{Bit: 0, Parameter: SignalingMaxBlockSize, Value: 65536}
+	burnFactor := SignalingDeployment{Bit: 1, Parameter: SignalingBurnFactor, Value: 20}
+
+	c := SignalingConfig{
+		SignalingWindow:       5,
+		MinSignalingThreshold: 80,
+		RoundLength:           10,
+		Deployments:           []SignalingDeployment{blockSize, burnFactor},
+	}
+
+	both := SignalBit(0) | SignalBit(1)
+
+	require.Empty(t, c.ActiveDeployments(nil))
+	require.Empty(t, c.ActiveDeployments([]uint32{0, 0, 0, 0, 0}))
+
+	// 4 of 5 blocks reach the 80% threshold, 3 of 5 don't
+	require.Equal(t, []SignalingDeployment{blockSize}, c.ActiveDeployments([]uint32{both, SignalBit(0), both, SignalBit(0), SignalBit(1)}))
+	require.Equal(t, []SignalingDeployment{blockSize, burnFactor}, c.ActiveDeployments([]uint32{both, both, both, both, 0}))
+
+	// A short window counts the missing blocks as not signaling
+	require.Empty(t, c.ActiveDeployments([]uint32{both, both, both}))
+
+	// Unknown bits are ignored
+	require.Empty(t, c.ActiveDeployments([]uint32{SignalBit(7), SignalBit(7), SignalBit(7), SignalBit(7), SignalBit(7)}))
+}
@@ -0,0 +1,87 @@
+package daemon
+
+import (
+	"sync"
+	"time"
+)
+
+// maxAnnouncedBlocks is the maximum number of block requests remembered by an announcedBlocksCache
+const maxAnnouncedBlocks = 1024
+
+// blockRequest is a request for the blocks up to an announced seq, sent to the peer that announced it
+type blockRequest struct {
+	addr string
+	sent time.Time
+}
+
+// announcedBlocksCache records the block requests sent in response to block announcements, so that
+// the announcements of the same block seq by any peer are dropped while a request for it is outstanding.
+// A request is outstanding until the peer it was sent to replies with blocks or disconnects, or until it expires.
+// A peer that announces a block it does not send only suppresses the announcements of other peers
+// until its request expires.
+type announcedBlocksCache struct {
+	sync.Mutex
+	// How long a request is outstanding if it is not answered. If 0, requests are not remembered
+	expiry   time.Duration
+	requests map[uint64]blockRequest
+}
+
+func newAnnouncedBlocksCache(expiry time.Duration) *announcedBlocksCache {
+	return &announcedBlocksCache{
+		expiry:   expiry,
+		requests: make(map[uint64]blockRequest),
+	}
+}
+
+// outstanding returns true if a request for the blocks up to seq was sent less than expiry ago and not answered
+func (c *announcedBlocksCache) outstanding(seq uint64, now time.Time) bool {
+	if c.expiry <= 0 {
+		return false
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	r, ok := c.requests[seq]
+	return ok && now.Sub(r.sent) < c.expiry
+}
+
+// add records a request for the blocks up to seq sent to addr.
+// Expired requests are removed when the cache is full. If the cache is still full, the request is not recorded.
+func (c *announcedBlocksCache) add(seq uint64, addr string, now time.Time) {
+	if c.expiry <= 0 {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if _, ok := c.requests[seq]; !ok && len(c.requests) >= maxAnnouncedBlocks {
+		for k, r := range c.requests {
+			if now.Sub(r.sent) >= c.expiry {
+				delete(c.requests, k)
+			}
+		}
+
+		if len(c.requests) >= maxAnnouncedBlocks {
+			return
+		}
+	}
+
+	c.requests[seq] = blockRequest{
+		addr: addr,
+		sent: now,
+	}
+}
+
+// remove removes the requests sent to addr, after addr answered them or disconnected
+func (c *announcedBlocksCache) remove(addr string) {
+	c.Lock()
+	defer c.Unlock()
+
+	for k, r := range c.requests {
+		if r.addr == addr {
+			delete(c.requests, k)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnouncedBlocksCache(t *testing.T) {
+	now := time.Now().UTC()
+	c := newAnnouncedBlocksCache(10 * time.Second)
+
+	addr := "1.2.3.4:6000"
+
+	require.False(t, c.outstanding(10, now))
+	c.add(10, addr, now)
+	require.True(t, c.outstanding(10, now.Add(time.Millisecond)))
+	require.False(t, c.outstanding(11, now.Add(time.Second)))
+	c.add(11, addr, now.Add(time.Second))
+	require.True(t, c.outstanding(10, now.Add(9*time.Second)))
+
+	// The request for 10 expires, the request for 11 does not
+	require.False(t, c.outstanding(10, now.Add(10*time.Second)))
+	require.True(t, c.outstanding(11, now.Add(10*time.Second)))
+	require.Len(t, c.requests, 2)
+
+	// The requests are removed once the peer answered them
+	c.remove(addr)
+	require.False(t, c.outstanding(11, now.Add(10*time.Second)))
+	require.Empty(t, c.requests)
+
+	// A cache with no expiry does not remember requests
+	c = newAnnouncedBlocksCache(0)
+	c.add(10, addr, now)
+	require.False(t, c.outstanding(10, now))
+	require.Empty(t, c.requests)
+}
+
+func TestAnnouncedBlocksCacheTwoPeers(t *testing.T) {
+	now := time.Now().UTC()
+	c := newAnnouncedBlocksCache(10 * time.Second)
+
+	addr := "1.2.3.4:6000"
+	otherAddr := "5.6.7.8:6000"
+
+	// The first peer announces 10 and is sent a request
+	require.False(t, c.outstanding(10, now))
+	c.add(10, addr, now)
+
+	// The announcement of 10 by the second peer is dropped while the request is outstanding
+	require.True(t, c.outstanding(10, now.Add(time.Second)))
+
+	// The announcement of 11 by the second peer is not dropped
+	require.False(t, c.outstanding(11, now.Add(time.Second)))
+	c.add(11, otherAddr, now.Add(time.Second))
+
+	// A reply of the second peer does not answer the request sent to the first peer
+	c.remove(otherAddr)
+	require.True(t, c.outstanding(10, now.Add(2*time.Second)))
+	require.False(t, c.outstanding(11, now.Add(2*time.Second)))
+
+	// The first peer disconnects without sending the block, so the announcement of 10
+	// by the second peer is not dropped
+	c.remove(addr)
+	require.False(t, c.outstanding(10, now.Add(3*time.Second)))
+	c.add(10, otherAddr, now.Add(3*time.Second))
+	require.True(t, c.outstanding(10, now.Add(4*time.Second)))
+}
+
+func TestAnnouncedBlocksCacheFull(t *testing.T) {
+	now := time.Now().UTC()
+	c := newAnnouncedBlocksCache(10 * time.Second)
+
+	for i := 0; i < maxAnnouncedBlocks; i++ {
+		c.add(uint64(i), "1.2.3.4:6000", now)
+	}
+	require.Len(t, c.requests, maxAnnouncedBlocks)
+
+	// Requests are not recorded while the cache is full of unexpired requests
+	c.add(maxAnnouncedBlocks, "1.2.3.4:6000", now.Add(time.Second))
+	require.False(t, c.outstanding(maxAnnouncedBlocks, now.Add(time.Second)))
+	require.Len(t, c.requests, maxAnnouncedBlocks)
+
+	// A request for a recorded seq replaces it
+	c.add(0, "5.6.7.8:6000", now.Add(time.Second))
+	require.Equal(t, "5.6.7.8:6000", c.requests[0].addr)
+	require.Len(t, c.requests, maxAnnouncedBlocks)
+
+	// Expired requests are removed once the cache is full
+	c.add(maxAnnouncedBlocks, "1.2.3.4:6000", now.Add(10*time.Second))
+	require.Len(t, c.requests, 2)
+	require.True(t, c.outstanding(maxAnnouncedBlocks, now.Add(11*time.Second)))
+}
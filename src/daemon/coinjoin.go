@@ -0,0 +1,438 @@
+package daemon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon/gnet"
+	"github.com/skycoin/skycoin/src/util/fee"
+)
+
+/*
+CoinJoin combines the inputs and outputs of several participants into a single transaction,
+so that an observer can't tell which inputs paid for which outputs.
+
+A node started with DaemonConfig.Coordinator coordinates the rounds:
+
+	1. Participants connect to the coordinator and send a JoinRequestMessage with their inputs and their output.
+	2. When CoordinatorMinParticipants requests are collected, the coordinator assembles the
+	   unsigned transaction and sends it to every participant in a PartialTxMessage.
+	3. Each participant checks that the transaction spends its inputs to its output,
+	   and sends back a SignedInputMessage for each of its inputs.
+	4. When all inputs are signed, the coordinator injects and broadcasts the transaction.
+
+A round is abandoned if it is not signed within CoordinatorRoundTimeout, or if a participant disconnects.
+The timeout is checked every CoordinatorExpireRate by the daemon run loop.
+Participants don't have a change output, the coins of their inputs must equal the coins of their output.
+*/
+
+var (
+	// ErrCoinJoinNotCoordinator is returned if a coinjoin request is sent to a node that is not a coordinator
+	ErrCoinJoinNotCoordinator = errors.New("Node is not a coinjoin coordinator")
+	// ErrCoinJoinCoordinatorNotIntroduced is returned if a join request is sent to a coordinator before its introduction
+	ErrCoinJoinCoordinatorNotIntroduced = errors.New("Coordinator connection has not introduced itself")
+	// ErrCoinJoinRoundInProgress is returned if a join request is received while the current round is being signed
+	ErrCoinJoinRoundInProgress = errors.New("Coinjoin round is being signed, try again later")
+	// ErrCoinJoinNoInputs is returned if a join request has no inputs
+	ErrCoinJoinNoInputs = errors.New("Coinjoin request has no inputs")
+	// ErrCoinJoinDuplicateInput is returned if an input of a join request is already part of the round
+	ErrCoinJoinDuplicateInput = errors.New("Coinjoin request input is already in the round")
+	// ErrCoinJoinInvalidOutput is returned if the output of a join request is sent to the null address or has no coins
+	ErrCoinJoinInvalidOutput = errors.New("Coinjoin request output is invalid")
+	// ErrCoinJoinCoinsMismatch is returned if the input coins of a join request don't equal its output coins
+	ErrCoinJoinCoinsMismatch = errors.New("Coinjoin request input coins must equal output coins")
+	// ErrCoinJoinNotRequested is returned if a partial transaction is received from a node that we did not send a join request to
+	ErrCoinJoinNotRequested = errors.New("No coinjoin request was sent to this coordinator")
+	// ErrCoinJoinUnknownTransaction is returned if a signed input does not belong to the round that is being signed
+	ErrCoinJoinUnknownTransaction = errors.New("Signed input does not belong to the coinjoin round")
+	// ErrCoinJoinInvalidSignedInput is returned if a signed input is not an input of the participant, or the signature is invalid
+	ErrCoinJoinInvalidSignedInput = errors.New("Signed input is invalid")
+	// ErrCoinJoinInvalidPartialTx is returned if a partial transaction does not spend the participant's inputs to its output
+	ErrCoinJoinInvalidPartialTx = errors.New("Coinjoin transaction does not match the join request")
+)
+
+// coinJoinBackend is used by the coinjoin coordinator to access the network and the blockchain
+type coinJoinBackend interface {
+	sendMessage(addr string, msg gnet.Message) error
+	coinJoinInputs(hashes []cipher.SHA256) (coin.UxArray, uint64, error)
+	InjectBroadcastTransaction(txn coin.Transaction) error
+}
+
+// coinJoinRequest is a join request accepted into the current round
+type coinJoinRequest struct {
+	addr   string
+	inputs coin.UxArray
+	output coin.TransactionOutput
+}
+
+// coinJoinCoordinator collects join requests and assembles, signs and broadcasts coinjoin transactions.
+// It is only accessed from the daemon run loop.
+type coinJoinCoordinator struct {
+	minParticipants int
+	roundTimeout    time.Duration
+	// burnFactor is the burn factor of the unconfirmed pool, which the joined transaction is injected to
+	burnFactor uint32
+	backend    coinJoinBackend
+
+	requests []coinJoinRequest
+	// txn is the transaction being signed, nil while requests are collected
+	txn *coin.Transaction
+	// owners maps the inputs of txn to the address of the participant that owns them
+	owners map[cipher.SHA256]coinJoinInputOwner
+	// signingStartedAt is the time that txn was sent to the participants
+	signingStartedAt time.Time
+}
+
+type coinJoinInputOwner struct {
+	addr    string
+	address cipher.Address
+	index   int
+}
+
+func newCoinJoinCoordinator(minParticipants int, roundTimeout time.Duration, burnFactor uint32, backend coinJoinBackend) *coinJoinCoordinator {
+	return &coinJoinCoordinator{
+		minParticipants: minParticipants,
+		roundTimeout:    roundTimeout,
+		burnFactor:      burnFactor,
+		backend:         backend,
+	}
+}
+
+// reset abandons the current round
+func (c *coinJoinCoordinator) reset() {
+	c.requests = nil
+	c.txn = nil
+	c.owners = nil
+	c.signingStartedAt = time.Time{}
+}
+
+// onJoinRequest adds a join request to the current round.
+// If the round has enough participants, the transaction is assembled and sent to the participants for signing.
+func (c *coinJoinCoordinator) onJoinRequest(addr string, m *JoinRequestMessage, now time.Time) error {
+	if c.txn != nil {
+		return ErrCoinJoinRoundInProgress
+	}
+
+	if len(m.Inputs) == 0 {
+		return ErrCoinJoinNoInputs
+	}
+
+	if m.Output.Address.Null() || m.Output.Coins == 0 {
+		return ErrCoinJoinInvalidOutput
+	}
+
+	// A participant can replace its own request, the inputs of the previous request are not duplicates
+	requests := make([]coinJoinRequest, 0, len(c.requests)+1)
+	seen := make(map[cipher.SHA256]struct{})
+	for _, r := range c.requests {
+		if r.addr == addr {
+			continue
+		}
+		requests = append(requests, r)
+		for _, ux := range r.inputs {
+			seen[ux.Hash()] = struct{}{}
+		}
+	}
+
+	for _, h := range m.Inputs {
+		if _, ok := seen[h]; ok {
+			return ErrCoinJoinDuplicateInput
+		}
+		seen[h] = struct{}{}
+	}
+
+	inputs, headTime, err := c.backend.coinJoinInputs(m.Inputs)
+	if err != nil {
+		return err
+	}
+
+	coins, err := inputs.Coins()
+	if err != nil {
+		return err
+	}
+	if coins != m.Output.Coins {
+		return ErrCoinJoinCoinsMismatch
+	}
+
+	// Each participant pays the fee for its own inputs, so that the combined transaction pays enough
+	hours, err := inputs.CoinHours(headTime)
+	if err != nil {
+		return err
+	}
+	if m.Output.Hours > hours {
+		return fee.ErrTxnInsufficientCoinHours
+	}
+	if err := fee.VerifyTransactionFeeForHours(m.Output.Hours, hours-m.Output.Hours, c.burnFactor); err != nil {
+		return err
+	}
+
+	c.requests = append(requests, coinJoinRequest{
+		addr:   addr,
+		inputs: inputs,
+		output: m.Output,
+	})
+
+	logger.WithFields(logrus.Fields{
+		"addr":         addr,
+		"participants": len(c.requests),
+	}).Info("Coinjoin request accepted")
+
+	if len(c.requests) < c.minParticipants {
+		return nil
+	}
+
+	return c.startSigning(now)
+}
+
+// expire abandons the round being signed if it was not signed within the round timeout.
+// The join requests of the round are removed, the participants have to send new ones.
+func (c *coinJoinCoordinator) expire(now time.Time) {
+	if c.txn == nil || now.Sub(c.signingStartedAt) < c.roundTimeout {
+		return
+	}
+
+	logger.WithField("txid", c.txn.Hash().Hex()).Info("Coinjoin round timed out, abandoning it")
+	c.reset()
+}
+
+// startSigning assembles the transaction of the round and sends it to the participants
+func (c *coinJoinCoordinator) startSigning(now time.Time) error {
+	txn, owners, err := newCoinJoinTransaction(c.requests)
+	if err != nil {
+		c.reset()
+		return err
+	}
+
+	c.txn = txn
+	c.owners = owners
+	c.signingStartedAt = now
+
+	logger.WithFields(logrus.Fields{
+		"participants": len(c.requests),
+		"inputs":       len(txn.In),
+	}).Info("Coinjoin round is full, sending the transaction for signing")
+
+	for _, r := range c.requests {
+		if err := c.backend.sendMessage(r.addr, NewPartialTxMessage(*txn)); err != nil {
+			logger.WithError(err).WithField("addr", r.addr).Error("Send PartialTxMessage failed")
+		}
+	}
+
+	return nil
+}
+
+// newCoinJoinTransaction creates the unsigned transaction of a round.
+// Inputs and outputs are sorted, so that their order does not reveal which participant they belong to.
+func newCoinJoinTransaction(requests []coinJoinRequest) (*coin.Transaction, map[cipher.SHA256]coinJoinInputOwner, error) {
+	var inputs []coinJoinInputOwner
+	var hashes []cipher.SHA256
+	var outputs []coin.TransactionOutput
+	for _, r := range requests {
+		for _, ux := range r.inputs {
+			hashes = append(hashes, ux.Hash())
+			inputs = append(inputs, coinJoinInputOwner{
+				addr:    r.addr,
+				address: ux.Body.Address,
+			})
+		}
+		outputs = append(outputs, r.output)
+	}
+
+	sort.Sort(coinJoinInputsByHash{hashes: hashes, owners: inputs})
+
+	sort.Slice(outputs, func(i, j int) bool {
+		a, b := outputs[i], outputs[j]
+		if c := bytes.Compare(a.Address.Bytes(), b.Address.Bytes()); c != 0 {
+			return c < 0
+		}
+		if a.Coins != b.Coins {
+			return a.Coins < b.Coins
+		}
+		return a.Hours < b.Hours
+	})
+
+	txn := &coin.Transaction{}
+	owners := make(map[cipher.SHA256]coinJoinInputOwner, len(hashes))
+	for i, h := range hashes {
+		if err := txn.PushInput(h); err != nil {
+			return nil, nil, err
+		}
+
+		o := inputs[i]
+		o.index = i
+		owners[h] = o
+	}
+
+	for _, o := range outputs {
+		if err := txn.PushOutput(o.Address, o.Coins, o.Hours); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	txn.Sigs = make([]cipher.Sig, len(txn.In))
+	if err := txn.UpdateHeader(); err != nil {
+		return nil, nil, err
+	}
+
+	return txn, owners, nil
+}
+
+// coinJoinInputsByHash sorts input hashes together with their owners
+type coinJoinInputsByHash struct {
+	hashes []cipher.SHA256
+	owners []coinJoinInputOwner
+}
+
+func (s coinJoinInputsByHash) Len() int {
+	return len(s.hashes)
+}
+
+func (s coinJoinInputsByHash) Less(i, j int) bool {
+	return bytes.Compare(s.hashes[i][:], s.hashes[j][:]) < 0
+}
+
+func (s coinJoinInputsByHash) Swap(i, j int) {
+	s.hashes[i], s.hashes[j] = s.hashes[j], s.hashes[i]
+	s.owners[i], s.owners[j] = s.owners[j], s.owners[i]
+}
+
+// onSignedInput adds the signature of an input to the transaction being signed.
+// Once all inputs are signed, the transaction is injected and broadcast.
+func (c *coinJoinCoordinator) onSignedInput(addr string, m *SignedInputMessage) error {
+	if c.txn == nil || c.txn.InnerHash != m.InnerHash {
+		return ErrCoinJoinUnknownTransaction
+	}
+
+	o, ok := c.owners[m.Input]
+	if !ok || o.addr != addr {
+		return ErrCoinJoinInvalidSignedInput
+	}
+
+	if err := cipher.VerifyAddressSignedHash(o.address, m.Sig, cipher.AddSHA256(m.InnerHash, m.Input)); err != nil {
+		return ErrCoinJoinInvalidSignedInput
+	}
+
+	c.txn.Sigs[o.index] = m.Sig
+
+	if !c.txn.IsFullySigned() {
+		return nil
+	}
+
+	txn := *c.txn
+	c.reset()
+
+	if err := c.backend.InjectBroadcastTransaction(txn); err != nil {
+		return fmt.Errorf("InjectBroadcastTransaction of coinjoin transaction %s failed: %v", txn.Hash().Hex(), err)
+	}
+
+	logger.WithField("txid", txn.Hash().Hex()).Info("Coinjoin transaction broadcast")
+	return nil
+}
+
+// onDisconnect abandons the current round if a participant disconnects during signing,
+// otherwise only the participant's request is removed
+func (c *coinJoinCoordinator) onDisconnect(addr string) {
+	for i, r := range c.requests {
+		if r.addr != addr {
+			continue
+		}
+
+		if c.txn != nil {
+			logger.WithField("addr", addr).Info("Coinjoin participant disconnected during signing, abandoning the round")
+			c.reset()
+			return
+		}
+
+		c.requests = append(c.requests[:i], c.requests[i+1:]...)
+		return
+	}
+}
+
+// coinJoinJoin is a join request that this node sent to a coordinator
+type coinJoinJoin struct {
+	inputs []cipher.SHA256
+	keys   []cipher.SecKey
+	output coin.TransactionOutput
+}
+
+// coinJoinParticipant keeps the join requests that this node sent to coordinators,
+// to sign the coinjoin transactions that the coordinators send back
+type coinJoinParticipant struct {
+	sync.Mutex
+	joins map[string]coinJoinJoin
+}
+
+func newCoinJoinParticipant() *coinJoinParticipant {
+	return &coinJoinParticipant{
+		joins: make(map[string]coinJoinJoin),
+	}
+}
+
+// add records a join request sent to a coordinator, replacing any previous request
+func (p *coinJoinParticipant) add(addr string, j coinJoinJoin) {
+	p.Lock()
+	defer p.Unlock()
+	p.joins[addr] = j
+}
+
+// remove removes the join request sent to a coordinator
+func (p *coinJoinParticipant) remove(addr string) {
+	p.Lock()
+	defer p.Unlock()
+	delete(p.joins, addr)
+}
+
+// sign checks that a coinjoin transaction spends the inputs of the join request sent to the coordinator
+// at addr to its output, and returns a SignedInputMessage for each input.
+// The join request is removed, a new one has to be sent for the next round.
+func (p *coinJoinParticipant) sign(addr string, txn coin.Transaction) ([]*SignedInputMessage, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	j, ok := p.joins[addr]
+	if !ok {
+		return nil, ErrCoinJoinNotRequested
+	}
+
+	if txn.InnerHash != txn.HashInner() {
+		return nil, ErrCoinJoinInvalidPartialTx
+	}
+
+	hasOutput := false
+	for _, o := range txn.Out {
+		if o == j.output {
+			hasOutput = true
+			break
+		}
+	}
+	if !hasOutput {
+		return nil, ErrCoinJoinInvalidPartialTx
+	}
+
+	inputs := make(map[cipher.SHA256]struct{}, len(txn.In))
+	for _, h := range txn.In {
+		inputs[h] = struct{}{}
+	}
+
+	msgs := make([]*SignedInputMessage, len(j.inputs))
+	for i, h := range j.inputs {
+		if _, ok := inputs[h]; !ok {
+			return nil, ErrCoinJoinInvalidPartialTx
+		}
+
+		sig := cipher.MustSignHash(cipher.AddSHA256(txn.InnerHash, h), j.keys[i])
+		msgs[i] = NewSignedInputMessage(txn.InnerHash, h, sig)
+	}
+
+	delete(p.joins, addr)
+
+	return msgs, nil
+}
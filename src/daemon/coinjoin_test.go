@@ -0,0 +1,298 @@
+package daemon
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon/gnet"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/util/fee"
+)
+
+type fakeCoinJoinBackend struct {
+	unspent  map[cipher.SHA256]coin.UxOut
+	headTime uint64
+	sent     map[string][]gnet.Message
+	injected []coin.Transaction
+}
+
+func newFakeCoinJoinBackend() *fakeCoinJoinBackend {
+	return &fakeCoinJoinBackend{
+		unspent:  make(map[cipher.SHA256]coin.UxOut),
+		headTime: 1000,
+		sent:     make(map[string][]gnet.Message),
+	}
+}
+
+func (b *fakeCoinJoinBackend) sendMessage(addr string, msg gnet.Message) error {
+	b.sent[addr] = append(b.sent[addr], msg)
+	return nil
+}
+
+func (b *fakeCoinJoinBackend) coinJoinInputs(hashes []cipher.SHA256) (coin.UxArray, uint64, error) {
+	uxa := make(coin.UxArray, len(hashes))
+	for i, h := range hashes {
+		ux, ok := b.unspent[h]
+		if !ok {
+			return nil, 0, errors.New("unspent output does not exist")
+		}
+		uxa[i] = ux
+	}
+	return uxa, b.headTime, nil
+}
+
+func (b *fakeCoinJoinBackend) InjectBroadcastTransaction(txn coin.Transaction) error {
+	b.injected = append(b.injected, txn)
+	return nil
+}
+
+// addUnspent adds an unspent output owned by a new key to the backend
+func (b *fakeCoinJoinBackend) addUnspent(t *testing.T, coins, hours uint64) (cipher.SHA256, cipher.SecKey) {
+	p, s := cipher.GenerateKeyPair()
+	ux := coin.UxOut{
+		Head: coin.UxHead{
+			Time: b.headTime,
+		},
+		Body: coin.UxBody{
+			SrcTransaction: testutil.RandSHA256(t),
+			Address:        cipher.AddressFromPubKey(p),
+			Coins:          coins,
+			Hours:          hours,
+		},
+	}
+	b.unspent[ux.Hash()] = ux
+	return ux.Hash(), s
+}
+
+func TestCoinJoinRound(t *testing.T) {
+	b := newFakeCoinJoinBackend()
+	c := newCoinJoinCoordinator(2, time.Minute, params.UserVerifyTxn.BurnFactor, b)
+	now := time.Now()
+
+	participants := []string{"1.1.1.1:6000", "2.2.2.2:6000"}
+	joins := make(map[string]coinJoinJoin)
+	for _, addr := range participants {
+		h, s := b.addUnspent(t, 1e6, 100)
+		j := coinJoinJoin{
+			inputs: []cipher.SHA256{h},
+			keys:   []cipher.SecKey{s},
+			output: coin.TransactionOutput{
+				Address: testutil.MakeAddress(),
+				Coins:   1e6,
+				Hours:   50,
+			},
+		}
+		joins[addr] = j
+
+		err := c.onJoinRequest(addr, NewJoinRequestMessage(j.inputs, j.output), now)
+		require.NoError(t, err)
+	}
+
+	// The round is full, the transaction was sent to every participant
+	require.NotNil(t, c.txn)
+	require.Len(t, c.txn.In, 2)
+	require.Len(t, c.txn.Out, 2)
+
+	// Requests are rejected while the round is being signed
+	h, _ := b.addUnspent(t, 1e6, 100)
+	err := c.onJoinRequest("3.3.3.3:6000", NewJoinRequestMessage([]cipher.SHA256{h}, coin.TransactionOutput{
+		Address: testutil.MakeAddress(),
+		Coins:   1e6,
+	}), now)
+	require.Equal(t, ErrCoinJoinRoundInProgress, err)
+
+	p := newCoinJoinParticipant()
+	for _, addr := range participants {
+		p.add(addr, joins[addr])
+	}
+
+	for i, addr := range participants {
+		require.Len(t, b.sent[addr], 1)
+		ptm, ok := b.sent[addr][0].(*PartialTxMessage)
+		require.True(t, ok)
+
+		msgs, err := p.sign(addr, ptm.Transaction)
+		require.NoError(t, err)
+		require.Len(t, msgs, 1)
+
+		// A participant can't sign the input of another participant
+		err = c.onSignedInput(participants[(i+1)%2], msgs[0])
+		require.Equal(t, ErrCoinJoinInvalidSignedInput, err)
+
+		err = c.onSignedInput(addr, msgs[0])
+		require.NoError(t, err)
+	}
+
+	// The fully signed transaction was broadcast and the round reset
+	require.Len(t, b.injected, 1)
+	require.NoError(t, b.injected[0].Verify())
+	require.Nil(t, c.txn)
+	require.Empty(t, c.requests)
+}
+
+func TestCoinJoinRequestErrors(t *testing.T) {
+	b := newFakeCoinJoinBackend()
+	c := newCoinJoinCoordinator(3, time.Minute, params.UserVerifyTxn.BurnFactor, b)
+	now := time.Now()
+	addr := "1.1.1.1:6000"
+
+	h, _ := b.addUnspent(t, 1e6, 100)
+	output := coin.TransactionOutput{
+		Address: testutil.MakeAddress(),
+		Coins:   1e6,
+		Hours:   50,
+	}
+
+	err := c.onJoinRequest(addr, NewJoinRequestMessage(nil, output), now)
+	require.Equal(t, ErrCoinJoinNoInputs, err)
+
+	err = c.onJoinRequest(addr, NewJoinRequestMessage([]cipher.SHA256{h}, coin.TransactionOutput{Coins: 1e6}), now)
+	require.Equal(t, ErrCoinJoinInvalidOutput, err)
+
+	err = c.onJoinRequest(addr, NewJoinRequestMessage([]cipher.SHA256{h, h}, output), now)
+	require.Equal(t, ErrCoinJoinDuplicateInput, err)
+
+	badCoins := output
+	badCoins.Coins = 2e6
+	err = c.onJoinRequest(addr, NewJoinRequestMessage([]cipher.SHA256{h}, badCoins), now)
+	require.Equal(t, ErrCoinJoinCoinsMismatch, err)
+
+	noFee := output
+	noFee.Hours = 100
+	err = c.onJoinRequest(addr, NewJoinRequestMessage([]cipher.SHA256{h}, noFee), now)
+	require.Error(t, err)
+
+	// The fee is checked against the burn factor of the unconfirmed pool
+	lowFee := output
+	lowFee.Hours = 90
+	highBurn := newCoinJoinCoordinator(3, time.Minute, 2, b)
+	err = highBurn.onJoinRequest(addr, NewJoinRequestMessage([]cipher.SHA256{h}, lowFee), now)
+	require.Equal(t, fee.ErrTxnInsufficientFee, err)
+
+	lowBurn := newCoinJoinCoordinator(3, time.Minute, 10, b)
+	err = lowBurn.onJoinRequest(addr, NewJoinRequestMessage([]cipher.SHA256{h}, lowFee), now)
+	require.NoError(t, err)
+
+	err = c.onJoinRequest(addr, NewJoinRequestMessage([]cipher.SHA256{h}, output), now)
+	require.NoError(t, err)
+
+	// The same input can't be used by another participant
+	err = c.onJoinRequest("2.2.2.2:6000", NewJoinRequestMessage([]cipher.SHA256{h}, output), now)
+	require.Equal(t, ErrCoinJoinDuplicateInput, err)
+
+	// The participant can replace its own request
+	err = c.onJoinRequest(addr, NewJoinRequestMessage([]cipher.SHA256{h}, output), now)
+	require.NoError(t, err)
+	require.Len(t, c.requests, 1)
+
+	// A disconnected participant is removed from the round
+	c.onDisconnect(addr)
+	require.Empty(t, c.requests)
+}
+
+func TestCoinJoinRoundTimeout(t *testing.T) {
+	b := newFakeCoinJoinBackend()
+	c := newCoinJoinCoordinator(2, time.Minute, params.UserVerifyTxn.BurnFactor, b)
+	now := time.Now()
+
+	for _, addr := range []string{"1.1.1.1:6000", "2.2.2.2:6000"} {
+		h, _ := b.addUnspent(t, 1e6, 100)
+		err := c.onJoinRequest(addr, NewJoinRequestMessage([]cipher.SHA256{h}, coin.TransactionOutput{
+			Address: testutil.MakeAddress(),
+			Coins:   1e6,
+			Hours:   50,
+		}), now)
+		require.NoError(t, err)
+	}
+	require.NotNil(t, c.txn)
+
+	// A join request received while the round is being signed is rejected, even after the timeout
+	h, _ := b.addUnspent(t, 1e6, 100)
+	m := NewJoinRequestMessage([]cipher.SHA256{h}, coin.TransactionOutput{
+		Address: testutil.MakeAddress(),
+		Coins:   1e6,
+		Hours:   50,
+	})
+	err := c.onJoinRequest("3.3.3.3:6000", m, now.Add(time.Minute))
+	require.Equal(t, ErrCoinJoinRoundInProgress, err)
+
+	// The round is not abandoned before the timeout
+	c.expire(now.Add(time.Minute - time.Second))
+	require.NotNil(t, c.txn)
+	require.Len(t, c.requests, 2)
+
+	// After the timeout, the round is abandoned and a new one can be started
+	c.expire(now.Add(time.Minute))
+	require.Nil(t, c.txn)
+	require.Empty(t, c.requests)
+
+	err = c.onJoinRequest("3.3.3.3:6000", m, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Nil(t, c.txn)
+	require.Len(t, c.requests, 1)
+}
+
+func TestCoinJoinParticipantSign(t *testing.T) {
+	addr := "1.1.1.1:6000"
+	_, s := cipher.GenerateKeyPair()
+	h := testutil.RandSHA256(t)
+	output := coin.TransactionOutput{
+		Address: testutil.MakeAddress(),
+		Coins:   1e6,
+		Hours:   50,
+	}
+
+	makeTxn := func(in []cipher.SHA256, out []coin.TransactionOutput) coin.Transaction {
+		txn := coin.Transaction{
+			In:  in,
+			Out: out,
+		}
+		txn.Sigs = make([]cipher.Sig, len(in))
+		err := txn.UpdateHeader()
+		require.NoError(t, err)
+		return txn
+	}
+
+	p := newCoinJoinParticipant()
+
+	_, err := p.sign(addr, makeTxn([]cipher.SHA256{h}, []coin.TransactionOutput{output}))
+	require.Equal(t, ErrCoinJoinNotRequested, err)
+
+	p.add(addr, coinJoinJoin{
+		inputs: []cipher.SHA256{h},
+		keys:   []cipher.SecKey{s},
+		output: output,
+	})
+
+	// Missing output
+	_, err = p.sign(addr, makeTxn([]cipher.SHA256{h}, nil))
+	require.Equal(t, ErrCoinJoinInvalidPartialTx, err)
+
+	// Missing input
+	_, err = p.sign(addr, makeTxn([]cipher.SHA256{testutil.RandSHA256(t)}, []coin.TransactionOutput{output}))
+	require.Equal(t, ErrCoinJoinInvalidPartialTx, err)
+
+	// Invalid inner hash
+	txn := makeTxn([]cipher.SHA256{h}, []coin.TransactionOutput{output})
+	txn.InnerHash = testutil.RandSHA256(t)
+	_, err = p.sign(addr, txn)
+	require.Equal(t, ErrCoinJoinInvalidPartialTx, err)
+
+	txn = makeTxn([]cipher.SHA256{h}, []coin.TransactionOutput{output})
+	msgs, err := p.sign(addr, txn)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	require.Equal(t, txn.InnerHash, msgs[0].InnerHash)
+	require.Equal(t, h, msgs[0].Input)
+	require.NoError(t, cipher.VerifyAddressSignedHash(cipher.MustAddressFromSecKey(s), msgs[0].Sig, cipher.AddSHA256(txn.InnerHash, h)))
+
+	// The join request is removed after signing
+	_, err = p.sign(addr, txn)
+	require.Equal(t, ErrCoinJoinNotRequested, err)
+}
@@ -55,16 +55,17 @@ var (
 
 // ConnectionDetails connection data managed by daemon
 type ConnectionDetails struct {
-	State                ConnectionState
-	Outgoing             bool
-	ConnectedAt          time.Time
-	Mirror               uint32
-	ListenPort           uint16
-	ProtocolVersion      int32
-	Height               uint64
-	UserAgent            useragent.Data
-	UnconfirmedVerifyTxn params.VerifyTxn
-	GenesisHash          cipher.SHA256
+	State                     ConnectionState
+	Outgoing                  bool
+	ConnectedAt               time.Time
+	Mirror                    uint32
+	ListenPort                uint16
+	ProtocolVersion           int32
+	NegotiatedProtocolVersion int32
+	Height                    uint64
+	UserAgent                 useragent.Data
+	UnconfirmedVerifyTxn      params.VerifyTxn
+	GenesisHash               cipher.SHA256
 }
 
 // HasIntroduced returns true if the connection has introduced
@@ -81,6 +82,10 @@ type connection struct {
 	Addr string
 	ConnectionDetails
 	gnetID uint64
+	// sessionSecKey is the secret key of the ephemeral session pubkey sent in our IntroductionMessage
+	sessionSecKey cipher.SecKey
+	// pexSession encrypts the peer exchange messages, if the peer sent a session pubkey in its IntroductionMessage
+	pexSession *pexSession
 }
 
 // ListenAddr returns the addr that connection listens on, if available
@@ -272,6 +277,15 @@ func (c *Connections) introduced(addr string, gnetID uint64, m *IntroductionMess
 		return nil, err
 	}
 
+	var pexSession *pexSession
+	if !m.SessionPubkey.Null() && conn.sessionSecKey != (cipher.SecKey{}) {
+		pexSession, err = newPEXSession(conn.sessionSecKey, m.SessionPubkey)
+		if err != nil {
+			logger.WithFields(fields).WithError(err).Error("newPEXSession failed")
+			return nil, err
+		}
+	}
+
 	// For outgoing connections, which are created by pending,
 	// the listen port is set from the addr's port number.
 	// Since we are connecting to it, it is presumed to be that peer's open listening port.
@@ -296,10 +310,12 @@ func (c *Connections) introduced(addr string, gnetID uint64, m *IntroductionMess
 	conn.State = ConnectionStateIntroduced
 	conn.Mirror = m.Mirror
 	conn.ProtocolVersion = m.ProtocolVersion
+	conn.NegotiatedProtocolVersion = m.NegotiatedProtocolVersion
 	conn.ListenPort = listenPort
 	conn.UserAgent = m.UserAgent
 	conn.UnconfirmedVerifyTxn = m.UnconfirmedVerifyTxn
 	conn.GenesisHash = m.GenesisHash
+	conn.pexSession = pexSession
 
 	if !conn.Outgoing {
 		listenAddr := conn.ListenAddr()
@@ -389,6 +405,25 @@ func (c *Connections) modify(addr string, gnetID uint64, f func(c *ConnectionDet
 	return nil
 }
 
+// setSessionSecKey sets the secret key of the ephemeral session pubkey sent to a connection in the IntroductionMessage
+func (c *Connections) setSessionSecKey(addr string, gnetID uint64, sec cipher.SecKey) error {
+	c.Lock()
+	defer c.Unlock()
+
+	conn := c.conns[addr]
+	if conn == nil {
+		return ErrConnectionNotExist
+	}
+
+	if conn.gnetID != gnetID {
+		return ErrConnectionGnetIDMismatch
+	}
+
+	conn.sessionSecKey = sec
+
+	return nil
+}
+
 // SetHeight sets the height for a connection
 func (c *Connections) SetHeight(addr string, gnetID uint64, height uint64) error {
 	c.Lock()
@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/testutil"
 	"github.com/skycoin/skycoin/src/util/useragent"
@@ -104,8 +105,9 @@ func TestConnectionsOutgoingFlow(t *testing.T) {
 		// use a different port to make sure we don't overwrite the true listen port
 		ListenPort:      port + 1,
 		Mirror:          1111,
-		ProtocolVersion: 2,
-		UserAgent:       userAgent,
+		ProtocolVersion:           3,
+		NegotiatedProtocolVersion: 2,
+		UserAgent:                 userAgent,
 	}
 
 	c, err = conns.introduced(addr, 1, m)
@@ -121,6 +123,7 @@ func TestConnectionsOutgoingFlow(t *testing.T) {
 	require.Equal(t, 1, conns.Len())
 	require.Equal(t, m.Mirror, c.Mirror)
 	require.Equal(t, m.ProtocolVersion, c.ProtocolVersion)
+	require.Equal(t, m.NegotiatedProtocolVersion, c.NegotiatedProtocolVersion)
 	require.Len(t, conns.mirrors, 1)
 	require.Equal(t, port, getMirrorPort(conns, ip, c.Mirror))
 	require.True(t, c.HasIntroduced())
@@ -448,6 +451,51 @@ func TestConnectionsSetHeight(t *testing.T) {
 	require.Equal(t, height, c.Height)
 }
 
+func TestConnectionsPEXSession(t *testing.T) {
+	conns := NewConnections()
+	addr := "127.0.0.1:6060"
+
+	_, sec := cipher.GenerateKeyPair()
+	remotePubkey, _ := cipher.GenerateKeyPair()
+
+	err := conns.setSessionSecKey(addr, 1, sec)
+	require.Equal(t, ErrConnectionNotExist, err)
+
+	_, err = conns.connected(addr, 1)
+	require.NoError(t, err)
+
+	err = conns.setSessionSecKey(addr, 2, sec)
+	require.Equal(t, ErrConnectionGnetIDMismatch, err)
+
+	err = conns.setSessionSecKey(addr, 1, sec)
+	require.NoError(t, err)
+
+	c, err := conns.introduced(addr, 1, &IntroductionMessage{
+		ListenPort:    6061,
+		Mirror:        1111,
+		UserAgent:     userAgent,
+		SessionPubkey: remotePubkey,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, c.pexSession)
+
+	// A peer that does not send a session pubkey has no session
+	addr2 := "127.0.0.2:6060"
+	_, err = conns.connected(addr2, 2)
+	require.NoError(t, err)
+
+	err = conns.setSessionSecKey(addr2, 2, sec)
+	require.NoError(t, err)
+
+	c, err = conns.introduced(addr2, 2, &IntroductionMessage{
+		ListenPort: 6061,
+		Mirror:     2222,
+		UserAgent:  userAgent,
+	})
+	require.NoError(t, err)
+	require.Nil(t, c.pexSession)
+}
+
 func TestConnectionsModifyMirrorPanics(t *testing.T) {
 	conns := NewConnections()
 	addr := "127.0.0.1:6060"
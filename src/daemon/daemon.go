@@ -54,8 +54,25 @@ func IsBroadcastFailure(err error) bool {
 
 const (
 	daemonRunDurationThreshold = time.Millisecond * 200
+
+	// Peers negotiate the highest protocol version they both support during the introduction.
+	// Messages that are added, or whose encoding changes, in a protocol version are only sent to peers
+	// with that negotiated version; peers with an older version are sent the old message instead.
+	// Old messages are kept for at least two major releases after their replacement.
+
+	// getBlocksRangeProtocolVersion is the lowest protocol version that supports GetBlocksRangeMessage
+	getBlocksRangeProtocolVersion = 3
 )
 
+// negotiateProtocolVersion returns the highest protocol version supported by both peers.
+// A node supports all the versions from its MinProtocolVersion to its ProtocolVersion.
+func negotiateProtocolVersion(version, peerVersion int32) int32 {
+	if peerVersion < version {
+		return peerVersion
+	}
+	return version
+}
+
 // Config subsystem configurations
 type Config struct {
 	Daemon   DaemonConfig
@@ -116,9 +133,22 @@ func (cfg *Config) preprocess() (Config, error) {
 		config.Daemon.MaxPendingConnections = config.Daemon.MaxOutgoingConnections
 	}
 
+	if config.Daemon.Coordinator {
+		if config.Daemon.CoordinatorMinParticipants < 2 {
+			return Config{}, errors.New("CoordinatorMinParticipants must be at least 2")
+		}
+		if config.Daemon.CoordinatorRoundTimeout <= 0 {
+			return Config{}, errors.New("CoordinatorRoundTimeout must be positive")
+		}
+		if config.Daemon.CoordinatorExpireRate <= 0 {
+			return Config{}, errors.New("CoordinatorExpireRate must be positive")
+		}
+	}
+
 	// MaxOutgoingMessageLength must be able to fit a GiveBlocksMessage with at least one maximum-sized block,
 	// otherwise it cannot send certain blocks.
-	// Blocks are the largest object sent over the network, so MaxBlockTransactionsSize is used as an upper limit
+	// Blocks are the largest object sent over the network, so MaxBlockTransactionsSize is used as an upper limit.
+	// It includes the max_block_size of the signaling deployments, so a block created while one is active can be sent
 	maxSizeGBM := maxSizeGiveBlocksMessage(config.Daemon.MaxBlockTransactionsSize)
 	if config.Daemon.MaxOutgoingMessageLength < maxSizeGBM {
 		return Config{}, fmt.Errorf("MaxOutgoingMessageLength must be >= %d", maxSizeGBM)
@@ -200,6 +230,9 @@ type DaemonConfig struct { //nolint:golint
 	MaxGetBlocksResponseCount uint64
 	// Max announce txns hash number
 	MaxTxnAnnounceNum int
+	// Number of random peers that transactions received from the network are announced to.
+	// Values <= 0 announce them to all peers
+	GossipFanout int
 	// How often new blocks are created by the signing node, in seconds
 	BlockCreationInterval uint64
 	// How often to check the unconfirmed pool for transactions that become valid
@@ -219,14 +252,22 @@ type DaemonConfig struct { //nolint:golint
 	MaxIncomingMessageLength uint64
 	// Maximum size of incoming messages
 	MaxOutgoingMessageLength uint64
-	// Maximum total size of transactions in a block
+	// Maximum total size of transactions in a block, including the max_block_size of the signaling deployments
 	MaxBlockTransactionsSize uint32
+	// Coordinate coinjoin rounds for peers that send join requests
+	Coordinator bool
+	// Number of join requests needed to start a coinjoin round
+	CoordinatorMinParticipants int
+	// How long the participants of a coinjoin round have to sign the transaction
+	CoordinatorRoundTimeout time.Duration
+	// How often the coinjoin round is checked for the round timeout
+	CoordinatorExpireRate time.Duration
 }
 
 // NewDaemonConfig creates daemon config
 func NewDaemonConfig() DaemonConfig {
 	return DaemonConfig{
-		ProtocolVersion:              2,
+		ProtocolVersion:              3,
 		MinProtocolVersion:           2,
 		Address:                      "",
 		Port:                         6677,
@@ -249,6 +290,7 @@ func NewDaemonConfig() DaemonConfig {
 		GetBlocksRequestCount:        20,
 		MaxGetBlocksResponseCount:    20,
 		MaxTxnAnnounceNum:            16,
+		GossipFanout:                 8,
 		BlockCreationInterval:        10,
 		UnconfirmedRefreshRate:       time.Minute,
 		UnconfirmedRemoveInvalidRate: time.Minute,
@@ -257,6 +299,10 @@ func NewDaemonConfig() DaemonConfig {
 		MaxOutgoingMessageLength:     256 * 1024,
 		MaxIncomingMessageLength:     1024 * 1024,
 		MaxBlockTransactionsSize:     32768,
+		Coordinator:                  false,
+		CoordinatorMinParticipants:   3,
+		CoordinatorRoundTimeout:      time.Minute * 2,
+		CoordinatorExpireRate:        time.Second * 5,
 	}
 }
 
@@ -268,11 +314,18 @@ type daemoner interface {
 	DaemonConfig() DaemonConfig
 	sendMessage(addr string, msg gnet.Message) error
 	broadcastMessage(msg gnet.Message) ([]uint64, error)
+	gossipMessage(msg gnet.Message) ([]uint64, error)
 	disconnectNow(addr string, r gnet.DisconnectReason) error
 	addPeers(addrs []string) int
 	recordPeerHeight(addr string, gnetID, height uint64)
+	recordPong(addr string)
 	getSignedBlocksSince(seq, count uint64) ([]coin.SignedBlock, error)
+	getSignedBlocksInRange(start, end uint64) ([]coin.SignedBlock, error)
+	connectionProtocolVersion(addr string) (int32, bool)
 	headBkSeq() (uint64, bool, error)
+	blockRequestOutstanding(seq uint64) bool
+	recordBlockRequest(seq uint64, addr string)
+	blockRequestsAnswered(addr string)
 	executeSignedBlock(b coin.SignedBlock) error
 	filterKnownUnconfirmed(txns []cipher.SHA256) ([]cipher.SHA256, error)
 	getKnownUnconfirmed(txns []cipher.SHA256) (coin.Transactions, error)
@@ -283,6 +336,10 @@ type daemoner interface {
 	recordMessageEvent(m asyncMessage, c *gnet.MessageContext) error
 	connectionIntroduced(addr string, gnetID uint64, m *IntroductionMessage) (*connection, error)
 	sendRandomPeers(addr string) error
+	openGivePeersMessage(addr string, m *EncryptedGivePeersMessage) (*GivePeersMessage, error)
+	coinJoinRequest(addr string, m *JoinRequestMessage) error
+	coinJoinPartialTx(addr string, m *PartialTxMessage) error
+	coinJoinSignedInput(addr string, m *SignedInputMessage) error
 }
 
 // Daemon stateful properties of the daemon
@@ -298,8 +355,17 @@ type Daemon struct {
 
 	// Cache of announced transactions that are flushed to the database periodically
 	announcedTxns *announcedTxnsCache
+	// Block seqs recently announced by peers, to discard duplicate announcements
+	announcedBlocks *announcedBlocksCache
 	// Cache of connection metadata
 	connections *Connections
+	// Connection limits, which can be changed at runtime by SetConnectionLimits
+	connectionLimits     ConnectionLimits
+	connectionLimitsLock sync.RWMutex
+	// Coinjoin coordinator, nil unless DaemonConfig.Coordinator is set
+	coinJoinCoordinator *coinJoinCoordinator
+	// Join requests sent to coinjoin coordinators
+	coinJoinParticipant *coinJoinParticipant
 	// connect, disconnect, message, error events channel
 	events chan interface{}
 	// quit channel
@@ -329,11 +395,23 @@ func New(config Config, v *visor.Visor) (*Daemon, error) {
 		pex:      pex,
 		visor:    v,
 
-		announcedTxns: newAnnouncedTxnsCache(),
-		connections:   NewConnections(),
-		events:        make(chan interface{}, config.Pool.EventChannelSize),
-		quit:          make(chan struct{}),
-		done:          make(chan struct{}),
+		announcedTxns:   newAnnouncedTxnsCache(),
+		announcedBlocks: newAnnouncedBlocksCache(time.Second * time.Duration(config.Daemon.BlockCreationInterval)),
+		connections:     NewConnections(),
+		connectionLimits: ConnectionLimits{
+			MaxConnections:                    config.Daemon.MaxConnections,
+			MaxOutgoingConnections:            config.Daemon.MaxOutgoingConnections,
+			MaxIncomingConnections:            config.Pool.MaxIncomingConnections,
+			MaxDefaultPeerOutgoingConnections: config.Pool.MaxDefaultPeerOutgoingConnections,
+		},
+		coinJoinParticipant: newCoinJoinParticipant(),
+		events:              make(chan interface{}, config.Pool.EventChannelSize),
+		quit:                make(chan struct{}),
+		done:                make(chan struct{}),
+	}
+
+	if config.Daemon.Coordinator {
+		d.coinJoinCoordinator = newCoinJoinCoordinator(config.Daemon.CoordinatorMinParticipants, config.Daemon.CoordinatorRoundTimeout, config.Daemon.UnconfirmedVerifyTxn.BurnFactor, d)
 	}
 
 	d.pool, err = NewPool(config.Pool, d)
@@ -420,6 +498,14 @@ func (dm *Daemon) Run() error {
 	flushAnnouncedTxnsTicker := time.NewTicker(dm.config.FlushAnnouncedTxnsRate)
 	defer flushAnnouncedTxnsTicker.Stop()
 
+	// The ticker channel of a node that is not a coinjoin coordinator is nil, it never fires
+	var coinJoinExpireC <-chan time.Time
+	if dm.coinJoinCoordinator != nil {
+		coinJoinExpireTicker := time.NewTicker(dm.config.CoordinatorExpireRate)
+		defer coinJoinExpireTicker.Stop()
+		coinJoinExpireC = coinJoinExpireTicker.C
+	}
+
 	// Try to connect to limited trusted public peers
 	if !dm.config.DisableOutgoingConnections {
 		wg.Add(1)
@@ -466,7 +552,12 @@ loop:
 			elapser.Register("blockCreationTicker.C")
 			if dm.visor.Config.IsBlockPublisher {
 				sb, err := dm.createAndPublishBlock()
-				if err != nil {
+				switch err {
+				case nil:
+				case visor.ErrBlockProducerCannotProduce:
+					logger.Debug("Block producer plugin does not allow producing the next block")
+					continue
+				default:
 					logger.WithError(err).Error("Failed to create and publish block")
 					continue
 				}
@@ -492,6 +583,10 @@ loop:
 				logger.WithError(err).Warning("announceBlocks failed")
 			}
 
+		case <-coinJoinExpireC:
+			elapser.Register("coinJoinExpireTicker")
+			dm.coinJoinCoordinator.expire(time.Now().UTC())
+
 		case setupErr = <-errC:
 			logger.WithError(setupErr).Error("read from errc")
 			break loop
@@ -759,7 +854,9 @@ func (dm *Daemon) maybeConnectToTrustedPeer() error {
 		return ErrNetworkingDisabled
 	}
 
-	if dm.pool.IsMaxOutgoingDefaultConnectionsReached() {
+	if reached, err := dm.pool.IsMaxOutgoingDefaultConnectionsReached(); err != nil {
+		return err
+	} else if reached {
 		return nil
 	}
 
@@ -783,8 +880,40 @@ func (dm *Daemon) maybeConnectToTrustedPeer() error {
 	return nil
 }
 
-func (dm Daemon) maxDefaultOutgoingConnections() int {
-	return dm.pool.Config.MaxDefaultPeerOutgoingConnections
+func (dm *Daemon) maxDefaultOutgoingConnections() int {
+	return dm.getConnectionLimits().MaxDefaultPeerOutgoingConnections
+}
+
+// ConnectionLimits are the connection limits that can be changed while the daemon is running
+type ConnectionLimits struct {
+	MaxConnections                    int
+	MaxOutgoingConnections            int
+	MaxIncomingConnections            int
+	MaxDefaultPeerOutgoingConnections int
+}
+
+// SetConnectionLimits changes the connection limits of the running daemon.
+// Existing connections are not disconnected if they exceed the new limits.
+func (dm *Daemon) SetConnectionLimits(l ConnectionLimits) error {
+	if l.MaxOutgoingConnections > l.MaxConnections {
+		return errors.New("MaxOutgoingConnections cannot be more than MaxConnections")
+	}
+
+	if err := dm.pool.Pool.SetConnectionLimits(l.MaxConnections, l.MaxOutgoingConnections, l.MaxIncomingConnections, l.MaxDefaultPeerOutgoingConnections); err != nil {
+		return err
+	}
+
+	dm.connectionLimitsLock.Lock()
+	defer dm.connectionLimitsLock.Unlock()
+	dm.connectionLimits = l
+
+	return nil
+}
+
+func (dm *Daemon) getConnectionLimits() ConnectionLimits {
+	dm.connectionLimitsLock.RLock()
+	defer dm.connectionLimitsLock.RUnlock()
+	return dm.connectionLimits
 }
 
 // connectToRandomPeer attempts to connect to a random peer. If it fails, the peer is removed.
@@ -792,18 +921,19 @@ func (dm *Daemon) connectToRandomPeer() {
 	if dm.config.DisableOutgoingConnections {
 		return
 	}
-	if dm.connections.OutgoingLen() >= dm.config.MaxOutgoingConnections {
+	limits := dm.getConnectionLimits()
+	if dm.connections.OutgoingLen() >= limits.MaxOutgoingConnections {
 		return
 	}
 	if dm.connections.PendingLen() >= dm.config.MaxPendingConnections {
 		return
 	}
-	if dm.connections.Len() >= dm.config.MaxConnections {
+	if dm.connections.Len() >= limits.MaxConnections {
 		return
 	}
 
 	// Make a connection to a random (public) peer
-	peers := dm.pex.Random(dm.config.MaxOutgoingConnections - dm.connections.OutgoingLen())
+	peers := dm.pex.Random(limits.MaxOutgoingConnections - dm.connections.OutgoingLen())
 	for _, p := range peers {
 		if err := dm.connectToPeer(p); err != nil {
 			logger.WithError(err).WithField("addr", p.Addr).Warning("connectToPeer failed")
@@ -944,6 +1074,16 @@ func (dm *Daemon) onConnectEvent(e ConnectEvent) {
 		return
 	}
 
+	// Generate an ephemeral session key for encrypting the peer exchange messages of this connection
+	sessionPubkey, sessionSecKey := cipher.GenerateKeyPair()
+	if err := dm.connections.setSessionSecKey(e.Addr, e.GnetID, sessionSecKey); err != nil {
+		logger.Critical().WithError(err).WithFields(fields).Error("connections.setSessionSecKey failed")
+		if err := dm.Disconnect(e.Addr, ErrDisconnectUnexpectedError); err != nil {
+			logger.WithError(err).WithFields(fields).Error("Disconnect")
+		}
+		return
+	}
+
 	logger.WithFields(fields).Debug("Sending introduction message")
 
 	if err := dm.sendMessage(e.Addr, NewIntroductionMessage(
@@ -954,6 +1094,7 @@ func (dm *Daemon) onConnectEvent(e ConnectEvent) {
 		dm.config.userAgent,
 		dm.config.UnconfirmedVerifyTxn,
 		dm.config.GenesisHash,
+		sessionPubkey,
 	)); err != nil {
 		logger.WithFields(fields).WithError(err).Error("Send IntroductionMessage failed")
 		return
@@ -973,10 +1114,17 @@ func (dm *Daemon) onDisconnectEvent(e DisconnectEvent) {
 		return
 	}
 
+	if dm.coinJoinCoordinator != nil {
+		dm.coinJoinCoordinator.onDisconnect(e.Addr)
+	}
+	dm.coinJoinParticipant.remove(e.Addr)
+	dm.announcedBlocks.remove(e.Addr)
+
 	// TODO -- blacklist peer for certain reasons, not just remove
 	switch e.Reason {
 	case ErrDisconnectIntroductionTimeout,
 		ErrDisconnectBlockchainPubkeyNotMatched,
+		ErrDisconnectNetworkIDNotMatched,
 		ErrDisconnectInvalidExtraData,
 		ErrDisconnectInvalidUserAgent:
 		if !dm.isTrustedPeer(e.Addr) {
@@ -1405,9 +1553,32 @@ func (dm *Daemon) sendRandomPeers(addr string) error {
 
 	m := NewGivePeersMessage(peers, dm.config.MaxOutgoingMessageLength)
 
+	// Encrypt the peers for peers that established a peer exchange session
+	if c := dm.connections.get(addr); c != nil && c.pexSession != nil {
+		em, err := newEncryptedGivePeersMessage(c.pexSession, m)
+		if err != nil {
+			return err
+		}
+		return dm.sendMessage(addr, em)
+	}
+
 	return dm.sendMessage(addr, m)
 }
 
+// openGivePeersMessage decrypts an EncryptedGivePeersMessage with the peer exchange session of the connection
+func (dm *Daemon) openGivePeersMessage(addr string, m *EncryptedGivePeersMessage) (*GivePeersMessage, error) {
+	c := dm.connections.get(addr)
+	if c == nil {
+		return nil, ErrConnectionNotExist
+	}
+
+	if c.pexSession == nil {
+		return nil, ErrNoPEXSession
+	}
+
+	return m.open(c.pexSession)
+}
+
 // announceAllValidTxns broadcasts valid unconfirmed transactions
 func (dm *Daemon) announceAllValidTxns() error {
 	if dm.config.DisableNetworking {
@@ -1485,6 +1656,23 @@ func (dm *Daemon) broadcastMessage(msg gnet.Message) ([]uint64, error) {
 		return nil, ErrNetworkingDisabled
 	}
 
+	return dm.pool.Pool.BroadcastMessage(msg, dm.introducedAddrs())
+}
+
+// gossipMessage sends a Message to GossipFanout random introduced connections in the Pool,
+// which relay it to their own peers. This avoids a broadcast storm when there are many connections.
+// Returns the gnet IDs of connections that broadcast succeeded for.
+func (dm *Daemon) gossipMessage(msg gnet.Message) ([]uint64, error) {
+	if dm.config.DisableNetworking {
+		return nil, ErrNetworkingDisabled
+	}
+
+	addrs := selectGossipPeers(dm.introducedAddrs(), dm.config.GossipFanout)
+	return dm.pool.Pool.BroadcastMessage(msg, addrs)
+}
+
+// introducedAddrs returns the addresses of the introduced connections
+func (dm *Daemon) introducedAddrs() []string {
 	conns := dm.connections.all()
 	var addrs []string
 	for _, c := range conns {
@@ -1492,8 +1680,20 @@ func (dm *Daemon) broadcastMessage(msg gnet.Message) ([]uint64, error) {
 			addrs = append(addrs, c.Addr)
 		}
 	}
+	return addrs
+}
 
-	return dm.pool.Pool.BroadcastMessage(msg, addrs)
+// selectGossipPeers returns fanout random addresses of addrs. If fanout is <= 0, all addrs are returned
+func selectGossipPeers(addrs []string, fanout int) []string {
+	if fanout <= 0 || len(addrs) <= fanout {
+		return addrs
+	}
+
+	rand.Shuffle(len(addrs), func(i, j int) {
+		addrs[i], addrs[j] = addrs[j], addrs[i]
+	})
+
+	return addrs[:fanout]
 }
 
 // disconnectNow disconnects from a peer immediately without sending a DisconnectMessage. Any pending messages
@@ -1519,16 +1719,54 @@ func (dm *Daemon) recordPeerHeight(addr string, gnetID, height uint64) {
 	}
 }
 
+// recordPong records a PongMessage from a peer, to measure the round trip time of the connection
+func (dm *Daemon) recordPong(addr string) {
+	if err := dm.pool.Pool.RecordPong(addr); err != nil {
+		logger.WithError(err).WithField("addr", addr).Error("pool.RecordPong failed")
+	}
+}
+
 // getSignedBlocksSince returns N signed blocks since given seq
 func (dm *Daemon) getSignedBlocksSince(seq, count uint64) ([]coin.SignedBlock, error) {
 	return dm.visor.GetSignedBlocksSince(seq, count)
 }
 
+// getSignedBlocksInRange returns signed blocks from start to end, including both
+func (dm *Daemon) getSignedBlocksInRange(start, end uint64) ([]coin.SignedBlock, error) {
+	return dm.visor.GetBlocksInRange(start, end)
+}
+
+// connectionProtocolVersion returns the protocol version negotiated with an introduced connection
+func (dm *Daemon) connectionProtocolVersion(addr string) (int32, bool) {
+	c := dm.connections.get(addr)
+	if c == nil || !c.HasIntroduced() {
+		return 0, false
+	}
+
+	return c.NegotiatedProtocolVersion, true
+}
+
 // headBkSeq returns the head block sequence
 func (dm *Daemon) headBkSeq() (uint64, bool, error) {
 	return dm.visor.HeadBkSeq()
 }
 
+// blockRequestOutstanding returns true if the blocks up to an announced seq were requested
+// less than one block creation interval ago, and the peer they were requested from did not reply yet
+func (dm *Daemon) blockRequestOutstanding(seq uint64) bool {
+	return dm.announcedBlocks.outstanding(seq, time.Now().UTC())
+}
+
+// recordBlockRequest records a request for the blocks up to an announced seq sent to addr
+func (dm *Daemon) recordBlockRequest(seq uint64, addr string) {
+	dm.announcedBlocks.add(seq, addr, time.Now().UTC())
+}
+
+// blockRequestsAnswered removes the block requests sent to addr, after addr replied with blocks
+func (dm *Daemon) blockRequestsAnswered(addr string) {
+	dm.announcedBlocks.remove(addr)
+}
+
 // executeSignedBlock executes the signed block
 func (dm *Daemon) executeSignedBlock(b coin.SignedBlock) error {
 	return dm.visor.ExecuteSignedBlock(b)
@@ -1568,6 +1806,8 @@ type GnetConnectionDetails struct {
 	ID           uint64
 	LastSent     time.Time
 	LastReceived time.Time
+	// Moving average of the round trip times measured by pings, 0 if not measured yet
+	RTT time.Duration
 }
 
 func newConnection(dc *connection, gc *gnet.Connection, pp *pex.Peer) Connection {
@@ -1583,6 +1823,7 @@ func newConnection(dc *connection, gc *gnet.Connection, pp *pex.Peer) Connection
 			ID:           gc.ID,
 			LastSent:     gc.LastSent,
 			LastReceived: gc.LastReceived,
+			RTT:          gc.RTT(),
 		}
 	}
 
@@ -1657,6 +1898,15 @@ func (dm *Daemon) GetDefaultConnections() []string {
 	return conns
 }
 
+// GetConnectionEvents returns the last peer connection events recorded by the connection pool, oldest first
+func (dm *Daemon) GetConnectionEvents() []gnet.ConnectionEvent {
+	if dm.pool.Pool == nil {
+		return nil
+	}
+
+	return dm.pool.Pool.GetConnectionEvents()
+}
+
 // GetConnection returns a *Connection of specific address
 func (dm *Daemon) GetConnection(addr string) (*Connection, error) {
 	c := dm.connections.get(addr)
@@ -1756,8 +2006,12 @@ func (dm *Daemon) GetBlockchainProgress(headSeq uint64) *BlockchainProgress {
 // For transactions received over the network, use daemon.injectTransaction and check the result to
 // decide on repropagation.
 func (dm *Daemon) InjectBroadcastTransaction(txn coin.Transaction) error {
-	return dm.visor.WithUpdateTx("daemon.InjectBroadcastTransaction", func(tx *dbutil.Tx) error {
-		_, head, inputs, err := dm.visor.InjectUserTransactionTx(tx, txn)
+	var known bool
+	if err := dm.visor.WithUpdateTx("daemon.InjectBroadcastTransaction", func(tx *dbutil.Tx) error {
+		var head *coin.SignedBlock
+		var inputs coin.UxArray
+		var err error
+		known, head, inputs, err = dm.visor.InjectUserTransactionTx(tx, txn)
 		if err != nil {
 			logger.WithError(err).Error("InjectUserTransactionTx failed")
 			return err
@@ -1769,7 +2023,15 @@ func (dm *Daemon) InjectBroadcastTransaction(txn coin.Transaction) error {
 		}
 
 		return nil
-	})
+	}); err != nil {
+		return err
+	}
+
+	if !known {
+		dm.visor.NotifyUnconfirmedTransaction(txn)
+	}
+
+	return nil
 }
 
 // InjectTransaction injects transaction to the unconfirmed pool but does not broadcast it.
@@ -1781,3 +2043,115 @@ func (dm *Daemon) InjectTransaction(txn coin.Transaction) error {
 	_, _, _, err := dm.visor.InjectUserTransaction(txn)
 	return err
 }
+
+// coinJoinInputs returns the unspent outputs with the given hashes, and the time of the head block.
+// An error is returned if any of the outputs is not in the unspent pool.
+func (dm *Daemon) coinJoinInputs(hashes []cipher.SHA256) (coin.UxArray, uint64, error) {
+	uxa, err := dm.visor.GetUnspentOutputs(hashes)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	m, err := dm.visor.GetBlockchainMetadata()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return uxa, m.HeadBlock.Time(), nil
+}
+
+// coinJoinRequest adds a join request received from a peer to the current coinjoin round
+func (dm *Daemon) coinJoinRequest(addr string, m *JoinRequestMessage) error {
+	if dm.coinJoinCoordinator == nil {
+		return ErrCoinJoinNotCoordinator
+	}
+
+	return dm.coinJoinCoordinator.onJoinRequest(addr, m, time.Now().UTC())
+}
+
+// coinJoinPartialTx signs our inputs of a coinjoin transaction received from a coordinator
+// and sends the signatures back
+func (dm *Daemon) coinJoinPartialTx(addr string, m *PartialTxMessage) error {
+	msgs, err := dm.coinJoinParticipant.sign(addr, m.Transaction)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range msgs {
+		if err := dm.sendMessage(addr, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// coinJoinSignedInput adds a signature received from a participant to the coinjoin transaction being signed
+func (dm *Daemon) coinJoinSignedInput(addr string, m *SignedInputMessage) error {
+	if dm.coinJoinCoordinator == nil {
+		return ErrCoinJoinNotCoordinator
+	}
+
+	return dm.coinJoinCoordinator.onSignedInput(addr, m)
+}
+
+// RequestCoinJoin sends a join request to the coinjoin coordinator at coordinatorAddr, to spend inputs to output
+// in the next coinjoin round. keys are the secret keys of the inputs, in the same order.
+// The coordinator must be a connected peer. The transaction is signed automatically when the coordinator sends it,
+// if it spends the inputs to the output. A new request replaces the previous request sent to the same coordinator.
+func (dm *Daemon) RequestCoinJoin(coordinatorAddr string, inputs []cipher.SHA256, keys []cipher.SecKey, output coin.TransactionOutput) error {
+	if len(inputs) == 0 {
+		return ErrCoinJoinNoInputs
+	}
+	if output.Address.Null() || output.Coins == 0 {
+		return ErrCoinJoinInvalidOutput
+	}
+	if len(keys) != len(inputs) {
+		return errors.New("Number of keys must equal number of inputs")
+	}
+
+	c := dm.connections.get(coordinatorAddr)
+	if c == nil {
+		return ErrConnectionNotExist
+	}
+	if !c.HasIntroduced() {
+		return ErrCoinJoinCoordinatorNotIntroduced
+	}
+
+	uxa, err := dm.visor.GetUnspentOutputs(inputs)
+	if err != nil {
+		return err
+	}
+
+	// The coordinator rejects a request whose coins don't match, there is no change output
+	coins, err := uxa.Coins()
+	if err != nil {
+		return err
+	}
+	if coins != output.Coins {
+		return ErrCoinJoinCoinsMismatch
+	}
+
+	for i, ux := range uxa {
+		addr, err := cipher.AddressFromSecKey(keys[i])
+		if err != nil {
+			return err
+		}
+		if addr != ux.Body.Address {
+			return fmt.Errorf("Key %d does not own input %s", i, inputs[i].Hex())
+		}
+	}
+
+	dm.coinJoinParticipant.add(coordinatorAddr, coinJoinJoin{
+		inputs: inputs,
+		keys:   keys,
+		output: output,
+	})
+
+	if err := dm.sendMessage(coordinatorAddr, NewJoinRequestMessage(inputs, output)); err != nil {
+		dm.coinJoinParticipant.remove(coordinatorAddr)
+		return err
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -340,3 +341,37 @@ func TestCheckBroadcastTxnRecipients(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectGossipPeers(t *testing.T) {
+	addrs := []string{"1.1.1.1:6000", "2.2.2.2:6000", "3.3.3.3:6000", "4.4.4.4:6000"}
+
+	require.Nil(t, selectGossipPeers(nil, 2))
+	require.Equal(t, addrs, selectGossipPeers(addrs, 0))
+	require.Equal(t, addrs, selectGossipPeers(addrs, -1))
+	require.ElementsMatch(t, addrs, selectGossipPeers(append([]string{}, addrs...), 4))
+	require.ElementsMatch(t, addrs, selectGossipPeers(append([]string{}, addrs...), 8))
+
+	for i := 0; i < 10; i++ {
+		selected := selectGossipPeers(append([]string{}, addrs...), 2)
+		require.Len(t, selected, 2)
+		require.NotEqual(t, selected[0], selected[1])
+		require.Subset(t, addrs, selected)
+	}
+}
+
+func TestConfigPreprocessMaxBlockSize(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Daemon.UserAgent = useragent.Data{
+		Coin:    "skycoin",
+		Version: "0.27.0",
+	}
+
+	// A block of MaxBlockTransactionsSize must fit in a GiveBlocksMessage of MaxOutgoingMessageLength
+	cfg.Daemon.MaxBlockTransactionsSize = 64 * 1024
+	_, err := cfg.preprocess()
+	require.NoError(t, err)
+
+	cfg.Daemon.MaxBlockTransactionsSize = 256 * 1024
+	_, err = cfg.preprocess()
+	require.EqualError(t, err, fmt.Sprintf("MaxOutgoingMessageLength must be >= %d", maxSizeGiveBlocksMessage(256*1024)))
+}
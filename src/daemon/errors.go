@@ -47,6 +47,10 @@ var (
 	ErrDisconnectInvalidMaxTransactionSize gnet.DisconnectReason = errors.New("Invalid max transaction size in introduction message")
 	// ErrDisconnectInvalidMaxDropletPrecision invalid max droplet precision in introduction message
 	ErrDisconnectInvalidMaxDropletPrecision gnet.DisconnectReason = errors.New("Invalid max droplet precision in introduction message")
+	// ErrDisconnectNetworkIDNotMatched is returned when the network ID in introduction does not match
+	ErrDisconnectNetworkIDNotMatched gnet.DisconnectReason = errors.New("Network ID does not match")
+	// ErrDisconnectInvalidEncryptedMessage an encrypted message could not be decrypted
+	ErrDisconnectInvalidEncryptedMessage gnet.DisconnectReason = errors.New("Encrypted message could not be decrypted")
 
 	// ErrDisconnectUnknownReason used when mapping an unknown reason code to an error. Is not sent over the network.
 	ErrDisconnectUnknownReason gnet.DisconnectReason = errors.New("Unknown DisconnectReason")
@@ -73,6 +77,8 @@ var (
 		ErrDisconnectInvalidBurnFactor:             17,
 		ErrDisconnectInvalidMaxTransactionSize:     18,
 		ErrDisconnectInvalidMaxDropletPrecision:    19,
+		ErrDisconnectNetworkIDNotMatched:           20,
+		ErrDisconnectInvalidEncryptedMessage:       21,
 
 		// gnet codes are registered here, but they are not sent in a DISC
 		// message by gnet. Only daemon sends a DISC packet.
@@ -0,0 +1,82 @@
+package gnet
+
+import (
+	"sync"
+	"time"
+)
+
+// bandwidthLimiter is a token bucket that limits the number of bytes transferred per second.
+// It is shared by all connections of a ConnectionPool, so that their total usage stays within the limit.
+// The bucket holds up to one second of tokens, which allows short bursts after idle periods.
+type bandwidthLimiter struct {
+	sync.Mutex
+	// Bytes per second
+	rate float64
+	// Maximum number of tokens
+	burst float64
+	// Available tokens, negative when transfers have been reserved ahead of the refill
+	tokens float64
+	last   time.Time
+}
+
+// newBandwidthLimiter creates a bandwidthLimiter for bytesPerSecond.
+// Returns nil if bytesPerSecond is 0 or less, which is unlimited.
+func newBandwidthLimiter(bytesPerSecond int) *bandwidthLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+
+	return &bandwidthLimiter{
+		rate:   float64(bytesPerSecond),
+		burst:  float64(bytesPerSecond),
+		tokens: float64(bytesPerSecond),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes n tokens from the bucket and returns how long to wait before transferring n bytes.
+// Transfers larger than the bucket are allowed, the wait is extended until the bucket is refilled.
+func (l *bandwidthLimiter) reserve(n int, now time.Time) time.Duration {
+	l.Lock()
+	defer l.Unlock()
+
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += elapsed.Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+	}
+
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// wait blocks until n bytes can be transferred. Returns false if any of the quit channels is closed while waiting.
+// A nil bandwidthLimiter never blocks.
+func (l *bandwidthLimiter) wait(n int, quit, qc <-chan struct{}) bool {
+	if l == nil || n <= 0 {
+		return true
+	}
+
+	d := l.reserve(n, time.Now())
+	if d == 0 {
+		return true
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-quit:
+		return false
+	case <-qc:
+		return false
+	}
+}
@@ -0,0 +1,55 @@
+package gnet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBandwidthLimiterReserve(t *testing.T) {
+	require.Nil(t, newBandwidthLimiter(0))
+	require.Nil(t, newBandwidthLimiter(-1))
+
+	l := newBandwidthLimiter(1000)
+	now := l.last
+
+	// The bucket starts full
+	require.Equal(t, time.Duration(0), l.reserve(600, now))
+	require.Equal(t, time.Duration(0), l.reserve(400, now))
+
+	// Transfers beyond the bucket wait for it to be refilled
+	require.Equal(t, 500*time.Millisecond, l.reserve(500, now))
+	require.Equal(t, 1500*time.Millisecond, l.reserve(1000, now))
+
+	// The bucket is refilled over time
+	now = now.Add(1500 * time.Millisecond)
+	require.Equal(t, time.Duration(0), l.reserve(0, now))
+	require.Equal(t, 100*time.Millisecond, l.reserve(100, now))
+
+	// The bucket does not hold more than one second of tokens
+	now = now.Add(time.Hour)
+	require.Equal(t, time.Duration(0), l.reserve(1000, now))
+	require.Equal(t, 10*time.Millisecond, l.reserve(10, now))
+}
+
+func TestBandwidthLimiterWait(t *testing.T) {
+	// A nil limiter never blocks
+	var l *bandwidthLimiter
+	require.True(t, l.wait(1e9, nil, nil))
+
+	l = newBandwidthLimiter(1000)
+	start := time.Now()
+	require.True(t, l.wait(1000, nil, nil))
+	require.True(t, l.wait(50, nil, nil))
+	require.True(t, time.Since(start) >= 40*time.Millisecond)
+
+	// The wait is interrupted by the quit channels
+	quit := make(chan struct{})
+	close(quit)
+	require.False(t, l.wait(1000, quit, nil))
+
+	qc := make(chan struct{})
+	close(qc)
+	require.False(t, l.wait(1000, nil, qc))
+}
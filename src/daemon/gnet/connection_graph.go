@@ -0,0 +1,91 @@
+package gnet
+
+import (
+	"sync"
+	"time"
+)
+
+// ConnectionEventType is the type of a ConnectionEvent
+type ConnectionEventType string
+
+const (
+	// ConnectionEventConnect is recorded when a connection is added to the pool
+	ConnectionEventConnect ConnectionEventType = "connect"
+	// ConnectionEventDisconnect is recorded when a connection is removed from the pool
+	ConnectionEventDisconnect ConnectionEventType = "disconnect"
+)
+
+// ConnectionEvent is a connect or disconnect of a peer recorded by a ConnectionGraph
+type ConnectionEvent struct {
+	Type ConnectionEventType
+	Addr string
+	// gnet ID of the connection
+	ID       uint64
+	Outgoing bool
+	Time     time.Time
+	// Bytes transferred over the connection until the event, 0 for a connect event
+	BytesSent     uint64
+	BytesReceived uint64
+	// Reason is the disconnect reason, empty for a connect event
+	Reason string
+}
+
+// ConnectionGraph records the last connection events of a ConnectionPool in a circular buffer,
+// so that the peers that the node has been connected to can be visualized
+type ConnectionGraph struct {
+	sync.Mutex
+	events []ConnectionEvent
+	// Index of the oldest event once the buffer is full
+	next int
+	full bool
+}
+
+// NewConnectionGraph creates a ConnectionGraph that holds the last size events.
+// Returns nil if size is 0 or less, which records nothing.
+func NewConnectionGraph(size int) *ConnectionGraph {
+	if size <= 0 {
+		return nil
+	}
+
+	return &ConnectionGraph{
+		events: make([]ConnectionEvent, size),
+	}
+}
+
+// Record adds an event, replacing the oldest event if the graph is full.
+// A nil ConnectionGraph ignores the event.
+func (g *ConnectionGraph) Record(e ConnectionEvent) {
+	if g == nil {
+		return
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	g.events[g.next] = e
+	g.next++
+	if g.next == len(g.events) {
+		g.next = 0
+		g.full = true
+	}
+}
+
+// Events returns a copy of the recorded events, oldest first
+func (g *ConnectionGraph) Events() []ConnectionEvent {
+	if g == nil {
+		return []ConnectionEvent{}
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	if !g.full {
+		events := make([]ConnectionEvent, g.next)
+		copy(events, g.events[:g.next])
+		return events
+	}
+
+	events := make([]ConnectionEvent, 0, len(g.events))
+	events = append(events, g.events[g.next:]...)
+	return append(events, g.events[:g.next]...)
+}
@@ -0,0 +1,48 @@
+package gnet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionGraph(t *testing.T) {
+	// A graph of size 0 records nothing
+	var g *ConnectionGraph
+	require.Nil(t, NewConnectionGraph(0))
+	g.Record(ConnectionEvent{Addr: "1.2.3.4:6000"})
+	require.Empty(t, g.Events())
+
+	newEvent := func(i int) ConnectionEvent {
+		return ConnectionEvent{
+			Type: ConnectionEventConnect,
+			ID:   uint64(i),
+			Time: time.Unix(int64(i), 0),
+		}
+	}
+
+	g = NewConnectionGraph(3)
+	require.Empty(t, g.Events())
+
+	g.Record(newEvent(1))
+	g.Record(newEvent(2))
+	require.Equal(t, []ConnectionEvent{newEvent(1), newEvent(2)}, g.Events())
+
+	g.Record(newEvent(3))
+	require.Equal(t, []ConnectionEvent{newEvent(1), newEvent(2), newEvent(3)}, g.Events())
+
+	// The oldest events are replaced once the graph is full
+	g.Record(newEvent(4))
+	require.Equal(t, []ConnectionEvent{newEvent(2), newEvent(3), newEvent(4)}, g.Events())
+
+	g.Record(newEvent(5))
+	g.Record(newEvent(6))
+	g.Record(newEvent(7))
+	require.Equal(t, []ConnectionEvent{newEvent(5), newEvent(6), newEvent(7)}, g.Events())
+
+	// Events returns a copy
+	events := g.Events()
+	events[0].ID = 100
+	require.Equal(t, uint64(5), g.Events()[0].ID)
+}
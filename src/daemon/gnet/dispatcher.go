@@ -17,6 +17,9 @@ import (
 var (
 	// ErrMsgExceedsMaxLen is returned if trying to send a message that exceeds the configured max length
 	ErrMsgExceedsMaxLen = errors.New("Message exceeds max message length")
+
+	// errSendCanceled is returned by sendMessage if the connection is closed while waiting for the bandwidth limit
+	errSendCanceled = errors.New("Send canceled")
 )
 
 // SendResult result of a single message send
@@ -34,8 +37,9 @@ func newSendResult(addr string, m Message, err error) SendResult {
 	}
 }
 
-// Serializes a Message over a net.Conn
-func sendMessage(conn net.Conn, msg Message, timeout time.Duration, maxMsgLength int) error {
+// Serializes a Message over a net.Conn.
+// If wait is not nil, it is called with the number of bytes before the write, and the write is canceled if it returns false.
+func sendMessage(conn net.Conn, msg Message, timeout time.Duration, maxMsgLength int, wait func(n int) bool) error {
 	m, err := EncodeMessage(msg)
 	if err != nil {
 		return err
@@ -43,6 +47,9 @@ func sendMessage(conn net.Conn, msg Message, timeout time.Duration, maxMsgLength
 	if len(m) > maxMsgLength {
 		return ErrMsgExceedsMaxLen
 	}
+	if wait != nil && !wait(len(m)) {
+		return errSendCanceled
+	}
 	return sendByteMessage(conn, m, timeout)
 }
 
@@ -192,11 +192,27 @@ func TestSendMessage(t *testing.T) {
 		require.True(t, bytes.Equal(msg, expect))
 		return nil
 	}
-	err := sendMessage(nil, m, 0, 1024)
+	err := sendMessage(nil, m, 0, 1024, nil)
 	require.NoError(t, err)
 
-	err = sendMessage(nil, m, 0, 1)
+	err = sendMessage(nil, m, 0, 1, nil)
 	testutil.RequireError(t, err, "Message exceeds max message length")
+
+	// wait is called with the size of the message before the write
+	var waited int
+	err = sendMessage(nil, m, 0, 1024, func(n int) bool {
+		waited = n
+		return true
+	})
+	require.NoError(t, err)
+	require.Equal(t, 9, waited)
+
+	// The write is canceled if wait returns false
+	sendByteMessage = failingSendByteMessage
+	err = sendMessage(nil, m, 0, 1024, func(n int) bool {
+		return false
+	})
+	require.Equal(t, errSendCanceled, err)
 }
 
 /* Helpers */
@@ -11,6 +11,7 @@ import (
 	"net"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"io"
@@ -31,6 +32,9 @@ const (
 	readLoopDurationThreshold       = 10 * time.Second
 	sendInMsgChanDurationThreshold  = 5 * time.Second
 	sendLoopDurationThreshold       = 500 * time.Millisecond
+
+	// rttWeight is the weight of a new round trip time sample in the moving average of Connection.RTT
+	rttWeight = 0.125
 )
 
 var (
@@ -124,6 +128,12 @@ type Config struct {
 	// Individual connections' send queue size.  This should be increased
 	// if send volume per connection is high, so as not to block
 	ConnectionWriteQueueSize int
+	// Maximum number of bytes per second written to all connections. Values <= 0 are unlimited
+	OutboundBandwidthLimit int
+	// Maximum number of bytes per second read from all connections. Values <= 0 are unlimited
+	InboundBandwidthLimit int
+	// Number of connection events kept by the ConnectionGraph. Values <= 0 record no events
+	ConnectionGraphSize int
 	// Triggered on client disconnect
 	DisconnectCallback DisconnectCallback
 	// Triggered on client connect
@@ -154,6 +164,7 @@ func NewConfig() Config {
 		WriteTimeout:                      time.Second * 30,
 		SendResultsSize:                   2048,
 		ConnectionWriteQueueSize:          128,
+		ConnectionGraphSize:               1000,
 		DisconnectCallback:                nil,
 		ConnectCallback:                   nil,
 		DebugPrint:                        false,
@@ -183,6 +194,51 @@ type Connection struct {
 	// Message send queue.
 	WriteQueue chan Message
 	Solicited  bool
+	// Bytes transferred, shared by the copies of the Connection
+	bytes *connectionBytes
+	// Round trip time measured by pings, shared by the copies of the Connection
+	rtt *connectionRTT
+}
+
+// connectionBytes counts the bytes transferred over a connection, it is updated atomically
+type connectionBytes struct {
+	sent     uint64
+	received uint64
+}
+
+// connectionRTT is the round trip time of a connection, it is updated atomically
+type connectionRTT struct {
+	// Time the outstanding ping was sent, in unix nanoseconds. 0 if no ping is waiting for a pong
+	pingSentAt int64
+	// Exponentially weighted moving average of the round trip times, in nanoseconds
+	avg int64
+}
+
+// pingSent records the time a ping was sent. If a ping is already waiting for its pong, it is kept
+func (r *connectionRTT) pingSent(t time.Time) {
+	atomic.CompareAndSwapInt64(&r.pingSentAt, 0, t.UnixNano())
+}
+
+// pongReceived updates the average round trip time with the time since the outstanding ping was sent.
+// Returns false if no ping is waiting for a pong
+func (r *connectionRTT) pongReceived(t time.Time) bool {
+	sentAt := atomic.SwapInt64(&r.pingSentAt, 0)
+	if sentAt == 0 {
+		return false
+	}
+
+	rtt := t.UnixNano() - sentAt
+	if rtt < 0 {
+		rtt = 0
+	}
+
+	avg := atomic.LoadInt64(&r.avg)
+	if avg != 0 {
+		rtt = avg + int64(rttWeight*float64(rtt-avg))
+	}
+	atomic.StoreInt64(&r.avg, rtt)
+
+	return true
 }
 
 // NewConnection creates a new Connection tied to a ConnectionPool
@@ -196,9 +252,27 @@ func NewConnection(pool *ConnectionPool, id uint64, conn net.Conn, writeQueueSiz
 		LastSent:       Now(),
 		WriteQueue:     make(chan Message, writeQueueSize),
 		Solicited:      solicited,
+		bytes:          &connectionBytes{},
+		rtt:            &connectionRTT{},
 	}
 }
 
+// BytesSent returns the number of bytes written to the connection
+func (conn *Connection) BytesSent() uint64 {
+	return atomic.LoadUint64(&conn.bytes.sent)
+}
+
+// BytesReceived returns the number of bytes read from the connection
+func (conn *Connection) BytesReceived() uint64 {
+	return atomic.LoadUint64(&conn.bytes.received)
+}
+
+// RTT returns the moving average of the round trip times to the peer, measured from the pings
+// sent by the ConnectionPool and the pongs recorded by RecordPong. Returns 0 if no pong has been received
+func (conn *Connection) RTT() time.Duration {
+	return time.Duration(atomic.LoadInt64(&conn.rtt.avg))
+}
+
 // Addr returns remote address
 func (conn *Connection) Addr() string {
 	return conn.Conn.RemoteAddr().String()
@@ -242,6 +316,11 @@ type ConnectionPool struct {
 	outgoingConnections map[string]struct{}
 	// connected incoming connections
 	incomingConnections map[string]struct{}
+	// Bandwidth limits shared by all connections, nil if unlimited
+	outboundLimiter *bandwidthLimiter
+	inboundLimiter  *bandwidthLimiter
+	// Last connection events
+	graph *ConnectionGraph
 	// User-defined state to be passed into message handlers
 	messageState interface{}
 	// Connection ID counter
@@ -277,6 +356,9 @@ func NewConnectionPool(c Config, state interface{}) (*ConnectionPool, error) {
 		outgoingConnections:        make(map[string]struct{}),
 		incomingConnections:        make(map[string]struct{}),
 		SendResults:                make(chan SendResult, c.SendResultsSize),
+		outboundLimiter:            newBandwidthLimiter(c.OutboundBandwidthLimit),
+		inboundLimiter:             newBandwidthLimiter(c.InboundBandwidthLimit),
+		graph:                      NewConnectionGraph(c.ConnectionGraphSize),
 		messageState:               state,
 		quit:                       make(chan struct{}),
 		done:                       make(chan struct{}),
@@ -426,7 +508,7 @@ func (pool *ConnectionPool) canConnect(a string, solicited bool) error {
 	}
 
 	if solicited {
-		if _, ok := pool.Config.defaultConnections[a]; ok && pool.IsMaxOutgoingDefaultConnectionsReached() {
+		if _, ok := pool.Config.defaultConnections[a]; ok && pool.isMaxOutgoingDefaultConnectionsReached() {
 			return ErrMaxOutgoingDefaultConnectionsReached
 		} else if pool.isMaxOutgoingConnectionsReached() {
 			return ErrMaxOutgoingConnectionsReached
@@ -470,6 +552,14 @@ func (pool *ConnectionPool) newConnection(conn net.Conn, solicited bool) (*Conne
 	pool.pool[nc.ID] = nc
 	pool.addresses[a] = nc
 
+	pool.graph.Record(ConnectionEvent{
+		Type:     ConnectionEventConnect,
+		Addr:     a,
+		ID:       nc.ID,
+		Outgoing: solicited,
+		Time:     Now(),
+	})
+
 	return nc, nil
 }
 
@@ -630,6 +720,13 @@ func (pool *ConnectionPool) readLoop(conn *Connection, msgChan chan []byte, qc c
 			continue
 		}
 
+		atomic.AddUint64(&conn.bytes.received, uint64(len(data)))
+
+		// Reading is paused while over the inbound limit, which lets TCP flow control slow down the sender
+		if !pool.inboundLimiter.wait(len(data), pool.quit, qc) {
+			return nil
+		}
+
 		// write data to buffer
 		if _, err := conn.Buffer.Write(data); err != nil {
 			return err
@@ -673,12 +770,20 @@ func (pool *ConnectionPool) sendLoop(conn *Connection, timeout time.Duration, ma
 				continue
 			}
 
-			err := sendMessage(conn.Conn, m, timeout, maxMsgLength)
+			var sent int
+			err := sendMessage(conn.Conn, m, timeout, maxMsgLength, func(n int) bool {
+				sent = n
+				return pool.outboundLimiter.wait(n, pool.quit, qc)
+			})
+			if err == errSendCanceled {
+				return nil
+			}
 
 			// Update last sent before writing to SendResult,
 			// this allows a write to SendResult to be used as a sync marker,
 			// since no further action in this block will happen after the write.
 			if err == nil {
+				atomic.AddUint64(&conn.bytes.sent, uint64(sent))
 				if err := pool.updateLastSent(conn.Addr(), Now()); err != nil {
 					logger.WithField("addr", conn.Addr()).WithError(err).Warning("updateLastSent failed")
 				}
@@ -778,11 +883,35 @@ func (pool *ConnectionPool) isMaxOutgoingConnectionsReached() bool {
 	return len(pool.outgoingConnections) >= pool.Config.MaxOutgoingConnections
 }
 
-// IsMaxOutgoingDefaultConnectionsReached checks whether the max outgoing default connections reached
-func (pool *ConnectionPool) IsMaxOutgoingDefaultConnectionsReached() bool {
+func (pool *ConnectionPool) isMaxOutgoingDefaultConnectionsReached() bool {
 	return len(pool.defaultOutgoingConnections) >= pool.Config.MaxDefaultPeerOutgoingConnections
 }
 
+// IsMaxOutgoingDefaultConnectionsReached checks whether the max outgoing default connections reached
+func (pool *ConnectionPool) IsMaxOutgoingDefaultConnectionsReached() (reached bool, err error) {
+	err = pool.strand("IsMaxOutgoingDefaultConnectionsReached", func() error {
+		reached = pool.isMaxOutgoingDefaultConnectionsReached()
+		return nil
+	})
+	return
+}
+
+// SetConnectionLimits updates the maximum number of connections allowed by the pool.
+// Existing connections are not disconnected if they exceed the new limits.
+func (pool *ConnectionPool) SetConnectionLimits(maxConnections, maxOutgoing, maxIncoming, maxDefaultPeerOutgoing int) error {
+	if maxConnections < maxOutgoing+maxIncoming {
+		return errors.New("MaxConnections must be >= MaxOutgoingConnections + MaxIncomingConnections")
+	}
+
+	return pool.strand("SetConnectionLimits", func() error {
+		pool.Config.MaxConnections = maxConnections
+		pool.Config.MaxOutgoingConnections = maxOutgoing
+		pool.Config.MaxIncomingConnections = maxIncoming
+		pool.Config.MaxDefaultPeerOutgoingConnections = maxDefaultPeerOutgoing
+		return nil
+	})
+}
+
 func (pool *ConnectionPool) updateLastSent(addr string, t time.Time) error {
 	return pool.strand("updateLastSent", func() error {
 		if conn, ok := pool.addresses[addr]; ok {
@@ -902,6 +1031,21 @@ func (pool *ConnectionPool) disconnect(addr string, r DisconnectReason) *Connect
 
 	logger.WithFields(fields).WithField("reason", r).Debug("Closed connection and removed from pool")
 
+	var reason string
+	if r != nil {
+		reason = r.Error()
+	}
+	pool.graph.Record(ConnectionEvent{
+		Type:          ConnectionEventDisconnect,
+		Addr:          addr,
+		ID:            conn.ID,
+		Outgoing:      conn.Solicited,
+		Time:          Now(),
+		BytesSent:     conn.BytesSent(),
+		BytesReceived: conn.BytesReceived(),
+		Reason:        reason,
+	})
+
 	return conn
 }
 
@@ -927,6 +1071,11 @@ func (pool *ConnectionPool) GetConnections() ([]Connection, error) {
 	return conns, nil
 }
 
+// GetConnectionEvents returns the last connection events, oldest first
+func (pool *ConnectionPool) GetConnectionEvents() []ConnectionEvent {
+	return pool.graph.Events()
+}
+
 // Size returns the pool size
 func (pool *ConnectionPool) Size() (l int, err error) {
 	err = pool.strand("Size", func() error {
@@ -1028,14 +1177,15 @@ func (pool *ConnectionPool) receiveMessage(c *Connection, msg []byte) error {
 	return m.Handle(NewMessageContext(c), pool.messageState)
 }
 
-// SendPings sends a ping if our last message sent was over pingRate ago
+// SendPings sends a ping if our last message sent was over pingRate ago.
+// The time the ping is sent is recorded, to measure the round trip time when RecordPong is called
 func (pool *ConnectionPool) SendPings(rate time.Duration, msg Message) error {
 	now := time.Now().UTC()
-	var addrs []string
+	var conns []*Connection
 	if err := pool.strand("SendPings", func() error {
 		for _, conn := range pool.pool {
 			if conn.LastSent.Add(rate).Before(now) {
-				addrs = append(addrs, conn.Addr())
+				conns = append(conns, conn)
 			}
 		}
 		return nil
@@ -1043,15 +1193,28 @@ func (pool *ConnectionPool) SendPings(rate time.Duration, msg Message) error {
 		return err
 	}
 
-	for _, a := range addrs {
-		if err := pool.SendMessage(a, msg); err != nil {
+	for _, c := range conns {
+		if err := pool.SendMessage(c.Addr(), msg); err != nil {
 			return err
 		}
+		c.rtt.pingSent(now)
 	}
 
 	return nil
 }
 
+// RecordPong records the reply to the last ping sent to a connection by SendPings,
+// and updates the connection's round trip time
+func (pool *ConnectionPool) RecordPong(addr string) error {
+	now := Now()
+	return pool.strand("RecordPong", func() error {
+		if conn, ok := pool.addresses[addr]; ok {
+			conn.rtt.pongReceived(now)
+		}
+		return nil
+	})
+}
+
 // GetStaleConnections returns connections that have been idle for longer than idleLimit
 func (pool *ConnectionPool) GetStaleConnections(idleLimit time.Duration) ([]string, error) {
 	now := Now()
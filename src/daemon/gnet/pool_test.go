@@ -433,6 +433,71 @@ func TestDisconnect(t *testing.T) {
 	<-q
 }
 
+func TestConnectionEvents(t *testing.T) {
+	cfg := newTestConfig()
+	p, err := NewConnectionPool(cfg, nil)
+	require.NoError(t, err)
+
+	cc := make(chan *Connection, 1)
+	p.Config.ConnectCallback = func(addr string, id uint64, solicited bool) {
+		cc <- p.pool[id]
+	}
+
+	q := make(chan struct{})
+	go func() {
+		defer close(q)
+		err := p.Run()
+		require.NoError(t, err)
+	}()
+	wait()
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+
+	var c *Connection
+	select {
+	case c = <-cc:
+	case <-time.After(time.Second * 3):
+		t.Fatal("Timed out waiting for connection")
+	}
+
+	events := p.GetConnectionEvents()
+	require.Len(t, events, 1)
+	require.Equal(t, ConnectionEventConnect, events[0].Type)
+	require.Equal(t, conn.LocalAddr().String(), events[0].Addr)
+	require.Equal(t, c.ID, events[0].ID)
+	require.False(t, events[0].Outgoing)
+
+	// Less than a length prefix, the bytes are buffered until the rest of the message arrives
+	_, err = conn.Write([]byte{1, 2, 3})
+	require.NoError(t, err)
+
+	timeout := time.After(time.Second * 3)
+	for c.BytesReceived() != 3 {
+		select {
+		case <-timeout:
+			t.Fatal("Timed out waiting for bytes to be received")
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
+
+	err = p.Disconnect(conn.LocalAddr().String(), ErrDisconnectMalformedMessage)
+	require.NoError(t, err)
+
+	events = p.GetConnectionEvents()
+	require.Len(t, events, 2)
+	require.Equal(t, ConnectionEventDisconnect, events[1].Type)
+	require.Equal(t, conn.LocalAddr().String(), events[1].Addr)
+	require.Equal(t, c.ID, events[1].ID)
+	require.Equal(t, uint64(3), events[1].BytesReceived)
+	require.Equal(t, uint64(0), events[1].BytesSent)
+	require.Equal(t, ErrDisconnectMalformedMessage.Error(), events[1].Reason)
+	require.False(t, events[1].Time.Before(events[0].Time))
+
+	p.Shutdown()
+	<-q
+}
+
 func TestConnectionClose(t *testing.T) {
 	c := &Connection{
 		Conn:       NewDummyConn(addr),
@@ -1382,3 +1447,127 @@ func (c *readNothingConn) Close() error {
 func (c *readNothingConn) stop() {
 	close(c.stopReading)
 }
+
+func TestConnectionRTT(t *testing.T) {
+	c := NewConnection(nil, 1, nil, 1, false)
+	require.Equal(t, time.Duration(0), c.RTT())
+
+	now := time.Now().UTC()
+
+	// A pong without a ping is ignored
+	require.False(t, c.rtt.pongReceived(now))
+	require.Equal(t, time.Duration(0), c.RTT())
+
+	// The first sample sets the RTT
+	c.rtt.pingSent(now)
+	require.True(t, c.rtt.pongReceived(now.Add(100*time.Millisecond)))
+	require.Equal(t, 100*time.Millisecond, c.RTT())
+
+	// A ping waiting for its pong is not replaced
+	c.rtt.pingSent(now)
+	c.rtt.pingSent(now.Add(time.Second))
+	require.True(t, c.rtt.pongReceived(now.Add(900*time.Millisecond)))
+	require.Equal(t, 200*time.Millisecond, c.RTT())
+	require.False(t, c.rtt.pongReceived(now.Add(time.Second)))
+
+	// The RTT is shared by the copies of the connection
+	cp := *c
+	cp.rtt.pingSent(now)
+	require.True(t, cp.rtt.pongReceived(now))
+	require.Equal(t, 175*time.Millisecond, c.RTT())
+}
+
+func TestPoolSendPingsRecordPong(t *testing.T) {
+	resetHandler()
+	EraseMessages()
+	RegisterMessage(BytePrefix, ByteMessage{})
+	VerifyMessages()
+
+	cfg := newTestConfig()
+	cfg.WriteTimeout = time.Second
+	cfg.SendResultsSize = 1
+	cfg.ConnectionWriteQueueSize = 8
+	p, err := NewConnectionPool(cfg, nil)
+	require.NoError(t, err)
+
+	cc := make(chan *Connection, 1)
+	p.Config.ConnectCallback = func(addr string, id uint64, solicited bool) {
+		cc <- p.pool[1]
+	}
+
+	q := make(chan struct{})
+	go func() {
+		defer close(q)
+		err := p.Run()
+		require.NoError(t, err)
+	}()
+	wait()
+
+	_, err = net.Dial("tcp", addr)
+	require.NoError(t, err)
+
+	c := <-cc
+
+	// A pong without a ping does not change the RTT
+	require.NoError(t, p.RecordPong(c.Addr()))
+	require.Equal(t, time.Duration(0), c.RTT())
+
+	// A pong for an unknown connection is ignored
+	require.NoError(t, p.RecordPong("127.0.0.1:1"))
+
+	require.NoError(t, p.SendPings(0, NewByteMessage(88)))
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, p.RecordPong(c.Addr()))
+	require.True(t, c.RTT() >= 10*time.Millisecond)
+
+	gc, err := p.GetConnection(c.Addr())
+	require.NoError(t, err)
+	require.Equal(t, c.RTT(), gc.RTT())
+
+	p.Shutdown()
+	<-q
+}
+
+func TestPoolSetConnectionLimits(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.MaxDefaultPeerOutgoingConnections = 0
+	p, err := NewConnectionPool(cfg, nil)
+	require.NoError(t, err)
+
+	q := make(chan struct{})
+	go func() {
+		defer close(q)
+		err := p.Run()
+		require.NoError(t, err)
+	}()
+	wait()
+
+	reached, err := p.IsMaxOutgoingDefaultConnectionsReached()
+	require.NoError(t, err)
+	require.True(t, reached)
+
+	err = p.SetConnectionLimits(10, 8, 4, 1)
+	require.EqualError(t, err, "MaxConnections must be >= MaxOutgoingConnections + MaxIncomingConnections")
+
+	// The limits are changed while they are read by other goroutines
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			_, err := p.IsMaxOutgoingDefaultConnectionsReached()
+			require.NoError(t, err)
+		}
+	}()
+
+	err = p.SetConnectionLimits(24, 8, 16, 1)
+	require.NoError(t, err)
+	wg.Wait()
+
+	reached, err = p.IsMaxOutgoingDefaultConnectionsReached()
+	require.NoError(t, err)
+	require.False(t, reached)
+
+	p.Shutdown()
+	<-q
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/util/iputil"
 	"github.com/skycoin/skycoin/src/util/useragent"
+	"github.com/skycoin/skycoin/src/visor"
 )
 
 // Message represent a packet to be serialized over the network by
@@ -46,13 +47,18 @@ func NewMessageConfig(prefix string, m interface{}) MessageConfig {
 
 //go:generate skyencoder -unexported -struct IntroductionMessage
 //go:generate skyencoder -unexported -struct GivePeersMessage
+//go:generate skyencoder -unexported -struct EncryptedGivePeersMessage
 //go:generate skyencoder -unexported -struct GetBlocksMessage
+//go:generate skyencoder -unexported -struct GetBlocksRangeMessage
 //go:generate skyencoder -unexported -struct GiveBlocksMessage
 //go:generate skyencoder -unexported -struct AnnounceBlocksMessage
 //go:generate skyencoder -unexported -struct GetTxnsMessage
 //go:generate skyencoder -unexported -struct GiveTxnsMessage
 //go:generate skyencoder -unexported -struct AnnounceTxnsMessage
 //go:generate skyencoder -unexported -struct DisconnectMessage
+//go:generate skyencoder -unexported -struct JoinRequestMessage
+//go:generate skyencoder -unexported -struct PartialTxMessage
+//go:generate skyencoder -unexported -struct SignedInputMessage
 //go:generate skyencoder -unexported -struct IPAddr
 //go:generate skyencoder -unexported -output-path . -package daemon -struct SignedBlock github.com/skycoin/skycoin/src/coin
 //go:generate skyencoder -unexported -output-path . -package daemon -struct Transaction github.com/skycoin/skycoin/src/coin
@@ -72,6 +78,11 @@ func getMessageConfigs() []MessageConfig {
 		NewMessageConfig("GIVT", GiveTxnsMessage{}),
 		NewMessageConfig("ANNT", AnnounceTxnsMessage{}),
 		NewMessageConfig("DISC", DisconnectMessage{}),
+		NewMessageConfig("GETR", GetBlocksRangeMessage{}),
+		NewMessageConfig("CJRQ", JoinRequestMessage{}),
+		NewMessageConfig("CJTX", PartialTxMessage{}),
+		NewMessageConfig("CJSI", SignedInputMessage{}),
+		NewMessageConfig("EGVP", EncryptedGivePeersMessage{}),
 	}
 }
 
@@ -323,12 +334,102 @@ func (gpm *GivePeersMessage) process(d daemoner) {
 	d.addPeers(peers)
 }
 
+// EncryptedGivePeersMessage is a GivePeersMessage encrypted with the peer exchange session of the connection.
+// It is sent instead of a GivePeersMessage to peers that sent a session pubkey in their IntroductionMessage,
+// so that a network observer cannot learn the peers of a node.
+type EncryptedGivePeersMessage struct {
+	// Seq is the sequence number of the message in the connection, starting at 1
+	Seq uint64
+	// Ciphertext is the encrypted GivePeersMessage followed by its authentication tag
+	Ciphertext []byte               `enc:",maxlen=4096"`
+	c          *gnet.MessageContext `enc:"-"`
+}
+
+// newEncryptedGivePeersMessage encrypts a GivePeersMessage with a pexSession
+func newEncryptedGivePeersMessage(s *pexSession, gpm *GivePeersMessage) (*EncryptedGivePeersMessage, error) {
+	plaintext, err := encodeGivePeersMessage(gpm)
+	if err != nil {
+		return nil, err
+	}
+
+	seq, ciphertext := s.seal(plaintext)
+
+	return &EncryptedGivePeersMessage{
+		Seq:        seq,
+		Ciphertext: ciphertext,
+	}, nil
+}
+
+// open decrypts the GivePeersMessage with a pexSession
+func (egpm *EncryptedGivePeersMessage) open(s *pexSession) (*GivePeersMessage, error) {
+	plaintext, err := s.open(egpm.Seq, egpm.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	var gpm GivePeersMessage
+	if err := decodeGivePeersMessageExact(plaintext, &gpm); err != nil {
+		return nil, err
+	}
+
+	return &gpm, nil
+}
+
+// EncodeSize implements gnet.Serializer
+func (egpm *EncryptedGivePeersMessage) EncodeSize() uint64 {
+	return encodeSizeEncryptedGivePeersMessage(egpm)
+}
+
+// Encode implements gnet.Serializer
+func (egpm *EncryptedGivePeersMessage) Encode(buf []byte) error {
+	return encodeEncryptedGivePeersMessageToBuffer(buf, egpm)
+}
+
+// Decode implements gnet.Serializer
+func (egpm *EncryptedGivePeersMessage) Decode(buf []byte) (uint64, error) {
+	return decodeEncryptedGivePeersMessage(buf, egpm)
+}
+
+// Handle handle message
+func (egpm *EncryptedGivePeersMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	egpm.c = mc
+	return daemon.(daemoner).recordMessageEvent(egpm, mc)
+}
+
+// process decrypts the peers and notifies the Pex instance that peers were received
+func (egpm *EncryptedGivePeersMessage) process(d daemoner) {
+	if d.pexConfig().Disabled {
+		return
+	}
+
+	fields := logrus.Fields{
+		"addr":   egpm.c.Addr,
+		"gnetID": egpm.c.ConnID,
+		"seq":    egpm.Seq,
+	}
+
+	gpm, err := d.openGivePeersMessage(egpm.c.Addr, egpm)
+	if err != nil {
+		logger.WithError(err).WithFields(fields).Warning("EncryptedGivePeersMessage could not be decrypted")
+		if err := d.Disconnect(egpm.c.Addr, ErrDisconnectInvalidEncryptedMessage); err != nil {
+			logger.WithError(err).WithFields(fields).Warning("Disconnect")
+		}
+		return
+	}
+
+	gpm.c = egpm.c
+	gpm.process(d)
+}
+
 // IntroductionMessage is sent on first connect by both parties
 type IntroductionMessage struct {
-	c                    *gnet.MessageContext `enc:"-"`
-	UserAgent            useragent.Data       `enc:"-"`
-	UnconfirmedVerifyTxn params.VerifyTxn     `enc:"-"`
-	GenesisHash          cipher.SHA256        `enc:"-"`
+	c                         *gnet.MessageContext `enc:"-"`
+	UserAgent                 useragent.Data       `enc:"-"`
+	UnconfirmedVerifyTxn      params.VerifyTxn     `enc:"-"`
+	GenesisHash               cipher.SHA256        `enc:"-"`
+	NetworkID                 NetworkID            `enc:"-"`
+	SessionPubkey             cipher.PubKey        `enc:"-"`
+	NegotiatedProtocolVersion int32                `enc:"-"`
 
 	// Mirror is a random value generated on client startup that is used to identify self-connections
 	Mirror uint32
@@ -348,16 +449,36 @@ type IntroductionMessage struct {
 	// MaxDropletPrecision uint8 // maximum number of decimal places for announced txns
 	// UserAgent           string `enc:",maxlen=256"`
 	// GenesisHash         cipher.SHA256 // genesis block hash
+	// NetworkID           [4]byte // first 4 bytes of the genesis block hash
+	// SessionPubkey       cipher.PubKey // ephemeral pubkey for encrypted peer exchange, optional
 	Extra []byte `enc:",omitempty"`
 }
 
-// NewIntroductionMessage creates introduction message
-func NewIntroductionMessage(mirror uint32, version int32, port uint16, pubkey cipher.PubKey, userAgent string, verifyParams params.VerifyTxn, genesisHash cipher.SHA256) *IntroductionMessage {
+// NetworkID identifies the network of a node, so that nodes of different networks
+// which share the same port range do not peer with each other.
+// It is the first 4 bytes of the genesis block hash, so it is unique per network.
+type NetworkID [4]byte
+
+// NewNetworkID returns the NetworkID of the network with the given genesis block hash
+func NewNetworkID(genesisHash cipher.SHA256) NetworkID {
+	var id NetworkID
+	copy(id[:], genesisHash[:len(id)])
+	return id
+}
+
+// NewIntroductionMessage creates introduction message.
+// If sessionPubkey is not null, it is appended to the extra data to establish an encrypted peer exchange session
+func NewIntroductionMessage(mirror uint32, version int32, port uint16, pubkey cipher.PubKey, userAgent string, verifyParams params.VerifyTxn, genesisHash cipher.SHA256, sessionPubkey cipher.PubKey) *IntroductionMessage {
+	extra := newIntroductionMessageExtra(pubkey, userAgent, verifyParams, genesisHash)
+	if !sessionPubkey.Null() {
+		extra = append(extra, sessionPubkey[:]...)
+	}
+
 	return &IntroductionMessage{
 		Mirror:          mirror,
 		ProtocolVersion: version,
 		ListenPort:      port,
-		Extra:           newIntroductionMessageExtra(pubkey, userAgent, verifyParams, genesisHash),
+		Extra:           extra,
 	}
 }
 
@@ -380,7 +501,9 @@ func newIntroductionMessageExtra(pubkey cipher.PubKey, userAgent string, verifyP
 	userAgentSerialized := encoder.SerializeString(userAgent)
 	verifyParamsSerialized := encoder.Serialize(verifyParams)
 
-	extra := make([]byte, len(pubkey)+len(userAgentSerialized)+len(verifyParamsSerialized)+len(genesisHash))
+	networkID := NewNetworkID(genesisHash)
+
+	extra := make([]byte, len(pubkey)+len(userAgentSerialized)+len(verifyParamsSerialized)+len(genesisHash)+len(networkID))
 
 	copy(extra[:len(pubkey)], pubkey[:])
 	i := len(pubkey)
@@ -389,6 +512,8 @@ func newIntroductionMessageExtra(pubkey cipher.PubKey, userAgent string, verifyP
 	copy(extra[i:], userAgentSerialized)
 	i += len(userAgentSerialized)
 	copy(extra[i:i+len(genesisHash)], genesisHash[:])
+	i += len(genesisHash)
+	copy(extra[i:i+len(networkID)], networkID[:])
 
 	return extra
 }
@@ -500,7 +625,12 @@ func (intro *IntroductionMessage) Verify(dc DaemonConfig, logFields logrus.Field
 		return ErrDisconnectVersionNotSupported
 	}
 
-	logger.WithFields(logFields).WithField("protocolVersion", intro.ProtocolVersion).Debug("Peer protocol version accepted")
+	intro.NegotiatedProtocolVersion = negotiateProtocolVersion(dc.ProtocolVersion, intro.ProtocolVersion)
+
+	logger.WithFields(logFields).WithFields(logrus.Fields{
+		"protocolVersion":           intro.ProtocolVersion,
+		"negotiatedProtocolVersion": intro.NegotiatedProtocolVersion,
+	}).Debug("Peer protocol version accepted")
 
 	// v24 does not send blockchain pubkey or user agent
 	// v25 sends blockchain pubkey and user agent
@@ -508,6 +638,7 @@ func (intro *IntroductionMessage) Verify(dc DaemonConfig, logFields logrus.Field
 	// v26 would check the blockchain pubkey and reject if not matched or not provided, and parses a user agent
 	// v26 adds genesis hash
 	// v27 would require and check the genesis hash
+	// the network ID is added after the genesis hash, and is checked if the peer sends it or a genesis hash
 	extraLen := len(intro.Extra)
 	if extraLen == 0 {
 		logger.WithFields(logFields).Warning("Blockchain pubkey is not provided")
@@ -580,6 +711,44 @@ func (intro *IntroductionMessage) Verify(dc DaemonConfig, logFields logrus.Field
 	}
 	copy(intro.GenesisHash[:], intro.Extra[i:])
 
+	if remainingLen == 0 {
+		return nil
+	}
+
+	i += len(intro.GenesisHash)
+	remainingLen = extraLen - i
+	if remainingLen > 0 && remainingLen < len(intro.NetworkID) {
+		logger.WithFields(logFields).Warning("Extra data network ID could not be deserialized: not enough data")
+		return ErrDisconnectInvalidExtraData
+	}
+
+	// Peers that send a genesis hash without a network ID are on the network of their genesis hash
+	if remainingLen == 0 {
+		intro.NetworkID = NewNetworkID(intro.GenesisHash)
+	} else {
+		copy(intro.NetworkID[:], intro.Extra[i:])
+	}
+
+	if networkID := NewNetworkID(dc.GenesisHash); intro.NetworkID != networkID {
+		logger.WithFields(logFields).WithFields(logrus.Fields{
+			"networkID":       fmt.Sprintf("%x", intro.NetworkID[:]),
+			"daemonNetworkID": fmt.Sprintf("%x", networkID[:]),
+		}).Warning("Network ID does not match")
+		return ErrDisconnectNetworkIDNotMatched
+	}
+
+	// The session pubkey follows the network ID. Peers that do not send it exchange peers unencrypted
+	i += len(intro.NetworkID)
+	if extraLen-i < len(intro.SessionPubkey) {
+		return nil
+	}
+
+	copy(intro.SessionPubkey[:], intro.Extra[i:])
+	if err := intro.SessionPubkey.Verify(); err != nil {
+		logger.WithError(err).WithFields(logFields).Warning("Extra data session pubkey is invalid")
+		return ErrDisconnectInvalidExtraData
+	}
+
 	return nil
 }
 
@@ -624,7 +793,7 @@ func (ping *PingMessage) process(d daemoner) {
 	}
 }
 
-// PongMessage Sent in reply to a PingMessage.  No action is taken when this is received.
+// PongMessage Sent in reply to a PingMessage. The round trip time of the connection is measured when this is received.
 type PongMessage struct {
 }
 
@@ -645,14 +814,15 @@ func (pong *PongMessage) Decode(buf []byte) (uint64, error) {
 
 // Handle handles message
 func (pong *PongMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
-	// There is nothing to do; gnet updates Connection.LastMessage internally
-	// when this is received
-	if daemon.(daemoner).DaemonConfig().LogPings {
+	// gnet updates Connection.LastReceived internally when this is received
+	d := daemon.(daemoner)
+	if d.DaemonConfig().LogPings {
 		logger.WithFields(logrus.Fields{
 			"addr":   mc.Addr,
 			"gnetID": mc.ConnID,
 		}).Debug("Received pong")
 	}
+	d.recordPong(mc.Addr)
 	return nil
 }
 
@@ -776,7 +946,13 @@ func (gbm *GetBlocksMessage) process(d daemoner) {
 	// Fetch and return signed blocks since LastBlock
 	blocks, err := d.getSignedBlocksSince(gbm.LastBlock, requestedBlocks)
 	if err != nil {
-		logger.WithFields(fields).WithError(err).Error("getSignedBlocksSince failed")
+		switch err.(type) {
+		case visor.ErrBlockPruned:
+			// This node is pruned and can't serve historical blocks
+			logger.WithFields(fields).WithError(err).Debug("getSignedBlocksSince failed")
+		default:
+			logger.WithFields(fields).WithError(err).Error("getSignedBlocksSince failed")
+		}
 		return
 	}
 
@@ -796,7 +972,102 @@ func (gbm *GetBlocksMessage) process(d daemoner) {
 	}
 }
 
-// GiveBlocksMessage sent in response to GetBlocksMessage, or unsolicited
+// GetBlocksRangeMessage sent to request the blocks from StartSeq to EndSeq, including both.
+// Unlike GetBlocksMessage, it can request blocks below the peer's last announced block,
+// which lets a node that is behind pull blocks that are no longer announced to it.
+// Only sent to peers with protocol version getBlocksRangeProtocolVersion or higher.
+type GetBlocksRangeMessage struct {
+	StartSeq uint64
+	EndSeq   uint64
+	c        *gnet.MessageContext `enc:"-"`
+}
+
+// NewGetBlocksRangeMessage creates GetBlocksRangeMessage
+func NewGetBlocksRangeMessage(startSeq, endSeq uint64) *GetBlocksRangeMessage {
+	return &GetBlocksRangeMessage{
+		StartSeq: startSeq,
+		EndSeq:   endSeq,
+	}
+}
+
+// EncodeSize implements gnet.Serializer
+func (gbm *GetBlocksRangeMessage) EncodeSize() uint64 {
+	return encodeSizeGetBlocksRangeMessage(gbm)
+}
+
+// Encode implements gnet.Serializer
+func (gbm *GetBlocksRangeMessage) Encode(buf []byte) error {
+	return encodeGetBlocksRangeMessageToBuffer(buf, gbm)
+}
+
+// Decode implements gnet.Serializer
+func (gbm *GetBlocksRangeMessage) Decode(buf []byte) (uint64, error) {
+	return decodeGetBlocksRangeMessage(buf, gbm)
+}
+
+// Handle handles message
+func (gbm *GetBlocksRangeMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	gbm.c = mc
+	return daemon.(daemoner).recordMessageEvent(gbm, mc)
+}
+
+// process sends the requested blocks that we have, up to MaxGetBlocksResponseCount blocks
+func (gbm *GetBlocksRangeMessage) process(d daemoner) {
+	dc := d.DaemonConfig()
+	if dc.DisableNetworking {
+		return
+	}
+
+	fields := logrus.Fields{
+		"addr":     gbm.c.Addr,
+		"gnetID":   gbm.c.ConnID,
+		"startSeq": gbm.StartSeq,
+		"endSeq":   gbm.EndSeq,
+	}
+
+	if gbm.StartSeq > gbm.EndSeq || dc.MaxGetBlocksResponseCount == 0 {
+		logger.WithFields(fields).Debug("GetBlocksRangeMessage range is empty, ignoring")
+		return
+	}
+
+	// Cap the number of requested blocks
+	endSeq := gbm.EndSeq
+	if endSeq-gbm.StartSeq >= dc.MaxGetBlocksResponseCount {
+		logger.WithFields(logrus.Fields{
+			"maxRequestedBlocks": dc.MaxGetBlocksResponseCount,
+		}).WithFields(fields).Debug("GetBlocksRangeMessage range exceeds configured limit, reducing")
+		endSeq = gbm.StartSeq + dc.MaxGetBlocksResponseCount - 1
+	}
+
+	blocks, err := d.getSignedBlocksInRange(gbm.StartSeq, endSeq)
+	if err != nil {
+		switch err.(type) {
+		case visor.ErrBlockPruned:
+			// This node is pruned and can't serve historical blocks
+			logger.WithFields(fields).WithError(err).Debug("getSignedBlocksInRange failed")
+		default:
+			logger.WithFields(fields).WithError(err).Error("getSignedBlocksInRange failed")
+		}
+		return
+	}
+
+	if len(blocks) == 0 {
+		return
+	}
+
+	logger.WithFields(fields).Debugf("GetBlocksRangeMessage: replying with %d blocks", len(blocks))
+
+	m := NewGiveBlocksMessage(blocks, dc.MaxOutgoingMessageLength)
+	if len(m.Blocks) != len(blocks) {
+		logger.WithField("startBlockSeq", blocks[0].Head.BkSeq).WithFields(fields).Warningf("NewGiveBlocksMessage truncated %d blocks to %d blocks", len(blocks), len(m.Blocks))
+	}
+
+	if err := d.sendMessage(gbm.c.Addr, m); err != nil {
+		logger.WithFields(fields).WithError(err).Error("Send GiveBlocksMessage failed")
+	}
+}
+
+// GiveBlocksMessage sent in response to GetBlocksMessage or GetBlocksRangeMessage, or unsolicited
 type GiveBlocksMessage struct {
 	Blocks []coin.SignedBlock   `enc:",maxlen=128"`
 	c      *gnet.MessageContext `enc:"-"`
@@ -881,6 +1152,9 @@ func (m *GiveBlocksMessage) process(d daemoner) {
 		return
 	}
 
+	// The peer replied, the blocks it announced can be requested again if they are still missing after this message
+	d.blockRequestsAnswered(m.c.Addr)
+
 	// These DB queries are not performed in a transaction for performance reasons.
 	// It is not necessary that the blocks be executed together in a single transaction.
 
@@ -991,6 +1265,13 @@ func (abm *AnnounceBlocksMessage) process(d daemoner) {
 		return
 	}
 
+	// Peers often announce the same block several times.
+	// The blocks are requested once, the announcements of the same seq by any peer are discarded
+	// before reading the database while the request is outstanding
+	if d.blockRequestOutstanding(abm.MaxBkSeq) {
+		return
+	}
+
 	fields := logrus.Fields{
 		"addr":   abm.c.Addr,
 		"gnetID": abm.c.ConnID,
@@ -1010,12 +1291,25 @@ func (abm *AnnounceBlocksMessage) process(d daemoner) {
 		return
 	}
 
-	// TODO: Should this be block get request for current sequence?
-	// If client is not caught up, won't attempt to get block
-	m := NewGetBlocksMessage(headBkSeq, d.DaemonConfig().GetBlocksRequestCount)
+	// Pull the blocks we are missing from the peer that announced them.
+	// Peers that don't support GetBlocksRangeMessage are sent a GetBlocksMessage instead.
+	var m gnet.Message
+	if version, ok := d.connectionProtocolVersion(abm.c.Addr); ok && version >= getBlocksRangeProtocolVersion {
+		endSeq := abm.MaxBkSeq
+		if n := d.DaemonConfig().GetBlocksRequestCount; n > 0 && endSeq-headBkSeq > n {
+			endSeq = headBkSeq + n
+		}
+		m = NewGetBlocksRangeMessage(headBkSeq+1, endSeq)
+	} else {
+		m = NewGetBlocksMessage(headBkSeq, d.DaemonConfig().GetBlocksRequestCount)
+	}
+
 	if err := d.sendMessage(abm.c.Addr, m); err != nil {
-		logger.WithError(err).WithFields(fields).Error("Send GetBlocksMessage")
+		logger.WithError(err).WithFields(fields).Errorf("Send %T failed", m)
+		return
 	}
+
+	d.recordBlockRequest(abm.MaxBkSeq, abm.c.Addr)
 }
 
 // SendingTxnsMessage send transaction message interface
@@ -1376,15 +1670,159 @@ func (gtm *GiveTxnsMessage) process(d daemoner) {
 		return
 	}
 
-	// Announce these transactions to peers
+	// Announce these transactions to a random subset of peers, which announce them further
 	m := NewAnnounceTxnsMessage(hashes, dc.MaxOutgoingMessageLength)
 	if len(m.Transactions) != len(hashes) {
 		logger.Warningf("NewAnnounceTxnsMessage truncated %d hashes to %d hashes", len(hashes), len(m.Transactions))
 	}
 
-	if ids, err := d.broadcastMessage(m); err != nil {
+	if ids, err := d.gossipMessage(m); err != nil {
 		logger.WithError(err).Warning("Broadcast AnnounceTxnsMessage failed")
 	} else {
 		logger.Debugf("Announced %d transactions to %d peers", len(hashes), len(ids))
 	}
 }
+
+// JoinRequestMessage is sent by a coinjoin participant to a coordinator, to join the next coinjoin round
+// with Inputs, spent to Output. The coins of the inputs must equal the coins of the output.
+type JoinRequestMessage struct {
+	Inputs []cipher.SHA256 `enc:",maxlen=64"`
+	Output coin.TransactionOutput
+	c      *gnet.MessageContext `enc:"-"`
+}
+
+// NewJoinRequestMessage creates JoinRequestMessage
+func NewJoinRequestMessage(inputs []cipher.SHA256, output coin.TransactionOutput) *JoinRequestMessage {
+	return &JoinRequestMessage{
+		Inputs: inputs,
+		Output: output,
+	}
+}
+
+// EncodeSize implements gnet.Serializer
+func (jrm *JoinRequestMessage) EncodeSize() uint64 {
+	return encodeSizeJoinRequestMessage(jrm)
+}
+
+// Encode implements gnet.Serializer
+func (jrm *JoinRequestMessage) Encode(buf []byte) error {
+	return encodeJoinRequestMessageToBuffer(buf, jrm)
+}
+
+// Decode implements gnet.Serializer
+func (jrm *JoinRequestMessage) Decode(buf []byte) (uint64, error) {
+	return decodeJoinRequestMessage(buf, jrm)
+}
+
+// Handle handles message
+func (jrm *JoinRequestMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	jrm.c = mc
+	return daemon.(daemoner).recordMessageEvent(jrm, mc)
+}
+
+// process adds the request to the coinjoin round, if this node is a coordinator
+func (jrm *JoinRequestMessage) process(d daemoner) {
+	if d.DaemonConfig().DisableNetworking {
+		return
+	}
+
+	if err := d.coinJoinRequest(jrm.c.Addr, jrm); err != nil {
+		logger.WithError(err).WithField("addr", jrm.c.Addr).Info("Coinjoin request rejected")
+	}
+}
+
+// PartialTxMessage is sent by a coinjoin coordinator to the participants of a round,
+// with the unsigned coinjoin transaction for them to sign
+type PartialTxMessage struct {
+	Transaction coin.Transaction
+	c           *gnet.MessageContext `enc:"-"`
+}
+
+// NewPartialTxMessage creates PartialTxMessage
+func NewPartialTxMessage(txn coin.Transaction) *PartialTxMessage {
+	return &PartialTxMessage{
+		Transaction: txn,
+	}
+}
+
+// EncodeSize implements gnet.Serializer
+func (ptm *PartialTxMessage) EncodeSize() uint64 {
+	return encodeSizePartialTxMessage(ptm)
+}
+
+// Encode implements gnet.Serializer
+func (ptm *PartialTxMessage) Encode(buf []byte) error {
+	return encodePartialTxMessageToBuffer(buf, ptm)
+}
+
+// Decode implements gnet.Serializer
+func (ptm *PartialTxMessage) Decode(buf []byte) (uint64, error) {
+	return decodePartialTxMessage(buf, ptm)
+}
+
+// Handle handles message
+func (ptm *PartialTxMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	ptm.c = mc
+	return daemon.(daemoner).recordMessageEvent(ptm, mc)
+}
+
+// process signs our inputs of the coinjoin transaction, if we sent a join request to the coordinator
+func (ptm *PartialTxMessage) process(d daemoner) {
+	if d.DaemonConfig().DisableNetworking {
+		return
+	}
+
+	if err := d.coinJoinPartialTx(ptm.c.Addr, ptm); err != nil {
+		logger.WithError(err).WithField("addr", ptm.c.Addr).Info("Coinjoin transaction not signed")
+	}
+}
+
+// SignedInputMessage is sent by a coinjoin participant to the coordinator, with the signature
+// of one of its inputs of the coinjoin transaction with InnerHash
+type SignedInputMessage struct {
+	InnerHash cipher.SHA256
+	Input     cipher.SHA256
+	Sig       cipher.Sig
+	c         *gnet.MessageContext `enc:"-"`
+}
+
+// NewSignedInputMessage creates SignedInputMessage
+func NewSignedInputMessage(innerHash, input cipher.SHA256, sig cipher.Sig) *SignedInputMessage {
+	return &SignedInputMessage{
+		InnerHash: innerHash,
+		Input:     input,
+		Sig:       sig,
+	}
+}
+
+// EncodeSize implements gnet.Serializer
+func (sim *SignedInputMessage) EncodeSize() uint64 {
+	return encodeSizeSignedInputMessage(sim)
+}
+
+// Encode implements gnet.Serializer
+func (sim *SignedInputMessage) Encode(buf []byte) error {
+	return encodeSignedInputMessageToBuffer(buf, sim)
+}
+
+// Decode implements gnet.Serializer
+func (sim *SignedInputMessage) Decode(buf []byte) (uint64, error) {
+	return decodeSignedInputMessage(buf, sim)
+}
+
+// Handle handles message
+func (sim *SignedInputMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	sim.c = mc
+	return daemon.(daemoner).recordMessageEvent(sim, mc)
+}
+
+// process adds the signature to the coinjoin transaction, if this node is a coordinator
+func (sim *SignedInputMessage) process(d daemoner) {
+	if d.DaemonConfig().DisableNetworking {
+		return
+	}
+
+	if err := d.coinJoinSignedInput(sim.c.Addr, sim); err != nil {
+		logger.WithError(err).WithField("addr", sim.c.Addr).Info("Coinjoin signed input rejected")
+	}
+}
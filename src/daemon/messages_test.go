@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -17,6 +18,7 @@ import (
 	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/testutil"
 	"github.com/skycoin/skycoin/src/util/useragent"
+	"github.com/skycoin/skycoin/src/visor"
 )
 
 func TestIntroductionMessage(t *testing.T) {
@@ -32,7 +34,36 @@ func TestIntroductionMessage(t *testing.T) {
 		MaxTransactionSize:  32768,
 		MaxDropletPrecision: 3,
 	}, genesisHash)
-	invalidGenesisHashExtra = invalidGenesisHashExtra[:len(invalidGenesisHashExtra)-2]
+	invalidGenesisHashExtra = invalidGenesisHashExtra[:len(invalidGenesisHashExtra)-len(NetworkID{})-2]
+
+	invalidNetworkIDExtra := newIntroductionMessageExtra(pubkey, "skycoin:0.26.0", params.VerifyTxn{
+		BurnFactor:          4,
+		MaxTransactionSize:  32768,
+		MaxDropletPrecision: 3,
+	}, genesisHash)
+	invalidNetworkIDExtra = invalidNetworkIDExtra[:len(invalidNetworkIDExtra)-2]
+
+	// Peers that send a genesis hash without a network ID
+	noNetworkIDExtra := newIntroductionMessageExtra(pubkey, "skycoin:0.26.0", params.VerifyTxn{
+		BurnFactor:          4,
+		MaxTransactionSize:  32768,
+		MaxDropletPrecision: 3,
+	}, genesisHash)
+	noNetworkIDExtra = noNetworkIDExtra[:len(noNetworkIDExtra)-len(NetworkID{})]
+
+	otherGenesisHash := testutil.RandSHA256(t)
+	otherNoNetworkIDExtra := newIntroductionMessageExtra(pubkey, "skycoin:0.26.0", params.VerifyTxn{
+		BurnFactor:          4,
+		MaxTransactionSize:  32768,
+		MaxDropletPrecision: 3,
+	}, otherGenesisHash)
+	otherNoNetworkIDExtra = otherNoNetworkIDExtra[:len(otherNoNetworkIDExtra)-len(NetworkID{})]
+
+	sessionPubkey, _ := cipher.GenerateKeyPair()
+	var invalidSessionPubkey cipher.PubKey
+	for i := range invalidSessionPubkey {
+		invalidSessionPubkey[i] = 0xff
+	}
 
 	type daemonMockValue struct {
 		protocolVersion          uint32
@@ -56,6 +87,7 @@ func TestIntroductionMessage(t *testing.T) {
 		mockValue            daemonMockValue
 		userAgent            useragent.Data
 		unconfirmedVerifyTxn params.VerifyTxn
+		sessionPubkey        cipher.PubKey
 		intro                *IntroductionMessage
 	}{
 		{
@@ -190,6 +222,69 @@ func TestIntroductionMessage(t *testing.T) {
 				}, genesisHash), []byte("additional data")...),
 			},
 		},
+		{
+			name: "INTR message with session pubkey",
+			addr: "121.121.121.121:6000",
+			mockValue: daemonMockValue{
+				mirror:          10000,
+				protocolVersion: 1,
+				pubkey:          pubkey,
+				connectionIntroduced: &connection{
+					Addr: "121.121.121.121:6000",
+					ConnectionDetails: ConnectionDetails{
+						ListenPort: 6000,
+						UserAgent: useragent.Data{
+							Coin:    "skycoin",
+							Version: "0.26.0",
+						},
+						UnconfirmedVerifyTxn: params.VerifyTxn{
+							BurnFactor:          4,
+							MaxTransactionSize:  32768,
+							MaxDropletPrecision: 3,
+						},
+					},
+				},
+			},
+			userAgent: useragent.Data{
+				Coin:    "skycoin",
+				Version: "0.26.0",
+			},
+			unconfirmedVerifyTxn: params.VerifyTxn{
+				BurnFactor:          4,
+				MaxTransactionSize:  32768,
+				MaxDropletPrecision: 3,
+			},
+			sessionPubkey: sessionPubkey,
+			intro: NewIntroductionMessage(10001, 1, 6000, pubkey, "skycoin:0.26.0", params.VerifyTxn{
+				BurnFactor:          4,
+				MaxTransactionSize:  32768,
+				MaxDropletPrecision: 3,
+			}, genesisHash, sessionPubkey),
+		},
+		{
+			name: "INTR message with invalid session pubkey",
+			addr: "121.121.121.121:6000",
+			mockValue: daemonMockValue{
+				mirror:           10000,
+				protocolVersion:  1,
+				pubkey:           pubkey,
+				disconnectReason: ErrDisconnectInvalidExtraData,
+			},
+			userAgent: useragent.Data{
+				Coin:    "skycoin",
+				Version: "0.26.0",
+			},
+			unconfirmedVerifyTxn: params.VerifyTxn{
+				BurnFactor:          4,
+				MaxTransactionSize:  32768,
+				MaxDropletPrecision: 3,
+			},
+			intro: NewIntroductionMessage(10001, 1, 6000, pubkey, "skycoin:0.26.0", params.VerifyTxn{
+				BurnFactor:          4,
+				MaxTransactionSize:  32768,
+				MaxDropletPrecision: 3,
+			}, genesisHash, invalidSessionPubkey),
+		},
 		{
 			name: "INTR message with extra fields but invalid genesis hash data",
 			addr: "121.121.121.121:6000",
@@ -215,6 +310,97 @@ func TestIntroductionMessage(t *testing.T) {
 				Extra:           invalidGenesisHashExtra,
 			},
 		},
+		{
+			name: "INTR message with extra fields but invalid network ID data",
+			addr: "121.121.121.121:6000",
+			mockValue: daemonMockValue{
+				mirror:           10000,
+				protocolVersion:  1,
+				pubkey:           pubkey,
+				disconnectReason: ErrDisconnectInvalidExtraData,
+			},
+			intro: &IntroductionMessage{
+				Mirror:          10001,
+				ListenPort:      6000,
+				ProtocolVersion: 1,
+				Extra:           invalidNetworkIDExtra,
+			},
+		},
+		{
+			name: "INTR message with different network ID",
+			addr: "121.121.121.121:6000",
+			mockValue: daemonMockValue{
+				mirror:           10000,
+				protocolVersion:  1,
+				pubkey:           pubkey,
+				disconnectReason: ErrDisconnectNetworkIDNotMatched,
+			},
+			intro: &IntroductionMessage{
+				Mirror:          10001,
+				ListenPort:      6000,
+				ProtocolVersion: 1,
+				Extra: newIntroductionMessageExtra(pubkey, "skycoin:0.26.0", params.VerifyTxn{
+					BurnFactor:          4,
+					MaxTransactionSize:  32768,
+					MaxDropletPrecision: 3,
+				}, otherGenesisHash),
+			},
+		},
+		{
+			name: "INTR message with genesis hash of a different network and no network ID",
+			addr: "121.121.121.121:6000",
+			mockValue: daemonMockValue{
+				mirror:           10000,
+				protocolVersion:  1,
+				pubkey:           pubkey,
+				disconnectReason: ErrDisconnectNetworkIDNotMatched,
+			},
+			intro: &IntroductionMessage{
+				Mirror:          10001,
+				ListenPort:      6000,
+				ProtocolVersion: 1,
+				Extra:           otherNoNetworkIDExtra,
+			},
+		},
+		{
+			name: "INTR message with genesis hash and no network ID",
+			addr: "121.121.121.121:6000",
+			mockValue: daemonMockValue{
+				mirror:          10000,
+				protocolVersion: 1,
+				pubkey:          pubkey,
+				connectionIntroduced: &connection{
+					Addr: "121.121.121.121:6000",
+					ConnectionDetails: ConnectionDetails{
+						ListenPort: 6000,
+						UserAgent: useragent.Data{
+							Coin:    "skycoin",
+							Version: "0.26.0",
+						},
+						UnconfirmedVerifyTxn: params.VerifyTxn{
+							BurnFactor:          4,
+							MaxTransactionSize:  32768,
+							MaxDropletPrecision: 3,
+						},
+					},
+				},
+			},
+			userAgent: useragent.Data{
+				Coin:    "skycoin",
+				Version: "0.26.0",
+			},
+			unconfirmedVerifyTxn: params.VerifyTxn{
+				BurnFactor:          4,
+				MaxTransactionSize:  32768,
+				MaxDropletPrecision: 3,
+			},
+			intro: &IntroductionMessage{
+				Mirror:          10001,
+				ListenPort:      6000,
+				ProtocolVersion: 1,
+				Extra:           noNetworkIDExtra,
+			},
+		},
 		{
 			name: "INTR message with different pubkey",
 			addr: "121.121.121.121:6000",
@@ -500,6 +686,7 @@ func TestIntroductionMessage(t *testing.T) {
 				},
 				Mirror:           tc.mockValue.mirror,
 				BlockchainPubkey: tc.mockValue.pubkey,
+				GenesisHash:      genesisHash,
 			})
 			d.On("recordMessageEvent", tc.intro, mc).Return(tc.mockValue.recordMessageEventErr)
 			d.On("Disconnect", tc.addr, tc.mockValue.disconnectReason).Return(tc.mockValue.disconnectErr)
@@ -532,11 +719,86 @@ func TestIntroductionMessage(t *testing.T) {
 			} else {
 				d.AssertNotCalled(t, "Disconnect", mock.Anything, mock.Anything)
 				require.Equal(t, genesisHash, tc.intro.GenesisHash)
+				require.Equal(t, NewNetworkID(genesisHash), tc.intro.NetworkID)
+				require.Equal(t, tc.sessionPubkey, tc.intro.SessionPubkey)
 			}
 		})
 	}
 }
 
+func TestIntroductionMessageNegotiateProtocolVersion(t *testing.T) {
+	pubkey, _ := cipher.GenerateKeyPair()
+	genesisHash := testutil.RandSHA256(t)
+	verifyParams := params.VerifyTxn{
+		BurnFactor:          2,
+		MaxTransactionSize:  32768,
+		MaxDropletPrecision: 3,
+	}
+
+	newConfig := func(mirror uint32, version int32) DaemonConfig {
+		dc := NewDaemonConfig()
+		dc.Mirror = mirror
+		dc.ProtocolVersion = version
+		dc.MinProtocolVersion = 2
+		dc.BlockchainPubkey = pubkey
+		dc.GenesisHash = genesisHash
+		return dc
+	}
+
+	newIntro := func(dc DaemonConfig) *IntroductionMessage {
+		return NewIntroductionMessage(dc.Mirror, dc.ProtocolVersion, 6000, pubkey, "skycoin:0.26.0", verifyParams, genesisHash, cipher.PubKey{})
+	}
+
+	// The old node doesn't support GetBlocksRangeMessage
+	oldNode := newConfig(1, getBlocksRangeProtocolVersion-1)
+	newNode := newConfig(2, getBlocksRangeProtocolVersion)
+
+	// Both nodes agree on the old node's version
+	intro := newIntro(oldNode)
+	require.NoError(t, intro.Verify(newNode, nil))
+	require.Equal(t, oldNode.ProtocolVersion, intro.NegotiatedProtocolVersion)
+
+	intro = newIntro(newNode)
+	require.NoError(t, intro.Verify(oldNode, nil))
+	require.Equal(t, oldNode.ProtocolVersion, intro.NegotiatedProtocolVersion)
+
+	// Two new nodes use the new version
+	intro = newIntro(newConfig(3, getBlocksRangeProtocolVersion))
+	require.NoError(t, intro.Verify(newNode, nil))
+	require.Equal(t, newNode.ProtocolVersion, intro.NegotiatedProtocolVersion)
+
+	// A node below the minimum supported version is rejected
+	intro = newIntro(newConfig(4, 1))
+	require.Equal(t, ErrDisconnectVersionNotSupported, intro.Verify(newNode, nil))
+
+	// The new node sends a GetBlocksMessage to the old node, and a GetBlocksRangeMessage to new nodes
+	for _, tc := range []struct {
+		version int32
+		msg     gnet.Message
+	}{
+		{oldNode.ProtocolVersion, NewGetBlocksMessage(5, newNode.GetBlocksRequestCount)},
+		{newNode.ProtocolVersion, NewGetBlocksRangeMessage(6, 8)},
+	} {
+		d := &mockDaemoner{}
+		m := &AnnounceBlocksMessage{
+			MaxBkSeq: 8,
+			c: &gnet.MessageContext{
+				Addr: "121.121.121.121:6000",
+			},
+		}
+		d.On("DaemonConfig").Return(newNode)
+		d.On("blockRequestOutstanding", m.MaxBkSeq).Return(false)
+		d.On("headBkSeq").Return(uint64(5), true, nil)
+		d.On("connectionProtocolVersion", m.c.Addr).Return(tc.version, true)
+		d.On("sendMessage", m.c.Addr, tc.msg).Return(nil)
+		d.On("recordBlockRequest", m.MaxBkSeq, m.c.Addr)
+
+		m.process(d)
+
+		d.AssertCalled(t, "sendMessage", m.c.Addr, tc.msg)
+	}
+}
+
 func TestMessageEncodeDecode(t *testing.T) {
 	update := false
 
@@ -592,6 +854,14 @@ func TestMessageEncodeDecode(t *testing.T) {
 				},
 			},
 		},
+		{
+			goldenFile: "encrypted-give-peers-msg.golden",
+			obj:        &EncryptedGivePeersMessage{},
+			msg: &EncryptedGivePeersMessage{
+				Seq:        12345,
+				Ciphertext: []byte{0x35, 0x9c, 0x1a, 0xe0, 0x4f, 0x27, 0x88, 0xd1, 0x0b, 0x6e, 0xf3, 0x52, 0xa4, 0x17, 0xc9, 0x60},
+			},
+		},
 		{
 			goldenFile: "ping-msg.golden",
 			obj:        &PingMessage{},
@@ -610,6 +880,14 @@ func TestMessageEncodeDecode(t *testing.T) {
 				RequestedBlocks: 888899997777,
 			},
 		},
+		{
+			goldenFile: "get-blocks-range-msg.golden",
+			obj:        &GetBlocksRangeMessage{},
+			msg: &GetBlocksRangeMessage{
+				StartSeq: 999988887777,
+				EndSeq:   999988887797,
+			},
+		},
 		{
 			goldenFile: "give-blocks-msg.golden",
 			obj:        &GiveBlocksMessage{},
@@ -839,6 +1117,61 @@ func TestMessageEncodeDecode(t *testing.T) {
 				},
 			},
 		},
+		{
+			goldenFile: "join-request-msg.golden",
+			obj:        &JoinRequestMessage{},
+			msg: &JoinRequestMessage{
+				Inputs: []cipher.SHA256{
+					cipher.MustSHA256FromHex("703f84ee0702b44fc89ce573a239d5fbf185bf5d4e7fc8f4930262bcda1e8fb0"),
+					cipher.MustSHA256FromHex("c9e904862da01f2d7676c12c4342dde36d9a9a9d25be5351e2b57fae6f426bb9"),
+				},
+				Output: coin.TransactionOutput{
+					Address: cipher.MustDecodeBase58Address("29VEn56iRr2TpVVpPoPxUJPfFWuhbLSBRdU"),
+					Coins:   1111111111111111111,
+					Hours:   9999999999999999999,
+				},
+			},
+		},
+		{
+			goldenFile: "partial-tx-msg.golden",
+			obj:        &PartialTxMessage{},
+			msg: &PartialTxMessage{
+				Transaction: coin.Transaction{
+					Length:    220,
+					Type:      0,
+					InnerHash: cipher.MustSHA256FromHex("1773d8901df96bba4c6d65499e11e6ec73a9978c611d1463898ffbc2b49773fc"),
+					Sigs: []cipher.Sig{
+						{},
+						{},
+					},
+					In: []cipher.SHA256{
+						cipher.MustSHA256FromHex("703f84ee0702b44fc89ce573a239d5fbf185bf5d4e7fc8f4930262bcda1e8fb0"),
+						cipher.MustSHA256FromHex("c9e904862da01f2d7676c12c4342dde36d9a9a9d25be5351e2b57fae6f426bb9"),
+					},
+					Out: []coin.TransactionOutput{
+						{
+							Address: cipher.MustDecodeBase58Address("29VEn56iRr2TpVVpPoPxUJPfFWuhbLSBRdU"),
+							Coins:   1111111111111111111,
+							Hours:   9999999999999999999,
+						},
+						{
+							Address: cipher.MustDecodeBase58Address("2bqs99tysFtfs8QPT81kpZWnzTT1rWd8xtQ"),
+							Coins:   9922581002,
+							Hours:   9932900022223334,
+						},
+					},
+				},
+			},
+		},
+		{
+			goldenFile: "signed-input-msg.golden",
+			obj:        &SignedInputMessage{},
+			msg: &SignedInputMessage{
+				InnerHash: cipher.MustSHA256FromHex("1773d8901df96bba4c6d65499e11e6ec73a9978c611d1463898ffbc2b49773fc"),
+				Input:     cipher.MustSHA256FromHex("703f84ee0702b44fc89ce573a239d5fbf185bf5d4e7fc8f4930262bcda1e8fb0"),
+				Sig:       cipher.MustSigFromHex("a711880ae54d1b6b9adade2ef1e743d6d539a78b0cecf1af08107e467956de80ef1d49fb5e896c9d0870ef8bf8a4d328ca0ecf7c1956866867ec56064e68f8a374"),
+			},
+		},
 	}
 
 	if update {
@@ -1086,6 +1419,235 @@ func TestGetBlocksMessageProcess(t *testing.T) {
 	d.AssertExpectations(t)
 }
 
+func TestGetBlocksRangeMessageProcess(t *testing.T) {
+	config := DaemonConfig{
+		DisableNetworking:         false,
+		MaxGetBlocksResponseCount: 20,
+		MaxOutgoingMessageLength:  1024,
+	}
+
+	blocks := make([]coin.SignedBlock, 20)
+
+	cases := []struct {
+		name     string
+		startSeq uint64
+		endSeq   uint64
+		start    uint64
+		end      uint64
+		blocks   []coin.SignedBlock
+		err      error
+	}{
+		{
+			name:     "range within limit",
+			startSeq: 7,
+			endSeq:   10,
+			start:    7,
+			end:      10,
+			blocks:   blocks[:4],
+		},
+		{
+			// request more blocks than MaxGetBlocksResponseCount to verify capping
+			name:     "range capped",
+			startSeq: 7,
+			endSeq:   100,
+			start:    7,
+			end:      26,
+			blocks:   blocks,
+		},
+		{
+			name:     "start after end",
+			startSeq: 10,
+			endSeq:   7,
+		},
+		{
+			name:     "blocks not available",
+			startSeq: 7,
+			endSeq:   10,
+			start:    7,
+			end:      10,
+		},
+		{
+			name:     "blocks pruned",
+			startSeq: 7,
+			endSeq:   10,
+			start:    7,
+			end:      10,
+			err:      visor.ErrBlockPruned{Seq: 7},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &mockDaemoner{}
+
+			m := &GetBlocksRangeMessage{
+				StartSeq: tc.startSeq,
+				EndSeq:   tc.endSeq,
+				c: &gnet.MessageContext{
+					ConnID: 10,
+					Addr:   "127.0.0.1:1234",
+				},
+			}
+
+			d.On("DaemonConfig").Return(config)
+			if tc.startSeq <= tc.endSeq {
+				d.On("getSignedBlocksInRange", tc.start, tc.end).Return(tc.blocks, tc.err)
+			}
+			if len(tc.blocks) != 0 {
+				d.On("sendMessage", "127.0.0.1:1234", NewGiveBlocksMessage(tc.blocks, config.MaxOutgoingMessageLength)).Return(nil)
+			}
+
+			m.process(d)
+
+			d.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAnnounceBlocksMessageProcess(t *testing.T) {
+	config := DaemonConfig{
+		DisableNetworking:     false,
+		GetBlocksRequestCount: 20,
+	}
+
+	cases := []struct {
+		name            string
+		maxBkSeq        uint64
+		protocolVersion int32
+		introduced      bool
+		seen            bool
+		msg             gnet.Message
+		sendErr         error
+	}{
+		{
+			name:     "request outstanding",
+			maxBkSeq: 15,
+			seen:     true,
+		},
+		{
+			name:            "peer is not ahead",
+			maxBkSeq:        10,
+			protocolVersion: getBlocksRangeProtocolVersion,
+			introduced:      true,
+		},
+		{
+			name:            "range request",
+			maxBkSeq:        15,
+			protocolVersion: getBlocksRangeProtocolVersion,
+			introduced:      true,
+			msg:             NewGetBlocksRangeMessage(11, 15),
+		},
+		{
+			name:            "range request capped",
+			maxBkSeq:        100,
+			protocolVersion: getBlocksRangeProtocolVersion,
+			introduced:      true,
+			msg:             NewGetBlocksRangeMessage(11, 30),
+		},
+		{
+			name:            "old protocol version",
+			maxBkSeq:        15,
+			protocolVersion: getBlocksRangeProtocolVersion - 1,
+			introduced:      true,
+			msg:             NewGetBlocksMessage(10, 20),
+		},
+		{
+			name:     "connection not introduced",
+			maxBkSeq: 15,
+			msg:      NewGetBlocksMessage(10, 20),
+		},
+		{
+			name:            "send failed, the request is not recorded",
+			maxBkSeq:        15,
+			protocolVersion: getBlocksRangeProtocolVersion,
+			introduced:      true,
+			msg:             NewGetBlocksRangeMessage(11, 15),
+			sendErr:         errors.New("send failed"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &mockDaemoner{}
+
+			m := &AnnounceBlocksMessage{
+				MaxBkSeq: tc.maxBkSeq,
+				c: &gnet.MessageContext{
+					ConnID: 10,
+					Addr:   "127.0.0.1:1234",
+				},
+			}
+
+			d.On("DaemonConfig").Return(config)
+			d.On("blockRequestOutstanding", tc.maxBkSeq).Return(tc.seen)
+			if !tc.seen {
+				d.On("headBkSeq").Return(uint64(10), true, nil)
+			}
+			if tc.msg != nil {
+				d.On("connectionProtocolVersion", "127.0.0.1:1234").Return(tc.protocolVersion, tc.introduced)
+				d.On("sendMessage", "127.0.0.1:1234", tc.msg).Return(tc.sendErr)
+				if tc.sendErr == nil {
+					d.On("recordBlockRequest", tc.maxBkSeq, "127.0.0.1:1234")
+				}
+			}
+
+			m.process(d)
+
+			d.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEncryptedGivePeersMessageProcess(t *testing.T) {
+	addr := "127.0.0.1:1234"
+	peers := []string{"1.2.3.4:6000", "5.6.7.8:6001"}
+
+	cases := []struct {
+		name    string
+		openErr error
+	}{
+		{
+			name: "decrypted",
+		},
+		{
+			name:    "no session",
+			openErr: ErrNoPEXSession,
+		},
+		{
+			name:    "replayed",
+			openErr: ErrPEXSessionSeqNotIncreasing,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &mockDaemoner{}
+
+			m := &EncryptedGivePeersMessage{
+				Seq: 1,
+				c: &gnet.MessageContext{
+					ConnID: 10,
+					Addr:   addr,
+				},
+			}
+
+			d.On("pexConfig").Return(pex.Config{})
+			if tc.openErr != nil {
+				d.On("openGivePeersMessage", addr, m).Return(nil, tc.openErr)
+				d.On("Disconnect", addr, ErrDisconnectInvalidEncryptedMessage).Return(nil)
+			} else {
+				gpm := NewGivePeersMessage([]pex.Peer{{Addr: peers[0]}, {Addr: peers[1]}}, 1024)
+				d.On("openGivePeersMessage", addr, m).Return(gpm, nil)
+				d.On("addPeers", peers).Return(2)
+			}
+
+			m.process(d)
+
+			d.AssertExpectations(t)
+		})
+	}
+}
+
 func setupMsgEncoding() {
 	gnet.EraseMessages()
 	var messagesConfig = NewMessagesConfig()
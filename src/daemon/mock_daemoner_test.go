@@ -70,6 +70,25 @@ func (_m *mockDaemoner) announceAllValidTxns() error {
 	return r0
 }
 
+// blockRequestOutstanding provides a mock function with given fields: seq
+func (_m *mockDaemoner) blockRequestOutstanding(seq uint64) bool {
+	ret := _m.Called(seq)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(uint64) bool); ok {
+		r0 = rf(seq)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// blockRequestsAnswered provides a mock function with given fields: addr
+func (_m *mockDaemoner) blockRequestsAnswered(addr string) {
+	_m.Called(addr)
+}
+
 // broadcastMessage provides a mock function with given fields: msg
 func (_m *mockDaemoner) broadcastMessage(msg gnet.Message) ([]uint64, error) {
 	ret := _m.Called(msg)
@@ -93,6 +112,48 @@ func (_m *mockDaemoner) broadcastMessage(msg gnet.Message) ([]uint64, error) {
 	return r0, r1
 }
 
+// coinJoinPartialTx provides a mock function with given fields: addr, m
+func (_m *mockDaemoner) coinJoinPartialTx(addr string, m *PartialTxMessage) error {
+	ret := _m.Called(addr, m)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *PartialTxMessage) error); ok {
+		r0 = rf(addr, m)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// coinJoinRequest provides a mock function with given fields: addr, m
+func (_m *mockDaemoner) coinJoinRequest(addr string, m *JoinRequestMessage) error {
+	ret := _m.Called(addr, m)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *JoinRequestMessage) error); ok {
+		r0 = rf(addr, m)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// coinJoinSignedInput provides a mock function with given fields: addr, m
+func (_m *mockDaemoner) coinJoinSignedInput(addr string, m *SignedInputMessage) error {
+	ret := _m.Called(addr, m)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *SignedInputMessage) error); ok {
+		r0 = rf(addr, m)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // connectionIntroduced provides a mock function with given fields: addr, gnetID, m
 func (_m *mockDaemoner) connectionIntroduced(addr string, gnetID uint64, m *IntroductionMessage) (*connection, error) {
 	ret := _m.Called(addr, gnetID, m)
@@ -116,6 +177,27 @@ func (_m *mockDaemoner) connectionIntroduced(addr string, gnetID uint64, m *Intr
 	return r0, r1
 }
 
+// connectionProtocolVersion provides a mock function with given fields: addr
+func (_m *mockDaemoner) connectionProtocolVersion(addr string) (int32, bool) {
+	ret := _m.Called(addr)
+
+	var r0 int32
+	if rf, ok := ret.Get(0).(func(string) int32); ok {
+		r0 = rf(addr)
+	} else {
+		r0 = ret.Get(0).(int32)
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(addr)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
 // disconnectNow provides a mock function with given fields: addr, r
 func (_m *mockDaemoner) disconnectNow(addr string, r gnet.DisconnectReason) error {
 	ret := _m.Called(addr, r)
@@ -190,6 +272,29 @@ func (_m *mockDaemoner) getKnownUnconfirmed(txns []cipher.SHA256) (coin.Transact
 	return r0, r1
 }
 
+// getSignedBlocksInRange provides a mock function with given fields: start, end
+func (_m *mockDaemoner) getSignedBlocksInRange(start uint64, end uint64) ([]coin.SignedBlock, error) {
+	ret := _m.Called(start, end)
+
+	var r0 []coin.SignedBlock
+	if rf, ok := ret.Get(0).(func(uint64, uint64) []coin.SignedBlock); ok {
+		r0 = rf(start, end)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]coin.SignedBlock)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uint64, uint64) error); ok {
+		r1 = rf(start, end)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // getSignedBlocksSince provides a mock function with given fields: seq, count
 func (_m *mockDaemoner) getSignedBlocksSince(seq uint64, count uint64) ([]coin.SignedBlock, error) {
 	ret := _m.Called(seq, count)
@@ -213,6 +318,29 @@ func (_m *mockDaemoner) getSignedBlocksSince(seq uint64, count uint64) ([]coin.S
 	return r0, r1
 }
 
+// gossipMessage provides a mock function with given fields: msg
+func (_m *mockDaemoner) gossipMessage(msg gnet.Message) ([]uint64, error) {
+	ret := _m.Called(msg)
+
+	var r0 []uint64
+	if rf, ok := ret.Get(0).(func(gnet.Message) []uint64); ok {
+		r0 = rf(msg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uint64)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(gnet.Message) error); ok {
+		r1 = rf(msg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // headBkSeq provides a mock function with given fields:
 func (_m *mockDaemoner) headBkSeq() (uint64, bool, error) {
 	ret := _m.Called()
@@ -271,6 +399,29 @@ func (_m *mockDaemoner) injectTransaction(txn coin.Transaction) (bool, *visor.Er
 	return r0, r1, r2
 }
 
+// openGivePeersMessage provides a mock function with given fields: addr, m
+func (_m *mockDaemoner) openGivePeersMessage(addr string, m *EncryptedGivePeersMessage) (*GivePeersMessage, error) {
+	ret := _m.Called(addr, m)
+
+	var r0 *GivePeersMessage
+	if rf, ok := ret.Get(0).(func(string, *EncryptedGivePeersMessage) *GivePeersMessage); ok {
+		r0 = rf(addr, m)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*GivePeersMessage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, *EncryptedGivePeersMessage) error); ok {
+		r1 = rf(addr, m)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // pexConfig provides a mock function with given fields:
 func (_m *mockDaemoner) pexConfig() pex.Config {
 	ret := _m.Called()
@@ -299,11 +450,21 @@ func (_m *mockDaemoner) recordMessageEvent(m asyncMessage, c *gnet.MessageContex
 	return r0
 }
 
+// recordBlockRequest provides a mock function with given fields: seq, addr
+func (_m *mockDaemoner) recordBlockRequest(seq uint64, addr string) {
+	_m.Called(seq, addr)
+}
+
 // recordPeerHeight provides a mock function with given fields: addr, gnetID, height
 func (_m *mockDaemoner) recordPeerHeight(addr string, gnetID uint64, height uint64) {
 	_m.Called(addr, gnetID, height)
 }
 
+// recordPong provides a mock function with given fields: addr
+func (_m *mockDaemoner) recordPong(addr string) {
+	_m.Called(addr)
+}
+
 // requestBlocksFromAddr provides a mock function with given fields: addr
 func (_m *mockDaemoner) requestBlocksFromAddr(addr string) error {
 	ret := _m.Called(addr)
@@ -6,6 +6,7 @@ import (
 	"io"
 	"math/rand"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -90,6 +91,31 @@ func loadCachedPeersFile(path string) (map[string]*Peer, error) {
 	return peers, nil
 }
 
+// LoadPeersFile loads the peers of a peers.json file saved by Pex, sorted by address.
+// It does not need a running Pex, so that the peers of a stopped node can be inspected.
+// Returns an error if the file does not exist.
+func LoadPeersFile(path string) (Peers, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	peersMap, err := loadCachedPeersFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	peers := make(Peers, 0, len(peersMap))
+	for _, p := range peersMap {
+		peers = append(peers, *p)
+	}
+
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].Addr < peers[j].Addr
+	})
+
+	return peers, nil
+}
+
 func (pl *peerlist) setPeers(peers []Peer) {
 	for _, p := range peers {
 		np := p
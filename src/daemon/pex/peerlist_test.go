@@ -507,6 +507,28 @@ func TestPeerlistSave(t *testing.T) {
 	}
 }
 
+func TestLoadPeersFile(t *testing.T) {
+	pl := newPeerlist()
+	pl.setPeers([]Peer{
+		{Addr: testPeers[1], LastSeen: 1000},
+		{Addr: testPeers[0], LastSeen: 2000, Trusted: true},
+	})
+
+	f, removeFile := preparePeerlistFile(t)
+	defer removeFile()
+	require.NoError(t, pl.save(f))
+
+	peers, err := LoadPeersFile(f)
+	require.NoError(t, err)
+	require.Equal(t, Peers{
+		{Addr: testPeers[0], LastSeen: 2000, Trusted: true},
+		{Addr: testPeers[1], LastSeen: 1000},
+	}, peers)
+
+	_, err = LoadPeersFile(f + ".missing")
+	require.True(t, os.IsNotExist(err))
+}
+
 func TestPeerCanTry(t *testing.T) {
 	testData := []struct {
 		LastSeen   int64
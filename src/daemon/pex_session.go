@@ -0,0 +1,111 @@
+package daemon
+
+import (
+	"crypto/aes"
+	gocipher "crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+var (
+	// ErrNoPEXSession the connection did not establish a session key for encrypted peer exchange
+	ErrNoPEXSession = errors.New("Connection has no peer exchange session")
+	// ErrPEXSessionSeqNotIncreasing the sequence number of an encrypted message was not greater than the previous one
+	ErrPEXSessionSeqNotIncreasing = errors.New("Encrypted message sequence number is not increasing")
+	// ErrPEXSessionAuthFailed an encrypted message could not be authenticated
+	ErrPEXSessionAuthFailed = errors.New("Encrypted message authentication failed")
+)
+
+// pexSession encrypts and decrypts the peer exchange messages of a connection with AES-256-GCM.
+// The keys are derived from the ECDH shared secret of the ephemeral session keys that both peers
+// send in their IntroductionMessage. Each direction has its own key, the SHA256 of the shared secret
+// and the sender's session pubkey, so that the nonces of the two directions never collide.
+// The nonce is the sequence number of the message, which must increase to prevent replays.
+type pexSession struct {
+	sendAEAD gocipher.AEAD
+	recvAEAD gocipher.AEAD
+	sendSeq  uint64
+	recvSeq  uint64
+	sync.Mutex
+}
+
+// newPEXSession creates a pexSession from our session secret key and the remote peer's session pubkey
+func newPEXSession(sec cipher.SecKey, remotePubkey cipher.PubKey) (*pexSession, error) {
+	pubkey, err := cipher.PubKeyFromSecKey(sec)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := cipher.ECDH(remotePubkey, sec)
+	if err != nil {
+		return nil, err
+	}
+
+	sendAEAD, err := newPEXSessionAEAD(secret, pubkey)
+	if err != nil {
+		return nil, err
+	}
+
+	recvAEAD, err := newPEXSessionAEAD(secret, remotePubkey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pexSession{
+		sendAEAD: sendAEAD,
+		recvAEAD: recvAEAD,
+	}, nil
+}
+
+func newPEXSessionAEAD(secret []byte, senderPubkey cipher.PubKey) (gocipher.AEAD, error) {
+	b := make([]byte, 0, len(secret)+len(senderPubkey))
+	b = append(b, secret...)
+	b = append(b, senderPubkey[:]...)
+	key := cipher.SumSHA256(b)
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return gocipher.NewGCM(block)
+}
+
+func pexSessionNonce(aead gocipher.AEAD, seq uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], seq)
+	return nonce
+}
+
+// seal encrypts plaintext, returning the sequence number of the message and the ciphertext with its authentication tag
+func (s *pexSession) seal(plaintext []byte) (uint64, []byte) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.sendSeq++
+	seq := s.sendSeq
+
+	return seq, s.sendAEAD.Seal(nil, pexSessionNonce(s.sendAEAD, seq), plaintext, nil)
+}
+
+// open authenticates and decrypts a ciphertext created by the remote peer's seal
+func (s *pexSession) open(seq uint64, ciphertext []byte) ([]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if seq <= s.recvSeq {
+		return nil, ErrPEXSessionSeqNotIncreasing
+	}
+
+	plaintext, err := s.recvAEAD.Open(nil, pexSessionNonce(s.recvAEAD, seq), ciphertext, nil)
+	if err != nil {
+		return nil, ErrPEXSessionAuthFailed
+	}
+
+	s.recvSeq = seq
+
+	return plaintext, nil
+}
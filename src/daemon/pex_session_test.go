@@ -0,0 +1,94 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/daemon/pex"
+)
+
+func newTestPEXSessions(t *testing.T) (*pexSession, *pexSession) {
+	pubA, secA := cipher.GenerateKeyPair()
+	pubB, secB := cipher.GenerateKeyPair()
+
+	a, err := newPEXSession(secA, pubB)
+	require.NoError(t, err)
+
+	b, err := newPEXSession(secB, pubA)
+	require.NoError(t, err)
+
+	return a, b
+}
+
+func TestPEXSession(t *testing.T) {
+	a, b := newTestPEXSessions(t)
+
+	// Both directions can be decrypted by the other side
+	seq, ciphertext := a.seal([]byte("foo"))
+	require.Equal(t, uint64(1), seq)
+	require.NotContains(t, string(ciphertext), "foo")
+
+	plaintext, err := b.open(seq, ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, []byte("foo"), plaintext)
+
+	seq, ciphertext = b.seal([]byte("bar"))
+	require.Equal(t, uint64(1), seq)
+
+	plaintext, err = a.open(seq, ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, []byte("bar"), plaintext)
+
+	// A replayed message is rejected
+	_, err = a.open(seq, ciphertext)
+	require.Equal(t, ErrPEXSessionSeqNotIncreasing, err)
+
+	// A message cannot be decrypted by its sender
+	seq, ciphertext = a.seal([]byte("baz"))
+	require.Equal(t, uint64(2), seq)
+	_, err = a.open(seq, ciphertext)
+	require.Equal(t, ErrPEXSessionAuthFailed, err)
+
+	// A message with a different sequence number is not authenticated
+	_, err = b.open(seq+1, ciphertext)
+	require.Equal(t, ErrPEXSessionAuthFailed, err)
+
+	// A modified message is not authenticated
+	tampered := append([]byte{}, ciphertext...)
+	tampered[0] ^= 1
+	_, err = b.open(seq, tampered)
+	require.Equal(t, ErrPEXSessionAuthFailed, err)
+
+	// A failed message does not advance the sequence number
+	plaintext, err = b.open(seq, ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, []byte("baz"), plaintext)
+
+	// A session with other keys cannot decrypt the message
+	_, c := newTestPEXSessions(t)
+	seq, ciphertext = a.seal([]byte("foo"))
+	_, err = c.open(seq, ciphertext)
+	require.Equal(t, ErrPEXSessionAuthFailed, err)
+}
+
+func TestEncryptedGivePeersMessage(t *testing.T) {
+	a, b := newTestPEXSessions(t)
+
+	gpm := NewGivePeersMessage([]pex.Peer{
+		{Addr: "1.2.3.4:6000"},
+		{Addr: "5.6.7.8:6001"},
+	}, 1024)
+
+	egpm, err := newEncryptedGivePeersMessage(a, gpm)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), egpm.Seq)
+
+	m, err := egpm.open(b)
+	require.NoError(t, err)
+	require.Equal(t, []string{"1.2.3.4:6000", "5.6.7.8:6001"}, m.GetPeers())
+
+	_, err = egpm.open(b)
+	require.Equal(t, ErrPEXSessionSeqNotIncreasing, err)
+}
@@ -36,6 +36,12 @@ type PoolConfig struct {
 	MaxIncomingMessageLength int
 	// Maximum length of outgoing messages in bytes
 	MaxOutgoingMessageLength int
+	// Maximum bytes per second written to all connections, 0 is unlimited
+	OutboundBandwidthLimit int
+	// Maximum bytes per second read from all connections, 0 is unlimited
+	InboundBandwidthLimit int
+	// Number of peer connection events to keep for the connection graph
+	ConnectionGraphSize int
 	// These should be assigned by the controlling daemon
 	address string
 	port    int
@@ -59,6 +65,7 @@ func NewPoolConfig() PoolConfig {
 		MaxDefaultPeerOutgoingConnections: 2,
 		MaxOutgoingMessageLength:          256 * 1024,
 		MaxIncomingMessageLength:          1024 * 1024,
+		ConnectionGraphSize:               1000,
 	}
 }
 
@@ -84,6 +91,9 @@ func NewPool(cfg PoolConfig, d *Daemon) (*Pool, error) {
 	gnetCfg.DefaultConnections = cfg.DefaultConnections
 	gnetCfg.MaxIncomingMessageLength = cfg.MaxIncomingMessageLength
 	gnetCfg.MaxOutgoingMessageLength = cfg.MaxOutgoingMessageLength
+	gnetCfg.OutboundBandwidthLimit = cfg.OutboundBandwidthLimit
+	gnetCfg.InboundBandwidthLimit = cfg.InboundBandwidthLimit
+	gnetCfg.ConnectionGraphSize = cfg.ConnectionGraphSize
 
 	pool, err := gnet.NewConnectionPool(gnetCfg, d)
 	if err != nil {
@@ -128,6 +138,6 @@ func (pool *Pool) getStaleConnections() ([]string, error) {
 }
 
 // IsMaxOutgoingDefaultConnectionsReached returns whether max outgoing default connections reached
-func (pool *Pool) IsMaxOutgoingDefaultConnectionsReached() bool {
+func (pool *Pool) IsMaxOutgoingDefaultConnectionsReached() (bool, error) {
 	return pool.Pool.IsMaxOutgoingDefaultConnectionsReached()
 }
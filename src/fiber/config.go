@@ -49,14 +49,28 @@ type NodeConfig struct {
 	UnconfirmedMaxTransactionSize uint32 `mapstructure:"unconfirmed_max_transaction_size"`
 	// UnconfirmedMaxDropletPrecision is the maximum number of decimals allowed in an unconfirmed transaction
 	UnconfirmedMaxDropletPrecision uint8 `mapstructure:"unconfirmed_max_decimals"`
+	// UnconfirmedMinFeePerByte is the minimum coinhour fee per byte of an unconfirmed transaction
+	UnconfirmedMinFeePerByte uint64 `mapstructure:"unconfirmed_min_fee_per_byte"`
 	// CreateBlockBurnFactor is the burn factor to apply to transactions when publishing blocks
 	CreateBlockBurnFactor uint32 `mapstructure:"create_block_burn_factor"`
 	// CreateBlockMaxTransactionSize is the maximum size of an transaction when publishing blocks
 	CreateBlockMaxTransactionSize uint32 `mapstructure:"create_block_max_transaction_size"`
 	// CreateBlockMaxDropletPrecision is the maximum number of decimals allowed in a transaction when publishing blocks
 	CreateBlockMaxDropletPrecision uint8 `mapstructure:"create_block_max_decimals"`
+	// CreateBlockMinFeePerByte is the minimum coinhour fee per byte of a transaction when publishing blocks
+	CreateBlockMinFeePerByte uint64 `mapstructure:"create_block_min_fee_per_byte"`
 	// MaxBlockTransactionsSize is the maximum total size of transactions in a block when publishing a block
 	MaxBlockTransactionsSize uint32 `mapstructure:"max_block_transactions_size"`
+	// MinBlockInterval is the minimum number of seconds between the timestamps of consecutive blocks, 0 disables the check
+	MinBlockInterval uint64 `mapstructure:"min_block_interval"`
+	// SignalingWindow is the number of blocks before a round boundary whose signals are counted
+	SignalingWindow uint64 `mapstructure:"signaling_window"`
+	// SignalingThreshold is the percent of the blocks of the window that must signal a deployment to activate it
+	SignalingThreshold uint8 `mapstructure:"signaling_threshold"`
+	// SignalingRoundLength is the number of blocks in a signaling round
+	SignalingRoundLength uint64 `mapstructure:"signaling_round_length"`
+	// SignalingDeployments are the parameter changes that can be activated by signaling
+	SignalingDeployments []SignalingDeployment `mapstructure:"signaling_deployments"`
 
 	// DisplayName is the display name of the coin in the wallet e.g. Skycoin
 	DisplayName string `mapstructure:"display_name"`
@@ -82,6 +96,16 @@ type NodeConfig struct {
 	DataDirectory string
 }
 
+// SignalingDeployment is a parameter change activated by signaling a bit of the block header's signaling field
+type SignalingDeployment struct {
+	// Bit is the bit of the signaling field, from 0 to 31
+	Bit uint8 `mapstructure:"bit"`
+	// Parameter is the name of the parameter to change, "max_block_size", "min_fee_per_byte" or "burn_factor"
+	Parameter string `mapstructure:"parameter"`
+	// Value is the value of the parameter while the deployment is active
+	Value uint64 `mapstructure:"value"`
+}
+
 // ParamsConfig are the parameters used to generate params/params.go.
 // These parameters are exposed in an importable package `params` because they
 // may need to be imported by libraries that would not know the node's configured CLI options.
@@ -104,6 +128,12 @@ type ParamsConfig struct {
 	DistributionAddresses []string `mapstructure:"distribution_addresses"`
 	// UserBurnFactor inverse fraction of coinhours that must be burned, this value is used when creating transactions
 	UserBurnFactor uint64 `mapstructure:"user_burn_factor"`
+	// UserMinFeePerByte is the minimum coinhour fee per byte of the transaction size, this value is used when creating transactions
+	UserMinFeePerByte uint64 `mapstructure:"user_min_fee_per_byte"`
+	// UserMaxTxInputs is the maximum number of inputs of a transaction, this value is used when creating transactions
+	UserMaxTxInputs int `mapstructure:"user_max_txn_inputs"`
+	// UserMaxTxOutputs is the maximum number of outputs of a transaction, this value is used when creating transactions
+	UserMaxTxOutputs int `mapstructure:"user_max_txn_outputs"`
 }
 
 // NewConfig loads blockchain config parameters from a config file
@@ -151,10 +181,16 @@ func setDefaults() {
 	viper.SetDefault("node.unconfirmed_burn_factor", 10)
 	viper.SetDefault("node.unconfirmed_max_transaction_size", 32*1024)
 	viper.SetDefault("node.unconfirmed_max_decimals", 3)
+	viper.SetDefault("node.unconfirmed_min_fee_per_byte", 0)
 	viper.SetDefault("node.create_block_burn_factor", 10)
 	viper.SetDefault("node.create_block_max_transaction_size", 32*1024)
 	viper.SetDefault("node.create_block_max_decimals", 3)
+	viper.SetDefault("node.create_block_min_fee_per_byte", 0)
 	viper.SetDefault("node.max_block_transactions_size", 32*1024)
+	viper.SetDefault("node.min_block_interval", 0)
+	viper.SetDefault("node.signaling_window", 1000)
+	viper.SetDefault("node.signaling_threshold", 95)
+	viper.SetDefault("node.signaling_round_length", 1000)
 	viper.SetDefault("node.display_name", "Skycoin")
 	viper.SetDefault("node.ticker", "SKY")
 	viper.SetDefault("node.coin_hours_display_name", "Coin Hours")
@@ -176,5 +212,8 @@ func setDefaults() {
 	viper.SetDefault("params.unlock_time_interval", 60*60*24*365)
 	viper.SetDefault("params.user_max_decimals", 3)
 	viper.SetDefault("params.user_burn_factor", 10)
+	viper.SetDefault("params.user_min_fee_per_byte", 0)
+	viper.SetDefault("params.user_max_txn_inputs", 0)
+	viper.SetDefault("params.user_max_txn_outputs", 0)
 	viper.SetDefault("params.user_max_transaction_size", 32*1024)
 }
@@ -38,6 +38,10 @@ func TestNewConfig(t *testing.T) {
 			CreateBlockMaxTransactionSize:  1234,
 			CreateBlockMaxDropletPrecision: 4,
 			MaxBlockTransactionsSize:       1111,
+			MinBlockInterval:               5,
+			SignalingWindow:                100,
+			SignalingThreshold:             90,
+			SignalingRoundLength:           200,
 			DisplayName:                    "Testcoin",
 			Ticker:                         "TST",
 			CoinHoursName:                  "Testcoin Hours",
@@ -47,6 +51,13 @@ func TestNewConfig(t *testing.T) {
 			ExplorerURL:                    "https://explorer.testcoin.com",
 			VersionURL:                     "https://version.testcoin.com/testcoin/version.txt",
 			Bip44Coin:                      bip44.CoinTypeSkycoin,
+			SignalingDeployments: []SignalingDeployment{
+				{
+					Bit:       1,
+					Parameter: "max_block_size",
+					Value:     65536,
+				},
+			},
 		},
 		Params: ParamsConfig{
 			MaxCoinSupply:           1e8,
@@ -12,6 +12,9 @@ func init() {
 	loadUserBurnFactor()
 	loadUserMaxTransactionSize()
 	loadUserMaxDecimals()
+	loadUserMinFeePerByte()
+	loadUserMaxTxInputs()
+	loadUserMaxTxOutputs()
 	sanityCheck()
 }
 
@@ -76,3 +79,45 @@ func loadUserMaxDecimals() {
 
 	UserVerifyTxn.MaxDropletPrecision = uint8(x)
 }
+
+func loadUserMinFeePerByte() {
+	xs := os.Getenv("USER_MIN_FEE_PER_BYTE")
+	if xs == "" {
+		return
+	}
+
+	x, err := strconv.ParseUint(xs, 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid USER_MIN_FEE_PER_BYTE %q: %v", xs, err))
+	}
+
+	UserVerifyTxn.MinFeePerByte = x
+}
+
+func loadUserMaxTxInputs() {
+	xs := os.Getenv("USER_MAX_TXN_INPUTS")
+	if xs == "" {
+		return
+	}
+
+	x, err := strconv.ParseUint(xs, 10, 31)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid USER_MAX_TXN_INPUTS %q: %v", xs, err))
+	}
+
+	UserVerifyTxn.MaxTxInputs = int(x)
+}
+
+func loadUserMaxTxOutputs() {
+	xs := os.Getenv("USER_MAX_TXN_OUTPUTS")
+	if xs == "" {
+		return
+	}
+
+	x, err := strconv.ParseUint(xs, 10, 31)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid USER_MAX_TXN_OUTPUTS %q: %v", xs, err))
+	}
+
+	UserVerifyTxn.MaxTxOutputs = int(x)
+}
@@ -124,5 +124,11 @@ var (
 		MaxTransactionSize: 32768, // in bytes
 		// MaxDropletPrecision can be overriden with `USER_MAX_DECIMALS` env var
 		MaxDropletPrecision: 3,
+		// MinFeePerByte can be overriden with `USER_MIN_FEE_PER_BYTE` env var
+		MinFeePerByte: 0,
+		// MaxTxInputs can be overriden with `USER_MAX_TXN_INPUTS` env var
+		MaxTxInputs: 0,
+		// MaxTxOutputs can be overriden with `USER_MAX_TXN_OUTPUTS` env var
+		MaxTxOutputs: 0,
 	}
 )
@@ -20,6 +20,10 @@ var (
 	ErrInvalidMaxTransactionSize = errors.New("MaxTransactionSize value is out of range")
 	// ErrInvalidMaxDropletPrecision MaxDropletPrecision value is out of range
 	ErrInvalidMaxDropletPrecision = errors.New("MaxDropletPrecision value is out of range")
+	// ErrInvalidMaxTxInputs MaxTxInputs value is out of range
+	ErrInvalidMaxTxInputs = errors.New("MaxTxInputs value is out of range")
+	// ErrInvalidMaxTxOutputs MaxTxOutputs value is out of range
+	ErrInvalidMaxTxOutputs = errors.New("MaxTxOutputs value is out of range")
 )
 
 // VerifyTxn are parameters for verifying a transaction
@@ -30,6 +34,15 @@ type VerifyTxn struct {
 	MaxTransactionSize uint32
 	// MaxDropletPrecision maximum decimal precision of droplets
 	MaxDropletPrecision uint8
+	// MinFeePerByte minimum coinhour fee per byte of the transaction size, 0 disables the check.
+	// It is not part of the parameters announced to peers in the introduction message.
+	MinFeePerByte uint64 `enc:"-"`
+	// MaxTxInputs maximum number of inputs of a transaction, 0 disables the check.
+	// It is not part of the parameters announced to peers in the introduction message.
+	MaxTxInputs int `enc:"-"`
+	// MaxTxOutputs maximum number of outputs of a transaction, 0 disables the check.
+	// It is not part of the parameters announced to peers in the introduction message.
+	MaxTxOutputs int `enc:"-"`
 }
 
 // MaxDropletDivisor return the modulus divisor used when checking droplet precision rules
@@ -51,5 +64,13 @@ func (v VerifyTxn) Validate() error {
 		return ErrInvalidMaxDropletPrecision
 	}
 
+	if v.MaxTxInputs < 0 {
+		return ErrInvalidMaxTxInputs
+	}
+
+	if v.MaxTxOutputs < 0 {
+		return ErrInvalidMaxTxOutputs
+	}
+
 	return nil
 }
@@ -1,6 +1,8 @@
 package readable
 
 import (
+	"time"
+
 	"github.com/skycoin/skycoin/src/daemon"
 	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/util/useragent"
@@ -12,6 +14,7 @@ type Connection struct {
 	Addr                 string                 `json:"address"`
 	LastSent             int64                  `json:"last_sent"`
 	LastReceived         int64                  `json:"last_received"`
+	RTT                  float64                `json:"rtt_ms"`
 	ConnectedAt          int64                  `json:"connected_at"`
 	Outgoing             bool                   `json:"outgoing"`
 	State                daemon.ConnectionState `json:"state"`
@@ -44,6 +47,7 @@ func NewConnection(c *daemon.Connection) Connection {
 		Addr:                 c.Addr,
 		LastSent:             lastSent,
 		LastReceived:         lastReceived,
+		RTT:                  float64(c.Gnet.RTT) / float64(time.Millisecond),
 		ConnectedAt:          connectedAt,
 		Outgoing:             c.Outgoing,
 		State:                c.State,
@@ -61,6 +65,11 @@ type VerifyTxn struct {
 	BurnFactor          uint32 `json:"burn_factor"`
 	MaxTransactionSize  uint32 `json:"max_transaction_size"`
 	MaxDropletPrecision uint8  `json:"max_decimals"`
+	// MinFeePerByte is not announced by peers, it is only set for the local node
+	MinFeePerByte uint64 `json:"min_fee_per_byte,omitempty"`
+	// MaxTxInputs and MaxTxOutputs are not announced by peers, they are only set for the local node
+	MaxTxInputs  int `json:"max_txn_inputs,omitempty"`
+	MaxTxOutputs int `json:"max_txn_outputs,omitempty"`
 }
 
 // NewVerifyTxn converts params.VerifyTxn to VerifyTxn
@@ -69,5 +78,8 @@ func NewVerifyTxn(p params.VerifyTxn) VerifyTxn {
 		BurnFactor:          p.BurnFactor,
 		MaxTransactionSize:  p.MaxTransactionSize,
 		MaxDropletPrecision: p.MaxDropletPrecision,
+		MinFeePerByte:       p.MinFeePerByte,
+		MaxTxInputs:         p.MaxTxInputs,
+		MaxTxOutputs:        p.MaxTxOutputs,
 	}
 }
@@ -8,35 +8,66 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/consensus"
 	"github.com/skycoin/skycoin/src/fiber"
 	"github.com/skycoin/skycoin/src/kvstorage"
 	"github.com/skycoin/skycoin/src/wallet/crypto"
 
-	"log"
-
 	"github.com/skycoin/skycoin/src/api"
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/transaction"
 	"github.com/skycoin/skycoin/src/util/droplet"
 	"github.com/skycoin/skycoin/src/util/file"
+	"github.com/skycoin/skycoin/src/util/logging"
 	"github.com/skycoin/skycoin/src/util/useragent"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
 )
 
 var (
 	help = false
 )
 
+const (
+	// NodeModeArchival keeps the full blockchain
+	NodeModeArchival = "archival"
+	// NodeModePruned deletes the transactions and spent outputs of old blocks
+	NodeModePruned = "pruned"
+
+	// DefaultPruneOlderThanBlocks is the number of recent blocks whose transactions are kept in pruned mode
+	DefaultPruneOlderThanBlocks = 10000
+)
+
 // Config records skycoin node and build config
 type Config struct {
 	Node  NodeConfig
 	Build readable.BuildInfo
 }
 
+// APIListenerConfig configures an address that the web interface is served on
+type APIListenerConfig struct {
+	// Address to listen on, e.g. 127.0.0.1:6420
+	Addr string
+	// Reject any request that is not a GET request
+	ReadOnly bool
+	// Serve HTTPS instead of HTTP if set
+	TLS *APIListenerTLSConfig
+}
+
+// APIListenerTLSConfig configures HTTPS for an API listener
+type APIListenerTLSConfig struct {
+	// Certificate and key files. Defaults to the web interface certificate and key, which are autogenerated if missing
+	Cert string
+	Key  string
+}
+
 // NodeConfig records the node's configuration
 type NodeConfig struct {
 	// Name of the coin
@@ -56,6 +87,8 @@ type NodeConfig struct {
 	DisableNetworking bool
 	// Enable GUI
 	EnableGUI bool
+	// Serve node metrics for prometheus on /metrics of the web interface
+	EnableMetrics bool
 	// Disable CSRF check in the wallet API
 	DisableCSRF bool
 	// Disable Host, Origin and Referer header check in the wallet API
@@ -73,6 +106,12 @@ type NodeConfig struct {
 	// Comma separate list of hostnames to accept in the Host header, used to bypass the Host header check which only applies to localhost addresses
 	HostWhitelist string
 	hostWhitelist []string
+	// Comma separated list of route=requests per minute limits on expensive API routes,
+	// e.g. /api/v2/blockchain/richlist=6
+	APIRouteRateLimits string
+	apiRouteRateLimits map[string]int
+	// Write the full API requests and responses to a file in the logs directory, for debugging
+	APIDebugLog bool
 
 	// Only run on localhost and only connect to others on localhost
 	LocalhostOnly bool
@@ -95,6 +134,12 @@ type NodeConfig struct {
 	MaxOutgoingMessageLength int
 	// MaxIncomingMessageLength maximum size of incoming messages
 	MaxIncomingMessageLength int
+	// OutboundBandwidthLimit maximum bytes per second sent to all peers, 0 is unlimited
+	OutboundBandwidthLimit int
+	// InboundBandwidthLimit maximum bytes per second received from all peers, 0 is unlimited
+	InboundBandwidthLimit int
+	// GossipFanout number of random peers that transactions received from peers are announced to, 0 is all peers
+	GossipFanout int
 	// PeerlistSize represents the maximum number of peers that the pex would maintain
 	PeerlistSize int
 	// Wallet Address Version
@@ -116,6 +161,10 @@ type NodeConfig struct {
 	WebInterfacePassword string
 	// Allow web interface auth without HTTPS
 	WebInterfacePlaintextAuth bool
+	// Addresses to serve the web interface on, only configurable from the config file.
+	// If empty, the web interface is served on WebInterfaceAddr and WebInterfacePort
+	APIListeners []APIListenerConfig
+	apiListeners []APIListenerConfig
 
 	// Launch System Default Browser after client startup
 	LaunchBrowser bool
@@ -145,6 +194,23 @@ type NodeConfig struct {
 	VerifyDB bool
 	// Reset the database if integrity checks fail, and continue running
 	ResetCorruptDB bool
+	// With ResetCorruptDB, log the corrupted entries and exit instead of resetting the database
+	ResetCorruptDBDryRun bool
+	// Repair the database if its blockchain is corrupted, by removing the first corrupted block and the blocks after it,
+	// and continue running. Takes precedence over ResetCorruptDB
+	RepairDB bool
+	// Signed checkpoint manifest used to skip verifying block signatures up to the last checkpoint
+	// when checking the database. Defaults to ${DataDirectory}/checkpoints.json
+	CheckpointsFile string
+	// Don't use the checkpoint manifest, verify all block signatures
+	NoCheckpoints bool
+	// Abort the database check if it takes longer than this, 0 for no limit
+	MaxVerifyDuration time.Duration
+	// File the unconfirmed transactions are dumped to on shutdown and restored from on startup.
+	// Defaults to ${DataDirectory}/mempool.bin
+	MempoolFile string
+	// Don't dump and restore the unconfirmed transactions
+	NoMempoolDump bool
 
 	// Transaction verification parameters for unconfirmed transactions
 	UnconfirmedVerifyTxn params.VerifyTxn
@@ -152,6 +218,29 @@ type NodeConfig struct {
 	CreateBlockVerifyTxn params.VerifyTxn
 	// Maximum total size of transactions in a block
 	MaxBlockTransactionsSize uint32
+	// Maximum number of transactions in the unconfirmed pool, 0 for unlimited
+	MaxUnconfirmedTransactions int
+	// When the unconfirmed pool is full, only transactions that have been in the pool
+	// for at least this long can be evicted
+	UnconfirmedEvictionMinAge time.Duration
+	// Upper bounds of the buckets of the unconfirmed transaction age histogram
+	UnconfirmedAgeHistogramBuckets []time.Duration
+	// Upper bounds of the buckets of the block size histogram, in bytes
+	BlockSizeHistogramBuckets []uint32
+	// Blocks whose timestamp is more than this far ahead of the local clock are rejected, 0 disables the check
+	MaxFutureBlockTime time.Duration
+	// Blocks whose timestamp is less than this long after the previous block's timestamp are rejected, 0 disables the check.
+	// This is a consensus rule, so it is set by the coin's fiber.toml and not by a command line flag
+	MinBlockInterval time.Duration
+	// Parameter changes activated by the signals in the block headers, applied when creating blocks.
+	// Like MinBlockInterval, it is set by the coin's fiber.toml and not by a command line flag
+	Signaling consensus.SignalingConfig
+	// Signaling field of the blocks created by this node, with the bits of the supported deployments set
+	SignalingField uint32
+	// Minimum coins of an output of a transaction created by this node, in droplets. 0 disables the check
+	DustThreshold uint64
+	// How change below the dust threshold is handled when creating transactions
+	DustPolicy transaction.DustPolicy
 
 	unconfirmedBurnFactor          uint64
 	maxUnconfirmedTransactionSize  uint64
@@ -160,12 +249,21 @@ type NodeConfig struct {
 	createBlockMaxTransactionSize  uint64
 	createBlockMaxDropletPrecision uint64
 	maxBlockSize                   uint64
+	signalingField                 uint64
+	blockSizeHistogramBuckets      string
+	unconfirmedAgeHistogramBuckets string
+	dustPolicy                     string
 
 	// Wallets
 	// Defaults to ${DataDirectory}/wallets/
 	WalletDirectory string
 	// Wallet crypto type
 	WalletCryptoType string
+	// Duration of the wallet PIN sessions, 0 disables them
+	WalletSessionTimeout time.Duration
+	// Upload encrypted wallet files to an S3 compatible storage service after every write,
+	// only configurable from the config file
+	WalletBackupS3 *wallet.S3BackupConfig
 
 	// Key-value storage
 	// Default to ${DataDirectory}/data
@@ -177,7 +275,24 @@ type NodeConfig struct {
 	// Load custom peers from disk
 	CustomPeersFile string
 
+	// Coordinate coinjoin rounds for peers that send join requests
+	Coordinator bool
+	// Number of join requests needed to start a coinjoin round
+	CoordinatorMinParticipants int
+	// How long the participants of a coinjoin round have to sign the transaction
+	CoordinatorRoundTimeout time.Duration
+
+	// Node mode, "archival" or "pruned"
+	NodeMode string
+	// In pruned mode, the transactions of blocks older than this many blocks are deleted.
+	// Defaults to DefaultPruneOlderThanBlocks in pruned mode
+	PruneOlderThanBlocks uint64
+	// Record the outputs created and spent by each block in the event log
+	EnableEventLog bool
+
 	RunBlockPublisher bool
+	// Name of the registered visor.BlockProducerPlugin used by a block publisher
+	BlockProducer string
 
 	/* Developer options */
 
@@ -192,8 +307,14 @@ type NodeConfig struct {
 
 	DBPath     string
 	DBReadOnly bool
-	LogToFile  bool
-	Version    bool // show node version
+	// Fill percent of the DB pages that are split when writing, 0 for bolt's default
+	DBFillPercent float64
+	LogToFile     bool
+	Version       bool // show node version
+
+	// JSON file with config values that override the command line flags.
+	// The file is reloaded on SIGHUP, see reloadableConfigFields for the values that are applied
+	ConfigFile string
 
 	GenesisSignatureStr string
 	GenesisAddressStr   string
@@ -201,9 +322,13 @@ type NodeConfig struct {
 	BlockchainSeckeyStr string
 	GenesisTimestamp    uint64
 	GenesisCoinVolume   uint64
-	DefaultConnections  []string
+	// JSON file of the genesis block outputs, an array of {address, coins, coin_hours} objects.
+	// If set, it replaces GenesisAddressStr and GenesisCoinVolume
+	GenesisOutputsFile string
+	DefaultConnections []string
 
 	genesisSignature cipher.Sig
+	genesisOutputs   *visor.GenesisOutputs
 	genesisAddress   cipher.Address
 	genesisHash      cipher.SHA256
 
@@ -234,6 +359,8 @@ func NewNodeConfig(mode string, node fiber.NodeConfig) NodeConfig {
 		DisableNetworking: false,
 		// Enable GUI
 		EnableGUI: false,
+		// Serve node metrics for prometheus on /metrics
+		EnableMetrics: false,
 		// Disable CSRF check in the wallet API
 		DisableCSRF: false,
 		// Disable Host, Origin and Referer header check in the wallet API
@@ -261,7 +388,12 @@ func NewNodeConfig(mode string, node fiber.NodeConfig) NodeConfig {
 		OutgoingConnectionsRate:  time.Second * 5,
 		MaxOutgoingMessageLength: 256 * 1024,
 		MaxIncomingMessageLength: 1024 * 1024,
-		PeerlistSize:             65535,
+		GossipFanout:             8,
+		// Number of join requests needed to start a coinjoin round
+		CoordinatorMinParticipants: 3,
+		// How long the participants of a coinjoin round have to sign the transaction
+		CoordinatorRoundTimeout: time.Minute * 2,
+		PeerlistSize:            65535,
 		// Wallet Address Version
 		// AddressVersion: "test",
 		// Remote web interface
@@ -289,25 +421,46 @@ func NewNodeConfig(mode string, node fiber.NodeConfig) NodeConfig {
 		LogToFile:       false,
 		DisablePingPong: false,
 
-		VerifyDB:       false,
-		ResetCorruptDB: false,
+		VerifyDB:             false,
+		ResetCorruptDB:       false,
+		ResetCorruptDBDryRun: false,
+		RepairDB:             false,
 
 		// Blockchain/transaction validation
 		UnconfirmedVerifyTxn: params.VerifyTxn{
 			BurnFactor:          node.UnconfirmedBurnFactor,
 			MaxTransactionSize:  node.UnconfirmedMaxTransactionSize,
 			MaxDropletPrecision: node.UnconfirmedMaxDropletPrecision,
+			MinFeePerByte:       node.UnconfirmedMinFeePerByte,
+			MaxTxInputs:         params.UserVerifyTxn.MaxTxInputs,
+			MaxTxOutputs:        params.UserVerifyTxn.MaxTxOutputs,
 		},
 		CreateBlockVerifyTxn: params.VerifyTxn{
 			BurnFactor:          node.CreateBlockBurnFactor,
 			MaxTransactionSize:  node.CreateBlockMaxTransactionSize,
 			MaxDropletPrecision: node.CreateBlockMaxDropletPrecision,
+			MinFeePerByte:       node.CreateBlockMinFeePerByte,
+			MaxTxInputs:         params.UserVerifyTxn.MaxTxInputs,
+			MaxTxOutputs:        params.UserVerifyTxn.MaxTxOutputs,
+		},
+		MaxBlockTransactionsSize:       node.MaxBlockTransactionsSize,
+		UnconfirmedEvictionMinAge:      visor.DefaultUnconfirmedEvictionMinAge,
+		UnconfirmedAgeHistogramBuckets: visor.DefaultUnconfirmedAgeHistogramBuckets,
+		BlockSizeHistogramBuckets:      visor.DefaultBlockSizeHistogramBuckets,
+		MaxFutureBlockTime:             visor.DefaultMaxFutureBlockTime,
+		MinBlockInterval:               time.Duration(node.MinBlockInterval) * time.Second,
+		DustPolicy:                     transaction.DustPolicyReject,
+		Signaling: consensus.SignalingConfig{
+			SignalingWindow:       node.SignalingWindow,
+			MinSignalingThreshold: node.SignalingThreshold,
+			RoundLength:           node.SignalingRoundLength,
+			Deployments:           newSignalingDeployments(node.SignalingDeployments),
 		},
-		MaxBlockTransactionsSize: node.MaxBlockTransactionsSize,
 
 		// Wallets
-		WalletDirectory:  "",
-		WalletCryptoType: string(crypto.DefaultCryptoType),
+		WalletDirectory:      "",
+		WalletCryptoType:     string(crypto.DefaultCryptoType),
+		WalletSessionTimeout: wallet.DefaultSessionTimeout,
 
 		// Key-value storage
 		KVStorageDirectory: "",
@@ -322,7 +475,10 @@ func NewNodeConfig(mode string, node fiber.NodeConfig) NodeConfig {
 		HTTPWriteTimeout: time.Second * 60,
 		HTTPIdleTimeout:  time.Second * 120,
 
+		NodeMode: NodeModeArchival,
+
 		RunBlockPublisher: false,
+		BlockProducer:     visor.DefaultBlockProducerName,
 
 		// Enable cpu profiling
 		ProfileCPU: false,
@@ -351,37 +507,110 @@ func NewNodeConfig(mode string, node fiber.NodeConfig) NodeConfig {
 	return nodeConfig
 }
 
+// newSignalingDeployments converts the signaling deployments of a fiber.toml file
+func newSignalingDeployments(deployments []fiber.SignalingDeployment) []consensus.SignalingDeployment {
+	if len(deployments) == 0 {
+		return nil
+	}
+
+	ds := make([]consensus.SignalingDeployment, len(deployments))
+	for i, d := range deployments {
+		ds[i] = consensus.SignalingDeployment{
+			Bit:       d.Bit,
+			Parameter: consensus.SignalingParameter(d.Parameter),
+			Value:     d.Value,
+		}
+	}
+
+	return ds
+}
+
 func (c *Config) postProcess() error {
 	if help {
 		flag.Usage()
 		os.Exit(0)
 	}
 
+	if c.Node.ConfigFile != "" {
+		if err := loadConfigFile(c.Node.ConfigFile, &c.Node); err != nil {
+			return err
+		}
+	}
+
+	// The problems with the flags are collected and returned together.
+	// A flag with a problem is not checked again against the other flags.
+	var errs visor.ConfigErrors
+	addErr := func(flag string, err error) {
+		ce, ok := err.(visor.ConfigError)
+		if !ok {
+			ce = visor.ConfigError{
+				Field: flag,
+				Err:   err,
+			}
+		}
+		errs = append(errs, ce)
+	}
+	hasErr := func(flags ...string) bool {
+		for _, e := range errs {
+			for _, f := range flags {
+				if e.Field == f {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
 	var err error
 	if c.Node.GenesisSignatureStr != "" {
 		c.Node.genesisSignature, err = cipher.SigFromHex(c.Node.GenesisSignatureStr)
-		panicIfError(err, "Invalid Signature")
+		if err != nil {
+			addErr("-genesis-signature", err)
+		}
 	}
 
 	if c.Node.GenesisAddressStr != "" {
 		c.Node.genesisAddress, err = cipher.DecodeBase58Address(c.Node.GenesisAddressStr)
-		panicIfError(err, "Invalid Address")
+		if err != nil {
+			addErr("-genesis-address", err)
+		}
+	}
+
+	if c.Node.GenesisOutputsFile != "" {
+		c.Node.genesisOutputs, err = visor.LoadGenesisOutputs(c.Node.GenesisOutputsFile)
+		if err != nil {
+			addErr("-genesis-outputs-file", err)
+		}
 	}
 
 	// Compute genesis block hash
-	gb, err := coin.NewGenesisBlock(c.Node.genesisAddress, c.Node.GenesisCoinVolume, c.Node.GenesisTimestamp)
-	if err != nil {
-		panicIfError(err, "Create genesis hash failed")
+	var gb *coin.Block
+	if c.Node.genesisOutputs != nil {
+		gb, err = c.Node.genesisOutputs.NewGenesisBlock(c.Node.GenesisTimestamp)
+		if err != nil {
+			addErr("-genesis-outputs-file", err)
+		}
+	} else {
+		gb, err = coin.NewGenesisBlock(c.Node.genesisAddress, c.Node.GenesisCoinVolume, c.Node.GenesisTimestamp)
+		if err != nil {
+			addErr("-genesis-address", err)
+		}
+	}
+	if gb != nil {
+		c.Node.genesisHash = gb.HashHeader()
 	}
-	c.Node.genesisHash = gb.HashHeader()
 
 	if c.Node.BlockchainPubkeyStr != "" {
 		c.Node.blockchainPubkey, err = cipher.PubKeyFromHex(c.Node.BlockchainPubkeyStr)
-		panicIfError(err, "Invalid Pubkey")
+		if err != nil {
+			addErr("-blockchain-public-key", err)
+		}
 	}
 	if c.Node.BlockchainSeckeyStr != "" {
 		c.Node.blockchainSeckey, err = cipher.SecKeyFromHex(c.Node.BlockchainSeckeyStr)
-		panicIfError(err, "Invalid Seckey")
+		if err != nil {
+			addErr("-blockchain-secret-key", err)
+		}
 		c.Node.BlockchainSeckeyStr = ""
 	}
 	if c.Node.BlockchainSeckeyStr != "" {
@@ -390,7 +619,9 @@ func (c *Config) postProcess() error {
 
 	home := file.UserHome()
 	c.Node.DataDirectory, err = file.InitDataDir(replaceHome(c.Node.DataDirectory, home))
-	panicIfError(err, "Invalid DataDirectory")
+	if err != nil {
+		addErr("-data-dir", err)
+	}
 
 	if c.Node.WebInterfaceCert == "" {
 		c.Node.WebInterfaceCert = filepath.Join(c.Node.DataDirectory, "skycoind.cert")
@@ -428,6 +659,18 @@ func (c *Config) postProcess() error {
 		c.Node.DBPath = replaceHome(c.Node.DBPath, home)
 	}
 
+	if c.Node.CheckpointsFile == "" {
+		c.Node.CheckpointsFile = filepath.Join(c.Node.DataDirectory, "checkpoints.json")
+	} else {
+		c.Node.CheckpointsFile = replaceHome(c.Node.CheckpointsFile, home)
+	}
+
+	if c.Node.MempoolFile == "" {
+		c.Node.MempoolFile = filepath.Join(c.Node.DataDirectory, "mempool.bin")
+	} else {
+		c.Node.MempoolFile = replaceHome(c.Node.MempoolFile, home)
+	}
+
 	userAgentData := useragent.Data{
 		Coin:    c.Node.CoinName,
 		Version: c.Build.Version,
@@ -435,14 +678,14 @@ func (c *Config) postProcess() error {
 	}
 
 	if _, err := userAgentData.Build(); err != nil {
-		return err
+		addErr("-user-agent-remark", err)
 	}
 
 	c.Node.userAgent = userAgentData
 
 	apiSets, err := buildAPISets(c.Node)
 	if err != nil {
-		return err
+		addErr("-enable-api-sets", err)
 	}
 
 	// Don't open browser to load wallets if wallet apis are disabled.
@@ -462,46 +705,88 @@ func (c *Config) postProcess() error {
 
 	if c.Node.HostWhitelist != "" {
 		if c.Node.DisableHeaderCheck {
-			return errors.New("host whitelist should be empty when header check is disabled")
+			addErr("-host-whitelist", errors.New("host whitelist should be empty when header check is disabled"))
 		}
 		c.Node.hostWhitelist = strings.Split(c.Node.HostWhitelist, ",")
 	}
 
+	c.Node.apiRouteRateLimits, err = buildAPIRouteRateLimits(c.Node.APIRouteRateLimits)
+	if err != nil {
+		addErr("-api-route-rate-limits", err)
+	}
+
+	c.Node.apiListeners, err = buildAPIListeners(c.Node, home)
+	if err != nil {
+		addErr("APIListeners", err)
+	}
+
 	httpAuthEnabled := c.Node.WebInterfaceUsername != "" || c.Node.WebInterfacePassword != ""
-	if httpAuthEnabled && !c.Node.WebInterfaceHTTPS && !c.Node.WebInterfacePlaintextAuth {
-		return errors.New("Web interface auth enabled but HTTPS is not enabled. Use -web-interface-plaintext-auth=true if this is desired")
+	if httpAuthEnabled && !c.Node.WebInterfacePlaintextAuth {
+		for _, l := range c.Node.apiListeners {
+			if l.TLS == nil {
+				addErr("-web-interface-plaintext-auth", errors.New("Web interface auth enabled but HTTPS is not enabled. Use -web-interface-plaintext-auth=true if this is desired"))
+				break
+			}
+		}
+	}
+
+	if c.Node.WalletBackupS3 != nil {
+		if err := c.Node.WalletBackupS3.Validate(); err != nil {
+			addErr("WalletBackupS3", err)
+		}
 	}
 
-	if c.Node.MaxConnections < c.Node.MaxOutgoingConnections+c.Node.MaxIncomingConnections {
-		return errors.New("-max-connections must be >= -max-outgoing-connections + -max-incoming-connections")
+	if err := validateConnectionLimits(c.Node); err != nil {
+		addErr("-max-connections", err)
 	}
 
-	if c.Node.MaxOutgoingConnections > c.Node.MaxConnections {
-		return errors.New("-max-outgoing-connections cannot be higher than -max-connections")
+	switch c.Node.NodeMode {
+	case NodeModeArchival:
+		if c.Node.PruneOlderThanBlocks != 0 {
+			addErr("-prune-older-than-blocks", errors.New("-prune-older-than-blocks requires -node-mode=pruned"))
+		}
+	case NodeModePruned:
+		if c.Node.RunBlockPublisher {
+			addErr("-node-mode", errors.New("-node-mode=pruned cannot be used with -block-publisher"))
+		}
+		if c.Node.EnableEventLog {
+			addErr("-node-mode", errors.New("-node-mode=pruned cannot be used with -enable-event-log"))
+		}
+		if c.Node.RepairDB {
+			addErr("-node-mode", errors.New("-node-mode=pruned cannot be used with -repair-db"))
+		}
+		if c.Node.PruneOlderThanBlocks == 0 {
+			c.Node.PruneOlderThanBlocks = DefaultPruneOlderThanBlocks
+		}
+	default:
+		addErr("-node-mode", fmt.Errorf("Invalid -node-mode %q, must be %q or %q", c.Node.NodeMode, NodeModeArchival, NodeModePruned))
 	}
 
-	if c.Node.MaxIncomingConnections > c.Node.MaxConnections {
-		return errors.New("-max-incoming-connections cannot be higher than -max-connections")
+	if c.Node.RepairDB && c.Node.ResetCorruptDBDryRun {
+		addErr("-repair-db", errors.New("-repair-db cannot be used with -reset-corrupt-db-dry-run"))
 	}
 
 	if c.Node.maxBlockSize > math.MaxUint32 {
-		return errors.New("-max-block-size exceeds MaxUint32")
+		addErr("-max-block-size", errors.New("-max-block-size exceeds MaxUint32"))
+	}
+	if c.Node.signalingField > math.MaxUint32 {
+		addErr("-signaling-field", errors.New("-signaling-field exceeds MaxUint32"))
 	}
 	if c.Node.maxUnconfirmedTransactionSize > math.MaxUint32 {
-		return errors.New("-max-txn-size-unconfirmed exceeds MaxUint32")
+		addErr("-max-txn-size-unconfirmed", errors.New("-max-txn-size-unconfirmed exceeds MaxUint32"))
 	}
 	if c.Node.unconfirmedBurnFactor > math.MaxUint32 {
-		return errors.New("-burn-factor-unconfirmed exceeds MaxUint32")
+		addErr("-burn-factor-unconfirmed", errors.New("-burn-factor-unconfirmed exceeds MaxUint32"))
 	}
 	if c.Node.createBlockBurnFactor > math.MaxUint32 {
-		return errors.New("-burn-factor-create-block exceeds MaxUint32")
+		addErr("-burn-factor-create-block", errors.New("-burn-factor-create-block exceeds MaxUint32"))
 	}
 
 	if c.Node.unconfirmedMaxDropletPrecision > math.MaxUint8 {
-		return errors.New("-max-decimals-unconfirmed exceeds MaxUint8")
+		addErr("-max-decimals-unconfirmed", errors.New("-max-decimals-unconfirmed exceeds MaxUint8"))
 	}
 	if c.Node.createBlockMaxDropletPrecision > math.MaxUint8 {
-		return errors.New("-max-decimals-create-block exceeds MaxUint8")
+		addErr("-max-decimals-create-block", errors.New("-max-decimals-create-block exceeds MaxUint8"))
 	}
 
 	c.Node.UnconfirmedVerifyTxn.BurnFactor = uint32(c.Node.unconfirmedBurnFactor)
@@ -511,59 +796,185 @@ func (c *Config) postProcess() error {
 	c.Node.CreateBlockVerifyTxn.MaxTransactionSize = uint32(c.Node.createBlockMaxTransactionSize)
 	c.Node.CreateBlockVerifyTxn.MaxDropletPrecision = uint8(c.Node.createBlockMaxDropletPrecision)
 	c.Node.MaxBlockTransactionsSize = uint32(c.Node.maxBlockSize)
+	c.Node.SignalingField = uint32(c.Node.signalingField)
+
+	c.Node.BlockSizeHistogramBuckets, err = parseBlockSizeHistogramBuckets(c.Node.blockSizeHistogramBuckets)
+	if err != nil {
+		addErr("-block-size-histogram-buckets", err)
+	}
+
+	c.Node.UnconfirmedAgeHistogramBuckets, err = parseUnconfirmedAgeHistogramBuckets(c.Node.unconfirmedAgeHistogramBuckets)
+	if err != nil {
+		addErr("-unconfirmed-age-histogram-buckets", err)
+	}
+
+	c.Node.DustPolicy, err = transaction.ParseDustPolicy(c.Node.dustPolicy)
+	if err != nil {
+		addErr("-dust-policy", err)
+	}
 
-	if c.Node.UnconfirmedVerifyTxn.MaxTransactionSize < params.MinTransactionSize {
-		return fmt.Errorf("-max-txn-size-unconfirmed must be >= params.MinTransactionSize (%d)", params.MinTransactionSize)
+	if _, err := logging.LevelFromString(c.Node.LogLevel); err != nil {
+		addErr("-log-level", err)
 	}
-	if c.Node.UnconfirmedVerifyTxn.MaxTransactionSize < params.UserVerifyTxn.MaxTransactionSize {
-		return fmt.Errorf("-max-txn-size-unconfirmed must be >= params.UserVerifyTxn.MaxTransactionSize (%d)", params.UserVerifyTxn.MaxTransactionSize)
+
+	if _, err := crypto.CryptoTypeFromString(c.Node.WalletCryptoType); err != nil {
+		addErr("-wallet-crypto-type", err)
+	}
+
+	if c.Node.WalletSessionTimeout < 0 {
+		addErr("-wallet-session-timeout", errors.New("-wallet-session-timeout must be >= 0"))
+	}
+
+	if !hasErr("-max-txn-size-unconfirmed") && c.Node.UnconfirmedVerifyTxn.MaxTransactionSize < params.MinTransactionSize {
+		addErr("-max-txn-size-unconfirmed", fmt.Errorf("-max-txn-size-unconfirmed must be >= params.MinTransactionSize (%d)", params.MinTransactionSize))
 	}
-	if c.Node.CreateBlockVerifyTxn.MaxTransactionSize < params.MinTransactionSize {
-		return fmt.Errorf("-max-txn-size-create-block must be >= params.MinTransactionSize (%d)", params.MinTransactionSize)
+	if !hasErr("-max-txn-size-unconfirmed") && c.Node.UnconfirmedVerifyTxn.MaxTransactionSize < params.UserVerifyTxn.MaxTransactionSize {
+		addErr("-max-txn-size-unconfirmed", fmt.Errorf("-max-txn-size-unconfirmed must be >= params.UserVerifyTxn.MaxTransactionSize (%d)", params.UserVerifyTxn.MaxTransactionSize))
 	}
-	if c.Node.CreateBlockVerifyTxn.MaxTransactionSize < params.UserVerifyTxn.MaxTransactionSize {
-		return fmt.Errorf("-max-txn-size-create-block must be >= params.UserVerifyTxn.MaxTransactionSize (%d)", params.UserVerifyTxn.MaxTransactionSize)
+	if !hasErr("-max-txn-size-create-block") && c.Node.CreateBlockVerifyTxn.MaxTransactionSize < params.MinTransactionSize {
+		addErr("-max-txn-size-create-block", fmt.Errorf("-max-txn-size-create-block must be >= params.MinTransactionSize (%d)", params.MinTransactionSize))
+	}
+	if !hasErr("-max-txn-size-create-block") && c.Node.CreateBlockVerifyTxn.MaxTransactionSize < params.UserVerifyTxn.MaxTransactionSize {
+		addErr("-max-txn-size-create-block", fmt.Errorf("-max-txn-size-create-block must be >= params.UserVerifyTxn.MaxTransactionSize (%d)", params.UserVerifyTxn.MaxTransactionSize))
 	}
 
-	if c.Node.MaxBlockTransactionsSize < params.MinTransactionSize {
-		return fmt.Errorf("-max-block-size must be >= params.MinTransactionSize (%d)", params.MinTransactionSize)
+	if !hasErr("-max-block-size") && c.Node.MaxBlockTransactionsSize < params.MinTransactionSize {
+		addErr("-max-block-size", fmt.Errorf("-max-block-size must be >= params.MinTransactionSize (%d)", params.MinTransactionSize))
 	}
-	if c.Node.MaxBlockTransactionsSize < params.UserVerifyTxn.MaxTransactionSize {
-		return fmt.Errorf("-max-block-size must be >= params.UserVerifyTxn.MaxTransactionSize (%d)", params.UserVerifyTxn.MaxTransactionSize)
+	if !hasErr("-max-block-size") && c.Node.MaxBlockTransactionsSize < params.UserVerifyTxn.MaxTransactionSize {
+		addErr("-max-block-size", fmt.Errorf("-max-block-size must be >= params.UserVerifyTxn.MaxTransactionSize (%d)", params.UserVerifyTxn.MaxTransactionSize))
 	}
-	if c.Node.MaxBlockTransactionsSize < c.Node.UnconfirmedVerifyTxn.MaxTransactionSize {
-		return errors.New("-max-block-size must be >= -max-txn-size-unconfirmed")
+	if !hasErr("-max-block-size", "-max-txn-size-unconfirmed") && c.Node.MaxBlockTransactionsSize < c.Node.UnconfirmedVerifyTxn.MaxTransactionSize {
+		addErr("-max-block-size", errors.New("-max-block-size must be >= -max-txn-size-unconfirmed"))
 	}
-	if c.Node.MaxBlockTransactionsSize < c.Node.CreateBlockVerifyTxn.MaxTransactionSize {
-		return errors.New("-max-block-size must be >= -max-txn-size-create-block")
+	if !hasErr("-max-block-size", "-max-txn-size-create-block") && c.Node.MaxBlockTransactionsSize < c.Node.CreateBlockVerifyTxn.MaxTransactionSize {
+		addErr("-max-block-size", errors.New("-max-block-size must be >= -max-txn-size-create-block"))
 	}
 
-	if c.Node.UnconfirmedVerifyTxn.BurnFactor < params.MinBurnFactor {
-		return fmt.Errorf("-burn-factor-unconfirmed must be >= params.MinBurnFactor (%d)", params.MinBurnFactor)
+	if !hasErr("-burn-factor-unconfirmed") && c.Node.UnconfirmedVerifyTxn.BurnFactor < params.MinBurnFactor {
+		addErr("-burn-factor-unconfirmed", fmt.Errorf("-burn-factor-unconfirmed must be >= params.MinBurnFactor (%d)", params.MinBurnFactor))
 	}
-	if c.Node.UnconfirmedVerifyTxn.BurnFactor < params.UserVerifyTxn.BurnFactor {
-		return fmt.Errorf("-burn-factor-unconfirmed must be >= params.UserVerifyTxn.BurnFactor (%d)", params.UserVerifyTxn.BurnFactor)
+	if !hasErr("-burn-factor-unconfirmed") && c.Node.UnconfirmedVerifyTxn.BurnFactor < params.UserVerifyTxn.BurnFactor {
+		addErr("-burn-factor-unconfirmed", fmt.Errorf("-burn-factor-unconfirmed must be >= params.UserVerifyTxn.BurnFactor (%d)", params.UserVerifyTxn.BurnFactor))
 	}
 
-	if c.Node.CreateBlockVerifyTxn.BurnFactor < params.MinBurnFactor {
-		return fmt.Errorf("-burn-factor-create-block must be >= params.MinBurnFactor (%d)", params.MinBurnFactor)
+	if !hasErr("-burn-factor-create-block") && c.Node.CreateBlockVerifyTxn.BurnFactor < params.MinBurnFactor {
+		addErr("-burn-factor-create-block", fmt.Errorf("-burn-factor-create-block must be >= params.MinBurnFactor (%d)", params.MinBurnFactor))
 	}
-	if c.Node.CreateBlockVerifyTxn.BurnFactor < params.UserVerifyTxn.BurnFactor {
-		return fmt.Errorf("-burn-factor-create-block must be >= params.UserVerifyTxn.BurnFactor (%d)", params.UserVerifyTxn.BurnFactor)
+	if !hasErr("-burn-factor-create-block") && c.Node.CreateBlockVerifyTxn.BurnFactor < params.UserVerifyTxn.BurnFactor {
+		addErr("-burn-factor-create-block", fmt.Errorf("-burn-factor-create-block must be >= params.UserVerifyTxn.BurnFactor (%d)", params.UserVerifyTxn.BurnFactor))
 	}
 
-	if c.Node.UnconfirmedVerifyTxn.MaxDropletPrecision > droplet.Exponent {
-		return fmt.Errorf("-max-decimals-unconfirmed must be <= droplet.Exponent (%d)", droplet.Exponent)
+	if !hasErr("-min-fee-per-byte-unconfirmed") && c.Node.UnconfirmedVerifyTxn.MinFeePerByte < params.UserVerifyTxn.MinFeePerByte {
+		addErr("-min-fee-per-byte-unconfirmed", fmt.Errorf("-min-fee-per-byte-unconfirmed must be >= params.UserVerifyTxn.MinFeePerByte (%d)", params.UserVerifyTxn.MinFeePerByte))
 	}
-	if c.Node.UnconfirmedVerifyTxn.MaxDropletPrecision < params.UserVerifyTxn.MaxDropletPrecision {
-		return fmt.Errorf("-max-decimals-unconfirmed must be >= params.UserVerifyTxn.MaxDropletPrecision (%d)", params.UserVerifyTxn.MaxDropletPrecision)
+	if !hasErr("-min-fee-per-byte-create-block") && c.Node.CreateBlockVerifyTxn.MinFeePerByte < params.UserVerifyTxn.MinFeePerByte {
+		addErr("-min-fee-per-byte-create-block", fmt.Errorf("-min-fee-per-byte-create-block must be >= params.UserVerifyTxn.MinFeePerByte (%d)", params.UserVerifyTxn.MinFeePerByte))
 	}
 
-	if c.Node.CreateBlockVerifyTxn.MaxDropletPrecision > droplet.Exponent {
-		return fmt.Errorf("-max-decimals-create-block must be <= droplet.Exponent (%d)", droplet.Exponent)
+	if !hasErr("-max-decimals-unconfirmed") && c.Node.UnconfirmedVerifyTxn.MaxDropletPrecision > droplet.Exponent {
+		addErr("-max-decimals-unconfirmed", fmt.Errorf("-max-decimals-unconfirmed must be <= droplet.Exponent (%d)", droplet.Exponent))
 	}
-	if c.Node.CreateBlockVerifyTxn.MaxDropletPrecision < params.UserVerifyTxn.MaxDropletPrecision {
-		return fmt.Errorf("-max-decimals-create-block must be >= params.UserVerifyTxn.MaxDropletPrecision (%d)", params.UserVerifyTxn.MaxDropletPrecision)
+	if !hasErr("-max-decimals-unconfirmed") && c.Node.UnconfirmedVerifyTxn.MaxDropletPrecision < params.UserVerifyTxn.MaxDropletPrecision {
+		addErr("-max-decimals-unconfirmed", fmt.Errorf("-max-decimals-unconfirmed must be >= params.UserVerifyTxn.MaxDropletPrecision (%d)", params.UserVerifyTxn.MaxDropletPrecision))
+	}
+
+	if !hasErr("-max-decimals-create-block") && c.Node.CreateBlockVerifyTxn.MaxDropletPrecision > droplet.Exponent {
+		addErr("-max-decimals-create-block", fmt.Errorf("-max-decimals-create-block must be <= droplet.Exponent (%d)", droplet.Exponent))
+	}
+	if !hasErr("-max-decimals-create-block") && c.Node.CreateBlockVerifyTxn.MaxDropletPrecision < params.UserVerifyTxn.MaxDropletPrecision {
+		addErr("-max-decimals-create-block", fmt.Errorf("-max-decimals-create-block must be >= params.UserVerifyTxn.MaxDropletPrecision (%d)", params.UserVerifyTxn.MaxDropletPrecision))
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// parseBlockSizeHistogramBuckets parses a comma separated list of increasing block sizes
+func parseBlockSizeHistogramBuckets(s string) ([]uint32, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var buckets []uint32
+	for _, v := range strings.Split(s, ",") {
+		b, err := strconv.ParseUint(strings.TrimSpace(v), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("-block-size-histogram-buckets: invalid size %q", v)
+		}
+
+		if len(buckets) > 0 && uint32(b) <= buckets[len(buckets)-1] {
+			return nil, errors.New("-block-size-histogram-buckets must be in increasing order")
+		}
+
+		buckets = append(buckets, uint32(b))
+	}
+
+	return buckets, nil
+}
+
+// parseUnconfirmedAgeHistogramBuckets parses a comma separated list of increasing durations
+func parseUnconfirmedAgeHistogramBuckets(s string) ([]time.Duration, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var buckets []time.Duration
+	for _, v := range strings.Split(s, ",") {
+		b, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil || b <= 0 {
+			return nil, fmt.Errorf("-unconfirmed-age-histogram-buckets: invalid duration %q", v)
+		}
+
+		if len(buckets) > 0 && b <= buckets[len(buckets)-1] {
+			return nil, errors.New("-unconfirmed-age-histogram-buckets must be in increasing order")
+		}
+
+		buckets = append(buckets, b)
+	}
+
+	return buckets, nil
+}
+
+func joinDurations(v []time.Duration) string {
+	s := make([]string, len(v))
+	for i, x := range v {
+		s[i] = x.String()
+	}
+	return strings.Join(s, ",")
+}
+
+func joinUint32s(v []uint32) string {
+	s := make([]string, len(v))
+	for i, x := range v {
+		s[i] = strconv.FormatUint(uint64(x), 10)
+	}
+	return strings.Join(s, ",")
+}
+
+func validateConnectionLimits(c NodeConfig) error {
+	if c.MaxConnections < c.MaxOutgoingConnections+c.MaxIncomingConnections {
+		return visor.ConfigError{
+			Field: "-max-connections",
+			Err:   errors.New("-max-connections must be >= -max-outgoing-connections + -max-incoming-connections"),
+		}
+	}
+
+	if c.MaxOutgoingConnections > c.MaxConnections {
+		return visor.ConfigError{
+			Field: "-max-outgoing-connections",
+			Err:   errors.New("-max-outgoing-connections cannot be higher than -max-connections"),
+		}
+	}
+
+	if c.MaxIncomingConnections > c.MaxConnections {
+		return visor.ConfigError{
+			Field: "-max-incoming-connections",
+			Err:   errors.New("-max-incoming-connections cannot be higher than -max-connections"),
+		}
 	}
 
 	return nil
@@ -594,8 +1005,9 @@ func buildAPISets(c NodeConfig) (map[string]struct{}, error) {
 		api.EndpointsPrometheus,
 		api.EndpointsNetCtrl,
 		api.EndpointsStorage,
-		// Do not include insecure or deprecated API sets, they must always
-		// be explicitly enabled through -enable-api-sets
+		// Do not include insecure or deprecated API sets, or DB_CTRL which
+		// runs expensive database checks. They must always be explicitly
+		// enabled through -enable-api-sets
 	}
 
 	if c.EnableAllAPISets {
@@ -628,11 +1040,15 @@ func validateAPISets(opt string, apiSets []string) error {
 			api.EndpointsInsecureWalletSeed,
 			api.EndpointsPrometheus,
 			api.EndpointsNetCtrl,
+			api.EndpointsDBCtrl,
 			api.EndpointsStorage:
 		case "":
 			continue
 		default:
-			return fmt.Errorf("Invalid value in %s: %q", opt, k)
+			return visor.ConfigError{
+				Field: opt,
+				Err:   fmt.Errorf("Invalid value in %s: %q", opt, k),
+			}
 		}
 	}
 	return nil
@@ -648,6 +1064,7 @@ func (c *NodeConfig) RegisterFlags() {
 	flag.BoolVar(&c.DisableIncomingConnections, "disable-incoming", c.DisableIncomingConnections, "Don't allow incoming connections")
 	flag.BoolVar(&c.DisableNetworking, "disable-networking", c.DisableNetworking, "Disable all network activity")
 	flag.BoolVar(&c.EnableGUI, "enable-gui", c.EnableGUI, "Enable GUI")
+	flag.BoolVar(&c.EnableMetrics, "enable-metrics", c.EnableMetrics, "Serve node metrics for prometheus on /metrics")
 	flag.BoolVar(&c.DisableCSRF, "disable-csrf", c.DisableCSRF, "disable CSRF check")
 	flag.BoolVar(&c.DisableHeaderCheck, "disable-header-check", c.DisableHeaderCheck, "disables the host, origin and referer header checks.")
 	flag.BoolVar(&c.DisableCSP, "disable-csp", c.DisableCSP, "disable content-security-policy in http response")
@@ -669,12 +1086,15 @@ func (c *NodeConfig) RegisterFlags() {
 		api.EndpointsTransaction,
 		api.EndpointsPrometheus,
 		api.EndpointsNetCtrl,
+		api.EndpointsDBCtrl,
 		api.EndpointsInsecureWalletSeed,
 		api.EndpointsStorage,
 	}
 	flag.StringVar(&c.EnabledAPISets, "enable-api-sets", c.EnabledAPISets, fmt.Sprintf("enable API set. Options are %s. Multiple values should be separated by comma", strings.Join(allAPISets, ", ")))
 	flag.StringVar(&c.DisabledAPISets, "disable-api-sets", c.DisabledAPISets, fmt.Sprintf("disable API set. Options are %s. Multiple values should be separated by comma", strings.Join(allAPISets, ", ")))
-	flag.BoolVar(&c.EnableAllAPISets, "enable-all-api-sets", c.EnableAllAPISets, "enable all API sets, except for deprecated or insecure sets. This option is applied before -disable-api-sets.")
+	flag.BoolVar(&c.EnableAllAPISets, "enable-all-api-sets", c.EnableAllAPISets, "enable all API sets, except for deprecated or insecure sets and DB_CTRL. This option is applied before -disable-api-sets.")
+	flag.BoolVar(&c.APIDebugLog, "api-debug-log", c.APIDebugLog, "write the full API requests and responses, including wallet seeds and passwords, to a file in the logs directory")
+	flag.StringVar(&c.APIRouteRateLimits, "api-route-rate-limits", c.APIRouteRateLimits, "limit the number of requests per minute to API routes, across all clients. Multiple route=limit values should be separated by comma, e.g. /api/v2/blockchain/richlist=6,/api/v1/outputs=30")
 
 	flag.StringVar(&c.WebInterfaceUsername, "web-interface-username", c.WebInterfaceUsername, "username for the web interface")
 	flag.StringVar(&c.WebInterfacePassword, "web-interface-password", c.WebInterfacePassword, "password for the web interface")
@@ -684,6 +1104,7 @@ func (c *NodeConfig) RegisterFlags() {
 	flag.StringVar(&c.DataDirectory, "data-dir", c.DataDirectory, "directory to store app data (defaults to ~/.skycoin)")
 	flag.StringVar(&c.DBPath, "db-path", c.DBPath, "path of database file (defaults to ~/.skycoin/data.db)")
 	flag.BoolVar(&c.DBReadOnly, "db-read-only", c.DBReadOnly, "open bolt db read-only")
+	flag.Float64Var(&c.DBFillPercent, "db-fill-percent", c.DBFillPercent, "fill percent of the bolt db pages that are split when writing, between 0.1 and 1, 0 for the default of 0.5")
 	flag.BoolVar(&c.ProfileCPU, "profile-cpu", c.ProfileCPU, "enable cpu profiling")
 	flag.StringVar(&c.ProfileCPUFile, "profile-cpu-file", c.ProfileCPUFile, "where to write the cpu profile file")
 	flag.BoolVar(&c.HTTPProf, "http-prof", c.HTTPProf, "run the HTTP profiling interface")
@@ -696,27 +1117,53 @@ func (c *NodeConfig) RegisterFlags() {
 
 	flag.BoolVar(&c.VerifyDB, "verify-db", c.VerifyDB, "check the database for corruption")
 	flag.BoolVar(&c.ResetCorruptDB, "reset-corrupt-db", c.ResetCorruptDB, "reset the database if corrupted, and continue running instead of exiting")
+	flag.BoolVar(&c.ResetCorruptDBDryRun, "reset-corrupt-db-dry-run", c.ResetCorruptDBDryRun, "with -reset-corrupt-db, log the corrupted entries and exit instead of resetting the database")
+	flag.BoolVar(&c.RepairDB, "repair-db", c.RepairDB, "if the blockchain is corrupted, remove the first corrupted block and the blocks after it and rebuild the database from the blocks before it, instead of exiting. Takes precedence over -reset-corrupt-db")
+	flag.StringVar(&c.CheckpointsFile, "checkpoints-file", c.CheckpointsFile, "signed checkpoint manifest used when checking the database (defaults to ~/.skycoin/checkpoints.json)")
+	flag.BoolVar(&c.NoCheckpoints, "no-checkpoints", c.NoCheckpoints, "don't use the checkpoint manifest, verify all block signatures when checking the database")
+	flag.DurationVar(&c.MaxVerifyDuration, "max-verify-duration", c.MaxVerifyDuration, "abort the database check if it takes longer than this, 0 for no limit")
+	flag.StringVar(&c.MempoolFile, "mempool-file", c.MempoolFile, "file the unconfirmed transactions are dumped to on shutdown and restored from on startup (defaults to ~/.skycoin/mempool.bin)")
+	flag.BoolVar(&c.NoMempoolDump, "no-mempool-dump", c.NoMempoolDump, "don't dump the unconfirmed transactions on shutdown and restore them on startup")
 
 	flag.BoolVar(&c.DisableDefaultPeers, "disable-default-peers", c.DisableDefaultPeers, "disable the hardcoded default peers")
 	flag.StringVar(&c.CustomPeersFile, "custom-peers-file", c.CustomPeersFile, "load custom peers from a newline separate list of ip:port in a file. Note that this is different from the peers.json file in the data directory")
 
+	flag.BoolVar(&c.Coordinator, "coordinator", c.Coordinator, "coordinate coinjoin rounds for peers that send join requests")
+	flag.IntVar(&c.CoordinatorMinParticipants, "coordinator-min-participants", c.CoordinatorMinParticipants, "number of join requests needed to start a coinjoin round")
+	flag.DurationVar(&c.CoordinatorRoundTimeout, "coordinator-round-timeout", c.CoordinatorRoundTimeout, "how long the participants of a coinjoin round have to sign the transaction")
+
 	flag.StringVar(&c.UserAgentRemark, "user-agent-remark", c.UserAgentRemark, "additional remark to include in the user agent sent over the wire protocol")
 
 	flag.Uint64Var(&c.maxUnconfirmedTransactionSize, "max-txn-size-unconfirmed", uint64(c.UnconfirmedVerifyTxn.MaxTransactionSize), "maximum size of an unconfirmed transaction")
 	flag.Uint64Var(&c.unconfirmedBurnFactor, "burn-factor-unconfirmed", uint64(c.UnconfirmedVerifyTxn.BurnFactor), "coinhour burn factor applied to unconfirmed transactions")
 	flag.Uint64Var(&c.unconfirmedMaxDropletPrecision, "max-decimals-unconfirmed", uint64(c.UnconfirmedVerifyTxn.MaxDropletPrecision), "max number of decimal places applied to unconfirmed transactions")
+	flag.Uint64Var(&c.UnconfirmedVerifyTxn.MinFeePerByte, "min-fee-per-byte-unconfirmed", c.UnconfirmedVerifyTxn.MinFeePerByte, "minimum coinhour fee per byte of transaction size applied to unconfirmed transactions")
 	flag.Uint64Var(&c.createBlockBurnFactor, "burn-factor-create-block", uint64(c.CreateBlockVerifyTxn.BurnFactor), "coinhour burn factor applied when creating blocks")
 	flag.Uint64Var(&c.createBlockMaxTransactionSize, "max-txn-size-create-block", uint64(c.CreateBlockVerifyTxn.MaxTransactionSize), "maximum size of a transaction applied when creating blocks")
 	flag.Uint64Var(&c.createBlockMaxDropletPrecision, "max-decimals-create-block", uint64(c.CreateBlockVerifyTxn.MaxDropletPrecision), "max number of decimal places applied when creating blocks")
+	flag.Uint64Var(&c.CreateBlockVerifyTxn.MinFeePerByte, "min-fee-per-byte-create-block", c.CreateBlockVerifyTxn.MinFeePerByte, "minimum coinhour fee per byte of transaction size applied when creating blocks")
 	flag.Uint64Var(&c.maxBlockSize, "max-block-size", uint64(c.MaxBlockTransactionsSize), "maximum total size of transactions in a block")
-
+	flag.Uint64Var(&c.signalingField, "signaling-field", uint64(c.SignalingField), "signaling field of the blocks created by a block publisher, with the bits of the supported deployments set")
+	flag.IntVar(&c.MaxUnconfirmedTransactions, "max-unconfirmed-txns", c.MaxUnconfirmedTransactions, "maximum number of transactions in the unconfirmed pool, 0 for unlimited")
+	flag.StringVar(&c.blockSizeHistogramBuckets, "block-size-histogram-buckets", joinUint32s(c.BlockSizeHistogramBuckets), "upper bounds of the buckets of the block size histogram, in bytes, separated by comma")
+	flag.DurationVar(&c.MaxFutureBlockTime, "max-future-block-time", c.MaxFutureBlockTime, "blocks whose timestamp is more than this far ahead of the local clock are rejected, 0 to disable")
+	flag.DurationVar(&c.UnconfirmedEvictionMinAge, "unconfirmed-eviction-min-age", c.UnconfirmedEvictionMinAge, "when the unconfirmed pool is full, only transactions in the pool for at least this long can be evicted")
+	flag.StringVar(&c.unconfirmedAgeHistogramBuckets, "unconfirmed-age-histogram-buckets", joinDurations(c.UnconfirmedAgeHistogramBuckets), "upper bounds of the buckets of the unconfirmed transaction age histogram, separated by comma")
+	flag.Uint64Var(&c.DustThreshold, "dust-threshold", c.DustThreshold, "minimum coins of an output of a transaction created by this node, in droplets, 0 to disable")
+	flag.StringVar(&c.dustPolicy, "dust-policy", string(c.DustPolicy), fmt.Sprintf("how change below -dust-threshold is handled when creating transactions, %q fails or %q spends more outputs", transaction.DustPolicyReject, transaction.DustPolicyMerge))
+
+	flag.StringVar(&c.NodeMode, "node-mode", c.NodeMode, fmt.Sprintf("node mode, %q keeps the full blockchain, %q deletes the transactions of old blocks", NodeModeArchival, NodeModePruned))
+	flag.Uint64Var(&c.PruneOlderThanBlocks, "prune-older-than-blocks", c.PruneOlderThanBlocks, fmt.Sprintf("in pruned mode, delete the transactions of blocks older than this many blocks (defaults to %d)", DefaultPruneOlderThanBlocks))
+	flag.BoolVar(&c.EnableEventLog, "enable-event-log", c.EnableEventLog, "record the outputs created and spent by each block in the event log, served by /api/v2/events")
 	flag.BoolVar(&c.RunBlockPublisher, "block-publisher", c.RunBlockPublisher, "run the daemon as a block publisher")
+	flag.StringVar(&c.BlockProducer, "block-producer", c.BlockProducer, fmt.Sprintf("block producer plugin used by a block publisher %v", visor.BlockProducers()))
 	flag.StringVar(&c.BlockchainPubkeyStr, "blockchain-public-key", c.BlockchainPubkeyStr, "public key of the blockchain")
 	flag.StringVar(&c.BlockchainSeckeyStr, "blockchain-secret-key", c.BlockchainSeckeyStr, "secret key of the blockchain")
 
 	flag.StringVar(&c.GenesisAddressStr, "genesis-address", c.GenesisAddressStr, "genesis address")
 	flag.StringVar(&c.GenesisSignatureStr, "genesis-signature", c.GenesisSignatureStr, "genesis block signature")
 	flag.Uint64Var(&c.GenesisTimestamp, "genesis-timestamp", c.GenesisTimestamp, "genesis block timestamp")
+	flag.StringVar(&c.GenesisOutputsFile, "genesis-outputs-file", c.GenesisOutputsFile, "JSON file of the genesis block outputs, replaces the genesis address and coin volume")
 
 	flag.StringVar(&c.WalletDirectory, "wallet-dir", c.WalletDirectory, "location of the wallet files. Defaults to ~/.skycoin/wallet/")
 	flag.StringVar(&c.KVStorageDirectory, "storage-dir", c.KVStorageDirectory, "location of the storage data files. Defaults to ~/.skycoin/data/")
@@ -728,9 +1175,14 @@ func (c *NodeConfig) RegisterFlags() {
 	flag.DurationVar(&c.OutgoingConnectionsRate, "connection-rate", c.OutgoingConnectionsRate, "How often to make an outgoing connection")
 	flag.IntVar(&c.MaxOutgoingMessageLength, "max-out-msg-len", c.MaxOutgoingMessageLength, "Maximum length of outgoing wire messages")
 	flag.IntVar(&c.MaxIncomingMessageLength, "max-in-msg-len", c.MaxIncomingMessageLength, "Maximum length of incoming wire messages")
+	flag.IntVar(&c.OutboundBandwidthLimit, "max-out-bandwidth", c.OutboundBandwidthLimit, "Maximum bytes per second sent to all peers, 0 is unlimited")
+	flag.IntVar(&c.InboundBandwidthLimit, "max-in-bandwidth", c.InboundBandwidthLimit, "Maximum bytes per second received from all peers, 0 is unlimited")
+	flag.IntVar(&c.GossipFanout, "gossip-fanout", c.GossipFanout, "Number of random peers that transactions received from peers are announced to, 0 is all peers")
 	flag.BoolVar(&c.LocalhostOnly, "localhost-only", c.LocalhostOnly, "Run on localhost and only connect to localhost peers")
 	flag.StringVar(&c.WalletCryptoType, "wallet-crypto-type", c.WalletCryptoType, "wallet crypto type. Can be sha256-xor or scrypt-chacha20poly1305")
+	flag.DurationVar(&c.WalletSessionTimeout, "wallet-session-timeout", c.WalletSessionTimeout, "How long the password of an encrypted wallet unlocked with a PIN is kept in memory, 0 disables wallet PIN sessions")
 	flag.BoolVar(&c.Version, "version", false, "show node version")
+	flag.StringVar(&c.ConfigFile, "config-file", c.ConfigFile, "JSON file of config values that override the command line flags. Log level, connection limits and API route rate limits are reloaded from this file on SIGHUP")
 }
 
 func (c *NodeConfig) applyConfigMode(configMode string) {
@@ -757,10 +1209,76 @@ func (c *NodeConfig) applyConfigMode(configMode string) {
 	}
 }
 
-func panicIfError(err error, msg string, args ...interface{}) { //nolint:unparam
-	if err != nil {
-		log.Panicf(msg+": %v", append(args, err)...)
+// buildAPIRouteRateLimits parses a comma separated list of route=requests per minute limits
+func buildAPIRouteRateLimits(s string) (map[string]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	limits := make(map[string]int)
+	for _, v := range strings.Split(s, ",") {
+		pts := strings.Split(v, "=")
+		if len(pts) != 2 || strings.TrimSpace(pts[0]) == "" {
+			return nil, fmt.Errorf("-api-route-rate-limits: invalid value %q, must be route=limit", v)
+		}
+
+		route := strings.TrimSpace(pts[0])
+		if _, ok := limits[route]; ok {
+			return nil, fmt.Errorf("-api-route-rate-limits: duplicate route %q", route)
+		}
+
+		limit, err := strconv.Atoi(strings.TrimSpace(pts[1]))
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("-api-route-rate-limits: invalid limit %q of route %q, must be a positive number of requests per minute", pts[1], route)
+		}
+
+		limits[route] = limit
+	}
+
+	return limits, nil
+}
+
+// buildAPIListeners returns the configured API listeners with their default values applied.
+// If no listeners are configured, a single listener is built from the WebInterface* options.
+func buildAPIListeners(c NodeConfig, home string) ([]APIListenerConfig, error) {
+	if len(c.APIListeners) == 0 {
+		l := APIListenerConfig{
+			Addr: fmt.Sprintf("%s:%d", c.WebInterfaceAddr, c.WebInterfacePort),
+		}
+		if c.WebInterfaceHTTPS {
+			l.TLS = &APIListenerTLSConfig{
+				Cert: c.WebInterfaceCert,
+				Key:  c.WebInterfaceKey,
+			}
+		}
+		return []APIListenerConfig{l}, nil
 	}
+
+	listeners := make([]APIListenerConfig, len(c.APIListeners))
+	for i, l := range c.APIListeners {
+		if l.Addr == "" {
+			return nil, fmt.Errorf("APIListeners[%d]: Addr is required", i)
+		}
+
+		if l.TLS != nil {
+			tls := *l.TLS
+			if tls.Cert == "" {
+				tls.Cert = c.WebInterfaceCert
+			} else {
+				tls.Cert = replaceHome(tls.Cert, home)
+			}
+			if tls.Key == "" {
+				tls.Key = c.WebInterfaceKey
+			} else {
+				tls.Key = replaceHome(tls.Key, home)
+			}
+			l.TLS = &tls
+		}
+
+		listeners[i] = l
+	}
+
+	return listeners, nil
 }
 
 func replaceHome(path, home string) string {
@@ -0,0 +1,391 @@
+package skycoin
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/consensus"
+	"github.com/skycoin/skycoin/src/fiber"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/util/logging"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func TestBuildAPIListeners(t *testing.T) {
+	base := NodeConfig{
+		WebInterfaceAddr: "127.0.0.1",
+		WebInterfacePort: 6420,
+		WebInterfaceCert: "/data/skycoind.cert",
+		WebInterfaceKey:  "/data/skycoind.key",
+	}
+
+	cases := []struct {
+		name      string
+		listeners []APIListenerConfig
+		https     bool
+		expect    []APIListenerConfig
+		err       string
+	}{
+		{
+			name: "default http listener",
+			expect: []APIListenerConfig{
+				{Addr: "127.0.0.1:6420"},
+			},
+		},
+		{
+			name:  "default https listener",
+			https: true,
+			expect: []APIListenerConfig{
+				{
+					Addr: "127.0.0.1:6420",
+					TLS: &APIListenerTLSConfig{
+						Cert: "/data/skycoind.cert",
+						Key:  "/data/skycoind.key",
+					},
+				},
+			},
+		},
+		{
+			name: "multiple listeners",
+			listeners: []APIListenerConfig{
+				{Addr: "127.0.0.1:6420"},
+				{Addr: "0.0.0.0:6421", ReadOnly: true, TLS: &APIListenerTLSConfig{}},
+				{Addr: "0.0.0.0:6422", ReadOnly: true, TLS: &APIListenerTLSConfig{Cert: "$HOME/a.cert", Key: "/b.key"}},
+			},
+			expect: []APIListenerConfig{
+				{Addr: "127.0.0.1:6420"},
+				{
+					Addr:     "0.0.0.0:6421",
+					ReadOnly: true,
+					TLS: &APIListenerTLSConfig{
+						Cert: "/data/skycoind.cert",
+						Key:  "/data/skycoind.key",
+					},
+				},
+				{
+					Addr:     "0.0.0.0:6422",
+					ReadOnly: true,
+					TLS: &APIListenerTLSConfig{
+						Cert: "/home/user/a.cert",
+						Key:  "/b.key",
+					},
+				},
+			},
+		},
+		{
+			name: "missing addr",
+			listeners: []APIListenerConfig{
+				{Addr: "127.0.0.1:6420"},
+				{ReadOnly: true},
+			},
+			err: "APIListeners[1]: Addr is required",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := base
+			c.APIListeners = tc.listeners
+			c.WebInterfaceHTTPS = tc.https
+
+			listeners, err := buildAPIListeners(c, "/home/user")
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, listeners)
+		})
+	}
+}
+
+func TestBuildAPIRouteRateLimits(t *testing.T) {
+	cases := []struct {
+		name   string
+		value  string
+		expect map[string]int
+		err    string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:  "multiple routes",
+			value: "/api/v2/blockchain/richlist=6, /api/v1/outputs = 30",
+			expect: map[string]int{
+				"/api/v2/blockchain/richlist": 6,
+				"/api/v1/outputs":             30,
+			},
+		},
+		{
+			name:  "missing limit",
+			value: "/api/v2/blockchain/richlist",
+			err:   `-api-route-rate-limits: invalid value "/api/v2/blockchain/richlist", must be route=limit`,
+		},
+		{
+			name:  "missing route",
+			value: "=6",
+			err:   `-api-route-rate-limits: invalid value "=6", must be route=limit`,
+		},
+		{
+			name:  "invalid limit",
+			value: "/api/v2/blockchain/richlist=0",
+			err:   `-api-route-rate-limits: invalid limit "0" of route "/api/v2/blockchain/richlist", must be a positive number of requests per minute`,
+		},
+		{
+			name:  "duplicate route",
+			value: "/api/v1/outputs=6,/api/v1/outputs=30",
+			err:   `-api-route-rate-limits: duplicate route "/api/v1/outputs"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			limits, err := buildAPIRouteRateLimits(tc.value)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, limits)
+		})
+	}
+}
+
+func TestParseBlockSizeHistogramBuckets(t *testing.T) {
+	buckets, err := parseBlockSizeHistogramBuckets("")
+	require.NoError(t, err)
+	require.Empty(t, buckets)
+
+	buckets, err = parseBlockSizeHistogramBuckets("256, 1024,32768")
+	require.NoError(t, err)
+	require.Equal(t, []uint32{256, 1024, 32768}, buckets)
+
+	_, err = parseBlockSizeHistogramBuckets("256,foo")
+	require.EqualError(t, err, `-block-size-histogram-buckets: invalid size "foo"`)
+
+	_, err = parseBlockSizeHistogramBuckets("1024,256")
+	require.EqualError(t, err, "-block-size-histogram-buckets must be in increasing order")
+
+	require.Equal(t, "256,512,1024,2048,4096,8192,16384,32768", joinUint32s(visor.DefaultBlockSizeHistogramBuckets))
+}
+
+func TestParseUnconfirmedAgeHistogramBuckets(t *testing.T) {
+	buckets, err := parseUnconfirmedAgeHistogramBuckets("")
+	require.NoError(t, err)
+	require.Empty(t, buckets)
+
+	buckets, err = parseUnconfirmedAgeHistogramBuckets("30s, 1m,1h")
+	require.NoError(t, err)
+	require.Equal(t, []time.Duration{30 * time.Second, time.Minute, time.Hour}, buckets)
+
+	_, err = parseUnconfirmedAgeHistogramBuckets("30s,foo")
+	require.EqualError(t, err, `-unconfirmed-age-histogram-buckets: invalid duration "foo"`)
+
+	_, err = parseUnconfirmedAgeHistogramBuckets("0s,30s")
+	require.EqualError(t, err, `-unconfirmed-age-histogram-buckets: invalid duration "0s"`)
+
+	_, err = parseUnconfirmedAgeHistogramBuckets("1m,30s")
+	require.EqualError(t, err, "-unconfirmed-age-histogram-buckets must be in increasing order")
+
+	buckets, err = parseUnconfirmedAgeHistogramBuckets(joinDurations(visor.DefaultUnconfirmedAgeHistogramBuckets))
+	require.NoError(t, err)
+	require.Equal(t, visor.DefaultUnconfirmedAgeHistogramBuckets, buckets)
+}
+
+func newTestConfig(dataDir string) Config {
+	node := NewNodeConfig("", fiber.NodeConfig{
+		CoinName:            "skycoin",
+		GenesisSignatureStr: "eb10468d10054d15f2b6f8946cd46797779aa20a7617ceb4be884189f219bc9a164e56a5b9f7bec392a804ff3740210348d73db77a37adb542a8e08d429ac92700",
+		GenesisAddressStr:   "2jBbGxZRGoQG1mqhPBnXnLTxK6oxsTf8os6",
+		BlockchainPubkeyStr: "0328c576d3f420e7682058a981173a4b374c7cc5ff55bf394d3cf57059bbe6456a",
+		GenesisTimestamp:    1426562704,
+		GenesisCoinVolume:   100e12,
+		Port:                6000,
+		WebInterfacePort:    6420,
+
+		UnconfirmedBurnFactor:          10,
+		UnconfirmedMaxTransactionSize:  32768,
+		UnconfirmedMaxDropletPrecision: 3,
+		CreateBlockBurnFactor:          10,
+		CreateBlockMaxTransactionSize:  32768,
+		CreateBlockMaxDropletPrecision: 3,
+		MaxBlockTransactionsSize:       32768,
+		SignalingWindow:                1000,
+		SignalingThreshold:             95,
+		SignalingRoundLength:           1000,
+	})
+	node.DataDirectory = dataDir
+
+	// The values of the flags that are not registered in the test
+	node.unconfirmedBurnFactor = uint64(node.UnconfirmedVerifyTxn.BurnFactor)
+	node.maxUnconfirmedTransactionSize = uint64(node.UnconfirmedVerifyTxn.MaxTransactionSize)
+	node.unconfirmedMaxDropletPrecision = uint64(node.UnconfirmedVerifyTxn.MaxDropletPrecision)
+	node.createBlockBurnFactor = uint64(node.CreateBlockVerifyTxn.BurnFactor)
+	node.createBlockMaxTransactionSize = uint64(node.CreateBlockVerifyTxn.MaxTransactionSize)
+	node.createBlockMaxDropletPrecision = uint64(node.CreateBlockVerifyTxn.MaxDropletPrecision)
+	node.maxBlockSize = uint64(node.MaxBlockTransactionsSize)
+	node.signalingField = uint64(node.SignalingField)
+	node.dustPolicy = string(node.DustPolicy)
+
+	return Config{
+		Node: node,
+		Build: readable.BuildInfo{
+			Version: "0.27.0",
+		},
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parse-config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := NewCoin(newTestConfig(dir), logging.MustGetLogger("test"))
+	require.NoError(t, c.ParseConfig())
+
+	// All the problems with the flags are returned, a flag is not checked again after a problem
+	cfg := newTestConfig(dir)
+	cfg.Node.GenesisSignatureStr = "foo"
+	cfg.Node.BlockchainPubkeyStr = "foo"
+	cfg.Node.NodeMode = "foo"
+	cfg.Node.maxBlockSize = math.MaxUint32 + 1
+	cfg.Node.signalingField = math.MaxUint32 + 1
+	cfg.Node.unconfirmedBurnFactor = 1
+	cfg.Node.LogLevel = "foo"
+
+	err = NewCoin(cfg, logging.MustGetLogger("test")).ParseConfig()
+	require.IsType(t, visor.ConfigErrors{}, err)
+	errs := err.(visor.ConfigErrors)
+
+	fields := make([]string, len(errs))
+	for i, e := range errs {
+		fields[i] = e.Field
+	}
+	require.Equal(t, []string{
+		"-genesis-signature",
+		"-blockchain-public-key",
+		"-node-mode",
+		"-max-block-size",
+		"-signaling-field",
+		"-log-level",
+		"-burn-factor-unconfirmed",
+	}, fields)
+	require.Equal(t, "-max-block-size exceeds MaxUint32", errs[3].Error())
+	require.Equal(t, "-signaling-field exceeds MaxUint32", errs[4].Error())
+	require.Equal(t, fmt.Sprintf("-burn-factor-unconfirmed must be >= params.MinBurnFactor (%d)", params.MinBurnFactor), errs[6].Error())
+
+	// The visor config is validated after the flags
+	cfg = newTestConfig(dir)
+	cfg.Node.RunBlockPublisher = true
+	cfg.Node.DBFillPercent = 2
+	cfg.Node.Signaling.Deployments = []consensus.SignalingDeployment{
+		{
+			Bit:       0,
+			Parameter: consensus.SignalingMaxBlockSize,
+			Value:     1,
+		},
+	}
+
+	err = NewCoin(cfg, logging.MustGetLogger("test")).ParseConfig()
+	require.Equal(t, visor.ConfigErrors{
+		{
+			Field: "BlockchainSeckey",
+			Err:   errors.New("Cannot run as block publisher: invalid seckey for pubkey"),
+		},
+		{
+			Field: "Signaling",
+			Err:   errors.New("signaling max_block_size must be >= CreateBlockVerifyTxn.MaxTransactionSize (32768) and fit in 32 bits"),
+		},
+		{
+			Field: "DBFillPercent",
+			Err:   errors.New("DBFillPercent must be 0 or between 0.1 and 1"),
+		},
+	}, err)
+}
+
+func TestConfigureSignaling(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configure-signaling")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	node := NewNodeConfig("", fiber.NodeConfig{
+		SignalingWindow:      100,
+		SignalingThreshold:   90,
+		SignalingRoundLength: 200,
+		SignalingDeployments: []fiber.SignalingDeployment{
+			{
+				Bit:       1,
+				Parameter: "max_block_size",
+				Value:     65536,
+			},
+			{
+				Bit:       2,
+				Parameter: "burn_factor",
+				Value:     20,
+			},
+		},
+	})
+	signaling := consensus.SignalingConfig{
+		SignalingWindow:       100,
+		MinSignalingThreshold: 90,
+		RoundLength:           200,
+		Deployments: []consensus.SignalingDeployment{
+			{
+				Bit:       1,
+				Parameter: consensus.SignalingMaxBlockSize,
+				Value:     65536,
+			},
+			{
+				Bit:       2,
+				Parameter: consensus.SignalingBurnFactor,
+				Value:     20,
+			},
+		},
+	}
+	require.Equal(t, signaling, node.Signaling)
+
+	cfg := newTestConfig(dir)
+	cfg.Node.Signaling = signaling
+	cfg.Node.signalingField = 6
+
+	c := NewCoin(cfg, logging.MustGetLogger("test"))
+	require.NoError(t, c.ParseConfig())
+
+	vc := c.ConfigureVisor()
+	require.Equal(t, signaling, vc.Signaling)
+	require.Equal(t, uint32(6), vc.SignalingField)
+	require.Equal(t, uint32(32768), vc.MaxBlockTransactionsSize)
+
+	// The daemon checks that the largest block that can be created fits in a message
+	dc := c.ConfigureDaemon()
+	require.Equal(t, uint32(65536), dc.Daemon.MaxBlockTransactionsSize)
+
+	require.Equal(t, uint32(32768), maxSignaledBlockSize(32768, consensus.SignalingConfig{}))
+	require.Equal(t, uint32(32768), maxSignaledBlockSize(32768, consensus.SignalingConfig{
+		Deployments: []consensus.SignalingDeployment{
+			{
+				Parameter: consensus.SignalingMaxBlockSize,
+				Value:     1024,
+			},
+		},
+	}))
+	require.Equal(t, uint32(math.MaxUint32), maxSignaledBlockSize(32768, consensus.SignalingConfig{
+		Deployments: []consensus.SignalingDeployment{
+			{
+				Parameter: consensus.SignalingMaxBlockSize,
+				Value:     math.MaxUint32 + 1,
+			},
+		},
+	}))
+}
@@ -1,7 +1,11 @@
 package skycoin
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"sync"
+	"time"
 
 	"github.com/blang/semver"
 
@@ -11,12 +15,19 @@ import (
 	"github.com/skycoin/skycoin/src/visor/dbutil"
 )
 
+// ErrVerifyTimeout is returned if the database check does not complete within dbCheckConfig.MaxVerifyDuration
+var ErrVerifyTimeout = errors.New("database verification timed out")
+
+// ErrCorruptDBDryRun is returned if the database is corrupted and dbCheckConfig.ResetCorruptDBDryRun is set
+var ErrCorruptDBDryRun = errors.New("database is corrupted, not resetting it in dry run mode")
+
 type dbAction uint
 
 const (
 	doNothing dbAction = iota
 	doCheckDB
 	doResetCorruptDB
+	doRepairDB
 )
 
 // dbCheckConfig contains the parameters for verifying db
@@ -25,28 +36,37 @@ type dbCheckConfig struct {
 	ForceVerify bool
 	// ResetCorruptDB reset the DB if it is corrupted
 	ResetCorruptDB bool
+	// ResetCorruptDBDryRun logs the corrupted entries and aborts instead of resetting the DB if it is corrupted
+	ResetCorruptDBDryRun bool
+	// RepairDB truncates the blockchain at the first corrupted block instead of resetting the DB, and takes precedence over ResetCorruptDB
+	RepairDB bool
 	// AppVersion is the current wallet version
 	AppVersion *semver.Version
 	// DBCheckpointVersion is the check point db version
 	DBCheckpointVersion *semver.Version
+	// MaxVerifyDuration aborts the database check if it takes longer, 0 for no limit
+	MaxVerifyDuration time.Duration
 }
 
 //go:generate mockery -name dbCheckCorruptResetter -case underscore -inpkg -testonly
 type dbCheckCorruptResetter interface {
-	CheckDatabase(db *dbutil.DB) error
-	ResetCorruptDB(db *dbutil.DB) (*dbutil.DB, error)
+	CheckDatabase(db *dbutil.DB, quit chan struct{}) error
+	ResetCorruptDB(db *dbutil.DB, quit chan struct{}) (*dbutil.DB, error)
+	RepairDB(db *dbutil.DB, quit chan struct{}) (*dbutil.DB, error)
+	DescribeCorruption(db *dbutil.DB, quit chan struct{}) ([]visor.CorruptionDescription, error)
 	GetDBVersion(db *dbutil.DB) (*semver.Version, error)
 	SetDBVersion(db *dbutil.DB, v *semver.Version) error
+	MigrateDB(db *dbutil.DB, appVersion *semver.Version) error
 }
 
 type dbVerify struct {
 	blockchainPubkey cipher.PubKey
+	checkpoints      *visor.Checkpoints
 	logger           *logging.Logger
-	quit             chan struct{}
 }
 
-func (dv dbVerify) CheckDatabase(db *dbutil.DB) error {
-	if err := visor.CheckDatabase(db, dv.blockchainPubkey, dv.quit); err != nil {
+func (dv dbVerify) CheckDatabase(db *dbutil.DB, quit chan struct{}) error {
+	if err := visor.CheckDatabase(db, dv.blockchainPubkey, dv.checkpoints, quit); err != nil {
 		if err != visor.ErrVerifyStopped {
 			dv.logger.WithError(err).Error("visor.CheckDatabase failed")
 		}
@@ -55,9 +75,9 @@ func (dv dbVerify) CheckDatabase(db *dbutil.DB) error {
 	return nil
 }
 
-func (dv *dbVerify) ResetCorruptDB(db *dbutil.DB) (*dbutil.DB, error) {
+func (dv *dbVerify) ResetCorruptDB(db *dbutil.DB, quit chan struct{}) (*dbutil.DB, error) {
 	dv.logger.Info("Checking database and resetting if corrupted")
-	newDB, err := visor.ResetCorruptDB(db, dv.blockchainPubkey, dv.quit)
+	newDB, err := visor.ResetCorruptDB(db, dv.blockchainPubkey, dv.checkpoints, quit)
 	if err != nil {
 		if err != visor.ErrVerifyStopped {
 			dv.logger.WithError(err).Error("visor.ResetCorruptDB failed")
@@ -68,6 +88,46 @@ func (dv *dbVerify) ResetCorruptDB(db *dbutil.DB) (*dbutil.DB, error) {
 	return newDB, nil
 }
 
+func (dv *dbVerify) RepairDB(db *dbutil.DB, quit chan struct{}) (*dbutil.DB, error) {
+	dv.logger.Info("Checking the blockchain and repairing the database if corrupted")
+	newDB, report, err := visor.RepairDB(db, dv.blockchainPubkey, quit)
+	if err != nil {
+		if err != visor.ErrVerifyStopped {
+			dv.logger.WithError(err).Error("visor.RepairDB failed")
+		}
+		return nil, err
+	}
+
+	if report.Truncated {
+		dv.logger.WithError(report.Err).Warningf("Repaired the database, removed %d blocks from height %d. The corrupted database was moved to %s",
+			report.BlocksRemoved, report.TruncatedHeight, report.BackupPath)
+	} else {
+		dv.logger.Info("The blockchain is not corrupted, the database was not changed")
+	}
+
+	return newDB, nil
+}
+
+func (dv dbVerify) DescribeCorruption(db *dbutil.DB, quit chan struct{}) ([]visor.CorruptionDescription, error) {
+	dv.logger.Info("Checking database for corruption (dry run, the database will not be reset)")
+	descs, err := visor.DescribeCorruption(db, dv.blockchainPubkey, dv.checkpoints, quit)
+	if err != nil {
+		if err != visor.ErrVerifyStopped {
+			dv.logger.WithError(err).Error("visor.DescribeCorruption failed")
+		}
+		return nil, err
+	}
+
+	for _, d := range descs {
+		dv.logger.WithError(d.Err).Errorf("Database is corrupted: bucket=%q key=%q", d.Bucket, d.Key)
+	}
+	if len(descs) > 0 {
+		dv.logger.Errorf("A reset would move the database %s to a backup file and start with an empty database", db.Path())
+	}
+
+	return descs, nil
+}
+
 func (dv *dbVerify) SetDBVersion(db *dbutil.DB, v *semver.Version) error {
 	if err := visor.SetDBVersion(db, *v); err != nil {
 		if err != visor.ErrVerifyStopped {
@@ -78,6 +138,19 @@ func (dv *dbVerify) SetDBVersion(db *dbutil.DB, v *semver.Version) error {
 	return nil
 }
 
+func (dv dbVerify) MigrateDB(db *dbutil.DB, appVersion *semver.Version) error {
+	n, err := visor.MigrateDB(db, *appVersion)
+	if err != nil {
+		dv.logger.WithError(err).Error("visor.MigrateDB failed")
+		return err
+	}
+
+	if n > 0 {
+		dv.logger.Infof("Ran %d database migrations", n)
+	}
+	return nil
+}
+
 func (dv dbVerify) GetDBVersion(db *dbutil.DB) (*semver.Version, error) {
 	dbVersion, err := visor.GetDBVersion(db)
 	if err != nil {
@@ -93,7 +166,26 @@ func (dv dbVerify) GetDBVersion(db *dbutil.DB) (*semver.Version, error) {
 	return dbVersion, nil
 }
 
-func checkAndUpdateDB(db *dbutil.DB, c dbCheckConfig, dv dbCheckCorruptResetter) (*dbutil.DB, error) {
+// loadCheckpoints loads the checkpoint manifest, if it exists.
+// A missing manifest is not an error, the database check verifies all block signatures instead.
+func loadCheckpoints(path string, pubkey cipher.PubKey, logger *logging.Logger) (*visor.Checkpoints, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		logger.Infof("Checkpoint manifest %s not found, all block signatures will be verified", path)
+		return nil, nil
+	}
+
+	checkpoints, err := visor.LoadCheckpointManifest(path, pubkey)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Loaded checkpoint manifest %s, last checkpoint is block %d", path, checkpoints.LastSeq())
+	return checkpoints, nil
+}
+
+// checkAndUpdateDB runs the registered database migrations, checks the database if needed and sets its version to the app version.
+// The check is stopped when quit is closed, and returns ErrVerifyTimeout if it takes longer than c.MaxVerifyDuration
+func checkAndUpdateDB(db *dbutil.DB, c dbCheckConfig, dv dbCheckCorruptResetter, quit chan struct{}) (*dbutil.DB, error) {
 	dbVersion, err := dv.GetDBVersion(db)
 	if err != nil {
 		return nil, err
@@ -104,15 +196,53 @@ func checkAndUpdateDB(db *dbutil.DB, c dbCheckConfig, dv dbCheckCorruptResetter)
 		return nil, err
 	}
 
+	// Migrate before the check, which verifies the database with the current schema
+	if err := dv.MigrateDB(db, c.AppVersion); err != nil {
+		return nil, err
+	}
+
 	switch action {
 	case doCheckDB:
-		if err := dv.CheckDatabase(db); err != nil {
+		if err := withVerifyTimeout(quit, c.MaxVerifyDuration, func(quit chan struct{}) error {
+			return dv.CheckDatabase(db, quit)
+		}); err != nil {
 			return nil, err
 		}
 	case doResetCorruptDB:
+		if c.ResetCorruptDBDryRun {
+			var descs []visor.CorruptionDescription
+			if err := withVerifyTimeout(quit, c.MaxVerifyDuration, func(quit chan struct{}) error {
+				var err error
+				descs, err = dv.DescribeCorruption(db, quit)
+				return err
+			}); err != nil {
+				return nil, err
+			}
+
+			if len(descs) > 0 {
+				return nil, ErrCorruptDBDryRun
+			}
+			break
+		}
+
 		// Check the database integrity and recreate it if necessary
-		newDB, err := dv.ResetCorruptDB(db)
-		if err != nil {
+		var newDB *dbutil.DB
+		if err := withVerifyTimeout(quit, c.MaxVerifyDuration, func(quit chan struct{}) error {
+			var err error
+			newDB, err = dv.ResetCorruptDB(db, quit)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		db = newDB
+	case doRepairDB:
+		// Truncate the blockchain at the first corrupted block and rebuild the database from the blocks before it
+		var newDB *dbutil.DB
+		if err := withVerifyTimeout(quit, c.MaxVerifyDuration, func(quit chan struct{}) error {
+			var err error
+			newDB, err = dv.RepairDB(db, quit)
+			return err
+		}); err != nil {
 			return nil, err
 		}
 		db = newDB
@@ -128,16 +258,58 @@ func checkAndUpdateDB(db *dbutil.DB, c dbCheckConfig, dv dbCheckCorruptResetter)
 	return db, nil
 }
 
+// withVerifyTimeout calls f with a quit channel that is closed when quit is closed, or after maxDuration if it is not 0.
+// If f is stopped by the timeout, ErrVerifyTimeout is returned instead of visor.ErrVerifyStopped
+func withVerifyTimeout(quit chan struct{}, maxDuration time.Duration, f func(quit chan struct{}) error) error {
+	if maxDuration <= 0 {
+		return f(quit)
+	}
+
+	verifyQuit := make(chan struct{})
+	done := make(chan struct{})
+	timer := time.NewTimer(maxDuration)
+	defer timer.Stop()
+
+	var timedOut bool
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-quit:
+		case <-timer.C:
+			timedOut = true
+		case <-done:
+			return
+		}
+		close(verifyQuit)
+	}()
+
+	err := f(verifyQuit)
+	close(done)
+	wg.Wait()
+
+	if timedOut && err == visor.ErrVerifyStopped {
+		return ErrVerifyTimeout
+	}
+
+	return err
+}
+
 // checkDB checks whether need to verify or reset the DB version
 func checkDB(c dbCheckConfig, dbVersion *semver.Version) (dbAction, error) {
 	// If the saved DB version is higher than the app version, abort.
 	// Otherwise DB corruption could occur.
-	if dbVersion != nil && dbVersion.GT(*c.AppVersion) {
+	if dbVersionNewerThan(dbVersion, c.AppVersion) {
 		return doNothing, fmt.Errorf("Cannot use newer DB version=%v with older software version=%v", dbVersion, c.AppVersion)
 	}
 
 	// Verify the DB if the version detection says to, or if it was requested on the command line
 	if shouldVerifyDB(c.AppVersion, dbVersion, c.DBCheckpointVersion) || c.ForceVerify {
+		if c.RepairDB {
+			return doRepairDB, nil
+		}
+
 		if c.ResetCorruptDB {
 			return doResetCorruptDB, nil
 		}
@@ -157,9 +329,39 @@ func shouldVerifyDB(appVersion, dbVersion, checkpointVersion *semver.Version) bo
 	// If the dbVersion is less than the verification checkpoint version
 	// and the appVersion is greater than or equal to the checkpoint version,
 	// verify
-	if dbVersion.LT(*checkpointVersion) && appVersion.GTE(*checkpointVersion) {
+	if dbVersionOlderThan(dbVersion, checkpointVersion) && versionAtLeast(appVersion, checkpointVersion) {
 		return true
 	}
 
 	return false
 }
+
+// compareVersions returns -1 if a is lower than b, 0 if they are equal and 1 if a is higher than b.
+// A nil version is lower than any version, and equal to another nil version
+func compareVersions(a, b *semver.Version) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	default:
+		return a.Compare(*b)
+	}
+}
+
+// dbVersionOlderThan returns true if dbVersion is lower than v. A DB without a version is older than any version
+func dbVersionOlderThan(dbVersion, v *semver.Version) bool {
+	return compareVersions(dbVersion, v) < 0
+}
+
+// dbVersionNewerThan returns true if dbVersion is higher than v. A DB without a version is not newer than any version
+func dbVersionNewerThan(dbVersion, v *semver.Version) bool {
+	return compareVersions(dbVersion, v) > 0
+}
+
+// versionAtLeast returns true if a is higher than or equal to b. A nil a is only at least a nil b
+func versionAtLeast(a, b *semver.Version) bool {
+	return compareVersions(a, b) >= 0
+}
@@ -3,12 +3,14 @@ package skycoin
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
 	"github.com/skycoin/skycoin/src/visor/dbutil"
 )
 
@@ -91,6 +93,24 @@ func TestCheckDB(t *testing.T) {
 			dbVersion: v26,
 			exp:       expect{action: doResetCorruptDB},
 		},
+		{
+			name:      "db version < check point < app version, repair db",
+			params:    dbCheckConfig{AppVersion: v26, RepairDB: true, DBCheckpointVersion: v25},
+			dbVersion: v241,
+			exp:       expect{action: doRepairDB},
+		},
+		{
+			name:      "db version == app version, force verify, repair db takes precedence over reset corrupt db",
+			params:    dbCheckConfig{AppVersion: v26, ForceVerify: true, ResetCorruptDB: true, RepairDB: true, DBCheckpointVersion: v25},
+			dbVersion: v26,
+			exp:       expect{action: doRepairDB},
+		},
+		{
+			name:      "db version == check point, repair db, do nothing",
+			params:    dbCheckConfig{AppVersion: v26, RepairDB: true, DBCheckpointVersion: v25},
+			dbVersion: v26,
+			exp:       expect{action: doNothing},
+		},
 	}
 
 	for _, tc := range tt {
@@ -148,8 +168,8 @@ func TestCheckAndUpdateDB(t *testing.T) {
 			assertCalled: func(t *testing.T, db *dbutil.DB, m *mockDbCheckCorruptResetter) {
 				require.True(t, m.AssertCalled(t, "GetDBVersion", matchFunc))
 				require.True(t, m.AssertCalled(t, "SetDBVersion", matchFunc, v26))
-				require.True(t, m.AssertNotCalled(t, "CheckDatabase", matchFunc))
-				require.True(t, m.AssertNotCalled(t, "ResetCorruptDB", matchFunc))
+				require.True(t, m.AssertNotCalled(t, "CheckDatabase", matchFunc, mock.Anything))
+				require.True(t, m.AssertNotCalled(t, "ResetCorruptDB", matchFunc, mock.Anything))
 			},
 		},
 		{
@@ -160,9 +180,9 @@ func TestCheckAndUpdateDB(t *testing.T) {
 			setDBVersion: v26,
 			assertCalled: func(t *testing.T, db *dbutil.DB, m *mockDbCheckCorruptResetter) {
 				require.True(t, m.AssertCalled(t, "GetDBVersion", matchFunc))
-				require.True(t, m.AssertCalled(t, "CheckDatabase", matchFunc))
+				require.True(t, m.AssertCalled(t, "CheckDatabase", matchFunc, mock.Anything))
 				require.True(t, m.AssertCalled(t, "SetDBVersion", matchFunc, v26))
-				require.True(t, m.AssertNotCalled(t, "ResetCorruptDB", matchFunc))
+				require.True(t, m.AssertNotCalled(t, "ResetCorruptDB", matchFunc, mock.Anything))
 			},
 		},
 		{
@@ -174,9 +194,9 @@ func TestCheckAndUpdateDB(t *testing.T) {
 			resetedDB:    resetedDB,
 			assertCalled: func(t *testing.T, db *dbutil.DB, m *mockDbCheckCorruptResetter) {
 				require.True(t, m.AssertCalled(t, "GetDBVersion", matchFunc))
-				require.True(t, m.AssertNotCalled(t, "CheckDatabase", matchFunc))
+				require.True(t, m.AssertNotCalled(t, "CheckDatabase", matchFunc, mock.Anything))
 				require.True(t, m.AssertCalled(t, "SetDBVersion", matchFunc, v26))
-				require.True(t, m.AssertCalled(t, "ResetCorruptDB", matchFunc))
+				require.True(t, m.AssertCalled(t, "ResetCorruptDB", matchFunc, mock.Anything))
 			},
 		},
 		{
@@ -190,9 +210,9 @@ func TestCheckAndUpdateDB(t *testing.T) {
 			retErr:    errors.New("Cannot use newer DB version=0.25.0 with older software version=0.24.1"),
 			assertCalled: func(t *testing.T, db *dbutil.DB, m *mockDbCheckCorruptResetter) {
 				require.True(t, m.AssertCalled(t, "GetDBVersion", matchFunc))
-				require.True(t, m.AssertNotCalled(t, "CheckDatabase", matchFunc))
+				require.True(t, m.AssertNotCalled(t, "CheckDatabase", matchFunc, mock.Anything))
 				require.True(t, m.AssertNotCalled(t, "SetDBVersion", matchFunc, v26))
-				require.True(t, m.AssertNotCalled(t, "ResetCorruptDB", matchFunc))
+				require.True(t, m.AssertNotCalled(t, "ResetCorruptDB", matchFunc, mock.Anything))
 			},
 		},
 		{
@@ -207,9 +227,9 @@ func TestCheckAndUpdateDB(t *testing.T) {
 			setDBVersion: v26,
 			assertCalled: func(t *testing.T, db *dbutil.DB, m *mockDbCheckCorruptResetter) {
 				require.True(t, m.AssertCalled(t, "GetDBVersion", matchFunc))
-				require.True(t, m.AssertCalled(t, "CheckDatabase", matchFunc))
+				require.True(t, m.AssertCalled(t, "CheckDatabase", matchFunc, mock.Anything))
 				require.True(t, m.AssertCalled(t, "SetDBVersion", matchFunc, v26))
-				require.True(t, m.AssertNotCalled(t, "ResetCorruptDB", matchFunc))
+				require.True(t, m.AssertNotCalled(t, "ResetCorruptDB", matchFunc, mock.Anything))
 			},
 		},
 		{
@@ -223,9 +243,9 @@ func TestCheckAndUpdateDB(t *testing.T) {
 			checkDBErr: nil,
 			assertCalled: func(t *testing.T, db *dbutil.DB, m *mockDbCheckCorruptResetter) {
 				require.True(t, m.AssertCalled(t, "GetDBVersion", matchFunc))
-				require.True(t, m.AssertCalled(t, "CheckDatabase", matchFunc))
+				require.True(t, m.AssertCalled(t, "CheckDatabase", matchFunc, mock.Anything))
 				require.True(t, m.AssertNotCalled(t, "SetDBVersion", matchFunc, v26))
-				require.True(t, m.AssertNotCalled(t, "ResetCorruptDB", matchFunc))
+				require.True(t, m.AssertNotCalled(t, "ResetCorruptDB", matchFunc, mock.Anything))
 			},
 		},
 		{
@@ -240,9 +260,9 @@ func TestCheckAndUpdateDB(t *testing.T) {
 			retErr:     errors.New("check db error"),
 			assertCalled: func(t *testing.T, db *dbutil.DB, m *mockDbCheckCorruptResetter) {
 				require.True(t, m.AssertCalled(t, "GetDBVersion", matchFunc))
-				require.True(t, m.AssertCalled(t, "CheckDatabase", matchFunc))
+				require.True(t, m.AssertCalled(t, "CheckDatabase", matchFunc, mock.Anything))
 				require.True(t, m.AssertNotCalled(t, "SetDBVersion", matchFunc, v26))
-				require.True(t, m.AssertNotCalled(t, "ResetCorruptDB", matchFunc))
+				require.True(t, m.AssertNotCalled(t, "ResetCorruptDB", matchFunc, mock.Anything))
 			},
 		},
 		{
@@ -258,8 +278,8 @@ func TestCheckAndUpdateDB(t *testing.T) {
 			setDBVersion: v26,
 			assertCalled: func(t *testing.T, db *dbutil.DB, m *mockDbCheckCorruptResetter) {
 				require.True(t, m.AssertCalled(t, "GetDBVersion", matchFunc))
-				require.True(t, m.AssertNotCalled(t, "CheckDatabase", matchFunc))
-				require.True(t, m.AssertCalled(t, "ResetCorruptDB", matchFunc))
+				require.True(t, m.AssertNotCalled(t, "CheckDatabase", matchFunc, mock.Anything))
+				require.True(t, m.AssertCalled(t, "ResetCorruptDB", matchFunc, mock.Anything))
 				require.True(t, m.AssertCalled(t, "SetDBVersion", matchFunc, v26))
 			},
 		},
@@ -276,8 +296,8 @@ func TestCheckAndUpdateDB(t *testing.T) {
 			retErr:     errors.New("reset corrupt db failed"),
 			assertCalled: func(t *testing.T, db *dbutil.DB, m *mockDbCheckCorruptResetter) {
 				require.True(t, m.AssertCalled(t, "GetDBVersion", matchFunc))
-				require.True(t, m.AssertNotCalled(t, "CheckDatabase", matchFunc))
-				require.True(t, m.AssertCalled(t, "ResetCorruptDB", matchFunc))
+				require.True(t, m.AssertNotCalled(t, "CheckDatabase", matchFunc, mock.Anything))
+				require.True(t, m.AssertCalled(t, "ResetCorruptDB", matchFunc, mock.Anything))
 				require.True(t, m.AssertNotCalled(t, "SetDBVersion", matchFunc, v26))
 			},
 		},
@@ -292,8 +312,8 @@ func TestCheckAndUpdateDB(t *testing.T) {
 			setDBVersion: v25,
 			assertCalled: func(t *testing.T, db *dbutil.DB, m *mockDbCheckCorruptResetter) {
 				require.True(t, m.AssertCalled(t, "GetDBVersion", matchFunc))
-				require.True(t, m.AssertCalled(t, "CheckDatabase", matchFunc))
-				require.True(t, m.AssertNotCalled(t, "ResetCorruptDB", matchFunc))
+				require.True(t, m.AssertCalled(t, "CheckDatabase", matchFunc, mock.Anything))
+				require.True(t, m.AssertNotCalled(t, "ResetCorruptDB", matchFunc, mock.Anything))
 				require.True(t, m.AssertCalled(t, "SetDBVersion", matchFunc, v25))
 			},
 		},
@@ -310,8 +330,8 @@ func TestCheckAndUpdateDB(t *testing.T) {
 			resetedDB:    resetedDB,
 			assertCalled: func(t *testing.T, db *dbutil.DB, m *mockDbCheckCorruptResetter) {
 				require.True(t, m.AssertCalled(t, "GetDBVersion", matchFunc))
-				require.True(t, m.AssertNotCalled(t, "CheckDatabase", matchFunc))
-				require.True(t, m.AssertCalled(t, "ResetCorruptDB", matchFunc))
+				require.True(t, m.AssertNotCalled(t, "CheckDatabase", matchFunc, mock.Anything))
+				require.True(t, m.AssertCalled(t, "ResetCorruptDB", matchFunc, mock.Anything))
 				require.True(t, m.AssertCalled(t, "SetDBVersion", matchFunc, v25))
 			},
 		},
@@ -327,8 +347,8 @@ func TestCheckAndUpdateDB(t *testing.T) {
 			setDBVersion: v26,
 			assertCalled: func(t *testing.T, db *dbutil.DB, m *mockDbCheckCorruptResetter) {
 				require.True(t, m.AssertCalled(t, "GetDBVersion", matchFunc))
-				require.True(t, m.AssertCalled(t, "CheckDatabase", matchFunc))
-				require.True(t, m.AssertNotCalled(t, "ResetCorruptDB", matchFunc))
+				require.True(t, m.AssertCalled(t, "CheckDatabase", matchFunc, mock.Anything))
+				require.True(t, m.AssertNotCalled(t, "ResetCorruptDB", matchFunc, mock.Anything))
 				require.True(t, m.AssertCalled(t, "SetDBVersion", matchFunc, v26))
 			},
 		},
@@ -346,8 +366,8 @@ func TestCheckAndUpdateDB(t *testing.T) {
 			resetedDB:    resetedDB,
 			assertCalled: func(t *testing.T, db *dbutil.DB, m *mockDbCheckCorruptResetter) {
 				require.True(t, m.AssertCalled(t, "GetDBVersion", matchFunc))
-				require.True(t, m.AssertNotCalled(t, "CheckDatabase", matchFunc))
-				require.True(t, m.AssertCalled(t, "ResetCorruptDB", matchFunc))
+				require.True(t, m.AssertNotCalled(t, "CheckDatabase", matchFunc, mock.Anything))
+				require.True(t, m.AssertCalled(t, "ResetCorruptDB", matchFunc, mock.Anything))
 				require.True(t, m.AssertCalled(t, "SetDBVersion", matchFunc, v26))
 			},
 		},
@@ -358,10 +378,11 @@ func TestCheckAndUpdateDB(t *testing.T) {
 			m := &mockDbCheckCorruptResetter{}
 			m.On("GetDBVersion", matchFunc).Return(tc.dbVersion, tc.dbVersionErr)
 			m.On("SetDBVersion", matchFunc, tc.setDBVersion).Return(tc.setDBVersionErr)
-			m.On("CheckDatabase", matchFunc).Return(tc.checkDBErr)
-			m.On("ResetCorruptDB", matchFunc).Return(tc.resetedDB, tc.resetDBErr)
+			m.On("MigrateDB", matchFunc, tc.config.AppVersion).Return(nil)
+			m.On("CheckDatabase", matchFunc, mock.Anything).Return(tc.checkDBErr)
+			m.On("ResetCorruptDB", matchFunc, mock.Anything).Return(tc.resetedDB, tc.resetDBErr)
 
-			dbAfter, err := checkAndUpdateDB(tc.db, tc.config, m)
+			dbAfter, err := checkAndUpdateDB(tc.db, tc.config, m, nil)
 			require.Equal(t, tc.retErr, err)
 			tc.assertCalled(t, tc.db, m)
 			if err != nil {
@@ -376,3 +397,506 @@ func TestCheckAndUpdateDB(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckAndUpdateDBVersionNil(t *testing.T) {
+	v241, err := semver.New("0.24.1")
+	require.NoError(t, err)
+
+	v25, err := semver.New("0.25.0")
+	require.NoError(t, err)
+
+	v26, err := semver.New("0.26.0")
+	require.NoError(t, err)
+
+	matchFunc := mock.MatchedBy(func(db *dbutil.DB) bool {
+		return true
+	})
+
+	matchDB := func(db *dbutil.DB) interface{} {
+		return mock.MatchedBy(func(d *dbutil.DB) bool {
+			return d == db
+		})
+	}
+
+	resetedDB, closeRSDB := testutil.PrepareDB(t)
+	defer closeRSDB()
+
+	readOnlyDB, closeRODB := testutil.PrepareDBReadOnly(t)
+	defer closeRODB()
+
+	db, closeDB := testutil.PrepareDB(t)
+	defer closeDB()
+
+	tt := []struct {
+		name            string
+		config          dbCheckConfig
+		db              *dbutil.DB
+		dbVersion       *semver.Version
+		checkDBErr      error
+		setDBVersionErr error
+		retErr          error
+		// The database returned by checkAndUpdateDB, and the database that the version is set on
+		retDB        *dbutil.DB
+		checkDB      bool
+		resetDB      bool
+		setDBVersion bool
+	}{
+		{
+			name:         "db version nil - check db",
+			config:       dbCheckConfig{AppVersion: v26, DBCheckpointVersion: v25},
+			db:           db,
+			retDB:        db,
+			checkDB:      true,
+			setDBVersion: true,
+		},
+		{
+			name:         "db version nil - force verify - check db",
+			config:       dbCheckConfig{AppVersion: v26, DBCheckpointVersion: v25, ForceVerify: true},
+			db:           db,
+			retDB:        db,
+			checkDB:      true,
+			setDBVersion: true,
+		},
+		{
+			name:         "db version nil - reset corrupt db",
+			config:       dbCheckConfig{AppVersion: v26, DBCheckpointVersion: v25, ResetCorruptDB: true},
+			db:           db,
+			retDB:        resetedDB,
+			resetDB:      true,
+			setDBVersion: true,
+		},
+		{
+			name:         "db version nil - force verify - reset corrupt db",
+			config:       dbCheckConfig{AppVersion: v26, DBCheckpointVersion: v25, ForceVerify: true, ResetCorruptDB: true},
+			db:           db,
+			retDB:        resetedDB,
+			resetDB:      true,
+			setDBVersion: true,
+		},
+		{
+			name:         "db version nil - no checkpoint version - check db",
+			config:       dbCheckConfig{AppVersion: v26},
+			db:           db,
+			retDB:        db,
+			checkDB:      true,
+			setDBVersion: true,
+		},
+		{
+			name:    "db version nil - read only db - check db",
+			config:  dbCheckConfig{AppVersion: v26, DBCheckpointVersion: v25},
+			db:      readOnlyDB,
+			retDB:   readOnlyDB,
+			checkDB: true,
+		},
+		{
+			name:       "db version nil - check db - got err",
+			config:     dbCheckConfig{AppVersion: v26, DBCheckpointVersion: v25},
+			db:         db,
+			checkDBErr: errors.New("check db error"),
+			retErr:     errors.New("check db error"),
+			checkDB:    true,
+		},
+		{
+			name:            "db version nil - set db version - got err",
+			config:          dbCheckConfig{AppVersion: v26, DBCheckpointVersion: v25},
+			db:              db,
+			setDBVersionErr: errors.New("set db version error"),
+			retErr:          errors.New("set db version error"),
+			retDB:           db,
+			checkDB:         true,
+			setDBVersion:    true,
+		},
+		{
+			name:         "db version < check point - check db",
+			config:       dbCheckConfig{AppVersion: v26, DBCheckpointVersion: v25},
+			db:           db,
+			dbVersion:    v241,
+			retDB:        db,
+			checkDB:      true,
+			setDBVersion: true,
+		},
+		{
+			name:         "db version == check point - do nothing",
+			config:       dbCheckConfig{AppVersion: v26, DBCheckpointVersion: v25},
+			db:           db,
+			dbVersion:    v25,
+			retDB:        db,
+			setDBVersion: true,
+		},
+		{
+			name:         "db version == check point - force verify - check db",
+			config:       dbCheckConfig{AppVersion: v26, DBCheckpointVersion: v25, ForceVerify: true},
+			db:           db,
+			dbVersion:    v25,
+			retDB:        db,
+			checkDB:      true,
+			setDBVersion: true,
+		},
+		{
+			name:         "db version > check point - do nothing",
+			config:       dbCheckConfig{AppVersion: v26, DBCheckpointVersion: v25},
+			db:           db,
+			dbVersion:    v26,
+			retDB:        db,
+			setDBVersion: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := &mockDbCheckCorruptResetter{}
+			m.On("GetDBVersion", matchFunc).Return(tc.dbVersion, nil)
+			m.On("SetDBVersion", matchFunc, tc.config.AppVersion).Return(tc.setDBVersionErr)
+			m.On("MigrateDB", matchFunc, tc.config.AppVersion).Return(nil)
+			m.On("CheckDatabase", matchFunc, mock.Anything).Return(tc.checkDBErr)
+			m.On("ResetCorruptDB", matchFunc, mock.Anything).Return(resetedDB, nil)
+
+			dbAfter, err := checkAndUpdateDB(tc.db, tc.config, m, nil)
+			require.Equal(t, tc.retErr, err)
+			if err == nil {
+				require.Equal(t, tc.retDB, dbAfter)
+			}
+
+			m.AssertCalled(t, "GetDBVersion", matchDB(tc.db))
+
+			if tc.checkDB {
+				m.AssertCalled(t, "CheckDatabase", matchDB(tc.db), mock.Anything)
+			} else {
+				m.AssertNotCalled(t, "CheckDatabase", matchFunc, mock.Anything)
+			}
+
+			if tc.resetDB {
+				m.AssertCalled(t, "ResetCorruptDB", matchDB(tc.db), mock.Anything)
+			} else {
+				m.AssertNotCalled(t, "ResetCorruptDB", matchFunc, mock.Anything)
+			}
+
+			if tc.setDBVersion {
+				m.AssertCalled(t, "SetDBVersion", matchDB(tc.retDB), tc.config.AppVersion)
+			} else {
+				m.AssertNotCalled(t, "SetDBVersion", matchFunc, tc.config.AppVersion)
+			}
+		})
+	}
+}
+
+func TestCheckAndUpdateDBMaxVerifyDuration(t *testing.T) {
+	v25, err := semver.New("0.25.0")
+	require.NoError(t, err)
+
+	v26, err := semver.New("0.26.0")
+	require.NoError(t, err)
+
+	matchFunc := mock.MatchedBy(func(db *dbutil.DB) bool {
+		return true
+	})
+
+	resetedDB, closeRSDB := testutil.PrepareDB(t)
+	defer closeRSDB()
+
+	db, closeDB := testutil.PrepareDB(t)
+	defer closeDB()
+
+	// waitQuit blocks the check until its quit channel is closed, like visor.CheckDatabase does when it is stopped
+	waitQuit := func(args mock.Arguments) {
+		<-args.Get(1).(chan struct{})
+	}
+
+	tt := []struct {
+		name      string
+		config    dbCheckConfig
+		blocks    bool
+		interrupt bool
+		retErr    error
+	}{
+		{
+			name:   "check db - timeout",
+			config: dbCheckConfig{AppVersion: v26, DBCheckpointVersion: v25, MaxVerifyDuration: time.Millisecond * 10},
+			blocks: true,
+			retErr: ErrVerifyTimeout,
+		},
+		{
+			name:   "reset corrupt db - timeout",
+			config: dbCheckConfig{AppVersion: v26, DBCheckpointVersion: v25, MaxVerifyDuration: time.Millisecond * 10, ResetCorruptDB: true},
+			blocks: true,
+			retErr: ErrVerifyTimeout,
+		},
+		{
+			name:      "check db - interrupted before the timeout",
+			config:    dbCheckConfig{AppVersion: v26, DBCheckpointVersion: v25, MaxVerifyDuration: time.Hour},
+			blocks:    true,
+			interrupt: true,
+			retErr:    visor.ErrVerifyStopped,
+		},
+		{
+			name:   "check db - completes before the timeout",
+			config: dbCheckConfig{AppVersion: v26, DBCheckpointVersion: v25, MaxVerifyDuration: time.Hour},
+		},
+		{
+			name:   "reset corrupt db - completes before the timeout",
+			config: dbCheckConfig{AppVersion: v26, DBCheckpointVersion: v25, MaxVerifyDuration: time.Hour, ResetCorruptDB: true},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			m := &mockDbCheckCorruptResetter{}
+			m.On("GetDBVersion", matchFunc).Return(nil, nil)
+			m.On("SetDBVersion", matchFunc, v26).Return(nil)
+			m.On("MigrateDB", matchFunc, v26).Return(nil)
+
+			if tc.blocks {
+				m.On("CheckDatabase", matchFunc, mock.Anything).Run(waitQuit).Return(visor.ErrVerifyStopped)
+				m.On("ResetCorruptDB", matchFunc, mock.Anything).Run(waitQuit).Return(nil, visor.ErrVerifyStopped)
+			} else {
+				m.On("CheckDatabase", matchFunc, mock.Anything).Return(nil)
+				m.On("ResetCorruptDB", matchFunc, mock.Anything).Return(resetedDB, nil)
+			}
+
+			quit := make(chan struct{})
+			if tc.interrupt {
+				close(quit)
+			}
+
+			dbAfter, err := checkAndUpdateDB(db, tc.config, m, quit)
+			require.Equal(t, tc.retErr, err)
+
+			if tc.config.ResetCorruptDB {
+				m.AssertCalled(t, "ResetCorruptDB", matchFunc, mock.Anything)
+			} else {
+				m.AssertCalled(t, "CheckDatabase", matchFunc, mock.Anything)
+			}
+
+			if err != nil {
+				m.AssertNotCalled(t, "SetDBVersion", matchFunc, v26)
+				return
+			}
+
+			m.AssertCalled(t, "SetDBVersion", matchFunc, v26)
+			if tc.config.ResetCorruptDB {
+				require.Equal(t, resetedDB, dbAfter)
+			} else {
+				require.Equal(t, db, dbAfter)
+			}
+		})
+	}
+}
+
+func TestCheckAndUpdateDBMigrateDB(t *testing.T) {
+	v25, err := semver.New("0.25.0")
+	require.NoError(t, err)
+
+	v26, err := semver.New("0.26.0")
+	require.NoError(t, err)
+
+	matchFunc := mock.MatchedBy(func(db *dbutil.DB) bool {
+		return true
+	})
+
+	db, closeDB := testutil.PrepareDB(t)
+	defer closeDB()
+
+	config := dbCheckConfig{AppVersion: v26, DBCheckpointVersion: v26}
+
+	// The migrations run before the check, and a failed migration aborts the check and the version update
+	migrateErr := errors.New("migration failed")
+	m := &mockDbCheckCorruptResetter{}
+	m.On("GetDBVersion", matchFunc).Return(v25, nil)
+	m.On("MigrateDB", matchFunc, v26).Return(migrateErr)
+	m.On("CheckDatabase", matchFunc, mock.Anything).Return(nil)
+	m.On("SetDBVersion", matchFunc, v26).Return(nil)
+
+	_, err = checkAndUpdateDB(db, config, m, nil)
+	require.Equal(t, migrateErr, err)
+	m.AssertNotCalled(t, "CheckDatabase", matchFunc, mock.Anything)
+	m.AssertNotCalled(t, "SetDBVersion", matchFunc, v26)
+
+	var calls []string
+	m = &mockDbCheckCorruptResetter{}
+	m.On("GetDBVersion", matchFunc).Return(v25, nil)
+	m.On("MigrateDB", matchFunc, v26).Return(nil).Run(func(mock.Arguments) {
+		calls = append(calls, "MigrateDB")
+	})
+	m.On("CheckDatabase", matchFunc, mock.Anything).Return(nil).Run(func(mock.Arguments) {
+		calls = append(calls, "CheckDatabase")
+	})
+	m.On("SetDBVersion", matchFunc, v26).Return(nil).Run(func(mock.Arguments) {
+		calls = append(calls, "SetDBVersion")
+	})
+
+	dbAfter, err := checkAndUpdateDB(db, config, m, nil)
+	require.NoError(t, err)
+	require.Equal(t, db, dbAfter)
+	require.Equal(t, []string{"MigrateDB", "CheckDatabase", "SetDBVersion"}, calls)
+}
+
+func TestCheckAndUpdateDBResetCorruptDBDryRun(t *testing.T) {
+	v26, err := semver.New("0.26.0")
+	require.NoError(t, err)
+
+	matchFunc := mock.MatchedBy(func(db *dbutil.DB) bool {
+		return true
+	})
+
+	db, closeDB := testutil.PrepareDB(t)
+	defer closeDB()
+
+	config := dbCheckConfig{
+		AppVersion:           v26,
+		DBCheckpointVersion:  v26,
+		ResetCorruptDB:       true,
+		ResetCorruptDBDryRun: true,
+	}
+
+	newMock := func(descs []visor.CorruptionDescription, err error) *mockDbCheckCorruptResetter {
+		m := &mockDbCheckCorruptResetter{}
+		m.On("GetDBVersion", matchFunc).Return(nil, nil)
+		m.On("MigrateDB", matchFunc, v26).Return(nil)
+		m.On("DescribeCorruption", matchFunc, mock.Anything).Return(descs, err)
+		m.On("SetDBVersion", matchFunc, v26).Return(nil)
+		return m
+	}
+
+	// A corrupted database is not reset and its version is not updated
+	m := newMock([]visor.CorruptionDescription{
+		{
+			Bucket: "block_sigs",
+			Err:    errors.New("Signature not found"),
+		},
+	}, nil)
+	_, err = checkAndUpdateDB(db, config, m, nil)
+	require.Equal(t, ErrCorruptDBDryRun, err)
+	m.AssertNotCalled(t, "ResetCorruptDB", matchFunc, mock.Anything)
+	m.AssertNotCalled(t, "SetDBVersion", matchFunc, v26)
+
+	// Errors other than corruption are returned
+	describeErr := errors.New("describe failed")
+	m = newMock(nil, describeErr)
+	_, err = checkAndUpdateDB(db, config, m, nil)
+	require.Equal(t, describeErr, err)
+	m.AssertNotCalled(t, "ResetCorruptDB", matchFunc, mock.Anything)
+
+	// A database that is not corrupted is used as is
+	m = newMock(nil, nil)
+	dbAfter, err := checkAndUpdateDB(db, config, m, nil)
+	require.NoError(t, err)
+	require.Equal(t, db, dbAfter)
+	m.AssertNotCalled(t, "ResetCorruptDB", matchFunc, mock.Anything)
+	m.AssertCalled(t, "SetDBVersion", matchFunc, v26)
+}
+
+func TestCheckAndUpdateDBRepairDB(t *testing.T) {
+	v26, err := semver.New("0.26.0")
+	require.NoError(t, err)
+
+	matchFunc := mock.MatchedBy(func(db *dbutil.DB) bool {
+		return true
+	})
+
+	db, closeDB := testutil.PrepareDB(t)
+	defer closeDB()
+
+	repairedDB, closeRepairedDB := testutil.PrepareDB(t)
+	defer closeRepairedDB()
+
+	config := dbCheckConfig{
+		AppVersion:          v26,
+		DBCheckpointVersion: v26,
+		ResetCorruptDB:      true,
+		RepairDB:            true,
+	}
+
+	newMock := func(newDB *dbutil.DB, err error) *mockDbCheckCorruptResetter {
+		m := &mockDbCheckCorruptResetter{}
+		m.On("GetDBVersion", matchFunc).Return(nil, nil)
+		m.On("MigrateDB", matchFunc, v26).Return(nil)
+		m.On("RepairDB", matchFunc, mock.Anything).Return(newDB, err)
+		m.On("SetDBVersion", matchFunc, v26).Return(nil)
+		return m
+	}
+
+	// The repaired database is used and its version is updated
+	m := newMock(repairedDB, nil)
+	dbAfter, err := checkAndUpdateDB(db, config, m, nil)
+	require.NoError(t, err)
+	require.Equal(t, repairedDB, dbAfter)
+	m.AssertNotCalled(t, "ResetCorruptDB", matchFunc, mock.Anything)
+	m.AssertCalled(t, "SetDBVersion", matchFunc, v26)
+
+	// Errors of the repair are returned
+	repairErr := errors.New("repair failed")
+	m = newMock(nil, repairErr)
+	_, err = checkAndUpdateDB(db, config, m, nil)
+	require.Equal(t, repairErr, err)
+	m.AssertNotCalled(t, "ResetCorruptDB", matchFunc, mock.Anything)
+	m.AssertNotCalled(t, "SetDBVersion", matchFunc, v26)
+}
+
+func TestVersionHelpers(t *testing.T) {
+	v25, err := semver.New("0.25.0")
+	require.NoError(t, err)
+
+	v26, err := semver.New("0.26.0")
+	require.NoError(t, err)
+
+	v26b, err := semver.New("0.26.0")
+	require.NoError(t, err)
+
+	tt := []struct {
+		name      string
+		a         *semver.Version
+		b         *semver.Version
+		compare   int
+		olderThan bool
+		newerThan bool
+		atLeast   bool
+	}{
+		{
+			name:    "both nil",
+			atLeast: true,
+		},
+		{
+			name:      "a nil",
+			b:         v25,
+			compare:   -1,
+			olderThan: true,
+		},
+		{
+			name:      "b nil",
+			a:         v25,
+			compare:   1,
+			newerThan: true,
+			atLeast:   true,
+		},
+		{
+			name:      "a < b",
+			a:         v25,
+			b:         v26,
+			compare:   -1,
+			olderThan: true,
+		},
+		{
+			name:      "a > b",
+			a:         v26,
+			b:         v25,
+			compare:   1,
+			newerThan: true,
+			atLeast:   true,
+		},
+		{
+			name:    "a == b",
+			a:       v26,
+			b:       v26b,
+			atLeast: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.compare, compareVersions(tc.a, tc.b))
+			require.Equal(t, tc.olderThan, dbVersionOlderThan(tc.a, tc.b))
+			require.Equal(t, tc.newerThan, dbVersionNewerThan(tc.a, tc.b))
+			require.Equal(t, tc.atLeast, versionAtLeast(tc.a, tc.b))
+		})
+	}
+}
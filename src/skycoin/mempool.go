@@ -0,0 +1,46 @@
+package skycoin
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+
+	"github.com/skycoin/skycoin/src/util/file"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+// dumpMempool writes the unconfirmed transactions to path, so that they can be restored
+// by restoreMempool when the node is started again. Returns the number of transactions written.
+func dumpMempool(v *visor.Visor, path string) (int, error) {
+	var buf bytes.Buffer
+	n, err := v.DumpUnconfirmed(&buf)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := file.SaveBinaryAtomic(path, buf.Bytes(), 0600); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// restoreMempool adds the transactions dumped to path by dumpMempool back to the unconfirmed pool,
+// then removes the file so that a later startup doesn't restore them again.
+// Returns the number of transactions restored and skipped. A missing file restores nothing.
+func restoreMempool(v *visor.Visor, path string) (int, int, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, 0, nil
+		}
+		return 0, 0, err
+	}
+
+	restored, invalid, err := v.RestoreUnconfirmed(bytes.NewReader(b))
+	if err != nil {
+		return restored, invalid, err
+	}
+
+	return restored, invalid, os.Remove(path)
+}
@@ -7,6 +7,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	semver "github.com/blang/semver"
+
+	visor "github.com/skycoin/skycoin/src/visor"
 )
 
 // mockDbCheckCorruptResetter is an autogenerated mock type for the dbCheckCorruptResetter type
@@ -14,13 +16,13 @@ type mockDbCheckCorruptResetter struct {
 	mock.Mock
 }
 
-// CheckDatabase provides a mock function with given fields: db
-func (_m *mockDbCheckCorruptResetter) CheckDatabase(db *dbutil.DB) error {
-	ret := _m.Called(db)
+// CheckDatabase provides a mock function with given fields: db, quit
+func (_m *mockDbCheckCorruptResetter) CheckDatabase(db *dbutil.DB, quit chan struct{}) error {
+	ret := _m.Called(db, quit)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*dbutil.DB) error); ok {
-		r0 = rf(db)
+	if rf, ok := ret.Get(0).(func(*dbutil.DB, chan struct{}) error); ok {
+		r0 = rf(db, quit)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -28,6 +30,29 @@ func (_m *mockDbCheckCorruptResetter) CheckDatabase(db *dbutil.DB) error {
 	return r0
 }
 
+// DescribeCorruption provides a mock function with given fields: db, quit
+func (_m *mockDbCheckCorruptResetter) DescribeCorruption(db *dbutil.DB, quit chan struct{}) ([]visor.CorruptionDescription, error) {
+	ret := _m.Called(db, quit)
+
+	var r0 []visor.CorruptionDescription
+	if rf, ok := ret.Get(0).(func(*dbutil.DB, chan struct{}) []visor.CorruptionDescription); ok {
+		r0 = rf(db, quit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]visor.CorruptionDescription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.DB, chan struct{}) error); ok {
+		r1 = rf(db, quit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDBVersion provides a mock function with given fields: db
 func (_m *mockDbCheckCorruptResetter) GetDBVersion(db *dbutil.DB) (*semver.Version, error) {
 	ret := _m.Called(db)
@@ -51,13 +76,27 @@ func (_m *mockDbCheckCorruptResetter) GetDBVersion(db *dbutil.DB) (*semver.Versi
 	return r0, r1
 }
 
-// ResetCorruptDB provides a mock function with given fields: db
-func (_m *mockDbCheckCorruptResetter) ResetCorruptDB(db *dbutil.DB) (*dbutil.DB, error) {
-	ret := _m.Called(db)
+// MigrateDB provides a mock function with given fields: db, appVersion
+func (_m *mockDbCheckCorruptResetter) MigrateDB(db *dbutil.DB, appVersion *semver.Version) error {
+	ret := _m.Called(db, appVersion)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*dbutil.DB, *semver.Version) error); ok {
+		r0 = rf(db, appVersion)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RepairDB provides a mock function with given fields: db, quit
+func (_m *mockDbCheckCorruptResetter) RepairDB(db *dbutil.DB, quit chan struct{}) (*dbutil.DB, error) {
+	ret := _m.Called(db, quit)
 
 	var r0 *dbutil.DB
-	if rf, ok := ret.Get(0).(func(*dbutil.DB) *dbutil.DB); ok {
-		r0 = rf(db)
+	if rf, ok := ret.Get(0).(func(*dbutil.DB, chan struct{}) *dbutil.DB); ok {
+		r0 = rf(db, quit)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*dbutil.DB)
@@ -65,8 +104,31 @@ func (_m *mockDbCheckCorruptResetter) ResetCorruptDB(db *dbutil.DB) (*dbutil.DB,
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*dbutil.DB) error); ok {
-		r1 = rf(db)
+	if rf, ok := ret.Get(1).(func(*dbutil.DB, chan struct{}) error); ok {
+		r1 = rf(db, quit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResetCorruptDB provides a mock function with given fields: db, quit
+func (_m *mockDbCheckCorruptResetter) ResetCorruptDB(db *dbutil.DB, quit chan struct{}) (*dbutil.DB, error) {
+	ret := _m.Called(db, quit)
+
+	var r0 *dbutil.DB
+	if rf, ok := ret.Get(0).(func(*dbutil.DB, chan struct{}) *dbutil.DB); ok {
+		r0 = rf(db, quit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dbutil.DB)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.DB, chan struct{}) error); ok {
+		r1 = rf(db, quit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,177 @@
+package skycoin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/util/logging"
+)
+
+// reloadableConfigFields are the NodeConfig fields that are applied to the running node
+// when the config file is reloaded on SIGHUP. Changes to any other field, such as the
+// listen addresses or the DB path, require a restart and are ignored with a warning.
+var reloadableConfigFields = []string{
+	"LogLevel",
+	"MaxConnections",
+	"MaxOutgoingConnections",
+	"MaxIncomingConnections",
+	"MaxDefaultPeerOutgoingConnections",
+	"APIRouteRateLimits",
+}
+
+// loadConfigFile decodes a JSON config file on top of the NodeConfig's existing values.
+// Keys are NodeConfig field names, matched case-insensitively.
+func loadConfigFile(path string, c *NodeConfig) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Read config file %s failed: %v", path, err)
+	}
+
+	if err := json.Unmarshal(b, c); err != nil {
+		return fmt.Errorf("Invalid config file %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// reloadNodeConfig applies the reloadable fields of a JSON config file on top of the current NodeConfig.
+// It returns the new NodeConfig and the names of changed fields that were ignored because they
+// cannot be changed without a restart
+func reloadNodeConfig(current NodeConfig, data []byte) (NodeConfig, []string, error) {
+	loaded := current
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return NodeConfig{}, nil, err
+	}
+
+	reloadable := make(map[string]struct{}, len(reloadableConfigFields))
+	for _, f := range reloadableConfigFields {
+		reloadable[f] = struct{}{}
+	}
+
+	updated := current
+	updatedV := reflect.ValueOf(&updated).Elem()
+	currentV := reflect.ValueOf(current)
+	loadedV := reflect.ValueOf(loaded)
+
+	var ignored []string
+	t := currentV.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+
+		if reflect.DeepEqual(currentV.Field(i).Interface(), loadedV.Field(i).Interface()) {
+			continue
+		}
+
+		if _, ok := reloadable[f.Name]; !ok {
+			ignored = append(ignored, f.Name)
+			continue
+		}
+
+		updatedV.Field(i).Set(loadedV.Field(i))
+	}
+
+	if _, err := logging.LevelFromString(updated.LogLevel); err != nil {
+		return NodeConfig{}, nil, fmt.Errorf("Invalid LogLevel: %v", err)
+	}
+
+	if err := validateConnectionLimits(updated); err != nil {
+		return NodeConfig{}, nil, err
+	}
+
+	limits, err := buildAPIRouteRateLimits(updated.APIRouteRateLimits)
+	if err != nil {
+		return NodeConfig{}, nil, err
+	}
+	updated.apiRouteRateLimits = limits
+
+	sort.Strings(ignored)
+
+	return updated, ignored, nil
+}
+
+// reloadConfig rereads the config file and applies the reloadable values to the running subsystems.
+// All values are validated before any is applied, so an invalid config file leaves the node unchanged
+func (c *Coin) reloadConfig(d *daemon.Daemon) error {
+	c.configLock.Lock()
+	current := c.config.Node
+	c.configLock.Unlock()
+
+	c.logger.Infof("Reloading config file %s", current.ConfigFile)
+
+	b, err := ioutil.ReadFile(current.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	nc, ignored, err := reloadNodeConfig(current, b)
+	if err != nil {
+		return err
+	}
+
+	if len(ignored) > 0 {
+		c.logger.Warningf("Config values cannot be changed without a restart, ignoring: %s", strings.Join(ignored, ", "))
+	}
+
+	logLevel, err := logging.LevelFromString(nc.LogLevel)
+	if err != nil {
+		return err
+	}
+
+	// The routes are only registered by the API servers, so the route rate limits are checked against the limiter
+	routeLimitsChanged := nc.APIRouteRateLimits != current.APIRouteRateLimits && c.apiRouteRateLimiter != nil
+	if routeLimitsChanged {
+		if err := c.apiRouteRateLimiter.CheckLimits(nc.apiRouteRateLimits); err != nil {
+			return err
+		}
+	}
+
+	// The connection limits are applied first, they can still fail if the daemon is shutting down
+	if err := d.SetConnectionLimits(daemon.ConnectionLimits{
+		MaxConnections:                    nc.MaxConnections,
+		MaxOutgoingConnections:            nc.MaxOutgoingConnections,
+		MaxIncomingConnections:            nc.MaxIncomingConnections,
+		MaxDefaultPeerOutgoingConnections: nc.MaxDefaultPeerOutgoingConnections,
+	}); err != nil {
+		return err
+	}
+
+	if routeLimitsChanged {
+		if err := c.apiRouteRateLimiter.SetLimits(nc.apiRouteRateLimits); err != nil {
+			return err
+		}
+		c.logger.Infof("API route rate limits changed to %q", nc.APIRouteRateLimits)
+	}
+
+	if nc.LogLevel != current.LogLevel {
+		logging.SetLevel(logLevel)
+		c.logger.Infof("Log level changed to %s", nc.LogLevel)
+	}
+
+	// Only the reloadable fields are written, the other fields are read without the lock
+	c.configLock.Lock()
+	setReloadableConfigFields(&c.config.Node, nc)
+	c.configLock.Unlock()
+
+	c.logger.Info("Config reloaded")
+
+	return nil
+}
+
+// setReloadableConfigFields copies the reloadable fields of src to dst
+func setReloadableConfigFields(dst *NodeConfig, src NodeConfig) {
+	dstV := reflect.ValueOf(dst).Elem()
+	srcV := reflect.ValueOf(src)
+	for _, f := range reloadableConfigFields {
+		dstV.FieldByName(f).Set(srcV.FieldByName(f))
+	}
+	dst.apiRouteRateLimits = src.apiRouteRateLimits
+}
@@ -0,0 +1,146 @@
+package skycoin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadNodeConfig(t *testing.T) {
+	current := NodeConfig{
+		LogLevel:                          "INFO",
+		Port:                              6000,
+		DBPath:                            "/tmp/data.db",
+		MaxConnections:                    128,
+		MaxOutgoingConnections:            8,
+		MaxIncomingConnections:            120,
+		MaxDefaultPeerOutgoingConnections: 2,
+	}
+
+	cases := []struct {
+		name    string
+		data    string
+		expect  NodeConfig
+		ignored []string
+		err     string
+	}{
+		{
+			name:   "no changes",
+			data:   `{}`,
+			expect: current,
+		},
+		{
+			name: "reloadable changes",
+			data: `{"LogLevel": "debug", "MaxConnections": 64, "MaxIncomingConnections": 50, "maxoutgoingconnections": 10, "MaxDefaultPeerOutgoingConnections": 1}`,
+			expect: NodeConfig{
+				LogLevel:                          "debug",
+				Port:                              6000,
+				DBPath:                            "/tmp/data.db",
+				MaxConnections:                    64,
+				MaxOutgoingConnections:            10,
+				MaxIncomingConnections:            50,
+				MaxDefaultPeerOutgoingConnections: 1,
+			},
+		},
+		{
+			name: "non-reloadable changes ignored",
+			data: `{"LogLevel": "warn", "Port": 7000, "DBPath": "/tmp/other.db", "MaxConnections": 128}`,
+			expect: NodeConfig{
+				LogLevel:                          "warn",
+				Port:                              6000,
+				DBPath:                            "/tmp/data.db",
+				MaxConnections:                    128,
+				MaxOutgoingConnections:            8,
+				MaxIncomingConnections:            120,
+				MaxDefaultPeerOutgoingConnections: 2,
+			},
+			ignored: []string{"DBPath", "Port"},
+		},
+		{
+			name: "invalid log level",
+			data: `{"LogLevel": "foo"}`,
+			err:  "Invalid LogLevel: could not convert string to log level",
+		},
+		{
+			name: "invalid connection limits",
+			data: `{"MaxConnections": 100}`,
+			err:  "-max-connections must be >= -max-outgoing-connections + -max-incoming-connections",
+		},
+		{
+			name: "route rate limits",
+			data: `{"APIRouteRateLimits": "/api/v2/blockchain/richlist=6"}`,
+			expect: NodeConfig{
+				LogLevel:                          "INFO",
+				Port:                              6000,
+				DBPath:                            "/tmp/data.db",
+				MaxConnections:                    128,
+				MaxOutgoingConnections:            8,
+				MaxIncomingConnections:            120,
+				MaxDefaultPeerOutgoingConnections: 2,
+				APIRouteRateLimits:                "/api/v2/blockchain/richlist=6",
+				apiRouteRateLimits: map[string]int{
+					"/api/v2/blockchain/richlist": 6,
+				},
+			},
+		},
+		{
+			name: "invalid route rate limits",
+			data: `{"APIRouteRateLimits": "/api/v2/blockchain/richlist=0"}`,
+			err:  `-api-route-rate-limits: invalid limit "0" of route "/api/v2/blockchain/richlist", must be a positive number of requests per minute`,
+		},
+		{
+			name: "invalid json",
+			data: `{"MaxConnections": "foo"}`,
+			err:  "json: cannot unmarshal string into Go struct field NodeConfig.MaxConnections of type int",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, ignored, err := reloadNodeConfig(current, []byte(tc.data))
+			if tc.err != "" {
+				require.Error(t, err)
+				require.Equal(t, tc.err, err.Error())
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, c)
+			require.Equal(t, tc.ignored, ignored)
+		})
+	}
+}
+
+func TestSetReloadableConfigFields(t *testing.T) {
+	c := NodeConfig{
+		LogLevel:       "INFO",
+		Port:           6000,
+		DBPath:         "/tmp/data.db",
+		MaxConnections: 128,
+		NoMempoolDump:  true,
+	}
+
+	setReloadableConfigFields(&c, NodeConfig{
+		LogLevel:           "debug",
+		Port:               6001,
+		DBPath:             "/tmp/other.db",
+		MaxConnections:     64,
+		APIRouteRateLimits: "/api/v2/blockchain/richlist=6",
+		apiRouteRateLimits: map[string]int{
+			"/api/v2/blockchain/richlist": 6,
+		},
+	})
+
+	// Only the reloadable fields are changed
+	require.Equal(t, NodeConfig{
+		LogLevel:           "debug",
+		Port:               6000,
+		DBPath:             "/tmp/data.db",
+		MaxConnections:     64,
+		NoMempoolDump:      true,
+		APIRouteRateLimits: "/api/v2/blockchain/richlist=6",
+		apiRouteRateLimits: map[string]int{
+			"/api/v2/blockchain/richlist": 6,
+		},
+	}, c)
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -22,6 +23,7 @@ import (
 	"github.com/skycoin/skycoin/src/api"
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/consensus"
 	"github.com/skycoin/skycoin/src/daemon"
 	"github.com/skycoin/skycoin/src/kvstorage"
 	"github.com/skycoin/skycoin/src/params"
@@ -47,7 +49,16 @@ var (
 // Coin represents a fiber coin instance
 type Coin struct {
 	config Config
-	logger *logging.Logger
+	// Guards the reloadable fields of config.Node, which are changed when the config file is reloaded
+	configLock sync.Mutex
+	logger     *logging.Logger
+
+	// Duration of the database check at startup, exposed on /metrics
+	dbCheckDuration time.Duration
+	// API debug log file, nil if disabled
+	apiDebugLog *os.File
+	// Route rate limits shared by all API listeners
+	apiRouteRateLimiter *api.RouteRateLimiter
 }
 
 // Run starts the node
@@ -58,7 +69,7 @@ func (c *Coin) Run() error {
 	var d *daemon.Daemon
 	var s *kvstorage.Manager
 	var gw *api.Gateway
-	var webInterface *api.Server
+	var webInterfaces []*api.Server
 	var retErr error
 	errC := make(chan error, 10)
 
@@ -92,12 +103,22 @@ func (c *Coin) Run() error {
 		}
 	}
 
-	var fullAddress string
-	scheme := "http"
-	if c.config.Node.WebInterfaceHTTPS {
-		scheme = "https"
+	if c.config.Node.APIDebugLog {
+		var err error
+		c.apiDebugLog, err = c.initAPIDebugLogFile()
+		if err != nil {
+			c.logger.Error(err)
+			return err
+		}
+
+		defer func() {
+			if err := c.apiDebugLog.Close(); err != nil {
+				c.logger.WithError(err).Error("Failed to close API debug log file")
+			}
+		}()
 	}
-	host := fmt.Sprintf("%s:%d", c.config.Node.WebInterfaceAddr, c.config.Node.WebInterfacePort)
+
+	var fullAddress string
 
 	if c.config.Node.ProfileCPU {
 		f, err := os.Create(c.config.Node.ProfileCPUFile)
@@ -124,9 +145,12 @@ func (c *Coin) Run() error {
 	var wg sync.WaitGroup
 
 	quit := make(chan struct{})
+	sigC := make(chan os.Signal, 1)
 
-	// Catch SIGINT (CTRL-C) (closes the quit channel)
-	go apputil.CatchInterrupt(quit)
+	// Catch SIGINT (CTRL-C) or SIGTERM (closes the quit channel, then sends the signal to sigC)
+	go func() {
+		sigC <- apputil.CatchInterrupt(quit)
+	}()
 
 	// Catch SIGUSR1 (prints runtime stack to stdout)
 	go apputil.CatchDebug()
@@ -173,22 +197,39 @@ func (c *Coin) Run() error {
 	}()
 
 	cf := dbCheckConfig{
-		ForceVerify:         c.config.Node.VerifyDB,
-		ResetCorruptDB:      c.config.Node.ResetCorruptDB,
-		AppVersion:          appVersion,
-		DBCheckpointVersion: &dbVerifyCheckpointVersionParsed,
+		ForceVerify:          c.config.Node.VerifyDB,
+		ResetCorruptDB:       c.config.Node.ResetCorruptDB,
+		ResetCorruptDBDryRun: c.config.Node.ResetCorruptDBDryRun,
+		RepairDB:             c.config.Node.RepairDB,
+		AppVersion:           appVersion,
+		DBCheckpointVersion:  &dbVerifyCheckpointVersionParsed,
+		MaxVerifyDuration:    c.config.Node.MaxVerifyDuration,
+	}
+
+	var checkpoints *visor.Checkpoints
+	if !c.config.Node.NoCheckpoints {
+		checkpoints, err = loadCheckpoints(c.config.Node.CheckpointsFile, c.config.Node.blockchainPubkey, c.logger)
+		if err != nil {
+			c.logger.WithError(err).Error("loadCheckpoints failed")
+			return err
+		}
 	}
 
 	dv := dbVerify{
 		blockchainPubkey: c.config.Node.blockchainPubkey,
+		checkpoints:      checkpoints,
 		logger:           c.logger,
-		quit:             quit,
 	}
 
-	db, err = checkAndUpdateDB(db, cf, &dv)
+	dbCheckStart := time.Now()
+	db, err = checkAndUpdateDB(db, cf, &dv, quit)
 	if err != nil {
+		if err == ErrVerifyTimeout {
+			c.logger.Errorf("Database verification did not complete within -max-verify-duration=%s. Run the node with -verify-db -max-verify-duration=0 to verify the database without a time limit", cf.MaxVerifyDuration)
+		}
 		return err
 	}
+	c.dbCheckDuration = time.Since(dbCheckStart)
 
 	c.logger.Infof("Coinhour burn factor for user transactions is %d", params.UserVerifyTxn.BurnFactor)
 	c.logger.Infof("Max transaction size for user transactions is %d", params.UserVerifyTxn.MaxTransactionSize)
@@ -226,14 +267,37 @@ func (c *Coin) Run() error {
 	gw = api.NewGateway(d, v, w, s)
 
 	if c.config.Node.WebInterface {
-		webInterface, err = c.createGUI(gw, host)
-		if err != nil {
-			c.logger.WithError(err).Error("c.createGUI failed")
-			return err
-		}
+		c.apiRouteRateLimiter = api.NewRouteRateLimiter(c.config.Node.apiRouteRateLimits)
+		for _, l := range c.config.Node.apiListeners {
+			webInterface, err := c.createGUI(gw, l)
+			if err != nil {
+				c.logger.WithError(err).Error("c.createGUI failed")
+				// Release the listeners that were already opened, none have been served yet
+				for _, webInterface := range webInterfaces {
+					if err := webInterface.Close(); err != nil {
+						c.logger.WithError(err).Warningf("Closing web interface %s failed", webInterface.Addr())
+					}
+				}
+				return err
+			}
+			webInterfaces = append(webInterfaces, webInterface)
 
-		fullAddress = fmt.Sprintf("%s://%s", scheme, webInterface.Addr())
-		c.logger.Critical().Infof("Full address: %s", fullAddress)
+			scheme := "http"
+			if l.TLS != nil {
+				scheme = "https"
+			}
+			address := fmt.Sprintf("%s://%s", scheme, webInterface.Addr())
+
+			if l.ReadOnly {
+				c.logger.Critical().Infof("Full address (read-only): %s", address)
+			} else {
+				c.logger.Critical().Infof("Full address: %s", address)
+				// The browser is launched with the first listener that can serve the wallet
+				if fullAddress == "" {
+					fullAddress = address
+				}
+			}
+		}
 	}
 
 	c.logger.Info("visor.Init")
@@ -242,6 +306,16 @@ func (c *Coin) Run() error {
 		return err
 	}
 
+	if !c.config.Node.NoMempoolDump && !db.IsReadOnly() {
+		c.logger.Infof("Restoring unconfirmed transactions from %s", c.config.Node.MempoolFile)
+		// A damaged dump file doesn't prevent the node from starting, the transactions can be rebroadcast
+		restored, invalid, err := restoreMempool(v, c.config.Node.MempoolFile)
+		if err != nil {
+			c.logger.WithError(err).Error("restoreMempool failed")
+		}
+		c.logger.Infof("Restored %d unconfirmed transactions, skipped %d invalid transactions", restored, invalid)
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -256,19 +330,24 @@ func (c *Coin) Run() error {
 	if c.config.Node.WebInterface {
 		cancelLaunchBrowser := make(chan struct{})
 
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-
-			c.logger.Info("webInterface.Serve")
-			if err := webInterface.Serve(); err != nil {
-				close(cancelLaunchBrowser)
-				c.logger.WithError(err).Error("webInterface.Serve failed")
-				errC <- err
-			}
-		}()
+		var cancelLaunchBrowserOnce sync.Once
+		for _, webInterface := range webInterfaces {
+			wg.Add(1)
+			go func(webInterface *api.Server) {
+				defer wg.Done()
+
+				c.logger.Info("webInterface.Serve")
+				if err := webInterface.Serve(); err != nil {
+					cancelLaunchBrowserOnce.Do(func() {
+						close(cancelLaunchBrowser)
+					})
+					c.logger.WithError(err).Error("webInterface.Serve failed")
+					errC <- err
+				}
+			}(webInterface)
+		}
 
-		if c.config.Node.LaunchBrowser {
+		if c.config.Node.LaunchBrowser && fullAddress != "" {
 			go func() {
 				select {
 				case <-cancelLaunchBrowser:
@@ -285,16 +364,42 @@ func (c *Coin) Run() error {
 		}
 	}
 
+	if c.config.Node.ConfigFile != "" {
+		// Catch SIGHUP (reloads the config file)
+		reload := make(chan struct{}, 1)
+		go apputil.CatchHangup(reload)
+
+		reloadDone := make(chan struct{})
+		defer close(reloadDone)
+		go func() {
+			for {
+				select {
+				case <-quit:
+					return
+				case <-reloadDone:
+					return
+				case <-reload:
+					if err := c.reloadConfig(d); err != nil {
+						c.logger.WithError(err).Error("Reload config failed, keeping the current config")
+					}
+				}
+			}
+		}()
+	}
+
+	var shutdownReason string
 	select {
 	case <-quit:
+		shutdownReason = fmt.Sprintf("signal: %v", <-sigC)
 	case retErr = <-errC:
 		c.logger.WithError(err).Error("Received error from errC (something prior has failed)")
+		shutdownReason = fmt.Sprintf("error: %v", retErr)
 	}
 
 	c.logger.Info("Shutting down...")
 
-	if webInterface != nil {
-		c.logger.Info("Closing web interface")
+	for _, webInterface := range webInterfaces {
+		c.logger.Infof("Closing web interface %s", webInterface.Addr())
 		webInterface.Shutdown()
 	}
 
@@ -304,6 +409,28 @@ func (c *Coin) Run() error {
 	c.logger.Info("Waiting for goroutines to finish")
 	wg.Wait()
 
+	c.logger.Info("Stopping database corruption check")
+	v.StopCorruptionCheck()
+
+	if !c.config.Node.NoMempoolDump {
+		c.logger.Infof("Dumping unconfirmed transactions to %s", c.config.Node.MempoolFile)
+		if n, err := dumpMempool(v, c.config.Node.MempoolFile); err != nil {
+			c.logger.WithError(err).Error("dumpMempool failed")
+		} else {
+			c.logger.Infof("Dumped %d unconfirmed transactions", n)
+		}
+	}
+
+	c.logger.Info("Waiting for wallet backups to finish")
+	w.Shutdown()
+
+	if !db.IsReadOnly() {
+		c.logger.Infof("Recording shutdown reason %q", shutdownReason)
+		if err := visor.SetShutdownReason(db, shutdownReason); err != nil {
+			c.logger.WithError(err).Error("visor.SetShutdownReason failed")
+		}
+	}
+
 	return retErr
 }
 
@@ -338,6 +465,27 @@ func (c *Coin) initLogFile() (*os.File, error) {
 	return f, nil
 }
 
+func (c *Coin) initAPIDebugLogFile() (*os.File, error) {
+	logDir := filepath.Join(c.config.Node.DataDirectory, "logs")
+	if err := createDirIfNotExist(logDir); err != nil {
+		c.logger.WithError(err).Errorf("createDirIfNotExist(%s) failed", logDir)
+		return nil, fmt.Errorf("createDirIfNotExist(%s) failed: %v", logDir, err)
+	}
+
+	tf := "2006-01-02-030405"
+	logfile := filepath.Join(logDir, fmt.Sprintf("%s-api-debug.log", time.Now().Format(tf)))
+
+	f, err := os.OpenFile(logfile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		c.logger.WithError(err).Errorf("os.OpenFile(%s) failed", logfile)
+		return nil, err
+	}
+
+	c.logger.Warningf("API debug log enabled, the full requests and responses, including wallet seeds and passwords, are written to %s", logfile)
+
+	return f, nil
+}
+
 // ConfigureVisor sets the visor config values
 func (c *Coin) ConfigureVisor() visor.Config {
 	vc := visor.NewConfig()
@@ -353,11 +501,26 @@ func (c *Coin) ConfigureVisor() visor.Config {
 	vc.UnconfirmedVerifyTxn = c.config.Node.UnconfirmedVerifyTxn
 	vc.CreateBlockVerifyTxn = c.config.Node.CreateBlockVerifyTxn
 	vc.MaxBlockTransactionsSize = c.config.Node.MaxBlockTransactionsSize
+	vc.MaxUnconfirmedTransactions = c.config.Node.MaxUnconfirmedTransactions
+	vc.UnconfirmedEvictionMinAge = c.config.Node.UnconfirmedEvictionMinAge
+	vc.UnconfirmedAgeHistogramBuckets = c.config.Node.UnconfirmedAgeHistogramBuckets
+	vc.BlockSizeHistogramBuckets = c.config.Node.BlockSizeHistogramBuckets
+	vc.MaxFutureBlockTime = c.config.Node.MaxFutureBlockTime
+	vc.MinBlockInterval = c.config.Node.MinBlockInterval
+	vc.Signaling = c.config.Node.Signaling
+	vc.SignalingField = c.config.Node.SignalingField
+	vc.DustThreshold = c.config.Node.DustThreshold
+	vc.DustPolicy = c.config.Node.DustPolicy
+	vc.BlockProducer = c.config.Node.BlockProducer
+	vc.PruneOlderThanBlocks = c.config.Node.PruneOlderThanBlocks
+	vc.EnableEventLog = c.config.Node.EnableEventLog
+	vc.DBFillPercent = c.config.Node.DBFillPercent
 
 	vc.GenesisAddress = c.config.Node.genesisAddress
 	vc.GenesisSignature = c.config.Node.genesisSignature
 	vc.GenesisTimestamp = c.config.Node.GenesisTimestamp
 	vc.GenesisCoinVolume = c.config.Node.GenesisCoinVolume
+	vc.GenesisOutputs = c.config.Node.genesisOutputs
 
 	return vc
 }
@@ -381,6 +544,9 @@ func (c *Coin) ConfigureWallet() wallet.Config {
 	bc := c.config.Node.Fiber.Bip44Coin
 	wc.Bip44Coin = &bc
 
+	wc.S3Backup = c.config.Node.WalletBackupS3
+	wc.SessionTimeout = c.config.Node.WalletSessionTimeout
+
 	return wc
 }
 
@@ -395,6 +561,22 @@ func (c *Coin) ConfigureStorage() kvstorage.Config {
 	return sc
 }
 
+// maxSignaledBlockSize returns the largest of maxBlockSize and the max_block_size values of the signaling deployments
+func maxSignaledBlockSize(maxBlockSize uint32, signaling consensus.SignalingConfig) uint32 {
+	for _, d := range signaling.Deployments {
+		if d.Parameter != consensus.SignalingMaxBlockSize || d.Value <= uint64(maxBlockSize) {
+			continue
+		}
+
+		if d.Value > math.MaxUint32 {
+			return math.MaxUint32
+		}
+		maxBlockSize = uint32(d.Value)
+	}
+
+	return maxBlockSize
+}
+
 // ConfigureDaemon sets the daemon config values
 func (c *Coin) ConfigureDaemon() daemon.Config {
 	dc := daemon.NewConfig()
@@ -406,6 +588,8 @@ func (c *Coin) ConfigureDaemon() daemon.Config {
 	dc.Pool.MaxIncomingConnections = c.config.Node.MaxIncomingConnections
 	dc.Pool.MaxIncomingMessageLength = c.config.Node.MaxIncomingMessageLength
 	dc.Pool.MaxOutgoingMessageLength = c.config.Node.MaxOutgoingMessageLength
+	dc.Pool.OutboundBandwidthLimit = c.config.Node.OutboundBandwidthLimit
+	dc.Pool.InboundBandwidthLimit = c.config.Node.InboundBandwidthLimit
 
 	dc.Pex.DataDirectory = c.config.Node.DataDirectory
 	dc.Pex.Disabled = c.config.Node.DisablePEX
@@ -419,8 +603,11 @@ func (c *Coin) ConfigureDaemon() daemon.Config {
 
 	dc.Daemon.MaxOutgoingMessageLength = uint64(c.config.Node.MaxOutgoingMessageLength)
 	dc.Daemon.MaxIncomingMessageLength = uint64(c.config.Node.MaxIncomingMessageLength)
-	dc.Daemon.MaxBlockTransactionsSize = c.config.Node.MaxBlockTransactionsSize
+	// The daemon checks that the largest block fits in MaxOutgoingMessageLength,
+	// which rejects a signaled max_block_size above the wire limit
+	dc.Daemon.MaxBlockTransactionsSize = maxSignaledBlockSize(c.config.Node.MaxBlockTransactionsSize, c.config.Node.Signaling)
 	dc.Daemon.DefaultConnections = c.config.Node.DefaultConnections
+	dc.Daemon.GossipFanout = c.config.Node.GossipFanout
 	dc.Daemon.DisableOutgoingConnections = c.config.Node.DisableOutgoingConnections
 	dc.Daemon.DisableIncomingConnections = c.config.Node.DisableIncomingConnections
 	dc.Daemon.DisableNetworking = c.config.Node.DisableNetworking
@@ -435,6 +622,9 @@ func (c *Coin) ConfigureDaemon() daemon.Config {
 	dc.Daemon.GenesisHash = c.config.Node.genesisHash
 	dc.Daemon.UserAgent = c.config.Node.userAgent
 	dc.Daemon.UnconfirmedVerifyTxn = c.config.Node.UnconfirmedVerifyTxn
+	dc.Daemon.Coordinator = c.config.Node.Coordinator
+	dc.Daemon.CoordinatorMinParticipants = c.config.Node.CoordinatorMinParticipants
+	dc.Daemon.CoordinatorRoundTimeout = c.config.Node.CoordinatorRoundTimeout
 
 	if c.config.Node.OutgoingConnectionsRate == 0 {
 		c.config.Node.OutgoingConnectionsRate = time.Millisecond
@@ -444,7 +634,7 @@ func (c *Coin) ConfigureDaemon() daemon.Config {
 	return dc
 }
 
-func (c *Coin) createGUI(gw *api.Gateway, host string) (*api.Server, error) {
+func (c *Coin) createGUI(gw *api.Gateway, l APIListenerConfig) (*api.Server, error) {
 	config := api.Config{
 		StaticDir:          c.config.Node.GUIDirectory,
 		DisableCSRF:        c.config.Node.DisableCSRF,
@@ -466,38 +656,46 @@ func (c *Coin) createGUI(gw *api.Gateway, host string) (*api.Server, error) {
 			DaemonUserAgent: c.config.Node.userAgent,
 			BlockPublisher:  c.config.Node.RunBlockPublisher,
 		},
-		Username: c.config.Node.WebInterfaceUsername,
-		Password: c.config.Node.WebInterfacePassword,
+		Username:         c.config.Node.WebInterfaceUsername,
+		Password:         c.config.Node.WebInterfacePassword,
+		EnableMetrics:    c.config.Node.EnableMetrics,
+		DBCheckDuration:  c.dbCheckDuration,
+		ReadOnly:         l.ReadOnly,
+		RouteRateLimiter: c.apiRouteRateLimiter,
+	}
+
+	if c.apiDebugLog != nil {
+		config.DebugLog = c.apiDebugLog
 	}
 
 	var s *api.Server
-	if c.config.Node.WebInterfaceHTTPS {
+	if l.TLS != nil {
 		// Verify cert/key parameters, and if neither exist, create them
-		exists, err := checkCertFiles(c.config.Node.WebInterfaceCert, c.config.Node.WebInterfaceKey)
+		exists, err := checkCertFiles(l.TLS.Cert, l.TLS.Key)
 		if err != nil {
 			c.logger.WithError(err).Error("checkCertFiles failed")
 			return nil, err
 		}
 
 		if !exists {
-			c.logger.Infof("Autogenerating HTTP certificate and key files %s, %s", c.config.Node.WebInterfaceCert, c.config.Node.WebInterfaceKey)
-			if err := createCertFiles(c.config.Node.WebInterfaceCert, c.config.Node.WebInterfaceKey); err != nil {
+			c.logger.Infof("Autogenerating HTTP certificate and key files %s, %s", l.TLS.Cert, l.TLS.Key)
+			if err := createCertFiles(l.TLS.Cert, l.TLS.Key); err != nil {
 				c.logger.WithError(err).Error("createCertFiles failed")
 				return nil, err
 			}
 
-			c.logger.Infof("Created cert file %s", c.config.Node.WebInterfaceCert)
-			c.logger.Infof("Created key file %s", c.config.Node.WebInterfaceKey)
+			c.logger.Infof("Created cert file %s", l.TLS.Cert)
+			c.logger.Infof("Created key file %s", l.TLS.Key)
 		}
 
-		s, err = api.CreateHTTPS(host, config, gw, c.config.Node.WebInterfaceCert, c.config.Node.WebInterfaceKey)
+		s, err = api.CreateHTTPS(l.Addr, config, gw, l.TLS.Cert, l.TLS.Key)
 		if err != nil {
 			c.logger.WithError(err).Error("Failed to start web failed")
 			return nil, err
 		}
 	} else {
 		var err error
-		s, err = api.Create(host, config, gw)
+		s, err = api.Create(l.Addr, config, gw)
 		if err != nil {
 			c.logger.WithError(err).Error("Failed to start web failed")
 			return nil, err
@@ -564,9 +762,18 @@ func createCertFiles(certFile, keyFile string) error {
 	return nil
 }
 
-// ParseConfig prepare the config
+// ParseConfig prepares the config and validates it before anything is started.
+// If the config is invalid, the error is a visor.ConfigErrors with all the problems found
 func (c *Coin) ParseConfig() error {
-	return c.config.postProcess()
+	if err := c.config.postProcess(); err != nil {
+		return err
+	}
+
+	if errs := visor.ValidateConfig(c.ConfigureVisor()); len(errs) != 0 {
+		return visor.ConfigErrors(errs)
+	}
+
+	return nil
 }
 
 // InitTransaction creates the genesis transaction
@@ -0,0 +1,229 @@
+/*
+Package chaintest provides signed blocks, transactions and unspent output sets for tests.
+
+Everything is derived from a fixed seed, including the signatures, so the same blocks and transactions,
+with the same hashes, are made on every run.
+*/
+package chaintest
+
+import (
+	"crypto/sha256"
+	"log"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	secp "github.com/skycoin/skycoin/src/cipher/secp256k1-go/secp256k1-go2"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/util/fee"
+)
+
+const (
+	// Seed is the seed the keys are derived from
+	Seed = "skycoin chaintest"
+	// NumKeys is the number of keys derived from Seed
+	NumKeys = 8
+	// GenesisCoins is the number of droplets of the genesis output
+	GenesisCoins uint64 = 100e12
+	// GenesisTime is the timestamp of the genesis block
+	GenesisTime uint64 = 1426562704
+	// BlockInterval is the number of seconds between the blocks made by MakeBlockchain
+	BlockInterval uint64 = 3600
+	// SendCoins is the number of droplets sent by the transaction of each block made by MakeBlockchain
+	SendCoins uint64 = 1e6
+)
+
+var (
+	secKeys   = cipher.MustGenerateDeterministicKeyPairs([]byte(Seed), NumKeys)
+	addresses = make([]cipher.Address, NumKeys)
+)
+
+func init() {
+	for i, s := range secKeys {
+		addresses[i] = cipher.MustAddressFromSecKey(s)
+	}
+}
+
+// SecKey returns the i-th key derived from Seed.
+// The first key owns the genesis output and signs the blocks.
+func SecKey(i int) cipher.SecKey {
+	return secKeys[i]
+}
+
+// Address returns the address of SecKey(i)
+func Address(i int) cipher.Address {
+	return addresses[i]
+}
+
+// PubKey returns the public key the blocks are signed with
+func PubKey() cipher.PubKey {
+	return cipher.MustPubKeyFromSecKey(secKeys[0])
+}
+
+// GenesisBlock returns the signed genesis block, which sends GenesisCoins to Address(0),
+// and the key that owns the genesis output and signs the blocks
+func GenesisBlock() (coin.SignedBlock, cipher.SecKey) {
+	b, err := coin.NewGenesisBlock(addresses[0], GenesisCoins, GenesisTime)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	return signBlock(*b), secKeys[0]
+}
+
+// MakeTransaction returns a signed transaction that spends the inputs to the outputs.
+// The inputs must be owned by the addresses of the keys derived from Seed.
+// The transaction is not checked to be valid, so that invalid transactions can be made on purpose.
+func MakeTransaction(inputs coin.UxArray, outputs []coin.TransactionOutput) coin.Transaction {
+	txn := coin.Transaction{}
+	keys := make([]cipher.SecKey, len(inputs))
+	for i, ux := range inputs {
+		if err := txn.PushInput(ux.Hash()); err != nil {
+			log.Panic(err)
+		}
+		keys[i] = secKeyOf(ux.Body.Address)
+	}
+
+	for _, o := range outputs {
+		if err := txn.PushOutput(o.Address, o.Coins, o.Hours); err != nil {
+			log.Panic(err)
+		}
+	}
+
+	txn.InnerHash = txn.HashInner()
+	txn.Sigs = make([]cipher.Sig, len(txn.In))
+	for i, k := range keys {
+		txn.Sigs[i] = sign(cipher.AddSHA256(txn.InnerHash, txn.In[i]), k)
+	}
+
+	if err := txn.UpdateHeader(); err != nil {
+		log.Panic(err)
+	}
+
+	return txn
+}
+
+// MakeBlockchain returns n signed blocks, starting with the genesis block.
+// Each block after the genesis block is made BlockInterval seconds after the previous one, with a transaction
+// that sends SendCoins and half of the remaining coin hours from Address(0) to one of the other addresses,
+// in turn, and the change back to Address(0).
+// The blocks are valid for a blockchain signed by PubKey().
+func MakeBlockchain(n int) []coin.SignedBlock {
+	if n <= 0 {
+		return nil
+	}
+
+	gb, _ := GenesisBlock()
+	blocks := []coin.SignedBlock{gb}
+
+	change := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])[0]
+	var uxHash cipher.SHA256
+	uxHash = uxHash.Xor(change.SnapshotHash())
+
+	for seq := uint64(1); seq < uint64(n); seq++ {
+		prev := blocks[len(blocks)-1]
+		headTime := prev.Head.Time
+
+		hours, err := change.CoinHours(headTime)
+		if err != nil {
+			log.Panic(err)
+		}
+		remaining := fee.RemainingHours(hours, params.UserVerifyTxn.BurnFactor)
+		sendHours := remaining / 2
+
+		txn := MakeTransaction(coin.UxArray{change}, []coin.TransactionOutput{
+			{
+				Address: addresses[1+int(seq-1)%(NumKeys-1)],
+				Coins:   SendCoins,
+				Hours:   sendHours,
+			},
+			{
+				Address: addresses[0],
+				Coins:   change.Body.Coins - SendCoins,
+				Hours:   remaining - sendHours,
+			},
+		})
+
+		inputs := coin.UxArray{change}
+		b, err := coin.NewBlock(prev.Block, GenesisTime+seq*BlockInterval, uxHash, coin.Transactions{txn}, func(txn *coin.Transaction) (uint64, error) {
+			return fee.TransactionFee(txn, headTime, inputs)
+		})
+		if err != nil {
+			log.Panic(err)
+		}
+
+		uxHash = uxHash.Xor(change.SnapshotHash())
+		uxs := coin.CreateUnspents(b.Head, txn)
+		for _, ux := range uxs {
+			uxHash = uxHash.Xor(ux.SnapshotHash())
+		}
+		change = uxs[1]
+
+		blocks = append(blocks, signBlock(*b))
+	}
+
+	return blocks
+}
+
+// Unspents returns the unspent outputs after the blocks are executed, in the order they were created
+func Unspents(blocks []coin.SignedBlock) coin.UxArray {
+	var created coin.UxArray
+	spent := make(map[cipher.SHA256]struct{})
+	for _, b := range blocks {
+		for _, txn := range b.Body.Transactions {
+			for _, in := range txn.In {
+				spent[in] = struct{}{}
+			}
+			created = append(created, coin.CreateUnspents(b.Head, txn)...)
+		}
+	}
+
+	uxs := make(coin.UxArray, 0, len(created))
+	for _, ux := range created {
+		if _, ok := spent[ux.Hash()]; !ok {
+			uxs = append(uxs, ux)
+		}
+	}
+
+	return uxs
+}
+
+func signBlock(b coin.Block) coin.SignedBlock {
+	return coin.SignedBlock{
+		Block: b,
+		Sig:   sign(b.HashHeader(), secKeys[0]),
+	}
+}
+
+func secKeyOf(addr cipher.Address) cipher.SecKey {
+	for i, a := range addresses {
+		if a == addr {
+			return secKeys[i]
+		}
+	}
+
+	log.Panicf("chaintest: address %s is not derived from the seed", addr)
+	return cipher.SecKey{}
+}
+
+// sign signs the hash like cipher.SignHash, but with a nonce derived from the key and the hash
+// instead of a random one, so that the signatures are the same on every run.
+// It must not be used outside of tests.
+func sign(hash cipher.SHA256, sec cipher.SecKey) cipher.Sig {
+	var seckey, msg, nonce secp.Number
+	seckey.SetBytes(sec[:])
+	msg.SetBytes(hash[:])
+
+	k := sha256.Sum256(append(sec[:], hash[:]...))
+	for {
+		nonce.SetBytes(k[:])
+		if nonce.Sign() != 0 && nonce.Cmp(&secp.TheCurve.Order.Int) < 0 {
+			var sig secp.Signature
+			var recid int
+			if sig.Sign(&seckey, &msg, &nonce, &recid) == 1 {
+				return cipher.MustNewSig(append(sig.Bytes(), byte(recid)))
+			}
+		}
+
+		k = sha256.Sum256(k[:])
+	}
+}
@@ -0,0 +1,127 @@
+package chaintest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestGenesisBlock(t *testing.T) {
+	gb, sec := GenesisBlock()
+	require.Equal(t, SecKey(0), sec)
+	require.NoError(t, gb.VerifySignature(PubKey()))
+	require.Equal(t, uint64(0), gb.Head.BkSeq)
+	require.Equal(t, GenesisTime, gb.Head.Time)
+	require.Len(t, gb.Body.Transactions, 1)
+	require.Equal(t, []coin.TransactionOutput{
+		{
+			Address: Address(0),
+			Coins:   GenesisCoins,
+			Hours:   GenesisCoins,
+		},
+	}, gb.Body.Transactions[0].Out)
+
+	// The genesis block is the same on every call
+	gb2, _ := GenesisBlock()
+	require.Equal(t, gb, gb2)
+}
+
+func TestMakeTransaction(t *testing.T) {
+	gb, _ := GenesisBlock()
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+
+	outputs := []coin.TransactionOutput{
+		{
+			Address: Address(1),
+			Coins:   1e6,
+			Hours:   100,
+		},
+		{
+			Address: Address(0),
+			Coins:   GenesisCoins - 1e6,
+			Hours:   100,
+		},
+	}
+
+	txn := MakeTransaction(uxs, outputs)
+	require.NoError(t, txn.Verify())
+	require.NoError(t, txn.VerifyInputSignatures(uxs))
+	require.Equal(t, outputs, txn.Out)
+
+	// The signatures are deterministic
+	require.Equal(t, txn, MakeTransaction(uxs, outputs))
+
+	require.Panics(t, func() {
+		ux := uxs[0]
+		ux.Body.Address = testutil.MakeAddress()
+		MakeTransaction(coin.UxArray{ux}, outputs)
+	})
+}
+
+func TestMakeBlockchain(t *testing.T) {
+	require.Empty(t, MakeBlockchain(0))
+	require.Len(t, MakeBlockchain(1), 1)
+
+	blocks := MakeBlockchain(10)
+	require.Len(t, blocks, 10)
+	require.Equal(t, blocks, MakeBlockchain(10))
+
+	gb, _ := GenesisBlock()
+	require.Equal(t, gb, blocks[0])
+
+	db, shutdown := testutil.PrepareDB(t)
+	defer shutdown()
+
+	err := visor.CreateBuckets(db)
+	require.NoError(t, err)
+
+	bc, err := visor.NewBlockchain(db, visor.BlockchainConfig{
+		Pubkey: PubKey(),
+	})
+	require.NoError(t, err)
+
+	for i, b := range blocks {
+		require.Equal(t, uint64(i), b.Head.BkSeq)
+		require.NoError(t, bc.VerifySignature(&b))
+
+		err := db.Update("", func(tx *dbutil.Tx) error {
+			if i > 0 {
+				if err := bc.VerifyBlock(tx, &b); err != nil {
+					return err
+				}
+			}
+			return bc.ExecuteBlock(tx, &b)
+		})
+		require.NoError(t, err)
+	}
+
+	// The unspent outputs match the unspent pool of the blockchain
+	uxs := Unspents(blocks)
+	require.Len(t, uxs, 10)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		n, err := bc.Unspent().Len(tx)
+		require.NoError(t, err)
+		require.Equal(t, uint64(len(uxs)), n)
+
+		poolUxs, err := bc.Unspent().GetArray(tx, uxs.Hashes())
+		require.NoError(t, err)
+		require.Equal(t, uxs, poolUxs)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	var coins uint64
+	for _, ux := range uxs {
+		coins += ux.Body.Coins
+	}
+	require.Equal(t, GenesisCoins, coins)
+	require.Equal(t, Address(0), uxs[len(uxs)-1].Body.Address)
+	require.Equal(t, GenesisCoins-9*SendCoins, uxs[len(uxs)-1].Body.Coins)
+}
@@ -266,6 +266,8 @@ func create(p Params, auxs coin.AddressUxOuts, headTime uint64, callCount int) (
 					return nil, nil, err
 				}
 
+				totalInputHours = newTotalHours
+				feeHours = newFee
 				spends = append(spends, extra)
 
 				if err := txn.PushInput(extra.Hash); err != nil {
@@ -291,6 +293,67 @@ func create(p Params, auxs coin.AddressUxOuts, headTime uint64, callCount int) (
 		}
 	}
 
+	// If the change output would be dust, either fail or, with the merge policy, add inputs
+	// with the most coins until the change reaches the dust threshold.
+	// The hours of the extra inputs, minus the additional fee, are added to the change.
+	if changeCoins > 0 && changeCoins < p.DustThreshold {
+		if p.DustPolicy != DustPolicyMerge {
+			return nil, nil, ErrDustChange
+		}
+
+		logger.Info("Merging the dust change output with extra inputs")
+		z := uxBalancesSub(uxb, spends)
+		sortSpendsCoinsHighToLow(z)
+		for _, extra := range z {
+			if changeCoins >= p.DustThreshold {
+				break
+			}
+
+			newTotalHours, err := mathutil.AddUint64(totalInputHours, extra.Hours)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			newFee := fee.RequiredFee(newTotalHours, params.UserVerifyTxn.BurnFactor)
+			additionalFee := newFee - feeHours
+			if extra.Hours < additionalFee {
+				err := errors.New("calculated additional fee is unexpectedly higher than the extra input's hours")
+				logger.WithError(err).Error()
+				return nil, nil, err
+			}
+
+			changeCoins, err = mathutil.AddUint64(changeCoins, extra.Coins)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			changeHours, err = mathutil.AddUint64(changeHours, extra.Hours-additionalFee)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			totalInputHours = newTotalHours
+			feeHours = newFee
+			spends = append(spends, extra)
+
+			if err := txn.PushInput(extra.Hash); err != nil {
+				logger.Critical().WithError(err).Error("PushInput failed")
+				return nil, nil, err
+			}
+		}
+
+		if changeCoins < p.DustThreshold {
+			return nil, nil, ErrDustChange
+		}
+
+		logger.WithFields(logrus.Fields{
+			"changeCoins": changeCoins,
+			"changeHours": changeHours,
+			"nSpends":     len(spends),
+			"nInputs":     len(txn.In),
+		}).Info("Recalculated spend parameters after merging the dust change output")
+	}
+
 	// With auto share mode, if there are leftover hours and change couldn't be force-added,
 	// recalculate that share ratio at 100%
 	if changeCoins == 0 && changeHours > 0 && p.HoursSelection.Type == HoursSelectionTypeAuto && p.HoursSelection.Mode == HoursSelectionModeShare {
@@ -361,6 +424,12 @@ func create(p Params, auxs coin.AddressUxOuts, headTime uint64, callCount int) (
 	// Initialize unsigned transaction
 	txn.Sigs = make([]cipher.Sig, len(txn.In))
 
+	// The size of the transaction does not depend on the hours of its outputs,
+	// so the minimum fee per byte is burned once the inputs and outputs are final
+	if err := burnMinFeePerByte(txn, totalInputHours, changeCoins > 0); err != nil {
+		return nil, nil, err
+	}
+
 	if err := txn.UpdateHeader(); err != nil {
 		logger.Critical().WithError(err).Error("txn.UpdateHeader failed")
 		return nil, nil, err
@@ -382,9 +451,74 @@ func create(p Params, auxs coin.AddressUxOuts, headTime uint64, callCount int) (
 		return nil, nil, fmt.Errorf("Created transaction that violates invariants, this is a bug: %v", err)
 	}
 
+	// The invariants check the outputs against p.To in order, so the transaction is sorted afterwards
+	if p.SortInputsOutputs {
+		if err := txn.SortInputsOutputs(); err != nil {
+			logger.Critical().WithError(err).Error("txn.SortInputsOutputs failed")
+			return nil, nil, err
+		}
+
+		if err := txn.UpdateHeader(); err != nil {
+			logger.Critical().WithError(err).Error("txn.UpdateHeader failed")
+			return nil, nil, err
+		}
+
+		for i, h := range txn.In {
+			inputs[i] = uxbMap[h]
+		}
+	}
+
 	return txn, inputs, nil
 }
 
+// burnMinFeePerByte lowers the hours of the change output of txn, its last output if hasChange, so that txn burns
+// at least params.UserVerifyTxn.MinFeePerByte coin hours per byte of its size. inputHours are the hours of the inputs of txn.
+// ErrInsufficientHoursFeePerByte is returned if txn has no change output with enough hours to burn
+func burnMinFeePerByte(txn *coin.Transaction, inputHours uint64, hasChange bool) error {
+	if params.UserVerifyTxn.MinFeePerByte == 0 {
+		return nil
+	}
+
+	size, err := txn.Size()
+	if err != nil {
+		return err
+	}
+
+	minFee, err := mathutil.MultUint64(uint64(size), params.UserVerifyTxn.MinFeePerByte)
+	if err != nil {
+		return ErrInsufficientHoursFeePerByte
+	}
+
+	outputHours, err := txn.OutputHours()
+	if err != nil {
+		return err
+	}
+
+	if outputHours > inputHours {
+		return fee.ErrTxnInsufficientCoinHours
+	}
+
+	burned := inputHours - outputHours
+	if burned >= minFee {
+		return nil
+	}
+
+	missing := minFee - burned
+	if !hasChange || txn.Out[len(txn.Out)-1].Hours < missing {
+		return ErrInsufficientHoursFeePerByte
+	}
+
+	txn.Out[len(txn.Out)-1].Hours -= missing
+
+	logger.WithFields(logrus.Fields{
+		"size":        size,
+		"minFee":      minFee,
+		"changeHours": txn.Out[len(txn.Out)-1].Hours,
+	}).Info("Burned change hours to pay the minimum fee per byte")
+
+	return nil
+}
+
 func verifyCreatedUnignedInvariants(p Params, txn *coin.Transaction, inputs []UxBalance) error {
 	if !txn.IsFullyUnsigned() {
 		return errors.New("Transaction is not fully unsigned")
@@ -500,5 +634,14 @@ func VerifyCreatedInvariants(p Params, txn *coin.Transaction, inputs []UxBalance
 		return errors.New("Transaction will not satisfy required fee")
 	}
 
+	size, err := txn.Size()
+	if err != nil {
+		return err
+	}
+
+	if err := fee.VerifyTransactionFeePerByte(inputHours-outputHours, size, params.UserVerifyTxn.MinFeePerByte); err != nil {
+		return errors.New("Transaction will not satisfy required fee per byte")
+	}
+
 	return nil
 }
@@ -14,6 +14,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/testutil"
 	"github.com/skycoin/skycoin/src/util/fee"
 )
@@ -304,6 +305,151 @@ func TestCreate(t *testing.T) {
 			},
 		},
 
+		{
+			name: "manual, 1 output, dust receiver",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[0],
+						Hours:   130,
+						Coins:   1e6,
+					},
+				},
+				DustThreshold: 2e6,
+			},
+			unspents: uxouts,
+			err:      ErrDustReceiver,
+		},
+
+		{
+			name: "manual, 1 output, dust change rejected",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[0],
+						Hours:   130,
+						Coins:   2e6 + 1,
+					},
+				},
+				DustThreshold: 2e6,
+				DustPolicy:    DustPolicyReject,
+			},
+			unspents: uxouts,
+			err:      ErrDustChange,
+		},
+
+		{
+			name: "manual, 1 output, change above dust threshold",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[0],
+						Hours:   130,
+						Coins:   2e6 + 1,
+					},
+				},
+				DustThreshold: 1e6,
+			},
+			unspents:       uxouts,
+			chosenUnspents: []coin.UxOut{originalUxouts[0], originalUxouts[1]},
+			changeOutput: &coin.TransactionOutput{
+				Address: changeAddress,
+				Hours:   50,
+				Coins:   2e6 - 1,
+			},
+		},
+
+		{
+			// the change is dust and an additional input is merged into it,
+			// paying the additional fee of its hours
+			name: "manual, 1 output, dust change merged",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[0],
+						Hours:   130,
+						Coins:   2e6 + 1,
+					},
+				},
+				DustThreshold: 2e6,
+				DustPolicy:    DustPolicyMerge,
+			},
+			unspents:       uxouts,
+			chosenUnspents: []coin.UxOut{originalUxouts[0], originalUxouts[1], originalUxouts[2]},
+			changeOutput: &coin.TransactionOutput{
+				Address: changeAddress,
+				Hours:   142,
+				Coins:   4e6 - 1,
+			},
+		},
+
+		{
+			name: "manual, 2 outputs, change, sorted inputs and outputs",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[1],
+						Hours:   20,
+						Coins:   1e6,
+					},
+					{
+						Address: addrs[0],
+						Hours:   30,
+						Coins:   2e6,
+					},
+				},
+				SortInputsOutputs: true,
+			},
+			unspents:       uxouts,
+			chosenUnspents: []coin.UxOut{originalUxouts[0], originalUxouts[1]},
+			changeOutput: &coin.TransactionOutput{
+				Address: changeAddress,
+				Hours:   130,
+				Coins:   1e6,
+			},
+		},
+
+		{
+			name: "manual, 1 output, dust change merge no more unspents",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[0],
+						Hours:   130,
+						Coins:   2e6 + 1,
+					},
+				},
+				DustThreshold: 2e6,
+				DustPolicy:    DustPolicyMerge,
+			},
+			unspents: originalUxouts[:2],
+			err:      ErrDustChange,
+		},
+
 		{
 			name: "manual, 1 output, change, unspecified change address",
 			params: Params{
@@ -757,12 +903,125 @@ func TestCreate(t *testing.T) {
 				to = append(to, *tc.changeOutput)
 			}
 
+			if tc.params.SortInputsOutputs {
+				require.Equal(t, sortedTxnIn, txn.In)
+
+				sort.Slice(to, func(i, j int) bool {
+					if c := bytes.Compare(to[i].Address.Bytes(), to[j].Address.Bytes()); c != 0 {
+						return c < 0
+					}
+					if to[i].Coins != to[j].Coins {
+						return to[i].Coins < to[j].Coins
+					}
+					return to[i].Hours < to[j].Hours
+				})
+			}
+
 			// Compare transaction outputs
 			require.Equal(t, to, txn.Out)
 		})
 	}
 }
 
+func TestCreateMinFeePerByte(t *testing.T) {
+	headTime := uint64(time.Now().UTC().Unix())
+	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed([]byte("seed"), 1)
+	changeAddress := testutil.MakeAddress()
+	toAddress := testutil.MakeAddress()
+
+	uxout := makeUxOut(t, secKeys[0], 2e6, 1000)
+	uxout.Head.Time = headTime
+	auxs := coin.AddressUxOuts{
+		uxout.Body.Address: []coin.UxOut{uxout},
+	}
+
+	originalMinFeePerByte := params.UserVerifyTxn.MinFeePerByte
+	defer func() {
+		params.UserVerifyTxn.MinFeePerByte = originalMinFeePerByte
+	}()
+
+	cases := []struct {
+		name          string
+		minFeePerByte uint64
+		toCoins       uint64
+		toHours       uint64
+		err           error
+	}{
+		{
+			name:    "no minimum, the burn factor fee is burned",
+			toCoins: 1e6,
+			toHours: 10,
+		},
+		{
+			name:          "minimum burned from the change hours",
+			minFeePerByte: 1,
+			toCoins:       1e6,
+			toHours:       10,
+		},
+		{
+			name:          "change output has too few hours",
+			minFeePerByte: 10,
+			toCoins:       1e6,
+			toHours:       10,
+			err:           ErrInsufficientHoursFeePerByte,
+		},
+		{
+			name:          "no change output",
+			minFeePerByte: 1,
+			toCoins:       2e6,
+			toHours:       900,
+			err:           ErrInsufficientHoursFeePerByte,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			params.UserVerifyTxn.MinFeePerByte = tc.minFeePerByte
+
+			p := Params{
+				ChangeAddress: &changeAddress,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: toAddress,
+						Coins:   tc.toCoins,
+						Hours:   tc.toHours,
+					},
+				},
+			}
+
+			txn, inputs, err := Create(p, auxs, headTime)
+			if tc.err != nil {
+				require.Equal(t, tc.err, err)
+				require.Nil(t, txn)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, inputs, 1)
+			require.Len(t, txn.Out, 2)
+			require.Equal(t, tc.toHours, txn.Out[0].Hours)
+
+			size, err := txn.Size()
+			require.NoError(t, err)
+
+			outputHours, err := txn.OutputHours()
+			require.NoError(t, err)
+			burned := inputs[0].Hours - outputHours
+
+			requiredFee := fee.RequiredFee(inputs[0].Hours, params.UserVerifyTxn.BurnFactor)
+			minFee := uint64(size) * tc.minFeePerByte
+			if minFee > requiredFee {
+				require.Equal(t, minFee, burned)
+			} else {
+				require.Equal(t, requiredFee, burned)
+			}
+			require.NoError(t, fee.VerifyTransactionFeePerByte(burned, size, tc.minFeePerByte))
+		})
+	}
+}
+
 func makeUxOut(t *testing.T, s cipher.SecKey, coins, hours uint64) coin.UxOut { //nolint:unparam
 	body := makeUxBody(t, s, coins, hours)
 	tm := rand.Int31n(1000)
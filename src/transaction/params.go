@@ -2,6 +2,7 @@ package transaction
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/shopspring/decimal"
 
@@ -31,6 +32,11 @@ const (
 
 	// HoursSelectionModeShare will distribute coin hours equally amongst destinations
 	HoursSelectionModeShare = "share"
+
+	// DustPolicyReject fails transaction creation if the change output would be below the dust threshold
+	DustPolicyReject DustPolicy = "reject"
+	// DustPolicyMerge spends additional unspent outputs to bring the change output to the dust threshold
+	DustPolicyMerge DustPolicy = "merge"
 )
 
 var (
@@ -60,8 +66,29 @@ var (
 	ErrInvalidShareFactor = NewError(errors.New("HoursSelection.ShareFactor can only be used for share mode"))
 	// ErrShareFactorOutOfRange HoursSelection.ShareFactor must be >= 0 and <= 1
 	ErrShareFactorOutOfRange = NewError(errors.New("HoursSelection.ShareFactor must be >= 0 and <= 1"))
+	// ErrDustReceiver To.Coins must not be below the dust threshold
+	ErrDustReceiver = NewError(errors.New("To.Coins must not be below the dust threshold"))
+	// ErrInvalidDustPolicy Invalid DustPolicy
+	ErrInvalidDustPolicy = NewError(errors.New("Invalid DustPolicy"))
+	// ErrDustChange the change output would be below the dust threshold
+	ErrDustChange = NewError(errors.New("Change output coins would be below the dust threshold"))
+	// ErrInsufficientHoursFeePerByte the change output does not have enough hours to burn the minimum fee per byte
+	ErrInsufficientHoursFeePerByte = NewError(errors.New("Insufficient coin hours to burn the minimum fee per byte of the transaction size"))
 )
 
+// DustPolicy is how transaction creation handles a change output with fewer coins than the dust threshold
+type DustPolicy string
+
+// ParseDustPolicy parses the name of a dust policy
+func ParseDustPolicy(s string) (DustPolicy, error) {
+	switch p := DustPolicy(s); p {
+	case DustPolicyReject, DustPolicyMerge:
+		return p, nil
+	default:
+		return "", fmt.Errorf("invalid dust policy %q, must be %q or %q", s, DustPolicyReject, DustPolicyMerge)
+	}
+}
+
 // HoursSelection defines options for hours distribution
 type HoursSelection struct {
 	Type        string
@@ -74,6 +101,14 @@ type Params struct {
 	HoursSelection HoursSelection
 	To             []coin.TransactionOutput
 	ChangeAddress  *cipher.Address
+	// DustThreshold is the minimum number of droplets of an output, 0 disables the check.
+	// Receivers with fewer coins are rejected, a change output with fewer coins is handled by DustPolicy
+	DustThreshold uint64
+	// DustPolicy defaults to DustPolicyReject if empty
+	DustPolicy DustPolicy
+	// SortInputsOutputs sorts the inputs and outputs of the created transaction into a canonical order,
+	// see coin.Transaction.SortInputsOutputs
+	SortInputsOutputs bool
 }
 
 // Validate validates Params
@@ -94,6 +129,16 @@ func (c Params) Validate() error {
 		if to.Address.Null() {
 			return ErrNullAddressReceiver
 		}
+
+		if to.Coins < c.DustThreshold {
+			return ErrDustReceiver
+		}
+	}
+
+	switch c.DustPolicy {
+	case "", DustPolicyReject, DustPolicyMerge:
+	default:
+		return ErrInvalidDustPolicy
 	}
 
 	// Check for duplicate outputs, a transaction can't have outputs with
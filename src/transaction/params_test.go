@@ -247,6 +247,45 @@ func TestCreateWalletParamsVerify(t *testing.T) {
 			err: "To contains duplicate values",
 		},
 
+		{
+			name: "to coins below dust threshold",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				To:            toManual,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				DustThreshold: 2e6,
+			},
+			err: "To.Coins must not be below the dust threshold",
+		},
+
+		{
+			name: "invalid dust policy",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				To:            toManual,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				DustPolicy: "foo",
+			},
+			err: "Invalid DustPolicy",
+		},
+
+		{
+			name: "valid dust threshold",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				To:            toManual,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				DustThreshold: 1e6,
+				DustPolicy:    DustPolicyMerge,
+			},
+		},
+
 		{
 			name: "valid auto split even share factor",
 			params: Params{
@@ -283,3 +322,16 @@ func TestCreateWalletParamsVerify(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDustPolicy(t *testing.T) {
+	p, err := ParseDustPolicy("reject")
+	require.NoError(t, err)
+	require.Equal(t, DustPolicyReject, p)
+
+	p, err = ParseDustPolicy("merge")
+	require.NoError(t, err)
+	require.Equal(t, DustPolicyMerge, p)
+
+	_, err = ParseDustPolicy("absorb")
+	require.Equal(t, errors.New(`invalid dust policy "absorb", must be "reject" or "merge"`), err)
+}
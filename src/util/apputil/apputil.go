@@ -11,19 +11,22 @@ import (
 	"syscall"
 )
 
-// CatchInterrupt catches CTRL-C and closes the quit channel if it occurs.
+// CatchInterrupt catches CTRL-C or SIGTERM and closes the quit channel if it occurs.
+// The received signal is returned after the quit channel is closed.
 // If CTRL-C is called again, the program stack is dumped and the process panics,
 // so that shutdown hangs can be diagnosed.
-func CatchInterrupt(quit chan<- struct{}) {
+func CatchInterrupt(quit chan<- struct{}) os.Signal {
 	sigchan := make(chan os.Signal, 1)
-	signal.Notify(sigchan, os.Interrupt)
-	<-sigchan
+	signal.Notify(sigchan, os.Interrupt, syscall.SIGTERM)
+	sig := <-sigchan
 	signal.Stop(sigchan)
 	close(quit)
 
 	// If ctrl-c is called again, panic so that the program state can be examined.
 	// Ctrl-c would be called again if program shutdown was stuck.
 	go CatchInterruptPanic()
+
+	return sig
 }
 
 // CatchInterruptPanic catches os.Interrupt and panics
@@ -46,6 +49,19 @@ func CatchDebug() {
 	}
 }
 
+// CatchHangup catches SIGHUP and sends a notification on the reload channel each time it occurs.
+// If a notification is already pending, the signal is dropped.
+func CatchHangup(reload chan<- struct{}) {
+	sigchan := make(chan os.Signal, 1)
+	signal.Notify(sigchan, syscall.SIGHUP)
+	for range sigchan {
+		select {
+		case reload <- struct{}{}:
+		default:
+		}
+	}
+}
+
 // PrintProgramStatus prints all goroutine data to stdout
 func PrintProgramStatus() {
 	p := pprof.Lookup("goroutine")
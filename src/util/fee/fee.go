@@ -19,6 +19,9 @@ var (
 
 	// ErrTxnInsufficientCoinHours is returned if a transaction has more coinhours in its outputs than its inputs
 	ErrTxnInsufficientCoinHours = errors.New("Insufficient coinhours for transaction outputs")
+
+	// ErrTxnInsufficientFeePerByte is returned if a transaction's coinhour fee per byte of its size is not enough
+	ErrTxnInsufficientFeePerByte = errors.New("Transaction coinhour fee per byte minimum not met")
 )
 
 // VerifyTransactionFee performs additional transaction verification at the unconfirmed pool level.
@@ -59,6 +62,20 @@ func VerifyTransactionFeeForHours(hours, fee uint64, burnFactor uint32) error {
 	return nil
 }
 
+// VerifyTransactionFeePerByte verifies that the fee divided by the transaction size in bytes is at least minFeePerByte.
+// A minFeePerByte of 0 disables the check.
+func VerifyTransactionFeePerByte(fee uint64, size uint32, minFeePerByte uint64) error {
+	if minFeePerByte == 0 {
+		return nil
+	}
+
+	if size == 0 || fee/uint64(size) < minFeePerByte {
+		return ErrTxnInsufficientFeePerByte
+	}
+
+	return nil
+}
+
 // RequiredFee returns the coinhours fee required for an amount of hours
 // The required fee is calculated as hours/burnFactor, rounded up.
 func RequiredFee(hours uint64, burnFactor uint32) uint64 {
@@ -245,6 +245,56 @@ var burnFactor10RequiredFeeTestCases = []requiredFeeTestCase{
 	{1003, 101},
 }
 
+func TestVerifyTransactionFeePerByte(t *testing.T) {
+	cases := []struct {
+		name          string
+		fee           uint64
+		size          uint32
+		minFeePerByte uint64
+		err           error
+	}{
+		{
+			name:          "disabled",
+			fee:           0,
+			size:          200,
+			minFeePerByte: 0,
+		},
+		{
+			name:          "exact",
+			fee:           400,
+			size:          200,
+			minFeePerByte: 2,
+		},
+		{
+			name:          "rounded down",
+			fee:           599,
+			size:          200,
+			minFeePerByte: 2,
+		},
+		{
+			name:          "insufficient",
+			fee:           399,
+			size:          200,
+			minFeePerByte: 2,
+			err:           ErrTxnInsufficientFeePerByte,
+		},
+		{
+			name:          "zero size",
+			fee:           100,
+			size:          0,
+			minFeePerByte: 1,
+			err:           ErrTxnInsufficientFeePerByte,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := VerifyTransactionFeePerByte(tc.fee, tc.size, tc.minFeePerByte)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
 func TestRequiredFee(t *testing.T) {
 	cases := []struct {
 		burnFactor uint32
@@ -170,6 +170,71 @@ func SaveBinary(filename string, data []byte, mode os.FileMode) error {
 	return os.Remove(tmpname)
 }
 
+// SaveBinaryAtomic persists data into given file atomically.
+// The data is written to a temporary file in the same directory, which is synced
+// to disk and renamed over the target file. If any step fails, the temporary file
+// is removed and the target file is left unchanged.
+func SaveBinaryAtomic(filename string, data []byte, mode os.FileMode) error {
+	dir := filepath.Dir(filename)
+	f, err := ioutil.TempFile(dir, filepath.Base(filename)+".tmp.")
+	if err != nil {
+		return err
+	}
+	tmpname := f.Name()
+
+	removeTmp := func() {
+		if err := os.Remove(tmpname); err != nil && !os.IsNotExist(err) {
+			logger.WithError(err).Warningf("os.Remove(%s) failed", tmpname)
+		}
+	}
+
+	if err := writeAndSync(f, data, mode); err != nil {
+		f.Close()
+		removeTmp()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		removeTmp()
+		return err
+	}
+
+	if err := os.Rename(tmpname, filename); err != nil {
+		removeTmp()
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+func writeAndSync(f *os.File, data []byte, mode os.FileMode) error {
+	if err := f.Chmod(mode); err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
+
+// syncDir syncs a directory to disk, to persist the renaming of a file in it
+func syncDir(dir string) error {
+	// Directories can't be synced on windows
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
+
 //TODO: require file named after application and then hashcode, in static directory
 
 // ResolveResourceDirectory searches locations for a research directory and returns absolute path
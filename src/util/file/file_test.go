@@ -251,6 +251,50 @@ func TestSaveBinary(t *testing.T) {
 	// requireFileMode(t, fn+".bak", 0644)
 }
 
+func TestSaveBinaryAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "savebinaryatomic")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fn := filepath.Join(dir, "test.bin")
+	b := make([]byte, 128)
+	_, err = rand.Read(b)
+	require.NoError(t, err)
+	err = SaveBinaryAtomic(fn, b, 0600)
+	require.NoError(t, err)
+	requireIsRegularFile(t, fn)
+	requireFileContentsBinary(t, fn, b)
+	requireFileMode(t, fn, 0600)
+
+	b2 := make([]byte, 128)
+	_, err = rand.Read(b2)
+	require.NoError(t, err)
+	require.False(t, bytes.Equal(b, b2))
+
+	err = SaveBinaryAtomic(fn, b2, 0644)
+	require.NoError(t, err)
+	requireIsRegularFile(t, fn)
+	requireFileContentsBinary(t, fn, b2)
+	requireFileMode(t, fn, 0644)
+
+	// Temporary files are not left behind
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// Failing to rename over a directory leaves the directory unchanged
+	sub := filepath.Join(dir, "sub")
+	require.NoError(t, os.Mkdir(sub, 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(sub, "foo"), b, 0600))
+	err = SaveBinaryAtomic(sub, b, 0600)
+	require.Error(t, err)
+	requireFileContentsBinary(t, filepath.Join(sub, "foo"), b)
+
+	files, err = ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+}
+
 func TestIsWritable(t *testing.T) {
 	fn := "test.bin"
 	defer cleanup(t, fn)
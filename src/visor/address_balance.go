@@ -0,0 +1,46 @@
+package visor
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// ReadUnspentsOfAddrs returns the confirmed unspent outputs of addrs and the head block, read directly from db.
+// It does not create a Visor, so it can be used with a database opened read-only while the node is stopped.
+// The head block is nil if the database has no blocks.
+func ReadUnspentsOfAddrs(db *dbutil.DB, addrs []cipher.Address) (coin.AddressUxOuts, *coin.SignedBlock, error) {
+	bc, err := NewBlockchain(db, BlockchainConfig{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	addrUxs := make(coin.AddressUxOuts, len(addrs))
+	var head *coin.SignedBlock
+	if err := db.View("ReadUnspentsOfAddrs", func(tx *dbutil.Tx) error {
+		// A new database has no blocks
+		if !dbutil.Exists(tx, blockdb.BlocksBkt) {
+			return nil
+		}
+
+		if _, ok, err := bc.HeadSeq(tx); err != nil {
+			return err
+		} else if !ok {
+			return nil
+		}
+
+		var err error
+		head, err = bc.Head(tx)
+		if err != nil {
+			return err
+		}
+
+		addrUxs, err = bc.Unspent().GetUnspentsOfAddrs(tx, addrs)
+		return err
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	return addrUxs, head, nil
+}
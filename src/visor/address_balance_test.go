@@ -0,0 +1,56 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestReadUnspentsOfAddrs(t *testing.T) {
+	v, shutdown := newChainExportTestVisor(t)
+	defer shutdown()
+
+	addr := testutil.MakeAddress()
+
+	// An empty blockchain has no unspents
+	addrUxs, head, err := ReadUnspentsOfAddrs(v.db, []cipher.Address{genAddress, addr})
+	require.NoError(t, err)
+	require.Nil(t, head)
+	require.Empty(t, addrUxs[genAddress])
+	require.Empty(t, addrUxs[addr])
+
+	gb := addGenesisBlockToVisor(t, v)
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+
+	addrUxs, head, err = ReadUnspentsOfAddrs(v.db, []cipher.Address{genAddress, addr})
+	require.NoError(t, err)
+	require.Equal(t, gb.HashHeader(), head.HashHeader())
+	require.Equal(t, coin.UxArray(uxs), addrUxs[genAddress])
+	require.Empty(t, addrUxs[addr])
+
+	// Send part of the genesis coins to addr
+	txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, addr, 100e6)
+	err = v.db.Update("", func(tx *dbutil.Tx) error {
+		b, err := v.blockchain.NewBlock(tx, coin.Transactions{txn}, genTime+100)
+		require.NoError(t, err)
+
+		return v.executeSignedBlock(tx, coin.SignedBlock{
+			Block: *b,
+			Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+		})
+	})
+	require.NoError(t, err)
+
+	addrUxs, head, err = ReadUnspentsOfAddrs(v.db, []cipher.Address{genAddress, addr})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), head.Seq())
+	require.Len(t, addrUxs[addr], 1)
+	require.Equal(t, uint64(100e6), addrUxs[addr][0].Body.Coins)
+	require.Len(t, addrUxs[genAddress], 1)
+	require.Equal(t, uint64(genCoins-100e6), addrUxs[genAddress][0].Body.Coins)
+}
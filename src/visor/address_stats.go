@@ -0,0 +1,109 @@
+package visor
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/util/mathutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+)
+
+// AddressStats are the usage statistics of an address, from the confirmed outputs it has received.
+// Coins sent from an address to itself, e.g. as change, are counted as both sent and received.
+type AddressStats struct {
+	Address cipher.Address
+	// Received is the sum of the coins of all outputs received by the address
+	Received uint64
+	// Sent is the sum of the coins of the outputs of the address that have been spent
+	Sent uint64
+	// UnspentCount is the number of outputs of the address that have not been spent
+	UnspentCount uint64
+	// LastSeq is the seq of the most recent block that created or spent an output of the address.
+	// Only set if Used is true
+	LastSeq uint64
+	// Used is true if the address has received any output
+	Used bool
+}
+
+// GetAddressStats returns the usage statistics of addresses.
+// The statistics are read from the address index of the history db, which is built from the blockchain on startup.
+// The returned bool is true if the index has not been built up to the head block yet, in which case the
+// statistics are approximate and miss the most recent blocks.
+func (vs *Visor) GetAddressStats(addrs []cipher.Address) ([]AddressStats, bool, error) {
+	var stats []AddressStats
+	var approximate bool
+
+	if err := vs.db.View("GetAddressStats", func(tx *dbutil.Tx) error {
+		var err error
+		stats, approximate, err = vs.getAddressStats(tx, addrs)
+		return err
+	}); err != nil {
+		return nil, false, err
+	}
+
+	return stats, approximate, nil
+}
+
+func (vs *Visor) getAddressStats(tx *dbutil.Tx, addrs []cipher.Address) ([]AddressStats, bool, error) {
+	headSeq, ok, err := vs.blockchain.HeadSeq(tx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	parsedSeq, parsed, err := vs.history.ParsedBlockSeq(tx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	approximate := ok && (!parsed || parsedSeq < headSeq)
+
+	stats := make([]AddressStats, len(addrs))
+	for i, addr := range addrs {
+		outs, err := vs.history.GetOutputsForAddress(tx, addr)
+		if err != nil {
+			return nil, false, checkHistoryUxOutsPruned(tx, vs.blockchain, err)
+		}
+
+		stats[i], err = addressStats(addr, outs)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	return stats, approximate, nil
+}
+
+// addressStats computes the usage statistics of an address from the history of its outputs
+func addressStats(addr cipher.Address, outs []historydb.UxOut) (AddressStats, error) {
+	s := AddressStats{
+		Address: addr,
+		Used:    len(outs) > 0,
+	}
+
+	for _, o := range outs {
+		var err error
+		s.Received, err = mathutil.AddUint64(s.Received, o.Out.Body.Coins)
+		if err != nil {
+			return AddressStats{}, err
+		}
+
+		if o.Out.Head.BkSeq > s.LastSeq {
+			s.LastSeq = o.Out.Head.BkSeq
+		}
+
+		if o.SpentTxnID == (cipher.SHA256{}) {
+			s.UnspentCount++
+			continue
+		}
+
+		s.Sent, err = mathutil.AddUint64(s.Sent, o.Out.Body.Coins)
+		if err != nil {
+			return AddressStats{}, err
+		}
+
+		if o.SpentBlockSeq > s.LastSeq {
+			s.LastSeq = o.SpentBlockSeq
+		}
+	}
+
+	return s, nil
+}
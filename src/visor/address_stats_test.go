@@ -0,0 +1,71 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+)
+
+func TestGetAddressStats(t *testing.T) {
+	v, shutdown := newChainExportTestVisor(t)
+	defer shutdown()
+
+	gb := addGenesisBlockToVisor(t, v)
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	genesisCoins := uxs[0].Body.Coins
+
+	// Send part of the genesis output to addr, with the change back to the genesis address
+	addr := testutil.MakeAddress()
+	unused := testutil.MakeAddress()
+	txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, addr, 100e6)
+	err := v.db.Update("", func(tx *dbutil.Tx) error {
+		b, err := v.blockchain.NewBlock(tx, coin.Transactions{txn}, genTime+100)
+		require.NoError(t, err)
+
+		return v.executeSignedBlock(tx, coin.SignedBlock{
+			Block: *b,
+			Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+		})
+	})
+	require.NoError(t, err)
+
+	stats, approximate, err := v.GetAddressStats([]cipher.Address{genAddress, addr, unused})
+	require.NoError(t, err)
+	require.False(t, approximate)
+	require.Equal(t, []AddressStats{
+		{
+			Address:      genAddress,
+			Received:     genesisCoins + genesisCoins - 100e6,
+			Sent:         genesisCoins,
+			UnspentCount: 1,
+			LastSeq:      1,
+			Used:         true,
+		},
+		{
+			Address:      addr,
+			Received:     100e6,
+			UnspentCount: 1,
+			LastSeq:      1,
+			Used:         true,
+		},
+		{
+			Address: unused,
+		},
+	}, stats)
+
+	// The statistics are approximate until the history is parsed up to the head block
+	err = v.db.Update("", func(tx *dbutil.Tx) error {
+		return historydb.New().SetParsedBlockSeq(tx, 0)
+	})
+	require.NoError(t, err)
+
+	_, approximate, err = v.GetAddressStats([]cipher.Address{addr})
+	require.NoError(t, err)
+	require.True(t, approximate)
+}
@@ -0,0 +1,185 @@
+package visor
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// addressSubscriptionBufferSize is the size of the channel buffer of an address subscription
+const addressSubscriptionBufferSize = 100
+
+// AddressEvent is sent to an address subscription when a transaction creates outputs for its addresses
+type AddressEvent struct {
+	Transaction coin.Transaction
+	// Addresses are the subscribed addresses that the transaction sends outputs to
+	Addresses []cipher.Address
+	// Confirmed is false when the transaction is added to the unconfirmed pool,
+	// and true when it is executed in a block
+	Confirmed bool
+	// BlockSeq is the seq of the block of a confirmed transaction
+	BlockSeq uint64
+}
+
+// CancelFunc cancels a subscription and closes its channel
+type CancelFunc func()
+
+type addressSubscription struct {
+	addrs []cipher.Address
+	c     chan AddressEvent
+}
+
+// addressSubscriptions is the registry of the address subscriptions,
+// indexed by address so that each output of a transaction is matched to the subscriptions in O(1)
+type addressSubscriptions struct {
+	subs      map[uint64]*addressSubscription
+	byAddress map[cipher.Address]map[uint64]struct{}
+	nextID    uint64
+	sync.Mutex
+}
+
+func (s *addressSubscriptions) subscribe(addrs []cipher.Address) (<-chan AddressEvent, CancelFunc) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.subs == nil {
+		s.subs = make(map[uint64]*addressSubscription)
+		s.byAddress = make(map[cipher.Address]map[uint64]struct{})
+	}
+
+	s.nextID++
+	id := s.nextID
+
+	sub := &addressSubscription{
+		c: make(chan AddressEvent, addressSubscriptionBufferSize),
+	}
+
+	for _, a := range addrs {
+		ids := s.byAddress[a]
+		if ids == nil {
+			ids = make(map[uint64]struct{})
+			s.byAddress[a] = ids
+		}
+
+		if _, ok := ids[id]; ok {
+			continue
+		}
+
+		ids[id] = struct{}{}
+		sub.addrs = append(sub.addrs, a)
+	}
+
+	s.subs[id] = sub
+
+	var once sync.Once
+	return sub.c, func() {
+		once.Do(func() {
+			s.cancel(id)
+		})
+	}
+}
+
+func (s *addressSubscriptions) cancel(id uint64) {
+	s.Lock()
+	defer s.Unlock()
+
+	sub := s.subs[id]
+	if sub == nil {
+		return
+	}
+
+	for _, a := range sub.addrs {
+		ids := s.byAddress[a]
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(s.byAddress, a)
+		}
+	}
+
+	delete(s.subs, id)
+	close(sub.c)
+}
+
+// notifyBlock sends the events of the transactions of an executed block
+func (s *addressSubscriptions) notifyBlock(b coin.Block) {
+	for _, txn := range b.Body.Transactions {
+		s.notify(txn, true, b.Seq())
+	}
+}
+
+// notify sends an event to each subscription of an address that the transaction sends outputs to.
+// The send does not block; if the channel buffer of a subscription is full, the event is dropped
+func (s *addressSubscriptions) notify(txn coin.Transaction, confirmed bool, seq uint64) {
+	// Visors created without New have no subscriptions
+	if s == nil {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if len(s.byAddress) == 0 {
+		return
+	}
+
+	var matches map[uint64][]cipher.Address
+	for _, o := range txn.Out {
+		for id := range s.byAddress[o.Address] {
+			if matches == nil {
+				matches = make(map[uint64][]cipher.Address)
+			}
+
+			addrs := matches[id]
+			if len(addrs) != 0 && hasAddress(addrs, o.Address) {
+				continue
+			}
+			matches[id] = append(addrs, o.Address)
+		}
+	}
+
+	for id, addrs := range matches {
+		e := AddressEvent{
+			Transaction: txn,
+			Addresses:   addrs,
+			Confirmed:   confirmed,
+		}
+		if confirmed {
+			e.BlockSeq = seq
+		}
+
+		select {
+		case s.subs[id].c <- e:
+		default:
+			logger.WithFields(logrus.Fields{
+				"txid":      txn.Hash().Hex(),
+				"confirmed": confirmed,
+			}).Warning("Address subscription channel is full, dropping event")
+		}
+	}
+}
+
+func hasAddress(addrs []cipher.Address, addr cipher.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribe subscribes to the transactions that send outputs to any of the addresses.
+// An AddressEvent is sent when such a transaction is added to the unconfirmed pool, and again when it is executed in a block.
+// Events are not blocking: the channel is buffered, and events are dropped if the buffer is full,
+// so the channel must be read promptly. The CancelFunc removes the subscription and closes the channel.
+func (vs *Visor) Subscribe(addrs []cipher.Address) (<-chan AddressEvent, CancelFunc) {
+	return vs.addressSubscriptions.subscribe(addrs)
+}
+
+// NotifyUnconfirmedTransaction sends the events of a transaction added to the unconfirmed pool by InjectUserTransactionTx.
+// It must be called after the database transaction of InjectUserTransactionTx is committed
+func (vs *Visor) NotifyUnconfirmedTransaction(txn coin.Transaction) {
+	vs.addressSubscriptions.notify(txn, false, 0)
+}
@@ -0,0 +1,144 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+)
+
+func requireNoAddressEvent(t *testing.T, c <-chan AddressEvent) {
+	select {
+	case e := <-c:
+		t.Fatalf("Unexpected address event for txn %s", e.Transaction.Hash().Hex())
+	default:
+	}
+}
+
+func TestVisorSubscribe(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolConfig{})
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = genSecret
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:               cfg,
+		unconfirmed:          unconfirmed,
+		blockchain:           bc,
+		db:                   db,
+		history:              historydb.New(),
+		blockProducer:        DefaultBlockProducer{},
+		addressSubscriptions: &addressSubscriptions{},
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	toAddr := testutil.MakeAddress()
+	otherAddr := testutil.MakeAddress()
+
+	c, cancel := v.Subscribe([]cipher.Address{toAddr, toAddr})
+	otherC, otherCancel := v.Subscribe([]cipher.Address{otherAddr})
+	genesisC, genesisCancel := v.Subscribe([]cipher.Address{genAddress, toAddr})
+	defer otherCancel()
+	defer genesisCancel()
+
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, toAddr, 1e6)
+
+	// Admission to the unconfirmed pool sends an unconfirmed event
+	known, softErr, err := v.InjectForeignTransaction(txn)
+	require.NoError(t, err)
+	require.Nil(t, softErr)
+	require.False(t, known)
+
+	e := <-c
+	require.Equal(t, AddressEvent{
+		Transaction: txn,
+		Addresses:   []cipher.Address{toAddr},
+	}, e)
+
+	// The change output is sent to the genesis address
+	e = <-genesisC
+	require.Equal(t, AddressEvent{
+		Transaction: txn,
+		Addresses:   []cipher.Address{toAddr, genAddress},
+	}, e)
+
+	requireNoAddressEvent(t, otherC)
+
+	// A transaction already in the pool does not send an event
+	known, _, err = v.InjectForeignTransaction(txn)
+	require.NoError(t, err)
+	require.True(t, known)
+	requireNoAddressEvent(t, c)
+
+	// Execution in a block sends a confirmed event
+	sb, err := v.CreateAndExecuteBlock()
+	require.NoError(t, err)
+	require.Len(t, sb.Body.Transactions, 1)
+
+	e = <-c
+	require.Equal(t, AddressEvent{
+		Transaction: txn,
+		Addresses:   []cipher.Address{toAddr},
+		Confirmed:   true,
+		BlockSeq:    1,
+	}, e)
+
+	e = <-genesisC
+	require.True(t, e.Confirmed)
+
+	requireNoAddressEvent(t, otherC)
+
+	// Cancel closes the channel and can be called more than once
+	cancel()
+	cancel()
+	_, ok := <-c
+	require.False(t, ok)
+
+	require.Len(t, v.addressSubscriptions.subs, 2)
+	require.Len(t, v.addressSubscriptions.byAddress[toAddr], 1)
+	require.Len(t, v.addressSubscriptions.byAddress[otherAddr], 1)
+
+	otherCancel()
+	require.Len(t, v.addressSubscriptions.subs, 1)
+	_, ok = v.addressSubscriptions.byAddress[otherAddr]
+	require.False(t, ok)
+}
+
+func TestAddressSubscriptionsNotifyFull(t *testing.T) {
+	var s addressSubscriptions
+
+	addr := testutil.MakeAddress()
+	c, cancel := s.subscribe([]cipher.Address{addr})
+	defer cancel()
+
+	txn := coin.Transaction{
+		Out: []coin.TransactionOutput{
+			{Address: addr, Coins: 1e6},
+		},
+	}
+
+	// Events are dropped instead of blocking when the channel buffer is full
+	for i := 0; i < addressSubscriptionBufferSize+1; i++ {
+		s.notify(txn, false, 0)
+	}
+
+	require.Len(t, c, addressSubscriptionBufferSize)
+}
@@ -0,0 +1,86 @@
+package visor
+
+import (
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// FindBlockByTime returns the block whose timestamp is closest to t, reading the blockchain from db
+// without loading a Visor, so that it can be used on the database of a stopped node.
+// If two blocks are equally close, the earlier one is returned.
+// Returns nil if the database has no blocks.
+func FindBlockByTime(db *dbutil.DB, t uint64) (*coin.SignedBlock, error) {
+	bc, err := NewBlockchain(db, BlockchainConfig{})
+	if err != nil {
+		return nil, err
+	}
+
+	var block *coin.SignedBlock
+	if err := db.View("FindBlockByTime", func(tx *dbutil.Tx) error {
+		// A new database has no blocks
+		if !dbutil.Exists(tx, blockdb.BlocksBkt) {
+			return nil
+		}
+
+		headSeq, ok, err := bc.HeadSeq(tx)
+		if err != nil {
+			return err
+		} else if !ok {
+			return nil
+		}
+
+		getBlock := func(seq uint64) (*coin.SignedBlock, error) {
+			b, err := bc.GetSignedBlockBySeq(tx, seq)
+			if err != nil {
+				return nil, err
+			}
+			if b == nil {
+				return nil, fmt.Errorf("block %d not found", seq)
+			}
+			return b, nil
+		}
+
+		// Block times increase with the block sequence, so binary search
+		// for the first block whose time is not before t
+		lo, hi := uint64(0), headSeq
+		for lo < hi {
+			mid := lo + (hi-lo)/2
+			b, err := getBlock(mid)
+			if err != nil {
+				return err
+			}
+
+			if b.Time() < t {
+				lo = mid + 1
+			} else {
+				hi = mid
+			}
+		}
+
+		block, err = getBlock(lo)
+		if err != nil {
+			return err
+		}
+
+		// The block before it may be closer
+		if lo > 0 && block.Time() > t {
+			prev, err := getBlock(lo - 1)
+			if err != nil {
+				return err
+			}
+
+			if t-prev.Time() <= block.Time()-t {
+				block = prev
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return block, nil
+}
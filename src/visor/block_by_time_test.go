@@ -0,0 +1,68 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestFindBlockByTime(t *testing.T) {
+	v, shutdown := newChainExportTestVisor(t)
+	defer shutdown()
+
+	// An empty blockchain has no blocks
+	b, err := FindBlockByTime(v.db, genTime)
+	require.NoError(t, err)
+	require.Nil(t, b)
+
+	// Create blocks 100 seconds apart, each spending the output of the previous block's transaction
+	gb := addGenesisBlockToVisor(t, v)
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	for i := 1; i <= 6; i++ {
+		txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, uxs[0].Body.Coins)
+
+		err := v.db.Update("", func(tx *dbutil.Tx) error {
+			b, err := v.blockchain.NewBlock(tx, coin.Transactions{txn}, genTime+uint64(i)*100)
+			require.NoError(t, err)
+
+			if err := v.executeSignedBlock(tx, coin.SignedBlock{
+				Block: *b,
+				Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+			}); err != nil {
+				return err
+			}
+
+			uxs = coin.CreateUnspents(b.Head, txn)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	cases := []struct {
+		name string
+		time uint64
+		seq  uint64
+	}{
+		{"before genesis", 0, 0},
+		{"genesis", genTime, 0},
+		{"exact", genTime + 300, 3},
+		{"closer to previous", genTime + 340, 3},
+		{"closer to next", genTime + 360, 4},
+		{"equally close", genTime + 450, 4},
+		{"head", genTime + 600, 6},
+		{"after head", genTime + 100000, 6},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := FindBlockByTime(v.db, tc.time)
+			require.NoError(t, err)
+			require.NotNil(t, b)
+			require.Equal(t, tc.seq, b.Seq())
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package visor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// DefaultBlockProducerName is the name of the block producer plugin that implements the standard block production rules
+const DefaultBlockProducerName = "default"
+
+var (
+	// ErrBlockProducerCannotProduce is returned if the block producer plugin does not allow this node to produce the next block
+	ErrBlockProducerCannotProduce = errors.New("Block producer plugin does not allow producing a block at this height")
+	// ErrBlockProducerNoTransactions is returned if the block producer plugin selected no transactions for the block
+	ErrBlockProducerNoTransactions = errors.New("Block producer plugin selected no transactions")
+	// ErrBlockProducerInvalidSelection is returned if the transactions selected by the block producer plugin
+	// are not a subset of the pool, or do not fit in a block
+	ErrBlockProducerInvalidSelection = errors.New("Block producer plugin selected invalid transactions")
+
+	blockProducersLock sync.RWMutex
+	blockProducers     = map[string]BlockProducerPlugin{
+		DefaultBlockProducerName: DefaultBlockProducer{},
+	}
+)
+
+// BlockProducerPlugin decides when a block publisher node may produce a block and which
+// transactions go into it, so that deployments can apply their own block production rules
+// (e.g. PoA or DPoS) without forking the visor.
+// Transactions are checked against the transaction constraints and sorted by fee before
+// being passed to SelectTransactions.
+type BlockProducerPlugin interface {
+	// CanProduce returns true if the block publisher with pubKey may produce the block at height
+	CanProduce(height uint64, pubKey cipher.PubKey) bool
+	// SelectTransactions returns the transactions to include in a block, from a pool of
+	// valid transactions ordered by fee. The total size of the selected transactions must not exceed maxSize bytes.
+	// No block is created if the selection contains transactions that are not in the pool, or does not fit in a block.
+	SelectTransactions(pool coin.Transactions, maxSize int) coin.Transactions
+}
+
+// RegisterBlockProducer registers a block producer plugin under a name, so that it can be selected by Config.BlockProducer.
+// Plugins must be registered at startup, before the visor is created.
+func RegisterBlockProducer(name string, p BlockProducerPlugin) error {
+	if name == "" {
+		return errors.New("Block producer name must not be empty")
+	}
+	if p == nil {
+		return errors.New("Block producer must not be nil")
+	}
+
+	blockProducersLock.Lock()
+	defer blockProducersLock.Unlock()
+
+	if _, ok := blockProducers[name]; ok {
+		return fmt.Errorf("Block producer %q is already registered", name)
+	}
+
+	blockProducers[name] = p
+	return nil
+}
+
+// GetBlockProducer returns the block producer plugin registered under a name
+func GetBlockProducer(name string) (BlockProducerPlugin, error) {
+	blockProducersLock.RLock()
+	defer blockProducersLock.RUnlock()
+
+	p, ok := blockProducers[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown block producer %q", name)
+	}
+
+	return p, nil
+}
+
+// BlockProducers returns the names of the registered block producer plugins
+func BlockProducers() []string {
+	blockProducersLock.RLock()
+	defer blockProducersLock.RUnlock()
+
+	names := make([]string, 0, len(blockProducers))
+	for name := range blockProducers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// DefaultBlockProducer implements the standard block production rules.
+// The block publisher can produce every block, and blocks are filled with the highest fee
+// transactions up to the maximum block size and coin.MaxBlockTransactions.
+type DefaultBlockProducer struct{}
+
+// CanProduce always returns true
+func (DefaultBlockProducer) CanProduce(height uint64, pubKey cipher.PubKey) bool {
+	return true
+}
+
+// SelectTransactions returns the leading transactions of the pool that fit in maxSize bytes, up to coin.MaxBlockTransactions
+func (DefaultBlockProducer) SelectTransactions(pool coin.Transactions, maxSize int) coin.Transactions {
+	txns, err := pool.TruncateBytesTo(uint32(maxSize))
+	if err != nil {
+		logger.Critical().WithError(err).Error("TruncateBytesTo failed, no block can be made until the offending transaction is removed")
+		return nil
+	}
+
+	if len(txns) > coin.MaxBlockTransactions {
+		txns = txns[:coin.MaxBlockTransactions]
+	}
+
+	return txns
+}
+
+// verifySelectedTransactions checks that the transactions selected by a block producer plugin
+// are distinct transactions of the pool, and that they fit in a block of maxSize bytes
+func verifySelectedTransactions(pool, selected coin.Transactions, maxSize uint32) error {
+	if len(selected) > coin.MaxBlockTransactions {
+		logger.Errorf("Block producer selected %d transactions, more than the maximum of %d", len(selected), coin.MaxBlockTransactions)
+		return ErrBlockProducerInvalidSelection
+	}
+
+	size, err := selected.Size()
+	if err != nil {
+		return err
+	}
+	if size > maxSize {
+		logger.Errorf("Block producer selected %d bytes of transactions, more than the maximum block size of %d", size, maxSize)
+		return ErrBlockProducerInvalidSelection
+	}
+
+	inPool := make(map[cipher.SHA256]struct{}, len(pool))
+	for _, h := range pool.Hashes() {
+		inPool[h] = struct{}{}
+	}
+
+	for _, h := range selected.Hashes() {
+		if _, ok := inPool[h]; !ok {
+			logger.Errorf("Block producer selected transaction %s, which is not in the pool or was selected twice", h.Hex())
+			return ErrBlockProducerInvalidSelection
+		}
+		delete(inPool, h)
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+type fakeBlockProducer struct {
+	pubKey cipher.PubKey
+}
+
+func (p fakeBlockProducer) CanProduce(height uint64, pubKey cipher.PubKey) bool {
+	return pubKey == p.pubKey && height%2 == 0
+}
+
+func (p fakeBlockProducer) SelectTransactions(pool coin.Transactions, maxSize int) coin.Transactions {
+	return pool
+}
+
+func TestRegisterBlockProducer(t *testing.T) {
+	p, err := GetBlockProducer(DefaultBlockProducerName)
+	require.NoError(t, err)
+	require.Equal(t, DefaultBlockProducer{}, p)
+
+	_, err = GetBlockProducer("fake")
+	require.Equal(t, `Unknown block producer "fake"`, err.Error())
+
+	err = RegisterBlockProducer(DefaultBlockProducerName, fakeBlockProducer{})
+	require.Equal(t, `Block producer "default" is already registered`, err.Error())
+
+	err = RegisterBlockProducer("", fakeBlockProducer{})
+	require.Error(t, err)
+
+	err = RegisterBlockProducer("fake", nil)
+	require.Error(t, err)
+
+	pubKey, _ := cipher.GenerateKeyPair()
+	err = RegisterBlockProducer("fake", fakeBlockProducer{pubKey: pubKey})
+	require.NoError(t, err)
+	defer func() {
+		blockProducersLock.Lock()
+		delete(blockProducers, "fake")
+		blockProducersLock.Unlock()
+	}()
+
+	require.Equal(t, []string{DefaultBlockProducerName, "fake"}, BlockProducers())
+
+	p, err = GetBlockProducer("fake")
+	require.NoError(t, err)
+	require.True(t, p.CanProduce(2, pubKey))
+	require.False(t, p.CanProduce(3, pubKey))
+	require.False(t, p.CanProduce(2, cipher.PubKey{}))
+}
+
+func TestDefaultBlockProducerSelectTransactions(t *testing.T) {
+	var txns coin.Transactions
+	for i := 0; i < 3; i++ {
+		txns = append(txns, coin.Transaction{
+			Out: []coin.TransactionOutput{
+				{
+					Address: testutil.MakeAddress(),
+					Coins:   1e6,
+					Hours:   uint64(i),
+				},
+			},
+		})
+	}
+
+	p := DefaultBlockProducer{}
+	require.True(t, p.CanProduce(1, cipher.PubKey{}))
+
+	size, err := txns.Size()
+	require.NoError(t, err)
+	require.Equal(t, txns, p.SelectTransactions(txns, int(size)))
+
+	txnSize, err := txns[0].Size()
+	require.NoError(t, err)
+	require.Equal(t, txns[:2], p.SelectTransactions(txns, int(size-txnSize)))
+
+	require.Empty(t, p.SelectTransactions(txns, int(txnSize-1)))
+}
+
+func TestVerifySelectedTransactions(t *testing.T) {
+	var pool coin.Transactions
+	for i := 0; i < 3; i++ {
+		pool = append(pool, coin.Transaction{
+			Out: []coin.TransactionOutput{
+				{
+					Address: testutil.MakeAddress(),
+					Coins:   1e6,
+					Hours:   uint64(i),
+				},
+			},
+		})
+	}
+
+	size, err := pool.Size()
+	require.NoError(t, err)
+
+	notInPool := coin.Transaction{
+		Out: []coin.TransactionOutput{
+			{
+				Address: testutil.MakeAddress(),
+				Coins:   1e6,
+			},
+		},
+	}
+
+	tooMany := make(coin.Transactions, coin.MaxBlockTransactions+1)
+
+	cases := []struct {
+		name     string
+		selected coin.Transactions
+		maxSize  uint32
+		err      error
+	}{
+		{
+			name:     "whole pool",
+			selected: pool,
+			maxSize:  size,
+		},
+		{
+			name:     "subset out of order",
+			selected: coin.Transactions{pool[2], pool[0]},
+			maxSize:  size,
+		},
+		{
+			name:     "too large",
+			selected: pool,
+			maxSize:  size - 1,
+			err:      ErrBlockProducerInvalidSelection,
+		},
+		{
+			name:     "too many transactions",
+			selected: tooMany,
+			maxSize:  ^uint32(0),
+			err:      ErrBlockProducerInvalidSelection,
+		},
+		{
+			name:     "not in pool",
+			selected: coin.Transactions{pool[0], notInPool},
+			maxSize:  size * 2,
+			err:      ErrBlockProducerInvalidSelection,
+		},
+		{
+			name:     "duplicate",
+			selected: coin.Transactions{pool[1], pool[1]},
+			maxSize:  size,
+			err:      ErrBlockProducerInvalidSelection,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifySelectedTransactions(pool, tc.selected, tc.maxSize)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package visor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// BlockSizeHistogramBkt stores the block size histogram
+var BlockSizeHistogramBkt = []byte("block_size_histogram")
+
+var blockSizeHistogramKey = []byte("histogram")
+
+// DefaultBlockSizeHistogramBuckets are the default upper bounds of the buckets of the block size histogram, in bytes
+var DefaultBlockSizeHistogramBuckets = []uint32{256, 512, 1024, 2048, 4096, 8192, 16384, 32768}
+
+// BlockSizeHistogram counts the blocks by the size of their transactions.
+// It is kept up to date as blocks are executed and stored in the database.
+type BlockSizeHistogram struct {
+	// Upper bounds of the buckets, in bytes, in increasing order
+	Buckets []uint32
+	// Number of blocks in each bucket. Counts[i] is the number of blocks larger than Buckets[i-1]
+	// and at most Buckets[i]. The last count is the number of blocks larger than the last bucket
+	Counts []uint64
+	// Number of blocks counted
+	Count uint64
+	// Sum of the sizes of the blocks counted
+	Sum uint64
+	// Seqs of the first and last blocks counted. Only set if Count is not 0
+	FromSeq uint64
+	ToSeq   uint64
+}
+
+func newBlockSizeHistogram(buckets []uint32) *BlockSizeHistogram {
+	return &BlockSizeHistogram{
+		Buckets: append([]uint32{}, buckets...),
+		Counts:  make([]uint64, len(buckets)+1),
+	}
+}
+
+// add counts a block of the given seq and size. Blocks must be added in sequence
+func (h *BlockSizeHistogram) add(seq uint64, size uint32) {
+	i := sort.Search(len(h.Buckets), func(i int) bool {
+		return size <= h.Buckets[i]
+	})
+	h.Counts[i]++
+
+	if h.Count == 0 {
+		h.FromSeq = seq
+	}
+	h.ToSeq = seq
+	h.Count++
+	h.Sum += uint64(size)
+}
+
+func (h *BlockSizeHistogram) hasBuckets(buckets []uint32) bool {
+	if len(h.Buckets) != len(buckets) {
+		return false
+	}
+
+	for i, b := range buckets {
+		if h.Buckets[i] != b {
+			return false
+		}
+	}
+
+	return true
+}
+
+// verifyBlockSizeHistogramBuckets checks that the buckets are in increasing order
+func verifyBlockSizeHistogramBuckets(buckets []uint32) error {
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			return errors.New("BlockSizeHistogramBuckets must be in increasing order")
+		}
+	}
+
+	return nil
+}
+
+func getBlockSizeHistogram(tx *dbutil.Tx) (*BlockSizeHistogram, error) {
+	var h BlockSizeHistogram
+	if ok, err := dbutil.GetBucketObjectDecoded(tx, BlockSizeHistogramBkt, blockSizeHistogramKey, &h); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, nil
+	}
+
+	if len(h.Counts) != len(h.Buckets)+1 {
+		return nil, fmt.Errorf("block size histogram has %d counts for %d buckets", len(h.Counts), len(h.Buckets))
+	}
+
+	return &h, nil
+}
+
+func setBlockSizeHistogram(tx *dbutil.Tx, h *BlockSizeHistogram) error {
+	return dbutil.PutBucketValue(tx, BlockSizeHistogramBkt, blockSizeHistogramKey, encoder.Serialize(*h))
+}
+
+// addBlockSize counts a block in the block size histogram stored in the database
+func addBlockSize(tx *dbutil.Tx, seq uint64, size uint32, buckets []uint32) error {
+	h, err := getBlockSizeHistogram(tx)
+	if err != nil {
+		return err
+	}
+	if h == nil {
+		h = newBlockSizeHistogram(buckets)
+	}
+
+	h.add(seq, size)
+
+	return setBlockSizeHistogram(tx, h)
+}
+
+// initBlockSizeHistogram counts the blocks up to the head block that are not in the block size histogram yet.
+// The histogram is rebuilt if its buckets have been changed.
+// Pruned blocks are not counted, since the size of their transactions can't be known.
+func initBlockSizeHistogram(tx *dbutil.Tx, bc Blockchainer, buckets []uint32, headSeq uint64, hasHead bool) error {
+	h, err := getBlockSizeHistogram(tx)
+	if err != nil {
+		return err
+	}
+
+	if h != nil && !h.hasBuckets(buckets) {
+		logger.Info("Block size histogram buckets changed, rebuilding the histogram")
+		h = nil
+	}
+	if h == nil {
+		h = newBlockSizeHistogram(buckets)
+	}
+
+	start := uint64(0)
+	if h.Count != 0 {
+		start = h.ToSeq + 1
+	}
+
+	prunedSeq, pruned, err := bc.PrunedSeq(tx)
+	if err != nil {
+		return err
+	}
+	if pruned && start <= prunedSeq {
+		start = prunedSeq + 1
+	}
+
+	if hasHead && start <= headSeq {
+		logger.Infof("Counting the sizes of blocks %d to %d in the block size histogram", start, headSeq)
+
+		for seq := start; seq <= headSeq; seq++ {
+			b, err := bc.GetSignedBlockBySeq(tx, seq)
+			if err != nil {
+				return err
+			}
+			if b == nil {
+				return fmt.Errorf("no block exists in depth: %d", seq)
+			}
+
+			size, err := b.Block.Size()
+			if err != nil {
+				return err
+			}
+
+			h.add(seq, size)
+		}
+	}
+
+	return setBlockSizeHistogram(tx, h)
+}
+
+// BlockchainCapacity is the block size histogram and the maximum size of the next block
+type BlockchainCapacity struct {
+	// Maximum size of the transactions of the next block, with the active signaling deployments applied
+	MaxBlockSize uint32
+	Histogram    BlockSizeHistogram
+}
+
+// GetBlockchainCapacity returns the block size histogram and the maximum size of the next block
+func (vs *Visor) GetBlockchainCapacity() (*BlockchainCapacity, error) {
+	var c *BlockchainCapacity
+	if err := vs.db.View("GetBlockchainCapacity", func(tx *dbutil.Tx) error {
+		var err error
+		c, err = vs.getBlockchainCapacity(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (vs *Visor) getBlockchainCapacity(tx *dbutil.Tx) (*BlockchainCapacity, error) {
+	maxBlockSize := vs.Config.MaxBlockTransactionsSize
+
+	headSeq, ok, err := vs.blockchain.HeadSeq(tx)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		_, maxBlockSize, err = vs.createBlockParams(tx, headSeq+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// A read-only database created before the histogram has no bucket
+	var h *BlockSizeHistogram
+	if dbutil.Exists(tx, BlockSizeHistogramBkt) {
+		h, err = getBlockSizeHistogram(tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if h == nil {
+		h = newBlockSizeHistogram(vs.Config.BlockSizeHistogramBuckets)
+	}
+
+	return &BlockchainCapacity{
+		MaxBlockSize: maxBlockSize,
+		Histogram:    *h,
+	}, nil
+}
@@ -0,0 +1,131 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestBlockSizeHistogramAdd(t *testing.T) {
+	h := newBlockSizeHistogram([]uint32{100, 200})
+
+	h.add(3, 0)
+	h.add(4, 100)
+	h.add(5, 101)
+	h.add(6, 200)
+	h.add(7, 201)
+
+	require.Equal(t, &BlockSizeHistogram{
+		Buckets: []uint32{100, 200},
+		Counts:  []uint64{2, 2, 1},
+		Count:   5,
+		Sum:     602,
+		FromSeq: 3,
+		ToSeq:   7,
+	}, h)
+}
+
+func TestVerifyBlockSizeHistogramBuckets(t *testing.T) {
+	require.NoError(t, verifyBlockSizeHistogramBuckets(nil))
+	require.NoError(t, verifyBlockSizeHistogramBuckets([]uint32{1, 2}))
+	require.EqualError(t, verifyBlockSizeHistogramBuckets([]uint32{2, 2}), "BlockSizeHistogramBuckets must be in increasing order")
+	require.EqualError(t, verifyBlockSizeHistogramBuckets([]uint32{3, 2}), "BlockSizeHistogramBuckets must be in increasing order")
+}
+
+func newBlockSizeHistogramTestVisor(t *testing.T, db *dbutil.DB, buckets []uint32) *Visor {
+	cfg := NewConfig()
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+	cfg.Distribution = params.MainNetDistribution
+	cfg.BlockSizeHistogramBuckets = buckets
+
+	v, err := New(cfg, db, nil)
+	require.NoError(t, err)
+
+	return v
+}
+
+func TestGetBlockchainCapacity(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := newBlockSizeHistogramTestVisor(t, db, []uint32{100, 1000})
+
+	c, err := v.GetBlockchainCapacity()
+	require.NoError(t, err)
+	require.Equal(t, &BlockchainCapacity{
+		MaxBlockSize: v.Config.MaxBlockTransactionsSize,
+		Histogram: BlockSizeHistogram{
+			Buckets: []uint32{100, 1000},
+			Counts:  []uint64{0, 0, 0},
+		},
+	}, c)
+
+	gb := addGenesisBlockToVisor(t, v)
+	gbSize, err := gb.Block.Size()
+	require.NoError(t, err)
+
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, testutil.MakeAddress(), 100e6)
+	txnSize, err := txn.Size()
+	require.NoError(t, err)
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		b, err := v.blockchain.NewBlock(tx, coin.Transactions{txn}, genTime+100)
+		require.NoError(t, err)
+
+		return v.executeSignedBlock(tx, coin.SignedBlock{
+			Block: *b,
+			Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+		})
+	})
+	require.NoError(t, err)
+
+	// The genesis block has one output and the spend transaction has two
+	require.True(t, gbSize <= 100)
+	require.True(t, txnSize > 100 && txnSize <= 1000)
+
+	expect := &BlockchainCapacity{
+		MaxBlockSize: v.Config.MaxBlockTransactionsSize,
+		Histogram: BlockSizeHistogram{
+			Buckets: []uint32{100, 1000},
+			Counts:  []uint64{1, 1, 0},
+			Count:   2,
+			Sum:     uint64(gbSize) + uint64(txnSize),
+			FromSeq: 0,
+			ToSeq:   1,
+		},
+	}
+
+	c, err = v.GetBlockchainCapacity()
+	require.NoError(t, err)
+	require.Equal(t, expect, c)
+
+	// Restarting with the same buckets keeps the histogram
+	v = newBlockSizeHistogramTestVisor(t, db, []uint32{100, 1000})
+	c, err = v.GetBlockchainCapacity()
+	require.NoError(t, err)
+	require.Equal(t, expect, c)
+
+	// Restarting with other buckets rebuilds the histogram
+	v = newBlockSizeHistogramTestVisor(t, db, []uint32{50})
+	c, err = v.GetBlockchainCapacity()
+	require.NoError(t, err)
+	require.Equal(t, &BlockchainCapacity{
+		MaxBlockSize: v.Config.MaxBlockTransactionsSize,
+		Histogram: BlockSizeHistogram{
+			Buckets: []uint32{50},
+			Counts:  []uint64{0, 2},
+			Count:   2,
+			Sum:     uint64(gbSize) + uint64(txnSize),
+			FromSeq: 0,
+			ToSeq:   1,
+		},
+	}, c)
+}
@@ -0,0 +1,92 @@
+package visor
+
+import (
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// BlockStats are the statistics of a single block
+type BlockStats struct {
+	Height  uint64
+	TxCount int
+	// TotalCoinHoursBurned is the sum of the fees of the block's transactions, which are all burned
+	TotalCoinHoursBurned uint64
+	// SizeBytes is the size of the block's transactions
+	SizeBytes     uint32
+	TimestampUnix uint64
+}
+
+// StatsByHeight returns the statistics of the blocks between start and end, including both start and end.
+// Blocks after the head block are not included.
+// Returns ErrBlockPruned if the transactions of any of the blocks have been pruned.
+func StatsByHeight(db *dbutil.DB, start, end uint64) ([]BlockStats, error) {
+	bc, err := NewBlockchain(db, BlockchainConfig{})
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []BlockStats
+	if err := db.View("StatsByHeight", func(tx *dbutil.Tx) error {
+		var err error
+		stats, err = statsByHeight(tx, bc, start, end)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// StatsByHeight returns the statistics of the blocks between start and end, including both start and end
+func (vs *Visor) StatsByHeight(start, end uint64) ([]BlockStats, error) {
+	var stats []BlockStats
+	if err := vs.db.View("StatsByHeight", func(tx *dbutil.Tx) error {
+		var err error
+		stats, err = statsByHeight(tx, vs.blockchain, start, end)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func statsByHeight(tx *dbutil.Tx, bc Blockchainer, start, end uint64) ([]BlockStats, error) {
+	if start > end {
+		return nil, nil
+	}
+
+	var stats []BlockStats
+	for seq := start; seq <= end; seq++ {
+		b, err := bc.GetSignedBlockBySeq(tx, seq)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			break
+		}
+
+		if err := checkPruned(tx, bc, seq); err != nil {
+			return nil, err
+		}
+
+		size, err := b.Size()
+		if err != nil {
+			return nil, err
+		}
+
+		stats = append(stats, BlockStats{
+			Height:               seq,
+			TxCount:              len(b.Body.Transactions),
+			TotalCoinHoursBurned: b.Head.Fee,
+			SizeBytes:            size,
+			TimestampUnix:        b.Head.Time,
+		})
+
+		// Avoid overflow of seq if end is math.MaxUint64
+		if seq == end {
+			break
+		}
+	}
+
+	return stats, nil
+}
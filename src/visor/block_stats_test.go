@@ -0,0 +1,143 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestStatsByHeight(t *testing.T) {
+	txn := coin.Transaction{
+		In: []cipher.SHA256{testutil.RandSHA256(t)},
+		Out: []coin.TransactionOutput{
+			{
+				Address: testutil.MakeAddress(),
+				Coins:   1e6,
+				Hours:   10,
+			},
+		},
+	}
+	txnSize, err := txn.Size()
+	require.NoError(t, err)
+
+	blocks := []*coin.SignedBlock{
+		{
+			Block: coin.Block{
+				Head: coin.BlockHeader{BkSeq: 0, Time: 1000},
+			},
+		},
+		{
+			Block: coin.Block{
+				Head: coin.BlockHeader{BkSeq: 1, Time: 1010, Fee: 20},
+				Body: coin.BlockBody{Transactions: coin.Transactions{txn}},
+			},
+		},
+		{
+			Block: coin.Block{
+				Head: coin.BlockHeader{BkSeq: 2, Time: 1020, Fee: 50},
+				Body: coin.BlockBody{Transactions: coin.Transactions{txn, txn}},
+			},
+		},
+	}
+
+	allStats := []BlockStats{
+		{
+			Height:        0,
+			TimestampUnix: 1000,
+		},
+		{
+			Height:               1,
+			TxCount:              1,
+			TotalCoinHoursBurned: 20,
+			SizeBytes:            txnSize,
+			TimestampUnix:        1010,
+		},
+		{
+			Height:               2,
+			TxCount:              2,
+			TotalCoinHoursBurned: 50,
+			SizeBytes:            txnSize * 2,
+			TimestampUnix:        1020,
+		},
+	}
+
+	cases := []struct {
+		name      string
+		start     uint64
+		end       uint64
+		prunedSeq uint64
+		pruned    bool
+		stats     []BlockStats
+		err       error
+	}{
+		{
+			name:  "all blocks",
+			start: 0,
+			end:   2,
+			stats: allStats,
+		},
+		{
+			name:  "single block",
+			start: 1,
+			end:   1,
+			stats: allStats[1:2],
+		},
+		{
+			name:  "end after head",
+			start: 1,
+			end:   10,
+			stats: allStats[1:],
+		},
+		{
+			name:  "start after end",
+			start: 2,
+			end:   1,
+		},
+		{
+			name:      "pruned",
+			start:     0,
+			end:       2,
+			prunedSeq: 1,
+			pruned:    true,
+			err:       ErrBlockPruned{Seq: 1},
+		},
+	}
+
+	matchDBTx := mock.MatchedBy(func(tx *dbutil.Tx) bool {
+		return true
+	})
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, shutdown := testutil.PrepareDB(t)
+			defer shutdown()
+
+			bc := &MockBlockchainer{}
+			for i, b := range blocks {
+				bc.On("GetSignedBlockBySeq", matchDBTx, uint64(i)).Return(b, nil)
+			}
+			bc.On("GetSignedBlockBySeq", matchDBTx, mock.Anything).Return(nil, nil)
+			bc.On("PrunedSeq", matchDBTx).Return(tc.prunedSeq, tc.pruned, nil)
+
+			v := &Visor{
+				blockchain: bc,
+				db:         db,
+			}
+
+			stats, err := v.StatsByHeight(tc.start, tc.end)
+			if tc.err != nil {
+				require.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.stats, stats)
+		})
+	}
+}
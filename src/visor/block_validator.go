@@ -0,0 +1,118 @@
+package visor
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+var (
+	blockValidatorsLock sync.RWMutex
+	blockValidators     []BlockValidator
+)
+
+// BlockValidator checks a block against its previous block, so that deployments can apply their own
+// block validation rules (e.g. the block producer must be in an approved set) without forking the visor.
+// Validators are called after the default header checks and before the block's transactions are verified.
+type BlockValidator interface {
+	// Validate returns an error if the block must be rejected
+	Validate(block coin.Block, prevBlock coin.Block) error
+}
+
+// RegisterBlockValidator registers a block validator, which is called for every block after the
+// previously registered validators. Any error returned by a validator causes the block to be rejected.
+// Validators must be registered at startup, before the visor is created.
+func RegisterBlockValidator(v BlockValidator) error {
+	if v == nil {
+		return errors.New("Block validator must not be nil")
+	}
+
+	blockValidatorsLock.Lock()
+	defer blockValidatorsLock.Unlock()
+
+	blockValidators = append(blockValidators, v)
+	return nil
+}
+
+// ErrBlockIntervalTooShort is returned if a block's timestamp is less than BlockchainConfig.MinBlockInterval after the previous block's timestamp
+type ErrBlockIntervalTooShort struct {
+	Seq     uint64
+	Time    uint64
+	MinTime uint64
+}
+
+func (e ErrBlockIntervalTooShort) Error() string {
+	return fmt.Sprintf("block seq=%d timestamp %d is too soon after the previous block, the minimum is %d", e.Seq, e.Time, e.MinTime)
+}
+
+// validateBlock checks a block with DefaultBlockValidator and then with the registered block validators, in sequence
+func validateBlock(b, prevBlock coin.Block, minBlockInterval time.Duration) error {
+	if err := (DefaultBlockValidator{
+		MinBlockInterval: minBlockInterval,
+	}).Validate(b, prevBlock); err != nil {
+		return err
+	}
+
+	blockValidatorsLock.RLock()
+	defer blockValidatorsLock.RUnlock()
+
+	for _, v := range blockValidators {
+		if err := v.Validate(b, prevBlock); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DefaultBlockValidator implements the standard block header checks.
+// The block must follow prevBlock in sequence and in time, and its body hash must match its header.
+type DefaultBlockValidator struct {
+	// Blocks whose timestamp is less than this long after the previous block's timestamp are rejected. If 0, it is not checked
+	MinBlockInterval time.Duration
+}
+
+// Validate checks the block header against the previous block
+func (v DefaultBlockValidator) Validate(b coin.Block, prevBlock coin.Block) error {
+	//check BkSeq
+	if b.Head.BkSeq != prevBlock.Head.BkSeq+1 {
+		return errors.New("BkSeq invalid")
+	}
+	//check Time, only requirement is that its monotonely increasing
+	if b.Head.Time <= prevBlock.Head.Time {
+		return errors.New("Block time must be > head time")
+	}
+	if err := verifyBlockInterval(b.Head.BkSeq, b.Head.Time, prevBlock.Head.Time, v.MinBlockInterval); err != nil {
+		return err
+	}
+	// Check block hash against previous head
+	if b.Head.PrevHash != prevBlock.HashHeader() {
+		return errors.New("PrevHash does not match current head")
+	}
+
+	if b.Body.Hash() != b.Head.BodyHash {
+		return errors.New("Computed body hash does not match")
+	}
+	return nil
+}
+
+// verifyBlockInterval returns ErrBlockIntervalTooShort if t is less than minInterval after prevTime
+func verifyBlockInterval(seq, t, prevTime uint64, minInterval time.Duration) error {
+	if minInterval <= 0 {
+		return nil
+	}
+
+	minTime := prevTime + uint64(minInterval/time.Second)
+	if t < minTime {
+		return ErrBlockIntervalTooShort{
+			Seq:     seq,
+			Time:    t,
+			MinTime: minTime,
+		}
+	}
+
+	return nil
+}
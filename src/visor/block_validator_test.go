@@ -0,0 +1,151 @@
+package visor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+type fakeBlockValidator struct {
+	reject     bool
+	prevBlocks []coin.Block
+}
+
+func (v *fakeBlockValidator) Validate(b coin.Block, prevBlock coin.Block) error {
+	v.prevBlocks = append(v.prevBlocks, prevBlock)
+	if v.reject {
+		return errors.New("rejected by fake validator")
+	}
+	return nil
+}
+
+func TestRegisterBlockValidator(t *testing.T) {
+	require.Error(t, RegisterBlockValidator(nil))
+
+	v, shutdown := newChainExportTestVisor(t)
+	defer shutdown()
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	validator1 := &fakeBlockValidator{}
+	validator2 := &fakeBlockValidator{}
+	require.NoError(t, RegisterBlockValidator(validator1))
+	require.NoError(t, RegisterBlockValidator(validator2))
+	defer func() {
+		blockValidatorsLock.Lock()
+		blockValidators = nil
+		blockValidatorsLock.Unlock()
+	}()
+
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	executeBlock := func(seq uint64, reject bool) (*coin.Block, error) {
+		txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, uxs[0].Body.Coins)
+
+		var b *coin.Block
+		err := v.db.Update("", func(tx *dbutil.Tx) error {
+			var err error
+			b, err = v.blockchain.NewBlock(tx, coin.Transactions{txn}, genTime+seq*100)
+			require.NoError(t, err)
+
+			// NewBlock also validates the block if DebugLevel2 is enabled
+			validator1.prevBlocks = nil
+			validator2.prevBlocks = nil
+			validator1.reject = reject
+
+			if err := v.executeSignedBlock(tx, coin.SignedBlock{
+				Block: *b,
+				Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+			}); err != nil {
+				return err
+			}
+
+			uxs = coin.CreateUnspents(b.Head, txn)
+			return nil
+		})
+		return b, err
+	}
+
+	b1, err := executeBlock(1, false)
+	require.NoError(t, err)
+	// The validators are called in sequence with the previous block
+	require.Equal(t, []coin.Block{gb.Block}, validator1.prevBlocks)
+	require.Equal(t, []coin.Block{gb.Block}, validator2.prevBlocks)
+
+	// A validator error rejects the block, and the following validators are not called
+	_, err = executeBlock(2, true)
+	require.EqualError(t, err, "rejected by fake validator")
+	require.Equal(t, []coin.Block{*b1}, validator1.prevBlocks)
+	require.Empty(t, validator2.prevBlocks)
+
+	headSeq, ok, err := v.HeadBkSeq()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(1), headSeq)
+}
+
+func TestDefaultBlockValidator(t *testing.T) {
+	bs := makeBlocks(t, 2)
+
+	require.NoError(t, DefaultBlockValidator{}.Validate(bs[1].Block, bs[0].Block))
+
+	// The default checks are done before the registered validators
+	b := bs[1].Block
+	b.Head.BodyHash = cipher.SHA256{}
+	require.EqualError(t, validateBlock(b, bs[0].Block, 0), "Computed body hash does not match")
+}
+
+func TestDefaultBlockValidatorMinBlockInterval(t *testing.T) {
+	bs := makeBlocks(t, 1)
+	prev := bs[0].Block
+
+	tt := []struct {
+		name        string
+		time        uint64
+		minInterval time.Duration
+		err         error
+	}{
+		{
+			name:        "after the interval",
+			time:        prev.Head.Time + 11,
+			minInterval: time.Second * 10,
+		},
+		{
+			name:        "at the interval",
+			time:        prev.Head.Time + 10,
+			minInterval: time.Second * 10,
+		},
+		{
+			name:        "too soon",
+			time:        prev.Head.Time + 9,
+			minInterval: time.Second * 10,
+			err: ErrBlockIntervalTooShort{
+				Seq:     1,
+				Time:    prev.Head.Time + 9,
+				MinTime: prev.Head.Time + 10,
+			},
+		},
+		{
+			name: "not checked",
+			time: prev.Head.Time + 1,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			b := makeBlock(t, prev, tc.time)
+			err := DefaultBlockValidator{
+				MinBlockInterval: tc.minInterval,
+			}.Validate(*b, prev)
+			require.Equal(t, tc.err, err)
+
+			err = validateBlock(*b, prev, tc.minInterval)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
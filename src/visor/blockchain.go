@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
@@ -43,6 +46,32 @@ func (e ErrBlockNotExist) Error() string {
 	return fmt.Sprintf("block does not exist seq=%d", e.Seq)
 }
 
+// ErrBlockPruned is returned if the transactions of a block have been pruned.
+// It is also returned for a transaction or output that is not in the history database of a pruned node,
+// with the sequence of the most recent pruned block, since it may have been removed with a pruned block.
+type ErrBlockPruned struct {
+	Seq uint64
+}
+
+func (e ErrBlockPruned) Error() string {
+	return fmt.Sprintf("transactions of block seq=%d have been pruned", e.Seq)
+}
+
+// ErrBlockTimestampTooFar is returned if a block's timestamp is further in the future than BlockchainConfig.MaxFutureBlockTime
+type ErrBlockTimestampTooFar struct {
+	Seq     uint64
+	Time    uint64
+	MaxTime uint64
+}
+
+func (e ErrBlockTimestampTooFar) Error() string {
+	return fmt.Sprintf("block seq=%d timestamp %d is too far in the future, the maximum is %d", e.Seq, e.Time, e.MaxTime)
+}
+
+// futureBlockTimeWarning is how close to the maximum future block time a block's timestamp
+// can be before a warning is logged
+const futureBlockTimeWarning = time.Second * 10
+
 //Warning: 10e6 is 10 million, 1e6 is 1 million
 
 // Note: DebugLevel1 adds additional checks for hash collisions that
@@ -74,6 +103,10 @@ type chainStore interface {
 	GetGenesisBlock(*dbutil.Tx) (*coin.SignedBlock, error)
 	GetBlockSignature(*dbutil.Tx, *coin.Block) (cipher.Sig, bool, error)
 	ForEachBlock(*dbutil.Tx, func(*coin.Block) error) error
+	ForEachSignedBlock(*dbutil.Tx, func(*coin.SignedBlock) error) error
+	ForEachRawBlockInRange(*dbutil.Tx, uint64, uint64, func([]byte) error) error
+	PrunedSeq(*dbutil.Tx) (uint64, bool, error)
+	PruneBlocks(*dbutil.Tx, uint64) error
 }
 
 // DefaultWalker default blockchain walker
@@ -98,6 +131,8 @@ func CreateBuckets(db *dbutil.DB) error {
 		return dbutil.CreateBuckets(tx, [][]byte{
 			UnconfirmedTxnsBkt,
 			UnconfirmedUnspentsBkt,
+			EventLogBkt,
+			BlockSizeHistogramBkt,
 		})
 	})
 }
@@ -109,6 +144,10 @@ type BlockchainConfig struct {
 	// node will throw the error and return.
 	Arbitrating bool
 	Pubkey      cipher.PubKey
+	// Blocks whose timestamp is more than this far ahead of the local clock are rejected. If 0, it is not checked
+	MaxFutureBlockTime time.Duration
+	// Blocks whose timestamp is less than this long after the previous block's timestamp are rejected. If 0, it is not checked
+	MinBlockInterval time.Duration
 }
 
 // Blockchain maintains blockchain and provides apis for accessing the chain.
@@ -167,6 +206,16 @@ func (bc *Blockchain) HeadSeq(tx *dbutil.Tx) (uint64, bool, error) {
 	return bc.store.HeadSeq(tx)
 }
 
+// PrunedSeq returns the sequence of the most recent block whose transactions have been pruned
+func (bc *Blockchain) PrunedSeq(tx *dbutil.Tx) (uint64, bool, error) {
+	return bc.store.PrunedSeq(tx)
+}
+
+// PruneBlocks removes the transactions of the blocks up to and including seq, except the genesis block
+func (bc *Blockchain) PruneBlocks(tx *dbutil.Tx, seq uint64) error {
+	return bc.store.PruneBlocks(tx, seq)
+}
+
 // Time returns time of last block
 // used as system clock indepedent clock for coin hour calculations
 // TODO: Deprecate
@@ -200,6 +249,10 @@ func (bc Blockchain) NewBlock(tx *dbutil.Tx, txns coin.Transactions, currentTime
 		return nil, errors.New("Time can only move forward")
 	}
 
+	if err := verifyBlockInterval(head.Seq()+1, currentTime, head.Time(), bc.cfg.MinBlockInterval); err != nil {
+		return nil, err
+	}
+
 	txns, err = bc.processTransactions(tx, txns)
 	if err != nil {
 		return nil, err
@@ -475,6 +528,12 @@ func (bc Blockchain) GetBlocks(tx *dbutil.Tx, seqs []uint64) ([]coin.SignedBlock
 	return blocks, nil
 }
 
+// ForEachSignedBlock calls f on each block in height order, from the genesis block to the head block.
+// If f returns an error, the iteration stops and the error is returned.
+func (bc Blockchain) ForEachSignedBlock(tx *dbutil.Tx, f func(b *coin.SignedBlock) error) error {
+	return bc.store.ForEachSignedBlock(tx, f)
+}
+
 // GetBlocksInRange return blocks whose seq are in the range of start and end.
 func (bc Blockchain) GetBlocksInRange(tx *dbutil.Tx, start, end uint64) ([]coin.SignedBlock, error) {
 	if start > end {
@@ -825,28 +884,43 @@ func (bc *Blockchain) WalkChain(workers int, f func(*dbutil.Tx, *coin.SignedBloc
 	return err
 }
 
-// VerifyBlockHeader Returns error if the BlockHeader is not valid
+// verifyBlockHeader returns an error if the block is rejected by the block validators, checked against the head block
 func (bc Blockchain) verifyBlockHeader(tx *dbutil.Tx, b coin.Block) error {
 	head, err := bc.Head(tx)
 	if err != nil {
 		return err
 	}
 
-	//check BkSeq
-	if b.Head.BkSeq != head.Head.BkSeq+1 {
-		return errors.New("BkSeq invalid")
+	if err := validateBlock(b, head.Block, bc.cfg.MinBlockInterval); err != nil {
+		return err
 	}
-	//check Time, only requirement is that its monotonely increasing
-	if b.Head.Time <= head.Head.Time {
-		return errors.New("Block time must be > head time")
+
+	return verifyBlockTime(b, time.Now().UTC(), bc.cfg.MaxFutureBlockTime)
+}
+
+// verifyBlockTime returns ErrBlockTimestampTooFar if the block's timestamp is more than maxFutureTime ahead of now,
+// and logs a warning if it is within futureBlockTimeWarning of the limit
+func verifyBlockTime(b coin.Block, now time.Time, maxFutureTime time.Duration) error {
+	if maxFutureTime <= 0 {
+		return nil
 	}
-	// Check block hash against previous head
-	if b.Head.PrevHash != head.HashHeader() {
-		return errors.New("PrevHash does not match current head")
+
+	maxTime := uint64(now.Add(maxFutureTime).Unix())
+	if b.Head.Time > maxTime {
+		return ErrBlockTimestampTooFar{
+			Seq:     b.Head.BkSeq,
+			Time:    b.Head.Time,
+			MaxTime: maxTime,
+		}
 	}
 
-	if b.Body.Hash() != b.Head.BodyHash {
-		return errors.New("Computed body hash does not match")
+	if b.Head.Time > uint64(now.Add(maxFutureTime-futureBlockTimeWarning).Unix()) {
+		logger.WithFields(logrus.Fields{
+			"seq":     b.Head.BkSeq,
+			"time":    b.Head.Time,
+			"maxTime": maxTime,
+		}).Warning("Block timestamp is close to the maximum future block time")
 	}
+
 	return nil
 }
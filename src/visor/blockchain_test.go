@@ -3,6 +3,7 @@ package visor
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -142,6 +143,27 @@ func (fcs *fakeChainStore) ForEachBlock(tx *dbutil.Tx, f func(*coin.Block) error
 	return nil
 }
 
+func (fcs *fakeChainStore) ForEachSignedBlock(tx *dbutil.Tx, f func(*coin.SignedBlock) error) error {
+	for i := range fcs.blocks {
+		if err := f(&fcs.blocks[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fcs *fakeChainStore) ForEachRawBlockInRange(tx *dbutil.Tx, start, end uint64, f func([]byte) error) error {
+	return nil
+}
+
+func (fcs *fakeChainStore) PrunedSeq(tx *dbutil.Tx) (uint64, bool, error) {
+	return 0, false, nil
+}
+
+func (fcs *fakeChainStore) PruneBlocks(tx *dbutil.Tx, seq uint64) error {
+	return nil
+}
+
 func makeBlock(t *testing.T, preBlock coin.Block, tm uint64) *coin.Block {
 	uxHash := testutil.RandSHA256(t)
 	tx := coin.Transaction{}
@@ -336,6 +358,143 @@ func TestVerifyBlockHeader(t *testing.T) {
 	}
 }
 
+func TestVerifyBlockTime(t *testing.T) {
+	now := time.Unix(1540000000, 0)
+	newBlock := func(t uint64) coin.Block {
+		return coin.Block{
+			Head: coin.BlockHeader{
+				BkSeq: 10,
+				Time:  t,
+			},
+		}
+	}
+
+	tt := []struct {
+		name          string
+		b             coin.Block
+		maxFutureTime time.Duration
+		err           error
+	}{
+		{
+			name:          "past",
+			b:             newBlock(1530000000),
+			maxFutureTime: time.Minute * 2,
+		},
+		{
+			name:          "at the limit",
+			b:             newBlock(1540000120),
+			maxFutureTime: time.Minute * 2,
+		},
+		{
+			name:          "close to the limit",
+			b:             newBlock(1540000115),
+			maxFutureTime: time.Minute * 2,
+		},
+		{
+			name:          "too far",
+			b:             newBlock(1540000121),
+			maxFutureTime: time.Minute * 2,
+			err: ErrBlockTimestampTooFar{
+				Seq:     10,
+				Time:    1540000121,
+				MaxTime: 1540000120,
+			},
+		},
+		{
+			name: "not checked",
+			b:    newBlock(1640000000),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifyBlockTime(tc.b, now, tc.maxFutureTime)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestVerifyBlockHeaderFutureTime(t *testing.T) {
+	bs := makeBlocks(t, 1)
+	future := uint64(time.Now().Add(time.Hour).Unix())
+	b := makeBlock(t, bs[0].Block, future)
+
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	bc := &Blockchain{
+		db: db,
+		cfg: BlockchainConfig{
+			MaxFutureBlockTime: DefaultMaxFutureBlockTime,
+		},
+		store: &fakeChainStore{
+			blocks: bs,
+		},
+	}
+
+	err := db.View("", func(tx *dbutil.Tx) error {
+		err := bc.verifyBlockHeader(tx, *b)
+		require.IsType(t, ErrBlockTimestampTooFar{}, err)
+		require.Equal(t, future, err.(ErrBlockTimestampTooFar).Time)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Without a maximum future block time, the block is accepted
+	bc.cfg.MaxFutureBlockTime = 0
+	err = db.View("", func(tx *dbutil.Tx) error {
+		return bc.verifyBlockHeader(tx, *b)
+	})
+	require.NoError(t, err)
+}
+
+func TestBlockchainMinBlockInterval(t *testing.T) {
+	bs := makeBlocks(t, 1)
+	soon := bs[0].Block.Head.Time + 5
+	b := makeBlock(t, bs[0].Block, soon)
+
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	bc := &Blockchain{
+		db: db,
+		cfg: BlockchainConfig{
+			MinBlockInterval: time.Second * 10,
+		},
+		store: &fakeChainStore{
+			blocks: bs,
+		},
+	}
+
+	expectedErr := ErrBlockIntervalTooShort{
+		Seq:     1,
+		Time:    soon,
+		MinTime: bs[0].Block.Head.Time + 10,
+	}
+
+	err := db.View("", func(tx *dbutil.Tx) error {
+		err := bc.verifyBlockHeader(tx, *b)
+		require.Equal(t, expectedErr, err)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// A block publisher does not create a block before the interval
+	err = db.View("", func(tx *dbutil.Tx) error {
+		_, err := bc.NewBlock(tx, coin.Transactions{{}}, soon)
+		require.Equal(t, expectedErr, err)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Without a minimum block interval, the block is accepted
+	bc.cfg.MinBlockInterval = 0
+	err = db.View("", func(tx *dbutil.Tx) error {
+		return bc.verifyBlockHeader(tx, *b)
+	})
+	require.NoError(t, err)
+}
+
 func TestGetBlocks(t *testing.T) {
 	blocks := makeBlocks(t, 5)
 	tt := []struct {
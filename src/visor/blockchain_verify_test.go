@@ -412,6 +412,18 @@ func testVerifyTransactionSoftHardConstraints(t *testing.T, signed TxnSignedFlag
 	})
 	requireSoftViolation(t, "Transaction size bigger than max block size", err)
 
+	// Transaction exceeds the max outputs, the max inputs are tested by TestVerifyTxnInputOutputCounts
+	require.True(t, len(txn.Out) > 1)
+	verifyParams := params.UserVerifyTxn
+	verifyParams.MaxTxInputs = len(txn.In)
+	verifyParams.MaxTxOutputs = len(txn.Out)
+	err = verifySingleTxnSoftHardConstraints(txn, verifyParams)
+	require.NoError(t, err)
+
+	verifyParams.MaxTxOutputs = len(txn.Out) - 1
+	err = verifySingleTxnSoftHardConstraints(txn, verifyParams)
+	requireSoftViolation(t, ErrTooManyOutputs.Error(), err)
+
 	// Invalid transaction fee
 	uxs = coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
 	hours := uint64(0)
@@ -531,6 +543,55 @@ func testVerifyTransactionSoftHardConstraints(t *testing.T, signed TxnSignedFlag
 	requireHardViolation(t, "Duplicate output in transaction", err)
 }
 
+func TestVerifyTxnInputOutputCounts(t *testing.T) {
+	txn := coin.Transaction{
+		In:  make([]cipher.SHA256, 3),
+		Out: make([]coin.TransactionOutput, 2),
+	}
+
+	cases := []struct {
+		name         string
+		maxTxInputs  int
+		maxTxOutputs int
+		err          error
+	}{
+		{
+			name: "no limits",
+		},
+		{
+			name:         "at limits",
+			maxTxInputs:  3,
+			maxTxOutputs: 2,
+		},
+		{
+			name:         "too many inputs",
+			maxTxInputs:  2,
+			maxTxOutputs: 2,
+			err:          ErrTooManyInputs,
+		},
+		{
+			name:         "too many outputs",
+			maxTxInputs:  3,
+			maxTxOutputs: 1,
+			err:          ErrTooManyOutputs,
+		},
+		{
+			name:         "too many outputs, inputs not limited",
+			maxTxOutputs: 1,
+			err:          ErrTooManyOutputs,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifyParams := params.UserVerifyTxn
+			verifyParams.MaxTxInputs = tc.maxTxInputs
+			verifyParams.MaxTxOutputs = tc.maxTxOutputs
+			require.Equal(t, tc.err, verifyTxnInputOutputCounts(txn, verifyParams))
+		})
+	}
+}
+
 func TestVerifyTxnFeeCoinHoursAdditionFails(t *testing.T) {
 	// Test that VerifySingleTxnSoftConstraints fails if a uxIn.CoinHours() call fails.
 	// This is a separate test on its own, because it's not possible to reach the line
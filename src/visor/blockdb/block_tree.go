@@ -127,6 +127,38 @@ func (bt *blockTree) RemoveBlock(tx *dbutil.Tx, b *coin.Block) error {
 	return setHashPairInDepth(tx, b.Seq(), ps)
 }
 
+// PruneBlocksInDepth removes the transactions of all blocks in depth.
+// The block header is kept, so the block hash does not change.
+// Only the blocks bucket is changed, the history database is pruned separately.
+func (bt *blockTree) PruneBlocksInDepth(tx *dbutil.Tx, depth uint64) error {
+	hashPairs, err := getHashPairInDepth(tx, depth, allPairs)
+	if err != nil {
+		return err
+	}
+
+	for _, hp := range hashPairs {
+		b, err := bt.GetBlock(tx, hp.Hash)
+		if err != nil {
+			return err
+		} else if b == nil {
+			return fmt.Errorf("block %s in depth %d not found", hp.Hash.Hex(), depth)
+		}
+
+		b.Body = coin.BlockBody{}
+
+		buf, err := encodeBlock(b)
+		if err != nil {
+			return err
+		}
+
+		if err := dbutil.PutBucketValue(tx, BlocksBkt, hp.Hash[:], buf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // GetBlock get block by hash, return nil on not found
 func (bt *blockTree) GetBlock(tx *dbutil.Tx, hash cipher.SHA256) (*coin.Block, error) {
 	var b coin.Block
@@ -174,6 +206,59 @@ func (bt *blockTree) ForEachBlock(tx *dbutil.Tx, f func(b *coin.Block) error) er
 	})
 }
 
+// ForEachBlockInDepthOrder iterates the blocks in height order and calls f on them.
+// It walks the block tree bucket with a single cursor, the filter is used to choose the block of each height.
+// If f returns an error, the iteration stops and the error is returned.
+func (bt *blockTree) ForEachBlockInDepthOrder(tx *dbutil.Tx, filter Walker, f func(b *coin.Block) error) error {
+	return dbutil.ForEach(tx, TreeBkt, func(k, v []byte) error {
+		var pairs hashPairsWrapper
+		if err := decodeHashPairsWrapperExact(v, &pairs); err != nil {
+			return err
+		}
+
+		hash, ok := filter(tx, pairs.HashPairs)
+		if !ok {
+			return fmt.Errorf("No hash found in depth %d", dbutil.Btoi(k))
+		}
+
+		b, err := bt.GetBlock(tx, hash)
+		if err != nil {
+			return err
+		} else if b == nil {
+			return fmt.Errorf("block %s of depth %d not found", hash.Hex(), dbutil.Btoi(k))
+		}
+
+		return f(b)
+	})
+}
+
+// ForEachRawBlockInDepthRange calls f on the encoded blocks of the heights from start to end, inclusive, in height order,
+// without decoding them. The filter is used to choose the block of each height.
+// The iteration stops at the first height with no block. The data is only valid during tx and must not be modified.
+func (bt *blockTree) ForEachRawBlockInDepthRange(tx *dbutil.Tx, start, end uint64, filter Walker, f func(data []byte) error) error {
+	for depth := start; depth <= end; depth++ {
+		hash, ok, err := bt.getHashInDepth(tx, depth, filter)
+		if err != nil {
+			return err
+		} else if !ok {
+			return nil
+		}
+
+		v, err := dbutil.GetBucketValueNoCopy(tx, BlocksBkt, hash[:])
+		if err != nil {
+			return err
+		} else if v == nil {
+			return fmt.Errorf("block %s of depth %d not found", hash.Hex(), depth)
+		}
+
+		if err := f(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (bt *blockTree) getHashInDepth(tx *dbutil.Tx, depth uint64, filter Walker) (cipher.SHA256, bool, error) {
 	var pairs hashPairsWrapper
 
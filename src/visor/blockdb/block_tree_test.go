@@ -1,6 +1,7 @@
 package blockdb
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -226,3 +227,203 @@ func TestGetBlockInDepth(t *testing.T) {
 	require.NotNil(t, block)
 	require.Equal(t, blocks[2], *block)
 }
+
+func TestPruneBlocksInDepth(t *testing.T) {
+	db, teardown := prepareDB(t)
+	defer teardown()
+
+	bt := &blockTree{}
+	gb := coin.Block{
+		Head: coin.BlockHeader{
+			BkSeq: 0,
+		},
+	}
+	b := coin.Block{
+		Head: coin.BlockHeader{
+			BkSeq: 1,
+			Time:  1,
+		},
+		Body: coin.BlockBody{
+			Transactions: coin.Transactions{
+				{
+					Out: []coin.TransactionOutput{
+						{
+							Coins: 1e6,
+							Hours: 1,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	err := db.Update("", func(tx *dbutil.Tx) error {
+		require.NoError(t, bt.AddBlock(tx, &gb))
+
+		b.Head.PrevHash = gb.HashHeader()
+		b.Head.BodyHash = b.Body.Hash()
+		require.NoError(t, bt.AddBlock(tx, &b))
+
+		require.NoError(t, bt.PruneBlocksInDepth(tx, 1))
+
+		// Depths without blocks are ignored
+		require.NoError(t, bt.PruneBlocksInDepth(tx, 2))
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		pb, err := bt.GetBlock(tx, b.HashHeader())
+		require.NoError(t, err)
+		require.NotNil(t, pb)
+		require.Equal(t, b.Head, pb.Head)
+		require.Empty(t, pb.Body.Transactions)
+
+		g, err := bt.GetBlock(tx, gb.HashHeader())
+		require.NoError(t, err)
+		require.Equal(t, gb, *g)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestForEachBlockInDepthOrder(t *testing.T) {
+	db, teardown := prepareDB(t)
+	defer teardown()
+
+	bt := &blockTree{}
+
+	// More than 256 blocks, so that the depth keys differ in more than one byte
+	blocks := make([]coin.Block, 300)
+	err := db.Update("", func(tx *dbutil.Tx) error {
+		for i := range blocks {
+			blocks[i].Head.BkSeq = uint64(i)
+			blocks[i].Head.Time = uint64(i)
+			if i > 0 {
+				blocks[i].Head.PrevHash = blocks[i-1].HashHeader()
+			}
+			require.NoError(t, bt.AddBlock(tx, &blocks[i]))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	walker := func(tx *dbutil.Tx, hps []coin.HashPair) (cipher.SHA256, bool) {
+		if len(hps) == 0 {
+			return cipher.SHA256{}, false
+		}
+		return hps[0].Hash, true
+	}
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		var seqs []uint64
+		require.NoError(t, bt.ForEachBlockInDepthOrder(tx, walker, func(b *coin.Block) error {
+			require.Equal(t, blocks[b.Seq()], *b)
+			seqs = append(seqs, b.Seq())
+			return nil
+		}))
+		require.Len(t, seqs, len(blocks))
+		for i, seq := range seqs {
+			require.Equal(t, uint64(i), seq)
+		}
+
+		// The iteration stops at the first error
+		errStop := errors.New("stop")
+		n := 0
+		err := bt.ForEachBlockInDepthOrder(tx, walker, func(b *coin.Block) error {
+			n++
+			if b.Seq() == 10 {
+				return errStop
+			}
+			return nil
+		})
+		require.Equal(t, errStop, err)
+		require.Equal(t, 11, n)
+
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestForEachRawBlockInDepthRange(t *testing.T) {
+	db, teardown := prepareDB(t)
+	defer teardown()
+
+	bt := &blockTree{}
+
+	blocks := make([]coin.Block, 10)
+	err := db.Update("", func(tx *dbutil.Tx) error {
+		for i := range blocks {
+			blocks[i].Head.BkSeq = uint64(i)
+			blocks[i].Head.Time = uint64(i)
+			if i > 0 {
+				blocks[i].Head.PrevHash = blocks[i-1].HashHeader()
+			}
+			require.NoError(t, bt.AddBlock(tx, &blocks[i]))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	walker := func(tx *dbutil.Tx, hps []coin.HashPair) (cipher.SHA256, bool) {
+		if len(hps) == 0 {
+			return cipher.SHA256{}, false
+		}
+		return hps[0].Hash, true
+	}
+
+	cases := []struct {
+		name       string
+		start, end uint64
+		seqs       []uint64
+	}{
+		{
+			name:  "range",
+			start: 2,
+			end:   5,
+			seqs:  []uint64{2, 3, 4, 5},
+		},
+		{
+			name:  "single block",
+			start: 0,
+			end:   0,
+			seqs:  []uint64{0},
+		},
+		{
+			name:  "end past the last block",
+			start: 8,
+			end:   100,
+			seqs:  []uint64{8, 9},
+		},
+		{
+			name:  "start past the last block",
+			start: 10,
+			end:   20,
+		},
+		{
+			name:  "start after end",
+			start: 5,
+			end:   4,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := db.View("", func(tx *dbutil.Tx) error {
+				var seqs []uint64
+				err := bt.ForEachRawBlockInDepthRange(tx, tc.start, tc.end, walker, func(data []byte) error {
+					var b coin.Block
+					require.NoError(t, decodeBlockExact(data, &b))
+					require.Equal(t, blocks[b.Seq()], b)
+					seqs = append(seqs, b.Seq())
+					return nil
+				})
+				require.NoError(t, err)
+				require.Equal(t, tc.seqs, seqs)
+				return nil
+			})
+			require.NoError(t, err)
+		})
+	}
+}
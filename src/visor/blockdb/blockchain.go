@@ -57,6 +57,11 @@ func (e ErrMissingSignature) Error() string {
 	return fmt.Sprintf("Signature not found for block seq=%d hash=%s", e.b.Head.BkSeq, e.b.HashHeader().Hex())
 }
 
+// BlockHash returns the header hash of the block, which is the key of its signature
+func (e ErrMissingSignature) BlockHash() cipher.SHA256 {
+	return e.b.HashHeader()
+}
+
 // CreateBuckets creates bolt.DB buckets used by the blockdb
 func CreateBuckets(tx *dbutil.Tx) error {
 	return dbutil.CreateBuckets(tx, [][]byte{
@@ -76,6 +81,9 @@ type BlockTree interface {
 	GetBlock(*dbutil.Tx, cipher.SHA256) (*coin.Block, error)
 	GetBlockInDepth(*dbutil.Tx, uint64, Walker) (*coin.Block, error)
 	ForEachBlock(*dbutil.Tx, func(*coin.Block) error) error
+	ForEachBlockInDepthOrder(*dbutil.Tx, Walker, func(*coin.Block) error) error
+	ForEachRawBlockInDepthRange(*dbutil.Tx, uint64, uint64, Walker, func([]byte) error) error
+	PruneBlocksInDepth(*dbutil.Tx, uint64) error
 }
 
 // BlockSigs block signature storage
@@ -106,6 +114,8 @@ type UnspentPooler interface {
 type ChainMeta interface {
 	GetHeadSeq(*dbutil.Tx) (uint64, bool, error)
 	SetHeadSeq(*dbutil.Tx, uint64) error
+	GetPrunedSeq(*dbutil.Tx) (uint64, bool, error)
+	SetPrunedSeq(*dbutil.Tx, uint64) error
 }
 
 // Blockchain maintain the buckets for blockchain
@@ -283,3 +293,63 @@ func (bc *Blockchain) GetGenesisBlock(tx *dbutil.Tx) (*coin.SignedBlock, error)
 func (bc *Blockchain) ForEachBlock(tx *dbutil.Tx, f func(b *coin.Block) error) error {
 	return bc.tree.ForEachBlock(tx, f)
 }
+
+// ForEachSignedBlock iterates the signed blocks in height order, from the genesis block to the head block, and calls f on them.
+// If f returns an error, the iteration stops and the error is returned.
+func (bc *Blockchain) ForEachSignedBlock(tx *dbutil.Tx, f func(b *coin.SignedBlock) error) error {
+	return bc.tree.ForEachBlockInDepthOrder(tx, bc.walker, func(b *coin.Block) error {
+		sig, ok, err := bc.sigs.Get(tx, b.HashHeader())
+		if err != nil {
+			return fmt.Errorf("find signature of block: %v failed: %v", b.Seq(), err)
+		}
+
+		if !ok {
+			return NewErrMissingSignature(b)
+		}
+
+		return f(&coin.SignedBlock{
+			Block: *b,
+			Sig:   sig,
+		})
+	})
+}
+
+// ForEachRawBlockInRange calls f on the encoded blocks from seq start to end, inclusive, in height order.
+// The iteration stops after the head block. The data is only valid during tx and must not be modified.
+func (bc *Blockchain) ForEachRawBlockInRange(tx *dbutil.Tx, start, end uint64, f func(data []byte) error) error {
+	return bc.tree.ForEachRawBlockInDepthRange(tx, start, end, bc.walker, f)
+}
+
+// PrunedSeq returns the sequence of the most recent block whose transactions have been pruned
+func (bc *Blockchain) PrunedSeq(tx *dbutil.Tx) (uint64, bool, error) {
+	return bc.meta.GetPrunedSeq(tx)
+}
+
+// PruneBlocks removes the transactions of the blocks after the last pruned block
+// up to and including seq. The genesis block is never pruned.
+// Pruned blocks retain their header and signature.
+func (bc *Blockchain) PruneBlocks(tx *dbutil.Tx, seq uint64) error {
+	prunedSeq, _, err := bc.meta.GetPrunedSeq(tx)
+	if err != nil {
+		return err
+	}
+
+	if seq <= prunedSeq {
+		return nil
+	}
+
+	headSeq, ok, err := bc.meta.GetHeadSeq(tx)
+	if err != nil {
+		return err
+	} else if !ok || seq > headSeq {
+		return fmt.Errorf("cannot prune blocks up to %d, it is beyond the head block", seq)
+	}
+
+	for i := prunedSeq + 1; i <= seq; i++ {
+		if err := bc.tree.PruneBlocksInDepth(tx, i); err != nil {
+			return err
+		}
+	}
+
+	return bc.meta.SetPrunedSeq(tx, seq)
+}
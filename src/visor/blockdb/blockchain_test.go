@@ -108,6 +108,18 @@ func (bt *fakeBlockTree) ForEachBlock(tx *dbutil.Tx, f func(*coin.Block) error)
 	return nil
 }
 
+func (bt *fakeBlockTree) ForEachBlockInDepthOrder(tx *dbutil.Tx, filter Walker, f func(*coin.Block) error) error {
+	return nil
+}
+
+func (bt *fakeBlockTree) ForEachRawBlockInDepthRange(tx *dbutil.Tx, start, end uint64, filter Walker, f func([]byte) error) error {
+	return nil
+}
+
+func (bt *fakeBlockTree) PruneBlocksInDepth(tx *dbutil.Tx, depth uint64) error {
+	return nil
+}
+
 type fakeSignatureStore struct {
 	sigs       map[string]cipher.Sig
 	saveFailed bool
@@ -268,8 +280,10 @@ func (fup *fakeUnspentPool) AddressCount(tx *dbutil.Tx) (uint64, error) {
 }
 
 type fakeChainMeta struct {
-	headSeq   uint64
-	didSetSeq bool
+	headSeq         uint64
+	didSetSeq       bool
+	prunedSeq       uint64
+	didSetPrunedSeq bool
 }
 
 func newFakeChainMeta() *fakeChainMeta {
@@ -290,6 +304,20 @@ func (fcm *fakeChainMeta) SetHeadSeq(tx *dbutil.Tx, seq uint64) error {
 	return nil
 }
 
+func (fcm *fakeChainMeta) GetPrunedSeq(tx *dbutil.Tx) (uint64, bool, error) {
+	if !fcm.didSetPrunedSeq {
+		return 0, false, nil
+	}
+
+	return fcm.prunedSeq, true, nil
+}
+
+func (fcm *fakeChainMeta) SetPrunedSeq(tx *dbutil.Tx, seq uint64) error {
+	fcm.prunedSeq = seq
+	fcm.didSetPrunedSeq = true
+	return nil
+}
+
 func DefaultWalker(tx *dbutil.Tx, hps []coin.HashPair) (cipher.SHA256, bool) {
 	return hps[0].Hash, true
 }
@@ -9,6 +9,8 @@ var (
 	BlockchainMetaBkt = []byte("blockchain_meta")
 	// blockchain head sequence number
 	headSeqKey = []byte("head_seq")
+	// sequence number of the most recent block whose transactions have been pruned
+	prunedSeqKey = []byte("pruned_seq")
 )
 
 type chainMeta struct{}
@@ -27,3 +29,18 @@ func (m chainMeta) GetHeadSeq(tx *dbutil.Tx) (uint64, bool, error) {
 
 	return dbutil.Btoi(v), true, nil
 }
+
+func (m chainMeta) SetPrunedSeq(tx *dbutil.Tx, seq uint64) error {
+	return dbutil.PutBucketValue(tx, BlockchainMetaBkt, prunedSeqKey, dbutil.Itob(seq))
+}
+
+func (m chainMeta) GetPrunedSeq(tx *dbutil.Tx) (uint64, bool, error) {
+	v, err := dbutil.GetBucketValue(tx, BlockchainMetaBkt, prunedSeqKey)
+	if err != nil {
+		return 0, false, err
+	} else if v == nil {
+		return 0, false, nil
+	}
+
+	return dbutil.Btoi(v), true, nil
+}
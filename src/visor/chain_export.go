@@ -0,0 +1,185 @@
+package visor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+const (
+	// ChainExportProgressInterval is the number of blocks between progress reports of ExportBlocks and ImportBlocks
+	ChainExportProgressInterval = 10000
+
+	// maxChainExportFrameSize is the maximum size of an encoded block in a chain export file
+	maxChainExportFrameSize = 32 * 1024 * 1024
+
+	// chainImportBatchSize is the number of blocks executed in a single DB transaction by ImportBlocks
+	chainImportBatchSize = 1000
+)
+
+var (
+	// ErrChainImportNotEmpty is returned by ImportBlocks if the blockchain already has blocks
+	ErrChainImportNotEmpty = errors.New("blocks can only be imported into an empty blockchain")
+)
+
+// ExportBlocks writes all blocks of the blockchain to w, from the genesis block to the head block.
+// Each block is written as a frame of a 4 byte little endian length followed by the binary encoded coin.SignedBlock.
+// progress, if not nil, is called with the number of blocks written every ChainExportProgressInterval blocks.
+// Returns the number of blocks written.
+// A pruned database cannot be exported, since it does not have the transactions of all blocks.
+func ExportBlocks(db *dbutil.DB, w io.Writer, progress func(n uint64)) (uint64, error) {
+	bc, err := NewBlockchain(db, BlockchainConfig{})
+	if err != nil {
+		return 0, err
+	}
+
+	bw := bufio.NewWriter(w)
+
+	var n uint64
+	if err := db.View("ExportBlocks", func(tx *dbutil.Tx) error {
+		// A new database has no blocks
+		if !dbutil.Exists(tx, blockdb.BlocksBkt) {
+			return nil
+		}
+
+		if prunedSeq, ok, err := bc.PrunedSeq(tx); err != nil {
+			return err
+		} else if ok {
+			return fmt.Errorf("the transactions of blocks up to %d have been pruned, a pruned database cannot be exported", prunedSeq)
+		}
+
+		return bc.ForEachSignedBlock(tx, func(b *coin.SignedBlock) error {
+			if b.Seq() != n {
+				return fmt.Errorf("block %d not found", n)
+			}
+
+			if err := writeChainExportFrame(bw, b); err != nil {
+				return err
+			}
+
+			n++
+			if progress != nil && n%ChainExportProgressInterval == 0 {
+				progress(n)
+			}
+
+			return nil
+		})
+	}); err != nil {
+		return n, err
+	}
+
+	return n, bw.Flush()
+}
+
+func writeChainExportFrame(w io.Writer, b *coin.SignedBlock) error {
+	buf := encoder.Serialize(*b)
+
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(buf)))
+
+	if _, err := w.Write(length[:]); err != nil {
+		return err
+	}
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// readChainExportFrame reads a block written by writeChainExportFrame. Returns io.EOF if there are no more blocks.
+func readChainExportFrame(r io.Reader) (*coin.SignedBlock, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, errors.New("truncated block frame length")
+		}
+		return nil, err
+	}
+
+	size := binary.LittleEndian.Uint32(length[:])
+	if size > maxChainExportFrameSize {
+		return nil, fmt.Errorf("block frame size %d exceeds the maximum of %d", size, maxChainExportFrameSize)
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errors.New("truncated block frame")
+		}
+		return nil, err
+	}
+
+	var b coin.SignedBlock
+	if err := encoder.DeserializeRawExact(buf, &b); err != nil {
+		return nil, fmt.Errorf("decode block failed: %v", err)
+	}
+
+	return &b, nil
+}
+
+// ImportBlocks reads blocks written by ExportBlocks from r and executes them, starting from the genesis block.
+// Each block's signature is verified against the configured blockchain pubkey and the block is validated
+// like a block received from a peer.
+// The blockchain must be empty.
+// progress, if not nil, is called with the number of blocks imported every ChainExportProgressInterval blocks.
+// Blocks are committed in batches. If a block is invalid, the blocks of its batch are rolled back,
+// and the blocks of the previous batches remain imported.
+// Returns the number of blocks imported.
+func (vs *Visor) ImportBlocks(r io.Reader, progress func(n uint64)) (uint64, error) {
+	if err := vs.db.View("ImportBlocks", func(tx *dbutil.Tx) error {
+		if _, ok, err := vs.blockchain.HeadSeq(tx); err != nil {
+			return err
+		} else if ok {
+			return ErrChainImportNotEmpty
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	br := bufio.NewReader(r)
+
+	var n uint64
+	for done := false; !done; {
+		// Execute the blocks in batches, since committing a DB transaction for every block is slow
+		committed := n
+		if err := vs.db.Update("ImportBlocks", func(tx *dbutil.Tx) error {
+			for i := 0; i < chainImportBatchSize; i++ {
+				b, err := readChainExportFrame(br)
+				if err == io.EOF {
+					done = true
+					return nil
+				} else if err != nil {
+					return fmt.Errorf("read block %d failed: %v", n, err)
+				}
+
+				if b.Head.BkSeq != n {
+					return fmt.Errorf("expected block %d but found block %d", n, b.Head.BkSeq)
+				}
+
+				if err := vs.executeSignedBlock(tx, *b); err != nil {
+					return fmt.Errorf("execute block %d failed: %v", n, err)
+				}
+
+				n++
+			}
+
+			return nil
+		}); err != nil {
+			return committed, err
+		}
+
+		// The progress interval is a multiple of the batch size, so progress is only reported for committed blocks
+		if progress != nil && n != committed && n%ChainExportProgressInterval == 0 {
+			progress(n)
+		}
+	}
+
+	return n, nil
+}
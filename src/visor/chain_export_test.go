@@ -0,0 +1,136 @@
+package visor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func newChainExportTestVisor(t *testing.T) (*Visor, func()) {
+	db, shutdown := prepareDB(t)
+
+	cfg := NewConfig()
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+	cfg.Distribution = params.MainNetDistribution
+
+	v, err := New(cfg, db, nil)
+	require.NoError(t, err)
+
+	return v, shutdown
+}
+
+func TestExportImportBlocks(t *testing.T) {
+	src, shutdown := newChainExportTestVisor(t)
+	defer shutdown()
+
+	// Export an empty blockchain
+	var buf bytes.Buffer
+	n, err := ExportBlocks(src.db, &buf, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), n)
+	require.Empty(t, buf.Bytes())
+
+	// Create a chain of blocks, each spending the output of the previous block's transaction
+	gb := addGenesisBlockToVisor(t, src)
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	for i := 1; i <= 5; i++ {
+		txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, uxs[0].Body.Coins)
+
+		err := src.db.Update("", func(tx *dbutil.Tx) error {
+			b, err := src.blockchain.NewBlock(tx, coin.Transactions{txn}, genTime+uint64(i)*100)
+			require.NoError(t, err)
+
+			sb := coin.SignedBlock{
+				Block: *b,
+				Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+			}
+			if err := src.executeSignedBlock(tx, sb); err != nil {
+				return err
+			}
+
+			uxs = coin.CreateUnspents(b.Head, txn)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	srcBlocks, err := src.GetBlocksInRange(0, 5)
+	require.NoError(t, err)
+	require.Len(t, srcBlocks, 6)
+
+	n, err = ExportBlocks(src.db, &buf, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), n)
+	exported := buf.Bytes()
+
+	// Import into a fresh blockchain
+	dst, shutdown2 := newChainExportTestVisor(t)
+	defer shutdown2()
+
+	n, err = dst.ImportBlocks(bytes.NewReader(exported), nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), n)
+
+	dstBlocks, err := dst.GetBlocksInRange(0, 5)
+	require.NoError(t, err)
+	require.Equal(t, srcBlocks, dstBlocks)
+
+	srcUnspents, err := src.GetAllUnspentOutputs()
+	require.NoError(t, err)
+	dstUnspents, err := dst.GetAllUnspentOutputs()
+	require.NoError(t, err)
+	require.Equal(t, srcUnspents, dstUnspents)
+
+	// The history is rebuilt while importing
+	txn, err := dst.GetTransaction(srcBlocks[3].Body.Transactions[0].Hash())
+	require.NoError(t, err)
+	require.NotNil(t, txn)
+
+	require.NoError(t, CheckDatabase(dst.db, genPublic, nil, nil))
+
+	// Blocks can't be imported into a non-empty blockchain
+	_, err = dst.ImportBlocks(bytes.NewReader(exported), nil)
+	require.Equal(t, ErrChainImportNotEmpty, err)
+
+	// A block with an invalid signature is rejected
+	bad := append([]coin.SignedBlock{}, srcBlocks...)
+	_, otherSecret := cipher.GenerateKeyPair()
+	bad[2].Sig = cipher.MustSignHash(bad[2].HashHeader(), otherSecret)
+	var badBuf bytes.Buffer
+	for i := range bad {
+		require.NoError(t, writeChainExportFrame(&badBuf, &bad[i]))
+	}
+
+	dst2, shutdown3 := newChainExportTestVisor(t)
+	defer shutdown3()
+
+	n, err = dst2.ImportBlocks(&badBuf, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "execute block 2 failed")
+	// The failed batch is rolled back
+	require.Equal(t, uint64(0), n)
+	_, ok, err := dst2.HeadBkSeq()
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// A truncated export is rejected
+	dst3, shutdown4 := newChainExportTestVisor(t)
+	defer shutdown4()
+
+	_, err = dst3.ImportBlocks(bytes.NewReader(exported[:len(exported)-10]), nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "read block 5 failed: truncated block frame")
+}
+
+func TestReadChainExportFrameTooLarge(t *testing.T) {
+	_, err := readChainExportFrame(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff}))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exceeds the maximum")
+}
@@ -0,0 +1,280 @@
+package visor
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+var (
+	// ErrNoCheckpoints is returned if a checkpoint manifest has no checkpoints
+	ErrNoCheckpoints = errors.New("Checkpoint manifest has no checkpoints")
+	// ErrCheckpointsNotSorted is returned if a checkpoint manifest's checkpoints are not in ascending seq order
+	ErrCheckpointsNotSorted = errors.New("Checkpoint manifest checkpoints must be sorted by seq with no duplicates")
+)
+
+// ErrCheckpointMismatch is returned if a block's hash does not match the checkpoint hash for its seq
+type ErrCheckpointMismatch struct {
+	Seq uint64
+	// Hash is the header hash of the stored block
+	Hash cipher.SHA256
+}
+
+func (e ErrCheckpointMismatch) Error() string {
+	return fmt.Sprintf("Block %d hash does not match the checkpoint hash", e.Seq)
+}
+
+// ErrCheckpointChainBroken is returned if a block below a checkpoint does not have the header hash
+// of the block before it as its PrevHash
+type ErrCheckpointChainBroken struct {
+	Seq uint64
+	// Hash is the header hash of the stored block
+	Hash cipher.SHA256
+}
+
+func (e ErrCheckpointChainBroken) Error() string {
+	return fmt.Sprintf("Block %d PrevHash does not match the hash of block %d", e.Seq, e.Seq-1)
+}
+
+// Checkpoint is a block seq and header hash pair in a checkpoint manifest
+type Checkpoint struct {
+	Seq  uint64 `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+// CheckpointManifest is a list of checkpoints signed by the blockchain's secret key.
+// It is stored as JSON.
+type CheckpointManifest struct {
+	Checkpoints []Checkpoint `json:"checkpoints"`
+	Sig         string       `json:"sig"`
+}
+
+// Checkpoints are the verified block hashes of a CheckpointManifest.
+// Blocks up to the last checkpoint reached by the blockchain are trusted without verifying their signatures,
+// once the PrevHash chain of the blocks is verified to lead to the checkpoint hash.
+type Checkpoints struct {
+	hashes  map[uint64]cipher.SHA256
+	lastSeq uint64
+}
+
+// LastSeq returns the seq of the last checkpoint
+func (c *Checkpoints) LastSeq() uint64 {
+	return c.lastSeq
+}
+
+// verifyChain walks the blockchain forward from the genesis block to the last checkpoint at or below the head block,
+// checking that the PrevHash of each block is the header hash of the block before it, and that the blocks at
+// checkpoint seqs match the checkpoint hash. The hash of every block walked is then committed to by the checkpoint
+// hash, so the signatures of the blocks up to the returned seq do not need to be verified.
+// ok is false if no checkpoint is at or below the head block, in which case no block is trusted.
+func (c *Checkpoints) verifyChain(tx *dbutil.Tx, bc *Blockchain, quit chan struct{}) (uint64, bool, error) {
+	if c == nil {
+		return 0, false, nil
+	}
+
+	headSeq, ok, err := bc.HeadSeq(tx)
+	if err != nil || !ok {
+		return 0, false, err
+	}
+
+	var trustedSeq uint64
+	var found bool
+	for seq := range c.hashes {
+		if seq <= headSeq && (!found || seq > trustedSeq) {
+			trustedSeq = seq
+			found = true
+		}
+	}
+	if !found {
+		return 0, false, nil
+	}
+
+	var prevHash cipher.SHA256
+	for seq := uint64(0); seq <= trustedSeq; seq++ {
+		select {
+		case <-quit:
+			return 0, false, ErrVerifyStopped
+		default:
+		}
+
+		prefetchAhead(bc, 0, seq)
+
+		b, err := bc.GetSignedBlockBySeq(tx, seq)
+		if err != nil {
+			return 0, false, err
+		}
+		if b == nil {
+			return 0, false, fmt.Errorf("Block %d not found", seq)
+		}
+
+		hash := b.HashHeader()
+
+		if seq > 0 && b.Head.PrevHash != prevHash {
+			return 0, false, ErrCheckpointChainBroken{
+				Seq:  seq,
+				Hash: hash,
+			}
+		}
+
+		if h, ok := c.hashes[seq]; ok && h != hash {
+			return 0, false, ErrCheckpointMismatch{
+				Seq:  seq,
+				Hash: hash,
+			}
+		}
+
+		prevHash = hash
+	}
+
+	return trustedSeq, true, nil
+}
+
+// checkpointsHash returns the hash that is signed in a checkpoint manifest
+func checkpointsHash(cps []Checkpoint, hashes []cipher.SHA256) cipher.SHA256 {
+	b := make([]byte, 0, len(cps)*(8+len(cipher.SHA256{})))
+	for i, cp := range cps {
+		var seq [8]byte
+		binary.LittleEndian.PutUint64(seq[:], cp.Seq)
+		b = append(b, seq[:]...)
+		b = append(b, hashes[i][:]...)
+	}
+	return cipher.SumSHA256(b)
+}
+
+func parseCheckpoints(cps []Checkpoint) ([]cipher.SHA256, error) {
+	if len(cps) == 0 {
+		return nil, ErrNoCheckpoints
+	}
+
+	hashes := make([]cipher.SHA256, len(cps))
+	for i, cp := range cps {
+		if i > 0 && cp.Seq <= cps[i-1].Seq {
+			return nil, ErrCheckpointsNotSorted
+		}
+
+		h, err := cipher.SHA256FromHex(cp.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid checkpoint hash at seq %d: %v", cp.Seq, err)
+		}
+		hashes[i] = h
+	}
+
+	return hashes, nil
+}
+
+// NewCheckpointManifest creates a CheckpointManifest signed with the blockchain secret key
+func NewCheckpointManifest(cps []Checkpoint, seckey cipher.SecKey) (*CheckpointManifest, error) {
+	hashes, err := parseCheckpoints(cps)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := cipher.SignHash(checkpointsHash(cps, hashes), seckey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CheckpointManifest{
+		Checkpoints: cps,
+		Sig:         sig.Hex(),
+	}, nil
+}
+
+// Verify verifies the manifest's signature against the blockchain pubkey and returns its Checkpoints
+func (m CheckpointManifest) Verify(pubkey cipher.PubKey) (*Checkpoints, error) {
+	hashes, err := parseCheckpoints(m.Checkpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := cipher.SigFromHex(m.Sig)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid checkpoint manifest signature: %v", err)
+	}
+
+	if err := cipher.VerifyPubKeySignedHash(pubkey, sig, checkpointsHash(m.Checkpoints, hashes)); err != nil {
+		return nil, fmt.Errorf("Checkpoint manifest signature verification failed: %v", err)
+	}
+
+	c := &Checkpoints{
+		hashes:  make(map[uint64]cipher.SHA256, len(hashes)),
+		lastSeq: m.Checkpoints[len(m.Checkpoints)-1].Seq,
+	}
+	for i, cp := range m.Checkpoints {
+		c.hashes[cp.Seq] = hashes[i]
+	}
+
+	return c, nil
+}
+
+// LoadCheckpointManifest loads a checkpoint manifest file and verifies it against the blockchain pubkey
+func LoadCheckpointManifest(path string, pubkey cipher.PubKey) (*Checkpoints, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m CheckpointManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("Invalid checkpoint manifest %s: %v", path, err)
+	}
+
+	return m.Verify(pubkey)
+}
+
+// CreateCheckpoints returns a checkpoint for every interval blocks of the blockchain, and for the head block
+func CreateCheckpoints(db *dbutil.DB, interval uint64) ([]Checkpoint, error) {
+	if interval == 0 {
+		return nil, errors.New("Checkpoint interval must be > 0")
+	}
+
+	bc, err := NewBlockchain(db, BlockchainConfig{})
+	if err != nil {
+		return nil, err
+	}
+
+	var cps []Checkpoint
+	if err := db.View("CreateCheckpoints", func(tx *dbutil.Tx) error {
+		headSeq, ok, err := bc.HeadSeq(tx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+
+		seqs := make([]uint64, 0, headSeq/interval+2)
+		for seq := uint64(0); seq <= headSeq; seq += interval {
+			seqs = append(seqs, seq)
+		}
+		if seqs[len(seqs)-1] != headSeq {
+			seqs = append(seqs, headSeq)
+		}
+
+		for _, seq := range seqs {
+			b, err := bc.GetSignedBlockBySeq(tx, seq)
+			if err != nil {
+				return err
+			}
+			if b == nil {
+				return fmt.Errorf("Block %d not found", seq)
+			}
+
+			cps = append(cps, Checkpoint{
+				Seq:  seq,
+				Hash: b.HashHeader().Hex(),
+			})
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return cps, nil
+}
@@ -0,0 +1,212 @@
+package visor
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestCheckpointManifest(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	otherPubkey, _ := cipher.GenerateKeyPair()
+
+	cps := []Checkpoint{
+		{Seq: 0, Hash: testutil.RandSHA256(t).Hex()},
+		{Seq: 10, Hash: testutil.RandSHA256(t).Hex()},
+	}
+
+	m, err := NewCheckpointManifest(cps, seckey)
+	require.NoError(t, err)
+
+	c, err := m.Verify(pubkey)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), c.LastSeq())
+
+	_, err = m.Verify(otherPubkey)
+	require.Error(t, err)
+
+	// Tampered checkpoint
+	tampered := *m
+	tampered.Checkpoints = []Checkpoint{cps[0], {Seq: 11, Hash: cps[1].Hash}}
+	_, err = tampered.Verify(pubkey)
+	require.Error(t, err)
+
+	_, err = NewCheckpointManifest(nil, seckey)
+	require.Equal(t, ErrNoCheckpoints, err)
+
+	_, err = NewCheckpointManifest([]Checkpoint{cps[1], cps[0]}, seckey)
+	require.Equal(t, ErrCheckpointsNotSorted, err)
+
+	_, err = NewCheckpointManifest([]Checkpoint{{Seq: 1, Hash: "foo"}}, seckey)
+	require.Error(t, err)
+
+	// Load from file
+	dir, err := ioutil.TempDir("", "checkpoints")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "checkpoints.json")
+	b, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(path, b, 0600))
+
+	c2, err := LoadCheckpointManifest(path, pubkey)
+	require.NoError(t, err)
+	require.Equal(t, c, c2)
+}
+
+func TestCheckDatabaseCheckpoints(t *testing.T) {
+	db, err := OpenDB("./testdata/data.db.ok", true)
+	require.NoError(t, err)
+	defer db.Close()
+
+	pubkey := cipher.MustPubKeyFromHex("0328c576d3f420e7682058a981173a4b374c7cc5ff55bf394d3cf57059bbe6456a")
+	wrongPubkey, _ := cipher.GenerateKeyPair()
+	_, seckey := cipher.GenerateKeyPair()
+
+	cps, err := CreateCheckpoints(db, 2)
+	require.NoError(t, err)
+	require.NotEmpty(t, cps)
+	require.Equal(t, uint64(0), cps[0].Seq)
+
+	m, err := NewCheckpointManifest(cps, seckey)
+	require.NoError(t, err)
+	checkpoints, err := m.Verify(cipher.MustPubKeyFromSecKey(seckey))
+	require.NoError(t, err)
+
+	require.NoError(t, CheckDatabase(db, pubkey, nil, nil))
+	require.NoError(t, CheckDatabase(db, pubkey, checkpoints, nil))
+
+	// The signatures of blocks covered by the checkpoints are not verified
+	require.Error(t, CheckDatabase(db, wrongPubkey, nil, nil))
+	require.NoError(t, CheckDatabase(db, wrongPubkey, checkpoints, nil))
+
+	// A block that does not match its checkpoint is detected
+	last := len(cps) - 1
+	storedHash := cipher.MustSHA256FromHex(cps[last].Hash)
+	cps[last].Hash = testutil.RandSHA256(t).Hex()
+	m, err = NewCheckpointManifest(cps, seckey)
+	require.NoError(t, err)
+	checkpoints, err = m.Verify(cipher.MustPubKeyFromSecKey(seckey))
+	require.NoError(t, err)
+
+	err = CheckDatabase(db, pubkey, checkpoints, nil)
+	require.Equal(t, ErrCheckpointMismatch{
+		Seq:  cps[last].Seq,
+		Hash: storedHash,
+	}, err)
+	desc, ok := describeCorruptDBError(err)
+	require.True(t, ok)
+	require.Equal(t, string(blockdb.BlocksBkt), desc.Bucket)
+
+	// The signatures of blocks after the last checkpoint at or below the head block are verified
+	cps[last].Hash = storedHash.Hex()
+	cps = append(cps, Checkpoint{
+		Seq:  cps[last].Seq + 100,
+		Hash: testutil.RandSHA256(t).Hex(),
+	})
+	cps = append(cps[:1], cps[len(cps)-1])
+	m, err = NewCheckpointManifest(cps, seckey)
+	require.NoError(t, err)
+	checkpoints, err = m.Verify(cipher.MustPubKeyFromSecKey(seckey))
+	require.NoError(t, err)
+
+	require.NoError(t, CheckDatabase(db, pubkey, checkpoints, nil))
+	require.Error(t, CheckDatabase(db, wrongPubkey, checkpoints, nil))
+}
+
+func TestCheckDatabaseCheckpointsChainBroken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoints")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	b, err := ioutil.ReadFile("./testdata/data.db.ok")
+	require.NoError(t, err)
+	dbPath := filepath.Join(dir, "data.db")
+	require.NoError(t, ioutil.WriteFile(dbPath, b, 0600))
+
+	db, err := OpenDB(dbPath, false)
+	require.NoError(t, err)
+	defer db.Close()
+
+	pubkey := cipher.MustPubKeyFromHex("0328c576d3f420e7682058a981173a4b374c7cc5ff55bf394d3cf57059bbe6456a")
+	_, seckey := cipher.GenerateKeyPair()
+
+	cps, err := CreateCheckpoints(db, 1000)
+	require.NoError(t, err)
+	require.Len(t, cps, 2)
+
+	m, err := NewCheckpointManifest(cps, seckey)
+	require.NoError(t, err)
+	checkpoints, err := m.Verify(cipher.MustPubKeyFromSecKey(seckey))
+	require.NoError(t, err)
+
+	bc, err := NewBlockchain(db, BlockchainConfig{Pubkey: pubkey})
+	require.NoError(t, err)
+
+	// Replace a block between the checkpoints with a block with a tampered header, as if it was added to the
+	// block tree with the signature of the original block. The next block's PrevHash is the original hash
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		sb, err := bc.GetSignedBlockBySeq(tx, 1)
+		require.NoError(t, err)
+
+		sb.Head.Time++
+		hash := sb.HashHeader()
+
+		if err := dbutil.PutBucketValue(tx, blockdb.BlocksBkt, hash[:], encoder.Serialize(sb.Block)); err != nil {
+			return err
+		}
+		if err := dbutil.PutBucketValue(tx, blockdb.BlockSigsBkt, hash[:], encoder.Serialize(struct {
+			Sig cipher.Sig
+		}{
+			Sig: sb.Sig,
+		})); err != nil {
+			return err
+		}
+		return dbutil.PutBucketValue(tx, blockdb.TreeBkt, dbutil.Itob(1), encoder.Serialize(struct {
+			HashPairs []coin.HashPair
+		}{
+			HashPairs: []coin.HashPair{
+				{
+					Hash:     hash,
+					PrevHash: sb.Head.PrevHash,
+				},
+			},
+		}))
+	})
+	require.NoError(t, err)
+
+	var nextHash cipher.SHA256
+	err = db.View("", func(tx *dbutil.Tx) error {
+		sb, err := bc.GetSignedBlockBySeq(tx, 2)
+		require.NoError(t, err)
+		nextHash = sb.HashHeader()
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = CheckDatabase(db, pubkey, checkpoints, nil)
+	require.Equal(t, ErrCheckpointChainBroken{
+		Seq:  2,
+		Hash: nextHash,
+	}, err)
+
+	desc, ok := describeCorruptDBError(err)
+	require.True(t, ok)
+	require.Equal(t, string(blockdb.BlocksBkt), desc.Bucket)
+	require.Equal(t, nextHash.Hex(), desc.Key)
+
+	// Without checkpoints, the signature of the tampered block does not verify
+	require.Error(t, CheckDatabase(db, pubkey, nil, nil))
+}
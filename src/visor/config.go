@@ -3,11 +3,18 @@ package visor
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/consensus"
 	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/transaction"
 )
 
+// DefaultMaxFutureBlockTime is the default Config.MaxFutureBlockTime
+const DefaultMaxFutureBlockTime = time.Minute * 2
+
 // Config configuration parameters for the Visor
 type Config struct {
 	// Is this a block publishing node
@@ -25,6 +32,33 @@ type Config struct {
 	CreateBlockVerifyTxn params.VerifyTxn
 	// Maximum size of a block, in bytes for creating blocks
 	MaxBlockTransactionsSize uint32
+	// Name of the registered BlockProducerPlugin used when creating blocks
+	BlockProducer string
+	// Parameter changes activated by the signals in the block headers, applied when creating blocks
+	Signaling consensus.SignalingConfig
+	// Signaling field of the blocks created by this node, with the bits of the supported deployments set
+	SignalingField uint32
+	// Upper bounds of the buckets of the block size histogram, in bytes, in increasing order
+	BlockSizeHistogramBuckets []uint32
+	// Blocks whose timestamp is more than this far ahead of the local clock are rejected. If 0, it is not checked
+	MaxFutureBlockTime time.Duration
+	// Blocks whose timestamp is less than this long after the previous block's timestamp are rejected. If 0, it is not checked.
+	// This is a consensus rule, all nodes of a chain must use the same value
+	MinBlockInterval time.Duration
+
+	// Maximum number of transactions in the unconfirmed pool. If 0, the pool size is unlimited
+	MaxUnconfirmedTransactions int
+	// When the unconfirmed pool is full, only transactions that have been in the pool
+	// for at least this long can be evicted to make room for a new transaction
+	UnconfirmedEvictionMinAge time.Duration
+	// Upper bounds of the buckets of the unconfirmed transaction age histogram, in increasing order
+	UnconfirmedAgeHistogramBuckets []time.Duration
+
+	// Transactions created by this node must not have outputs with fewer coins than this,
+	// in droplets. If 0, there is no dust threshold
+	DustThreshold uint64
+	// How change below the dust threshold is handled when creating transactions
+	DustPolicy transaction.DustPolicy
 
 	// Coin distribution parameters (necessary for txn verification)
 	Distribution params.Distribution
@@ -42,8 +76,24 @@ type Config struct {
 	GenesisTimestamp uint64
 	// Number of coins in genesis block
 	GenesisCoinVolume uint64
+	// If set, the genesis block distributes these outputs instead of sending
+	// GenesisCoinVolume coins to GenesisAddress
+	GenesisOutputs *GenesisOutputs
 	// enable arbitrating mode
 	Arbitrating bool
+
+	// If nonzero, the transactions of blocks older than this many blocks are deleted,
+	// leaving only their headers and signatures, along with their transactions and spent
+	// outputs in the history database. The unspent outputs are kept. Historical block,
+	// transaction and output data is not served for pruned blocks.
+	PruneOlderThanBlocks uint64
+
+	// If true, the outputs created and spent by each block are recorded in the event log
+	EnableEventLog bool
+
+	// Fill percent of the DB pages that are split when writing, between 0.1 and 1.
+	// If 0, bolt's default of 0.5 is used
+	DBFillPercent float64
 }
 
 // NewConfig creates Config
@@ -54,9 +104,18 @@ func NewConfig() Config {
 		BlockchainPubkey: cipher.PubKey{},
 		BlockchainSeckey: cipher.SecKey{},
 
-		UnconfirmedVerifyTxn:     params.UserVerifyTxn,
-		CreateBlockVerifyTxn:     params.UserVerifyTxn,
-		MaxBlockTransactionsSize: params.UserVerifyTxn.MaxTransactionSize,
+		UnconfirmedVerifyTxn:      params.UserVerifyTxn,
+		CreateBlockVerifyTxn:      params.UserVerifyTxn,
+		MaxBlockTransactionsSize:  params.UserVerifyTxn.MaxTransactionSize,
+		BlockProducer:             DefaultBlockProducerName,
+		Signaling:                 consensus.NewSignalingConfig(),
+		BlockSizeHistogramBuckets: DefaultBlockSizeHistogramBuckets,
+		MaxFutureBlockTime:        DefaultMaxFutureBlockTime,
+
+		UnconfirmedEvictionMinAge:      DefaultUnconfirmedEvictionMinAge,
+		UnconfirmedAgeHistogramBuckets: DefaultUnconfirmedAgeHistogramBuckets,
+
+		DustPolicy: transaction.DustPolicyReject,
 
 		GenesisAddress:    cipher.Address{},
 		GenesisSignature:  cipher.Sig{},
@@ -67,53 +126,161 @@ func NewConfig() Config {
 	return c
 }
 
-// Verify verifies the configuration
+// ConfigError is a problem with the value of a Config field
+type ConfigError struct {
+	Field string
+	Err   error
+}
+
+// Error returns the error message, prefixed with the field name unless the message already starts with it
+func (e ConfigError) Error() string {
+	msg := e.Err.Error()
+	if strings.HasPrefix(msg, e.Field) {
+		return msg
+	}
+	return fmt.Sprintf("%s: %s", e.Field, msg)
+}
+
+// ConfigErrors is a list of ConfigError
+type ConfigErrors []ConfigError
+
+func (e ConfigErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Verify verifies the configuration, returning the first problem found by ValidateConfig
 func (c Config) Verify() error {
+	if errs := ValidateConfig(c); len(errs) != 0 {
+		return errs[0].Err
+	}
+	return nil
+}
+
+// ValidateConfig checks all fields of the configuration and returns every problem found, in field order
+func ValidateConfig(c Config) []ConfigError {
+	var errs []ConfigError
+	add := func(field string, err error) {
+		errs = append(errs, ConfigError{
+			Field: field,
+			Err:   err,
+		})
+	}
+
+	// The genesis block can't be created with a secret key of another blockchain
+	if c.BlockchainSeckey != (cipher.SecKey{}) || c.IsBlockPublisher {
+		if pubkey, err := cipher.PubKeyFromSecKey(c.BlockchainSeckey); err != nil || pubkey != c.BlockchainPubkey {
+			if c.IsBlockPublisher {
+				add("BlockchainSeckey", errors.New("Cannot run as block publisher: invalid seckey for pubkey"))
+			} else {
+				add("BlockchainSeckey", errors.New("BlockchainSeckey does not match BlockchainPubkey"))
+			}
+		}
+	}
+
 	if c.IsBlockPublisher {
-		if c.BlockchainPubkey != cipher.MustPubKeyFromSecKey(c.BlockchainSeckey) {
-			return errors.New("Cannot run as block publisher: invalid seckey for pubkey")
+		if _, err := GetBlockProducer(c.BlockProducer); err != nil {
+			add("BlockProducer", err)
+		}
+
+		if c.PruneOlderThanBlocks != 0 {
+			add("PruneOlderThanBlocks", errors.New("A block publisher node cannot prune blocks"))
 		}
 	}
 
 	if err := c.UnconfirmedVerifyTxn.Validate(); err != nil {
-		return err
+		add("UnconfirmedVerifyTxn", err)
+	} else {
+		if c.UnconfirmedVerifyTxn.BurnFactor < params.UserVerifyTxn.BurnFactor {
+			add("UnconfirmedVerifyTxn.BurnFactor", fmt.Errorf("UnconfirmedVerifyTxn.BurnFactor must be >= params.UserVerifyTxn.BurnFactor (%d)", params.UserVerifyTxn.BurnFactor))
+		}
+
+		if c.UnconfirmedVerifyTxn.MaxTransactionSize < params.UserVerifyTxn.MaxTransactionSize {
+			add("UnconfirmedVerifyTxn.MaxTransactionSize", fmt.Errorf("UnconfirmedVerifyTxn.MaxTransactionSize must be >= params.UserVerifyTxn.MaxTransactionSize (%d)", params.UserVerifyTxn.MaxTransactionSize))
+		}
+
+		if c.UnconfirmedVerifyTxn.MaxDropletPrecision < params.UserVerifyTxn.MaxDropletPrecision {
+			add("UnconfirmedVerifyTxn.MaxDropletPrecision", fmt.Errorf("UnconfirmedVerifyTxn.MaxDropletPrecision must be >= params.UserVerifyTxn.MaxDropletPrecision (%d)", params.UserVerifyTxn.MaxDropletPrecision))
+		}
+
+		if c.UnconfirmedVerifyTxn.MinFeePerByte < params.UserVerifyTxn.MinFeePerByte {
+			add("UnconfirmedVerifyTxn.MinFeePerByte", fmt.Errorf("UnconfirmedVerifyTxn.MinFeePerByte must be >= params.UserVerifyTxn.MinFeePerByte (%d)", params.UserVerifyTxn.MinFeePerByte))
+		}
 	}
 
 	if err := c.CreateBlockVerifyTxn.Validate(); err != nil {
-		return err
+		add("CreateBlockVerifyTxn", err)
+	} else {
+		if c.CreateBlockVerifyTxn.BurnFactor < params.UserVerifyTxn.BurnFactor {
+			add("CreateBlockVerifyTxn.BurnFactor", fmt.Errorf("CreateBlockVerifyTxn.BurnFactor must be >= params.UserVerifyTxn.BurnFactor (%d)", params.UserVerifyTxn.BurnFactor))
+		}
+
+		if c.CreateBlockVerifyTxn.MaxTransactionSize < params.UserVerifyTxn.MaxTransactionSize {
+			add("CreateBlockVerifyTxn.MaxTransactionSize", fmt.Errorf("CreateBlockVerifyTxn.MaxTransactionSize must be >= params.UserVerifyTxn.MaxTransactionSize (%d)", params.UserVerifyTxn.MaxTransactionSize))
+		}
+
+		if c.CreateBlockVerifyTxn.MaxDropletPrecision < params.UserVerifyTxn.MaxDropletPrecision {
+			add("CreateBlockVerifyTxn.MaxDropletPrecision", fmt.Errorf("CreateBlockVerifyTxn.MaxDropletPrecision must be >= params.UserVerifyTxn.MaxDropletPrecision (%d)", params.UserVerifyTxn.MaxDropletPrecision))
+		}
+
+		if c.CreateBlockVerifyTxn.MinFeePerByte < params.UserVerifyTxn.MinFeePerByte {
+			add("CreateBlockVerifyTxn.MinFeePerByte", fmt.Errorf("CreateBlockVerifyTxn.MinFeePerByte must be >= params.UserVerifyTxn.MinFeePerByte (%d)", params.UserVerifyTxn.MinFeePerByte))
+		}
+
+		if c.MaxBlockTransactionsSize < c.CreateBlockVerifyTxn.MaxTransactionSize {
+			add("MaxBlockTransactionsSize", errors.New("MaxBlockTransactionsSize must be >= CreateBlockVerifyTxn.MaxTransactionSize"))
+		}
 	}
 
-	if c.UnconfirmedVerifyTxn.BurnFactor < params.UserVerifyTxn.BurnFactor {
-		return fmt.Errorf("UnconfirmedVerifyTxn.BurnFactor must be >= params.UserVerifyTxn.BurnFactor (%d)", params.UserVerifyTxn.BurnFactor)
+	if err := verifySignalingConfig(c); err != nil {
+		add("Signaling", err)
 	}
 
-	if c.CreateBlockVerifyTxn.BurnFactor < params.UserVerifyTxn.BurnFactor {
-		return fmt.Errorf("CreateBlockVerifyTxn.BurnFactor must be >= params.UserVerifyTxn.BurnFactor (%d)", params.UserVerifyTxn.BurnFactor)
+	if err := verifyBlockSizeHistogramBuckets(c.BlockSizeHistogramBuckets); err != nil {
+		add("BlockSizeHistogramBuckets", err)
 	}
 
-	if c.UnconfirmedVerifyTxn.MaxTransactionSize < params.UserVerifyTxn.MaxTransactionSize {
-		return fmt.Errorf("UnconfirmedVerifyTxn.MaxTransactionSize must be >= params.UserVerifyTxn.MaxTransactionSize (%d)", params.UserVerifyTxn.MaxTransactionSize)
+	if c.MaxFutureBlockTime < 0 {
+		add("MaxFutureBlockTime", errors.New("MaxFutureBlockTime must be >= 0"))
 	}
 
-	if c.CreateBlockVerifyTxn.MaxTransactionSize < params.UserVerifyTxn.MaxTransactionSize {
-		return fmt.Errorf("CreateBlockVerifyTxn.MaxTransactionSize must be >= params.UserVerifyTxn.MaxTransactionSize (%d)", params.UserVerifyTxn.MaxTransactionSize)
+	if c.MinBlockInterval < 0 {
+		add("MinBlockInterval", errors.New("MinBlockInterval must be >= 0"))
 	}
 
-	if c.UnconfirmedVerifyTxn.MaxDropletPrecision < params.UserVerifyTxn.MaxDropletPrecision {
-		return fmt.Errorf("UnconfirmedVerifyTxn.MaxDropletPrecision must be >= params.UserVerifyTxn.MaxDropletPrecision (%d)", params.UserVerifyTxn.MaxDropletPrecision)
+	if c.MaxUnconfirmedTransactions < 0 {
+		add("MaxUnconfirmedTransactions", errors.New("MaxUnconfirmedTransactions must be >= 0"))
 	}
 
-	if c.CreateBlockVerifyTxn.MaxDropletPrecision < params.UserVerifyTxn.MaxDropletPrecision {
-		return fmt.Errorf("CreateBlockVerifyTxn.MaxDropletPrecision must be >= params.UserVerifyTxn.MaxDropletPrecision (%d)", params.UserVerifyTxn.MaxDropletPrecision)
+	if err := verifyUnconfirmedAgeHistogramBuckets(c.UnconfirmedAgeHistogramBuckets); err != nil {
+		add("UnconfirmedAgeHistogramBuckets", err)
 	}
 
-	if c.MaxBlockTransactionsSize < c.CreateBlockVerifyTxn.MaxTransactionSize {
-		return errors.New("MaxBlockTransactionsSize must be >= CreateBlockVerifyTxn.MaxTransactionSize")
+	if c.UnconfirmedEvictionMinAge < 0 {
+		add("UnconfirmedEvictionMinAge", errors.New("UnconfirmedEvictionMinAge must be >= 0"))
+	}
+
+	if c.DustPolicy != "" {
+		if _, err := transaction.ParseDustPolicy(string(c.DustPolicy)); err != nil {
+			add("DustPolicy", err)
+		}
+	}
+
+	// The event log reads the source transactions of the spent outputs, which may be in pruned blocks
+	if c.PruneOlderThanBlocks != 0 && c.EnableEventLog {
+		add("EnableEventLog", errors.New("The event log cannot be enabled on a node that prunes blocks"))
+	}
+
+	if c.DBFillPercent != 0 && (c.DBFillPercent < 0.1 || c.DBFillPercent > 1) {
+		add("DBFillPercent", errors.New("DBFillPercent must be 0 or between 0.1 and 1"))
 	}
 
 	if err := c.Distribution.Validate(); err != nil {
-		return err
+		add("Distribution", err)
 	}
 
-	return nil
+	return errs
 }
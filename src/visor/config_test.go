@@ -0,0 +1,85 @@
+package visor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/params"
+)
+
+func TestValidateConfig(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Distribution = params.MainNetDistribution
+	require.Empty(t, ValidateConfig(cfg))
+	require.NoError(t, cfg.Verify())
+
+	// All problems are returned, in field order
+	_, sk := cipher.GenerateKeyPair()
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = sk
+	cfg.UnconfirmedVerifyTxn.BurnFactor = 1
+	cfg.MaxUnconfirmedTransactions = -1
+	cfg.DustPolicy = "foo"
+	cfg.DBFillPercent = 2
+
+	errs := ValidateConfig(cfg)
+	require.Len(t, errs, 5)
+
+	fields := make([]string, len(errs))
+	for i, e := range errs {
+		fields[i] = e.Field
+	}
+	require.Equal(t, []string{
+		"BlockchainSeckey",
+		"UnconfirmedVerifyTxn",
+		"MaxUnconfirmedTransactions",
+		"DustPolicy",
+		"DBFillPercent",
+	}, fields)
+
+	// Verify returns the first problem
+	require.Equal(t, errs[0].Err, cfg.Verify())
+	require.Equal(t, errors.New("BlockchainSeckey does not match BlockchainPubkey"), cfg.Verify())
+
+	// The field name is not repeated if the message starts with it
+	require.Equal(t, "BlockchainSeckey does not match BlockchainPubkey", errs[0].Error())
+	require.Equal(t, "DustPolicy: "+errs[3].Err.Error(), errs[3].Error())
+	require.Equal(t, "MaxUnconfirmedTransactions must be >= 0; DBFillPercent must be 0 or between 0.1 and 1", ConfigErrors{errs[2], errs[4]}.Error())
+
+	// A block publisher needs the blockchain secret key
+	cfg = NewConfig()
+	cfg.Distribution = params.MainNetDistribution
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockProducer = "foo"
+	cfg.PruneOlderThanBlocks = 10
+
+	errs = ValidateConfig(cfg)
+	require.Len(t, errs, 3)
+	require.Equal(t, ConfigError{
+		Field: "BlockchainSeckey",
+		Err:   errors.New("Cannot run as block publisher: invalid seckey for pubkey"),
+	}, errs[0])
+	require.Equal(t, "BlockProducer", errs[1].Field)
+	require.Equal(t, ConfigError{
+		Field: "PruneOlderThanBlocks",
+		Err:   errors.New("A block publisher node cannot prune blocks"),
+	}, errs[2])
+
+	cfg.BlockchainSeckey = genSecret
+	cfg.BlockProducer = DefaultBlockProducerName
+	cfg.PruneOlderThanBlocks = 0
+	require.Empty(t, ValidateConfig(cfg))
+
+	// The event log can't be kept by a pruned node
+	cfg.IsBlockPublisher = false
+	cfg.PruneOlderThanBlocks = 10
+	cfg.EnableEventLog = true
+	require.Equal(t, []ConfigError{{
+		Field: "EnableEventLog",
+		Err:   errors.New("The event log cannot be enabled on a node that prunes blocks"),
+	}}, ValidateConfig(cfg))
+}
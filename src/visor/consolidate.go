@@ -0,0 +1,166 @@
+package visor
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/util/mathutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+var (
+	// ErrInvalidConsolidationMaxInputs is returned if fewer than 2 outputs may be consolidated
+	ErrInvalidConsolidationMaxInputs = NewUserError(errors.New("max inputs must be at least 2"))
+	// ErrNothingToConsolidate is returned if an address has fewer than 2 unspent outputs to consolidate
+	ErrNothingToConsolidate = NewUserError(errors.New("address has fewer than 2 unspent outputs to consolidate"))
+)
+
+// MaxConsolidationInputs returns the maximum number of inputs of a consolidation transaction,
+// a transaction with a single output, for the maximum transaction size
+func MaxConsolidationInputs(maxTxnSize uint32) (int, error) {
+	txn := coin.Transaction{
+		Out: []coin.TransactionOutput{{}},
+	}
+	baseSize, err := txn.Size()
+	if err != nil {
+		return 0, err
+	}
+
+	txn.In = []cipher.SHA256{{}}
+	txn.Sigs = []cipher.Sig{{}}
+	size, err := txn.Size()
+	if err != nil {
+		return 0, err
+	}
+
+	if maxTxnSize < baseSize {
+		return 0, nil
+	}
+
+	n := int((maxTxnSize - baseSize) / (size - baseSize))
+	if n >= math.MaxUint16 {
+		n = math.MaxUint16 - 1
+	}
+
+	return n, nil
+}
+
+// CreateConsolidationParams returns the parameters of a transaction that consolidates the unspent outputs
+// of a wallet address, to be passed to WalletCreateTransaction or WalletCreateTransactionSigned.
+// Up to maxInputs of the outputs with the fewest coins are spent, and all of their coins and hours,
+// minus the fee, are sent back to the address.
+// If maxInputs is 0, or is more than fit in a transaction of the maximum user transaction size
+// or than the maximum user transaction inputs, as many outputs as fit are spent.
+// Outputs spent by unconfirmed transactions are not spent.
+func (vs *Visor) CreateConsolidationParams(wltID string, addr cipher.Address, maxInputs int) (transaction.Params, CreateTransactionParams, error) {
+	if maxInputs < 0 || maxInputs == 1 {
+		return transaction.Params{}, CreateTransactionParams{}, ErrInvalidConsolidationMaxInputs
+	}
+
+	limit, err := MaxConsolidationInputs(params.UserVerifyTxn.MaxTransactionSize)
+	if err != nil {
+		return transaction.Params{}, CreateTransactionParams{}, err
+	}
+	if params.UserVerifyTxn.MaxTxInputs > 0 && limit > params.UserVerifyTxn.MaxTxInputs {
+		limit = params.UserVerifyTxn.MaxTxInputs
+	}
+	if maxInputs == 0 || maxInputs > limit {
+		maxInputs = limit
+	}
+
+	if err := vs.wallets.View(wltID, func(w wallet.Wallet) error {
+		addrs, err := w.GetAddresses()
+		if err != nil {
+			return err
+		}
+
+		for _, a := range wallet.SkycoinAddresses(addrs) {
+			if a == addr {
+				return nil
+			}
+		}
+
+		return wallet.ErrUnknownAddress
+	}); err != nil {
+		return transaction.Params{}, CreateTransactionParams{}, err
+	}
+
+	var uxouts coin.UxArray
+	if err := vs.db.View("CreateConsolidationParams", func(tx *dbutil.Tx) error {
+		auxs, err := vs.getCreateTransactionAuxsAddress(tx, []cipher.Address{addr}, true)
+		if err != nil {
+			return err
+		}
+
+		uxouts = auxs[addr]
+		return nil
+	}); err != nil {
+		switch err {
+		case transaction.ErrNoUnspents, ErrNoSpendableOutputs:
+			return transaction.Params{}, CreateTransactionParams{}, ErrNothingToConsolidate
+		default:
+			return transaction.Params{}, CreateTransactionParams{}, err
+		}
+	}
+
+	if len(uxouts) < 2 {
+		return transaction.Params{}, CreateTransactionParams{}, ErrNothingToConsolidate
+	}
+
+	// Spend the smallest outputs first. Ties are broken by hours then hash, so the selection is deterministic
+	sort.Slice(uxouts, func(i, j int) bool {
+		a, b := uxouts[i], uxouts[j]
+		if a.Body.Coins != b.Body.Coins {
+			return a.Body.Coins < b.Body.Coins
+		}
+		if a.Body.Hours != b.Body.Hours {
+			return a.Body.Hours < b.Body.Hours
+		}
+		ah, bh := a.Hash(), b.Hash()
+		return bytes.Compare(ah[:], bh[:]) < 0
+	})
+
+	if len(uxouts) > maxInputs {
+		uxouts = uxouts[:maxInputs]
+	}
+
+	var coins uint64
+	hashes := make([]cipher.SHA256, len(uxouts))
+	for i, ux := range uxouts {
+		coins, err = mathutil.AddUint64(coins, ux.Body.Coins)
+		if err != nil {
+			return transaction.Params{}, CreateTransactionParams{}, err
+		}
+		hashes[i] = ux.Hash()
+	}
+
+	shareFactor := decimal.New(1, 0)
+	changeAddr := addr
+	p := transaction.Params{
+		HoursSelection: transaction.HoursSelection{
+			Type:        transaction.HoursSelectionTypeAuto,
+			Mode:        transaction.HoursSelectionModeShare,
+			ShareFactor: &shareFactor,
+		},
+		To: []coin.TransactionOutput{
+			{
+				Address: addr,
+				Coins:   coins,
+			},
+		},
+		ChangeAddress: &changeAddr,
+	}
+
+	return p, CreateTransactionParams{
+		UxOuts: hashes,
+	}, nil
+}
@@ -0,0 +1,143 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/wallet"
+	"github.com/skycoin/skycoin/src/wallet/collection"
+	"github.com/skycoin/skycoin/src/wallet/crypto"
+)
+
+func TestMaxConsolidationInputs(t *testing.T) {
+	n, err := MaxConsolidationInputs(params.UserVerifyTxn.MaxTransactionSize)
+	require.NoError(t, err)
+	require.Equal(t, 336, n)
+
+	// The largest consolidation transaction fits in the maximum transaction size, one more input does not
+	txn := coin.Transaction{
+		In:   make([]cipher.SHA256, n),
+		Sigs: make([]cipher.Sig, n),
+		Out:  []coin.TransactionOutput{{}},
+	}
+	size, err := txn.Size()
+	require.NoError(t, err)
+	require.True(t, size <= params.UserVerifyTxn.MaxTransactionSize)
+
+	txn.In = append(txn.In, cipher.SHA256{})
+	txn.Sigs = append(txn.Sigs, cipher.Sig{})
+	size, err = txn.Size()
+	require.NoError(t, err)
+	require.True(t, size > params.UserVerifyTxn.MaxTransactionSize)
+
+	n, err = MaxConsolidationInputs(10)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+}
+
+func TestCreateConsolidationParams(t *testing.T) {
+	v, shutdown := newChainExportTestVisor(t)
+	defer shutdown()
+
+	ws, err := wallet.NewService(wallet.Config{
+		EnableWalletAPI: true,
+		CryptoType:      crypto.CryptoTypeScryptChacha20poly1305Insecure,
+		WalletDir:       prepareWltDir(),
+	})
+	require.NoError(t, err)
+	v.wallets = ws
+
+	_, err = ws.CreateWallet("foo.wlt", wallet.Options{
+		Coin: wallet.CoinTypeSkycoin,
+		Type: wallet.WalletTypeCollection,
+	})
+	require.NoError(t, err)
+
+	entries, addrs := makeEntries(2)
+	addr, unused := addrs[0], addrs[1]
+	err = ws.UpdateSecrets("foo.wlt", nil, func(w wallet.Wallet) error {
+		for _, e := range entries {
+			if err := w.(*collection.Wallet).AddEntry(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Send 4 outputs to addr, with the change back to the genesis address
+	gb := addGenesisBlockToVisor(t, v)
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+
+	txn := coin.Transaction{}
+	err = txn.PushInput(uxs[0].Hash())
+	require.NoError(t, err)
+	sent := uint64(0)
+	for _, coins := range []uint64{4e6, 1e6, 3e6, 2e6} {
+		err = txn.PushOutput(addr, coins, 1000)
+		require.NoError(t, err)
+		sent += coins
+	}
+	err = txn.PushOutput(genAddress, uxs[0].Body.Coins-sent, 1000)
+	require.NoError(t, err)
+	txn.SignInputs([]cipher.SecKey{genSecret})
+	err = txn.UpdateHeader()
+	require.NoError(t, err)
+
+	err = v.db.Update("", func(tx *dbutil.Tx) error {
+		b, err := v.blockchain.NewBlock(tx, coin.Transactions{txn}, genTime+100)
+		require.NoError(t, err)
+
+		return v.executeSignedBlock(tx, coin.SignedBlock{
+			Block: *b,
+			Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+		})
+	})
+	require.NoError(t, err)
+
+	txnID := txn.Hash()
+	uxID := func(i int) cipher.SHA256 {
+		return txn.Out[i].UxID(txnID)
+	}
+
+	_, _, err = v.CreateConsolidationParams("foo.wlt", addr, 1)
+	require.Equal(t, ErrInvalidConsolidationMaxInputs, err)
+
+	_, _, err = v.CreateConsolidationParams("bar.wlt", addr, 0)
+	require.Equal(t, wallet.ErrWalletNotExist, err)
+
+	_, _, err = v.CreateConsolidationParams("foo.wlt", testutil.MakeAddress(), 0)
+	require.Equal(t, wallet.ErrUnknownAddress, err)
+
+	_, _, err = v.CreateConsolidationParams("foo.wlt", unused, 0)
+	require.Equal(t, ErrNothingToConsolidate, err)
+
+	// The smallest outputs are spent first
+	p, wp, err := v.CreateConsolidationParams("foo.wlt", addr, 3)
+	require.NoError(t, err)
+	require.Equal(t, []cipher.SHA256{uxID(1), uxID(3), uxID(2)}, wp.UxOuts)
+	require.Equal(t, []coin.TransactionOutput{{Address: addr, Coins: 6e6}}, p.To)
+	require.Equal(t, addr, *p.ChangeAddress)
+
+	// All outputs are spent without a limit
+	p, wp, err = v.CreateConsolidationParams("foo.wlt", addr, 0)
+	require.NoError(t, err)
+	require.Equal(t, []cipher.SHA256{uxID(1), uxID(3), uxID(2), uxID(0)}, wp.UxOuts)
+	require.Equal(t, []coin.TransactionOutput{{Address: addr, Coins: 10e6}}, p.To)
+
+	// The transaction created from the params spends the outputs to a single output of addr
+	ctxn, inputs, err := v.WalletCreateTransactionSigned("foo.wlt", nil, p, wp)
+	require.NoError(t, err)
+	require.Len(t, ctxn.In, 4)
+	require.Len(t, inputs, 4)
+	require.Len(t, ctxn.Out, 1)
+	require.Equal(t, addr, ctxn.Out[0].Address)
+	require.Equal(t, uint64(10e6), ctxn.Out[0].Coins)
+	require.NotEqual(t, uint64(0), ctxn.Out[0].Hours)
+}
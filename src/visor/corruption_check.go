@@ -0,0 +1,137 @@
+package visor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// ErrCorruptionCheckRunning is returned by Visor.StartCorruptionCheck if another check is already running
+var ErrCorruptionCheckRunning = errors.New("a database corruption check is already running")
+
+// corruptionCheckSuffix is appended to the database path for the path of the snapshot checked by StartCorruptionCheck
+const corruptionCheckSuffix = ".corruption-check"
+
+// CorruptionCheck is the state of a database corruption check started by Visor.StartCorruptionCheck
+type CorruptionCheck struct {
+	Running   bool
+	StartedAt time.Time
+	// FinishedAt is zero while the check is running
+	FinishedAt time.Time
+	// Descriptions are the corrupted entries found by the check, empty if the database is not corrupted
+	Descriptions []CorruptionDescription
+	// Err is the error that stopped the check, if it did not complete.
+	// A corrupted database is reported by Descriptions, not by Err
+	Err error
+}
+
+// corruptionChecker holds the state of the last database corruption check
+type corruptionChecker struct {
+	sync.Mutex
+	check *CorruptionCheck
+	quit  chan struct{}
+	done  chan struct{}
+}
+
+// StartCorruptionCheck starts checking the database like DescribeCorruption in the background, without resetting it.
+// No checkpoints are used, the signatures of all blocks are verified.
+// The check runs on a read-only snapshot of the database, so it does not hold a View transaction
+// of the database open while blocks are executed, and all entries are checked at the same block.
+// Only one check runs at a time, ErrCorruptionCheckRunning is returned if another check is running.
+// The state of the check is returned by GetCorruptionCheck
+func (vs *Visor) StartCorruptionCheck() error {
+	cc := vs.corruptionChecker
+	cc.Lock()
+	defer cc.Unlock()
+
+	if cc.check != nil && cc.check.Running {
+		return ErrCorruptionCheckRunning
+	}
+
+	check := &CorruptionCheck{
+		Running:   true,
+		StartedAt: time.Now().UTC(),
+	}
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	cc.check = check
+	cc.quit = quit
+	cc.done = done
+
+	go func() {
+		defer close(done)
+
+		descs, err := describeSnapshotCorruption(vs.db, vs.db.Path()+corruptionCheckSuffix, vs.Config.BlockchainPubkey, quit)
+		if err != nil {
+			logger.WithError(err).Error("Database corruption check failed")
+		}
+
+		cc.Lock()
+		defer cc.Unlock()
+		check.Running = false
+		check.FinishedAt = time.Now().UTC()
+		check.Descriptions = descs
+		check.Err = err
+	}()
+
+	return nil
+}
+
+// GetCorruptionCheck returns the state of the last database corruption check started by StartCorruptionCheck,
+// or nil if no check was started
+func (vs *Visor) GetCorruptionCheck() *CorruptionCheck {
+	cc := vs.corruptionChecker
+	cc.Lock()
+	defer cc.Unlock()
+
+	if cc.check == nil {
+		return nil
+	}
+
+	c := *cc.check
+	return &c
+}
+
+// StopCorruptionCheck stops the running database corruption check, if any, and waits for it to finish
+// and remove its snapshot of the database
+func (vs *Visor) StopCorruptionCheck() {
+	cc := vs.corruptionChecker
+	cc.Lock()
+	if cc.check == nil || !cc.check.Running {
+		cc.Unlock()
+		return
+	}
+	if cc.quit != nil {
+		close(cc.quit)
+		cc.quit = nil
+	}
+	done := cc.done
+	cc.Unlock()
+
+	<-done
+}
+
+// describeSnapshotCorruption copies a read-only snapshot of db to path and describes its corruption.
+// The snapshot is removed after the check. A file left at path by an interrupted check is replaced
+func describeSnapshotCorruption(db *dbutil.DB, path string, pubkey cipher.PubKey, quit chan struct{}) ([]CorruptionDescription, error) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	snapshot, err := db.ReadOnlyClone(path)
+	if err != nil {
+		return nil, fmt.Errorf("db.ReadOnlyClone failed: %v", err)
+	}
+	defer func() {
+		if err := snapshot.Close(); err != nil {
+			logger.WithError(err).Errorf("Failed to close database snapshot %s", path)
+		}
+	}()
+
+	return DescribeCorruption(snapshot, pubkey, nil, quit)
+}
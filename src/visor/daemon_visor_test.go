@@ -27,7 +27,7 @@ import (
 func setupSimpleVisor(t *testing.T, db *dbutil.DB, bc *Blockchain) *Visor {
 	cfg := NewConfig()
 
-	pool, err := NewUnconfirmedTransactionPool(db)
+	pool, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolConfig{})
 	require.NoError(t, err)
 
 	return &Visor{
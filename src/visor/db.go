@@ -34,8 +34,11 @@ type ErrCorruptDB struct {
 	error
 }
 
-// CheckDatabase checks the database for corruption, rebuild history if corrupted
-func CheckDatabase(db *dbutil.DB, pubkey cipher.PubKey, quit chan struct{}) error {
+// CheckDatabase checks the database for corruption, rebuild history if corrupted.
+// If checkpoints is not nil, the PrevHash chain of the blocks up to the last checkpoint at or below the head block
+// is verified against the checkpoint hashes, and the signatures of these blocks are not verified.
+// The signatures of the blocks after it are verified.
+func CheckDatabase(db *dbutil.DB, pubkey cipher.PubKey, checkpoints *Checkpoints, quit chan struct{}) error {
 	elapser := elapse.NewElapser(time.Second*30, logger)
 	elapser.Register("CheckDatabase")
 	defer elapser.CheckForDone()
@@ -61,12 +64,24 @@ func CheckDatabase(db *dbutil.DB, pubkey cipher.PubKey, quit chan struct{}) erro
 	history := historydb.New()
 	indexesMap := historydb.NewIndexesMap()
 
+	var trustedSeq uint64
+	var hasTrusted bool
+	if err := db.View("CheckDatabase checkpoints", func(tx *dbutil.Tx) error {
+		var err error
+		trustedSeq, hasTrusted, err = checkpoints.verifyChain(tx, bc, quit)
+		return err
+	}); err != nil {
+		return err
+	}
+
 	var historyVerifyErr error
 	var lock sync.Mutex
 	verifyFunc := func(tx *dbutil.Tx, b *coin.SignedBlock) error {
-		// Verify signature
-		if err := bc.VerifySignature(b); err != nil {
-			return err
+		// Verify signature, unless the block is covered by a checkpoint
+		if !hasTrusted || b.Head.BkSeq > trustedSeq {
+			if err := bc.VerifySignature(b); err != nil {
+				return err
+			}
 		}
 
 		// Verify historydb, we don't return the error of history.Verify here,
@@ -118,6 +133,8 @@ func rebuildHistoryDB(db *dbutil.DB, history *historydb.HistoryDB, bc *Blockchai
 			case <-quit:
 				return nil
 			default:
+				prefetchAhead(bc, 0, i)
+
 				b, err := bc.GetSignedBlockBySeq(tx, i)
 				if err != nil {
 					return err
@@ -164,31 +181,93 @@ func backupDB(db *dbutil.DB) (*dbutil.DB, error) { //nolint:unused,megacheck
 // ResetCorruptDB checks the database for corruption and if one of the following
 // error types is found, then the database is deemed to be corrupted:
 // - blockdb.ErrMissingSignature,
-// - historydb.ErrHistoryDBCorrupted
+// - historydb.ErrHistoryDBCorrupted,
+// - ErrCheckpointMismatch,
+// - ErrCheckpointChainBroken,
 // - encoder.ErrBufferUnderflow
 // - encoder.ErrMaxLenExceeded
 // If the database is deemed to be corrupted then it is erased and the db starts over.
 // A copy of the corrupted database is saved.
-func ResetCorruptDB(db *dbutil.DB, pubkey cipher.PubKey, quit chan struct{}) (*dbutil.DB, error) {
-	err := CheckDatabase(db, pubkey, quit)
+func ResetCorruptDB(db *dbutil.DB, pubkey cipher.PubKey, checkpoints *Checkpoints, quit chan struct{}) (*dbutil.DB, error) {
+	err := CheckDatabase(db, pubkey, checkpoints, quit)
+	if err == nil {
+		return db, nil
+	}
+
+	if _, ok := describeCorruptDBError(err); !ok {
+		return nil, err
+	}
+
+	logger.Critical().Errorf("Database is corrupted, recreating db: %v", err)
+	return resetCorruptDB(db)
+}
+
+// CorruptionDescription describes a corrupted entry of the database
+type CorruptionDescription struct {
+	// Bucket is the name of the bucket of the entry, empty if unknown
+	Bucket string
+	// Key is the hex hash, or the address for the address index buckets, of the entry. Empty if unknown
+	Key string
+	Err error
+}
+
+// DescribeCorruption checks the database like ResetCorruptDB, but returns the corrupted entries instead of
+// resetting the database. Nothing is written to the database.
+// CheckDatabase stops at the first corrupted entry, so at most one entry is returned,
+// but a reset would move away the whole database, not only the entry.
+// Errors for which ResetCorruptDB would not reset the database are returned as errors.
+func DescribeCorruption(db *dbutil.DB, pubkey cipher.PubKey, checkpoints *Checkpoints, quit chan struct{}) ([]CorruptionDescription, error) {
+	err := CheckDatabase(db, pubkey, checkpoints, quit)
+	if err == nil {
+		return nil, nil
+	}
+
+	desc, ok := describeCorruptDBError(err)
+	if !ok {
+		return nil, err
+	}
+
+	return []CorruptionDescription{desc}, nil
+}
 
-	// Check if an encoder error has been reported.
-	// These are not types like the errors below so cannot be included in the
-	// .(type) switch evaluation.
+// describeCorruptDBError returns the description of err and true if err is one of the corruption errors
+// for which ResetCorruptDB resets the database
+func describeCorruptDBError(err error) (CorruptionDescription, bool) {
+	// Encoder errors are not types like the errors below so cannot be included in the
+	// .(type) switch evaluation. The entry that failed to decode is not known
 	if err == encoder.ErrBufferUnderflow || err == encoder.ErrMaxLenExceeded {
-		logger.Critical().Errorf("Database is corrupted (encoder error), recreating db: %v", err)
-		return resetCorruptDB(db)
+		return CorruptionDescription{
+			Err: err,
+		}, true
 	}
 
-	switch err.(type) {
-	case nil:
-		return db, nil
-	case blockdb.ErrMissingSignature,
-		historydb.ErrHistoryDBCorrupted:
-		logger.Critical().Errorf("Database is corrupted, recreating db: %v", err)
-		return resetCorruptDB(db)
+	switch e := err.(type) {
+	case blockdb.ErrMissingSignature:
+		return CorruptionDescription{
+			Bucket: string(blockdb.BlockSigsBkt),
+			Key:    e.BlockHash().Hex(),
+			Err:    err,
+		}, true
+	case historydb.ErrHistoryDBCorrupted:
+		return CorruptionDescription{
+			Bucket: e.Bucket,
+			Key:    e.Key,
+			Err:    err,
+		}, true
+	case ErrCheckpointMismatch:
+		return CorruptionDescription{
+			Bucket: string(blockdb.BlocksBkt),
+			Key:    e.Hash.Hex(),
+			Err:    err,
+		}, true
+	case ErrCheckpointChainBroken:
+		return CorruptionDescription{
+			Bucket: string(blockdb.BlocksBkt),
+			Key:    e.Hash.Hex(),
+			Err:    err,
+		}, true
 	default:
-		return nil, err
+		return CorruptionDescription{}, false
 	}
 }
 
@@ -0,0 +1,122 @@
+package visor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/blang/semver"
+
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+var (
+	migrationsLock sync.RWMutex
+	migrations     []migration
+)
+
+// MigrationFunc upgrades the database schema. It is called in an Update transaction;
+// if it returns an error, the transaction is rolled back and the migration is aborted
+type MigrationFunc func(tx *dbutil.Tx) error
+
+type migration struct {
+	from semver.Version
+	to   semver.Version
+	fn   MigrationFunc
+}
+
+// RegisterMigration registers a migration that upgrades databases of fromVersion, or of any version
+// between fromVersion and toVersion, to the schema of toVersion.
+// fromVersion must be lower than toVersion, and the range must not overlap the range of a registered migration.
+// Migrations must be registered at startup, before the database is checked.
+func RegisterMigration(fromVersion, toVersion semver.Version, fn MigrationFunc) error {
+	if fn == nil {
+		return errors.New("Migration func must not be nil")
+	}
+
+	if !fromVersion.LT(toVersion) {
+		return fmt.Errorf("Migration from %v to %v must be to a higher version", fromVersion, toVersion)
+	}
+
+	migrationsLock.Lock()
+	defer migrationsLock.Unlock()
+
+	for _, m := range migrations {
+		if fromVersion.LT(m.to) && m.from.LT(toVersion) {
+			return fmt.Errorf("Migration from %v to %v overlaps the migration from %v to %v", fromVersion, toVersion, m.from, m.to)
+		}
+	}
+
+	migrations = append(migrations, migration{
+		from: fromVersion,
+		to:   toVersion,
+		fn:   fn,
+	})
+
+	return nil
+}
+
+// pendingMigrations returns the registered migrations to a version higher than dbVersion and not higher than appVersion,
+// in order of version
+func pendingMigrations(dbVersion, appVersion semver.Version) []migration {
+	migrationsLock.RLock()
+	defer migrationsLock.RUnlock()
+
+	var ms []migration
+	for _, m := range migrations {
+		if dbVersion.LT(m.to) && m.to.LTE(appVersion) {
+			ms = append(ms, m)
+		}
+	}
+
+	// The ranges don't overlap, so the migrations are ordered by either version
+	sort.Slice(ms, func(i, j int) bool {
+		return ms[i].to.LT(ms[j].to)
+	})
+
+	return ms
+}
+
+// MigrateDB runs the registered migrations that upgrade the database towards appVersion, in order of version.
+// Each migration runs in its own Update transaction, which also sets the database version to the toVersion of the migration.
+// If a migration fails, the database remains at the version of the previous migration and the error is returned.
+// A database without a version is not migrated, because it is new or older than the versioned databases.
+// Returns the number of migrations run.
+func MigrateDB(db *dbutil.DB, appVersion semver.Version) (int, error) {
+	dbVersion, err := GetDBVersion(db)
+	if err != nil {
+		return 0, err
+	}
+
+	if dbVersion == nil {
+		return 0, nil
+	}
+
+	ms := pendingMigrations(*dbVersion, appVersion)
+	if len(ms) == 0 {
+		return 0, nil
+	}
+
+	if db.IsReadOnly() {
+		return 0, fmt.Errorf("database version %v must be migrated to %v, but the database is read-only", dbVersion, ms[len(ms)-1].to)
+	}
+
+	for i, m := range ms {
+		logger.Infof("Migrating database from version %v to %v", dbVersion, m.to)
+
+		if err := db.Update("MigrateDB", func(tx *dbutil.Tx) error {
+			if err := m.fn(tx); err != nil {
+				return err
+			}
+
+			return setDBVersion(tx, m.to)
+		}); err != nil {
+			return i, fmt.Errorf("database migration from %v to %v failed: %v", dbVersion, m.to, err)
+		}
+
+		dbVersion = &ms[i].to
+	}
+
+	return len(ms), nil
+}
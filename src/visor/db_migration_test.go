@@ -0,0 +1,169 @@
+package visor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func resetMigrations() {
+	migrationsLock.Lock()
+	migrations = nil
+	migrationsLock.Unlock()
+}
+
+func TestRegisterMigration(t *testing.T) {
+	defer resetMigrations()
+
+	noop := func(*dbutil.Tx) error { return nil }
+
+	err := RegisterMigration(semver.MustParse("0.25.0"), semver.MustParse("0.26.0"), nil)
+	require.EqualError(t, err, "Migration func must not be nil")
+
+	err = RegisterMigration(semver.MustParse("0.26.0"), semver.MustParse("0.26.0"), noop)
+	require.EqualError(t, err, "Migration from 0.26.0 to 0.26.0 must be to a higher version")
+
+	err = RegisterMigration(semver.MustParse("0.25.0"), semver.MustParse("0.26.0"), noop)
+	require.NoError(t, err)
+
+	err = RegisterMigration(semver.MustParse("0.25.5"), semver.MustParse("0.27.0"), noop)
+	require.EqualError(t, err, "Migration from 0.25.5 to 0.27.0 overlaps the migration from 0.25.0 to 0.26.0")
+
+	// Adjacent ranges don't overlap
+	err = RegisterMigration(semver.MustParse("0.26.0"), semver.MustParse("0.27.0"), noop)
+	require.NoError(t, err)
+}
+
+func TestMigrateDB(t *testing.T) {
+	defer resetMigrations()
+
+	bkt := []byte("migration_test")
+	var ran []string
+	failAt := ""
+
+	// Each migration writes a key, so that a rolled back migration can be detected
+	register := func(from, to string) {
+		err := RegisterMigration(semver.MustParse(from), semver.MustParse(to), func(tx *dbutil.Tx) error {
+			ran = append(ran, to)
+			if _, err := tx.CreateBucketIfNotExists(bkt); err != nil {
+				return err
+			}
+			if err := dbutil.PutBucketValue(tx, bkt, []byte(to), []byte{1}); err != nil {
+				return err
+			}
+			if to == failAt {
+				return errors.New("migration failed")
+			}
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	// Registered out of order
+	register("0.27.0", "0.28.0")
+	register("0.25.0", "0.26.0")
+	register("0.26.0", "0.27.0")
+
+	hasKey := func(db *dbutil.DB, k string) bool {
+		var ok bool
+		err := db.View("", func(tx *dbutil.Tx) error {
+			if !dbutil.Exists(tx, bkt) {
+				return nil
+			}
+			var err error
+			ok, err = dbutil.BucketHasKey(tx, bkt, []byte(k))
+			return err
+		})
+		require.NoError(t, err)
+		return ok
+	}
+
+	requireDBVersion := func(db *dbutil.DB, v string) {
+		dbVersion, err := GetDBVersion(db)
+		require.NoError(t, err)
+		require.Equal(t, v, dbVersion.String())
+	}
+
+	t.Run("no version", func(t *testing.T) {
+		ran = nil
+		db, shutdown := testutil.PrepareDB(t)
+		defer shutdown()
+
+		n, err := MigrateDB(db, semver.MustParse("0.28.0"))
+		require.NoError(t, err)
+		require.Equal(t, 0, n)
+		require.Empty(t, ran)
+	})
+
+	t.Run("in order up to the app version", func(t *testing.T) {
+		ran = nil
+		db, shutdown := testutil.PrepareDB(t)
+		defer shutdown()
+
+		require.NoError(t, SetDBVersion(db, semver.MustParse("0.25.1")))
+
+		n, err := MigrateDB(db, semver.MustParse("0.27.3"))
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		require.Equal(t, []string{"0.26.0", "0.27.0"}, ran)
+		requireDBVersion(db, "0.27.0")
+
+		// The migrations already run are not run again
+		ran = nil
+		n, err = MigrateDB(db, semver.MustParse("0.28.0"))
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Equal(t, []string{"0.28.0"}, ran)
+		requireDBVersion(db, "0.28.0")
+	})
+
+	t.Run("failed migration is rolled back", func(t *testing.T) {
+		ran = nil
+		failAt = "0.27.0"
+		defer func() {
+			failAt = ""
+		}()
+
+		db, shutdown := testutil.PrepareDB(t)
+		defer shutdown()
+
+		require.NoError(t, SetDBVersion(db, semver.MustParse("0.25.0")))
+
+		n, err := MigrateDB(db, semver.MustParse("0.28.0"))
+		require.EqualError(t, err, "database migration from 0.26.0 to 0.27.0 failed: migration failed")
+		require.Equal(t, 1, n)
+		require.Equal(t, []string{"0.26.0", "0.27.0"}, ran)
+
+		requireDBVersion(db, "0.26.0")
+		require.True(t, hasKey(db, "0.26.0"))
+		require.False(t, hasKey(db, "0.27.0"))
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		ran = nil
+		db, shutdown := testutil.PrepareDB(t)
+		require.NoError(t, SetDBVersion(db, semver.MustParse("0.27.0")))
+		dbPath := db.Path()
+		require.NoError(t, db.Close())
+		defer shutdown()
+
+		db, err := OpenDB(dbPath, true)
+		require.NoError(t, err)
+		defer db.Close()
+
+		n, err := MigrateDB(db, semver.MustParse("0.28.0"))
+		require.EqualError(t, err, "database version 0.27.0 must be migrated to 0.28.0, but the database is read-only")
+		require.Equal(t, 0, n)
+		require.Empty(t, ran)
+
+		// No migration is needed for the app version
+		n, err = MigrateDB(db, semver.MustParse("0.27.5"))
+		require.NoError(t, err)
+		require.Equal(t, 0, n)
+	})
+}
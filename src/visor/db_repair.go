@@ -0,0 +1,251 @@
+package visor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+)
+
+// RepairReport is the result of RepairDB
+type RepairReport struct {
+	// Truncated is true if a corrupted block was found and the blockchain was truncated
+	Truncated bool
+	// TruncatedHeight is the number of blocks kept, which is the seq of the first corrupted block.
+	// The node resyncs the blockchain from this height
+	TruncatedHeight uint64
+	// BlocksRemoved is the number of blocks removed, from the first corrupted block to the head block
+	BlocksRemoved uint64
+	// Err is the error of the first corrupted block
+	Err error
+	// BackupPath is the path that the corrupted database was moved to
+	BackupPath string
+}
+
+// RepairDB recovers a database whose blockchain is partially corrupted.
+// The blocks are read in sequence from the genesis block, and the first block that cannot be read,
+// or whose signature, header or transactions fail verification, is the first corrupted block.
+// The blocks before it are executed in a new database, which rebuilds the unspent outputs and the history,
+// and the new database replaces the original database, so the corrupted block and all blocks after it are removed.
+// Like ResetCorruptDB, the original database is closed and moved to a backup file, and the new database is returned.
+// Unconfirmed transactions are not kept.
+// If no block is corrupted, db is returned unchanged and RepairReport.Truncated is false.
+// A read-only or pruned database cannot be repaired.
+func RepairDB(db *dbutil.DB, pubkey cipher.PubKey, quit chan struct{}) (*dbutil.DB, RepairReport, error) {
+	if db.IsReadOnly() {
+		return nil, RepairReport{}, errors.New("a read-only database cannot be repaired")
+	}
+
+	bc, err := NewBlockchain(db, BlockchainConfig{Pubkey: pubkey})
+	if err != nil {
+		return nil, RepairReport{}, err
+	}
+
+	var headSeq uint64
+	var hasHead bool
+	if err := db.View("RepairDB", func(tx *dbutil.Tx) error {
+		// Don't repair the db if the blocks bucket does not exist
+		if !dbutil.Exists(tx, blockdb.BlocksBkt) {
+			return nil
+		}
+
+		// The unspent outputs cannot be rebuilt without the transactions of the pruned blocks
+		if prunedSeq, ok, err := bc.PrunedSeq(tx); err != nil {
+			return err
+		} else if ok {
+			return fmt.Errorf("the transactions of blocks up to %d have been pruned, a pruned database cannot be repaired", prunedSeq)
+		}
+
+		var err error
+		headSeq, hasHead, err = bc.HeadSeq(tx)
+		return err
+	}); err != nil {
+		return nil, RepairReport{}, err
+	}
+
+	if !hasHead {
+		return db, RepairReport{}, nil
+	}
+
+	dbPath := db.Path()
+	repairDBPath := dbPath + ".repair"
+
+	// Remove a new database left by an interrupted repair
+	if err := os.Remove(repairDBPath); err != nil && !os.IsNotExist(err) {
+		return nil, RepairReport{}, err
+	}
+
+	newDB, err := OpenDB(repairDBPath, false)
+	if err != nil {
+		return nil, RepairReport{}, err
+	}
+
+	removeNewDB := func() {
+		if err := newDB.Close(); err != nil {
+			logger.WithError(err).Error("Failed to close repaired db")
+		}
+		if err := os.Remove(repairDBPath); err != nil {
+			logger.WithError(err).Errorf("Failed to remove repaired db %s", repairDBPath)
+		}
+	}
+
+	report, err := copyValidBlocks(db, bc, newDB, pubkey, headSeq, quit)
+	if err != nil {
+		removeNewDB()
+		return nil, RepairReport{}, err
+	}
+
+	if !report.Truncated {
+		removeNewDB()
+		return db, RepairReport{}, nil
+	}
+
+	logger.Critical().Errorf("Database is corrupted at block %d, removing %d blocks: %v", report.TruncatedHeight, report.BlocksRemoved, report.Err)
+
+	// Keep the database version, so that the database is not migrated again
+	if v, err := GetDBVersion(db); err != nil {
+		removeNewDB()
+		return nil, RepairReport{}, err
+	} else if v != nil {
+		if err := SetDBVersion(newDB, *v); err != nil {
+			removeNewDB()
+			return nil, RepairReport{}, err
+		}
+	}
+
+	if err := newDB.Close(); err != nil {
+		return nil, RepairReport{}, fmt.Errorf("Failed to close repaired db: %v", err)
+	}
+
+	if err := db.Close(); err != nil {
+		return nil, RepairReport{}, fmt.Errorf("Failed to close db: %v", err)
+	}
+
+	corruptDBPath, err := moveCorruptDB(dbPath)
+	if err != nil {
+		return nil, RepairReport{}, fmt.Errorf("Failed to move corrupted db: %v", err)
+	}
+
+	logger.Critical().Infof("Moved corrupted db to %s", corruptDBPath)
+	report.BackupPath = corruptDBPath
+
+	if err := os.Rename(repairDBPath, dbPath); err != nil {
+		return nil, RepairReport{}, fmt.Errorf("Failed to move repaired db: %v", err)
+	}
+
+	db, err = OpenDB(dbPath, false)
+	if err != nil {
+		return nil, RepairReport{}, err
+	}
+
+	return db, report, nil
+}
+
+// copyValidBlocks executes the blocks of db in newDB, in sequence, until the first corrupted block.
+// Blocks are committed in batches. A block that fails to be read or verified is corrupted, and is reported in RepairReport.Err.
+// The returned error is a database error, e.g. of a write to newDB, and no corruption
+func copyValidBlocks(db *dbutil.DB, bc *Blockchain, newDB *dbutil.DB, pubkey cipher.PubKey, headSeq uint64, quit chan struct{}) (RepairReport, error) {
+	if err := CreateBuckets(newDB); err != nil {
+		return RepairReport{}, err
+	}
+
+	newBc, err := NewBlockchain(newDB, BlockchainConfig{Pubkey: pubkey})
+	if err != nil {
+		return RepairReport{}, err
+	}
+
+	history := historydb.New()
+
+	var report RepairReport
+	var prev *coin.SignedBlock
+	var seq uint64
+	for seq <= headSeq && report.Err == nil {
+		if err := db.View("RepairDB", func(tx *dbutil.Tx) error {
+			return newDB.Update("RepairDB", func(newTx *dbutil.Tx) error {
+				for i := 0; i < chainImportBatchSize && seq <= headSeq; i++ {
+					select {
+					case <-quit:
+						return ErrVerifyStopped
+					default:
+					}
+
+					// Only a block that cannot be read or verified is corrupted. processBlock does not write,
+					// so nothing of the corrupted block is committed
+					b, err := readRepairBlock(tx, bc, seq, prev)
+					var nb coin.SignedBlock
+					if err == nil {
+						nb, err = newBc.processBlock(newTx, *b)
+					}
+					if err != nil {
+						// Stop at the corrupted block, and commit the blocks before it
+						report.Err = err
+						return nil
+					}
+
+					// A failed write is a database error, the transaction is rolled back and the repair aborted
+					if err := newBc.store.AddBlock(newTx, &nb); err != nil {
+						return err
+					}
+
+					if err := history.ParseBlock(newTx, nb.Block); err != nil {
+						return err
+					}
+
+					prev = &nb
+					seq++
+				}
+
+				return nil
+			})
+		}); err != nil {
+			return RepairReport{}, err
+		}
+	}
+
+	if report.Err != nil {
+		report.Truncated = true
+		report.TruncatedHeight = seq
+		report.BlocksRemoved = headSeq + 1 - seq
+	}
+
+	return report, nil
+}
+
+// readRepairBlock reads the block seq and verifies it against the previous block.
+// An error means that the block is corrupted
+func readRepairBlock(tx *dbutil.Tx, bc *Blockchain, seq uint64, prev *coin.SignedBlock) (*coin.SignedBlock, error) {
+	b, err := bc.GetSignedBlockBySeq(tx, seq)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, NewErrBlockNotExist(seq)
+	}
+
+	if b.Seq() != seq {
+		return nil, fmt.Errorf("expected block %d but found block %d", seq, b.Seq())
+	}
+
+	if err := bc.VerifySignature(b); err != nil {
+		return nil, err
+	}
+
+	if prev == nil {
+		if b.Body.Hash() != b.Head.BodyHash {
+			return nil, errors.New("Computed body hash does not match")
+		}
+		return b, nil
+	}
+
+	// ExecuteBlock overwrites the PrevHash of the block, so the header is checked against the previous block here
+	if err := (DefaultBlockValidator{}).Validate(b.Block, prev.Block); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
@@ -0,0 +1,153 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestRepairDB(t *testing.T) {
+	_, otherSecret := cipher.GenerateKeyPair()
+
+	cases := []struct {
+		name      string
+		corruptFn func(tx *dbutil.Tx, hash cipher.SHA256) error
+		seq       uint64
+	}{
+		{
+			name: "missing signature",
+			corruptFn: func(tx *dbutil.Tx, hash cipher.SHA256) error {
+				return dbutil.Delete(tx, blockdb.BlockSigsBkt, hash[:])
+			},
+			seq: 3,
+		},
+		{
+			name: "invalid signature",
+			corruptFn: func(tx *dbutil.Tx, hash cipher.SHA256) error {
+				sig := cipher.MustSignHash(hash, otherSecret)
+				return dbutil.PutBucketValue(tx, blockdb.BlockSigsBkt, hash[:], sig[:])
+			},
+			seq: 4,
+		},
+		{
+			name: "signature decode error",
+			corruptFn: func(tx *dbutil.Tx, hash cipher.SHA256) error {
+				return dbutil.PutBucketValue(tx, blockdb.BlockSigsBkt, hash[:], []byte{1, 2, 3})
+			},
+			seq: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			src, shutdown := newChainExportTestVisor(t)
+			defer shutdown()
+
+			dbPath := src.db.Path()
+			defer removeCorruptDBFiles(t, dbPath)
+
+			// Create a chain of blocks, each spending the output of the previous block's transaction,
+			// and keep the unspent outputs after the block before the corrupted block
+			gb := addGenesisBlockToVisor(t, src)
+			uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+			var blocks []coin.SignedBlock
+			var expectedUnspents coin.UxArray
+			for i := 1; i <= 5; i++ {
+				if uint64(i) == tc.seq {
+					var err error
+					expectedUnspents, err = src.GetAllUnspentOutputs()
+					require.NoError(t, err)
+				}
+
+				txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, uxs[0].Body.Coins)
+
+				err := src.db.Update("", func(tx *dbutil.Tx) error {
+					b, err := src.blockchain.NewBlock(tx, coin.Transactions{txn}, genTime+uint64(i)*100)
+					require.NoError(t, err)
+
+					sb := coin.SignedBlock{
+						Block: *b,
+						Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+					}
+					if err := src.executeSignedBlock(tx, sb); err != nil {
+						return err
+					}
+
+					blocks = append(blocks, sb)
+					uxs = coin.CreateUnspents(b.Head, txn)
+					return nil
+				})
+				require.NoError(t, err)
+			}
+
+			// A database without corrupted blocks is not changed
+			db, report, err := RepairDB(src.db, genPublic, nil)
+			require.NoError(t, err)
+			require.Equal(t, RepairReport{}, report)
+			require.True(t, db == src.db)
+			require.Empty(t, findCorruptDBFiles(t, dbPath))
+
+			err = src.db.Update("", func(tx *dbutil.Tx) error {
+				return tc.corruptFn(tx, blocks[tc.seq-1].HashHeader())
+			})
+			require.NoError(t, err)
+
+			db, report, err = RepairDB(src.db, genPublic, nil)
+			require.NoError(t, err)
+			defer db.Close()
+
+			require.True(t, report.Truncated)
+			require.Equal(t, tc.seq, report.TruncatedHeight)
+			require.Equal(t, 6-tc.seq, report.BlocksRemoved)
+			require.Error(t, report.Err)
+			require.Equal(t, findCorruptDBFiles(t, dbPath), []string{report.BackupPath})
+			require.Equal(t, dbPath, db.Path())
+
+			// The repaired database passes verification and has the unspent outputs of the remaining blocks
+			err = CheckDatabase(db, genPublic, nil, nil)
+			require.NoError(t, err)
+
+			v, err := New(src.Config, db, nil)
+			require.NoError(t, err)
+
+			headSeq, ok, err := v.HeadBkSeq()
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, tc.seq-1, headSeq)
+
+			unspents, err := v.GetAllUnspentOutputs()
+			require.NoError(t, err)
+			require.Equal(t, expectedUnspents, unspents)
+
+			// The blocks after the truncated height can be executed again
+			err = db.Update("", func(tx *dbutil.Tx) error {
+				return v.executeSignedBlock(tx, blocks[tc.seq-1])
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRepairDBEmpty(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	newDB, report, err := RepairDB(db, genPublic, nil)
+	require.NoError(t, err)
+	require.Equal(t, RepairReport{}, report)
+	require.True(t, newDB == db)
+}
+
+func TestRepairDBReadOnly(t *testing.T) {
+	db, shutdown := testutil.PrepareDBReadOnly(t)
+	defer shutdown()
+
+	_, _, err := RepairDB(db, genPublic, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package dbutil
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// ReadOnlyClone copies a consistent snapshot of the database to a new file at path and opens it read-only,
+// so that slow read queries, e.g. of diagnostic tools, don't hold a View transaction of the database open.
+// The snapshot is copied in a View transaction, which does not block Update transactions.
+// The file is removed when the clone is closed, or after db.ReadOnlyCloneTTL if it is not 0.
+// path must not exist.
+func (db *DB) ReadOnlyClone(path string) (*DB, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("%s already exists", path)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err := db.View("ReadOnlyClone", func(tx *Tx) error {
+		return tx.CopyFile(path, 0600)
+	}); err != nil {
+		os.Remove(path) //nolint:errcheck
+		return nil, err
+	}
+
+	bdb, err := bolt.Open(path, 0600, &bolt.Options{
+		Timeout:  5 * time.Second,
+		ReadOnly: true,
+	})
+	if err != nil {
+		os.Remove(path) //nolint:errcheck
+		return nil, err
+	}
+
+	clone := WrapDB(bdb)
+	clone.ViewLog = db.ViewLog
+	clone.ViewTrace = db.ViewTrace
+	clone.UpdateLog = db.UpdateLog
+	clone.UpdateTrace = db.UpdateTrace
+	clone.DurationLog = db.DurationLog
+	clone.DurationReportingThreshold = db.DurationReportingThreshold
+	clone.clonePath = path
+
+	if db.ReadOnlyCloneTTL > 0 {
+		// Close waits for the View transactions in progress, so the clone is not closed during a read
+		clone.shutdownLock.Lock()
+		clone.cloneTimer = time.AfterFunc(db.ReadOnlyCloneTTL, func() {
+			if err := clone.Close(); err != nil {
+				logger.WithError(err).Errorf("Failed to close read-only clone %s", path)
+			}
+		})
+		clone.shutdownLock.Unlock()
+	}
+
+	return clone, nil
+}
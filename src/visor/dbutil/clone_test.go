@@ -0,0 +1,80 @@
+package dbutil_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestReadOnlyClone(t *testing.T) {
+	db, shutdown := testutil.PrepareDB(t)
+	defer shutdown()
+
+	bkt := []byte("foo")
+	put := func(k, v string) {
+		err := db.Update("", func(tx *dbutil.Tx) error {
+			if _, err := tx.CreateBucketIfNotExists(bkt); err != nil {
+				return err
+			}
+			return dbutil.PutBucketValue(tx, bkt, []byte(k), []byte(v))
+		})
+		require.NoError(t, err)
+	}
+
+	get := func(db *dbutil.DB, k string) []byte {
+		var v []byte
+		err := db.View("", func(tx *dbutil.Tx) error {
+			var err error
+			v, err = dbutil.GetBucketValue(tx, bkt, []byte(k))
+			return err
+		})
+		require.NoError(t, err)
+		return v
+	}
+
+	put("a", "1")
+
+	path := db.Path() + ".clone"
+	clone, err := db.ReadOnlyClone(path)
+	require.NoError(t, err)
+	require.True(t, clone.IsReadOnly())
+
+	// An existing file is not overwritten
+	_, err = db.ReadOnlyClone(path)
+	require.EqualError(t, err, path+" already exists")
+
+	// The clone is a snapshot, later writes to the database are not in the clone
+	put("b", "2")
+	require.Equal(t, []byte("1"), get(clone, "a"))
+	require.Nil(t, get(clone, "b"))
+	require.Equal(t, []byte("2"), get(db, "b"))
+
+	// The clone's file is removed when it is closed
+	require.NoError(t, clone.Close())
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+	require.NoError(t, clone.Close())
+
+	// The clone is closed and removed after the TTL
+	db.ReadOnlyCloneTTL = time.Millisecond * 10
+	clone, err = db.ReadOnlyClone(path)
+	require.NoError(t, err)
+	require.Equal(t, []byte("2"), get(clone, "b"))
+
+	deadline := time.Now().Add(time.Second * 5)
+	for {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		require.True(t, time.Now().Before(deadline), "clone was not removed after the TTL")
+		time.Sleep(time.Millisecond * 5)
+	}
+
+	err = clone.View("", func(*dbutil.Tx) error { return nil })
+	require.Error(t, err)
+}
@@ -8,8 +8,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/boltdb/bolt"
@@ -31,6 +33,9 @@ var (
 // Tx wraps a Tx
 type Tx struct {
 	*bolt.Tx
+
+	// fillPercent is set on the buckets opened by the Tx, if nonzero
+	fillPercent float64
 }
 
 // String is implemented to prevent a panic when mocking methods with *Tx arguments.
@@ -40,8 +45,36 @@ func (tx *Tx) String() string {
 	return fmt.Sprintf("%v", tx.Tx)
 }
 
+// Bucket wraps *bolt.Tx.Bucket to set the fill percent of the bucket
+func (tx *Tx) Bucket(name []byte) *bolt.Bucket {
+	return tx.setFillPercent(tx.Tx.Bucket(name))
+}
+
+// CreateBucket wraps *bolt.Tx.CreateBucket to set the fill percent of the bucket
+func (tx *Tx) CreateBucket(name []byte) (*bolt.Bucket, error) {
+	b, err := tx.Tx.CreateBucket(name)
+	return tx.setFillPercent(b), err
+}
+
+// CreateBucketIfNotExists wraps *bolt.Tx.CreateBucketIfNotExists to set the fill percent of the bucket
+func (tx *Tx) CreateBucketIfNotExists(name []byte) (*bolt.Bucket, error) {
+	b, err := tx.Tx.CreateBucketIfNotExists(name)
+	return tx.setFillPercent(b), err
+}
+
+func (tx *Tx) setFillPercent(b *bolt.Bucket) *bolt.Bucket {
+	if b != nil && tx.fillPercent != 0 {
+		b.FillPercent = tx.fillPercent
+	}
+	return b
+}
+
 // DB wraps a bolt.DB to add logging
 type DB struct {
+	// updateTxN is the number of Update transactions, which bolt does not count.
+	// It is the first field to be 64-bit aligned for atomic access
+	updateTxN uint64
+
 	ViewLog                    bool
 	ViewTrace                  bool
 	UpdateLog                  bool
@@ -49,8 +82,21 @@ type DB struct {
 	DurationLog                bool
 	DurationReportingThreshold time.Duration
 
+	// FillPercent is the fill percent of the pages that are split by Update transactions,
+	// between 0.1 and 1. Higher values make the DB smaller for workloads that mostly append keys.
+	// If 0, bolt.DefaultFillPercent is used
+	FillPercent float64
+
+	// ReadOnlyCloneTTL is the time after which a clone created by ReadOnlyClone is closed and its file removed.
+	// If 0, a clone is only removed when it is closed
+	ReadOnlyCloneTTL time.Duration
+
 	*bolt.DB
 
+	// clonePath is the snapshot file of a DB created by ReadOnlyClone, which is removed when the DB is closed
+	clonePath  string
+	cloneTimer *time.Timer
+
 	// shutdownLock is added to prevent closing the database while a View transaction is in progress
 	// bolt.DB will block for Update transactions but not for View transactions, and if
 	// the database is closed while in a View transaction, it will panic
@@ -89,7 +135,7 @@ func (db *DB) View(name string, f func(*Tx) error) error {
 	t0 := time.Now()
 
 	err := db.DB.View(func(tx *bolt.Tx) error {
-		return f(&Tx{tx})
+		return f(&Tx{Tx: tx})
 	})
 
 	t1 := time.Now()
@@ -114,10 +160,15 @@ func (db *DB) Update(name string, f func(*Tx) error) error {
 		debug.PrintStack()
 	}
 
+	atomic.AddUint64(&db.updateTxN, 1)
+
 	t0 := time.Now()
 
 	err := db.DB.Update(func(tx *bolt.Tx) error {
-		return f(&Tx{tx})
+		return f(&Tx{
+			Tx:          tx,
+			fillPercent: db.FillPercent,
+		})
 	})
 
 	t1 := time.Now()
@@ -129,12 +180,36 @@ func (db *DB) Update(name string, f func(*Tx) error) error {
 	return err
 }
 
-// Close closes the underlying *bolt.DB
+// Close closes the underlying *bolt.DB. The file of a DB created by ReadOnlyClone is removed
 func (db *DB) Close() error {
 	db.shutdownLock.Lock()
 	defer db.shutdownLock.Unlock()
 
-	return db.DB.Close()
+	if db.cloneTimer != nil {
+		db.cloneTimer.Stop()
+	}
+
+	if err := db.DB.Close(); err != nil {
+		return err
+	}
+
+	if db.clonePath != "" {
+		if err := os.Remove(db.clonePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ForEachBucket calls fn for each top level bucket of the database, in a single View transaction.
+// The bucket is only valid while fn is called. The iteration stops at the first error returned by fn
+func (db *DB) ForEachBucket(fn func(name string, b *bolt.Bucket) error) error {
+	return db.View("ForEachBucket", func(tx *Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			return fn(string(name), b)
+		})
+	})
 }
 
 // ErrCreateBucketFailed is returned if creating a bolt.DB bucket fails
@@ -0,0 +1,46 @@
+package dbutil_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestForEachBucket(t *testing.T) {
+	db, shutdown := testutil.PrepareDB(t)
+	defer shutdown()
+
+	err := db.Update("", func(tx *dbutil.Tx) error {
+		if err := dbutil.CreateBuckets(tx, [][]byte{[]byte("foo"), []byte("bar")}); err != nil {
+			return err
+		}
+		return dbutil.PutBucketValue(tx, []byte("foo"), []byte("a"), []byte("b"))
+	})
+	require.NoError(t, err)
+
+	keys := make(map[string]int)
+	err = db.ForEachBucket(func(name string, b *bolt.Bucket) error {
+		keys[name] = b.Stats().KeyN
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{
+		"bar": 0,
+		"foo": 1,
+	}, keys)
+
+	// The iteration stops at the first error
+	errStop := errors.New("stop")
+	var names []string
+	err = db.ForEachBucket(func(name string, _ *bolt.Bucket) error {
+		names = append(names, name)
+		return errStop
+	})
+	require.Equal(t, errStop, err)
+	require.Equal(t, []string{"bar"}, names)
+}
@@ -0,0 +1,57 @@
+package dbutil
+
+import (
+	"sync/atomic"
+
+	"github.com/boltdb/bolt"
+)
+
+// DBStats are the internal statistics of the bolt.DB, to monitor the growth and fragmentation of the database
+type DBStats struct {
+	bolt.Stats
+
+	// PageSize is the size of a page of the database file, in bytes
+	PageSize int
+	// Size is the size of the database, in bytes
+	Size int64
+	// PageCount is the number of pages of the database
+	PageCount int64
+	// FreedPagesPct is the percentage of the pages of the database that are free or
+	// pending to be freed, which are reused before the database file grows
+	FreedPagesPct float64
+	// TxCount is the number of View and Update transactions since the database was opened
+	TxCount uint64
+	// BytesWritten is the size of the pages allocated by the Update transactions since the database was opened,
+	// which bolt writes to the database file when the transactions are committed
+	BytesWritten uint64
+}
+
+// Stats returns the statistics of the database
+func (db *DB) Stats() (*DBStats, error) {
+	s := &DBStats{
+		PageSize: db.Info().PageSize,
+	}
+
+	if err := db.View("Stats", func(tx *Tx) error {
+		s.Size = tx.Size()
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// Read after the View, so that the transaction is included in the statistics
+	s.Stats = db.DB.Stats()
+
+	if s.PageSize > 0 {
+		s.PageCount = s.Size / int64(s.PageSize)
+	}
+	if s.PageCount > 0 {
+		s.FreedPagesPct = float64(s.FreePageN+s.PendingPageN) / float64(s.PageCount) * 100
+	}
+
+	// bolt.Stats.TxN only counts read transactions
+	s.TxCount = uint64(s.TxN) + atomic.LoadUint64(&db.updateTxN)
+	s.BytesWritten = uint64(s.TxStats.PageAlloc)
+
+	return s, nil
+}
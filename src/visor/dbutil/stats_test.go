@@ -0,0 +1,55 @@
+package dbutil_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestDBStats(t *testing.T) {
+	db, shutdown := testutil.PrepareDB(t)
+	defer shutdown()
+
+	s, err := db.Stats()
+	require.NoError(t, err)
+	require.Equal(t, db.Info().PageSize, s.PageSize)
+	require.Equal(t, s.Size/int64(s.PageSize), s.PageCount)
+	require.Equal(t, uint64(1), s.TxCount)
+	require.Equal(t, uint64(0), s.BytesWritten)
+
+	// Write and then delete enough data to free pages
+	value := bytes.Repeat([]byte{1}, 1024)
+	for i := 0; i < 3; i++ {
+		err := db.Update("", func(tx *dbutil.Tx) error {
+			b, err := tx.CreateBucketIfNotExists([]byte("foo"))
+			if err != nil {
+				return err
+			}
+			for j := uint64(0); j < 100; j++ {
+				if err := b.Put(dbutil.Itob(j), value); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return tx.DeleteBucket([]byte("foo"))
+	})
+	require.NoError(t, err)
+
+	s2, err := db.Stats()
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), s2.TxCount)
+	require.True(t, s2.BytesWritten > 300*1024)
+	require.True(t, s2.PageCount > s.PageCount)
+	require.NotZero(t, s2.FreePageN+s2.PendingPageN)
+	require.Equal(t, float64(s2.FreePageN+s2.PendingPageN)/float64(s2.PageCount)*100, s2.FreedPagesPct)
+	require.True(t, s2.FreedPagesPct > 0 && s2.FreedPagesPct <= 100)
+}
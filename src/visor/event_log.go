@@ -0,0 +1,362 @@
+package visor
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+/*
+The event log records the outputs created and spent by each block, so that applications can replay
+them without reprocessing the blockchain.
+
+Events are keyed by the block seq and the index of the event in the block, so they are iterated in
+the order they happened. In each transaction, the spent outputs come before the created outputs.
+Every block creates at least one output, so the last logged block is the block of the last key.
+*/
+
+// EventLogBkt stores the events of the event log
+var EventLogBkt = []byte("event_log")
+
+// ErrEventLogDisabled is returned when reading events while the event log is disabled
+var ErrEventLogDisabled = errors.New("The event log is disabled")
+
+// EventType is the type of an event of the event log
+type EventType uint8
+
+const (
+	// EventOutputCreated an output was created by a transaction
+	EventOutputCreated EventType = 1
+	// EventOutputSpent an output was spent by a transaction
+	EventOutputSpent EventType = 2
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventOutputCreated:
+		return "output_created"
+	case EventOutputSpent:
+		return "output_spent"
+	default:
+		return fmt.Sprintf("EventType(%d)", uint8(t))
+	}
+}
+
+// ParseEventType parses the name of an event type
+func ParseEventType(s string) (EventType, error) {
+	switch s {
+	case EventOutputCreated.String():
+		return EventOutputCreated, nil
+	case EventOutputSpent.String():
+		return EventOutputSpent, nil
+	default:
+		return 0, fmt.Errorf("invalid event type %q, must be %q or %q", s, EventOutputCreated, EventOutputSpent)
+	}
+}
+
+// OutputCreated is the payload of an EventOutputCreated event
+type OutputCreated struct {
+	TxID      cipher.SHA256
+	OutputIdx uint32
+	Address   cipher.Address
+	Coins     uint64
+	CoinHours uint64
+}
+
+// OutputSpent is the payload of an EventOutputSpent event.
+// SourceTxID and SourceOutputIdx identify the spent output by the transaction that created it
+type OutputSpent struct {
+	TxID            cipher.SHA256
+	InputIdx        uint32
+	SourceTxID      cipher.SHA256
+	SourceOutputIdx uint32
+}
+
+// Event is an event of the event log. Only the payload of the event's Type is set
+type Event struct {
+	// Seq of the block of the event
+	Seq uint64
+	// Index of the event in the block
+	Index         uint32
+	Type          EventType
+	OutputCreated *OutputCreated
+	OutputSpent   *OutputSpent
+}
+
+func eventKey(seq uint64, index uint32) []byte {
+	k := make([]byte, 12)
+	binary.BigEndian.PutUint64(k[:8], seq)
+	binary.BigEndian.PutUint32(k[8:], index)
+	return k
+}
+
+func encodeEvent(e Event) ([]byte, error) {
+	var payload []byte
+	switch e.Type {
+	case EventOutputCreated:
+		payload = encoder.Serialize(*e.OutputCreated)
+	case EventOutputSpent:
+		payload = encoder.Serialize(*e.OutputSpent)
+	default:
+		return nil, fmt.Errorf("invalid event type %v", e.Type)
+	}
+
+	return append([]byte{byte(e.Type)}, payload...), nil
+}
+
+func decodeEvent(k, v []byte) (Event, error) {
+	if len(k) != 12 {
+		return Event{}, fmt.Errorf("invalid event key length %d", len(k))
+	}
+	if len(v) == 0 {
+		return Event{}, errors.New("empty event")
+	}
+
+	e := Event{
+		Seq:   binary.BigEndian.Uint64(k[:8]),
+		Index: binary.BigEndian.Uint32(k[8:]),
+		Type:  EventType(v[0]),
+	}
+
+	switch e.Type {
+	case EventOutputCreated:
+		var p OutputCreated
+		if err := encoder.DeserializeRawExact(v[1:], &p); err != nil {
+			return Event{}, err
+		}
+		e.OutputCreated = &p
+	case EventOutputSpent:
+		var p OutputSpent
+		if err := encoder.DeserializeRawExact(v[1:], &p); err != nil {
+			return Event{}, err
+		}
+		e.OutputSpent = &p
+	default:
+		return Event{}, fmt.Errorf("invalid event type %v", e.Type)
+	}
+
+	return e, nil
+}
+
+// eventLog writes and reads the events of the event log
+type eventLog struct {
+	history    Historyer
+	blockchain Blockchainer
+}
+
+// lastSeq returns the seq of the last logged block, false if no block has been logged
+func (el eventLog) lastSeq(tx *dbutil.Tx) (uint64, bool, error) {
+	bkt := tx.Bucket(EventLogBkt)
+	if bkt == nil {
+		return 0, false, dbutil.NewErrBucketNotExist(EventLogBkt)
+	}
+
+	k, _ := bkt.Cursor().Last()
+	if k == nil {
+		return 0, false, nil
+	}
+
+	if len(k) != 12 {
+		return 0, false, fmt.Errorf("invalid event key length %d", len(k))
+	}
+
+	return binary.BigEndian.Uint64(k[:8]), true, nil
+}
+
+// logBlock writes the events of a block. The history must have parsed the block,
+// since the sources of the spent outputs are read from it.
+func (el eventLog) logBlock(tx *dbutil.Tx, b coin.Block) error {
+	var index uint32
+	put := func(e Event) error {
+		e.Seq = b.Seq()
+		e.Index = index
+		index++
+
+		v, err := encodeEvent(e)
+		if err != nil {
+			return err
+		}
+
+		return dbutil.PutBucketValue(tx, EventLogBkt, eventKey(e.Seq, e.Index), v)
+	}
+
+	for _, txn := range b.Body.Transactions {
+		txID := txn.Hash()
+
+		for i, in := range txn.In {
+			srcTxID, srcIdx, err := el.outputSource(tx, in)
+			if err != nil {
+				return err
+			}
+
+			if err := put(Event{
+				Type: EventOutputSpent,
+				OutputSpent: &OutputSpent{
+					TxID:            txID,
+					InputIdx:        uint32(i),
+					SourceTxID:      srcTxID,
+					SourceOutputIdx: srcIdx,
+				},
+			}); err != nil {
+				return err
+			}
+		}
+
+		for i, o := range txn.Out {
+			if err := put(Event{
+				Type: EventOutputCreated,
+				OutputCreated: &OutputCreated{
+					TxID:      txID,
+					OutputIdx: uint32(i),
+					Address:   o.Address,
+					Coins:     o.Coins,
+					CoinHours: o.Hours,
+				},
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// outputSource returns the transaction that created an output and the index of the output in it
+func (el eventLog) outputSource(tx *dbutil.Tx, uxID cipher.SHA256) (cipher.SHA256, uint32, error) {
+	uxs, err := el.history.GetUxOuts(tx, []cipher.SHA256{uxID})
+	if err != nil {
+		return cipher.SHA256{}, 0, err
+	}
+
+	var srcTxn coin.Transaction
+	srcTxID := uxs[0].Out.Body.SrcTransaction
+	if uxs[0].Out.Head.BkSeq == 0 {
+		// The outputs of the genesis block have the null hash as their SrcTransaction
+		gb, err := el.blockchain.GetGenesisBlock(tx)
+		if err != nil {
+			return cipher.SHA256{}, 0, err
+		}
+		if gb == nil {
+			return cipher.SHA256{}, 0, errors.New("genesis block not found")
+		}
+		srcTxn = gb.Body.Transactions[0]
+	} else {
+		txn, err := el.history.GetTransaction(tx, srcTxID)
+		if err != nil {
+			return cipher.SHA256{}, 0, err
+		}
+		if txn == nil {
+			return cipher.SHA256{}, 0, fmt.Errorf("source transaction %s of output %s not found", srcTxID.Hex(), uxID.Hex())
+		}
+		srcTxn = txn.Txn
+	}
+
+	for i, o := range srcTxn.Out {
+		if o.UxID(srcTxID) == uxID {
+			return srcTxn.Hash(), uint32(i), nil
+		}
+	}
+
+	return cipher.SHA256{}, 0, fmt.Errorf("output %s not found in its source transaction %s", uxID.Hex(), srcTxID.Hex())
+}
+
+// logTo writes the events of the blocks after the last logged block, up to the block at height
+func (el eventLog) logTo(tx *dbutil.Tx, bc Blockchainer, height uint64) error {
+	lastSeq, ok, err := el.lastSeq(tx)
+	if err != nil {
+		return err
+	}
+
+	start := uint64(0)
+	if ok {
+		start = lastSeq + 1
+	}
+
+	if start > height {
+		return nil
+	}
+
+	// The events can't be logged from pruned blocks
+	if err := checkPruned(tx, bc, start); err != nil {
+		return fmt.Errorf("the event log can't be built up to the head block: %v", err)
+	}
+
+	logger.Infof("Logging the events of blocks %d to %d", start, height)
+
+	for seq := start; seq <= height; seq++ {
+		b, err := bc.GetSignedBlockBySeq(tx, seq)
+		if err != nil {
+			return err
+		}
+		if b == nil {
+			return fmt.Errorf("no block exists in depth: %d", seq)
+		}
+
+		if err := el.logBlock(tx, b.Block); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// events returns the events of the blocks from seq since, of type typ or of any type if typ is 0.
+// Whole blocks are returned, until at least limit events are returned.
+// Also returns the seq to read the next events from.
+func (el eventLog) events(tx *dbutil.Tx, since uint64, typ EventType, limit int) ([]Event, uint64, error) {
+	// A read-only database created before the event log has no bucket
+	bkt := tx.Bucket(EventLogBkt)
+	if bkt == nil {
+		return nil, since, nil
+	}
+
+	var events []Event
+	next := since
+	c := bkt.Cursor()
+	for k, v := c.Seek(eventKey(since, 0)); k != nil; k, v = c.Next() {
+		e, err := decodeEvent(k, v)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// Stop at a block boundary
+		if e.Seq >= next && len(events) >= limit {
+			break
+		}
+		next = e.Seq + 1
+
+		if typ == 0 || e.Type == typ {
+			events = append(events, e)
+		}
+	}
+
+	return events, next, nil
+}
+
+// GetEvents returns the events of the event log, starting at the block at seq since.
+// If typ is not 0, only the events of that type are returned.
+// The events of whole blocks are returned, until at least limit events are returned.
+// Also returns the seq to get the next events from.
+// Returns ErrEventLogDisabled if the event log is disabled.
+func (vs *Visor) GetEvents(since uint64, typ EventType, limit int) ([]Event, uint64, error) {
+	if !vs.Config.EnableEventLog {
+		return nil, 0, ErrEventLogDisabled
+	}
+
+	var events []Event
+	var next uint64
+	if err := vs.db.View("GetEvents", func(tx *dbutil.Tx) error {
+		var err error
+		events, next, err = vs.eventLog.events(tx, since, typ, limit)
+		return err
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	return events, next, nil
+}
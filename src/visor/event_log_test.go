@@ -0,0 +1,184 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func newEventLogTestVisor(t *testing.T, db *dbutil.DB, enabled bool) *Visor {
+	cfg := NewConfig()
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+	cfg.Distribution = params.MainNetDistribution
+	cfg.EnableEventLog = enabled
+
+	v, err := New(cfg, db, nil)
+	require.NoError(t, err)
+
+	return v
+}
+
+func TestEventLog(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	// The events are not recorded while the event log is disabled
+	v := newEventLogTestVisor(t, db, false)
+	_, _, err := v.GetEvents(0, 0, 100)
+	require.Equal(t, ErrEventLogDisabled, err)
+
+	gb := addGenesisBlockToVisor(t, v)
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+
+	addr := testutil.MakeAddress()
+	txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, addr, 100e6)
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		b, err := v.blockchain.NewBlock(tx, coin.Transactions{txn}, genTime+100)
+		require.NoError(t, err)
+
+		return v.executeSignedBlock(tx, coin.SignedBlock{
+			Block: *b,
+			Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+		})
+	})
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		_, ok, err := v.eventLog.lastSeq(tx)
+		require.False(t, ok)
+		return err
+	})
+	require.NoError(t, err)
+
+	// Enabling the event log records the events of the existing blocks
+	v = newEventLogTestVisor(t, db, true)
+
+	gbTxn := gb.Body.Transactions[0]
+	genesisCreated := Event{
+		Seq:   0,
+		Index: 0,
+		Type:  EventOutputCreated,
+		OutputCreated: &OutputCreated{
+			TxID:      gbTxn.Hash(),
+			OutputIdx: 0,
+			Address:   genAddress,
+			Coins:     gbTxn.Out[0].Coins,
+			CoinHours: gbTxn.Out[0].Hours,
+		},
+	}
+	spent := Event{
+		Seq:   1,
+		Index: 0,
+		Type:  EventOutputSpent,
+		OutputSpent: &OutputSpent{
+			TxID:            txn.Hash(),
+			InputIdx:        0,
+			SourceTxID:      gbTxn.Hash(),
+			SourceOutputIdx: 0,
+		},
+	}
+	created := []Event{
+		{
+			Seq:   1,
+			Index: 1,
+			Type:  EventOutputCreated,
+			OutputCreated: &OutputCreated{
+				TxID:      txn.Hash(),
+				OutputIdx: 0,
+				Address:   addr,
+				Coins:     100e6,
+				CoinHours: txn.Out[0].Hours,
+			},
+		},
+		{
+			Seq:   1,
+			Index: 2,
+			Type:  EventOutputCreated,
+			OutputCreated: &OutputCreated{
+				TxID:      txn.Hash(),
+				OutputIdx: 1,
+				Address:   genAddress,
+				Coins:     txn.Out[1].Coins,
+				CoinHours: txn.Out[1].Hours,
+			},
+		},
+	}
+
+	events, next, err := v.GetEvents(0, 0, 100)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), next)
+	require.Equal(t, append([]Event{genesisCreated, spent}, created...), events)
+
+	// The events of whole blocks are returned
+	events, next, err = v.GetEvents(0, 0, 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), next)
+	require.Equal(t, []Event{genesisCreated}, events)
+
+	events, next, err = v.GetEvents(1, 0, 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), next)
+	require.Equal(t, append([]Event{spent}, created...), events)
+
+	// Filter by type
+	events, next, err = v.GetEvents(0, EventOutputSpent, 100)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), next)
+	require.Equal(t, []Event{spent}, events)
+
+	// No more events
+	events, next, err = v.GetEvents(2, 0, 100)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), next)
+	require.Empty(t, events)
+
+	// New blocks are logged when executed
+	head, err := v.GetHeadBlock()
+	require.NoError(t, err)
+	uxs = coin.CreateUnspents(head.Head, txn)
+	txn2 := makeSpendTxn(t, coin.UxArray{uxs[1]}, []cipher.SecKey{genSecret}, genAddress, uxs[1].Body.Coins)
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		b, err := v.blockchain.NewBlock(tx, coin.Transactions{txn2}, genTime+200)
+		require.NoError(t, err)
+
+		return v.executeSignedBlock(tx, coin.SignedBlock{
+			Block: *b,
+			Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+		})
+	})
+	require.NoError(t, err)
+
+	events, next, err = v.GetEvents(2, 0, 100)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), next)
+	require.Len(t, events, 2)
+	require.Equal(t, EventOutputSpent, events[0].Type)
+	require.Equal(t, OutputSpent{
+		TxID:            txn2.Hash(),
+		InputIdx:        0,
+		SourceTxID:      txn.Hash(),
+		SourceOutputIdx: 1,
+	}, *events[0].OutputSpent)
+	require.Equal(t, EventOutputCreated, events[1].Type)
+	require.Equal(t, txn2.Hash(), events[1].OutputCreated.TxID)
+}
+
+func TestParseEventType(t *testing.T) {
+	typ, err := ParseEventType("output_created")
+	require.NoError(t, err)
+	require.Equal(t, EventOutputCreated, typ)
+
+	typ, err = ParseEventType("output_spent")
+	require.NoError(t, err)
+	require.Equal(t, EventOutputSpent, typ)
+
+	_, err = ParseEventType("foo")
+	require.EqualError(t, err, `invalid event type "foo", must be "output_created" or "output_spent"`)
+}
@@ -0,0 +1,162 @@
+package visor
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/util/mathutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// BlockFeeHistory is the distribution of the fee rates of the transactions of a block.
+// A fee rate is the fee of a transaction in coin hours, divided by the size of the transaction in bytes.
+type BlockFeeHistory struct {
+	Height        uint64
+	TxCount       int
+	TimestampUnix uint64
+	MinFeeRate    float64
+	MedianFeeRate float64
+	MaxFeeRate    float64
+}
+
+// feeHistoryCache holds the fee history of the last blocks, since computing it requires reading the inputs of all their transactions
+type feeHistoryCache struct {
+	sync.Mutex
+	headSeq uint64
+	// Number of blocks requested when the history was computed. history can have fewer blocks if the blockchain is shorter
+	n       uint64
+	history []BlockFeeHistory
+}
+
+// GetFeeHistory returns the fee history of the last n blocks, ordered by height.
+// The genesis block is not included, since its transaction has no fee.
+// The history is cached until the head block changes.
+// Returns ErrBlockPruned if the transactions of any of the blocks have been pruned.
+func (vs *Visor) GetFeeHistory(n uint64) ([]BlockFeeHistory, error) {
+	vs.feeHistoryCache.Lock()
+	defer vs.feeHistoryCache.Unlock()
+
+	var history []BlockFeeHistory
+	if err := vs.db.View("GetFeeHistory", func(tx *dbutil.Tx) error {
+		headSeq, ok, err := vs.blockchain.HeadSeq(tx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+
+		cache := vs.feeHistoryCache
+		if cache.history != nil && cache.headSeq == headSeq && cache.n >= n {
+			history = cache.history
+			return nil
+		}
+
+		blocks, err := vs.blockchain.GetLastBlocks(tx, n)
+		if err != nil {
+			return err
+		}
+
+		if err := vs.checkBlocksPruned(tx, blocks); err != nil {
+			return err
+		}
+
+		history = make([]BlockFeeHistory, 0, len(blocks))
+		for i := range blocks {
+			if blocks[i].Seq() == 0 {
+				continue
+			}
+
+			h, err := vs.blockFeeHistory(tx, &blocks[i])
+			if err != nil {
+				return err
+			}
+			history = append(history, h)
+		}
+
+		cache.headSeq = headSeq
+		cache.n = n
+		cache.history = history
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// The cached history may have been computed for more blocks
+	if uint64(len(history)) > n {
+		history = history[uint64(len(history))-n:]
+	}
+
+	// Copy the cached history so that it can't be modified by the caller
+	return append([]BlockFeeHistory{}, history...), nil
+}
+
+func (vs *Visor) blockFeeHistory(tx *dbutil.Tx, b *coin.SignedBlock) (BlockFeeHistory, error) {
+	inputs, err := vs.getBlockInputs(tx, b)
+	if err != nil {
+		return BlockFeeHistory{}, err
+	}
+
+	rates := make([]float64, len(b.Body.Transactions))
+	for i, txn := range b.Body.Transactions {
+		fee, err := transactionFee(&txn, inputs[i])
+		if err != nil {
+			return BlockFeeHistory{}, fmt.Errorf("block %d transaction %s: %v", b.Seq(), txn.Hash().Hex(), err)
+		}
+
+		size, err := txn.Size()
+		if err != nil {
+			return BlockFeeHistory{}, err
+		}
+
+		rates[i] = float64(fee) / float64(size)
+	}
+
+	h := BlockFeeHistory{
+		Height:        b.Seq(),
+		TxCount:       len(b.Body.Transactions),
+		TimestampUnix: b.Time(),
+	}
+
+	if len(rates) == 0 {
+		return h, nil
+	}
+
+	sort.Float64s(rates)
+
+	h.MinFeeRate = rates[0]
+	h.MaxFeeRate = rates[len(rates)-1]
+	if len(rates)%2 == 1 {
+		h.MedianFeeRate = rates[len(rates)/2]
+	} else {
+		h.MedianFeeRate = (rates[len(rates)/2-1] + rates[len(rates)/2]) / 2
+	}
+
+	return h, nil
+}
+
+// transactionFee returns the coin hours of the inputs of a transaction that are not spent by its outputs
+func transactionFee(txn *coin.Transaction, inputs []TransactionInput) (uint64, error) {
+	var inputHours uint64
+	for _, in := range inputs {
+		var err error
+		inputHours, err = mathutil.AddUint64(inputHours, in.CalculatedHours)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	outputHours, err := txn.OutputHours()
+	if err != nil {
+		return 0, err
+	}
+
+	if outputHours > inputHours {
+		return 0, fmt.Errorf("output hours %d exceed input hours %d", outputHours, inputHours)
+	}
+
+	return inputHours - outputHours, nil
+}
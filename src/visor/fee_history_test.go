@@ -0,0 +1,184 @@
+package visor
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestGetFeeHistory(t *testing.T) {
+	v, shutdown := newChainExportTestVisor(t)
+	defer shutdown()
+
+	// An empty blockchain has no fee history
+	history, err := v.GetFeeHistory(10)
+	require.NoError(t, err)
+	require.Empty(t, history)
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	// The genesis block is not included
+	history, err = v.GetFeeHistory(10)
+	require.NoError(t, err)
+	require.Empty(t, history)
+
+	executeBlock := func(txns coin.Transactions, time uint64) *coin.SignedBlock {
+		var sb coin.SignedBlock
+		err := v.db.Update("", func(tx *dbutil.Tx) error {
+			b, err := v.blockchain.NewBlock(tx, txns, time)
+			require.NoError(t, err)
+
+			sb = coin.SignedBlock{
+				Block: *b,
+				Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+			}
+			return v.executeSignedBlock(tx, sb)
+		})
+		require.NoError(t, err)
+		return &sb
+	}
+
+	// expectedRate computes the fee rate of a transaction spending uxs, from the hours of uxs at the previous block time
+	expectedRate := func(txn coin.Transaction, uxs coin.UxArray, prevTime uint64) float64 {
+		var inputHours uint64
+		for _, ux := range uxs {
+			hours, err := ux.CoinHours(prevTime)
+			require.NoError(t, err)
+			inputHours += hours
+		}
+
+		outputHours, err := txn.OutputHours()
+		require.NoError(t, err)
+
+		size, err := txn.Size()
+		require.NoError(t, err)
+
+		return float64(inputHours-outputHours) / float64(size)
+	}
+
+	// Block 1 splits the genesis output
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	splitTxn := makeUnspentsTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, 4, params.UserVerifyTxn.MaxDropletPrecision)
+	b1 := executeBlock(coin.Transactions{splitTxn}, genTime+3600)
+	splitRate := expectedRate(splitTxn, uxs, gb.Time())
+
+	// Block 2 has transactions with different fees
+	uxs = coin.CreateUnspents(b1.Head, splitTxn)
+	var txns coin.Transactions
+	var rates []float64
+	for i, fee := range []uint64{10, 1000, 100} {
+		txn := makeSpendTxWithFee(t, coin.UxArray{uxs[i]}, []cipher.SecKey{genSecret}, genAddress, uxs[i].Body.Coins, fee)
+		txns = append(txns, txn)
+		rates = append(rates, expectedRate(txn, coin.UxArray{uxs[i]}, b1.Time()))
+	}
+	b2 := executeBlock(txns, genTime+7200)
+	sort.Float64s(rates)
+
+	expectedHistory := []BlockFeeHistory{
+		{
+			Height:        1,
+			TxCount:       1,
+			TimestampUnix: b1.Time(),
+			MinFeeRate:    splitRate,
+			MedianFeeRate: splitRate,
+			MaxFeeRate:    splitRate,
+		},
+		{
+			Height:        2,
+			TxCount:       3,
+			TimestampUnix: b2.Time(),
+			MinFeeRate:    rates[0],
+			MedianFeeRate: rates[1],
+			MaxFeeRate:    rates[2],
+		},
+	}
+
+	history, err = v.GetFeeHistory(10)
+	require.NoError(t, err)
+	require.Equal(t, expectedHistory, history)
+
+	// The history of fewer blocks is taken from the cache
+	v.feeHistoryCache.history[1].MaxFeeRate = 0
+	history, err = v.GetFeeHistory(1)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, float64(0), history[0].MaxFeeRate)
+
+	// The history of more blocks than cached is recomputed
+	history, err = v.GetFeeHistory(20)
+	require.NoError(t, err)
+	require.Equal(t, expectedHistory, history)
+
+	// The history is recomputed when the head block changes
+	v.feeHistoryCache.history[1].MaxFeeRate = 0
+	uxs = coin.CreateUnspents(b2.Head, txns[0])
+	txn := makeSpendTxWithFee(t, uxs, []cipher.SecKey{genSecret}, testutil.MakeAddress(), uxs[0].Body.Coins, 0)
+	b3 := executeBlock(coin.Transactions{txn}, genTime+10800)
+	rate := expectedRate(txn, uxs, b2.Time())
+
+	history, err = v.GetFeeHistory(2)
+	require.NoError(t, err)
+	require.Equal(t, []BlockFeeHistory{
+		expectedHistory[1],
+		{
+			Height:        3,
+			TxCount:       1,
+			TimestampUnix: b3.Time(),
+			MinFeeRate:    rate,
+			MedianFeeRate: rate,
+			MaxFeeRate:    rate,
+		},
+	}, history)
+}
+
+func TestBlockFeeHistoryMedian(t *testing.T) {
+	v, shutdown := newChainExportTestVisor(t)
+	defer shutdown()
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	splitTxn := makeUnspentsTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, 2, params.UserVerifyTxn.MaxDropletPrecision)
+
+	var txns coin.Transactions
+	err := v.db.Update("", func(tx *dbutil.Tx) error {
+		b, err := v.blockchain.NewBlock(tx, coin.Transactions{splitTxn}, genTime+3600)
+		require.NoError(t, err)
+		if err := v.executeSignedBlock(tx, coin.SignedBlock{
+			Block: *b,
+			Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+		}); err != nil {
+			return err
+		}
+
+		uxs = coin.CreateUnspents(b.Head, splitTxn)
+		for i, fee := range []uint64{10, 1000} {
+			txns = append(txns, makeSpendTxWithFee(t, coin.UxArray{uxs[i]}, []cipher.SecKey{genSecret}, genAddress, uxs[i].Body.Coins, fee))
+		}
+
+		b, err = v.blockchain.NewBlock(tx, txns, genTime+7200)
+		require.NoError(t, err)
+		return v.executeSignedBlock(tx, coin.SignedBlock{
+			Block: *b,
+			Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+		})
+	})
+	require.NoError(t, err)
+
+	history, err := v.GetFeeHistory(1)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+
+	// The median of an even number of transactions is the mean of the middle two
+	h := history[0]
+	require.Equal(t, 2, h.TxCount)
+	require.True(t, h.MinFeeRate < h.MaxFeeRate)
+	require.Equal(t, (h.MinFeeRate+h.MaxFeeRate)/2, h.MedianFeeRate)
+}
@@ -0,0 +1,159 @@
+package visor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/util/mathutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+var (
+	// ErrNoGenesisOutputs is returned if a genesis outputs file has no outputs
+	ErrNoGenesisOutputs = errors.New("Genesis outputs file has no outputs")
+)
+
+// ErrGenesisOutputsMismatch is returned if the genesis outputs hash recorded in the genesis block
+// does not match the hash of the genesis outputs file
+type ErrGenesisOutputsMismatch struct {
+	Hash        cipher.SHA256
+	GenesisHash cipher.SHA256
+}
+
+func (e ErrGenesisOutputsMismatch) Error() string {
+	return fmt.Sprintf("Genesis outputs hash %s does not match the hash %s recorded in the genesis block", e.Hash.Hex(), e.GenesisHash.Hex())
+}
+
+// GenesisOutput is an output of the genesis block in a genesis outputs file.
+// Coins are in droplets.
+type GenesisOutput struct {
+	Address   string `json:"address"`
+	Coins     uint64 `json:"coins"`
+	CoinHours uint64 `json:"coin_hours"`
+}
+
+// GenesisOutputs is the distribution of the genesis block coins and coin hours read from a genesis outputs file
+type GenesisOutputs struct {
+	Outputs []coin.TransactionOutput
+	// Hash is the SHA256 of the canonical JSON of the outputs, it is recorded in the genesis block header
+	Hash cipher.SHA256
+}
+
+// NewGenesisOutputs verifies the outputs of a genesis outputs file and hashes them
+func NewGenesisOutputs(outputs []GenesisOutput) (*GenesisOutputs, error) {
+	if len(outputs) == 0 {
+		return nil, ErrNoGenesisOutputs
+	}
+
+	// The outputs of the genesis block have the null hash as their SrcTransaction,
+	// identical outputs would have the same UxOut hash
+	seen := make(map[GenesisOutput]struct{}, len(outputs))
+	txnOuts := make([]coin.TransactionOutput, len(outputs))
+	var totalCoins uint64
+	for i, o := range outputs {
+		addr, err := cipher.DecodeBase58Address(o.Address)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid genesis output %d address %q: %v", i, o.Address, err)
+		}
+
+		if o.Coins == 0 {
+			return nil, fmt.Errorf("Genesis output %d has zero coins", i)
+		}
+
+		totalCoins, err = mathutil.AddUint64(totalCoins, o.Coins)
+		if err != nil {
+			return nil, errors.New("Genesis outputs coins overflow")
+		}
+
+		o.Address = addr.String()
+		if _, ok := seen[o]; ok {
+			return nil, fmt.Errorf("Genesis output %d is a duplicate", i)
+		}
+		seen[o] = struct{}{}
+
+		txnOuts[i] = coin.TransactionOutput{
+			Address: addr,
+			Coins:   o.Coins,
+			Hours:   o.CoinHours,
+		}
+	}
+
+	return &GenesisOutputs{
+		Outputs: txnOuts,
+		Hash:    genesisOutputsHash(txnOuts),
+	}, nil
+}
+
+// genesisOutputsHash returns the SHA256 of the compact JSON array of the outputs,
+// with the fields of every output in the order address, coins, coin_hours
+func genesisOutputsHash(outputs []coin.TransactionOutput) cipher.SHA256 {
+	canonical := make([]GenesisOutput, len(outputs))
+	for i, o := range outputs {
+		canonical[i] = GenesisOutput{
+			Address:   o.Address.String(),
+			Coins:     o.Coins,
+			CoinHours: o.Hours,
+		}
+	}
+
+	b, err := json.Marshal(canonical)
+	if err != nil {
+		logger.Panicf("json.Marshal genesis outputs failed: %v", err)
+	}
+
+	return cipher.SumSHA256(b)
+}
+
+// LoadGenesisOutputs loads a genesis outputs file, a JSON array of {address, coins, coin_hours} objects
+func LoadGenesisOutputs(path string) (*GenesisOutputs, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+
+	var outputs []GenesisOutput
+	if err := d.Decode(&outputs); err != nil {
+		return nil, fmt.Errorf("Invalid genesis outputs file %s: %v", path, err)
+	}
+
+	return NewGenesisOutputs(outputs)
+}
+
+// NewGenesisBlock creates a genesis block that distributes the outputs, with the outputs hash recorded in its header
+func (g GenesisOutputs) NewGenesisBlock(timestamp uint64) (*coin.Block, error) {
+	return coin.NewGenesisBlockFromOutputs(g.Outputs, timestamp, g.Hash)
+}
+
+// verifyGenesisOutputs checks that the genesis block was created from the configured genesis outputs.
+// The genesis block's signature covers its header, so the recorded hash can't be changed without
+// invalidating the genesis signature.
+func (vs *Visor) verifyGenesisOutputs(tx *dbutil.Tx) error {
+	if vs.Config.GenesisOutputs == nil {
+		return nil
+	}
+
+	gb, err := vs.blockchain.GetGenesisBlock(tx)
+	if err != nil {
+		return err
+	}
+	if gb == nil {
+		return nil
+	}
+
+	if gb.Head.UxHash != vs.Config.GenesisOutputs.Hash {
+		return ErrGenesisOutputsMismatch{
+			Hash:        vs.Config.GenesisOutputs.Hash,
+			GenesisHash: gb.Head.UxHash,
+		}
+	}
+
+	return nil
+}
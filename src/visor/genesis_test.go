@@ -0,0 +1,184 @@
+package visor
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestLoadGenesisOutputs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "genesis-outputs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	addr1 := cipher.MustDecodeBase58Address("R6aHqKWSQfvpdo2fGSrq4F1RYXkBWR9HHJ")
+	addr2 := cipher.MustDecodeBase58Address("2EYM4WFHe4Dgz6kjAdUkM6Etep7ruz2ia6h")
+
+	cases := []struct {
+		name    string
+		data    string
+		outputs []coin.TransactionOutput
+		err     error
+	}{
+		{
+			name: "valid",
+			// The hash is of the canonical JSON, not of the file's formatting
+			data: `[
+				{"coin_hours": 10, "coins": 1000000000000, "address": "R6aHqKWSQfvpdo2fGSrq4F1RYXkBWR9HHJ"},
+				{"address": "2EYM4WFHe4Dgz6kjAdUkM6Etep7ruz2ia6h", "coins": 2000000}
+			]`,
+			outputs: []coin.TransactionOutput{
+				{Address: addr1, Coins: 1e12, Hours: 10},
+				{Address: addr2, Coins: 2e6, Hours: 0},
+			},
+		},
+		{
+			name: "no outputs",
+			data: `[]`,
+			err:  ErrNoGenesisOutputs,
+		},
+		{
+			name: "invalid address",
+			data: `[{"address": "R6aHqKWSQfvpdo2fGSrq4F1RYXkBWR9HHK", "coins": 1}]`,
+			err:  errors.New(`Invalid genesis output 0 address "R6aHqKWSQfvpdo2fGSrq4F1RYXkBWR9HHK": Invalid checksum`),
+		},
+		{
+			name: "zero coins",
+			data: `[{"address": "R6aHqKWSQfvpdo2fGSrq4F1RYXkBWR9HHJ", "coins": 1}, {"address": "R6aHqKWSQfvpdo2fGSrq4F1RYXkBWR9HHJ", "coin_hours": 1}]`,
+			err:  errors.New("Genesis output 1 has zero coins"),
+		},
+		{
+			name: "coins overflow",
+			data: `[{"address": "R6aHqKWSQfvpdo2fGSrq4F1RYXkBWR9HHJ", "coins": 18446744073709551615}, {"address": "2EYM4WFHe4Dgz6kjAdUkM6Etep7ruz2ia6h", "coins": 1}]`,
+			err:  errors.New("Genesis outputs coins overflow"),
+		},
+		{
+			name: "duplicate output",
+			data: `[{"address": "R6aHqKWSQfvpdo2fGSrq4F1RYXkBWR9HHJ", "coins": 1}, {"address": "R6aHqKWSQfvpdo2fGSrq4F1RYXkBWR9HHJ", "coins": 1}]`,
+			err:  errors.New("Genesis output 1 is a duplicate"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, "genesis_outputs.json")
+			require.NoError(t, ioutil.WriteFile(path, []byte(tc.data), 0600))
+
+			g, err := LoadGenesisOutputs(path)
+			if tc.err != nil {
+				require.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.outputs, g.Outputs)
+			require.Equal(t, "76e811ed3daba19c7589ebcdfdea95ab9a265f92f55fbb26add710cb457a3218", g.Hash.Hex())
+		})
+	}
+
+	path := filepath.Join(dir, "genesis_outputs.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`[{"address": "R6aHqKWSQfvpdo2fGSrq4F1RYXkBWR9HHJ", "coins": 1, "hours": 1}]`), 0600))
+	_, err = LoadGenesisOutputs(path)
+	require.Error(t, err)
+
+	_, err = LoadGenesisOutputs(filepath.Join(dir, "missing.json"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestVisorGenesisOutputs(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	addr := testutil.MakeAddress()
+	g, err := NewGenesisOutputs([]GenesisOutput{
+		{Address: genAddress.String(), Coins: 100e6, CoinHours: 1000},
+		{Address: addr.String(), Coins: 10e6},
+	})
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = genSecret
+	cfg.GenesisTimestamp = genTime
+	cfg.GenesisOutputs = g
+	cfg.Distribution = params.MainNetDistribution
+
+	v, err := New(cfg, db, nil)
+	require.NoError(t, err)
+	require.NoError(t, v.Init())
+
+	// The genesis block distributes the outputs and records their hash
+	var gb *coin.SignedBlock
+	err = db.View("", func(tx *dbutil.Tx) error {
+		var err error
+		gb, err = v.blockchain.GetGenesisBlock(tx)
+		return err
+	})
+	require.NoError(t, err)
+	require.NotNil(t, gb)
+	require.Equal(t, g.Hash, gb.Head.UxHash)
+	require.Len(t, gb.Body.Transactions, 1)
+	require.Equal(t, g.Outputs, gb.Body.Transactions[0].Out)
+
+	expected, err := g.NewGenesisBlock(genTime)
+	require.NoError(t, err)
+	require.Equal(t, expected.HashHeader(), gb.HashHeader())
+
+	uxs, err := v.GetUnspentOutputsSummary(nil)
+	require.NoError(t, err)
+	require.Len(t, uxs.Confirmed, 2)
+
+	// Restarting with the same outputs succeeds
+	v, err = New(cfg, db, nil)
+	require.NoError(t, err)
+	require.NoError(t, v.Init())
+
+	// Restarting with other outputs is aborted
+	other, err := NewGenesisOutputs([]GenesisOutput{
+		{Address: genAddress.String(), Coins: 110e6, CoinHours: 1000},
+	})
+	require.NoError(t, err)
+
+	cfg.GenesisOutputs = other
+	v, err = New(cfg, db, nil)
+	require.NoError(t, err)
+	require.Equal(t, ErrGenesisOutputsMismatch{
+		Hash:        other.Hash,
+		GenesisHash: g.Hash,
+	}, v.Init())
+
+	// Without genesis outputs the genesis block is not checked
+	cfg.GenesisOutputs = nil
+	v, err = New(cfg, db, nil)
+	require.NoError(t, err)
+	require.NoError(t, v.Init())
+}
+
+func TestVisorGenesisOutputsWithoutHash(t *testing.T) {
+	v, shutdown := newChainExportTestVisor(t)
+	defer shutdown()
+
+	// A genesis block created from a genesis address has no genesis outputs hash
+	addGenesisBlockToVisor(t, v)
+
+	g, err := NewGenesisOutputs([]GenesisOutput{
+		{Address: genAddress.String(), Coins: genCoins, CoinHours: genCoins},
+	})
+	require.NoError(t, err)
+
+	v.Config.GenesisOutputs = g
+	require.Equal(t, ErrGenesisOutputsMismatch{
+		Hash: g.Hash,
+	}, v.Init())
+}
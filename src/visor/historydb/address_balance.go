@@ -0,0 +1,70 @@
+package historydb
+
+import (
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// AddressBalanceBkt caches the balances of addresses at past blocks
+var AddressBalanceBkt = []byte("address_balance_snapshots")
+
+// MaxAddressBalanceSnapshots is the maximum number of cached address balances.
+// Balances are computed without being cached once the cache is full.
+const MaxAddressBalanceSnapshots = 100000
+
+// AddressBalance is the confirmed balance of an address at a block
+type AddressBalance struct {
+	Coins uint64
+	Hours uint64
+}
+
+// addressBalances bucket stores computed AddressBalances, keyed by address and block seq.
+// A balance at a confirmed block never changes, so the entries don't have to be invalidated.
+type addressBalances struct {
+	maxEntries uint64
+}
+
+func addressBalanceKey(addr cipher.Address, seq uint64) []byte {
+	return append(addr.Bytes(), dbutil.Itob(seq)...)
+}
+
+// get returns nil on not found
+func (ab *addressBalances) get(tx *dbutil.Tx, addr cipher.Address, seq uint64) (*AddressBalance, error) {
+	v, err := dbutil.GetBucketValueNoCopy(tx, AddressBalanceBkt, addressBalanceKey(addr, seq))
+	if err != nil {
+		return nil, err
+	} else if v == nil {
+		return nil, nil
+	}
+
+	if len(v) != 16 {
+		return nil, fmt.Errorf("invalid address balance snapshot length %d", len(v))
+	}
+
+	return &AddressBalance{
+		Coins: dbutil.Btoi(v[:8]),
+		Hours: dbutil.Btoi(v[8:]),
+	}, nil
+}
+
+// put sets the balance of an address at a block, does nothing if the bucket has maxEntries entries.
+// The entries are counted from the bucket stats, which do not include the entries put in the current transaction.
+func (ab *addressBalances) put(tx *dbutil.Tx, addr cipher.Address, seq uint64, b AddressBalance) error {
+	n, err := dbutil.Len(tx, AddressBalanceBkt)
+	if err != nil {
+		return err
+	}
+	if n >= ab.maxEntries {
+		return nil
+	}
+
+	v := append(dbutil.Itob(b.Coins), dbutil.Itob(b.Hours)...)
+	return dbutil.PutBucketValue(tx, AddressBalanceBkt, addressBalanceKey(addr, seq), v)
+}
+
+// reset resets the bucket
+func (ab *addressBalances) reset(tx *dbutil.Tx) error {
+	return dbutil.Reset(tx, AddressBalanceBkt)
+}
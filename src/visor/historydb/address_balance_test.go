@@ -0,0 +1,134 @@
+package historydb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestAddressBalanceSnapshots(t *testing.T) {
+	db, td := prepareDB(t)
+	defer td()
+
+	addr := makeAddress()
+	noHistoryAddr := makeAddress()
+	hd := New()
+
+	err := db.Update("", func(tx *dbutil.Tx) error {
+		require.NoError(t, hd.addrUx.add(tx, addr, cipher.SumSHA256([]byte("out"))))
+
+		b, err := hd.GetAddressBalanceSnapshot(tx, addr, 10)
+		require.NoError(t, err)
+		require.Nil(t, b)
+
+		require.NoError(t, hd.SetAddressBalanceSnapshot(tx, addr, 10, AddressBalance{Coins: 1e6, Hours: 20}))
+		require.NoError(t, hd.SetAddressBalanceSnapshot(tx, addr, 11, AddressBalance{Coins: 2e6, Hours: 30}))
+
+		b, err = hd.GetAddressBalanceSnapshot(tx, addr, 10)
+		require.NoError(t, err)
+		require.Equal(t, &AddressBalance{Coins: 1e6, Hours: 20}, b)
+
+		b, err = hd.GetAddressBalanceSnapshot(tx, addr, 11)
+		require.NoError(t, err)
+		require.Equal(t, &AddressBalance{Coins: 2e6, Hours: 30}, b)
+
+		b, err = hd.GetAddressBalanceSnapshot(tx, makeAddress(), 10)
+		require.NoError(t, err)
+		require.Nil(t, b)
+
+		// Balances of addresses without outputs are not cached
+		require.NoError(t, hd.SetAddressBalanceSnapshot(tx, noHistoryAddr, 10, AddressBalance{}))
+		b, err = hd.GetAddressBalanceSnapshot(tx, noHistoryAddr, 10)
+		require.NoError(t, err)
+		require.Nil(t, b)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Nothing is cached once the cache is full.
+	// The number of entries is read from the committed bucket, so this is checked in a new transaction
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		hd.balances.maxEntries = 2
+		require.NoError(t, hd.SetAddressBalanceSnapshot(tx, addr, 12, AddressBalance{Coins: 3e6, Hours: 40}))
+		b, err := hd.GetAddressBalanceSnapshot(tx, addr, 12)
+		require.NoError(t, err)
+		require.Nil(t, b)
+
+		// Erasing the history removes the snapshots
+		require.NoError(t, hd.Erase(tx))
+
+		b, err = hd.GetAddressBalanceSnapshot(tx, addr, 10)
+		require.NoError(t, err)
+		require.Nil(t, b)
+
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestAddressBalanceAt(t *testing.T) {
+	addr := makeAddress()
+
+	outs := []UxOut{
+		// created at 1, spent at 3
+		{
+			Out: coin.UxOut{
+				Head: coin.UxHead{BkSeq: 1, Time: 1000},
+				Body: coin.UxBody{Address: addr, Coins: 10e6, Hours: 100},
+			},
+			SpentTxnID:    cipher.SumSHA256([]byte("spent")),
+			SpentBlockSeq: 3,
+		},
+		// created at 2, unspent
+		{
+			Out: coin.UxOut{
+				Head: coin.UxHead{BkSeq: 2, Time: 4600},
+				Body: coin.UxBody{Address: addr, Coins: 5e6, Hours: 50},
+			},
+		},
+	}
+
+	cases := []struct {
+		name      string
+		seq       uint64
+		blockTime uint64
+		balance   AddressBalance
+	}{
+		{
+			name:      "before any output",
+			seq:       0,
+			blockTime: 500,
+		},
+		{
+			name:      "first output",
+			seq:       1,
+			blockTime: 1000,
+			balance:   AddressBalance{Coins: 10e6, Hours: 100},
+		},
+		{
+			name:      "both outputs",
+			seq:       2,
+			blockTime: 1000 + 3600,
+			balance:   AddressBalance{Coins: 15e6, Hours: 100 + 10 + 50},
+		},
+		{
+			name:      "first output spent",
+			seq:       3,
+			blockTime: 4600 + 7200,
+			balance:   AddressBalance{Coins: 5e6, Hours: 50 + 10},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := addressBalanceAt(outs, tc.seq, tc.blockTime)
+			require.NoError(t, err)
+			require.Equal(t, tc.balance, b)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package historydb
 
 import (
 	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
 	"github.com/skycoin/skycoin/src/visor/dbutil"
 )
 
@@ -37,6 +38,32 @@ func (atx *addressTxns) get(tx *dbutil.Tx, addr cipher.Address) ([]cipher.SHA256
 	return txnHashes.Hashes, nil
 }
 
+// count returns the number of transaction hashes of given address.
+// Only the length prefix of the encoded hashes is read, the hashes are not decoded
+func (atx *addressTxns) count(tx *dbutil.Tx, addr cipher.Address) (uint64, error) {
+	v, err := dbutil.GetBucketValueNoCopy(tx, AddressTxnsBkt, addr.Bytes())
+	if err != nil {
+		return 0, err
+	} else if v == nil {
+		return 0, nil
+	}
+
+	d := &encoder.Decoder{
+		Buffer: v,
+	}
+
+	n, err := d.Uint32()
+	if err != nil {
+		return 0, err
+	}
+
+	if uint64(len(d.Buffer)) != uint64(n)*uint64(len(cipher.SHA256{})) {
+		return 0, encoder.ErrBufferUnderflow
+	}
+
+	return uint64(n), nil
+}
+
 // add adds a hash to an address's hash list
 func (atx *addressTxns) add(tx *dbutil.Tx, addr cipher.Address, hash cipher.SHA256) error {
 	hashes, err := atx.get(tx, addr)
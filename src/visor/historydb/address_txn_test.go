@@ -122,11 +122,22 @@ func TestAddAddressTxns(t *testing.T) {
 					hashes, err := addrTxns.get(tx, e.addr)
 					require.NoError(t, err)
 					require.Equal(t, e.txs, hashes)
+
+					count, err := addrTxns.count(tx, e.addr)
+					require.NoError(t, err)
+					require.Equal(t, uint64(len(e.txs)), count)
 					return nil
 				})
 				require.NoError(t, err)
 			}
 
+			err = db.View("", func(tx *dbutil.Tx) error {
+				count, err := addrTxns.count(tx, makeAddress())
+				require.NoError(t, err)
+				require.Equal(t, uint64(0), count)
+				return nil
+			})
+			require.NoError(t, err)
 		})
 	}
 }
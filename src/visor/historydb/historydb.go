@@ -11,6 +11,7 @@ import (
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/util/logging"
+	"github.com/skycoin/skycoin/src/util/mathutil"
 	"github.com/skycoin/skycoin/src/visor/dbutil"
 )
 
@@ -24,16 +25,18 @@ func CreateBuckets(tx *dbutil.Tx) error {
 		HistoryMetaBkt,
 		UxOutsBkt,
 		TransactionsBkt,
+		AddressBalanceBkt,
 	})
 }
 
 // HistoryDB provides APIs for blockchain explorer
 type HistoryDB struct {
-	outputs  *uxOuts       // outputs bucket
-	txns     *transactions // transactions bucket
-	addrUx   *addressUx    // bucket which stores all UxOuts that address received
-	addrTxns *addressTxns  // address related transaction bucket
-	meta     *historyMeta  // stores history meta info
+	outputs  *uxOuts          // outputs bucket
+	txns     *transactions    // transactions bucket
+	addrUx   *addressUx       // bucket which stores all UxOuts that address received
+	addrTxns *addressTxns     // address related transaction bucket
+	meta     *historyMeta     // stores history meta info
+	balances *addressBalances // caches address balances at past blocks
 }
 
 // New create HistoryDB instance
@@ -44,6 +47,9 @@ func New() *HistoryDB {
 		addrUx:   &addressUx{},
 		addrTxns: &addressTxns{},
 		meta:     &historyMeta{},
+		balances: &addressBalances{
+			maxEntries: MaxAddressBalanceSnapshots,
+		},
 	}
 }
 
@@ -105,6 +111,10 @@ func (hd *HistoryDB) Erase(tx *dbutil.Tx) error {
 		return err
 	}
 
+	if err := hd.balances.reset(tx); err != nil {
+		return err
+	}
+
 	return hd.txns.reset(tx)
 }
 
@@ -182,6 +192,36 @@ func (hd *HistoryDB) ParseBlock(tx *dbutil.Tx, b coin.Block) error {
 	return hd.SetParsedBlockSeq(tx, b.Seq())
 }
 
+// PruneBlock removes the transactions of a parsed block and the outputs spent by them.
+// The outputs created by the block are kept until the block that spends them is pruned,
+// so that the blocks which are not pruned can still be parsed and verified.
+// The outputs of the genesis block are kept, since the genesis block is never pruned.
+// The address indexes are kept, and refer to the removed transactions and outputs.
+func (hd *HistoryDB) PruneBlock(tx *dbutil.Tx, b coin.Block) error {
+	for _, t := range b.Body.Transactions {
+		if err := hd.txns.delete(tx, t.Hash()); err != nil {
+			return err
+		}
+
+		for _, in := range t.In {
+			o, err := hd.outputs.get(tx, in)
+			if err != nil {
+				return err
+			}
+
+			if o == nil || o.Out.Head.BkSeq == 0 {
+				continue
+			}
+
+			if err := hd.outputs.delete(tx, in); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // GetTransaction get transaction by hash.
 func (hd HistoryDB) GetTransaction(tx *dbutil.Tx, hash cipher.SHA256) (*Transaction, error) {
 	return hd.txns.get(tx, hash)
@@ -197,6 +237,68 @@ func (hd HistoryDB) GetOutputsForAddress(tx *dbutil.Tx, addr cipher.Address) ([]
 	return hd.outputs.getArray(tx, hashes)
 }
 
+// GetAddressBalanceSnapshot returns the cached balance of an address at block seq, returns nil if not cached
+func (hd HistoryDB) GetAddressBalanceSnapshot(tx *dbutil.Tx, addr cipher.Address, seq uint64) (*AddressBalance, error) {
+	return hd.balances.get(tx, addr, seq)
+}
+
+// SetAddressBalanceSnapshot caches the balance of an address at block seq.
+// Nothing is cached for addresses without outputs, or once MaxAddressBalanceSnapshots balances are cached.
+func (hd HistoryDB) SetAddressBalanceSnapshot(tx *dbutil.Tx, addr cipher.Address, seq uint64, b AddressBalance) error {
+	hashes, err := hd.addrUx.get(tx, addr)
+	if err != nil {
+		return err
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	return hd.balances.put(tx, addr, seq, b)
+}
+
+// GetAddressBalanceAt replays the outputs of an address to compute its balance after the block at seq was executed.
+// blockTime is the time of the block at seq, used to calculate the coin hours.
+func (hd HistoryDB) GetAddressBalanceAt(tx *dbutil.Tx, addr cipher.Address, seq, blockTime uint64) (AddressBalance, error) {
+	outs, err := hd.GetOutputsForAddress(tx, addr)
+	if err != nil {
+		return AddressBalance{}, err
+	}
+
+	return addressBalanceAt(outs, seq, blockTime)
+}
+
+// addressBalanceAt sums the outputs that were created at or before seq, and not spent at or before seq
+func addressBalanceAt(outs []UxOut, seq, blockTime uint64) (AddressBalance, error) {
+	var b AddressBalance
+	for _, o := range outs {
+		if o.Out.Head.BkSeq > seq {
+			continue
+		}
+
+		if o.SpentTxnID != (cipher.SHA256{}) && o.SpentBlockSeq <= seq {
+			continue
+		}
+
+		var err error
+		b.Coins, err = mathutil.AddUint64(b.Coins, o.Out.Body.Coins)
+		if err != nil {
+			return AddressBalance{}, err
+		}
+
+		hours, err := o.Out.CoinHours(blockTime)
+		if err != nil {
+			return AddressBalance{}, err
+		}
+
+		b.Hours, err = mathutil.AddUint64(b.Hours, hours)
+		if err != nil {
+			return AddressBalance{}, err
+		}
+	}
+
+	return b, nil
+}
+
 // GetTransactionHashesForAddresses returns transaction hashes of related addresses
 func (hd HistoryDB) GetTransactionHashesForAddresses(tx *dbutil.Tx, addrs []cipher.Address) ([]cipher.SHA256, error) {
 	var hashes []cipher.SHA256
@@ -219,6 +321,11 @@ func (hd HistoryDB) GetTransactionHashesForAddresses(tx *dbutil.Tx, addrs []ciph
 	return hashes, nil
 }
 
+// AddressTransactionCount returns the number of transactions of an address
+func (hd HistoryDB) AddressTransactionCount(tx *dbutil.Tx, addr cipher.Address) (uint64, error) {
+	return hd.addrTxns.count(tx, addr)
+}
+
 // AddressSeen returns true if the address appears in the blockchain
 func (hd HistoryDB) AddressSeen(tx *dbutil.Tx, addr cipher.Address) (bool, error) {
 	return hd.addrTxns.contains(tx, addr)
@@ -274,7 +381,7 @@ func (hd HistoryDB) Verify(tx *dbutil.Tx, b *coin.SignedBlock, indexesMap *Index
 
 		if txn == nil {
 			err := fmt.Errorf("HistoryDB.Verify: transaction %v does not exist in historydb", txnHash.Hex())
-			return ErrHistoryDBCorrupted{err}
+			return newErrHistoryDBCorrupted(TransactionsBkt, txnHash.Hex(), err)
 		}
 
 		for _, in := range t.In {
@@ -286,14 +393,14 @@ func (hd HistoryDB) Verify(tx *dbutil.Tx, b *coin.SignedBlock, indexesMap *Index
 
 			if o == nil {
 				err := fmt.Errorf("HistoryDB.Verify: transaction input %v does not exist in historydb", in.Hex())
-				return ErrHistoryDBCorrupted{err}
+				return newErrHistoryDBCorrupted(UxOutsBkt, in.Hex(), err)
 			}
 
 			// Checks the output's spend block seq
 			if o.SpentBlockSeq != b.Seq() {
 				err := fmt.Errorf("HistoryDB.Verify: spend block seq of transaction input %v is wrong, should be: %v, but is %v",
 					in.Hex(), b.Seq(), o.SpentBlockSeq)
-				return ErrHistoryDBCorrupted{err}
+				return newErrHistoryDBCorrupted(UxOutsBkt, in.Hex(), err)
 			}
 
 			addr := o.Out.Body.Address
@@ -331,13 +438,13 @@ func (hd HistoryDB) Verify(tx *dbutil.Tx, b *coin.SignedBlock, indexesMap *Index
 			if _, ok := txnHashesMap[txnHash]; !ok {
 				err := fmt.Errorf("HistoryDB.Verify: index of address transaction [%s:%s] does not exist in historydb",
 					addr, txnHash.Hex())
-				return ErrHistoryDBCorrupted{err}
+				return newErrHistoryDBCorrupted(AddressTxnsBkt, addr.String(), err)
 			}
 
 			if _, ok := uxHashesMap[in]; !ok {
 				err := fmt.Errorf("HistoryDB.Verify: index of address uxout [%s:%s] does not exist in historydb",
 					addr, in.Hex())
-				return ErrHistoryDBCorrupted{err}
+				return newErrHistoryDBCorrupted(AddressUxBkt, addr.String(), err)
 			}
 		}
 
@@ -352,7 +459,7 @@ func (hd HistoryDB) Verify(tx *dbutil.Tx, b *coin.SignedBlock, indexesMap *Index
 
 			if out == nil {
 				err := fmt.Errorf("HistoryDB.Verify: transaction output %s does not exist in historydb", uxHash.Hex())
-				return ErrHistoryDBCorrupted{err}
+				return newErrHistoryDBCorrupted(UxOutsBkt, uxHash.Hex(), err)
 			}
 
 			addr := ux.Body.Address
@@ -388,7 +495,7 @@ func (hd HistoryDB) Verify(tx *dbutil.Tx, b *coin.SignedBlock, indexesMap *Index
 			if _, ok := txnHashesMap[txnHash]; !ok {
 				err := fmt.Errorf("HistoryDB.Verify: index of address transaction [%s:%s] does not exist in historydb",
 					addr, txnHash.Hex())
-				return ErrHistoryDBCorrupted{err}
+				return newErrHistoryDBCorrupted(AddressTxnsBkt, addr.String(), err)
 			}
 		}
 	}
@@ -398,10 +505,22 @@ func (hd HistoryDB) Verify(tx *dbutil.Tx, b *coin.SignedBlock, indexesMap *Index
 // ErrHistoryDBCorrupted is returned when found the historydb is corrupted
 type ErrHistoryDBCorrupted struct {
 	error
+	// Bucket is the name of the bucket of the missing or invalid entry, empty if unknown
+	Bucket string
+	// Key is the hex hash or the address of the missing or invalid entry, empty if unknown
+	Key string
 }
 
 // NewErrHistoryDBCorrupted is for user to be able to create ErrHistoryDBCorrupted instance
 // outside of the package
 func NewErrHistoryDBCorrupted(err error) ErrHistoryDBCorrupted {
-	return ErrHistoryDBCorrupted{err}
+	return ErrHistoryDBCorrupted{error: err}
+}
+
+func newErrHistoryDBCorrupted(bkt []byte, key string, err error) ErrHistoryDBCorrupted {
+	return ErrHistoryDBCorrupted{
+		error:  err,
+		Bucket: string(bkt),
+		Key:    key,
+	}
 }
@@ -353,6 +353,101 @@ func TestProcessBlock(t *testing.T) {
 	testEngine(t, testData, bc, hisDB, db)
 }
 
+func TestPruneBlock(t *testing.T) {
+	db, teardown := prepareDB(t)
+	defer teardown()
+	bc := newBlockchain()
+	gb := bc.CreateGenesisBlock(genAddress, genCoins, genTime)
+	hisDB := New()
+
+	// The first block spends the genesis output, the second block spends the output of the first block
+	addr := "222uMeCeL1PbkJGZJDgAz5sib2uisv9hYUm"
+	toAddr := "2RxP5N26GhDqHrP6SK45ZzEMSmSpeUeWxsS"
+	b1, txn1, err := addBlock(bc, testData{
+		PreBlockHash: gb.HashHeader(),
+		Vin: txIn{
+			SigKey:   genSecret.Hex(),
+			Addr:     genAddress.String(),
+			TxID:     gb.Body.Transactions[0].Hash(),
+			BlockSeq: 0,
+		},
+		Vouts: []txOut{
+			{
+				ToAddr: addr,
+				Coins:  genCoins,
+				Hours:  100,
+			},
+		},
+	}, incTime)
+	require.NoError(t, err)
+
+	b2, txn2, err := addBlock(bc, testData{
+		PreBlockHash: b1.HashHeader(),
+		Vin: txIn{
+			SigKey:   "62f4d675d991c41a2819d908a4fcf4ba44ff0c31564039e80508c9d68197f90c",
+			Addr:     addr,
+			TxID:     txn1.Hash(),
+			BlockSeq: 1,
+		},
+		Vouts: []txOut{
+			{
+				ToAddr: toAddr,
+				Coins:  genCoins,
+				Hours:  10,
+			},
+		},
+	}, incTime*2)
+	require.NoError(t, err)
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		require.NoError(t, hisDB.ParseBlock(tx, gb))
+		require.NoError(t, hisDB.ParseBlock(tx, *b1))
+		require.NoError(t, hisDB.ParseBlock(tx, *b2))
+		require.NoError(t, hisDB.PruneBlock(tx, *b1))
+		return hisDB.PruneBlock(tx, *b2)
+	})
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		// The transactions of the pruned blocks and the outputs they spent are removed
+		for _, txn := range []*coin.Transaction{txn1, txn2} {
+			pruned, err := hisDB.GetTransaction(tx, txn.Hash())
+			require.NoError(t, err)
+			require.Nil(t, pruned)
+		}
+
+		_, err = hisDB.GetUxOuts(tx, txn2.In)
+		require.Equal(t, NewErrUxOutNotExist(txn2.In[0].Hex()), err)
+
+		// The outputs of the genesis block are kept
+		outs, err := hisDB.GetUxOuts(tx, txn1.In)
+		require.NoError(t, err)
+		require.Equal(t, txn1.Hash(), outs[0].SpentTxnID)
+
+		// The unspent output created by the pruned block is kept
+		ux, err := getUx(bc, 2, txn2.Hash(), toAddr)
+		require.NoError(t, err)
+		outs, err = hisDB.GetUxOuts(tx, []cipher.SHA256{ux.Hash()})
+		require.NoError(t, err)
+		require.Equal(t, *ux, outs[0].Out)
+
+		// The transactions of other blocks are kept
+		g, err := hisDB.GetTransaction(tx, gb.Body.Transactions[0].Hash())
+		require.NoError(t, err)
+		require.NotNil(t, g)
+
+		// The address indexes are kept
+		hashes, err := hisDB.GetTransactionHashesForAddresses(tx, []cipher.Address{cipher.MustDecodeBase58Address(addr)})
+		require.NoError(t, err)
+		require.Equal(t, []cipher.SHA256{txn1.Hash(), txn2.Hash()}, hashes)
+
+		_, err = hisDB.GetOutputsForAddress(tx, cipher.MustDecodeBase58Address(addr))
+		require.Equal(t, NewErrUxOutNotExist(txn2.In[0].Hex()), err)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
 func testEngine(t *testing.T, tds []testData, bc *fakeBlockchain, hdb *HistoryDB, db *dbutil.DB) {
 	for i, td := range tds {
 		b, txn, err := addBlock(bc, td, incTime*(uint64(i)+1))
@@ -74,6 +74,11 @@ func (ux *uxOuts) get(tx *dbutil.Tx, uxID cipher.SHA256) (*UxOut, error) {
 	return &out, nil
 }
 
+// delete removes the UxOut of the given id
+func (ux *uxOuts) delete(tx *dbutil.Tx, uxID cipher.SHA256) error {
+	return dbutil.Delete(tx, UxOutsBkt, uxID[:])
+}
+
 // getArray returns uxOuts for a set of uxids, will return error if any of the uxids do not exist
 func (ux *uxOuts) getArray(tx *dbutil.Tx, uxIDs []cipher.SHA256) ([]UxOut, error) {
 	var outs []UxOut
@@ -61,6 +61,11 @@ func (txs *transactions) get(tx *dbutil.Tx, hash cipher.SHA256) (*Transaction, e
 	return &txn, nil
 }
 
+// delete removes the transaction of the given hash
+func (txs *transactions) delete(tx *dbutil.Tx, hash cipher.SHA256) error {
+	return dbutil.Delete(tx, TransactionsBkt, hash[:])
+}
+
 // getArray returns transactions slice of given hashes
 func (txs *transactions) getArray(tx *dbutil.Tx, hashes []cipher.SHA256) ([]Transaction, error) {
 	txns := make([]Transaction, 0, len(hashes))
@@ -17,14 +17,19 @@ import (
 type Historyer interface {
 	GetUxOuts(tx *dbutil.Tx, uxids []cipher.SHA256) ([]historydb.UxOut, error)
 	ParseBlock(tx *dbutil.Tx, b coin.Block) error
+	PruneBlock(tx *dbutil.Tx, b coin.Block) error
 	GetTransaction(tx *dbutil.Tx, hash cipher.SHA256) (*historydb.Transaction, error)
 	GetOutputsForAddress(tx *dbutil.Tx, address cipher.Address) ([]historydb.UxOut, error)
 	GetTransactionHashesForAddresses(tx *dbutil.Tx, addresses []cipher.Address) ([]cipher.SHA256, error)
 	AddressSeen(tx *dbutil.Tx, address cipher.Address) (bool, error)
+	AddressTransactionCount(tx *dbutil.Tx, address cipher.Address) (uint64, error)
 	NeedsReset(tx *dbutil.Tx) (bool, error)
 	Erase(tx *dbutil.Tx) error
 	ParsedBlockSeq(tx *dbutil.Tx) (uint64, bool, error)
 	ForEachTxn(tx *dbutil.Tx, f func(cipher.SHA256, *historydb.Transaction) error) error
+	GetAddressBalanceAt(tx *dbutil.Tx, addr cipher.Address, seq, blockTime uint64) (historydb.AddressBalance, error)
+	GetAddressBalanceSnapshot(tx *dbutil.Tx, addr cipher.Address, seq uint64) (*historydb.AddressBalance, error)
+	SetAddressBalanceSnapshot(tx *dbutil.Tx, addr cipher.Address, seq uint64, b historydb.AddressBalance) error
 }
 
 // Blockchainer is the interface that provides methods for accessing the blockchain data
@@ -33,12 +38,16 @@ type Blockchainer interface {
 	GetBlocks(tx *dbutil.Tx, seqs []uint64) ([]coin.SignedBlock, error)
 	GetBlocksInRange(tx *dbutil.Tx, start, end uint64) ([]coin.SignedBlock, error)
 	GetLastBlocks(tx *dbutil.Tx, n uint64) ([]coin.SignedBlock, error)
+	ForEachSignedBlock(tx *dbutil.Tx, f func(b *coin.SignedBlock) error) error
+	Prefetch(start, end uint64)
 	GetSignedBlockByHash(tx *dbutil.Tx, hash cipher.SHA256) (*coin.SignedBlock, error)
 	GetSignedBlockBySeq(tx *dbutil.Tx, seq uint64) (*coin.SignedBlock, error)
 	Unspent() blockdb.UnspentPooler
 	Len(tx *dbutil.Tx) (uint64, error)
 	Head(tx *dbutil.Tx) (*coin.SignedBlock, error)
 	HeadSeq(tx *dbutil.Tx) (uint64, bool, error)
+	PrunedSeq(tx *dbutil.Tx) (uint64, bool, error)
+	PruneBlocks(tx *dbutil.Tx, seq uint64) error
 	Time(tx *dbutil.Tx) (uint64, error)
 	NewBlock(tx *dbutil.Tx, txns coin.Transactions, currentTime uint64) (*coin.Block, error)
 	ExecuteBlock(tx *dbutil.Tx, sb *coin.SignedBlock) error
@@ -68,4 +77,5 @@ type UnconfirmedTransactionPooler interface {
 	ForEach(tx *dbutil.Tx, f func(cipher.SHA256, UnconfirmedTransaction) error) error
 	GetUnspentsOfAddr(tx *dbutil.Tx, addr cipher.Address) (coin.UxArray, error)
 	Len(tx *dbutil.Tx) (uint64, error)
+	Evictions() uint64
 }
@@ -0,0 +1,124 @@
+package visor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// maxMempoolDumpFrameSize is the maximum size of an encoded transaction in a mempool dump file
+const maxMempoolDumpFrameSize = 32 * 1024 * 1024
+
+// DumpUnconfirmed writes all transactions of the unconfirmed pool to w.
+// Each transaction is written as a frame of a 4 byte little endian length followed by the raw transaction.
+// Returns the number of transactions written.
+func (vs *Visor) DumpUnconfirmed(w io.Writer) (int, error) {
+	var txns coin.Transactions
+	if err := vs.db.View("DumpUnconfirmed", func(tx *dbutil.Tx) error {
+		var err error
+		txns, err = vs.unconfirmed.AllRawTransactions(tx)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	bw := bufio.NewWriter(w)
+	for i := range txns {
+		if err := writeMempoolDumpFrame(bw, &txns[i]); err != nil {
+			return i, err
+		}
+	}
+
+	return len(txns), bw.Flush()
+}
+
+func writeMempoolDumpFrame(w io.Writer, txn *coin.Transaction) error {
+	buf, err := txn.Serialize()
+	if err != nil {
+		return err
+	}
+
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(buf)))
+
+	if _, err := w.Write(length[:]); err != nil {
+		return err
+	}
+
+	_, err = w.Write(buf)
+	return err
+}
+
+// readMempoolDumpFrame reads a transaction written by writeMempoolDumpFrame. Returns io.EOF if there are no more transactions.
+func readMempoolDumpFrame(r io.Reader) (*coin.Transaction, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, errors.New("truncated transaction frame length")
+		}
+		return nil, err
+	}
+
+	size := binary.LittleEndian.Uint32(length[:])
+	if size > maxMempoolDumpFrameSize {
+		return nil, fmt.Errorf("transaction frame size %d exceeds the maximum of %d", size, maxMempoolDumpFrameSize)
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errors.New("truncated transaction frame")
+		}
+		return nil, err
+	}
+
+	txn, err := coin.DeserializeTransaction(buf)
+	if err != nil {
+		return nil, fmt.Errorf("decode transaction failed: %v", err)
+	}
+
+	return &txn, nil
+}
+
+// RestoreUnconfirmed reads transactions written by DumpUnconfirmed from r and adds them back to the unconfirmed pool.
+// Each transaction is validated like a transaction received from a peer. Transactions that are invalid,
+// for example because they were confirmed or their inputs were spent while the node was stopped, are skipped.
+// Transactions are also skipped if the pool is full.
+// Returns the number of transactions added and the number of transactions skipped.
+// Transactions that are already in the pool are not counted.
+func (vs *Visor) RestoreUnconfirmed(r io.Reader) (int, int, error) {
+	br := bufio.NewReader(r)
+
+	var restored, invalid int
+	for i := 0; ; i++ {
+		txn, err := readMempoolDumpFrame(br)
+		if err == io.EOF {
+			return restored, invalid, nil
+		} else if err != nil {
+			return restored, invalid, fmt.Errorf("read transaction %d failed: %v", i, err)
+		}
+
+		known, softErr, err := vs.InjectForeignTransaction(*txn)
+		if err != nil {
+			if _, ok := err.(ErrTxnViolatesHardConstraint); !ok && err != ErrUnconfirmedPoolFull {
+				return restored, invalid, err
+			}
+			logger.WithError(err).WithField("txid", txn.Hash().Hex()).Info("RestoreUnconfirmed: skipping transaction")
+			invalid++
+			continue
+		}
+
+		if softErr != nil {
+			logger.WithError(softErr).WithField("txid", txn.Hash().Hex()).Info("RestoreUnconfirmed: transaction violates soft constraints")
+		}
+
+		if !known {
+			restored++
+		}
+	}
+}
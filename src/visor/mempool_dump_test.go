@@ -0,0 +1,123 @@
+package visor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func requireUnconfirmedHashes(t *testing.T, v *Visor, hashes []cipher.SHA256) {
+	err := v.db.View("", func(tx *dbutil.Tx) error {
+		txns, err := v.unconfirmed.AllRawTransactions(tx)
+		require.NoError(t, err)
+		require.Len(t, txns, len(hashes))
+		for _, h := range hashes {
+			found := false
+			for _, txn := range txns {
+				if txn.Hash() == h {
+					found = true
+				}
+			}
+			require.True(t, found)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestDumpRestoreUnconfirmed(t *testing.T) {
+	src, shutdown := newChainExportTestVisor(t)
+	defer shutdown()
+
+	gb := addGenesisBlockToVisor(t, src)
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+
+	// Dump an empty pool
+	var buf bytes.Buffer
+	n, err := src.DumpUnconfirmed(&buf)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+	require.Empty(t, buf.Bytes())
+
+	txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, 10e6)
+	_, softErr, err := src.InjectForeignTransaction(txn)
+	require.NoError(t, err)
+	require.Nil(t, softErr)
+
+	n, err = src.DumpUnconfirmed(&buf)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	// Append a transaction spending an output that doesn't exist
+	badTxn := makeSpendTxn(t, coin.CreateUnspents(gb.Head, txn)[:1], []cipher.SecKey{genSecret}, testutil.MakeAddress(), 1e6)
+	require.NoError(t, writeMempoolDumpFrame(&buf, &badTxn))
+	dump := buf.Bytes()
+
+	// Restore into a node with an empty pool
+	dst, shutdown2 := newChainExportTestVisor(t)
+	defer shutdown2()
+	addGenesisBlockToVisor(t, dst)
+
+	restored, invalid, err := dst.RestoreUnconfirmed(bytes.NewReader(dump))
+	require.NoError(t, err)
+	require.Equal(t, 1, restored)
+	require.Equal(t, 1, invalid)
+	requireUnconfirmedHashes(t, dst, []cipher.SHA256{txn.Hash()})
+
+	// Transactions already in the pool are not counted
+	restored, invalid, err = dst.RestoreUnconfirmed(bytes.NewReader(dump))
+	require.NoError(t, err)
+	require.Equal(t, 0, restored)
+	require.Equal(t, 1, invalid)
+
+	// Restore into a node where the genesis output was spent by another transaction
+	other, shutdown3 := newChainExportTestVisor(t)
+	defer shutdown3()
+	addGenesisBlockToVisor(t, other)
+
+	spend := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, testutil.MakeAddress(), 20e6)
+	err = other.db.Update("", func(tx *dbutil.Tx) error {
+		b, err := other.blockchain.NewBlock(tx, coin.Transactions{spend}, genTime+100)
+		require.NoError(t, err)
+
+		return other.executeSignedBlock(tx, coin.SignedBlock{
+			Block: *b,
+			Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+		})
+	})
+	require.NoError(t, err)
+
+	restored, invalid, err = other.RestoreUnconfirmed(bytes.NewReader(dump))
+	require.NoError(t, err)
+	require.Equal(t, 0, restored)
+	require.Equal(t, 2, invalid)
+	requireUnconfirmedHashes(t, other, nil)
+
+	// A truncated dump restores the transactions before the truncated frame
+	last, shutdown4 := newChainExportTestVisor(t)
+	defer shutdown4()
+	addGenesisBlockToVisor(t, last)
+
+	restored, invalid, err = last.RestoreUnconfirmed(bytes.NewReader(dump[:len(dump)-1]))
+	testutil.RequireError(t, err, "read transaction 1 failed: truncated transaction frame")
+	require.Equal(t, 1, restored)
+	require.Equal(t, 0, invalid)
+	requireUnconfirmedHashes(t, last, []cipher.SHA256{txn.Hash()})
+}
+
+func TestReadMempoolDumpFrameTooLarge(t *testing.T) {
+	_, err := readMempoolDumpFrame(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff}))
+	testutil.RequireError(t, err, "transaction frame size 4294967295 exceeds the maximum of 33554432")
+
+	_, err = readMempoolDumpFrame(bytes.NewReader([]byte{0x01, 0x00}))
+	testutil.RequireError(t, err, "truncated transaction frame length")
+
+	_, err = readMempoolDumpFrame(bytes.NewReader([]byte{0x01, 0x00, 0x00, 0x00, 0x00}))
+	require.Error(t, err)
+}
@@ -2,6 +2,7 @@ package visor
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/blang/semver"
 
@@ -13,8 +14,21 @@ var (
 	MetaBkt = []byte("db_meta")
 
 	versionKey = []byte("version")
+
+	restartCountKey   = []byte("restart_count")
+	shutdownReasonKey = []byte("shutdown_reason")
 )
 
+// ShutdownReasonUnclean is the restart reason reported when the previous run did not record a shutdown reason,
+// because it crashed or was killed
+const ShutdownReasonUnclean = "unclean shutdown"
+
+// RestartInfo is the number of times the node has been restarted on this DB, and why it last stopped
+type RestartInfo struct {
+	RestartCount      int
+	LastRestartReason string
+}
+
 // GetDBVersion returns the saved DB version
 func GetDBVersion(db *dbutil.DB) (*semver.Version, error) {
 	var v *semver.Version
@@ -50,22 +64,109 @@ func getDBVersion(tx *dbutil.Tx) (*semver.Version, error) {
 	return &sv, nil
 }
 
-// SetDBVersion sets the DB version
+// SetDBVersion sets the DB version.
+// If the DB already has this version, nothing is written
 func SetDBVersion(db *dbutil.DB, version semver.Version) error {
+	// Check in a View transaction first, since the DB version is mostly unchanged on startup
+	if oldVersion, err := GetDBVersion(db); err != nil {
+		return err
+	} else if oldVersion != nil && oldVersion.EQ(version) {
+		return nil
+	}
+
 	return db.Update("SetDBVersion", func(tx *dbutil.Tx) error {
-		if _, err := tx.CreateBucketIfNotExists(MetaBkt); err != nil {
-			return err
+		return setDBVersion(tx, version)
+	})
+}
+
+func setDBVersion(tx *dbutil.Tx, version semver.Version) error {
+	if _, err := tx.CreateBucketIfNotExists(MetaBkt); err != nil {
+		return err
+	}
+
+	oldVersion, err := getDBVersion(tx)
+	if err != nil {
+		return err
+	}
+
+	if oldVersion != nil && oldVersion.GT(version) {
+		return fmt.Errorf("SetDBVersion cannot regress version from %v to %v", oldVersion, version)
+	}
+
+	return dbutil.PutBucketValue(tx, MetaBkt, versionKey, []byte(version.String()))
+}
+
+// recordStart increments the restart count saved in the DB and returns it with the shutdown reason
+// recorded by the previous run.
+// The shutdown reason is cleared, so that if this run does not record one, the next start reports an unclean shutdown.
+// The first start on a DB has a zero restart count and no reason.
+func recordStart(tx *dbutil.Tx) (*RestartInfo, error) {
+	if _, err := tx.CreateBucketIfNotExists(MetaBkt); err != nil {
+		return nil, err
+	}
+
+	info, err := getRestartInfo(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if info == nil {
+		info = &RestartInfo{}
+	} else {
+		info.RestartCount++
+		if info.LastRestartReason == "" {
+			info.LastRestartReason = ShutdownReasonUnclean
 		}
+	}
 
-		oldVersion, err := getDBVersion(tx)
-		if err != nil {
-			return err
+	if err := dbutil.PutBucketValue(tx, MetaBkt, restartCountKey, []byte(strconv.Itoa(info.RestartCount))); err != nil {
+		return nil, err
+	}
+
+	if err := dbutil.Delete(tx, MetaBkt, shutdownReasonKey); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// getRestartInfo returns the saved restart count and shutdown reason, or nil if the node has not been started on this DB
+func getRestartInfo(tx *dbutil.Tx) (*RestartInfo, error) {
+	v, err := dbutil.GetBucketValue(tx, MetaBkt, restartCountKey)
+	if err != nil {
+		switch err.(type) {
+		case dbutil.ErrBucketNotExist:
+			return nil, nil
+		default:
+			return nil, err
 		}
+	} else if v == nil {
+		return nil, nil
+	}
 
-		if oldVersion != nil && oldVersion.GT(version) {
-			return fmt.Errorf("SetDBVersion cannot regress version from %v to %v", oldVersion, version)
+	n, err := strconv.Atoi(string(v))
+	if err != nil {
+		return nil, fmt.Errorf("invalid restart count %q: %v", v, err)
+	}
+
+	reason, err := dbutil.GetBucketValue(tx, MetaBkt, shutdownReasonKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RestartInfo{
+		RestartCount:      n,
+		LastRestartReason: string(reason),
+	}, nil
+}
+
+// SetShutdownReason saves the reason that the node is shutting down, which is reported as the restart reason on the next start
+func SetShutdownReason(db *dbutil.DB, reason string) error {
+	return db.Update("SetShutdownReason", func(tx *dbutil.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(MetaBkt); err != nil {
+			return err
 		}
 
-		return dbutil.PutBucketValue(tx, MetaBkt, versionKey, []byte(version.String()))
+		return dbutil.PutBucketValue(tx, MetaBkt, shutdownReasonKey, []byte(reason))
 	})
 }
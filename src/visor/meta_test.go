@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
 )
 
 func TestGetSetDBVersion(t *testing.T) {
@@ -43,3 +44,75 @@ func TestGetSetDBVersion(t *testing.T) {
 	err = SetDBVersion(db, x)
 	testutil.RequireError(t, err, "SetDBVersion cannot regress version from 0.26.0 to 0.25.0")
 }
+
+func TestSetDBVersionUnchanged(t *testing.T) {
+	db, shutdown := testutil.PrepareDB(t)
+	defer shutdown()
+
+	// updateTxCount returns the number of Update transactions, which is the total count minus the View count
+	updateTxCount := func() uint64 {
+		s, err := db.Stats()
+		require.NoError(t, err)
+		return s.TxCount - uint64(s.TxN)
+	}
+
+	x := semver.MustParse("0.25.0")
+	err := SetDBVersion(db, x)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), updateTxCount())
+
+	// Setting the same version again does not open an Update transaction
+	err = SetDBVersion(db, x)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), updateTxCount())
+
+	v, err := GetDBVersion(db)
+	require.NoError(t, err)
+	require.Equal(t, "0.25.0", v.String())
+
+	err = SetDBVersion(db, semver.MustParse("0.26.0"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), updateTxCount())
+}
+
+func TestRecordStart(t *testing.T) {
+	db, shutdown := testutil.PrepareDB(t)
+	defer shutdown()
+
+	start := func() *RestartInfo {
+		var info *RestartInfo
+		err := db.Update("", func(tx *dbutil.Tx) error {
+			var err error
+			info, err = recordStart(tx)
+			return err
+		})
+		require.NoError(t, err)
+		return info
+	}
+
+	// No restart info before the first start
+	err := db.View("", func(tx *dbutil.Tx) error {
+		info, err := getRestartInfo(tx)
+		require.NoError(t, err)
+		require.Nil(t, info)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// First start
+	require.Equal(t, &RestartInfo{}, start())
+
+	// Restart after a recorded shutdown
+	err = SetShutdownReason(db, "signal: interrupt")
+	require.NoError(t, err)
+	require.Equal(t, &RestartInfo{
+		RestartCount:      1,
+		LastRestartReason: "signal: interrupt",
+	}, start())
+
+	// Restart without a recorded shutdown
+	require.Equal(t, &RestartInfo{
+		RestartCount:      2,
+		LastRestartReason: ShutdownReasonUnclean,
+	}, start())
+}
@@ -34,6 +34,20 @@ func (_m *MockBlockchainer) ExecuteBlock(tx *dbutil.Tx, sb *coin.SignedBlock) er
 	return r0
 }
 
+// ForEachSignedBlock provides a mock function with given fields: tx, f
+func (_m *MockBlockchainer) ForEachSignedBlock(tx *dbutil.Tx, f func(*coin.SignedBlock) error) error {
+	ret := _m.Called(tx, f)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, func(*coin.SignedBlock) error) error); ok {
+		r0 = rf(tx, f)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetBlocks provides a mock function with given fields: tx, seqs
 func (_m *MockBlockchainer) GetBlocks(tx *dbutil.Tx, seqs []uint64) ([]coin.SignedBlock, error) {
 	ret := _m.Called(tx, seqs)
@@ -267,6 +281,53 @@ func (_m *MockBlockchainer) NewBlock(tx *dbutil.Tx, txns coin.Transactions, curr
 	return r0, r1
 }
 
+// Prefetch provides a mock function with given fields: start, end
+func (_m *MockBlockchainer) Prefetch(start uint64, end uint64) {
+	_m.Called(start, end)
+}
+
+// PruneBlocks provides a mock function with given fields: tx, seq
+func (_m *MockBlockchainer) PruneBlocks(tx *dbutil.Tx, seq uint64) error {
+	ret := _m.Called(tx, seq)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, uint64) error); ok {
+		r0 = rf(tx, seq)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PrunedSeq provides a mock function with given fields: tx
+func (_m *MockBlockchainer) PrunedSeq(tx *dbutil.Tx) (uint64, bool, error) {
+	ret := _m.Called(tx)
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx) uint64); ok {
+		r0 = rf(tx)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx) bool); ok {
+		r1 = rf(tx)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(*dbutil.Tx) error); ok {
+		r2 = rf(tx)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // Time provides a mock function with given fields: tx
 func (_m *MockBlockchainer) Time(tx *dbutil.Tx) (uint64, error) {
 	ret := _m.Called(tx)
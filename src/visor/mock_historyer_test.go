@@ -39,6 +39,27 @@ func (_m *MockHistoryer) AddressSeen(tx *dbutil.Tx, address cipher.Address) (boo
 	return r0, r1
 }
 
+// AddressTransactionCount provides a mock function with given fields: tx, address
+func (_m *MockHistoryer) AddressTransactionCount(tx *dbutil.Tx, address cipher.Address) (uint64, error) {
+	ret := _m.Called(tx, address)
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, cipher.Address) uint64); ok {
+		r0 = rf(tx, address)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx, cipher.Address) error); ok {
+		r1 = rf(tx, address)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Erase provides a mock function with given fields: tx
 func (_m *MockHistoryer) Erase(tx *dbutil.Tx) error {
 	ret := _m.Called(tx)
@@ -67,6 +88,50 @@ func (_m *MockHistoryer) ForEachTxn(tx *dbutil.Tx, f func(cipher.SHA256, *histor
 	return r0
 }
 
+// GetAddressBalanceAt provides a mock function with given fields: tx, addr, seq, blockTime
+func (_m *MockHistoryer) GetAddressBalanceAt(tx *dbutil.Tx, addr cipher.Address, seq uint64, blockTime uint64) (historydb.AddressBalance, error) {
+	ret := _m.Called(tx, addr, seq, blockTime)
+
+	var r0 historydb.AddressBalance
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, cipher.Address, uint64, uint64) historydb.AddressBalance); ok {
+		r0 = rf(tx, addr, seq, blockTime)
+	} else {
+		r0 = ret.Get(0).(historydb.AddressBalance)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx, cipher.Address, uint64, uint64) error); ok {
+		r1 = rf(tx, addr, seq, blockTime)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAddressBalanceSnapshot provides a mock function with given fields: tx, addr, seq
+func (_m *MockHistoryer) GetAddressBalanceSnapshot(tx *dbutil.Tx, addr cipher.Address, seq uint64) (*historydb.AddressBalance, error) {
+	ret := _m.Called(tx, addr, seq)
+
+	var r0 *historydb.AddressBalance
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, cipher.Address, uint64) *historydb.AddressBalance); ok {
+		r0 = rf(tx, addr, seq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*historydb.AddressBalance)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx, cipher.Address, uint64) error); ok {
+		r1 = rf(tx, addr, seq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetOutputsForAddress provides a mock function with given fields: tx, address
 func (_m *MockHistoryer) GetOutputsForAddress(tx *dbutil.Tx, address cipher.Address) ([]historydb.UxOut, error) {
 	ret := _m.Called(tx, address)
@@ -221,3 +286,31 @@ func (_m *MockHistoryer) ParsedBlockSeq(tx *dbutil.Tx) (uint64, bool, error) {
 
 	return r0, r1, r2
 }
+
+// PruneBlock provides a mock function with given fields: tx, b
+func (_m *MockHistoryer) PruneBlock(tx *dbutil.Tx, b coin.Block) error {
+	ret := _m.Called(tx, b)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, coin.Block) error); ok {
+		r0 = rf(tx, b)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetAddressBalanceSnapshot provides a mock function with given fields: tx, addr, seq, b
+func (_m *MockHistoryer) SetAddressBalanceSnapshot(tx *dbutil.Tx, addr cipher.Address, seq uint64, b historydb.AddressBalance) error {
+	ret := _m.Called(tx, addr, seq, b)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, cipher.Address, uint64, historydb.AddressBalance) error); ok {
+		r0 = rf(tx, addr, seq, b)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -41,6 +41,20 @@ func (_m *MockUnconfirmedTransactionPooler) AllRawTransactions(tx *dbutil.Tx) (c
 	return r0, r1
 }
 
+// Evictions provides a mock function with given fields:
+func (_m *MockUnconfirmedTransactionPooler) Evictions() uint64 {
+	ret := _m.Called()
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func() uint64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	return r0
+}
+
 // FilterKnown provides a mock function with given fields: tx, txns
 func (_m *MockUnconfirmedTransactionPooler) FilterKnown(tx *dbutil.Tx, txns []cipher.SHA256) ([]cipher.SHA256, error) {
 	ret := _m.Called(tx, txns)
@@ -0,0 +1,139 @@
+package visor
+
+import (
+	"os"
+	"sort"
+	"unsafe"
+
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// prefetchWindow is the number of blocks that sequential reads of the blockchain prefetch ahead of the block being read
+const prefetchWindow = 1000
+
+// fileRange is a range of bytes of a file
+type fileRange struct {
+	off int64
+	n   int64
+}
+
+// Prefetch asynchronously reads the blocks from seq start to end, inclusive, into the OS page cache,
+// so that reading them in order afterwards does not wait on the disk for every block.
+// It is only a hint: failures are logged, and it does nothing on systems other than Linux.
+func (bc *Blockchain) Prefetch(start, end uint64) {
+	if !prefetchSupported || start > end {
+		return
+	}
+
+	go func() {
+		if err := bc.prefetch(start, end); err != nil {
+			logger.WithError(err).Debugf("Prefetch of blocks %d to %d failed", start, end)
+		}
+	}()
+}
+
+func (bc *Blockchain) prefetch(start, end uint64) error {
+	var ranges []fileRange
+	if err := bc.db.View("Prefetch", func(tx *dbutil.Tx) error {
+		var err error
+		ranges, err = bc.blockFileRanges(tx, start, end)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	// The page cache is shared by all descriptors of the file, so the hint applies to the DB's reads
+	f, err := os.Open(bc.db.Path())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for _, r := range mergeFileRanges(ranges, int64(os.Getpagesize())) {
+		if err := readahead(f, r.off, r.n); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// blockFileRanges returns the ranges of the DB file that hold the blocks from seq start to end, in height order
+func (bc *Blockchain) blockFileRanges(tx *dbutil.Tx, start, end uint64) ([]fileRange, error) {
+	// The values read in a read-only bolt transaction point into the memory map of the DB file,
+	// so their offset from the start of the map is their offset in the file
+	data := tx.DB().Info().Data
+	size := tx.Size()
+
+	var ranges []fileRange
+	if err := bc.store.ForEachRawBlockInRange(tx, start, end, func(v []byte) error {
+		if len(v) == 0 {
+			return nil
+		}
+
+		off := int64(uintptr(unsafe.Pointer(&v[0])) - data)
+		n := int64(len(v))
+		if off < 0 || off+n > size {
+			// Not in the memory map, e.g. written by this transaction
+			return nil
+		}
+
+		ranges = append(ranges, fileRange{
+			off: off,
+			n:   n,
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ranges, nil
+}
+
+// mergeFileRanges sorts the ranges by offset and merges the ranges that overlap or are less than gap bytes apart
+func mergeFileRanges(ranges []fileRange, gap int64) []fileRange {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	sorted := make([]fileRange, len(ranges))
+	copy(sorted, ranges)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].off < sorted[j].off
+	})
+
+	merged := []fileRange{sorted[0]}
+	for _, r := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		lastEnd := last.off + last.n
+		if r.off-lastEnd >= gap {
+			merged = append(merged, r)
+			continue
+		}
+
+		if end := r.off + r.n; end > lastEnd {
+			last.n = end - last.off
+		}
+	}
+
+	return merged
+}
+
+// prefetchAhead is called by sequential reads of the blockchain that started at block start, before reading block seq.
+// At the start of each window of prefetchWindow blocks, it prefetches the following window,
+// so that the blocks are in the page cache by the time they are read.
+func prefetchAhead(bc Blockchainer, start, seq uint64) {
+	if (seq-start)%prefetchWindow != 0 {
+		return
+	}
+
+	if seq == start {
+		bc.Prefetch(seq, seq+prefetchWindow-1)
+	}
+
+	bc.Prefetch(seq+prefetchWindow, seq+2*prefetchWindow-1)
+}
@@ -0,0 +1,14 @@
+package visor
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+const prefetchSupported = true
+
+// readahead asks the kernel to start reading a range of the file into the page cache, without waiting for it
+func readahead(f *os.File, off, n int64) error {
+	return unix.Fadvise(int(f.Fd()), off, n, unix.FADV_WILLNEED)
+}
@@ -0,0 +1,12 @@
+// +build !linux
+
+package visor
+
+import "os"
+
+// Prefetching blocks is only implemented on Linux
+const prefetchSupported = false
+
+func readahead(f *os.File, off, n int64) error {
+	return nil
+}
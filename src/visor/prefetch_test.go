@@ -0,0 +1,129 @@
+package visor
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestBlockFileRanges(t *testing.T) {
+	v, shutdown := newChainExportTestVisor(t)
+	defer shutdown()
+
+	gb := addGenesisBlockToVisor(t, v)
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	for i := 1; i <= 5; i++ {
+		txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, uxs[0].Body.Coins)
+
+		err := v.db.Update("", func(tx *dbutil.Tx) error {
+			b, err := v.blockchain.NewBlock(tx, coin.Transactions{txn}, genTime+uint64(i)*100)
+			require.NoError(t, err)
+
+			sb := coin.SignedBlock{
+				Block: *b,
+				Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+			}
+			if err := v.executeSignedBlock(tx, sb); err != nil {
+				return err
+			}
+
+			uxs = coin.CreateUnspents(b.Head, txn)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	blocks, err := v.GetBlocksInRange(0, 5)
+	require.NoError(t, err)
+
+	bc := v.blockchain.(*Blockchain)
+
+	file, err := ioutil.ReadFile(v.db.Path())
+	require.NoError(t, err)
+
+	// Each range of the file holds the stored data of the block
+	err = v.db.View("", func(tx *dbutil.Tx) error {
+		ranges, err := bc.blockFileRanges(tx, 2, 10)
+		require.NoError(t, err)
+		require.Len(t, ranges, 4)
+
+		for i, r := range ranges {
+			hash := blocks[2+i].HashHeader()
+			data, err := dbutil.GetBucketValue(tx, blockdb.BlocksBkt, hash[:])
+			require.NoError(t, err)
+			require.Equal(t, data, file[r.off:r.off+r.n])
+		}
+
+		ranges, err = bc.blockFileRanges(tx, 6, 10)
+		require.NoError(t, err)
+		require.Empty(t, ranges)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, bc.prefetch(0, 5))
+}
+
+func TestMergeFileRanges(t *testing.T) {
+	cases := []struct {
+		name   string
+		ranges []fileRange
+		merged []fileRange
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:   "one range",
+			ranges: []fileRange{{off: 10, n: 5}},
+			merged: []fileRange{{off: 10, n: 5}},
+		},
+		{
+			name:   "unsorted ranges that are far apart",
+			ranges: []fileRange{{off: 300, n: 10}, {off: 10, n: 5}, {off: 150, n: 20}},
+			merged: []fileRange{{off: 10, n: 5}, {off: 150, n: 20}, {off: 300, n: 10}},
+		},
+		{
+			name:   "ranges closer than the gap",
+			ranges: []fileRange{{off: 10, n: 5}, {off: 40, n: 10}, {off: 150, n: 1}},
+			merged: []fileRange{{off: 10, n: 40}, {off: 150, n: 1}},
+		},
+		{
+			name:   "overlapping and contained ranges",
+			ranges: []fileRange{{off: 10, n: 50}, {off: 20, n: 10}, {off: 55, n: 10}},
+			merged: []fileRange{{off: 10, n: 55}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.merged, mergeFileRanges(tc.ranges, 100))
+		})
+	}
+}
+
+func TestPrefetchAhead(t *testing.T) {
+	bc := &MockBlockchainer{}
+
+	// The first read prefetches the first two windows
+	bc.On("Prefetch", uint64(5), uint64(5+prefetchWindow-1)).Return().Once()
+	bc.On("Prefetch", uint64(5+prefetchWindow), uint64(5+2*prefetchWindow-1)).Return().Once()
+	prefetchAhead(bc, 5, 5)
+
+	// The reads inside a window prefetch nothing
+	prefetchAhead(bc, 5, 6)
+	prefetchAhead(bc, 5, 5+prefetchWindow-1)
+
+	// The first read of each window prefetches the following window
+	bc.On("Prefetch", uint64(5+2*prefetchWindow), uint64(5+3*prefetchWindow-1)).Return().Once()
+	prefetchAhead(bc, 5, 5+prefetchWindow)
+
+	bc.AssertExpectations(t)
+}
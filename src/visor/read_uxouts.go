@@ -0,0 +1,71 @@
+package visor
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+)
+
+// ReadUxOuts returns the outputs with the given hashes and the head block, read directly from db.
+// Unspent outputs are read from the unspent pool, spent outputs from the history, if the node has built it.
+// The output is nil for hashes that are not found.
+// Like ReadUnspentsOfAddrs, it can be used with a database opened read-only while the node is stopped.
+func ReadUxOuts(db *dbutil.DB, hashes []cipher.SHA256) ([]*coin.UxOut, *coin.SignedBlock, error) {
+	bc, err := NewBlockchain(db, BlockchainConfig{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	history := historydb.New()
+
+	uxOuts := make([]*coin.UxOut, len(hashes))
+	var head *coin.SignedBlock
+	if err := db.View("ReadUxOuts", func(tx *dbutil.Tx) error {
+		// A new database has no blocks
+		if !dbutil.Exists(tx, blockdb.BlocksBkt) {
+			return nil
+		}
+
+		if _, ok, err := bc.HeadSeq(tx); err != nil {
+			return err
+		} else if !ok {
+			return nil
+		}
+
+		var err error
+		head, err = bc.Head(tx)
+		if err != nil {
+			return err
+		}
+
+		hasHistory := dbutil.Exists(tx, historydb.UxOutsBkt)
+
+		for i, h := range hashes {
+			ux, err := bc.Unspent().Get(tx, h)
+			if err != nil {
+				return err
+			}
+
+			if ux == nil && hasHistory {
+				outs, err := history.GetUxOuts(tx, []cipher.SHA256{h})
+				switch err.(type) {
+				case nil:
+					ux = &outs[0].Out
+				case historydb.ErrUxOutNotExist:
+				default:
+					return err
+				}
+			}
+
+			uxOuts[i] = ux
+		}
+
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	return uxOuts, head, nil
+}
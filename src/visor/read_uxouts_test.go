@@ -0,0 +1,57 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestReadUxOuts(t *testing.T) {
+	v, shutdown := newChainExportTestVisor(t)
+	defer shutdown()
+
+	unknown := testutil.RandSHA256(t)
+
+	// An empty blockchain has no outputs
+	uxOuts, head, err := ReadUxOuts(v.db, []cipher.SHA256{unknown})
+	require.NoError(t, err)
+	require.Nil(t, head)
+	require.Equal(t, []*coin.UxOut{nil}, uxOuts)
+
+	gb := addGenesisBlockToVisor(t, v)
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+
+	uxOuts, head, err = ReadUxOuts(v.db, []cipher.SHA256{uxs[0].Hash(), unknown})
+	require.NoError(t, err)
+	require.Equal(t, gb.HashHeader(), head.HashHeader())
+	require.Equal(t, []*coin.UxOut{&uxs[0], nil}, uxOuts)
+
+	// Spend the genesis output
+	addr := testutil.MakeAddress()
+	txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, addr, 100e6)
+	err = v.db.Update("", func(tx *dbutil.Tx) error {
+		b, err := v.blockchain.NewBlock(tx, coin.Transactions{txn}, genTime+100)
+		require.NoError(t, err)
+
+		return v.executeSignedBlock(tx, coin.SignedBlock{
+			Block: *b,
+			Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+		})
+	})
+	require.NoError(t, err)
+
+	// The spent output is read from the history, the new one from the unspent pool
+	newUxID := txn.Out[0].UxID(txn.Hash())
+	uxOuts, head, err = ReadUxOuts(v.db, []cipher.SHA256{uxs[0].Hash(), newUxID})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), head.Seq())
+	require.Len(t, uxOuts, 2)
+	require.Equal(t, uxs[0], *uxOuts[0])
+	require.Equal(t, newUxID, uxOuts[1].Hash())
+	require.Equal(t, addr, uxOuts[1].Body.Address)
+}
@@ -3,6 +3,7 @@ package visor
 import (
 	"bytes"
 	"sort"
+	"sync"
 
 	"github.com/skycoin/skycoin/src/cipher"
 )
@@ -17,6 +18,13 @@ type RichlistBalance struct {
 // Richlist contains RichlistBalances
 type Richlist []RichlistBalance
 
+// richlistCache holds the richlist of a block, since computing it requires reading all unspent outputs
+type richlistCache struct {
+	sync.Mutex
+	headSeq  uint64
+	richlist Richlist
+}
+
 // NewRichlist create Richlist via unspent outputs map
 func NewRichlist(allAccounts map[cipher.Address]uint64, lockedAddrs map[cipher.Address]struct{}) (Richlist, error) {
 	richlist := make(Richlist, 0, len(allAccounts))
@@ -6,6 +6,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
 )
 
 func getLockedMap(distributionAddresses [4]cipher.Address) map[cipher.Address]struct{} {
@@ -122,3 +125,77 @@ func TestRichlist(t *testing.T) {
 		})
 	}
 }
+
+func TestVisorGetRichlist(t *testing.T) {
+	v, shutdown := newChainExportTestVisor(t)
+	defer shutdown()
+
+	// A blockchain without blocks has an empty richlist
+	richlist, err := v.GetRichlist(true)
+	require.NoError(t, err)
+	require.Empty(t, richlist)
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	richlist, err = v.GetRichlist(true)
+	require.NoError(t, err)
+	require.Equal(t, Richlist{
+		{
+			Address: genAddress,
+			Coins:   genCoins,
+		},
+	}, richlist)
+
+	// The richlist is cached until the head block changes
+	v.richlistCache.richlist = Richlist{{Address: genAddress, Coins: 1}}
+	richlist, err = v.GetRichlist(true)
+	require.NoError(t, err)
+	require.Equal(t, Richlist{{Address: genAddress, Coins: 1}}, richlist)
+
+	// Send coins to a locked distribution address in a new block
+	lockedAddr := params.MainNetDistribution.LockedAddressesDecoded()[0]
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, lockedAddr, 100e6)
+	err = v.db.Update("", func(tx *dbutil.Tx) error {
+		b, err := v.blockchain.NewBlock(tx, coin.Transactions{txn}, genTime+100)
+		require.NoError(t, err)
+
+		return v.executeSignedBlock(tx, coin.SignedBlock{
+			Block: *b,
+			Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+		})
+	})
+	require.NoError(t, err)
+
+	richlist, err = v.GetRichlist(true)
+	require.NoError(t, err)
+	require.Equal(t, Richlist{
+		{
+			Address: genAddress,
+			Coins:   genCoins - 100e6,
+		},
+		{
+			Address: lockedAddr,
+			Coins:   100e6,
+			Locked:  true,
+		},
+	}, richlist)
+
+	// The distribution addresses are filtered out
+	richlist, err = v.GetRichlist(false)
+	require.NoError(t, err)
+	require.Equal(t, Richlist{
+		{
+			Address: genAddress,
+			Coins:   genCoins - 100e6,
+		},
+	}, richlist)
+
+	// Modifying the returned richlist doesn't modify the cache
+	richlist, err = v.GetRichlist(true)
+	require.NoError(t, err)
+	richlist[0].Coins = 0
+	richlist, err = v.GetRichlist(true)
+	require.NoError(t, err)
+	require.Equal(t, genCoins-100e6, richlist[0].Coins)
+}
@@ -0,0 +1,127 @@
+package visor
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/consensus"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// signalingCache holds the active signaling deployments of a round, since computing them requires reading the blocks of the window
+type signalingCache struct {
+	sync.Mutex
+	ok         bool
+	roundStart uint64
+	active     []consensus.SignalingDeployment
+}
+
+// activeDeployments returns the signaling deployments active for the block at seq.
+// The result is cached until a block of the next round is requested.
+func (vs *Visor) activeDeployments(tx *dbutil.Tx, seq uint64) ([]consensus.SignalingDeployment, error) {
+	cfg := vs.Config.Signaling
+	if len(cfg.Deployments) == 0 {
+		return nil, nil
+	}
+
+	cache := vs.signalingCache
+	cache.Lock()
+	defer cache.Unlock()
+
+	roundStart := cfg.RoundStart(seq)
+	if cache.ok && cache.roundStart == roundStart {
+		return cache.active, nil
+	}
+
+	var fields []uint32
+	if start, end := cfg.WindowRange(seq); end > start {
+		blocks, err := vs.blockchain.GetBlocksInRange(tx, start, end-1)
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(blocks)) != end-start {
+			return nil, fmt.Errorf("signaling window blocks %d to %d not found", start, end-1)
+		}
+
+		fields = make([]uint32, len(blocks))
+		for i, b := range blocks {
+			fields[i] = b.Head.Version
+		}
+	}
+
+	active := cfg.ActiveDeployments(fields)
+
+	for _, d := range active {
+		if !cache.ok || !signalingDeploymentIn(d, cache.active) {
+			logger.Infof("Signaling deployment on bit %d is active from block %d: %s=%d", d.Bit, roundStart, d.Parameter, d.Value)
+		}
+	}
+
+	cache.ok = true
+	cache.roundStart = roundStart
+	cache.active = active
+
+	return active, nil
+}
+
+func signalingDeploymentIn(d consensus.SignalingDeployment, deployments []consensus.SignalingDeployment) bool {
+	for _, x := range deployments {
+		if x == d {
+			return true
+		}
+	}
+	return false
+}
+
+// createBlockParams returns the transaction verification parameters and the maximum block size
+// used to create the block at seq, with the active signaling deployments applied
+func (vs *Visor) createBlockParams(tx *dbutil.Tx, seq uint64) (params.VerifyTxn, uint32, error) {
+	verifyParams := vs.Config.CreateBlockVerifyTxn
+	maxBlockSize := vs.Config.MaxBlockTransactionsSize
+
+	active, err := vs.activeDeployments(tx, seq)
+	if err != nil {
+		return params.VerifyTxn{}, 0, err
+	}
+
+	for _, d := range active {
+		switch d.Parameter {
+		case consensus.SignalingMaxBlockSize:
+			maxBlockSize = uint32(d.Value)
+		case consensus.SignalingMinFeePerByte:
+			verifyParams.MinFeePerByte = d.Value
+		case consensus.SignalingBurnFactor:
+			verifyParams.BurnFactor = uint32(d.Value)
+		}
+	}
+
+	return verifyParams, maxBlockSize, nil
+}
+
+// verifySignalingConfig checks that the values of the signaling deployments are valid block creation parameters
+func verifySignalingConfig(c Config) error {
+	if err := c.Signaling.Verify(); err != nil {
+		return err
+	}
+
+	for _, d := range c.Signaling.Deployments {
+		switch d.Parameter {
+		case consensus.SignalingMaxBlockSize:
+			if d.Value > math.MaxUint32 || uint32(d.Value) < c.CreateBlockVerifyTxn.MaxTransactionSize {
+				return fmt.Errorf("signaling %s must be >= CreateBlockVerifyTxn.MaxTransactionSize (%d) and fit in 32 bits", d.Parameter, c.CreateBlockVerifyTxn.MaxTransactionSize)
+			}
+		case consensus.SignalingMinFeePerByte:
+			if d.Value < params.UserVerifyTxn.MinFeePerByte {
+				return fmt.Errorf("signaling %s must be >= params.UserVerifyTxn.MinFeePerByte (%d)", d.Parameter, params.UserVerifyTxn.MinFeePerByte)
+			}
+		case consensus.SignalingBurnFactor:
+			if d.Value > math.MaxUint32 || uint32(d.Value) < params.UserVerifyTxn.BurnFactor {
+				return fmt.Errorf("signaling %s must be >= params.UserVerifyTxn.BurnFactor (%d) and fit in 32 bits", d.Parameter, params.UserVerifyTxn.BurnFactor)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/consensus"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestCreateBlockSignaling(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	blockSize := consensus.SignalingDeployment{
+		Bit:       1,
+		Parameter: consensus.SignalingMaxBlockSize,
+		Value:     65536,
+	}
+	burnFactor := consensus.SignalingDeployment{
+		Bit:       2,
+		Parameter: consensus.SignalingBurnFactor,
+		Value:     20,
+	}
+
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = genSecret
+	cfg.GenesisAddress = genAddress
+	cfg.Distribution = params.MainNetDistribution
+	cfg.Signaling = consensus.SignalingConfig{
+		SignalingWindow:       2,
+		MinSignalingThreshold: 100,
+		RoundLength:           2,
+		Deployments:           []consensus.SignalingDeployment{blockSize, burnFactor},
+	}
+	cfg.SignalingField = consensus.SignalBit(blockSize.Bit)
+
+	v, err := New(cfg, db, nil)
+	require.NoError(t, err)
+
+	gb := addGenesisBlockToVisor(t, v)
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+
+	requireParams := func(seq uint64, maxBlockSize, burnFactor uint32) {
+		err := db.View("", func(tx *dbutil.Tx) error {
+			verifyParams, size, err := v.createBlockParams(tx, seq)
+			require.NoError(t, err)
+			require.Equal(t, maxBlockSize, size)
+			require.Equal(t, burnFactor, verifyParams.BurnFactor)
+			require.Equal(t, cfg.CreateBlockVerifyTxn.MaxTransactionSize, verifyParams.MaxTransactionSize)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	defaultSize := cfg.MaxBlockTransactionsSize
+	defaultBurnFactor := cfg.CreateBlockVerifyTxn.BurnFactor
+
+	// The blocks of the first round have no window
+	requireParams(1, defaultSize, defaultBurnFactor)
+
+	// Create blocks signaling the block size deployment
+	for i := 1; i <= 3; i++ {
+		txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, uxs[0].Body.Coins)
+
+		err := db.Update("", func(tx *dbutil.Tx) error {
+			b, err := v.createBlockFromTxns(tx, coin.Transactions{txn}, genTime+uint64(i)*100)
+			require.NoError(t, err)
+			require.Equal(t, cfg.SignalingField, b.Head.Version)
+
+			if err := v.executeSignedBlock(tx, v.signBlock(b)); err != nil {
+				return err
+			}
+
+			uxs = coin.CreateUnspents(b.Head, txn)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	// The window of the second round has the genesis block, which doesn't signal
+	requireParams(2, defaultSize, defaultBurnFactor)
+	requireParams(3, defaultSize, defaultBurnFactor)
+
+	// All blocks of the window of the third round signal the block size deployment
+	requireParams(4, uint32(blockSize.Value), defaultBurnFactor)
+	requireParams(5, uint32(blockSize.Value), defaultBurnFactor)
+}
+
+func TestVerifySignalingConfig(t *testing.T) {
+	cfg := NewConfig()
+	cfg.Distribution = params.MainNetDistribution
+	require.NoError(t, cfg.Verify())
+
+	cfg.Signaling.Deployments = []consensus.SignalingDeployment{
+		{Bit: 0, Parameter: consensus.SignalingMaxBlockSize, Value: uint64(cfg.CreateBlockVerifyTxn.MaxTransactionSize) - 1},
+	}
+	require.Error(t, cfg.Verify())
+
+	cfg.Signaling.Deployments = []consensus.SignalingDeployment{
+		{Bit: 0, Parameter: consensus.SignalingBurnFactor, Value: uint64(params.UserVerifyTxn.BurnFactor) - 1},
+	}
+	require.Error(t, cfg.Verify())
+
+	cfg.Signaling.Deployments = []consensus.SignalingDeployment{
+		{Bit: 0, Parameter: consensus.SignalingMaxBlockSize, Value: 1 << 32},
+	}
+	require.Error(t, cfg.Verify())
+
+	cfg.Signaling.Deployments = []consensus.SignalingDeployment{
+		{Bit: 0, Parameter: consensus.SignalingMaxBlockSize, Value: 65536},
+		{Bit: 1, Parameter: consensus.SignalingBurnFactor, Value: 20},
+		{Bit: 2, Parameter: consensus.SignalingMinFeePerByte, Value: 1},
+	}
+	require.NoError(t, cfg.Verify())
+
+	cfg.Signaling.RoundLength = 0
+	require.Equal(t, consensus.ErrSignalingRoundLengthZero, cfg.Verify())
+}
@@ -333,6 +333,11 @@ func (ct confirmedTxnsGetter) getTransaction(tx *dbutil.Tx, hash cipher.SHA256)
 		return nil, err
 	}
 
+	// The transaction is in the address indexes but was removed with a pruned block
+	if hisTxn == nil {
+		return nil, checkHistoryPruned(tx, ct.blockchain, fmt.Errorf("transaction %s not found in historydb", hash.Hex()))
+	}
+
 	return ct.convertConfirmedTxn(tx, hisTxn)
 }
 
@@ -406,6 +411,10 @@ func (ct confirmedTxnsGetter) getTxnsHashes(tx *dbutil.Tx, addrs []cipher.Addres
 			return nil, err
 		}
 
+		if hisTxn == nil {
+			return nil, checkHistoryPruned(tx, ct.blockchain, fmt.Errorf("transaction %s not found in historydb", hash.Hex()))
+		}
+
 		hashCon.Add(hash, true, hisTxn.BlockSeq)
 	}
 
@@ -3,11 +3,14 @@ package visor
 import (
 	"errors"
 	"fmt"
+	"math"
+	"sync/atomic"
 	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/util/mathutil"
 	"github.com/skycoin/skycoin/src/visor/dbutil"
 )
 
@@ -18,6 +21,15 @@ var (
 	UnconfirmedUnspentsBkt = []byte("unconfirmed_unspents")
 
 	errUpdateObjectDoesNotExist = errors.New("object does not exist in bucket")
+
+	// ErrUnconfirmedPoolFull is returned by InjectTransaction if the pool is full
+	// and none of its transactions are old enough to be evicted
+	ErrUnconfirmedPoolFull = errors.New("The unconfirmed transaction pool is full")
+)
+
+const (
+	// DefaultUnconfirmedEvictionMinAge is the default UnconfirmedPoolConfig.EvictionMinAge
+	DefaultUnconfirmedEvictionMinAge = time.Minute * 10
 )
 
 //go:generate skyencoder -unexported -struct UnconfirmedTransaction
@@ -126,6 +138,19 @@ func (utb *unconfirmedTxns) len(tx *dbutil.Tx) (uint64, error) {
 	return dbutil.Len(tx, UnconfirmedTxnsBkt)
 }
 
+// count counts the keys of the bucket. Unlike len, it includes the changes made by tx before it is committed.
+func (utb *unconfirmedTxns) count(tx *dbutil.Tx) (uint64, error) {
+	var n uint64
+	if err := dbutil.ForEach(tx, UnconfirmedTxnsBkt, func(_, _ []byte) error {
+		n++
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
 type txnUnspents struct{}
 
 func (txus *txnUnspents) put(tx *dbutil.Tx, hash cipher.SHA256, uxs coin.UxArray) error {
@@ -166,34 +191,58 @@ func (txus *txnUnspents) getByAddr(tx *dbutil.Tx, a cipher.Address) (coin.UxArra
 	return uxo, nil
 }
 
+// UnconfirmedPoolConfig configures the size limit of the UnconfirmedTransactionPool
+type UnconfirmedPoolConfig struct {
+	// Maximum number of transactions in the pool. If 0, the pool size is unlimited
+	MaxTransactions int
+	// When the pool is full, only transactions that have been in the pool
+	// for at least this long can be evicted to make room for a new transaction
+	EvictionMinAge time.Duration
+}
+
 // UnconfirmedTransactionPool manages unconfirmed transactions
 type UnconfirmedTransactionPool struct {
 	db   *dbutil.DB
+	cfg  UnconfirmedPoolConfig
 	txns *unconfirmedTxns
 	// Predicted unspents, assuming txns are valid.  Needed to predict
 	// our future balance and avoid double spending our own coins
 	// Maps from Transaction.Hash() to UxArray.
 	unspent *txnUnspents
+	// Transactions ordered by insertion time, for evicting old transactions when the pool is full.
+	// It is only modified after the DB transaction that changed the txns bucket is committed.
+	queue *unconfirmedQueue
+	// Number of transactions evicted since startup, accessed atomically
+	evictions uint64
 }
 
 // NewUnconfirmedTransactionPool creates an UnconfirmedTransactionPool instance
-func NewUnconfirmedTransactionPool(db *dbutil.DB) (*UnconfirmedTransactionPool, error) {
+func NewUnconfirmedTransactionPool(db *dbutil.DB, cfg UnconfirmedPoolConfig) (*UnconfirmedTransactionPool, error) {
+	queue := newUnconfirmedQueue()
+
 	if err := db.View("Check unconfirmed txn pool size", func(tx *dbutil.Tx) error {
-		n, err := dbutil.Len(tx, UnconfirmedTxnsBkt)
+		txns, err := (&unconfirmedTxns{}).getAll(tx)
 		if err != nil {
 			return err
 		}
 
-		logger.Infof("Unconfirmed transaction pool size: %d", n)
+		logger.Infof("Unconfirmed transaction pool size: %d", len(txns))
+		queue.rebuild(txns)
 		return nil
 	}); err != nil {
 		return nil, err
 	}
 
+	if cfg.MaxTransactions > 0 {
+		logger.Infof("Unconfirmed transaction pool size limit is %d, transactions older than %v can be evicted", cfg.MaxTransactions, cfg.EvictionMinAge)
+	}
+
 	return &UnconfirmedTransactionPool{
 		db:      db,
+		cfg:     cfg,
 		txns:    &unconfirmedTxns{},
 		unspent: &txnUnspents{},
+		queue:   queue,
 	}, nil
 }
 
@@ -270,6 +319,13 @@ func (utp *UnconfirmedTransactionPool) InjectTransaction(tx *dbutil.Tx, bc Block
 		return true, softErr, nil
 	}
 
+	if err := utp.makeRoom(tx, bc); err != nil {
+		if err != ErrUnconfirmedPoolFull {
+			logger.Errorf("InjectTransaction evict transactions failed: %v", err)
+		}
+		return false, nil, err
+	}
+
 	utx := NewUnconfirmedTransaction(txn)
 	utx.IsValid = isValid
 
@@ -278,6 +334,9 @@ func (utp *UnconfirmedTransactionPool) InjectTransaction(tx *dbutil.Tx, bc Block
 		logger.Errorf("InjectTransaction put new unconfirmed txn failed: %v", err)
 		return false, nil, err
 	}
+	tx.OnCommit(func() {
+		utp.queue.push(hash, utx.Received)
+	})
 
 	head, err := bc.Head(tx)
 	if err != nil {
@@ -309,13 +368,127 @@ func (utp *UnconfirmedTransactionPool) AllRawTransactions(tx *dbutil.Tx) (coin.T
 	return txns, nil
 }
 
+// makeRoom evicts transactions until there is room for a new transaction in the pool.
+// Returns ErrUnconfirmedPoolFull if the pool is full and no transaction can be evicted.
+func (utp *UnconfirmedTransactionPool) makeRoom(tx *dbutil.Tx, bc Blockchainer) error {
+	if utp.cfg.MaxTransactions <= 0 {
+		return nil
+	}
+
+	for {
+		n, err := utp.txns.count(tx)
+		if err != nil {
+			return err
+		}
+
+		if n < uint64(utp.cfg.MaxTransactions) {
+			return nil
+		}
+
+		if err := utp.evictTransaction(tx, bc); err != nil {
+			return err
+		}
+	}
+}
+
+// evictTransaction removes the transaction with the lowest fee density among
+// the transactions inserted at least EvictionMinAge ago. If tied, the oldest transaction is removed.
+// Returns ErrUnconfirmedPoolFull if no transaction is old enough.
+func (utp *UnconfirmedTransactionPool) evictTransaction(tx *dbutil.Tx, bc Blockchainer) error {
+	head, err := bc.Head(tx)
+	if err != nil {
+		return err
+	}
+	feeCalc := bc.TransactionFee(tx, head.Time())
+
+	var evict *cipher.SHA256
+	var lowest uint64
+	before := time.Now().UTC().Add(-utp.cfg.EvictionMinAge).UnixNano()
+	if err := utp.queue.forEachInsertedBefore(before, func(hash cipher.SHA256) error {
+		// The queue is only updated on commit, so it can have transactions that were removed by this DB transaction
+		utxn, err := utp.txns.get(tx, hash)
+		if err != nil {
+			return err
+		}
+		if utxn == nil {
+			return nil
+		}
+
+		density, err := feeDensity(feeCalc, &utxn.Transaction)
+		if err != nil {
+			return err
+		}
+
+		if evict == nil || density < lowest {
+			h := hash
+			evict = &h
+			lowest = density
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if evict == nil {
+		return ErrUnconfirmedPoolFull
+	}
+
+	logger.Infof("Unconfirmed transaction pool is full, evicting transaction %s with a fee of %d coin hours per kB", evict.Hex(), lowest)
+
+	if err := utp.removeTransaction(tx, *evict); err != nil {
+		return err
+	}
+
+	tx.OnCommit(func() {
+		atomic.AddUint64(&utp.evictions, 1)
+	})
+
+	return nil
+}
+
+// feeDensity returns the fee of a transaction in coin hours per kB.
+// If the fee cannot be calculated because its inputs were spent, the transaction can't be confirmed
+// and its fee density is 0.
+func feeDensity(feeCalc coin.FeeCalculator, txn *coin.Transaction) (uint64, error) {
+	fee, err := feeCalc(txn)
+	if err != nil {
+		return 0, nil
+	}
+
+	size, err := txn.Size()
+	if err != nil {
+		return 0, err
+	}
+
+	// If the fee * 1024 would exceed math.MaxUint64, set it to math.MaxUint64, like coin.SortTransactions
+	feeKB, err := mathutil.MultUint64(fee, 1024)
+	if err != nil {
+		feeKB = math.MaxUint64
+	}
+
+	return feeKB / uint64(size), nil
+}
+
+// Evictions returns the number of transactions evicted from the pool since startup
+func (utp *UnconfirmedTransactionPool) Evictions() uint64 {
+	return atomic.LoadUint64(&utp.evictions)
+}
+
 // Remove a single txn by hash
 func (utp *UnconfirmedTransactionPool) removeTransaction(tx *dbutil.Tx, txHash cipher.SHA256) error {
 	if err := utp.txns.delete(tx, txHash); err != nil {
 		return err
 	}
 
-	return utp.unspent.delete(tx, txHash)
+	if err := utp.unspent.delete(tx, txHash); err != nil {
+		return err
+	}
+
+	tx.OnCommit(func() {
+		utp.queue.remove(txHash)
+	})
+
+	return nil
 }
 
 // RemoveTransactions remove transactions with dbutil.Tx
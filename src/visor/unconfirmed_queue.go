@@ -0,0 +1,116 @@
+package visor
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// unconfirmedQueue orders the transactions of the unconfirmed pool by insertion time, oldest first.
+// It is kept in memory alongside the unconfirmed txns bucket and is rebuilt from the bucket at startup.
+type unconfirmedQueue struct {
+	sync.Mutex
+	txns  *list.List
+	elems map[cipher.SHA256]*list.Element
+}
+
+type unconfirmedQueueEntry struct {
+	hash cipher.SHA256
+	// Insertion time in unix nanoseconds
+	inserted int64
+}
+
+func newUnconfirmedQueue() *unconfirmedQueue {
+	return &unconfirmedQueue{
+		txns:  list.New(),
+		elems: make(map[cipher.SHA256]*list.Element),
+	}
+}
+
+// rebuild replaces the queue with txns, ordered by their received time.
+// The insertion time is not saved to the DB, and txn.Received is the closest approximation,
+// since it is only updated when a known transaction is received again.
+func (q *unconfirmedQueue) rebuild(txns []UnconfirmedTransaction) {
+	q.Lock()
+	defer q.Unlock()
+
+	sort.SliceStable(txns, func(i, j int) bool {
+		return txns[i].Received < txns[j].Received
+	})
+
+	q.txns.Init()
+	q.elems = make(map[cipher.SHA256]*list.Element, len(txns))
+	for _, txn := range txns {
+		q.pushLocked(txn.Transaction.Hash(), txn.Received)
+	}
+}
+
+// push adds a transaction to the back of the queue. A transaction that is already queued keeps its position.
+func (q *unconfirmedQueue) push(hash cipher.SHA256, inserted int64) {
+	q.Lock()
+	defer q.Unlock()
+	q.pushLocked(hash, inserted)
+}
+
+func (q *unconfirmedQueue) pushLocked(hash cipher.SHA256, inserted int64) {
+	if _, ok := q.elems[hash]; ok {
+		return
+	}
+
+	q.elems[hash] = q.txns.PushBack(unconfirmedQueueEntry{
+		hash:     hash,
+		inserted: inserted,
+	})
+}
+
+// remove removes a transaction from the queue
+func (q *unconfirmedQueue) remove(hash cipher.SHA256) {
+	q.Lock()
+	defer q.Unlock()
+
+	if e, ok := q.elems[hash]; ok {
+		q.txns.Remove(e)
+		delete(q.elems, hash)
+	}
+}
+
+// len returns the number of queued transactions
+func (q *unconfirmedQueue) len() int {
+	q.Lock()
+	defer q.Unlock()
+	return q.txns.Len()
+}
+
+// hashes returns the queued transaction hashes, oldest first
+func (q *unconfirmedQueue) hashes() []cipher.SHA256 {
+	q.Lock()
+	defer q.Unlock()
+
+	hashes := make([]cipher.SHA256, 0, q.txns.Len())
+	for e := q.txns.Front(); e != nil; e = e.Next() {
+		hashes = append(hashes, e.Value.(unconfirmedQueueEntry).hash)
+	}
+	return hashes
+}
+
+// forEachInsertedBefore calls f for each transaction inserted before t (in unix nanoseconds), oldest first.
+// The queue must not be modified by f.
+func (q *unconfirmedQueue) forEachInsertedBefore(t int64, f func(hash cipher.SHA256) error) error {
+	q.Lock()
+	defer q.Unlock()
+
+	for e := q.txns.Front(); e != nil; e = e.Next() {
+		entry := e.Value.(unconfirmedQueueEntry)
+		if entry.inserted >= t {
+			return nil
+		}
+
+		if err := f(entry.hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package visor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+)
+
+func TestUnconfirmedQueue(t *testing.T) {
+	q := newUnconfirmedQueue()
+
+	h1 := testutil.RandSHA256(t)
+	h2 := testutil.RandSHA256(t)
+	h3 := testutil.RandSHA256(t)
+
+	q.push(h1, 10)
+	q.push(h2, 20)
+	q.push(h3, 30)
+	// A queued transaction keeps its position
+	q.push(h1, 40)
+	require.Equal(t, 3, q.len())
+	require.Equal(t, []cipher.SHA256{h1, h2, h3}, q.hashes())
+
+	var before []cipher.SHA256
+	require.NoError(t, q.forEachInsertedBefore(30, func(hash cipher.SHA256) error {
+		before = append(before, hash)
+		return nil
+	}))
+	require.Equal(t, []cipher.SHA256{h1, h2}, before)
+
+	errStop := errors.New("stop")
+	err := q.forEachInsertedBefore(30, func(hash cipher.SHA256) error {
+		return errStop
+	})
+	require.Equal(t, errStop, err)
+
+	q.remove(h2)
+	q.remove(testutil.RandSHA256(t))
+	require.Equal(t, []cipher.SHA256{h1, h3}, q.hashes())
+
+	// Rebuilding orders the transactions by received time
+	txns := make([]UnconfirmedTransaction, 3)
+	for i, received := range []int64{30, 10, 20} {
+		txns[i].Transaction.In = []cipher.SHA256{testutil.RandSHA256(t)}
+		txns[i].Received = received
+	}
+	q.rebuild(txns)
+	require.Equal(t, []cipher.SHA256{
+		txns[0].Transaction.Hash(),
+		txns[1].Transaction.Hash(),
+		txns[2].Transaction.Hash(),
+	}, q.hashes())
+	require.Equal(t, int64(10), txns[0].Received)
+}
+
+func TestUnconfirmedPoolEviction(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolConfig{})
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = genSecret
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:        cfg,
+		unconfirmed:   unconfirmed,
+		blockchain:    bc,
+		db:            db,
+		history:       historydb.New(),
+		blockProducer: DefaultBlockProducer{},
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	// Create a block with outputs for the transactions of the test
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	txn := makeUnspentsTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, 10, params.UserVerifyTxn.MaxDropletPrecision)
+	_, softErr, err := v.InjectForeignTransaction(txn)
+	require.NoError(t, err)
+	require.Nil(t, softErr)
+
+	sb, err := v.CreateAndExecuteBlock()
+	require.NoError(t, err)
+	uxs = coin.CreateUnspents(sb.Head, sb.Body.Transactions[0])
+
+	// Transactions of the same size, with different fees
+	toAddr := testutil.MakeAddress()
+	txns := make([]coin.Transaction, 6)
+	for i, fee := range []uint64{50, 10, 30, 40, 20, 60} {
+		txns[i] = makeSpendTxWithFee(t, coin.UxArray{uxs[i]}, []cipher.SecKey{genSecret}, toAddr, uxs[i].Body.Coins, fee)
+	}
+
+	inject := func(txn coin.Transaction) error {
+		return db.Update("", func(tx *dbutil.Tx) error {
+			_, _, err := unconfirmed.InjectTransaction(tx, bc, txn, params.MainNetDistribution, params.UserVerifyTxn)
+			return err
+		})
+	}
+
+	requirePool := func(hashes ...cipher.SHA256) {
+		require.Equal(t, hashes, unconfirmed.queue.hashes())
+
+		err := db.View("", func(tx *dbutil.Tx) error {
+			n, err := unconfirmed.Len(tx)
+			require.NoError(t, err)
+			require.Equal(t, uint64(len(hashes)), n)
+
+			for _, h := range hashes {
+				txn, err := unconfirmed.Get(tx, h)
+				require.NoError(t, err)
+				require.NotNil(t, txn)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	unconfirmed.cfg = UnconfirmedPoolConfig{
+		MaxTransactions: 3,
+		EvictionMinAge:  time.Hour,
+	}
+
+	for _, txn := range txns[:3] {
+		require.NoError(t, inject(txn))
+	}
+	requirePool(txns[0].Hash(), txns[1].Hash(), txns[2].Hash())
+
+	// None of the transactions are old enough to be evicted
+	require.Equal(t, ErrUnconfirmedPoolFull, inject(txns[3]))
+	requirePool(txns[0].Hash(), txns[1].Hash(), txns[2].Hash())
+	require.Equal(t, uint64(0), unconfirmed.Evictions())
+
+	// The transaction with the lowest fee is evicted
+	unconfirmed.cfg.EvictionMinAge = 0
+	require.NoError(t, inject(txns[3]))
+	requirePool(txns[0].Hash(), txns[2].Hash(), txns[3].Hash())
+	require.Equal(t, uint64(1), unconfirmed.Evictions())
+
+	// An eviction is rolled back with its DB transaction
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		_, _, err := unconfirmed.InjectTransaction(tx, bc, txns[4], params.MainNetDistribution, params.UserVerifyTxn)
+		require.NoError(t, err)
+		return errors.New("rollback")
+	})
+	require.Error(t, err)
+	requirePool(txns[0].Hash(), txns[2].Hash(), txns[3].Hash())
+	require.Equal(t, uint64(1), unconfirmed.Evictions())
+
+	// Several transactions are evicted in the same DB transaction
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		for _, txn := range txns[4:] {
+			if _, _, err := unconfirmed.InjectTransaction(tx, bc, txn, params.MainNetDistribution, params.UserVerifyTxn); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	// The transaction inserted by the same DB transaction can't be evicted
+	requirePool(txns[0].Hash(), txns[4].Hash(), txns[5].Hash())
+	require.Equal(t, uint64(3), unconfirmed.Evictions())
+
+	// A transaction that is removed from the pool is removed from the queue
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return unconfirmed.RemoveTransactions(tx, []cipher.SHA256{txns[4].Hash()})
+	})
+	require.NoError(t, err)
+	requirePool(txns[0].Hash(), txns[5].Hash())
+
+	// The queue is rebuilt at startup
+	unconfirmed2, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolConfig{})
+	require.NoError(t, err)
+	require.Equal(t, []cipher.SHA256{txns[0].Hash(), txns[5].Hash()}, unconfirmed2.queue.hashes())
+}
@@ -0,0 +1,94 @@
+package visor
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// DefaultUnconfirmedAgeHistogramBuckets are the default upper bounds of the buckets of the
+// unconfirmed transaction age histogram
+var DefaultUnconfirmedAgeHistogramBuckets = []time.Duration{
+	30 * time.Second,
+	time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+}
+
+// UnconfirmedAgeHistogram counts the unconfirmed transactions by how long they have been in the pool
+type UnconfirmedAgeHistogram struct {
+	// Upper bounds of the buckets, in increasing order
+	Buckets []time.Duration
+	// Number of transactions in each bucket. Counts[i] is the number of transactions older than Buckets[i-1]
+	// and at most Buckets[i] old. The last count is the number of transactions older than the last bucket
+	Counts []uint64
+}
+
+func newUnconfirmedAgeHistogram(buckets []time.Duration) UnconfirmedAgeHistogram {
+	return UnconfirmedAgeHistogram{
+		Buckets: append([]time.Duration{}, buckets...),
+		Counts:  make([]uint64, len(buckets)+1),
+	}
+}
+
+func (h *UnconfirmedAgeHistogram) add(age time.Duration) {
+	i := sort.Search(len(h.Buckets), func(i int) bool {
+		return age <= h.Buckets[i]
+	})
+	h.Counts[i]++
+}
+
+// verifyUnconfirmedAgeHistogramBuckets checks that the buckets are positive and in increasing order
+func verifyUnconfirmedAgeHistogramBuckets(buckets []time.Duration) error {
+	for i, b := range buckets {
+		if b <= 0 {
+			return errors.New("UnconfirmedAgeHistogramBuckets must be > 0")
+		}
+		if i > 0 && b <= buckets[i-1] {
+			return errors.New("UnconfirmedAgeHistogramBuckets must be in increasing order")
+		}
+	}
+
+	return nil
+}
+
+// UnconfirmedStats are the statistics of the unconfirmed transaction pool
+type UnconfirmedStats struct {
+	// Number of transactions in the pool
+	Count uint64
+	// Sum of the sizes of the transactions, in bytes
+	Size uint64
+	// Ages of the transactions, since they were last received
+	Ages UnconfirmedAgeHistogram
+}
+
+// GetUnconfirmedStats returns the number and size of the unconfirmed transactions,
+// and the histogram of how long they have been in the pool
+func (vs *Visor) GetUnconfirmedStats() (*UnconfirmedStats, error) {
+	s := &UnconfirmedStats{
+		Ages: newUnconfirmedAgeHistogram(vs.Config.UnconfirmedAgeHistogramBuckets),
+	}
+
+	now := time.Now().UTC()
+
+	if err := vs.db.View("GetUnconfirmedStats", func(tx *dbutil.Tx) error {
+		return vs.unconfirmed.ForEach(tx, func(_ cipher.SHA256, txn UnconfirmedTransaction) error {
+			size, err := txn.Transaction.Size()
+			if err != nil {
+				return err
+			}
+
+			s.Count++
+			s.Size += uint64(size)
+			s.Ages.add(now.Sub(time.Unix(0, txn.Received)))
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
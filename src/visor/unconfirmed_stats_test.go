@@ -0,0 +1,94 @@
+package visor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestUnconfirmedAgeHistogramAdd(t *testing.T) {
+	h := newUnconfirmedAgeHistogram([]time.Duration{time.Second, time.Minute})
+
+	h.add(0)
+	h.add(time.Second)
+	h.add(time.Second + 1)
+	h.add(time.Minute)
+	h.add(time.Hour)
+
+	require.Equal(t, UnconfirmedAgeHistogram{
+		Buckets: []time.Duration{time.Second, time.Minute},
+		Counts:  []uint64{2, 2, 1},
+	}, h)
+}
+
+func TestVerifyUnconfirmedAgeHistogramBuckets(t *testing.T) {
+	require.NoError(t, verifyUnconfirmedAgeHistogramBuckets(nil))
+	require.NoError(t, verifyUnconfirmedAgeHistogramBuckets(DefaultUnconfirmedAgeHistogramBuckets))
+	require.EqualError(t, verifyUnconfirmedAgeHistogramBuckets([]time.Duration{0, time.Second}), "UnconfirmedAgeHistogramBuckets must be > 0")
+	require.EqualError(t, verifyUnconfirmedAgeHistogramBuckets([]time.Duration{time.Second, time.Second}), "UnconfirmedAgeHistogramBuckets must be in increasing order")
+	require.EqualError(t, verifyUnconfirmedAgeHistogramBuckets([]time.Duration{time.Minute, time.Second}), "UnconfirmedAgeHistogramBuckets must be in increasing order")
+}
+
+func TestGetUnconfirmedStats(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	cfg := NewConfig()
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+	cfg.Distribution = params.MainNetDistribution
+	cfg.UnconfirmedAgeHistogramBuckets = []time.Duration{time.Minute, time.Hour}
+
+	v, err := New(cfg, db, nil)
+	require.NoError(t, err)
+
+	s, err := v.GetUnconfirmedStats()
+	require.NoError(t, err)
+	require.Equal(t, &UnconfirmedStats{
+		Ages: UnconfirmedAgeHistogram{
+			Buckets: []time.Duration{time.Minute, time.Hour},
+			Counts:  []uint64{0, 0, 0},
+		},
+	}, s)
+
+	now := time.Now().UTC()
+	var size uint64
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		for _, age := range []time.Duration{time.Second, time.Minute * 2, time.Minute * 3, time.Hour * 2} {
+			var txn coin.Transaction
+			err := txn.PushOutput(testutil.MakeAddress(), 1e6, 1)
+			require.NoError(t, err)
+			err = txn.UpdateHeader()
+			require.NoError(t, err)
+
+			n, err := txn.Size()
+			require.NoError(t, err)
+			size += uint64(n)
+
+			utx := NewUnconfirmedTransaction(txn)
+			utx.Received = now.Add(-age).UnixNano()
+			if err := (&unconfirmedTxns{}).put(tx, &utx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	s, err = v.GetUnconfirmedStats()
+	require.NoError(t, err)
+	require.Equal(t, &UnconfirmedStats{
+		Count: 4,
+		Size:  size,
+		Ages: UnconfirmedAgeHistogram{
+			Buckets: []time.Duration{time.Minute, time.Hour},
+			Counts:  []uint64{1, 2, 1},
+		},
+	}, s)
+}
@@ -0,0 +1,243 @@
+package visor
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// Constraint is the kind of transaction constraint a ValidationError violates
+type Constraint string
+
+const (
+	// ConstraintHard is violated by transactions that can never be confirmed
+	ConstraintHard Constraint = "hard"
+	// ConstraintSoft is violated by transactions that are not accepted by the unconfirmed pool or block publishers
+	ConstraintSoft Constraint = "soft"
+	// ConstraintUser is violated by transactions that the node does not let its user create
+	ConstraintUser Constraint = "user"
+)
+
+var (
+	// ErrTxnDustOutput is returned for an output with fewer coins than the dust threshold of the node
+	ErrTxnDustOutput = errors.New("Transaction output coins are below the dust threshold")
+	// ErrTxnNullAddressOutput is returned for an output sent to the null address
+	ErrTxnNullAddressOutput = errors.New("Transaction output is sent to the null address")
+	// ErrTxnUnsignedInput is returned for a null signature of a signed transaction
+	ErrTxnUnsignedInput = errors.New("Unsigned input in transaction")
+	// ErrTxnInvalidInputSignature is returned for a signature that is not valid for the output being spent
+	ErrTxnInvalidInputSignature = errors.New("Signature not valid for output being spent")
+	// ErrTxnNoNullSignature is returned for an unsigned transaction that has no null signature
+	ErrTxnNoNullSignature = errors.New("Unsigned transaction must contain a null signature")
+)
+
+// ValidationError is one of the problems of a transaction found by VerifyTransaction
+type ValidationError struct {
+	Constraint Constraint
+	// Index of the input the error is about, -1 if it is not about an input
+	Input int
+	// Index of the output the error is about, -1 if it is not about an output
+	Output int
+	Err    error
+}
+
+func (e ValidationError) Error() string {
+	switch {
+	case e.Input >= 0:
+		return fmt.Sprintf("Transaction violates %s constraint: input %d: %v", e.Constraint, e.Input, e.Err)
+	case e.Output >= 0:
+		return fmt.Sprintf("Transaction violates %s constraint: output %d: %v", e.Constraint, e.Output, e.Err)
+	default:
+		return fmt.Sprintf("Transaction violates %s constraint: %v", e.Constraint, e.Err)
+	}
+}
+
+// validationErrors collects the ValidationErrors of a transaction
+type validationErrors []ValidationError
+
+func (v *validationErrors) add(c Constraint, err error) {
+	*v = append(*v, ValidationError{
+		Constraint: c,
+		Input:      -1,
+		Output:     -1,
+		Err:        err,
+	})
+}
+
+func (v *validationErrors) addInput(c Constraint, i int, err error) {
+	*v = append(*v, ValidationError{
+		Constraint: c,
+		Input:      i,
+		Output:     -1,
+		Err:        err,
+	})
+}
+
+func (v *validationErrors) addOutput(c Constraint, i int, err error) {
+	*v = append(*v, ValidationError{
+		Constraint: c,
+		Input:      -1,
+		Output:     i,
+		Err:        err,
+	})
+}
+
+// VerifyTransaction checks a transaction that is not in a block against the hard, soft and user constraints,
+// and returns all of the problems found instead of only the first one.
+// The transaction has no problems if the returned slice is empty.
+// The inputs must be in the unspent pool, confirmed transactions are reported as spending missing inputs.
+// The returned error is only set if the transaction could not be checked.
+func (vs *Visor) VerifyTransaction(txn coin.Transaction, signed TxnSignedFlag) ([]ValidationError, error) {
+	var errs validationErrors
+	if err := vs.db.View("VerifyTransaction", func(tx *dbutil.Tx) error {
+		head, err := vs.blockchain.Head(tx)
+		if err != nil {
+			return err
+		}
+
+		uxIn := make(coin.UxArray, 0, len(txn.In))
+		for i, h := range txn.In {
+			ux, err := vs.blockchain.Unspent().Get(tx, h)
+			if err != nil {
+				return err
+			}
+			if ux == nil {
+				errs.addInput(ConstraintHard, i, blockdb.NewErrUnspentNotExist(h.Hex()))
+				continue
+			}
+			uxIn = append(uxIn, *ux)
+		}
+		hasInputs := len(uxIn) == len(txn.In)
+
+		verifyTxnStructure(&errs, txn, signed)
+		verifyTxnSignatures(&errs, txn, signed, uxIn, hasInputs)
+
+		if _, err := txn.OutputHours(); err != nil {
+			errs.add(ConstraintHard, err)
+		}
+
+		txnSize, sizeErr := txn.Size()
+		if sizeErr != nil || txnSize > params.UserVerifyTxn.MaxTransactionSize {
+			errs.add(ConstraintSoft, ErrTxnExceedsMaxBlockSize)
+		}
+
+		if err := verifyTxnInputOutputCounts(txn, params.UserVerifyTxn); err != nil {
+			errs.add(ConstraintSoft, err)
+		}
+
+		if hasInputs {
+			verifyTxnSpending(&errs, txn, head.Head, uxIn)
+
+			if f, err := fee.TransactionFee(&txn, head.Time(), uxIn); err != nil {
+				errs.add(ConstraintSoft, err)
+			} else {
+				if err := fee.VerifyTransactionFee(&txn, f, params.UserVerifyTxn.BurnFactor); err != nil {
+					errs.add(ConstraintSoft, err)
+				}
+				if sizeErr == nil {
+					if err := fee.VerifyTransactionFeePerByte(f, txnSize, params.UserVerifyTxn.MinFeePerByte); err != nil {
+						errs.add(ConstraintSoft, err)
+					}
+				}
+			}
+
+			if TransactionIsLocked(vs.Config.Distribution, uxIn) {
+				errs.add(ConstraintSoft, ErrTxnIsLocked)
+			}
+		}
+
+		for i, o := range txn.Out {
+			if err := params.DropletPrecisionCheck(params.UserVerifyTxn.MaxDropletPrecision, o.Coins); err != nil {
+				errs.addOutput(ConstraintSoft, i, err)
+			}
+			if o.Address.Null() {
+				errs.addOutput(ConstraintUser, i, ErrTxnNullAddressOutput)
+			}
+			if o.Coins < vs.Config.DustThreshold {
+				errs.addOutput(ConstraintUser, i, ErrTxnDustOutput)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if errs == nil {
+		return []ValidationError{}, nil
+	}
+
+	return errs, nil
+}
+
+// verifyTxnStructure checks everything that coin.Transaction.Verify checks except for the signatures,
+// which are checked one by one by verifyTxnSignatures
+func verifyTxnStructure(errs *validationErrors, txn coin.Transaction, signed TxnSignedFlag) {
+	// Verify the transaction as if it were unsigned, with all of its signatures null,
+	// so that the signatures are skipped
+	nullSigs := txn
+	nullSigs.Sigs = make([]cipher.Sig, len(txn.Sigs))
+	if err := nullSigs.VerifyUnsigned(); err != nil {
+		errs.add(ConstraintHard, err)
+	}
+
+	if signed == TxnUnsigned && len(txn.Sigs) != 0 && txn.IsFullySigned() {
+		errs.add(ConstraintHard, ErrTxnNoNullSignature)
+	}
+}
+
+// verifyTxnSignatures checks the signature of each input.
+// The signatures are checked against the addresses of the inputs if all of them were found
+func verifyTxnSignatures(errs *validationErrors, txn coin.Transaction, signed TxnSignedFlag, uxIn coin.UxArray, hasInputs bool) {
+	if len(txn.Sigs) != len(txn.In) || txn.InnerHash != txn.HashInner() {
+		// Reported by verifyTxnStructure
+		return
+	}
+
+	for i, sig := range txn.Sigs {
+		if sig.Null() {
+			if signed == TxnSigned {
+				errs.addInput(ConstraintHard, i, ErrTxnUnsignedInput)
+			}
+			continue
+		}
+
+		hash := cipher.AddSHA256(txn.InnerHash, txn.In[i])
+		if hasInputs {
+			if err := cipher.VerifyAddressSignedHash(uxIn[i].Body.Address, sig, hash); err != nil {
+				errs.addInput(ConstraintHard, i, ErrTxnInvalidInputSignature)
+			}
+		} else if err := cipher.VerifySignatureRecoverPubKey(sig, hash); err != nil {
+			errs.addInput(ConstraintHard, i, err)
+		}
+	}
+}
+
+// verifyTxnSpending checks that the transaction does not create or destroy coins and does not create hours
+func verifyTxnSpending(errs *validationErrors, txn coin.Transaction, head coin.BlockHeader, uxIn coin.UxArray) {
+	hoursOverflow := false
+	for i, ux := range uxIn {
+		if _, err := ux.CoinHours(head.Time); err != nil {
+			errs.addInput(ConstraintHard, i, err)
+			hoursOverflow = true
+		}
+	}
+
+	uxOut := coin.CreateUnspents(head, txn)
+
+	if err := coin.VerifyTransactionCoinsSpending(uxIn, uxOut); err != nil {
+		errs.add(ConstraintHard, err)
+	}
+
+	if !hoursOverflow {
+		if err := coin.VerifyTransactionHoursSpending(head.Time, uxIn, uxOut); err != nil {
+			errs.add(ConstraintHard, err)
+		}
+	}
+}
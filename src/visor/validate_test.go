@@ -0,0 +1,169 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+)
+
+func TestVerifyTransaction(t *testing.T) {
+	v, shutdown := newChainExportTestVisor(t)
+	defer shutdown()
+
+	gb := addGenesisBlockToVisor(t, v)
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	addr := testutil.MakeAddress()
+
+	makeTxn := func(in []cipher.SHA256, out []coin.TransactionOutput, keys []cipher.SecKey) coin.Transaction {
+		txn := coin.Transaction{
+			In:  in,
+			Out: out,
+		}
+		if keys != nil {
+			txn.SignInputs(keys)
+		} else {
+			txn.Sigs = make([]cipher.Sig, len(in))
+		}
+		err := txn.UpdateHeader()
+		require.NoError(t, err)
+		return txn
+	}
+
+	validTxn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, addr, 1e6)
+
+	// A transaction with a signature of the wrong key and outputs that violate soft and user constraints
+	_, wrongKey := cipher.GenerateKeyPair()
+	badOutputsTxn := makeTxn([]cipher.SHA256{uxs[0].Hash()}, []coin.TransactionOutput{
+		{
+			Address: cipher.Address{},
+			Coins:   genCoins - 1,
+		},
+		{
+			Address: addr,
+			Coins:   1,
+		},
+	}, []cipher.SecKey{wrongKey})
+
+	// An unsigned transaction spending an unknown output
+	missing := testutil.RandSHA256(t)
+	missingInputTxn := makeTxn([]cipher.SHA256{missing}, []coin.TransactionOutput{
+		{
+			Address: addr,
+			Coins:   1e6,
+		},
+	}, nil)
+
+	invalidSig := ValidationError{
+		Constraint: ConstraintHard,
+		Input:      0,
+		Output:     -1,
+		Err:        ErrTxnInvalidInputSignature,
+	}
+	precision := func(i int) ValidationError {
+		return ValidationError{
+			Constraint: ConstraintSoft,
+			Input:      -1,
+			Output:     i,
+			Err:        params.ErrInvalidDecimals,
+		}
+	}
+
+	cases := []struct {
+		name          string
+		txn           coin.Transaction
+		signed        TxnSignedFlag
+		dustThreshold uint64
+		errs          []ValidationError
+	}{
+		{
+			name:   "valid",
+			txn:    validTxn,
+			signed: TxnSigned,
+			errs:   []ValidationError{},
+		},
+		{
+			name:   "signed transaction verified as unsigned",
+			txn:    validTxn,
+			signed: TxnUnsigned,
+			errs: []ValidationError{
+				{
+					Constraint: ConstraintHard,
+					Input:      -1,
+					Output:     -1,
+					Err:        ErrTxnNoNullSignature,
+				},
+			},
+		},
+		{
+			name:          "invalid signature and outputs",
+			txn:           badOutputsTxn,
+			signed:        TxnSigned,
+			dustThreshold: 1e3,
+			errs: []ValidationError{
+				invalidSig,
+				precision(0),
+				{
+					Constraint: ConstraintUser,
+					Input:      -1,
+					Output:     0,
+					Err:        ErrTxnNullAddressOutput,
+				},
+				precision(1),
+				{
+					Constraint: ConstraintUser,
+					Input:      -1,
+					Output:     1,
+					Err:        ErrTxnDustOutput,
+				},
+			},
+		},
+		{
+			name:   "missing unsigned input",
+			txn:    missingInputTxn,
+			signed: TxnSigned,
+			errs: []ValidationError{
+				{
+					Constraint: ConstraintHard,
+					Input:      0,
+					Output:     -1,
+					Err:        blockdb.NewErrUnspentNotExist(missing.Hex()),
+				},
+				{
+					Constraint: ConstraintHard,
+					Input:      0,
+					Output:     -1,
+					Err:        ErrTxnUnsignedInput,
+				},
+			},
+		},
+		{
+			name:   "missing input of unsigned transaction",
+			txn:    missingInputTxn,
+			signed: TxnUnsigned,
+			errs: []ValidationError{
+				{
+					Constraint: ConstraintHard,
+					Input:      0,
+					Output:     -1,
+					Err:        blockdb.NewErrUnspentNotExist(missing.Hex()),
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			v.Config.DustThreshold = tc.dustThreshold
+
+			errs, err := v.VerifyTransaction(tc.txn, tc.signed)
+			require.NoError(t, err)
+			require.Equal(t, tc.errs, errs)
+		})
+	}
+}
@@ -53,6 +53,7 @@ HARD constraints can NEVER be violated. These include:
 
 SOFT constraints are based upon mutable parameters. These include:
     - Max block size (transaction must not be larger than this value)
+    - Max inputs and outputs of a transaction
     - Insufficient coin hour burn fee
     - Timelocked distribution addresses
     - Decimal place restrictions
@@ -72,6 +73,10 @@ var (
 	ErrTxnExceedsMaxBlockSize = errors.New("Transaction size bigger than max block size")
 	// ErrTxnIsLocked transaction has locked address inputs
 	ErrTxnIsLocked = errors.New("Transaction has locked address inputs")
+	// ErrTooManyInputs transaction has more inputs than the max inputs of a transaction
+	ErrTooManyInputs = errors.New("Transaction has too many inputs")
+	// ErrTooManyOutputs transaction has more outputs than the max outputs of a transaction
+	ErrTooManyOutputs = errors.New("Transaction has too many outputs")
 )
 
 // TxnSignedFlag indicates if the transaction is unsigned or not
@@ -148,7 +153,9 @@ func (e ErrTxnViolatesUserConstraint) Error() string {
 // accept blocks that violate soft constraints.
 // Checks:
 //      * That the transaction size is not greater than the max block total transaction size
+//      * That the transaction does not have more inputs or outputs than the max inputs and outputs of a transaction
 //      * That the transaction burn enough coin hours (the fee)
+//      * That the fee per byte of the transaction size is not less than the minimum
 //      * That if that transaction does not spend from a locked distribution address
 //      * That the transaction does not create outputs with a higher decimal precision than is allowed
 func VerifySingleTxnSoftConstraints(txn coin.Transaction, headTime uint64, uxIn coin.UxArray, distParams params.Distribution, verifyParams params.VerifyTxn) error {
@@ -169,6 +176,10 @@ func verifyTxnSoftConstraints(txn coin.Transaction, headTime uint64, uxIn coin.U
 		return ErrTxnExceedsMaxBlockSize
 	}
 
+	if err := verifyTxnInputOutputCounts(txn, verifyParams); err != nil {
+		return err
+	}
+
 	f, err := fee.TransactionFee(&txn, headTime, uxIn)
 	if err != nil {
 		return err
@@ -178,6 +189,10 @@ func verifyTxnSoftConstraints(txn coin.Transaction, headTime uint64, uxIn coin.U
 		return err
 	}
 
+	if err := fee.VerifyTransactionFeePerByte(f, txnSize, verifyParams.MinFeePerByte); err != nil {
+		return err
+	}
+
 	if TransactionIsLocked(distParams, uxIn) {
 		return ErrTxnIsLocked
 	}
@@ -192,6 +207,20 @@ func verifyTxnSoftConstraints(txn coin.Transaction, headTime uint64, uxIn coin.U
 	return nil
 }
 
+// verifyTxnInputOutputCounts returns ErrTooManyInputs or ErrTooManyOutputs
+// if the transaction exceeds verifyParams.MaxTxInputs or verifyParams.MaxTxOutputs
+func verifyTxnInputOutputCounts(txn coin.Transaction, verifyParams params.VerifyTxn) error {
+	if verifyParams.MaxTxInputs > 0 && len(txn.In) > verifyParams.MaxTxInputs {
+		return ErrTooManyInputs
+	}
+
+	if verifyParams.MaxTxOutputs > 0 && len(txn.Out) > verifyParams.MaxTxOutputs {
+		return ErrTooManyOutputs
+	}
+
+	return nil
+}
+
 // VerifySingleTxnHardConstraints returns an error if any "hard" constraints are violated.
 // "hard" constraints are always enforced and if violated the transaction
 // should not be included in any block and any block that includes such a transaction
@@ -13,7 +13,6 @@ package visor
 import (
 	"errors"
 	"fmt"
-
 	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
@@ -35,6 +34,7 @@ type Visor struct {
 	Config Config
 
 	startedAt   time.Time
+	restartInfo RestartInfo
 	db          *dbutil.DB
 	unconfirmed UnconfirmedTransactionPooler
 	blockchain  Blockchainer
@@ -42,6 +42,20 @@ type Visor struct {
 	wallets     *wallet.Service
 	txns        transactionsGetter
 	tf          wallet.TransactionsFinder
+	// Only set for block publisher nodes
+	blockProducer BlockProducerPlugin
+	// Richlist of the head block
+	richlistCache *richlistCache
+	// Fee history of the last blocks
+	feeHistoryCache *feeHistoryCache
+	// Active signaling deployments of the round of the last created block
+	signalingCache *signalingCache
+	// Records the outputs created and spent by each block, if Config.EnableEventLog is set
+	eventLog eventLog
+	// Subscriptions to the transactions of addresses
+	addressSubscriptions *addressSubscriptions
+	// The last database corruption check started by StartCorruptionCheck
+	corruptionChecker *corruptionChecker
 }
 
 // New creates a Visor for managing the blockchain database
@@ -63,6 +77,20 @@ func New(c Config, db *dbutil.DB, wltServ *wallet.Service) (*Visor, error) {
 	logger.Infof("Max decimals for transactions when creating blocks is %d", c.CreateBlockVerifyTxn.MaxDropletPrecision)
 	logger.Infof("Max block size is %d", c.MaxBlockTransactionsSize)
 
+	var blockProducer BlockProducerPlugin
+	if c.IsBlockPublisher {
+		var err error
+		blockProducer, err = GetBlockProducer(c.BlockProducer)
+		if err != nil {
+			return nil, err
+		}
+		logger.Infof("Block producer is %q", c.BlockProducer)
+	}
+
+	if c.DBFillPercent != 0 {
+		db.FillPercent = c.DBFillPercent
+	}
+
 	if !db.IsReadOnly() {
 		if err := CreateBuckets(db); err != nil {
 			logger.WithError(err).Error("CreateBuckets failed")
@@ -70,19 +98,66 @@ func New(c Config, db *dbutil.DB, wltServ *wallet.Service) (*Visor, error) {
 		}
 	}
 
+	// A read-only DB reports the restart info saved by the last writable run, without counting this start
+	var restartInfo *RestartInfo
+	if db.IsReadOnly() {
+		if err := db.View("getRestartInfo", func(tx *dbutil.Tx) error {
+			var err error
+			restartInfo, err = getRestartInfo(tx)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := db.Update("recordStart", func(tx *dbutil.Tx) error {
+			var err error
+			restartInfo, err = recordStart(tx)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if restartInfo == nil {
+		restartInfo = &RestartInfo{}
+	}
+	logger.Infof("Restart count is %d, last restart reason is %q", restartInfo.RestartCount, restartInfo.LastRestartReason)
+
 	bc, err := NewBlockchain(db, BlockchainConfig{
-		Pubkey:      c.BlockchainPubkey,
-		Arbitrating: c.Arbitrating,
+		Pubkey:             c.BlockchainPubkey,
+		Arbitrating:        c.Arbitrating,
+		MaxFutureBlockTime: c.MaxFutureBlockTime,
+		MinBlockInterval:   c.MinBlockInterval,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	if c.PruneOlderThanBlocks == 0 {
+		if err := db.View("check pruned", func(tx *dbutil.Tx) error {
+			prunedSeq, ok, err := bc.PrunedSeq(tx)
+			if err != nil {
+				return err
+			}
+			if ok {
+				return fmt.Errorf("The database has been pruned up to block %d and cannot be used by an archival node", prunedSeq)
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	} else {
+		logger.Infof("Visor running in pruned mode, keeping the transactions of the last %d blocks", c.PruneOlderThanBlocks)
+	}
+
 	history := historydb.New()
+	eventLog := eventLog{
+		history:    history,
+		blockchain: bc,
+	}
 
 	if !db.IsReadOnly() {
 		if err := db.Update("build unspent indexes and init history", func(tx *dbutil.Tx) error {
-			headSeq, _, err := bc.HeadSeq(tx)
+			headSeq, hasHead, err := bc.HeadSeq(tx)
 			if err != nil {
 				return err
 			}
@@ -91,13 +166,29 @@ func New(c Config, db *dbutil.DB, wltServ *wallet.Service) (*Visor, error) {
 				return err
 			}
 
-			return initHistory(tx, bc, history)
+			if err := initHistory(tx, bc, history); err != nil {
+				return err
+			}
+
+			if err := initBlockSizeHistogram(tx, bc, c.BlockSizeHistogramBuckets, headSeq, hasHead); err != nil {
+				return err
+			}
+
+			// Log the events of the blocks executed while the event log was disabled
+			if !c.EnableEventLog || !hasHead {
+				return nil
+			}
+
+			return eventLog.logTo(tx, bc, headSeq)
 		}); err != nil {
 			return nil, err
 		}
 	}
 
-	utp, err := NewUnconfirmedTransactionPool(db)
+	utp, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolConfig{
+		MaxTransactions: c.MaxUnconfirmedTransactions,
+		EvictionMinAge:  c.UnconfirmedEvictionMinAge,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -109,14 +200,22 @@ func New(c Config, db *dbutil.DB, wltServ *wallet.Service) (*Visor, error) {
 	}
 
 	v := &Visor{
-		Config:      c,
-		startedAt:   time.Now(),
-		db:          db,
-		blockchain:  bc,
-		unconfirmed: utp,
-		history:     history,
-		wallets:     wltServ,
-		txns:        &txns,
+		Config:               c,
+		startedAt:            time.Now(),
+		restartInfo:          *restartInfo,
+		db:                   db,
+		blockchain:           bc,
+		unconfirmed:          utp,
+		history:              history,
+		wallets:              wltServ,
+		txns:                 &txns,
+		blockProducer:        blockProducer,
+		richlistCache:        &richlistCache{},
+		feeHistoryCache:      &feeHistoryCache{},
+		signalingCache:       &signalingCache{},
+		addressSubscriptions: &addressSubscriptions{},
+		eventLog:             eventLog,
+		corruptionChecker:    &corruptionChecker{},
 	}
 
 	v.tf = newTransactionsFinder(v)
@@ -133,11 +232,15 @@ func (vs *Visor) VisorConfig() Config {
 func (vs *Visor) Init() error {
 	logger.Info("Visor init")
 
+	if err := vs.db.View("verifyGenesisOutputs", vs.verifyGenesisOutputs); err != nil {
+		return err
+	}
+
 	if vs.db.IsReadOnly() {
 		return nil
 	}
 
-	return vs.db.Update("visor init", func(tx *dbutil.Tx) error {
+	if err := vs.db.Update("visor init", func(tx *dbutil.Tx) error {
 		if err := vs.maybeCreateGenesisBlock(tx); err != nil {
 			return err
 		}
@@ -149,7 +252,11 @@ func (vs *Visor) Init() error {
 		logger.Infof("Removed %d invalid txns from pool", len(removed))
 
 		return nil
-	})
+	}); err != nil {
+		return err
+	}
+
+	return vs.pruneOldBlocks()
 }
 
 func initHistory(tx *dbutil.Tx, bc *Blockchain, history *historydb.HistoryDB) error {
@@ -164,6 +271,13 @@ func initHistory(tx *dbutil.Tx, bc *Blockchain, history *historydb.HistoryDB) er
 		return nil
 	}
 
+	// The history can't be rebuilt from pruned blocks
+	if prunedSeq, ok, err := bc.PrunedSeq(tx); err != nil {
+		return err
+	} else if ok {
+		return fmt.Errorf("historyDB needs to be rebuilt but the transactions of blocks up to %d have been pruned", prunedSeq)
+	}
+
 	logger.Info("Resetting historyDB")
 
 	if err := history.Erase(tx); err != nil {
@@ -193,6 +307,8 @@ func parseHistoryTo(tx *dbutil.Tx, history *historydb.HistoryDB, bc *Blockchain,
 	}
 
 	for i := uint64(0); i < height-parsedBlockSeq; i++ {
+		prefetchAhead(bc, parsedBlockSeq+1, parsedBlockSeq+i+1)
+
 		b, err := bc.GetSignedBlockBySeq(tx, parsedBlockSeq+i+1)
 		if err != nil {
 			return err
@@ -223,7 +339,12 @@ func (vs *Visor) maybeCreateGenesisBlock(tx *dbutil.Tx) error {
 
 	logger.Info("Create genesis block")
 	vs.GenesisPreconditions()
-	b, err := coin.NewGenesisBlock(vs.Config.GenesisAddress, vs.Config.GenesisCoinVolume, vs.Config.GenesisTimestamp)
+	var b *coin.Block
+	if vs.Config.GenesisOutputs != nil {
+		b, err = vs.Config.GenesisOutputs.NewGenesisBlock(vs.Config.GenesisTimestamp)
+	} else {
+		b, err = coin.NewGenesisBlock(vs.Config.GenesisAddress, vs.Config.GenesisCoinVolume, vs.Config.GenesisTimestamp)
+	}
 	if err != nil {
 		return err
 	}
@@ -257,6 +378,16 @@ func (vs *Visor) StartedAt() time.Time {
 	return vs.startedAt
 }
 
+// RestartInfo returns the number of times the node has been restarted and the reason for the last restart
+func (vs *Visor) RestartInfo() RestartInfo {
+	return vs.restartInfo
+}
+
+// UnconfirmedEvictions returns the number of transactions evicted from the full unconfirmed pool since startup
+func (vs *Visor) UnconfirmedEvictions() uint64 {
+	return vs.unconfirmed.Evictions()
+}
+
 // RefreshUnconfirmed checks unconfirmed txns against the blockchain and returns
 // all transaction that turn to valid.
 func (vs *Visor) RefreshUnconfirmed() ([]cipher.SHA256, error) {
@@ -294,6 +425,15 @@ func (vs *Visor) createBlock(tx *dbutil.Tx, when uint64) (coin.SignedBlock, erro
 		logger.Panic("Only a block publisher node can create blocks")
 	}
 
+	headSeq, _, err := vs.blockchain.HeadSeq(tx)
+	if err != nil {
+		return coin.SignedBlock{}, err
+	}
+
+	if !vs.blockProducer.CanProduce(headSeq+1, vs.Config.BlockchainPubkey) {
+		return coin.SignedBlock{}, ErrBlockProducerCannotProduce
+	}
+
 	// Gather all unconfirmed transactions
 	txns, err := vs.unconfirmed.AllRawTransactions(tx)
 	if err != nil {
@@ -316,10 +456,20 @@ func (vs *Visor) createBlockFromTxns(tx *dbutil.Tx, txns coin.Transactions, when
 
 	logger.Infof("unconfirmed pool has %d transactions pending", len(txns))
 
+	head, err := vs.blockchain.Head(tx)
+	if err != nil {
+		return coin.Block{}, err
+	}
+
+	verifyParams, maxBlockSize, err := vs.createBlockParams(tx, head.Seq()+1)
+	if err != nil {
+		return coin.Block{}, err
+	}
+
 	// Filter transactions that violate all constraints
 	var filteredTxns coin.Transactions
 	for _, txn := range txns {
-		if _, _, err := vs.blockchain.VerifySingleTxnSoftHardConstraints(tx, txn, vs.Config.Distribution, vs.Config.CreateBlockVerifyTxn, TxnSigned); err != nil {
+		if _, _, err := vs.blockchain.VerifySingleTxnSoftHardConstraints(tx, txn, vs.Config.Distribution, verifyParams, TxnSigned); err != nil {
 			switch err.(type) {
 			case ErrTxnViolatesHardConstraint, ErrTxnViolatesSoftConstraint:
 				logger.Warningf("Transaction %s violates constraints: %v", txn.Hash().Hex(), err)
@@ -343,11 +493,6 @@ func (vs *Visor) createBlockFromTxns(tx *dbutil.Tx, txns coin.Transactions, when
 		return coin.Block{}, errors.New("No transactions after filtering for constraint violations")
 	}
 
-	head, err := vs.blockchain.Head(tx)
-	if err != nil {
-		return coin.Block{}, err
-	}
-
 	// Sort them by highest fee per kilobyte
 	txns, err = coin.SortTransactions(txns, vs.blockchain.TransactionFee(tx, head.Time()))
 	if err != nil {
@@ -355,20 +500,17 @@ func (vs *Visor) createBlockFromTxns(tx *dbutil.Tx, txns coin.Transactions, when
 		return coin.Block{}, err
 	}
 
-	// Apply block size transaction limit
-	txns, err = txns.TruncateBytesTo(vs.Config.MaxBlockTransactionsSize)
-	if err != nil {
-		logger.Critical().WithError(err).Error("TruncateBytesTo failed, no block can be made until the offending transaction is removed")
-		return coin.Block{}, err
-	}
-
-	if len(txns) > coin.MaxBlockTransactions {
-		txns = txns[:coin.MaxBlockTransactions]
+	// Apply the block producer's transaction selection rules
+	selected := vs.blockProducer.SelectTransactions(txns, int(maxBlockSize))
+	if len(selected) == 0 {
+		return coin.Block{}, ErrBlockProducerNoTransactions
 	}
 
-	if len(txns) == 0 {
-		logger.Panic("TruncateBytesTo removed all transactions")
+	// The plugin's selection is not trusted, it must be a subset of the verified transactions that fits in a block
+	if err := verifySelectedTransactions(txns, selected, maxBlockSize); err != nil {
+		return coin.Block{}, err
 	}
+	txns = selected
 
 	logger.Infof("Creating new block with %d transactions, head time %d", len(txns), when)
 
@@ -378,6 +520,9 @@ func (vs *Visor) createBlockFromTxns(tx *dbutil.Tx, txns coin.Transactions, when
 		return coin.Block{}, err
 	}
 
+	// Signal the parameter changes supported by this block publisher
+	b.Head.Version = vs.Config.SignalingField
+
 	return *b, nil
 }
 
@@ -394,8 +539,13 @@ func (vs *Visor) CreateAndExecuteBlock() (coin.SignedBlock, error) {
 
 		return vs.executeSignedBlock(tx, sb)
 	})
+	if err != nil {
+		return sb, err
+	}
 
-	return sb, err
+	vs.addressSubscriptions.notifyBlock(sb.Block)
+
+	return sb, nil
 }
 
 // CreateBlockFromTxns creates a Block from specified set of transactions according to set of determinstic rules.
@@ -425,17 +575,29 @@ func (vs *Visor) VerifyBlock(b coin.SignedBlock) error {
 // ExecuteSignedBlock adds a block to the blockchain, or returns error.
 // Blocks must be executed in sequence, and be signed by a block publisher node.
 func (vs *Visor) ExecuteSignedBlock(b coin.SignedBlock) error {
-	return vs.db.Update("ExecuteSignedBlock", func(tx *dbutil.Tx) error {
+	if err := vs.db.Update("ExecuteSignedBlock", func(tx *dbutil.Tx) error {
 		return vs.executeSignedBlock(tx, b)
-	})
+	}); err != nil {
+		return err
+	}
+
+	vs.addressSubscriptions.notifyBlock(b.Block)
+
+	return nil
 }
 
 // ExecuteSignedBlockUnsafe adds block to the blockchain, or returns error.
 // Blocks must be executed in sequence. Block signature is not verified.
 func (vs *Visor) ExecuteSignedBlockUnsafe(b coin.SignedBlock) error {
-	return vs.db.Update("ExecuteSignedBlockUnsafe", func(tx *dbutil.Tx) error {
+	if err := vs.db.Update("ExecuteSignedBlockUnsafe", func(tx *dbutil.Tx) error {
 		return vs.executeSignedBlockUnsafe(tx, b)
-	})
+	}); err != nil {
+		return err
+	}
+
+	vs.addressSubscriptions.notifyBlock(b.Block)
+
+	return nil
 }
 
 // executeSignedBlock adds a block to the blockchain, or returns error.
@@ -466,7 +628,177 @@ func (vs *Visor) executeSignedBlockUnsafe(tx *dbutil.Tx, b coin.SignedBlock) err
 	}
 
 	// Update the HistoryDB
-	return vs.history.ParseBlock(tx, b.Block)
+	if err := vs.history.ParseBlock(tx, b.Block); err != nil {
+		return err
+	}
+
+	if vs.Config.EnableEventLog {
+		if err := vs.eventLog.logBlock(tx, b.Block); err != nil {
+			return err
+		}
+	}
+
+	size, err := b.Block.Size()
+	if err != nil {
+		return err
+	}
+
+	if err := addBlockSize(tx, b.Block.Seq(), size, vs.Config.BlockSizeHistogramBuckets); err != nil {
+		return err
+	}
+
+	// Blocks left to prune by Init are pruned one batch per executed block
+	_, err = vs.pruneBlocks(tx)
+	return err
+}
+
+// pruneBatchSize is the maximum number of blocks pruned in one db transaction
+var pruneBatchSize uint64 = 1000
+
+// pruneBlocks removes the transactions of the blocks older than Config.PruneOlderThanBlocks, and their
+// transactions and spent outputs from the history database. At most pruneBatchSize blocks are pruned,
+// returns true if older blocks remain to be pruned.
+func (vs *Visor) pruneBlocks(tx *dbutil.Tx) (bool, error) {
+	n := vs.Config.PruneOlderThanBlocks
+	if n == 0 {
+		return false, nil
+	}
+
+	headSeq, ok, err := vs.blockchain.HeadSeq(tx)
+	if err != nil {
+		return false, err
+	}
+
+	if !ok || headSeq <= n {
+		return false, nil
+	}
+
+	// The genesis block is never pruned
+	prunedSeq, _, err := vs.blockchain.PrunedSeq(tx)
+	if err != nil {
+		return false, err
+	}
+
+	seq := headSeq - n
+	if seq <= prunedSeq {
+		return false, nil
+	}
+
+	more := false
+	if seq-prunedSeq > pruneBatchSize {
+		seq = prunedSeq + pruneBatchSize
+		more = true
+	}
+
+	for i := prunedSeq + 1; i <= seq; i++ {
+		b, err := vs.blockchain.GetSignedBlockBySeq(tx, i)
+		if err != nil {
+			return false, err
+		}
+		if b == nil {
+			return false, fmt.Errorf("pruneBlocks: block seq=%d not found", i)
+		}
+
+		if err := vs.history.PruneBlock(tx, b.Block); err != nil {
+			return false, err
+		}
+	}
+
+	if err := vs.blockchain.PruneBlocks(tx, seq); err != nil {
+		return false, err
+	}
+
+	return more, nil
+}
+
+// pruneOldBlocks prunes the blocks older than Config.PruneOlderThanBlocks, in a separate
+// db transaction for each batch of pruneBatchSize blocks
+func (vs *Visor) pruneOldBlocks() error {
+	for {
+		var more bool
+		if err := vs.db.Update("pruneOldBlocks", func(tx *dbutil.Tx) error {
+			var err error
+			more, err = vs.pruneBlocks(tx)
+			if err != nil || !more {
+				return err
+			}
+
+			prunedSeq, _, err := vs.blockchain.PrunedSeq(tx)
+			if err != nil {
+				return err
+			}
+			logger.Infof("Pruned the blocks up to %d", prunedSeq)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if !more {
+			return nil
+		}
+	}
+}
+
+// checkPruned returns ErrBlockPruned if the transactions of the block at seq have been pruned
+func (vs *Visor) checkPruned(tx *dbutil.Tx, seq uint64) error {
+	if vs.Config.PruneOlderThanBlocks == 0 {
+		return nil
+	}
+
+	return checkPruned(tx, vs.blockchain, seq)
+}
+
+// checkBlocksPruned returns ErrBlockPruned if the transactions of any of the blocks have been pruned
+func (vs *Visor) checkBlocksPruned(tx *dbutil.Tx, blocks []coin.SignedBlock) error {
+	for _, b := range blocks {
+		if err := vs.checkPruned(tx, b.Seq()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkPruned(tx *dbutil.Tx, bc Blockchainer, seq uint64) error {
+	// The genesis block is never pruned
+	if seq == 0 {
+		return nil
+	}
+
+	prunedSeq, ok, err := bc.PrunedSeq(tx)
+	if err != nil {
+		return err
+	}
+
+	if ok && seq <= prunedSeq {
+		return ErrBlockPruned{Seq: seq}
+	}
+
+	return nil
+}
+
+// checkHistoryPruned returns ErrBlockPruned with the sequence of the most recent pruned block if the blockchain
+// has been pruned, otherwise err. It is used when a transaction or output is not found in the history database,
+// which can't tell whether it was removed with a pruned block or never existed.
+func checkHistoryPruned(tx *dbutil.Tx, bc Blockchainer, err error) error {
+	prunedSeq, ok, pErr := bc.PrunedSeq(tx)
+	if pErr != nil {
+		return pErr
+	}
+
+	if ok {
+		return ErrBlockPruned{Seq: prunedSeq}
+	}
+
+	return err
+}
+
+// checkHistoryUxOutsPruned returns ErrBlockPruned in place of historydb.ErrUxOutNotExist if the blockchain has been pruned
+func checkHistoryUxOutsPruned(tx *dbutil.Tx, bc Blockchainer, err error) error {
+	if _, ok := err.(historydb.ErrUxOutNotExist); ok {
+		return checkHistoryPruned(tx, bc, err)
+	}
+	return err
 }
 
 // signBlock signs a block for a block publisher node. Will panic if anything is invalid
@@ -602,11 +934,16 @@ func (vs *Visor) GetSignedBlocksSince(seq, ct uint64) ([]coin.SignedBlock, error
 			blocks = append(blocks, *b)
 		}
 
-		return nil
+		return vs.checkBlocksPruned(tx, blocks)
 	}); err != nil {
 		return nil, err
 	}
 
+	// A syncing peer requests the following blocks next
+	if len(blocks) != 0 {
+		vs.blockchain.Prefetch(seq+ct+1, seq+2*ct)
+	}
+
 	return blocks, nil
 }
 
@@ -627,6 +964,11 @@ func (vs *Visor) HeadBkSeq() (uint64, bool, error) {
 	return headSeq, ok, nil
 }
 
+// GetDBStats returns the internal statistics of the database
+func (vs *Visor) GetDBStats() (*dbutil.DBStats, error) {
+	return vs.db.Stats()
+}
+
 // GetBlockchainMetadata returns descriptive blockchain information
 func (vs *Visor) GetBlockchainMetadata() (*BlockchainMetadata, error) {
 	var head *coin.SignedBlock
@@ -667,6 +1009,10 @@ func (vs *Visor) GetBlock(seq uint64) (*coin.SignedBlock, error) {
 			return errors.New("Block seq out of range")
 		}
 
+		if err := vs.checkPruned(tx, seq); err != nil {
+			return err
+		}
+
 		b, err = vs.blockchain.GetSignedBlockBySeq(tx, seq)
 		return err
 	}); err != nil {
@@ -683,7 +1029,11 @@ func (vs *Visor) GetBlocks(seqs []uint64) ([]coin.SignedBlock, error) {
 	if err := vs.db.View("GetBlocks", func(tx *dbutil.Tx) error {
 		var err error
 		blocks, err = vs.blockchain.GetBlocks(tx, seqs)
-		return err
+		if err != nil {
+			return err
+		}
+
+		return vs.checkBlocksPruned(tx, blocks)
 	}); err != nil {
 		return nil, err
 	}
@@ -717,7 +1067,11 @@ func (vs *Visor) GetBlocksInRange(start, end uint64) ([]coin.SignedBlock, error)
 	if err := vs.db.View("GetBlocksInRange", func(tx *dbutil.Tx) error {
 		var err error
 		blocks, err = vs.blockchain.GetBlocksInRange(tx, start, end)
-		return err
+		if err != nil {
+			return err
+		}
+
+		return vs.checkBlocksPruned(tx, blocks)
 	}); err != nil {
 		return nil, err
 	}
@@ -725,6 +1079,22 @@ func (vs *Visor) GetBlocksInRange(start, end uint64) ([]coin.SignedBlock, error)
 	return blocks, nil
 }
 
+// ForEachBlock calls fn on each block in height order, from the genesis block to the head block.
+// The blocks are read from the DB one at a time within a single DB transaction, so all blocks
+// can be processed with constant memory. If fn returns an error, the iteration stops and the error is returned.
+// Returns ErrBlockPruned when reaching a block whose transactions have been pruned.
+func (vs *Visor) ForEachBlock(fn func(block coin.SignedBlock) error) error {
+	return vs.db.View("ForEachBlock", func(tx *dbutil.Tx) error {
+		return vs.blockchain.ForEachSignedBlock(tx, func(b *coin.SignedBlock) error {
+			if err := vs.checkPruned(tx, b.Seq()); err != nil {
+				return err
+			}
+
+			return fn(*b)
+		})
+	})
+}
+
 // GetBlocksInRangeVerbose returns multiple blocks between start and end, including both start and end.
 // Also returns the verbose transaction input data for transactions in these blocks.
 // Returns the empty slice if unable to fulfill request.
@@ -752,7 +1122,11 @@ func (vs *Visor) GetLastBlocks(num uint64) ([]coin.SignedBlock, error) {
 	if err := vs.db.View("GetLastBlocks", func(tx *dbutil.Tx) error {
 		var err error
 		blocks, err = vs.blockchain.GetLastBlocks(tx, num)
-		return err
+		if err != nil {
+			return err
+		}
+
+		return vs.checkBlocksPruned(tx, blocks)
 	}); err != nil {
 		return nil, err
 	}
@@ -784,6 +1158,10 @@ func (vs *Visor) getBlocksVerbose(tx *dbutil.Tx, getBlocks func(*dbutil.Tx) ([]c
 		return nil, nil, err
 	}
 
+	if err := vs.checkBlocksPruned(tx, blocks); err != nil {
+		return nil, nil, err
+	}
+
 	if len(blocks) == 0 {
 		return nil, nil, nil
 	}
@@ -818,6 +1196,10 @@ func (vs *Visor) InjectForeignTransaction(txn coin.Transaction) (bool, *ErrTxnVi
 		return false, nil, err
 	}
 
+	if !known {
+		vs.addressSubscriptions.notify(txn, false, 0)
+	}
+
 	return known, softErr, nil
 }
 
@@ -838,6 +1220,10 @@ func (vs *Visor) InjectUserTransaction(txn coin.Transaction) (bool, *coin.Signed
 		return false, nil, nil, err
 	}
 
+	if !known {
+		vs.addressSubscriptions.notify(txn, false, 0)
+	}
+
 	return known, head, inputs, nil
 }
 
@@ -846,6 +1232,7 @@ func (vs *Visor) InjectUserTransaction(txn coin.Transaction) (bool, *coin.Signed
 // The bool return value is whether or not the transaction was already in the pool.
 // If the transaction violates hard or soft constraints, it is rejected, and error will not be nil.
 // This method is only exported for use by the daemon gateway's InjectBroadcastTransaction method.
+// The caller must call NotifyUnconfirmedTransaction after the database transaction is committed, if the txn was not known.
 func (vs *Visor) InjectUserTransactionTx(tx *dbutil.Tx, txn coin.Transaction) (bool, *coin.SignedBlock, coin.UxArray, error) {
 	if err := VerifySingleTxnUserConstraints(txn); err != nil {
 		return false, nil, nil, err
@@ -933,7 +1320,7 @@ func (vs *Visor) getTransaction(tx *dbutil.Tx, txnHash cipher.SHA256) (*Transact
 	}
 
 	if htxn == nil {
-		return nil, nil
+		return nil, checkHistoryPruned(tx, vs.blockchain, nil)
 	}
 
 	headSeq, ok, err := vs.blockchain.HeadSeq(tx)
@@ -1274,7 +1661,11 @@ func (vs *Visor) GetConfirmedTransaction(txnHash cipher.SHA256) (*coin.Transacti
 	if err := vs.db.View("GetConfirmedTransaction", func(tx *dbutil.Tx) error {
 		var err error
 		histTxn, err = vs.history.GetTransaction(tx, txnHash)
-		return err
+		if err != nil || histTxn != nil {
+			return err
+		}
+
+		return checkHistoryPruned(tx, vs.blockchain, nil)
 	}); err != nil {
 		return nil, err
 	}
@@ -1294,7 +1685,11 @@ func (vs *Visor) GetSignedBlockByHash(hash cipher.SHA256) (*coin.SignedBlock, er
 	if err := vs.db.View("GetSignedBlockByHash", func(tx *dbutil.Tx) error {
 		var err error
 		sb, err = vs.blockchain.GetSignedBlockByHash(tx, hash)
-		return err
+		if err != nil || sb == nil {
+			return err
+		}
+
+		return vs.checkPruned(tx, sb.Seq())
 	}); err != nil {
 		return nil, err
 	}
@@ -1309,7 +1704,11 @@ func (vs *Visor) GetSignedBlockBySeq(seq uint64) (*coin.SignedBlock, error) {
 	if err := vs.db.View("GetSignedBlockBySeq", func(tx *dbutil.Tx) error {
 		var err error
 		b, err = vs.blockchain.GetSignedBlockBySeq(tx, seq)
-		return err
+		if err != nil || b == nil {
+			return err
+		}
+
+		return vs.checkPruned(tx, b.Seq())
 	}); err != nil {
 		return nil, err
 	}
@@ -1363,6 +1762,10 @@ func (vs *Visor) getBlockVerbose(tx *dbutil.Tx, getBlock func(*dbutil.Tx) (*coin
 		return nil, nil, nil
 	}
 
+	if err := vs.checkPruned(tx, b.Seq()); err != nil {
+		return nil, nil, err
+	}
+
 	inputs, err := vs.getBlockInputs(tx, b)
 	if err != nil {
 		return nil, nil, err
@@ -1494,11 +1897,7 @@ func (vs Visor) GetUxOutByID(id cipher.SHA256) (*historydb.UxOut, uint64, error)
 		headTime = head.Time()
 
 		outs, err = vs.history.GetUxOuts(tx, []cipher.SHA256{id})
-		if err != nil {
-			return err
-		}
-
-		return err
+		return checkHistoryUxOutsPruned(tx, vs.blockchain, err)
 	}); err != nil {
 		return nil, 0, err
 	}
@@ -1530,7 +1929,7 @@ func (vs Visor) GetSpentOutputsForAddresses(addresses []cipher.Address) ([][]his
 		for i, addr := range addresses {
 			addrUxOuts, err := vs.history.GetOutputsForAddress(tx, addr)
 			if err != nil {
-				return err
+				return checkHistoryUxOutsPruned(tx, vs.blockchain, err)
 			}
 
 			out[i] = addrUxOuts
@@ -1544,6 +1943,89 @@ func (vs Visor) GetSpentOutputsForAddresses(addresses []cipher.Address) ([][]his
 	return out, headTime, nil
 }
 
+// GetAddressBalanceAt returns the confirmed balance of an address after the block at seq was executed.
+// The coin hours are calculated at the time of that block. Computed balances of addresses with outputs
+// are cached in the db, up to historydb.MaxAddressBalanceSnapshots balances, unless the db is opened read-only.
+// Returns nil if the block at seq does not exist.
+func (vs *Visor) GetAddressBalanceAt(addr cipher.Address, seq uint64) (*historydb.AddressBalance, error) {
+	var b *historydb.AddressBalance
+	var cached bool
+
+	if err := vs.db.View("GetAddressBalanceAt", func(tx *dbutil.Tx) error {
+		block, err := vs.blockchain.GetSignedBlockBySeq(tx, seq)
+		if err != nil || block == nil {
+			return err
+		}
+
+		if !vs.db.IsReadOnly() {
+			b, err = vs.history.GetAddressBalanceSnapshot(tx, addr, seq)
+			if err != nil {
+				return err
+			}
+			if b != nil {
+				cached = true
+				return nil
+			}
+		}
+
+		balance, err := vs.history.GetAddressBalanceAt(tx, addr, seq, block.Time())
+		if err != nil {
+			return checkHistoryUxOutsPruned(tx, vs.blockchain, err)
+		}
+
+		b = &balance
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if b == nil || cached || vs.db.IsReadOnly() {
+		return b, nil
+	}
+
+	if err := vs.db.Update("GetAddressBalanceAt", func(tx *dbutil.Tx) error {
+		return vs.history.SetAddressBalanceSnapshot(tx, addr, seq, *b)
+	}); err != nil {
+		logger.WithError(err).Error("SetAddressBalanceSnapshot failed")
+	}
+
+	return b, nil
+}
+
+// ErrIndexNotAvailable is returned if the address transaction index of the history does not cover the blockchain
+var ErrIndexNotAvailable = errors.New("address transaction index is not available")
+
+// GetAddressTransactionCount returns the number of confirmed transactions of an address,
+// read from the address transaction index of the history without loading the transactions.
+// Returns ErrIndexNotAvailable if the history has not been parsed up to the blockchain head.
+func (vs *Visor) GetAddressTransactionCount(addr cipher.Address) (uint64, error) {
+	var n uint64
+	if err := vs.db.View("GetAddressTransactionCount", func(tx *dbutil.Tx) error {
+		headSeq, ok, err := vs.blockchain.HeadSeq(tx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+
+		parsedSeq, ok, err := vs.history.ParsedBlockSeq(tx)
+		if err != nil {
+			return err
+		}
+		if !ok || parsedSeq != headSeq {
+			return ErrIndexNotAvailable
+		}
+
+		n, err = vs.history.AddressTransactionCount(tx, addr)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
 // RecvOfAddresses returns unconfirmed receiving uxouts of addresses
 func (vs *Visor) RecvOfAddresses(addrs []cipher.Address) (coin.AddressUxOuts, error) {
 	var uxouts coin.AddressUxOuts
@@ -1851,7 +2333,7 @@ func (vs *Visor) VerifyTxnVerbose(txn *coin.Transaction, signed TxnSignedFlag) (
 			// Gets uxouts of txn.In from historydb
 			outs, err := vs.history.GetUxOuts(tx, txn.In)
 			if err != nil {
-				return err
+				return checkHistoryUxOutsPruned(tx, vs.blockchain, err)
 			}
 
 			if len(outs) == 0 {
@@ -2142,44 +2624,79 @@ func (vs *Visor) GetUnspentOutputsSummary(filters []OutputsFilter) (*UnspentOutp
 	}, nil
 }
 
-// GetRichlist returns a Richlist
+// GetRichlist returns a Richlist of the confirmed unspent outputs, from the highest balance to the lowest.
+// The richlist is computed once per head block.
 func (vs *Visor) GetRichlist(includeDistribution bool) (Richlist, error) {
-	rbOuts, err := vs.GetUnspentOutputsSummary(nil)
+	richlist, err := vs.headRichlist()
 	if err != nil {
 		return nil, err
 	}
 
-	// Build a map from addresses to total coins held
-	allAccounts := map[cipher.Address]uint64{}
-	for _, out := range rbOuts.Confirmed {
-		if _, ok := allAccounts[out.Body.Address]; ok {
-			var err error
-			allAccounts[out.Body.Address], err = mathutil.AddUint64(allAccounts[out.Body.Address], out.Body.Coins)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			allAccounts[out.Body.Address] = out.Body.Coins
-		}
+	if includeDistribution {
+		// Copy the cached richlist so that it can't be modified by the caller
+		return append(Richlist{}, richlist...), nil
 	}
 
-	lockedAddrs := vs.Config.Distribution.LockedAddressesDecoded()
-	addrsMap := make(map[cipher.Address]struct{}, len(lockedAddrs))
-	for _, a := range lockedAddrs {
+	distributionAddrs := vs.Config.Distribution.AddressesDecoded()
+	addrsMap := make(map[cipher.Address]struct{}, len(distributionAddrs))
+	for _, a := range distributionAddrs {
 		addrsMap[a] = struct{}{}
 	}
 
-	richlist, err := NewRichlist(allAccounts, addrsMap)
-	if err != nil {
-		return nil, err
-	}
+	return richlist.FilterAddresses(addrsMap), nil
+}
+
+// headRichlist returns the richlist of the head block, including the distribution addresses.
+// The richlist is cached until the head block changes
+func (vs *Visor) headRichlist() (Richlist, error) {
+	vs.richlistCache.Lock()
+	defer vs.richlistCache.Unlock()
 
-	if !includeDistribution {
-		unlockedAddrs := vs.Config.Distribution.UnlockedAddressesDecoded()
-		for _, a := range unlockedAddrs {
-			addrsMap[a] = struct{}{}
+	var richlist Richlist
+	if err := vs.db.View("headRichlist", func(tx *dbutil.Tx) error {
+		headSeq, ok, err := vs.blockchain.HeadSeq(tx)
+		if err != nil {
+			return err
+		}
+
+		if ok && vs.richlistCache.richlist != nil && vs.richlistCache.headSeq == headSeq {
+			richlist = vs.richlistCache.richlist
+			return nil
 		}
-		richlist = richlist.FilterAddresses(addrsMap)
+
+		uxa, err := vs.blockchain.Unspent().GetAll(tx)
+		if err != nil {
+			return fmt.Errorf("vs.blockchain.Unspent().GetAll failed: %v", err)
+		}
+
+		// Build a map from addresses to total coins held
+		allAccounts := map[cipher.Address]uint64{}
+		for _, ux := range uxa {
+			allAccounts[ux.Body.Address], err = mathutil.AddUint64(allAccounts[ux.Body.Address], ux.Body.Coins)
+			if err != nil {
+				return err
+			}
+		}
+
+		lockedAddrs := vs.Config.Distribution.LockedAddressesDecoded()
+		lockedAddrsMap := make(map[cipher.Address]struct{}, len(lockedAddrs))
+		for _, a := range lockedAddrs {
+			lockedAddrsMap[a] = struct{}{}
+		}
+
+		richlist, err = NewRichlist(allAccounts, lockedAddrsMap)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			vs.richlistCache.headSeq = headSeq
+			vs.richlistCache.richlist = richlist
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 
 	return richlist, nil
@@ -13,6 +13,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/boltdb/bolt"
 	"github.com/skycoin/skycoin/src/wallet"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -164,7 +165,7 @@ func TestErrMissingSignatureRecreateDB(t *testing.T) {
 	require.NotEmpty(t, badDB.Path())
 	t.Logf("badDB.Path() == %s", badDB.Path())
 
-	db, err := ResetCorruptDB(badDB, pubkey, nil)
+	db, err := ResetCorruptDB(badDB, pubkey, nil, nil)
 	require.NoError(t, err)
 
 	err = db.Close()
@@ -261,6 +262,152 @@ func TestHistorydbVerifier(t *testing.T) {
 
 }
 
+func TestDescribeCorruption(t *testing.T) {
+	historyPubkey := cipher.MustPubKeyFromHex("0328c576d3f420e7682058a981173a4b374c7cc5ff55bf394d3cf57059bbe6456a")
+
+	tt := []struct {
+		name   string
+		dbPath string
+		pubkey cipher.PubKey
+		bucket string
+		key    string
+		errT   error
+	}{
+		{
+			name:   "db is ok",
+			dbPath: "./testdata/data.db.ok",
+			pubkey: historyPubkey,
+		},
+		{
+			name:   "missing signature",
+			dbPath: "./testdata/data.db.nosig",
+			pubkey: mustParsePubkey(t),
+			bucket: "block_sigs",
+			errT:   blockdb.ErrMissingSignature{},
+		},
+		{
+			name:   "missing transaction",
+			dbPath: "./testdata/data.db.notxn",
+			pubkey: historyPubkey,
+			bucket: "transactions",
+			key:    "98db7eb30e13853d3dd93d5d8b4061596d5d288b6f8b92c4d43c46c6599f67fb",
+			errT:   historydb.ErrHistoryDBCorrupted{},
+		},
+		{
+			name:   "missing uxout",
+			dbPath: "./testdata/data.db.nouxout",
+			pubkey: historyPubkey,
+			bucket: "uxouts",
+			key:    "2f87d77c2a7d00b547db1af50e0ba04bafc5b05711e4939e9ec2640a21127dc0",
+			errT:   historydb.ErrHistoryDBCorrupted{},
+		},
+		{
+			name:   "missing addr transaction index",
+			dbPath: "./testdata/data.db.no-addr-txn-index",
+			pubkey: historyPubkey,
+			bucket: "address_txns",
+			key:    "2fGC7kwAM9yZyEF1QqBqp8uo9RUsF6ENGJF",
+			errT:   historydb.ErrHistoryDBCorrupted{},
+		},
+		{
+			name:   "missing addr uxout index",
+			dbPath: "./testdata/data.db.no-addr-uxout-index",
+			pubkey: historyPubkey,
+			bucket: "address_in",
+			key:    "2fGC7kwAM9yZyEF1QqBqp8uo9RUsF6ENGJF",
+			errT:   historydb.ErrHistoryDBCorrupted{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			data := readAll(t, tc.dbPath)
+
+			db, err := OpenDB(tc.dbPath, true)
+			require.NoError(t, err)
+
+			descs, err := DescribeCorruption(db, tc.pubkey, nil, nil)
+			require.NoError(t, err)
+			require.NoError(t, db.Close())
+
+			// The database is not changed or moved
+			require.Equal(t, data, readAll(t, tc.dbPath))
+			require.Empty(t, findCorruptDBFiles(t, tc.dbPath))
+
+			if tc.errT == nil {
+				require.Empty(t, descs)
+				return
+			}
+
+			require.Len(t, descs, 1)
+			require.Equal(t, tc.bucket, descs[0].Bucket)
+			require.IsType(t, tc.errT, descs[0].Err)
+			if tc.key != "" {
+				require.Equal(t, tc.key, descs[0].Key)
+			} else {
+				require.Len(t, descs[0].Key, 64)
+			}
+		})
+	}
+
+	// Errors that don't cause a reset are returned
+	db, err := OpenDB("./testdata/data.db.ok", true)
+	require.NoError(t, err)
+	defer db.Close()
+
+	wrongPubkey, _ := cipher.GenerateKeyPair()
+	descs, err := DescribeCorruption(db, wrongPubkey, nil, nil)
+	require.Error(t, err)
+	require.Empty(t, descs)
+}
+
+func TestVisorStartCorruptionCheck(t *testing.T) {
+	db, err := OpenDB("./testdata/data.db.ok", true)
+	require.NoError(t, err)
+	defer db.Close()
+
+	v := &Visor{
+		Config: Config{
+			BlockchainPubkey: cipher.MustPubKeyFromHex("0328c576d3f420e7682058a981173a4b374c7cc5ff55bf394d3cf57059bbe6456a"),
+		},
+		db:                db,
+		corruptionChecker: &corruptionChecker{},
+	}
+
+	require.Nil(t, v.GetCorruptionCheck())
+
+	// A second check is rejected while one is running
+	v.corruptionChecker.check = &CorruptionCheck{
+		Running: true,
+	}
+	err = v.StartCorruptionCheck()
+	require.Equal(t, ErrCorruptionCheckRunning, err)
+
+	// The check can run again once the running check finished
+	v.corruptionChecker.check.Running = false
+	err = v.StartCorruptionCheck()
+	require.NoError(t, err)
+
+	c := v.GetCorruptionCheck()
+	require.NotNil(t, c)
+	require.False(t, c.StartedAt.IsZero())
+
+	<-v.corruptionChecker.done
+
+	c = v.GetCorruptionCheck()
+	require.False(t, c.Running)
+	require.False(t, c.FinishedAt.IsZero())
+	require.NoError(t, c.Err)
+	require.Empty(t, c.Descriptions)
+
+	// The snapshot is removed after the check
+	_, err = os.Stat(db.Path() + corruptionCheckSuffix)
+	require.True(t, os.IsNotExist(err))
+
+	// Stopping a finished check does nothing
+	v.StopCorruptionCheck()
+}
+
 func TestVisorCreateBlock(t *testing.T) {
 	when := uint64(time.Now().UTC().Unix())
 
@@ -271,7 +418,7 @@ func TestVisorCreateBlock(t *testing.T) {
 		Pubkey: genPublic,
 	})
 
-	unconfirmed, err := NewUnconfirmedTransactionPool(db)
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolConfig{})
 	require.NoError(t, err)
 
 	his := historydb.New()
@@ -282,11 +429,12 @@ func TestVisorCreateBlock(t *testing.T) {
 	cfg.GenesisAddress = genAddress
 
 	v := &Visor{
-		Config:      cfg,
-		unconfirmed: unconfirmed,
-		blockchain:  bc,
-		db:          db,
-		history:     his,
+		Config:        cfg,
+		unconfirmed:   unconfirmed,
+		blockchain:    bc,
+		db:            db,
+		history:       his,
+		blockProducer: DefaultBlockProducer{},
 	}
 
 	// CreateBlock panics if called when not a block publisher
@@ -496,7 +644,7 @@ func TestVisorInjectTransaction(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	unconfirmed, err := NewUnconfirmedTransactionPool(db)
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolConfig{})
 	require.NoError(t, err)
 
 	his := historydb.New()
@@ -507,11 +655,12 @@ func TestVisorInjectTransaction(t *testing.T) {
 	cfg.GenesisAddress = genAddress
 
 	v := &Visor{
-		Config:      cfg,
-		unconfirmed: unconfirmed,
-		blockchain:  bc,
-		db:          db,
-		history:     his,
+		Config:        cfg,
+		unconfirmed:   unconfirmed,
+		blockchain:    bc,
+		db:            db,
+		history:       his,
+		blockProducer: DefaultBlockProducer{},
 	}
 
 	// CreateBlock panics if called when not a block publisher
@@ -1979,7 +2128,7 @@ func TestRefreshUnconfirmed(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	unconfirmed, err := NewUnconfirmedTransactionPool(db)
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolConfig{})
 	require.NoError(t, err)
 
 	his := historydb.New()
@@ -1991,11 +2140,12 @@ func TestRefreshUnconfirmed(t *testing.T) {
 	cfg.GenesisAddress = genAddress
 
 	v := &Visor{
-		Config:      cfg,
-		unconfirmed: unconfirmed,
-		blockchain:  bc,
-		db:          db,
-		history:     his,
+		Config:        cfg,
+		unconfirmed:   unconfirmed,
+		blockchain:    bc,
+		db:            db,
+		history:       his,
+		blockProducer: DefaultBlockProducer{},
 	}
 
 	addGenesisBlockToVisor(t, v)
@@ -2110,7 +2260,7 @@ func TestRemoveInvalidUnconfirmedDoubleSpendArbitrating(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	unconfirmed, err := NewUnconfirmedTransactionPool(db)
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolConfig{})
 	require.NoError(t, err)
 
 	his := historydb.New()
@@ -2123,11 +2273,12 @@ func TestRemoveInvalidUnconfirmedDoubleSpendArbitrating(t *testing.T) {
 	cfg.BlockchainSeckey = genSecret
 
 	v := &Visor{
-		Config:      cfg,
-		unconfirmed: unconfirmed,
-		blockchain:  bc,
-		db:          db,
-		history:     his,
+		Config:        cfg,
+		unconfirmed:   unconfirmed,
+		blockchain:    bc,
+		db:            db,
+		history:       his,
+		blockProducer: DefaultBlockProducer{},
 	}
 
 	addGenesisBlockToVisor(t, v)
@@ -2383,6 +2534,7 @@ func TestVerifyTxnVerbose(t *testing.T) {
 		err         error
 
 		maxUserTransactionSize uint32
+		minUserFeePerByte      uint64
 
 		getArrayRet coin.UxArray
 		getArrayErr error
@@ -2481,6 +2633,16 @@ func TestVerifyTxnVerbose(t *testing.T) {
 
 			getArrayRet: inputs[:1],
 		},
+		{
+			name:              "transaction violate soft constraints, insufficient fee per byte",
+			signed:            TxnSigned,
+			minUserFeePerByte: 1e9,
+			txn:               txn,
+			inputs:            spentInputs[:],
+			err:               ErrTxnViolatesSoftConstraint{fee.ErrTxnInsufficientFeePerByte},
+
+			getArrayRet: inputs[:1],
+		},
 		{
 			name:        "transaction violate soft constraints, Insufficient coinhours for transaction outputs",
 			signed:      TxnSigned,
@@ -2639,13 +2801,18 @@ func TestVerifyTxnVerbose(t *testing.T) {
 			}
 
 			originalMaxUnconfirmedTxnSize := params.UserVerifyTxn.MaxTransactionSize
+			originalMinUserFeePerByte := params.UserVerifyTxn.MinFeePerByte
 			defer func() {
 				params.UserVerifyTxn.MaxTransactionSize = originalMaxUnconfirmedTxnSize
+				params.UserVerifyTxn.MinFeePerByte = originalMinUserFeePerByte
 			}()
 
 			if tc.maxUserTransactionSize != 0 {
 				params.UserVerifyTxn.MaxTransactionSize = tc.maxUserTransactionSize
 			}
+			if tc.minUserFeePerByte != 0 {
+				params.UserVerifyTxn.MinFeePerByte = tc.minUserFeePerByte
+			}
 
 			var isConfirmed bool
 			var inputs []TransactionInput
@@ -2926,3 +3093,489 @@ func TestTransactionsFinder(t *testing.T) {
 		})
 	}
 }
+
+func TestVisorPruneBlocks(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolConfig{})
+	require.NoError(t, err)
+
+	his := historydb.New()
+
+	cfg := NewConfig()
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+	cfg.Distribution = params.MainNetDistribution
+	cfg.PruneOlderThanBlocks = 2
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     his,
+		txns: &transactionModel{
+			history:     his,
+			unconfirmed: unconfirmed,
+			blockchain:  bc,
+		},
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	// Create a chain of blocks, each spending the output of the previous block's transaction
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	var txns []coin.Transaction
+	for i := 1; i <= 5; i++ {
+		txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, uxs[0].Body.Coins)
+		txns = append(txns, txn)
+
+		err := db.Update("", func(tx *dbutil.Tx) error {
+			b, err := bc.NewBlock(tx, coin.Transactions{txn}, genTime+uint64(i)*100)
+			require.NoError(t, err)
+
+			sb := coin.SignedBlock{
+				Block: *b,
+				Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+			}
+			if err := v.executeSignedBlock(tx, sb); err != nil {
+				return err
+			}
+
+			uxs = coin.CreateUnspents(b.Head, txn)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	// The transactions of blocks 1 to 3 are pruned
+	err = db.View("", func(tx *dbutil.Tx) error {
+		prunedSeq, ok, err := bc.PrunedSeq(tx)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, uint64(3), prunedSeq)
+
+		for i := uint64(0); i <= 5; i++ {
+			b, err := bc.GetSignedBlockBySeq(tx, i)
+			require.NoError(t, err)
+			require.NotNil(t, b)
+			require.NoError(t, b.VerifySignature(genPublic))
+
+			if i == 0 || i > 3 {
+				require.Len(t, b.Body.Transactions, 1)
+			} else {
+				require.Empty(t, b.Body.Transactions)
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, err = v.GetSignedBlockBySeq(2)
+	require.Equal(t, ErrBlockPruned{Seq: 2}, err)
+
+	_, err = v.GetBlocksInRange(0, 5)
+	require.Equal(t, ErrBlockPruned{Seq: 1}, err)
+
+	var seqs []uint64
+	err = v.ForEachBlock(func(b coin.SignedBlock) error {
+		seqs = append(seqs, b.Seq())
+		return nil
+	})
+	require.Equal(t, ErrBlockPruned{Seq: 1}, err)
+	require.Equal(t, []uint64{0}, seqs)
+
+	_, err = v.GetSignedBlocksSince(2, 3)
+	require.Equal(t, ErrBlockPruned{Seq: 3}, err)
+
+	b, err := v.GetSignedBlockBySeq(0)
+	require.NoError(t, err)
+	require.Equal(t, *gb, *b)
+
+	blocks, err := v.GetLastBlocks(2)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+	require.Equal(t, uint64(4), blocks[0].Seq())
+
+	// The transactions and spent outputs of pruned blocks are removed from the history database
+	_, err = v.GetTransaction(txns[0].Hash())
+	require.Equal(t, ErrBlockPruned{Seq: 3}, err)
+
+	_, _, err = v.GetTransactions([]TxFilter{NewAddrsFilter([]cipher.Address{genAddress})}, AscOrder, nil)
+	require.Equal(t, ErrBlockPruned{Seq: 3}, err)
+
+	_, _, err = v.GetUxOutByID(txns[1].In[0])
+	require.Equal(t, ErrBlockPruned{Seq: 3}, err)
+
+	_, _, err = v.GetSpentOutputsForAddresses([]cipher.Address{genAddress})
+	require.Equal(t, ErrBlockPruned{Seq: 3}, err)
+
+	// The output created by the last pruned block is kept, it is spent by a block that is not pruned
+	out, _, err := v.GetUxOutByID(txns[3].In[0])
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), out.Out.Head.BkSeq)
+	require.Equal(t, txns[3].Hash(), out.SpentTxnID)
+
+	txn, err := v.GetTransaction(txns[3].Hash())
+	require.NoError(t, err)
+	require.Equal(t, txns[3], txn.Transaction)
+
+	txn, err = v.GetTransaction(txns[4].Hash())
+	require.NoError(t, err)
+	require.Equal(t, txns[4], txn.Transaction)
+
+	// The genesis block is never pruned
+	txn, err = v.GetTransaction(gb.Body.Transactions[0].Hash())
+	require.NoError(t, err)
+	require.Equal(t, gb.Body.Transactions[0], txn.Transaction)
+
+	// The pruned database passes the database check
+	require.NoError(t, CheckDatabase(db, genPublic, nil, nil))
+
+	// The pruned database can't be opened by an archival node
+	cfg.PruneOlderThanBlocks = 0
+	_, err = New(cfg, db, nil)
+	require.Equal(t, errors.New("The database has been pruned up to block 3 and cannot be used by an archival node"), err)
+}
+
+func TestVisorPruneOldBlocks(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolConfig{})
+	require.NoError(t, err)
+
+	his := historydb.New()
+
+	cfg := NewConfig()
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+	cfg.Distribution = params.MainNetDistribution
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     his,
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	// Execute blocks without pruning
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	for i := 1; i <= 6; i++ {
+		txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, uxs[0].Body.Coins)
+
+		err := db.Update("", func(tx *dbutil.Tx) error {
+			b, err := bc.NewBlock(tx, coin.Transactions{txn}, genTime+uint64(i)*100)
+			require.NoError(t, err)
+
+			uxs = coin.CreateUnspents(b.Head, txn)
+			return v.executeSignedBlock(tx, coin.SignedBlock{
+				Block: *b,
+				Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+			})
+		})
+		require.NoError(t, err)
+	}
+
+	defer func(n uint64) {
+		pruneBatchSize = n
+	}(pruneBatchSize)
+	pruneBatchSize = 2
+
+	prunedSeq := func() uint64 {
+		var seq uint64
+		err := db.View("", func(tx *dbutil.Tx) error {
+			var err error
+			seq, _, err = bc.PrunedSeq(tx)
+			return err
+		})
+		require.NoError(t, err)
+		return seq
+	}
+
+	// Turning pruning on for an existing database prunes at most pruneBatchSize blocks per db transaction
+	v.Config.PruneOlderThanBlocks = 1
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		more, err := v.pruneBlocks(tx)
+		require.NoError(t, err)
+		require.True(t, more)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), prunedSeq())
+
+	require.NoError(t, v.pruneOldBlocks())
+	require.Equal(t, uint64(5), prunedSeq())
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		more, err := v.pruneBlocks(tx)
+		require.NoError(t, err)
+		require.False(t, more)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), prunedSeq())
+
+	require.NoError(t, CheckDatabase(db, genPublic, nil, nil))
+}
+
+func TestVisorDBFillPercent(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	cfg := NewConfig()
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+	cfg.Distribution = params.MainNetDistribution
+
+	cfg.DBFillPercent = 1.1
+	_, err := New(cfg, db, nil)
+	require.Equal(t, errors.New("DBFillPercent must be 0 or between 0.1 and 1"), err)
+
+	cfg.DBFillPercent = 0.9
+	_, err = New(cfg, db, nil)
+	require.NoError(t, err)
+	require.Equal(t, 0.9, db.FillPercent)
+
+	// The buckets of Update transactions are split with the fill percent, the buckets of View transactions are never split
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		require.Equal(t, 0.9, tx.Bucket(MetaBkt).FillPercent)
+
+		b, err := tx.CreateBucketIfNotExists(MetaBkt)
+		require.NoError(t, err)
+		require.Equal(t, 0.9, b.FillPercent)
+
+		require.Nil(t, tx.Bucket([]byte("missing")))
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		require.Equal(t, bolt.DefaultFillPercent, tx.Bucket(MetaBkt).FillPercent)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestVisorForEachBlock(t *testing.T) {
+	v, shutdown := newChainExportTestVisor(t)
+	defer shutdown()
+
+	// An empty blockchain has no blocks
+	err := v.ForEachBlock(func(b coin.SignedBlock) error {
+		t.Fatal("unexpected block")
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Create a chain of blocks, each spending the output of the previous block's transaction
+	gb := addGenesisBlockToVisor(t, v)
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	for i := 1; i <= 5; i++ {
+		txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, uxs[0].Body.Coins)
+
+		err := v.db.Update("", func(tx *dbutil.Tx) error {
+			b, err := v.blockchain.NewBlock(tx, coin.Transactions{txn}, genTime+uint64(i)*100)
+			require.NoError(t, err)
+
+			sb := coin.SignedBlock{
+				Block: *b,
+				Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+			}
+			if err := v.executeSignedBlock(tx, sb); err != nil {
+				return err
+			}
+
+			uxs = coin.CreateUnspents(b.Head, txn)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	expected, err := v.GetBlocksInRange(0, 5)
+	require.NoError(t, err)
+	require.Len(t, expected, 6)
+
+	var blocks []coin.SignedBlock
+	err = v.ForEachBlock(func(b coin.SignedBlock) error {
+		blocks = append(blocks, b)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, expected, blocks)
+
+	// The iteration stops at the first error
+	errStop := errors.New("stop")
+	var seqs []uint64
+	err = v.ForEachBlock(func(b coin.SignedBlock) error {
+		seqs = append(seqs, b.Seq())
+		if b.Seq() == 2 {
+			return errStop
+		}
+		return nil
+	})
+	require.Equal(t, errStop, err)
+	require.Equal(t, []uint64{0, 1, 2}, seqs)
+}
+
+func TestGetAddressBalanceAt(t *testing.T) {
+	addr := testutil.MakeAddress()
+	block := &coin.SignedBlock{
+		Block: coin.Block{
+			Head: coin.BlockHeader{
+				BkSeq: 10,
+				Time:  1000,
+			},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		block    *coin.SignedBlock
+		snapshot *historydb.AddressBalance
+		computed historydb.AddressBalance
+		expect   *historydb.AddressBalance
+	}{
+		{
+			name: "block not found",
+		},
+		{
+			name:     "cached",
+			block:    block,
+			snapshot: &historydb.AddressBalance{Coins: 1e6, Hours: 2},
+			expect:   &historydb.AddressBalance{Coins: 1e6, Hours: 2},
+		},
+		{
+			name:     "not cached",
+			block:    block,
+			computed: historydb.AddressBalance{Coins: 3e6, Hours: 4},
+			expect:   &historydb.AddressBalance{Coins: 3e6, Hours: 4},
+		},
+	}
+
+	matchDBTx := mock.MatchedBy(func(tx *dbutil.Tx) bool {
+		return true
+	})
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, shutdown := testutil.PrepareDB(t)
+			defer shutdown()
+
+			history := &MockHistoryer{}
+			bc := &MockBlockchainer{}
+
+			bc.On("GetSignedBlockBySeq", matchDBTx, uint64(10)).Return(tc.block, nil)
+			history.On("GetAddressBalanceSnapshot", matchDBTx, addr, uint64(10)).Return(tc.snapshot, nil)
+			history.On("GetAddressBalanceAt", matchDBTx, addr, uint64(10), uint64(1000)).Return(tc.computed, nil)
+			history.On("SetAddressBalanceSnapshot", matchDBTx, addr, uint64(10), tc.computed).Return(nil)
+
+			v := &Visor{
+				blockchain: bc,
+				db:         db,
+				history:    history,
+			}
+
+			b, err := v.GetAddressBalanceAt(addr, 10)
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, b)
+
+			switch {
+			case tc.block == nil:
+				history.AssertNotCalled(t, "GetAddressBalanceSnapshot", matchDBTx, addr, uint64(10))
+				history.AssertNotCalled(t, "GetAddressBalanceAt", matchDBTx, addr, uint64(10), uint64(1000))
+			case tc.snapshot != nil:
+				history.AssertNotCalled(t, "GetAddressBalanceAt", matchDBTx, addr, uint64(10), uint64(1000))
+				history.AssertNotCalled(t, "SetAddressBalanceSnapshot", matchDBTx, addr, uint64(10), tc.computed)
+			default:
+				history.AssertCalled(t, "SetAddressBalanceSnapshot", matchDBTx, addr, uint64(10), tc.computed)
+			}
+		})
+	}
+}
+
+func TestGetAddressTransactionCount(t *testing.T) {
+	addr := testutil.MakeAddress()
+
+	cases := []struct {
+		name      string
+		headSeq   uint64
+		hasHead   bool
+		parsedSeq uint64
+		hasParsed bool
+		count     uint64
+		expect    uint64
+		err       error
+	}{
+		{
+			name: "empty blockchain",
+		},
+		{
+			name:      "history parsed to head",
+			headSeq:   10,
+			hasHead:   true,
+			parsedSeq: 10,
+			hasParsed: true,
+			count:     3,
+			expect:    3,
+		},
+		{
+			name:      "history behind head",
+			headSeq:   10,
+			hasHead:   true,
+			parsedSeq: 9,
+			hasParsed: true,
+			count:     3,
+			err:       ErrIndexNotAvailable,
+		},
+		{
+			name:    "history not parsed",
+			headSeq: 10,
+			hasHead: true,
+			err:     ErrIndexNotAvailable,
+		},
+	}
+
+	matchDBTx := mock.MatchedBy(func(tx *dbutil.Tx) bool {
+		return true
+	})
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, shutdown := testutil.PrepareDB(t)
+			defer shutdown()
+
+			history := &MockHistoryer{}
+			bc := &MockBlockchainer{}
+
+			bc.On("HeadSeq", matchDBTx).Return(tc.headSeq, tc.hasHead, nil)
+			history.On("ParsedBlockSeq", matchDBTx).Return(tc.parsedSeq, tc.hasParsed, nil)
+			history.On("AddressTransactionCount", matchDBTx, addr).Return(tc.count, nil)
+
+			v := &Visor{
+				blockchain: bc,
+				db:         db,
+				history:    history,
+			}
+
+			n, err := v.GetAddressTransactionCount(addr)
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.expect, n)
+		})
+	}
+}
@@ -45,6 +45,8 @@ var (
 	ErrTransactionAlreadySigned = NewUserError(errors.New("Transaction is already fully signed"))
 	// ErrUxOutsOrAddressesRequired Both Addresses and UxOuts are empty
 	ErrUxOutsOrAddressesRequired = NewUserError(errors.New("UxOuts or Addresses must not be empty"))
+	// ErrUxOutNotInWallet is returned if an unspent output is not owned by an address of the wallet
+	ErrUxOutNotInWallet = NewUserError(errors.New("UxOut is not owned by the wallet"))
 	// ErrNoSpendableOutputs after filtering unconfirmed spend outputs, there are no remaining outputs available for transaction creation
 	ErrNoSpendableOutputs = NewUserError(errors.New("All selected outputs are unavailable for spending"))
 )
@@ -105,6 +107,27 @@ func (vs *Visor) GetWalletBalance(wltID string) (wallet.BalancePair, wallet.Addr
 	return walletBalance, addressBalances, nil
 }
 
+// GetWalletAddressStats returns the usage statistics of the addresses of a wallet, in the wallet's address order.
+// The returned bool is true if the statistics are approximate, see GetAddressStats.
+func (vs *Visor) GetWalletAddressStats(wltID string) ([]AddressStats, bool, error) {
+	var stats []AddressStats
+	var approximate bool
+
+	if err := vs.wallets.View(wltID, func(w wallet.Wallet) error {
+		addrs, err := w.GetAddresses()
+		if err != nil {
+			return err
+		}
+
+		stats, approximate, err = vs.GetAddressStats(wallet.SkycoinAddresses(addrs))
+		return err
+	}); err != nil {
+		return nil, false, err
+	}
+
+	return stats, approximate, nil
+}
+
 // GetWalletUnconfirmedTransactions returns all unconfirmed transactions in given wallet
 func (vs *Visor) GetWalletUnconfirmedTransactions(wltID string) ([]UnconfirmedTransaction, error) {
 	var txns []UnconfirmedTransaction
@@ -218,6 +241,43 @@ func (vs *Visor) WalletSignTransaction(wltID string, password []byte, txn *coin.
 	return signedTxn, inputs, nil
 }
 
+// WalletCoinJoinKeys returns the secret keys of the unspent outputs of a wallet that are spent by a coinjoin request,
+// in the same order as inputs. The keys sign the inputs when the coordinator sends the coinjoin transaction.
+func (vs *Visor) WalletCoinJoinKeys(wltID string, password []byte, inputs []cipher.SHA256) ([]cipher.SecKey, error) {
+	if err := (CreateTransactionParams{UxOuts: inputs}).Validate(); err != nil {
+		return nil, err
+	}
+
+	var keys []cipher.SecKey
+	if err := vs.wallets.ViewSecrets(wltID, password, func(w wallet.Wallet) error {
+		return vs.db.View("WalletCoinJoinKeys", func(tx *dbutil.Tx) error {
+			uxa, err := vs.blockchain.Unspent().GetArray(tx, inputs)
+			if err != nil {
+				return err
+			}
+
+			keys = make([]cipher.SecKey, len(uxa))
+			for i, ux := range uxa {
+				e, err := w.GetEntry(ux.Body.Address)
+				if err != nil {
+					if err == wallet.ErrEntryNotFound {
+						return ErrUxOutNotInWallet
+					}
+					return err
+				}
+
+				keys[i] = e.Secret
+			}
+
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
 // CreateTransactionParams parameters for transaction creation
 type CreateTransactionParams struct {
 	UxOuts    []cipher.SHA256
@@ -259,8 +319,17 @@ func (p CreateTransactionParams) Validate() error {
 	return nil
 }
 
+// withDustConfig applies the dust threshold and policy of the node to the transaction params
+func (vs *Visor) withDustConfig(p transaction.Params) transaction.Params {
+	p.DustThreshold = vs.Config.DustThreshold
+	p.DustPolicy = vs.Config.DustPolicy
+	return p
+}
+
 // WalletCreateTransactionSigned creates a signed transaction based upon the parameters in CreateTransactionParams
 func (vs *Visor) WalletCreateTransactionSigned(wltID string, password []byte, p transaction.Params, wp CreateTransactionParams) (*coin.Transaction, []TransactionInput, error) {
+	p = vs.withDustConfig(p)
+
 	// Validate params before unlocking wallet
 	if err := p.Validate(); err != nil {
 		return nil, nil, err
@@ -310,6 +379,8 @@ func (vs *Visor) WalletCreateTransactionSigned(wltID string, password []byte, p
 // WalletCreateTransaction creates a transaction based upon the parameters in CreateTransactionParams
 // TODO: Only referenced by tests, vs.walletCreateTransaction
 func (vs *Visor) WalletCreateTransaction(wltID string, p transaction.Params, wp CreateTransactionParams) (*coin.Transaction, []TransactionInput, error) {
+	p = vs.withDustConfig(p)
+
 	// Validate params before opening wallet
 	if err := p.Validate(); err != nil {
 		return nil, nil, err
@@ -457,6 +528,12 @@ func (vs *Visor) walletCreateTransactionTx(tx *dbutil.Tx, methodName string,
 		return nil, nil, err
 	}
 
+	// Check the input and output counts before the soft constraints, so that the caller gets a user error
+	if err := verifyTxnInputOutputCounts(*txn, params.UserVerifyTxn); err != nil {
+		logger.WithError(err).Error("Created transaction has too many inputs or outputs")
+		return nil, nil, NewUserError(err)
+	}
+
 	// The wallet can create transactions that would not pass all validation, such as the decimal restriction,
 	// because the wallet is not aware of visor-level constraints.
 	// Check that the transaction is valid before returning it to the caller.
@@ -471,6 +548,8 @@ func (vs *Visor) walletCreateTransactionTx(tx *dbutil.Tx, methodName string,
 
 // CreateTransaction creates an unsigned transaction from requested coin.UxOut hashes
 func (vs *Visor) CreateTransaction(p transaction.Params, wp CreateTransactionParams) (*coin.Transaction, []TransactionInput, error) {
+	p = vs.withDustConfig(p)
+
 	// Validate parameters before starting database transaction
 	if err := p.Validate(); err != nil {
 		return nil, nil, err
@@ -527,6 +606,12 @@ func (vs *Visor) createTransactionTx(tx *dbutil.Tx, p transaction.Params, wp Cre
 		return nil, nil, err
 	}
 
+	// Check the input and output counts before the soft constraints, so that the caller gets a user error
+	if err := verifyTxnInputOutputCounts(*txn, params.UserVerifyTxn); err != nil {
+		logger.WithError(err).Error("Created transaction has too many inputs or outputs")
+		return nil, nil, NewUserError(err)
+	}
+
 	// The wallet can create transactions that would not pass all validation, such as the decimal restriction,
 	// because the wallet is not aware of visor-level constraints.
 	// Check that the transaction is valid before returning it to the caller.
@@ -1013,6 +1013,80 @@ func TestWalletCreateTransaction(t *testing.T) {
 	}
 }
 
+func TestWalletCoinJoinKeys(t *testing.T) {
+	ws, err := wallet.NewService(wallet.Config{
+		EnableWalletAPI: true,
+		CryptoType:      crypto.CryptoTypeScryptChacha20poly1305Insecure,
+		WalletDir:       prepareWltDir(),
+	})
+	require.NoError(t, err)
+
+	_, err = ws.CreateWallet("foo.wlt", wallet.Options{
+		Coin:       wallet.CoinTypeSkycoin,
+		Encrypt:    true,
+		Password:   []byte("pwd"),
+		CryptoType: crypto.CryptoTypeScryptChacha20poly1305Insecure,
+		Type:       wallet.WalletTypeCollection,
+	})
+	require.NoError(t, err)
+
+	entries, addrs := makeEntries(2)
+	err = ws.UpdateSecrets("foo.wlt", []byte("pwd"), func(w wallet.Wallet) error {
+		for _, e := range entries {
+			require.NoError(t, w.(*collection.Wallet).AddEntry(e))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	makeUxOut := func(addr cipher.Address) coin.UxOut {
+		return coin.UxOut{
+			Body: coin.UxBody{
+				SrcTransaction: testutil.RandSHA256(t),
+				Address:        addr,
+				Coins:          1e6,
+				Hours:          100,
+			},
+		}
+	}
+
+	uxa := coin.UxArray{makeUxOut(addrs[1]), makeUxOut(addrs[0])}
+	inputs := []cipher.SHA256{uxa[0].Hash(), uxa[1].Hash()}
+	foreign := coin.UxArray{makeUxOut(testutil.MakeAddress())}
+	foreignInputs := []cipher.SHA256{foreign[0].Hash()}
+
+	up := &MockUnspentPooler{}
+	up.On("GetArray", matchDBTx, inputs).Return(uxa, nil)
+	up.On("GetArray", matchDBTx, foreignInputs).Return(foreign, nil)
+	b := &MockBlockchainer{}
+	b.On("Unspent").Return(up)
+
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := &Visor{
+		db:         db,
+		blockchain: b,
+		wallets:    ws,
+	}
+
+	keys, err := v.WalletCoinJoinKeys("foo.wlt", []byte("pwd"), inputs)
+	require.NoError(t, err)
+	require.Equal(t, []cipher.SecKey{entries[1].Secret, entries[0].Secret}, keys)
+
+	_, err = v.WalletCoinJoinKeys("foo.wlt", []byte("bad"), inputs)
+	require.Equal(t, wallet.ErrInvalidPassword, err)
+
+	_, err = v.WalletCoinJoinKeys("foo.wlt", []byte("pwd"), []cipher.SHA256{inputs[0], inputs[0]})
+	require.Equal(t, ErrDuplicateUxOuts, err)
+
+	_, err = v.WalletCoinJoinKeys("foo.wlt", []byte("pwd"), foreignInputs)
+	require.Equal(t, ErrUxOutNotInWallet, err)
+
+	_, err = v.WalletCoinJoinKeys("bar.wlt", []byte("pwd"), inputs)
+	require.Equal(t, wallet.ErrWalletNotExist, err)
+}
+
 func TestCreateTransactionParamsValidate(t *testing.T) {
 	var nullAddress cipher.Address
 	addr := testutil.MakeAddress()
@@ -1097,10 +1171,11 @@ func TestWalletCreateTransactionValidation(t *testing.T) {
 	}
 
 	cases := []struct {
-		name string
-		p    transaction.Params
-		wp   CreateTransactionParams
-		err  error
+		name   string
+		config Config
+		p      transaction.Params
+		wp     CreateTransactionParams
+		err    error
 	}{
 		{
 			name: "bad transaction.Params",
@@ -1116,12 +1191,22 @@ func TestWalletCreateTransactionValidation(t *testing.T) {
 			},
 			err: ErrCreateTransactionParamsConflict,
 		},
+		{
+			name: "receiver below the dust threshold",
+			config: Config{
+				DustThreshold: 11,
+			},
+			p:   validParams,
+			err: transaction.ErrDustReceiver,
+		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			// setup visor
-			v := &Visor{}
+			v := &Visor{
+				Config: tc.config,
+			}
 
 			_, _, err := v.WalletCreateTransaction("foo.wlt", tc.p, tc.wp)
 			require.Equal(t, tc.err, err)
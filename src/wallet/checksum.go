@@ -0,0 +1,135 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// checksumField is the top level field of the wallet file JSON that stores the file checksum
+	checksumField = "checksum"
+	// checksumKeyPrefix is hashed with the wallet's creation timestamp to make the checksum HMAC key
+	checksumKeyPrefix = "skycoin-wallet-file-checksum:"
+)
+
+var errChecksumMismatch = errors.New("checksum mismatch")
+
+// WalletCorruptError is returned when loading a wallet file whose checksum does not match its content,
+// because the file was corrupted on disk or modified outside of the wallet service
+type WalletCorruptError struct {
+	Filename string
+	Err      error
+}
+
+func (e WalletCorruptError) Error() string {
+	return fmt.Sprintf("wallet file %q is corrupted: %v", e.Filename, e.Err)
+}
+
+// serializeWithChecksum serializes the wallet, and adds the checksum of the serialized wallet
+// as the first top level field of the JSON
+func serializeWithChecksum(w Wallet) ([]byte, error) {
+	data, err := w.Serialize()
+	if err != nil {
+		return nil, err
+	}
+
+	sum, err := fileChecksum(data)
+	if err != nil {
+		return nil, err
+	}
+
+	i := bytes.IndexByte(data, '{')
+	if i == -1 {
+		return nil, errors.New("serialized wallet is not a JSON object")
+	}
+
+	field := fmt.Sprintf("\n    %q: %q,", checksumField, sum)
+
+	out := make([]byte, 0, len(data)+len(field))
+	out = append(out, data[:i+1]...)
+	out = append(out, field...)
+	out = append(out, data[i+1:]...)
+	return out, nil
+}
+
+// verifyChecksum verifies the checksum of a wallet file.
+// Wallet files that were written before checksums were added don't have a checksum and are not verified.
+func verifyChecksum(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	v, ok := fields[checksumField]
+	if !ok {
+		return nil
+	}
+
+	var sum string
+	if err := json.Unmarshal(v, &sum); err != nil {
+		return fmt.Errorf("invalid checksum: %v", err)
+	}
+
+	expected, err := hex.DecodeString(sum)
+	if err != nil {
+		return fmt.Errorf("invalid checksum: %v", err)
+	}
+
+	delete(fields, checksumField)
+
+	actual, err := fieldsChecksum(fields)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal(expected, actual) {
+		return errChecksumMismatch
+	}
+
+	return nil
+}
+
+// fileChecksum returns the hex encoded checksum of a serialized wallet that has no checksum field
+func fileChecksum(data []byte) (string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", err
+	}
+
+	sum, err := fieldsChecksum(fields)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(sum), nil
+}
+
+// fieldsChecksum computes the HMAC-SHA256 of the top level fields of a wallet file.
+// The fields are re-encoded in compact form with sorted keys, so that the checksum does not depend on
+// the whitespace or the order of the top level fields.
+// The HMAC key is derived from the wallet's creation timestamp. It is not secret, since the timestamp
+// is stored in the wallet file, so the checksum detects corruption and accidental edits,
+// but not modifications made by someone who knows how the checksum is computed.
+func fieldsChecksum(fields map[string]json.RawMessage) ([]byte, error) {
+	var meta map[string]string
+	if v, ok := fields["meta"]; ok {
+		if err := json.Unmarshal(v, &meta); err != nil {
+			return nil, fmt.Errorf("invalid meta: %v", err)
+		}
+	}
+
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	key := sha256.Sum256([]byte(checksumKeyPrefix + meta[MetaTimestamp]))
+	h := hmac.New(sha256.New, key[:])
+	h.Write(canonical) //nolint:errcheck
+	return h.Sum(nil), nil
+}
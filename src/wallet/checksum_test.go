@@ -0,0 +1,71 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var checksumTestWallet = []byte(`{
+    "meta": {
+        "coin": "skycoin",
+        "filename": "test.wlt",
+        "label": "test",
+        "tm": "1503458909",
+        "type": "deterministic",
+        "version": "0.4"
+    },
+    "entries": [
+        {
+            "address": "JUdRuTiqD1mGcw358twMg3VPpXpzbkdRvJ",
+            "public_key": "028ef95b281f1bd6483f0c5c1ed1144b77c360b92a4eb48f681a6dff67a7c2dab1"
+        }
+    ]
+}`)
+
+func TestSerializeWithChecksum(t *testing.T) {
+	w := &MockWallet{}
+	w.On("Serialize").Return(checksumTestWallet, nil)
+
+	data, err := serializeWithChecksum(w)
+	require.NoError(t, err)
+
+	// The checksum is the first field, the rest of the serialized wallet is unchanged
+	require.True(t, bytes.HasPrefix(data, []byte("{\n    \"checksum\": \"")))
+	require.True(t, bytes.HasSuffix(data, checksumTestWallet[1:]))
+
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	require.NoError(t, err)
+	require.Len(t, fields, 3)
+
+	require.NoError(t, verifyChecksum(data))
+
+	// Whitespace changes don't change the checksum
+	var compact bytes.Buffer
+	err = json.Compact(&compact, data)
+	require.NoError(t, err)
+	require.NoError(t, verifyChecksum(compact.Bytes()))
+
+	// Wallets without a checksum are not verified
+	require.NoError(t, verifyChecksum(checksumTestWallet))
+
+	// Modified content
+	modified := bytes.Replace(data, []byte(`"label": "test"`), []byte(`"label": "tess"`), 1)
+	require.Equal(t, errChecksumMismatch, verifyChecksum(modified))
+
+	// Modified creation timestamp, which the checksum key is derived from
+	modified = bytes.Replace(data, []byte(`"tm": "1503458909"`), []byte(`"tm": "1503458908"`), 1)
+	require.Equal(t, errChecksumMismatch, verifyChecksum(modified))
+
+	// Invalid checksum
+	invalid := bytes.Replace(data, []byte(`"checksum": "`), []byte(`"checksum": "zz`), 1)
+	err = verifyChecksum(invalid)
+	require.Error(t, err)
+	require.NotEqual(t, errChecksumMismatch, err)
+
+	// Truncated file
+	require.Error(t, verifyChecksum(data[:len(data)/2]))
+}
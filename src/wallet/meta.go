@@ -2,6 +2,8 @@ package wallet
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -26,8 +28,17 @@ const (
 	MetaAccountsHash   = "accountsHash"   // accounts hash
 	MetaSeedPassphrase = "seedPassphrase" // seed passphrase [bip44 wallets]
 	MetaXPub           = "xpub"           // xpub key [xpub wallets]
+
+	// MetaUserPrefix is the prefix of the keys of user defined metadata, which are stored with the other meta fields
+	MetaUserPrefix = "user."
+
+	// MaxUserMetaValueLen is the maximum length in bytes of a user defined metadata value
+	MaxUserMetaValueLen = 255
 )
 
+// userMetaKeyRegexp matches valid user defined metadata keys
+var userMetaKeyRegexp = regexp.MustCompile(`^[a-z_]{1,32}$`)
+
 //const (
 //	// CoinTypeSkycoin skycoin type
 //	CoinTypeSkycoin CoinType = "skycoin"
@@ -257,6 +268,48 @@ func (m Meta) XPub() string {
 	return m[MetaXPub]
 }
 
+// UserMeta returns the user defined metadata, with the keys unprefixed
+func (m Meta) UserMeta() map[string]string {
+	um := make(map[string]string)
+	for k, v := range m {
+		if strings.HasPrefix(k, MetaUserPrefix) {
+			um[strings.TrimPrefix(k, MetaUserPrefix)] = v
+		}
+	}
+	return um
+}
+
+// SetUserMeta sets user defined metadata. A key with an empty value is removed.
+// Other keys of the user defined metadata are not modified.
+func (m Meta) SetUserMeta(um map[string]string) error {
+	if err := ValidateUserMeta(um); err != nil {
+		return err
+	}
+
+	for k, v := range um {
+		if v == "" {
+			delete(m, MetaUserPrefix+k)
+		} else {
+			m[MetaUserPrefix+k] = v
+		}
+	}
+	return nil
+}
+
+// ValidateUserMeta checks that user defined metadata keys match [a-z_]{1,32}
+// and that values are at most MaxUserMetaValueLen bytes
+func ValidateUserMeta(um map[string]string) error {
+	for k, v := range um {
+		if !userMetaKeyRegexp.MatchString(k) {
+			return NewError(fmt.Errorf("invalid meta key %q, keys must match [a-z_]{1,32}", k))
+		}
+		if len(v) > MaxUserMetaValueLen {
+			return NewError(fmt.Errorf("meta value of %q is longer than %d bytes", k, MaxUserMetaValueLen))
+		}
+	}
+	return nil
+}
+
 // Validate validates the meta data
 func (m Meta) Validate() error {
 	if fn := m[MetaFilename]; fn == "" {
@@ -0,0 +1,83 @@
+package wallet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateUserMeta(t *testing.T) {
+	tt := []struct {
+		name string
+		meta map[string]string
+		err  string
+	}{
+		{
+			name: "ok",
+			meta: map[string]string{
+				"purpose":                          "savings",
+				"a":                                "",
+				"abcdefghijklmnopqrstuvwxyz_abcde": strings.Repeat("x", MaxUserMetaValueLen),
+			},
+		},
+		{
+			name: "empty key",
+			meta: map[string]string{"": "x"},
+			err:  `invalid meta key "", keys must match [a-z_]{1,32}`,
+		},
+		{
+			name: "key too long",
+			meta: map[string]string{"abcdefghijklmnopqrstuvwxyz_abcdef": "x"},
+			err:  `invalid meta key "abcdefghijklmnopqrstuvwxyz_abcdef", keys must match [a-z_]{1,32}`,
+		},
+		{
+			name: "invalid key characters",
+			meta: map[string]string{"purpose1": "x"},
+			err:  `invalid meta key "purpose1", keys must match [a-z_]{1,32}`,
+		},
+		{
+			name: "value too long",
+			meta: map[string]string{"purpose": strings.Repeat("x", MaxUserMetaValueLen+1)},
+			err:  `meta value of "purpose" is longer than 255 bytes`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateUserMeta(tc.meta)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.EqualError(t, err, tc.err)
+			require.IsType(t, Error{}, err)
+		})
+	}
+}
+
+func TestMetaUserMeta(t *testing.T) {
+	m := Meta{
+		MetaLabel:    "label",
+		MetaFilename: "t.wlt",
+	}
+	require.Empty(t, m.UserMeta())
+
+	require.NoError(t, m.SetUserMeta(map[string]string{
+		"purpose": "savings",
+		"label":   "user label",
+	}))
+	// User defined keys don't collide with the wallet's own meta fields
+	require.Equal(t, "label", m.Label())
+	require.Equal(t, map[string]string{
+		"purpose": "savings",
+		"label":   "user label",
+	}, m.UserMeta())
+
+	require.NoError(t, m.SetUserMeta(map[string]string{"label": ""}))
+	require.Equal(t, map[string]string{"purpose": "savings"}, m.UserMeta())
+
+	require.Error(t, m.SetUserMeta(map[string]string{"purpose": "x", "Bad": "x"}))
+	require.Equal(t, map[string]string{"purpose": "savings"}, m.UserMeta())
+}
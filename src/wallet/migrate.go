@@ -0,0 +1,188 @@
+package wallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/skycoin/skycoin/src/util/file"
+)
+
+// walletMigration upgrades the meta fields of a wallet file from the version it is registered
+// for in walletMigrations to the version To. Migrations must only add missing fields with defaults.
+// migrate returns true if it changed any field other than the version.
+type walletMigration struct {
+	To      string
+	migrate func(meta map[string]string) bool
+}
+
+// walletMigrations are keyed by the version they upgrade from. A wallet file is upgraded by applying
+// the migrations in sequence until it reaches Version. When the wallet format changes, bump Version
+// and register a migration from the previous version.
+var walletMigrations = map[string]walletMigration{
+	"0.1": {
+		To:      "0.2",
+		migrate: migrateWalletV01,
+	},
+	"0.2": {
+		To: "0.3",
+	},
+	"0.3": {
+		To: "0.4",
+	},
+}
+
+// migrateWalletV01 adds the fields that are required since version 0.2.
+// Version 0.1 wallets were deterministic skycoin wallets. A missing encrypted field already means unencrypted.
+func migrateWalletV01(meta map[string]string) bool {
+	changed := false
+	for k, v := range map[string]string{
+		MetaType: WalletTypeDeterministic,
+		MetaCoin: string(CoinTypeSkycoin),
+	} {
+		if _, ok := meta[k]; !ok {
+			meta[k] = v
+			changed = true
+		}
+	}
+	return changed
+}
+
+// migrateWalletData upgrades the JSON of a wallet file to Version.
+// Wallet files without a version are version 0.1.
+// Returns the migrated JSON, with the checksum field removed, the original version and whether
+// a migration changed any field other than the version. If the wallet is already at Version,
+// data is returned unchanged.
+func migrateWalletData(data []byte) ([]byte, string, bool, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, "", false, err
+	}
+
+	var meta map[string]string
+	if v, ok := fields["meta"]; ok {
+		if err := json.Unmarshal(v, &meta); err != nil {
+			return nil, "", false, fmt.Errorf("invalid meta field: %v", err)
+		}
+	}
+	if meta == nil {
+		meta = make(map[string]string)
+	}
+
+	from := meta[MetaVersion]
+	if from == "" {
+		from = "0.1"
+	}
+
+	if from == Version {
+		return data, from, false, nil
+	}
+
+	changed := false
+	for v := from; v != Version; {
+		m, ok := walletMigrations[v]
+		if !ok {
+			return nil, from, false, fmt.Errorf("wallet version %q is not supported, the newest supported version is %q", from, Version)
+		}
+
+		if m.migrate != nil && m.migrate(meta) {
+			changed = true
+		}
+
+		v = m.To
+		meta[MetaVersion] = v
+	}
+
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return nil, from, false, err
+	}
+	fields["meta"] = b
+
+	// The checksum is computed over the file content, which is rewritten
+	delete(fields, checksumField)
+
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return nil, from, false, err
+	}
+
+	return out, from, changed, nil
+}
+
+// saveMigratedWallet writes a migrated wallet to filename. The original file is kept,
+// renamed to filename.bak, or filename.N.bak if that backup already exists.
+// Returns the name of the backup file.
+func saveMigratedWallet(filename string, w Wallet) (string, error) {
+	data, err := serializeWithChecksum(w)
+	if err != nil {
+		return "", err
+	}
+
+	backup := filename + ".bak"
+	for i := 1; ; i++ {
+		if _, err := os.Stat(backup); os.IsNotExist(err) {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		backup = fmt.Sprintf("%s.%d.bak", filename, i)
+	}
+
+	if err := os.Rename(filename, backup); err != nil {
+		return "", err
+	}
+
+	if err := file.SaveBinary(filename, data, 0600); err != nil {
+		return "", err
+	}
+
+	return backup, nil
+}
+
+// loadWalletData verifies the checksum of the data of a wallet file, migrates it to Version and loads it with l.
+// If a migration changed the wallet's fields, the migrated wallet is saved back to filename.
+// A migration that only changes the version is not saved, the new version is written when the wallet is next saved.
+func loadWalletData(filename string, data []byte, l Loader) (Wallet, error) {
+	w, changed, err := decodeWalletData(filename, data, l)
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		backup, err := saveMigratedWallet(filename, w)
+		if err != nil {
+			return nil, fmt.Errorf("save migrated wallet %q failed: %v", filename, err)
+		}
+		logger.WithField("filename", filename).Infof("Saved migrated wallet, the original file is kept in %s", backup)
+	}
+
+	return w, nil
+}
+
+// decodeWalletData verifies the checksum of the data of a wallet file, migrates it to Version in memory and loads it with l.
+// Returns true if a migration changed the wallet's fields other than the version. Nothing is written to filename.
+func decodeWalletData(filename string, data []byte, l Loader) (Wallet, bool, error) {
+	if err := verifyChecksum(data); err != nil {
+		return nil, false, WalletCorruptError{
+			Filename: filename,
+			Err:      err,
+		}
+	}
+
+	migrated, from, changed, err := migrateWalletData(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("wallet %q: %v", filename, err)
+	}
+
+	w, err := l.Load(migrated)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if from != Version {
+		logger.WithField("filename", filename).Infof("Migrated wallet from version %s to %s", from, Version)
+	}
+
+	return w, changed, nil
+}
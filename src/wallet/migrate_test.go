@@ -0,0 +1,186 @@
+package wallet
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// echoLoader loads a MockWallet that serializes to the data it was loaded from
+type echoLoader struct{}
+
+func (echoLoader) Load(data []byte) (Wallet, error) {
+	w := &MockWallet{}
+	w.On("Serialize").Return(data, nil)
+	return w, nil
+}
+
+func walletFileMeta(t *testing.T, data []byte) map[string]string {
+	var v struct {
+		Meta map[string]string `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(data, &v))
+	return v.Meta
+}
+
+func TestMigrateWalletData(t *testing.T) {
+	tt := []struct {
+		name    string
+		data    string
+		from    string
+		changed bool
+		meta    map[string]string
+		err     string
+	}{
+		{
+			name:    "0.1 missing fields",
+			data:    `{"meta":{"version":"0.1","label":"test"},"entries":[]}`,
+			from:    "0.1",
+			changed: true,
+			meta: map[string]string{
+				MetaVersion: Version,
+				MetaLabel:   "test",
+				MetaCoin:    string(CoinTypeSkycoin),
+				MetaType:    WalletTypeDeterministic,
+			},
+		},
+		{
+			name:    "no version",
+			data:    `{"meta":{"coin":"skycoin","type":"deterministic"},"entries":[]}`,
+			from:    "0.1",
+			changed: false,
+			meta: map[string]string{
+				MetaVersion: Version,
+				MetaCoin:    string(CoinTypeSkycoin),
+				MetaType:    WalletTypeDeterministic,
+			},
+		},
+		{
+			name:    "0.2 version only",
+			data:    `{"meta":{"version":"0.2","coin":"skycoin","type":"deterministic"},"checksum":"abc"}`,
+			from:    "0.2",
+			changed: false,
+			meta: map[string]string{
+				MetaVersion: Version,
+				MetaCoin:    string(CoinTypeSkycoin),
+				MetaType:    WalletTypeDeterministic,
+			},
+		},
+		{
+			name: "current version",
+			data: `{"meta":{"version":"` + Version + `","coin":"skycoin","type":"deterministic"}}`,
+			from: Version,
+			meta: map[string]string{
+				MetaVersion: Version,
+				MetaCoin:    string(CoinTypeSkycoin),
+				MetaType:    WalletTypeDeterministic,
+			},
+		},
+		{
+			name: "newer version",
+			data: `{"meta":{"version":"0.9","coin":"skycoin","type":"deterministic"}}`,
+			from: "0.9",
+			err:  `wallet version "0.9" is not supported, the newest supported version is "` + Version + `"`,
+		},
+		{
+			name: "invalid json",
+			data: `{"meta":`,
+			err:  "unexpected end of JSON input",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			data, from, changed, err := migrateWalletData([]byte(tc.data))
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.from, from)
+			require.Equal(t, tc.changed, changed)
+			require.Equal(t, tc.meta, walletFileMeta(t, data))
+
+			if tc.from == Version {
+				require.Equal(t, tc.data, string(data))
+			} else {
+				var fields map[string]json.RawMessage
+				require.NoError(t, json.Unmarshal(data, &fields))
+				_, ok := fields[checksumField]
+				require.False(t, ok)
+			}
+		})
+	}
+}
+
+func TestLoadWalletDataMigration(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wallet-migrate")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// A version-only migration does not rewrite the file
+	filename := filepath.Join(dir, "v2.wlt")
+	v2 := []byte(`{"meta":{"version":"0.2","coin":"skycoin","type":"deterministic","tm":"1503458909"},"entries":[]}`)
+	require.NoError(t, ioutil.WriteFile(filename, v2, 0600))
+
+	_, err = loadWalletData(filename, v2, echoLoader{})
+	require.NoError(t, err)
+
+	b, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, v2, b)
+	_, err = os.Stat(filename + ".bak")
+	require.True(t, os.IsNotExist(err))
+
+	// A migration that adds fields saves the wallet and keeps a backup of the original file
+	filename = filepath.Join(dir, "v1.wlt")
+	v1 := []byte(`{"meta":{"version":"0.1","type":"deterministic","tm":"1503458909"},"entries":[]}`)
+	require.NoError(t, ioutil.WriteFile(filename, v1, 0600))
+
+	// Decoding the wallet migrates it without writing the file
+	_, changed, err := decodeWalletData(filename, v1, echoLoader{})
+	require.NoError(t, err)
+	require.True(t, changed)
+	_, err = os.Stat(filename + ".bak")
+	require.True(t, os.IsNotExist(err))
+
+	_, err = loadWalletData(filename, v1, echoLoader{})
+	require.NoError(t, err)
+
+	b, err = ioutil.ReadFile(filename + ".bak")
+	require.NoError(t, err)
+	require.Equal(t, v1, b)
+
+	migrated, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	require.NoError(t, verifyChecksum(migrated))
+	meta := walletFileMeta(t, migrated)
+	require.Equal(t, Version, meta[MetaVersion])
+	require.Equal(t, string(CoinTypeSkycoin), meta[MetaCoin])
+
+	// The migrated file loads without migrating again
+	_, err = loadWalletData(filename, migrated, echoLoader{})
+	require.NoError(t, err)
+	b, err = ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, migrated, b)
+
+	// An existing backup is not overwritten
+	require.NoError(t, ioutil.WriteFile(filename, v1, 0600))
+	_, err = loadWalletData(filename, v1, echoLoader{})
+	require.NoError(t, err)
+	b, err = ioutil.ReadFile(filename + ".1.bak")
+	require.NoError(t, err)
+	require.Equal(t, v1, b)
+
+	// Unsupported versions are not loaded
+	filename = filepath.Join(dir, "v9.wlt")
+	v9 := []byte(`{"meta":{"version":"0.9","coin":"skycoin","type":"deterministic"}}`)
+	require.NoError(t, ioutil.WriteFile(filename, v9, 0600))
+	_, err = loadWalletData(filename, v9, echoLoader{})
+	require.EqualError(t, err, `wallet "`+filename+`": wallet version "0.9" is not supported, the newest supported version is "`+Version+`"`)
+}
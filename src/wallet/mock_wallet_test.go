@@ -522,6 +522,20 @@ func (_m *MockWallet) SetTimestamp(_a0 int64) {
 	_m.Called(_a0)
 }
 
+// SetUserMeta provides a mock function with given fields: _a0
+func (_m *MockWallet) SetUserMeta(_a0 map[string]string) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(map[string]string) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Timestamp provides a mock function with given fields:
 func (_m *MockWallet) Timestamp() int64 {
 	ret := _m.Called()
@@ -573,6 +587,22 @@ func (_m *MockWallet) Unlock(password []byte) (Wallet, error) {
 	return r0, r1
 }
 
+// UserMeta provides a mock function with given fields:
+func (_m *MockWallet) UserMeta() map[string]string {
+	ret := _m.Called()
+
+	var r0 map[string]string
+	if rf, ok := ret.Get(0).(func() map[string]string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	return r0
+}
+
 // Version provides a mock function with given fields:
 func (_m *MockWallet) Version() string {
 	ret := _m.Called()
@@ -0,0 +1,232 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultS3Region is the signing region used if S3BackupConfig.Region is empty
+	defaultS3Region = "us-east-1"
+	// s3BackupQueueSize is the maximum number of pending wallet backup uploads
+	s3BackupQueueSize = 64
+	// s3BackupTimeout is the timeout of a single wallet backup upload
+	s3BackupTimeout = 30 * time.Second
+)
+
+// S3BackupConfig configures uploading backups of encrypted wallet files
+// to a bucket of an S3 compatible storage service.
+type S3BackupConfig struct {
+	// Endpoint is the URL of the storage service, e.g. https://s3.amazonaws.com
+	Endpoint string
+	// Region is the region used to sign requests, defaults to us-east-1
+	Region string
+	// Bucket is the name of the bucket to upload to. Objects are addressed in path style, {Endpoint}/{Bucket}/{Key}
+	Bucket string
+	// KeyPrefix is prepended to the wallet filename to make the object key
+	KeyPrefix string
+	AccessKey string
+	SecretKey string
+}
+
+// Validate validates the config
+func (c S3BackupConfig) Validate() error {
+	if c.Endpoint == "" {
+		return errors.New("S3 backup endpoint is required")
+	}
+
+	u, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid S3 backup endpoint: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("S3 backup endpoint must be a http or https URL")
+	}
+
+	if c.Bucket == "" {
+		return errors.New("S3 backup bucket is required")
+	}
+
+	if c.AccessKey == "" || c.SecretKey == "" {
+		return errors.New("S3 backup access key and secret key are required")
+	}
+
+	return nil
+}
+
+type s3BackupRequest struct {
+	filename string
+	data     []byte
+}
+
+// s3Backup uploads wallet files to an S3 compatible storage service.
+// Uploads are done in the order they are queued by a single background goroutine,
+// so that the latest version of a wallet file is uploaded last.
+type s3Backup struct {
+	config S3BackupConfig
+	client *http.Client
+	queue  chan s3BackupRequest
+	wg     sync.WaitGroup
+	// now is replaced in tests
+	now func() time.Time
+}
+
+func newS3Backup(c S3BackupConfig) (*s3Backup, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	if c.Region == "" {
+		c.Region = defaultS3Region
+	}
+
+	b := &s3Backup{
+		config: c,
+		client: &http.Client{
+			Timeout: s3BackupTimeout,
+		},
+		queue: make(chan s3BackupRequest, s3BackupQueueSize),
+		now:   time.Now,
+	}
+
+	b.wg.Add(1)
+	go b.run()
+
+	return b, nil
+}
+
+func (b *s3Backup) run() {
+	defer b.wg.Done()
+
+	for r := range b.queue {
+		if err := b.upload(r.filename, r.data); err != nil {
+			logger.WithError(err).WithField("filename", r.filename).Error("Wallet backup upload failed")
+			continue
+		}
+
+		logger.WithField("filename", r.filename).Debug("Wallet backup uploaded")
+	}
+}
+
+// backup queues the upload of a wallet file. The data is not copied and must not be modified by the caller.
+func (b *s3Backup) backup(filename string, data []byte) {
+	select {
+	case b.queue <- s3BackupRequest{
+		filename: filename,
+		data:     data,
+	}:
+	default:
+		logger.WithField("filename", filename).Error("Wallet backup queue is full, the backup is skipped")
+	}
+}
+
+// shutdown waits for the queued uploads to finish
+func (b *s3Backup) shutdown() {
+	close(b.queue)
+	b.wg.Wait()
+}
+
+// upload puts the wallet file to the bucket
+func (b *s3Backup) upload(filename string, data []byte) error {
+	u, err := url.Parse(strings.TrimSuffix(b.config.Endpoint, "/"))
+	if err != nil {
+		return err
+	}
+	u.Path = strings.Join([]string{u.Path, b.config.Bucket, b.config.KeyPrefix + filename}, "/")
+
+	req, err := http.NewRequest(http.MethodPut, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	signS3Request(req, data, b.config.AccessKey, b.config.SecretKey, b.config.Region, b.now())
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body) //nolint:errcheck
+		return fmt.Errorf("upload to %s failed: %s %s", u.String(), resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+// signS3Request adds an AWS Signature Version 4 Authorization header to req.
+// The host header and all headers already set on req are signed.
+func signS3Request(req *http.Request, payload []byte, accessKey, secretKey, region string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+
+	payloadHash := sha256.Sum256(payload)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+
+	headers := map[string]string{
+		"host": req.URL.Host,
+	}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, "s3", "aws4_request"}, "/")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data)) //nolint:errcheck
+	return h.Sum(nil)
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -29,6 +30,10 @@ type Service struct {
 	config  Config
 	// fingerprints is used to check for duplicate deterministic wallets
 	fingerprints map[string]string
+	// s3Backup uploads encrypted wallet files after they are written, if configured
+	s3Backup *s3Backup
+	// sessions cache the passwords of encrypted wallets unlocked with a PIN
+	sessions *sessions
 }
 
 // Config wallet service config
@@ -38,6 +43,12 @@ type Config struct {
 	EnableWalletAPI bool
 	EnableSeedAPI   bool
 	Bip44Coin       *bip44.CoinType
+	// S3Backup configures uploading encrypted wallet files to an S3 compatible storage service after every write.
+	// Unencrypted wallets are not uploaded.
+	S3Backup *S3BackupConfig
+	// SessionTimeout is the duration of a wallet session started with StartSession.
+	// If 0, sessions are disabled
+	SessionTimeout time.Duration
 }
 
 // NewConfig creates a default Config
@@ -49,6 +60,7 @@ func NewConfig() Config {
 		EnableWalletAPI: false,
 		EnableSeedAPI:   false,
 		Bip44Coin:       &bc,
+		SessionTimeout:  DefaultSessionTimeout,
 	}
 }
 
@@ -57,6 +69,7 @@ func NewService(c Config) (*Service, error) {
 	serv := &Service{
 		config:       c,
 		fingerprints: make(map[string]string),
+		sessions:     newSessions(c.SessionTimeout),
 	}
 
 	if !serv.config.EnableWalletAPI {
@@ -90,6 +103,13 @@ func NewService(c Config) (*Service, error) {
 
 	serv.setWallets(w)
 
+	if c.S3Backup != nil {
+		serv.s3Backup, err = newS3Backup(*c.S3Backup)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	fields := logrus.Fields{
 		"walletDir": serv.config.WalletDir,
 	}
@@ -101,6 +121,38 @@ func NewService(c Config) (*Service, error) {
 	return serv, nil
 }
 
+// Shutdown waits for the pending wallet backup uploads to finish
+func (serv *Service) Shutdown() {
+	if serv.s3Backup != nil {
+		serv.s3Backup.shutdown()
+	}
+}
+
+// save saves the wallet to the wallet directory and queues a backup of the wallet file
+func (serv *Service) save(w Wallet) error {
+	data, err := serializeWithChecksum(w)
+	if err != nil {
+		return err
+	}
+
+	if err := file.SaveBinary(filepath.Join(serv.config.WalletDir, w.Filename()), data, 0600); err != nil {
+		return err
+	}
+
+	serv.backup(w, data)
+	return nil
+}
+
+// backup queues the upload of the serialized wallet to the S3 backup, if configured.
+// Only encrypted wallets are uploaded, their secrets are encrypted in the wallet file.
+func (serv *Service) backup(w Wallet, data []byte) {
+	if serv.s3Backup == nil || !w.IsEncrypted() {
+		return
+	}
+
+	serv.s3Backup.backup(w.Filename(), data)
+}
+
 // WalletDir returns the configured wallet directory
 func (serv *Service) WalletDir() (string, error) {
 	serv.Lock()
@@ -240,7 +292,7 @@ func (serv *Service) loadWallet(wltName string, options Options) (Wallet, error)
 		return nil, err
 	}
 
-	if err := Save(w, serv.config.WalletDir); err != nil {
+	if err := serv.save(w); err != nil {
 		// If save fails, remove the added wallet
 		serv.wallets.remove(w.Filename())
 		return nil, err
@@ -287,7 +339,7 @@ func (serv *Service) EncryptWallet(wltID string, password []byte) (Wallet, error
 	}
 
 	// Saves to disk
-	if err := Save(w, serv.config.WalletDir); err != nil {
+	if err := serv.save(w); err != nil {
 		return nil, err
 	}
 
@@ -322,15 +374,66 @@ func (serv *Service) DecryptWallet(wltID string, password []byte) (Wallet, error
 	}
 
 	// Updates the wallet file
-	if err := Save(unlockWlt, serv.config.WalletDir); err != nil {
+	if err := serv.save(unlockWlt); err != nil {
 		return nil, err
 	}
 
 	// Sets the decrypted wallet in memory
 	serv.wallets.set(unlockWlt)
+	serv.sessions.end(wltID)
 	return unlockWlt, nil
 }
 
+// ChangePassword re-encrypts an encrypted wallet with a new password.
+// The wallet file is replaced atomically, if any step fails the wallet
+// stays encrypted with the old password, both on disk and in memory.
+func (serv *Service) ChangePassword(wltID string, password, newPassword []byte) (Wallet, error) {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !w.IsEncrypted() {
+		return nil, ErrWalletNotEncrypted
+	}
+
+	if len(newPassword) == 0 {
+		return nil, ErrMissingPassword
+	}
+
+	// Decrypts the secrets into a copy of the wallet
+	wlt, err := w.Unlock(password)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypts the copy with the new password, which erases its decrypted secrets
+	if err := wlt.Lock(newPassword); err != nil {
+		wlt.Erase()
+		return nil, err
+	}
+
+	data, err := serializeWithChecksum(wlt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := file.SaveBinaryAtomic(filepath.Join(serv.config.WalletDir, wlt.Filename()), data, 0600); err != nil {
+		return nil, err
+	}
+
+	serv.backup(wlt, data)
+	serv.wallets.set(wlt)
+	serv.sessions.end(wltID)
+	return wlt, nil
+}
+
 // NewAddresses generate address entries in given wallet,
 // return nil if wallet does not exist.
 // Set password as nil if the wallet is not encrypted, otherwise the password must be provided.
@@ -435,7 +538,7 @@ func (serv *Service) NewAddresses(wltID string, password []byte, num uint64, opt
 	}
 
 	// Save the wallet first
-	if err := Save(w, serv.config.WalletDir); err != nil {
+	if err := serv.save(w); err != nil {
 		return nil, err
 	}
 
@@ -495,7 +598,7 @@ func (serv *Service) ScanAddresses(wltID string, password []byte, num uint64, tf
 	}
 
 	// Saves the wallet to disk
-	if err := Save(w, serv.config.WalletDir); err != nil {
+	if err := serv.save(w); err != nil {
 		return nil, err
 	}
 
@@ -592,7 +695,7 @@ func (serv *Service) UpdateWalletLabel(wltID, label string) error {
 
 	w.SetLabel(label)
 
-	if err := Save(w, serv.config.WalletDir); err != nil {
+	if err := serv.save(w); err != nil {
 		return err
 	}
 
@@ -600,6 +703,36 @@ func (serv *Service) UpdateWalletLabel(wltID, label string) error {
 	return nil
 }
 
+// UpdateWalletMeta updates the wallet label, if label is not empty, and the user defined metadata.
+// A metadata key with an empty value is removed. Returns the updated wallet.
+func (serv *Service) UpdateWalletMeta(wltID, label string, meta map[string]string) (Wallet, error) {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	if label != "" {
+		w.SetLabel(label)
+	}
+
+	if err := w.SetUserMeta(meta); err != nil {
+		return nil, err
+	}
+
+	if err := serv.save(w); err != nil {
+		return nil, err
+	}
+
+	serv.wallets.set(w)
+	return w.Clone(), nil
+}
+
 // UnloadWallet removes wallet of given wallet id from the service
 func (serv *Service) UnloadWallet(wltID string) error {
 	serv.Lock()
@@ -616,6 +749,7 @@ func (serv *Service) UnloadWallet(wltID string) error {
 	}
 
 	serv.wallets.remove(wltID)
+	serv.sessions.end(wltID)
 	return nil
 }
 
@@ -689,7 +823,7 @@ func (serv *Service) UpdateSecrets(wltID string, password []byte, f func(Wallet)
 	}
 
 	// Save the wallet to disk
-	if err := Save(w, serv.config.WalletDir); err != nil {
+	if err := serv.save(w); err != nil {
 		return err
 	}
 
@@ -716,7 +850,7 @@ func (serv *Service) Update(wltID string, f func(Wallet) error) error {
 	}
 
 	// Save the wallet to disk
-	if err := Save(w, serv.config.WalletDir); err != nil {
+	if err := serv.save(w); err != nil {
 		return err
 	}
 
@@ -831,7 +965,7 @@ func (serv *Service) RecoverWallet(wltName, seed, seedPassphrase string, passwor
 	w3.SetTimestamp(w.Timestamp())
 
 	// Save to disk
-	if err := Save(w3, serv.config.WalletDir); err != nil {
+	if err := serv.save(w3); err != nil {
 		return nil, err
 	}
 
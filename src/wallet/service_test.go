@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/skycoin/skycoin/src/cipher/bip39"
 	"github.com/skycoin/skycoin/src/testutil"
@@ -231,14 +235,21 @@ func TestServiceCreateWallet(t *testing.T) {
 					_, err = os.Stat(filepath.Join(dir, tc.filename))
 					require.False(t, os.IsNotExist(err))
 
-					// Confirms that the data saved to the disk is the same as the wallet.Deserialize()
+					// Confirms that the wallet saved to the disk is the same as the wallet.Deserialize()
 					data, err := ioutil.ReadFile(filepath.Join(dir, tc.filename))
 					require.NoError(t, err)
+					require.Contains(t, string(data), `"checksum": `)
+
+					lw, err := wallet.Load(filepath.Join(dir, tc.filename))
+					require.NoError(t, err)
 
 					sd, err := w.Serialize()
 					require.NoError(t, err)
 
-					require.Equal(t, sd, data)
+					lsd, err := lw.Serialize()
+					require.NoError(t, err)
+
+					require.Equal(t, sd, lsd)
 				}
 
 				// create wallet with dup wallet name
@@ -1163,6 +1174,84 @@ func TestServiceUpdateWalletLabel(t *testing.T) {
 	}
 }
 
+func TestServiceUpdateWalletMeta(t *testing.T) {
+	for _, walletType := range []string{
+		wallet.WalletTypeDeterministic,
+		wallet.WalletTypeBip44,
+	} {
+		t.Run(walletType, func(t *testing.T) {
+			dir := prepareWltDir()
+			s, err := wallet.NewService(wallet.Config{
+				WalletDir:       dir,
+				CryptoType:      crypto.DefaultCryptoType,
+				EnableWalletAPI: true,
+			})
+			require.NoError(t, err)
+
+			w, err := s.CreateWallet("t.wlt", wallet.Options{
+				Seed:     bip39.MustNewDefaultMnemonic(),
+				Label:    "label",
+				Type:     walletType,
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			})
+			require.NoError(t, err)
+			require.Empty(t, w.UserMeta())
+
+			_, err = s.UpdateWalletMeta("t1.wlt", "", map[string]string{"purpose": "savings"})
+			require.Equal(t, wallet.ErrWalletNotExist, err)
+
+			nw, err := s.UpdateWalletMeta("t.wlt", "", map[string]string{
+				"purpose":    "savings",
+				"created_by": "desktop",
+			})
+			require.NoError(t, err)
+			require.Equal(t, "label", nw.Label())
+			require.Equal(t, map[string]string{
+				"purpose":    "savings",
+				"created_by": "desktop",
+			}, nw.UserMeta())
+
+			// An empty value removes the key, and an empty map only updates the label
+			_, err = s.UpdateWalletMeta("t.wlt", "", map[string]string{"created_by": ""})
+			require.NoError(t, err)
+			nw, err = s.UpdateWalletMeta("t.wlt", "new-label", nil)
+			require.NoError(t, err)
+			require.Equal(t, "new-label", nw.Label())
+			require.Equal(t, map[string]string{"purpose": "savings"}, nw.UserMeta())
+
+			// Invalid metadata is rejected without modifying the wallet
+			_, err = s.UpdateWalletMeta("t.wlt", "other-label", map[string]string{"Purpose": "x"})
+			require.Equal(t, wallet.NewError(errors.New(`invalid meta key "Purpose", keys must match [a-z_]{1,32}`)), err)
+			nw, err = s.GetWallet("t.wlt")
+			require.NoError(t, err)
+			require.Equal(t, "new-label", nw.Label())
+
+			// The metadata is saved to the wallet file, and the wallet can still be decrypted
+			s, err = wallet.NewService(wallet.Config{
+				WalletDir:       dir,
+				CryptoType:      crypto.DefaultCryptoType,
+				EnableWalletAPI: true,
+			})
+			require.NoError(t, err)
+			nw, err = s.GetWallet("t.wlt")
+			require.NoError(t, err)
+			require.Equal(t, "new-label", nw.Label())
+			require.Equal(t, map[string]string{"purpose": "savings"}, nw.UserMeta())
+			_, err = s.DecryptWallet("t.wlt", []byte("pwd"))
+			require.NoError(t, err)
+
+			s, err = wallet.NewService(wallet.Config{
+				WalletDir:       dir,
+				EnableWalletAPI: false,
+			})
+			require.NoError(t, err)
+			_, err = s.UpdateWalletMeta("t.wlt", "", nil)
+			require.Equal(t, wallet.ErrWalletAPIDisabled, err)
+		})
+	}
+}
+
 func TestServiceEncryptWallet(t *testing.T) {
 	tt := []struct {
 		name             string
@@ -1617,6 +1706,242 @@ func TestServiceDecryptWallet(t *testing.T) {
 	}
 }
 
+func TestServiceChangePassword(t *testing.T) {
+	tt := []struct {
+		name             string
+		opts             wallet.Options
+		wltName          string
+		password         []byte
+		newPassword      []byte
+		disableWalletAPI bool
+		err              error
+	}{
+		{
+			name: "ok deterministic",
+			opts: wallet.Options{
+				Seed:     "seed",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+				Type:     wallet.WalletTypeDeterministic,
+			},
+			password:    []byte("pwd"),
+			newPassword: []byte("new pwd"),
+		},
+		{
+			name: "ok bip44",
+			opts: wallet.Options{
+				Seed:     "voyage say extend find sheriff surge priority merit ignore maple cash argue",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+				Type:     wallet.WalletTypeBip44,
+			},
+			password:    []byte("pwd"),
+			newPassword: []byte("new pwd"),
+		},
+		{
+			name: "ok collection",
+			opts: wallet.Options{
+				Type:     wallet.WalletTypeCollection,
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			password:    []byte("pwd"),
+			newPassword: []byte("new pwd"),
+		},
+		{
+			name: "wallet not exist",
+			opts: wallet.Options{
+				Seed:     "seed",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+				Type:     wallet.WalletTypeDeterministic,
+			},
+			wltName:     "t.wlt",
+			password:    []byte("pwd"),
+			newPassword: []byte("new pwd"),
+			err:         wallet.ErrWalletNotExist,
+		},
+		{
+			name: "wallet not encrypted",
+			opts: wallet.Options{
+				Seed: "seed",
+				Type: wallet.WalletTypeDeterministic,
+			},
+			password:    []byte("pwd"),
+			newPassword: []byte("new pwd"),
+			err:         wallet.ErrWalletNotEncrypted,
+		},
+		{
+			name: "invalid password",
+			opts: wallet.Options{
+				Seed:     "seed",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+				Type:     wallet.WalletTypeDeterministic,
+			},
+			password:    []byte("wrong password"),
+			newPassword: []byte("new pwd"),
+			err:         wallet.ErrInvalidPassword,
+		},
+		{
+			name: "missing new password",
+			opts: wallet.Options{
+				Seed:     "seed",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+				Type:     wallet.WalletTypeDeterministic,
+			},
+			password: []byte("pwd"),
+			err:      wallet.ErrMissingPassword,
+		},
+		{
+			name: "wallet api disabled",
+			opts: wallet.Options{
+				Seed:     "seed",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+				Type:     wallet.WalletTypeDeterministic,
+			},
+			password:         []byte("pwd"),
+			newPassword:      []byte("new pwd"),
+			disableWalletAPI: true,
+			err:              wallet.ErrWalletAPIDisabled,
+		},
+	}
+
+	for _, tc := range tt {
+		for _, ct := range crypto.TypesInsecure() {
+			name := fmt.Sprintf("crypto=%v %v", ct, tc.name)
+			t.Run(name, func(t *testing.T) {
+				dir := prepareWltDir()
+				defer os.RemoveAll(dir)
+
+				s, err := wallet.NewService(wallet.Config{
+					WalletDir:       dir,
+					CryptoType:      ct,
+					EnableWalletAPI: !tc.disableWalletAPI,
+				})
+				require.NoError(t, err)
+
+				wltName := tc.wltName
+				if wltName == "" {
+					wltName = "test.wlt"
+				}
+
+				if tc.disableWalletAPI {
+					_, err = s.ChangePassword(wltName, tc.password, tc.newPassword)
+					require.Equal(t, tc.err, err)
+					return
+				}
+
+				w, err := s.CreateWallet("test.wlt", tc.opts)
+				require.NoError(t, err)
+				origSecrets := w.Secrets()
+
+				fn := filepath.Join(dir, "test.wlt")
+				origData, err := ioutil.ReadFile(fn)
+				require.NoError(t, err)
+
+				w, err = s.ChangePassword(wltName, tc.password, tc.newPassword)
+				require.Equal(t, tc.err, err)
+				if err != nil {
+					// The wallet file is unchanged
+					data, err := ioutil.ReadFile(fn)
+					require.NoError(t, err)
+					require.Equal(t, origData, data)
+					return
+				}
+
+				require.True(t, w.IsEncrypted())
+				require.NotEqual(t, origSecrets, w.Secrets())
+				require.Empty(t, w.Seed())
+
+				// Only the wallet file is in the wallet dir
+				files, err := ioutil.ReadDir(dir)
+				require.NoError(t, err)
+				require.Len(t, files, 1)
+
+				// The wallet in memory and the wallet file can only be unlocked with the new password
+				w1, err := s.Load(fn)
+				require.NoError(t, err)
+
+				for _, w := range []wallet.Wallet{w, w1} {
+					_, err = w.Unlock(tc.password)
+					require.Equal(t, wallet.ErrInvalidPassword, err)
+
+					unlocked, err := w.Unlock(tc.newPassword)
+					require.NoError(t, err)
+					if tc.opts.Seed != "" {
+						require.Equal(t, tc.opts.Seed, unlocked.Seed())
+					}
+				}
+
+				sw, err := s.GetWallet("test.wlt")
+				require.NoError(t, err)
+				require.Equal(t, w.Secrets(), sw.Secrets())
+			})
+		}
+	}
+}
+
+func TestServiceS3Backup(t *testing.T) {
+	var mu sync.Mutex
+	uploads := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		mu.Lock()
+		uploads[r.URL.Path] = body
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	dir := prepareWltDir()
+	defer os.RemoveAll(dir)
+
+	s, err := wallet.NewService(wallet.Config{
+		WalletDir:       dir,
+		CryptoType:      crypto.CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+		S3Backup: &wallet.S3BackupConfig{
+			Endpoint:  server.URL,
+			Bucket:    "backups",
+			KeyPrefix: "wallets/",
+			AccessKey: "access",
+			SecretKey: "secret",
+		},
+	})
+	require.NoError(t, err)
+
+	// Unencrypted wallets are not backed up
+	_, err = s.CreateWallet("plain.wlt", wallet.Options{
+		Seed: "seed1",
+		Type: wallet.WalletTypeDeterministic,
+	})
+	require.NoError(t, err)
+
+	_, err = s.CreateWallet("encrypted.wlt", wallet.Options{
+		Seed:     "seed2",
+		Type:     wallet.WalletTypeDeterministic,
+		Encrypt:  true,
+		Password: []byte("pwd"),
+	})
+	require.NoError(t, err)
+
+	// The latest version of the wallet file is uploaded last
+	err = s.UpdateWalletLabel("encrypted.wlt", "foo")
+	require.NoError(t, err)
+
+	s.Shutdown()
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "encrypted.wlt"))
+	require.NoError(t, err)
+
+	require.Equal(t, map[string][]byte{
+		"/backups/wallets/encrypted.wlt": data,
+	}, uploads)
+}
+
 func TestServiceCreateWalletWithScan(t *testing.T) {
 	seed := "seed1"
 	addrs := make([]cipher.Address, 20)
@@ -4313,3 +4638,107 @@ func checkNoSensitiveData(t *testing.T, w wallet.Wallet) {
 		require.True(t, e.Secret.Null())
 	}
 }
+
+func TestServiceSession(t *testing.T) {
+	dir := prepareWltDir()
+	defer os.RemoveAll(dir)
+
+	s, err := wallet.NewService(wallet.Config{
+		WalletDir:       dir,
+		CryptoType:      crypto.CryptoTypeScryptChacha20poly1305Insecure,
+		EnableWalletAPI: true,
+		SessionTimeout:  time.Minute,
+	})
+	require.NoError(t, err)
+
+	_, err = s.CreateWallet("test.wlt", wallet.Options{
+		Seed:     "seed",
+		Encrypt:  true,
+		Password: []byte("pwd"),
+		Type:     wallet.WalletTypeDeterministic,
+	})
+	require.NoError(t, err)
+
+	_, err = s.CreateWallet("plain.wlt", wallet.Options{
+		Seed: "seed2",
+		Type: wallet.WalletTypeDeterministic,
+	})
+	require.NoError(t, err)
+
+	_, err = s.StartSession("test.wlt", []byte("pwd"), []byte("12a4"))
+	require.Equal(t, wallet.ErrInvalidPIN, err)
+	_, err = s.StartSession("test.wlt", []byte("wrong"), []byte("1234"))
+	require.Equal(t, wallet.ErrInvalidPassword, err)
+	_, err = s.StartSession("test.wlt", nil, []byte("1234"))
+	require.Equal(t, wallet.ErrMissingPassword, err)
+	_, err = s.StartSession("plain.wlt", []byte("pwd"), []byte("1234"))
+	require.Equal(t, wallet.ErrWalletNotEncrypted, err)
+	_, err = s.StartSession("missing.wlt", []byte("pwd"), []byte("1234"))
+	require.Equal(t, wallet.ErrWalletNotExist, err)
+
+	_, err = s.SessionPassword("test.wlt", []byte("1234"))
+	require.Equal(t, wallet.ErrSessionNotExist, err)
+
+	start := time.Now()
+	expires, err := s.StartSession("test.wlt", []byte("pwd"), []byte("1234"))
+	require.NoError(t, err)
+	require.False(t, expires.Before(start.Add(time.Minute)))
+
+	password, err := s.SessionPassword("test.wlt", []byte("1234"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("pwd"), password)
+
+	_, err = s.SessionPassword("test.wlt", []byte("123"))
+	require.Equal(t, wallet.ErrInvalidPIN, err)
+	_, err = s.SessionPassword("test.wlt", []byte("4321"))
+	require.Equal(t, wallet.ErrWrongPIN, err)
+
+	// The password unlocks the wallet
+	err = s.ViewSecrets("test.wlt", password, func(w wallet.Wallet) error {
+		require.Equal(t, "seed", w.Seed())
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, s.EndSession("test.wlt"))
+	_, err = s.SessionPassword("test.wlt", []byte("1234"))
+	require.Equal(t, wallet.ErrSessionNotExist, err)
+
+	// Changing the password ends the session
+	_, err = s.StartSession("test.wlt", []byte("pwd"), []byte("1234"))
+	require.NoError(t, err)
+	_, err = s.ChangePassword("test.wlt", []byte("pwd"), []byte("pwd2"))
+	require.NoError(t, err)
+	_, err = s.SessionPassword("test.wlt", []byte("1234"))
+	require.Equal(t, wallet.ErrSessionNotExist, err)
+
+	// Decrypting the wallet ends the session
+	_, err = s.StartSession("test.wlt", []byte("pwd2"), []byte("1234"))
+	require.NoError(t, err)
+	_, err = s.DecryptWallet("test.wlt", []byte("pwd2"))
+	require.NoError(t, err)
+	_, err = s.SessionPassword("test.wlt", []byte("1234"))
+	require.Equal(t, wallet.ErrSessionNotExist, err)
+
+	// Sessions can be disabled
+	s, err = wallet.NewService(wallet.Config{
+		WalletDir:       dir,
+		CryptoType:      crypto.CryptoTypeScryptChacha20poly1305Insecure,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+	_, err = s.StartSession("test.wlt", []byte("pwd2"), []byte("1234"))
+	require.Equal(t, wallet.ErrSessionsDisabled, err)
+
+	// The wallet API must be enabled
+	s, err = wallet.NewService(wallet.Config{
+		WalletDir:      dir,
+		SessionTimeout: time.Minute,
+	})
+	require.NoError(t, err)
+	_, err = s.StartSession("test.wlt", []byte("pwd2"), []byte("1234"))
+	require.Equal(t, wallet.ErrWalletAPIDisabled, err)
+	_, err = s.SessionPassword("test.wlt", []byte("1234"))
+	require.Equal(t, wallet.ErrWalletAPIDisabled, err)
+	require.Equal(t, wallet.ErrWalletAPIDisabled, s.EndSession("test.wlt"))
+}
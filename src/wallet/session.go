@@ -0,0 +1,197 @@
+package wallet
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encrypt"
+)
+
+const (
+	// MinPINLength is the minimum number of digits of a session PIN
+	MinPINLength = 4
+	// MaxPINLength is the maximum number of digits of a session PIN
+	MaxPINLength = 8
+	// DefaultSessionTimeout is the default duration of a wallet session
+	DefaultSessionTimeout = 5 * time.Minute
+	// maxPINAttempts is the number of wrong PINs after which a session is ended
+	maxPINAttempts = 3
+)
+
+var (
+	// ErrInvalidPIN is returned if a PIN is not 4 to 8 digits
+	ErrInvalidPIN = NewError(errors.New("pin must be 4 to 8 digits"))
+	// ErrWrongPIN is returned if a PIN does not match the PIN of the wallet session
+	ErrWrongPIN = NewError(errors.New("wrong pin"))
+	// ErrSessionNotExist is returned if a wallet has no session, or its session has expired
+	ErrSessionNotExist = NewError(errors.New("wallet session doesn't exist or has expired, the password is required"))
+	// ErrSessionsDisabled is returned when starting a session if Config.SessionTimeout is 0
+	ErrSessionsDisabled = NewError(errors.New("wallet sessions are disabled"))
+)
+
+// ValidatePIN checks that a PIN is 4 to 8 digits
+func ValidatePIN(pin []byte) error {
+	if len(pin) < MinPINLength || len(pin) > MaxPINLength {
+		return ErrInvalidPIN
+	}
+
+	for _, c := range pin {
+		if c < '0' || c > '9' {
+			return ErrInvalidPIN
+		}
+	}
+
+	return nil
+}
+
+// session holds the password of an encrypted wallet, encrypted with a key derived from the session PIN.
+// The password is only kept in memory.
+type session struct {
+	// sealedPassword is the wallet password encrypted with sessionKey(pin, salt)
+	sealedPassword []byte
+	salt           []byte
+	expires        time.Time
+	// Number of consecutive wrong PINs
+	failedAttempts int
+}
+
+// sessionKey derives the key that encrypts the password of a session from its PIN
+func sessionKey(pin, salt []byte) []byte {
+	h := cipher.SumSHA256(append(append([]byte{}, salt...), pin...))
+	return h[:]
+}
+
+// sessions are the wallet sessions, by wallet ID
+type sessions struct {
+	sync.Mutex
+	timeout  time.Duration
+	sessions map[string]*session
+}
+
+func newSessions(timeout time.Duration) *sessions {
+	return &sessions{
+		timeout:  timeout,
+		sessions: make(map[string]*session),
+	}
+}
+
+// start replaces the session of a wallet. The password must have been verified by the caller
+func (s *sessions) start(wltID string, password, pin []byte, now time.Time) (time.Time, error) {
+	salt := cipher.RandByte(32)
+	sealed, err := encrypt.DefaultSha256Xor.Encrypt(password, sessionKey(pin, salt))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	expires := now.Add(s.timeout)
+	s.sessions[wltID] = &session{
+		sealedPassword: sealed,
+		salt:           salt,
+		expires:        expires,
+	}
+
+	return expires, nil
+}
+
+// password returns the password of the wallet session unlocked by pin.
+// The session is ended after maxPINAttempts consecutive wrong PINs.
+func (s *sessions) password(wltID string, pin []byte, now time.Time) ([]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	ss, ok := s.sessions[wltID]
+	if !ok {
+		return nil, ErrSessionNotExist
+	}
+
+	if !now.Before(ss.expires) {
+		delete(s.sessions, wltID)
+		return nil, ErrSessionNotExist
+	}
+
+	password, err := encrypt.DefaultSha256Xor.Decrypt(ss.sealedPassword, sessionKey(pin, ss.salt))
+	if err != nil {
+		ss.failedAttempts++
+		if ss.failedAttempts >= maxPINAttempts {
+			delete(s.sessions, wltID)
+		}
+		return nil, ErrWrongPIN
+	}
+
+	ss.failedAttempts = 0
+	return password, nil
+}
+
+// end removes the session of a wallet, if any
+func (s *sessions) end(wltID string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.sessions, wltID)
+}
+
+// StartSession verifies the password of an encrypted wallet and caches it in memory for Config.SessionTimeout,
+// encrypted with a key derived from pin. Until the session expires, SessionPassword returns the password
+// for the PIN, so that the wallet can be used with the PIN instead of the password.
+// A previous session of the wallet is replaced. Returns the time at which the session expires.
+func (serv *Service) StartSession(wltID string, password, pin []byte) (time.Time, error) {
+	serv.RLock()
+	defer serv.RUnlock()
+	if !serv.config.EnableWalletAPI {
+		return time.Time{}, ErrWalletAPIDisabled
+	}
+
+	if serv.config.SessionTimeout <= 0 {
+		return time.Time{}, ErrSessionsDisabled
+	}
+
+	if err := ValidatePIN(pin); err != nil {
+		return time.Time{}, err
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if !w.IsEncrypted() {
+		return time.Time{}, ErrWalletNotEncrypted
+	}
+
+	if err := GuardView(w, password, func(Wallet) error {
+		return nil
+	}); err != nil {
+		return time.Time{}, err
+	}
+
+	return serv.sessions.start(wltID, password, pin, time.Now())
+}
+
+// SessionPassword returns the password cached by the session of a wallet, if pin is the PIN of the session.
+// Returns ErrSessionNotExist if the wallet has no session or the session has expired,
+// and ErrWrongPIN if the PIN is wrong. After 3 consecutive wrong PINs the session is ended.
+func (serv *Service) SessionPassword(wltID string, pin []byte) ([]byte, error) {
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	if err := ValidatePIN(pin); err != nil {
+		return nil, err
+	}
+
+	return serv.sessions.password(wltID, pin, time.Now())
+}
+
+// EndSession ends the session of a wallet, if it has one
+func (serv *Service) EndSession(wltID string) error {
+	if !serv.config.EnableWalletAPI {
+		return ErrWalletAPIDisabled
+	}
+
+	serv.sessions.end(wltID)
+	return nil
+}
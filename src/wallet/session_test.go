@@ -0,0 +1,81 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePIN(t *testing.T) {
+	for _, pin := range []string{"0000", "1234", "12345678"} {
+		require.NoError(t, ValidatePIN([]byte(pin)), pin)
+	}
+
+	for _, pin := range []string{"", "123", "123456789", "12a4", "12 34", "-1234"} {
+		require.Equal(t, ErrInvalidPIN, ValidatePIN([]byte(pin)), pin)
+	}
+}
+
+func TestSessions(t *testing.T) {
+	now := time.Unix(1540000000, 0)
+	s := newSessions(time.Minute)
+
+	_, err := s.password("foo.wlt", []byte("1234"), now)
+	require.Equal(t, ErrSessionNotExist, err)
+
+	expires, err := s.start("foo.wlt", []byte("pwd"), []byte("1234"), now)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(time.Minute), expires)
+
+	// The password is not stored in clear
+	require.NotContains(t, string(s.sessions["foo.wlt"].sealedPassword), "pwd")
+
+	password, err := s.password("foo.wlt", []byte("1234"), now.Add(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, []byte("pwd"), password)
+
+	// Sessions are per wallet
+	_, err = s.password("bar.wlt", []byte("1234"), now)
+	require.Equal(t, ErrSessionNotExist, err)
+
+	// A correct PIN resets the wrong PIN count
+	_, err = s.password("foo.wlt", []byte("4321"), now)
+	require.Equal(t, ErrWrongPIN, err)
+	_, err = s.password("foo.wlt", []byte("4321"), now)
+	require.Equal(t, ErrWrongPIN, err)
+	_, err = s.password("foo.wlt", []byte("1234"), now)
+	require.NoError(t, err)
+
+	// The session is ended after 3 wrong PINs
+	for i := 0; i < maxPINAttempts; i++ {
+		_, err = s.password("foo.wlt", []byte("4321"), now)
+		require.Equal(t, ErrWrongPIN, err)
+	}
+	_, err = s.password("foo.wlt", []byte("1234"), now)
+	require.Equal(t, ErrSessionNotExist, err)
+
+	// The session expires after the timeout
+	_, err = s.start("foo.wlt", []byte("pwd"), []byte("1234"), now)
+	require.NoError(t, err)
+	_, err = s.password("foo.wlt", []byte("1234"), now.Add(time.Minute-time.Nanosecond))
+	require.NoError(t, err)
+	_, err = s.password("foo.wlt", []byte("1234"), now.Add(time.Minute))
+	require.Equal(t, ErrSessionNotExist, err)
+	require.Empty(t, s.sessions)
+
+	// Starting a session replaces the previous session of the wallet
+	_, err = s.start("foo.wlt", []byte("pwd"), []byte("1234"), now)
+	require.NoError(t, err)
+	_, err = s.start("foo.wlt", []byte("pwd2"), []byte("5678"), now)
+	require.NoError(t, err)
+	_, err = s.password("foo.wlt", []byte("1234"), now)
+	require.Equal(t, ErrWrongPIN, err)
+	password, err = s.password("foo.wlt", []byte("5678"), now)
+	require.NoError(t, err)
+	require.Equal(t, []byte("pwd2"), password)
+
+	s.end("foo.wlt")
+	_, err = s.password("foo.wlt", []byte("5678"), now)
+	require.Equal(t, ErrSessionNotExist, err)
+}
@@ -183,6 +183,10 @@ type Wallet interface {
 	SetBip44Coin(ct bip44.CoinType)
 	Label() string
 	SetLabel(string)
+	// UserMeta returns the user defined metadata
+	UserMeta() map[string]string
+	// SetUserMeta sets user defined metadata, removing the keys with empty values
+	SetUserMeta(map[string]string) error
 	Filename() string
 	SetFilename(string)
 	IsEncrypted() bool
@@ -341,15 +345,26 @@ type walletLoadMeta struct {
 
 // Save saves the wallet to a directory. The wallet's filename is read from its metadata.
 func Save(w Wallet, dir string) error {
-	data, err := w.Serialize()
+	data, err := serializeWithChecksum(w)
 	if err != nil {
 		return err
 	}
 	return file.SaveBinary(filepath.Join(dir, w.Filename()), data, 0600)
 }
 
-// Load loads wallet from a file
+// Load loads wallet from a file.
+// A wallet file of an older version is migrated, and saved back to the file if the migration changed its fields
 func Load(filename string) (Wallet, error) {
+	return load(filename, true)
+}
+
+// LoadReadOnly loads wallet from a file like Load, but never writes to the file.
+// A wallet file of an older version is migrated in memory only
+func LoadReadOnly(filename string) (Wallet, error) {
+	return load(filename, false)
+}
+
+func load(filename string, saveMigrated bool) (Wallet, error) {
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
 		return nil, fmt.Errorf("wallet %q doesn't exist", filename)
 	}
@@ -360,6 +375,11 @@ func Load(filename string) (Wallet, error) {
 		return nil, err
 	}
 
+	// Version 0.1 wallets may have no type, the migration sets it to deterministic
+	if m.Meta.Type == "" && (m.Meta.Version == "" || m.Meta.Version == "0.1") {
+		m.Meta.Type = WalletTypeDeterministic
+	}
+
 	if m.Meta.Type == "" {
 		err := errors.New("missing meta.type field")
 		logger.WithError(err).WithField("filename", filename)
@@ -378,7 +398,12 @@ func Load(filename string) (Wallet, error) {
 		return nil, err
 	}
 
-	w, err := l.Load(data)
+	var w Wallet
+	if saveMigrated {
+		w, err = loadWalletData(filename, data, l)
+	} else {
+		w, _, err = decodeWalletData(filename, data, l)
+	}
 	if err != nil {
 		return nil, err
 	}
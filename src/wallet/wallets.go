@@ -34,7 +34,7 @@ func loadWallets(dir string, loader Loader) (Wallets, error) {
 			if err != nil {
 				return nil, err
 			}
-			w, err := loader.Load(data)
+			w, err := loadWalletData(fullpath, data, loader)
 			if err != nil {
 				logger.WithError(err).WithField("filename", fullpath).Error("loadWallets: loadWallet failed")
 				return nil, err